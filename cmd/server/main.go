@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fleet-backend/internal/api/middleware"
 	"fleet-backend/internal/api/routes"
 	"fleet-backend/internal/config"
 	"fleet-backend/pkg/database"
 	"fleet-backend/pkg/redis"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 
@@ -44,14 +53,25 @@ func main() {
 		log.Println("Redis is disabled")
 	}
 	
+	// Report misconfiguration and unreachable dependencies before serving
+	runSelfCheck(cfg, db, redisClient)
+	
 	// Setup Gin router
 	router := gin.Default()
+	// Only believe forwarding headers set by our own proxies; clients can
+	// send any X-Forwarded-For they like
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+	
+	// Tag every request with a correlation ID before anything else logs
+	router.Use(middleware.RequestIDMiddleware())
 	
 	// CORS middleware
 	corsConfig := cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Upgrade", "Connection", "Sec-WebSocket-Key", "Sec-WebSocket-Version", "Sec-WebSocket-Protocol"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Upgrade", "Connection", "Sec-WebSocket-Key", "Sec-WebSocket-Version", "Sec-WebSocket-Protocol", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 	}
 	
 	// Handle wildcard origin for development
@@ -66,9 +86,76 @@ func main() {
 	router.Use(cors.New(corsConfig))
 	
 	// Setup routes
-	routes.SetupRoutes(router, db, redisClient, cfg)
+	app := routes.SetupRoutes(router, db, redisClient, cfg)
+	
+	// Warm the vehicle cache in the background so startup isn't delayed
+	if cfg.Cache.WarmOnStartup {
+		go func() {
+			populated, err := app.VehicleService.WarmCache(context.Background())
+			if err != nil {
+				log.Printf("Cache warming failed: %v", err)
+				return
+			}
+			log.Printf("Cache warming populated %d entries", populated)
+		}()
+	}
+	
+	server := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: router,
+	}
 	
 	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	log.Fatal(router.Run(":" + cfg.Port))
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed:", err)
+		}
+	}()
+	
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+	
+	// Stop accepting requests first so no new telemetry enters the pipeline,
+	// then stop batching, deliver final broadcasts and drain WebSocket clients.
+	// Both steps share one deadline so shutdown never exceeds the timeout
+	deadline := time.Now().Add(cfg.ShutdownTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+	
+	app.Shutdown(max(time.Until(deadline), 0))
+	log.Println("Server stopped")
+}
+
+// runSelfCheck logs the startup self-check report and, in strict mode, exits
+// when it found a fatal problem
+func runSelfCheck(cfg *config.Config, db *mongo.Database, redisClient *redis.Client) {
+	dependencies := []config.DependencyCheck{{
+		Name:     "MongoDB",
+		Required: true,
+		Ping: func(ctx context.Context) error {
+			return db.Client().Ping(ctx, nil)
+		},
+	}}
+	if redisClient != nil {
+		dependencies = append(dependencies, config.DependencyCheck{
+			Name: "Redis",
+			// Without the in-memory fallback every cached read needs Redis
+			Required: !cfg.Cache.FallbackEnabled,
+			Ping: func(ctx context.Context) error {
+				return redisClient.GetClient().Ping(ctx).Err()
+			},
+		})
+	}
+
+	report := config.SelfCheck(context.Background(), cfg, cfg.SelfCheck.Timeout, dependencies...)
+	report.Log()
+	if report.Fatal() && cfg.SelfCheck.Strict {
+		log.Fatal("Refusing to start: the startup self-check found fatal problems (SELF_CHECK_STRICT is set)")
+	}
 }
@@ -14,6 +14,7 @@ require (
 	github.com/redis/go-redis/v9 v9.11.0
 	github.com/stretchr/testify v1.10.0
 	go.mongodb.org/mongo-driver v1.17.4
+	go.uber.org/goleak v1.3.0
 	golang.org/x/crypto v0.39.0
 )
 
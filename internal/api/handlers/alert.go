@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fleet-backend/internal/services"
 	"fleet-backend/pkg/utils"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -22,15 +25,57 @@ func NewAlertHandler(alertService *services.AlertService) *AlertHandler {
 	}
 }
 
-// GetAlerts retrieves all alerts
+// GetAlerts retrieves a page of alerts, optionally filtered by vehicle,
+// severity, type, resolved state and a from/to timestamp range
 func (h *AlertHandler) GetAlerts(c *gin.Context) {
-	alerts, err := h.alertService.GetAllAlerts()
+	var req services.AlertListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert filters", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	page, err := h.alertService.ListAlerts(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrInvalidAlertRange) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert filters", err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve alerts", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Alerts retrieved successfully", alerts)
+	utils.SuccessResponse(c, http.StatusOK, "Alerts retrieved successfully", page)
+}
+
+// ExportAlerts streams alerts as a CSV or JSON download, optionally filtered by
+// severity, type, resolved state and a from/to timestamp range
+func (h *AlertHandler) ExportAlerts(c *gin.Context) {
+	var req services.AlertExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid export filters", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", services.ExportFormatCSV))
+	if err := services.ValidateExportFormat(format); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid export format", err)
+		return
+	}
+
+	setExportHeaders(c, "alerts", format)
+	if err := h.alertService.ExportAlerts(c.Request.Context(), c.Writer, format, &req); err != nil {
+		// Headers are already sent, so the truncated download is all the client will see
+		log.Printf("Alert export failed: %v", err)
+	}
 }
 
 // GetAlert retrieves a specific alert by ID
@@ -41,7 +86,7 @@ func (h *AlertHandler) GetAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.alertService.GetAlertByID(alertID)
+	alert, err := h.alertService.GetAlertByID(c.Request.Context(), alertID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Alert not found", err)
 		return
@@ -63,7 +108,7 @@ func (h *AlertHandler) CreateAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.alertService.CreateAlert(&req)
+	alert, err := h.alertService.CreateAlert(c.Request.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create alert", err)
 		return
@@ -91,7 +136,7 @@ func (h *AlertHandler) UpdateAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.alertService.UpdateAlert(alertID, &req)
+	alert, err := h.alertService.UpdateAlert(c.Request.Context(), alertID, &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update alert", err)
 		return
@@ -108,7 +153,7 @@ func (h *AlertHandler) ResolveAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.alertService.ResolveAlert(alertID)
+	alert, err := h.alertService.ResolveAlert(c.Request.Context(), alertID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to resolve alert", err)
 		return
@@ -117,6 +162,74 @@ func (h *AlertHandler) ResolveAlert(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Alert resolved successfully", alert)
 }
 
+// AcknowledgeAlert claims an alert for the current user without resolving it
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	alertID := c.Param("id")
+	if alertID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Alert ID is required", nil)
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	// The note is optional, so an empty body is accepted
+	var req services.AcknowledgeAlertRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	alert, err := h.alertService.AcknowledgeAlert(c.Request.Context(), alertID, userID.(string), &req)
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "Alert acknowledged successfully", alert)
+	case errors.Is(err, services.ErrAlertAlreadyResolved):
+		utils.ErrorResponse(c, http.StatusConflict, "Alert is already resolved", err)
+	default:
+		utils.ErrorResponse(c, http.StatusNotFound, "Alert not found", err)
+	}
+}
+
+// BulkAcknowledgeAlerts acknowledges the unresolved alerts matching an ID list
+// or filter on behalf of the authenticated user
+func (h *AlertHandler) BulkAcknowledgeAlerts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	var req services.BulkAcknowledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.alertService.BulkAcknowledgeAlerts(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		respondError(c, err, "Failed to acknowledge alerts")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Alerts acknowledged successfully", result)
+}
+
 // DismissAlert dismisses (deletes) an alert
 func (h *AlertHandler) DismissAlert(c *gin.Context) {
 	alertID := c.Param("id")
@@ -125,7 +238,7 @@ func (h *AlertHandler) DismissAlert(c *gin.Context) {
 		return
 	}
 
-	err := h.alertService.DismissAlert(alertID)
+	err := h.alertService.DismissAlert(c.Request.Context(), alertID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to dismiss alert", err)
 		return
@@ -142,7 +255,7 @@ func (h *AlertHandler) GetAlertsByVehicle(c *gin.Context) {
 		return
 	}
 
-	alerts, err := h.alertService.GetAlertsByVehicle(vehicleID)
+	alerts, err := h.alertService.GetAlertsByVehicle(c.Request.Context(), vehicleID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve alerts", err)
 		return
@@ -159,7 +272,7 @@ func (h *AlertHandler) GetAlertsByType(c *gin.Context) {
 		return
 	}
 
-	alerts, err := h.alertService.GetAlertsByType(alertType)
+	alerts, err := h.alertService.GetAlertsByType(c.Request.Context(), alertType)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve alerts", err)
 		return
@@ -176,7 +289,7 @@ func (h *AlertHandler) GetAlertsBySeverity(c *gin.Context) {
 		return
 	}
 
-	alerts, err := h.alertService.GetAlertsBySeverity(severity)
+	alerts, err := h.alertService.GetAlertsBySeverity(c.Request.Context(), severity)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve alerts", err)
 		return
@@ -187,7 +300,7 @@ func (h *AlertHandler) GetAlertsBySeverity(c *gin.Context) {
 
 // GetUnresolvedAlerts retrieves all unresolved alerts
 func (h *AlertHandler) GetUnresolvedAlerts(c *gin.Context) {
-	alerts, err := h.alertService.GetUnresolvedAlerts()
+	alerts, err := h.alertService.GetUnresolvedAlerts(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve unresolved alerts", err)
 		return
@@ -198,7 +311,7 @@ func (h *AlertHandler) GetUnresolvedAlerts(c *gin.Context) {
 
 // GetAlertStatistics retrieves alert statistics
 func (h *AlertHandler) GetAlertStatistics(c *gin.Context) {
-	stats, err := h.alertService.GetAlertStatistics()
+	stats, err := h.alertService.GetAlertStatistics(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve alert statistics", err)
 		return
@@ -215,7 +328,7 @@ func (h *AlertHandler) ResolveAlertsByVehicle(c *gin.Context) {
 		return
 	}
 
-	err := h.alertService.ResolveAlertsByVehicle(vehicleID)
+	err := h.alertService.ResolveAlertsByVehicle(c.Request.Context(), vehicleID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to resolve alerts", err)
 		return
@@ -232,7 +345,7 @@ func (h *AlertHandler) ResolveAlertsByType(c *gin.Context) {
 		return
 	}
 
-	err := h.alertService.ResolveAlertsByType(alertType)
+	err := h.alertService.ResolveAlertsByType(c.Request.Context(), alertType)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to resolve alerts", err)
 		return
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchFlusher writes pending batched updates immediately
+type BatchFlusher interface {
+	FlushPending() (int, error)
+}
+
+// BatchFlushResponse reports a forced flush
+type BatchFlushResponse struct {
+	Flushed int `json:"flushed"`
+}
+
+// BatchAdminHandler lets operators drive the batch processor during deploys
+// and debugging
+type BatchAdminHandler struct {
+	flusher BatchFlusher
+}
+
+func NewBatchAdminHandler(flusher BatchFlusher) *BatchAdminHandler {
+	return &BatchAdminHandler{
+		flusher: flusher,
+	}
+}
+
+// FlushBatch writes pending updates without waiting for the batch interval or
+// max wait time (admin only). Updates that still fail after the batch's
+// fallback go to the dead-letter queue as usual.
+func (h *BatchAdminHandler) FlushBatch(c *gin.Context) {
+	flushed, err := h.flusher.FlushPending()
+	if err != nil {
+		log.Printf("Forced batch flush of %d updates had failures: %v", flushed, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Batch flushed with failures", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Batch flushed", BatchFlushResponse{Flushed: flushed})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeadLetterPageSize = 20
+	maxDeadLetterPageSize     = 100
+)
+
+// DeadLetterManager lists, retries and discards permanently failed batch updates
+type DeadLetterManager interface {
+	ListDeadLetters(offset, limit int) ([]batch.DeadLetter, int)
+	RetryDeadLetters(ids []string) batch.DeadLetterRetryResult
+	DiscardDeadLetters(ids []string) (int, []string)
+}
+
+// DeadLetterHandler exposes the batch dead-letter queue to operators
+type DeadLetterHandler struct {
+	manager DeadLetterManager
+}
+
+func NewDeadLetterHandler(manager DeadLetterManager) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		manager: manager,
+	}
+}
+
+// DeadLetterSelection picks the dead letters to act on: the listed IDs, or
+// every dead letter when All is set
+type DeadLetterSelection struct {
+	IDs []string `json:"ids"`
+	All bool     `json:"all"`
+}
+
+// GetDeadLetters lists permanently failed updates, oldest first (admin only)
+func (h *DeadLetterHandler) GetDeadLetters(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeadLetterPageSize)))
+	if err != nil || limit < 1 {
+		limit = defaultDeadLetterPageSize
+	}
+	if limit > maxDeadLetterPageSize {
+		limit = maxDeadLetterPageSize
+	}
+
+	letters, total := h.manager.ListDeadLetters((page-1)*limit, limit)
+	utils.PaginatedResponse(c, http.StatusOK, "Dead letters retrieved successfully", letters, utils.Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      int64(total),
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// RetryDeadLetters queues the selected dead letters for the next batch (admin only)
+func (h *DeadLetterHandler) RetryDeadLetters(c *gin.Context) {
+	ids, ok := bindDeadLetterSelection(c)
+	if !ok {
+		return
+	}
+
+	result := h.manager.RetryDeadLetters(ids)
+	utils.SuccessResponse(c, http.StatusOK, "Dead letters retried", result)
+}
+
+// DiscardDeadLetters drops the selected dead letters (admin only)
+func (h *DeadLetterHandler) DiscardDeadLetters(c *gin.Context) {
+	ids, ok := bindDeadLetterSelection(c)
+	if !ok {
+		return
+	}
+
+	discarded, notFound := h.manager.DiscardDeadLetters(ids)
+	utils.SuccessResponse(c, http.StatusOK, "Dead letters discarded", gin.H{
+		"discarded": discarded,
+		"notFound":  notFound,
+	})
+}
+
+// bindDeadLetterSelection reads the selection from the request body and returns
+// the IDs to act on, nil meaning all. An empty selection is rejected so a
+// missing body can't act on the whole queue.
+func bindDeadLetterSelection(c *gin.Context) ([]string, bool) {
+	var selection DeadLetterSelection
+	if err := c.ShouldBindJSON(&selection); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return nil, false
+	}
+
+	if selection.All {
+		if len(selection.IDs) > 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Specify either ids or all, not both", nil)
+			return nil, false
+		}
+		return nil, true
+	}
+	if len(selection.IDs) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Dead letter ids are required unless all is set", nil)
+		return nil, false
+	}
+	return selection.IDs, true
+}
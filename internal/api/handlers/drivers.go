@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultScorecardPeriod is the period a scorecard covers unless from is given
+const defaultScorecardPeriod = 7 * 24 * time.Hour
+
+type DriverHandler struct {
+	scorecardService *services.ScorecardService
+}
+
+func NewDriverHandler(scorecardService *services.ScorecardService) *DriverHandler {
+	return &DriverHandler{
+		scorecardService: scorecardService,
+	}
+}
+
+// GetScorecard scores the driver within the RFC3339 from and to query
+// parameters, the past week by default
+func (h *DriverHandler) GetScorecard(c *gin.Context) {
+	driverID := c.Param("id")
+	if driverID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Driver ID is required", nil)
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to parameter, expected RFC3339", err)
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultScorecardPeriod)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from parameter, expected RFC3339", err)
+			return
+		}
+		from = parsed
+	}
+
+	scorecard, err := h.scorecardService.Compute(c.Request.Context(), driverID, from, to)
+	if err != nil {
+		respondError(c, err, "Failed to compute driver scorecard")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Driver scorecard computed successfully", scorecard)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorMapping is the HTTP status and response code of a domain error
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// domainErrors maps service errors to responses. Specific errors come before
+// the kinds they belong to so they keep their own code.
+var domainErrors = []errorMapping{
+	{services.ErrDuplicatePlate, http.StatusConflict, "duplicate_plate"},
+	{services.ErrDuplicateVIN, http.StatusConflict, "duplicate_vin"},
+	{services.ErrInvalidVIN, http.StatusBadRequest, "invalid_vin"},
+	{services.ErrVehicleArchived, http.StatusConflict, "vehicle_archived"},
+	{services.ErrVehicleNotArchived, http.StatusConflict, "vehicle_not_archived"},
+	{services.ErrInvalidVehicleStatus, http.StatusBadRequest, "invalid_status"},
+	{services.ErrInvalidStatusTransition, http.StatusConflict, "invalid_status_transition"},
+	{services.ErrInvalidMetadata, http.StatusBadRequest, "invalid_metadata"},
+	{services.ErrInvalidTags, http.StatusBadRequest, "invalid_tags"},
+	{services.ErrVehicleNotFound, http.StatusNotFound, "vehicle_not_found"},
+	{services.ErrMaintenanceRecordNotFound, http.StatusNotFound, "maintenance_record_not_found"},
+	{services.ErrExchangeRateNotFound, http.StatusNotFound, "exchange_rate_not_found"},
+	{services.ErrDriverNotFound, http.StatusNotFound, "driver_not_found"},
+	{services.ErrNotFound, http.StatusNotFound, "not_found"},
+	{services.ErrValidation, http.StatusBadRequest, utils.CodeValidationFailed},
+	{services.ErrConflict, http.StatusConflict, "conflict"},
+}
+
+// respondError sends the response for an error returned by a service. Domain
+// errors get their own status and code; anything else is an internal error.
+func respondError(c *gin.Context, err error, message string) {
+	for _, mapping := range domainErrors {
+		if errors.Is(err, mapping.err) {
+			utils.CodedErrorResponse(c, mapping.status, mapping.code, message, err)
+			return
+		}
+	}
+	utils.CodedErrorResponse(c, http.StatusInternalServerError, "internal_error", message, err)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveError responds to a request with the given service error
+func serveError(t *testing.T, err error) (int, utils.APIResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/vehicles", func(c *gin.Context) {
+		respondError(c, err, "Failed to create vehicle")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/vehicles", nil)
+	router.ServeHTTP(w, req)
+
+	var body utils.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestRespondError_DuplicatePlate(t *testing.T) {
+	status, body := serveError(t, services.ErrDuplicatePlate)
+
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "duplicate_plate", body.Code)
+	assert.False(t, body.Success)
+	assert.Equal(t, "Failed to create vehicle", body.Message)
+	assert.Equal(t, "plate number already exists", body.Error)
+}
+
+func TestRespondError_MapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"wrapped duplicate plate", fmt.Errorf("row 3: %w", services.ErrDuplicatePlate), http.StatusConflict, "duplicate_plate"},
+		{"duplicate VIN", services.ErrDuplicateVIN, http.StatusConflict, "duplicate_vin"},
+		{"invalid VIN", fmt.Errorf("%w: VIN check digit does not match", services.ErrInvalidVIN), http.StatusBadRequest, "invalid_vin"},
+		{"vehicle not found", services.ErrVehicleNotFound, http.StatusNotFound, "vehicle_not_found"},
+		{"maintenance record not found", services.ErrMaintenanceRecordNotFound, http.StatusNotFound, "maintenance_record_not_found"},
+		{"exchange rate not found", fmt.Errorf("%w: no rate from EUR to USD", services.ErrExchangeRateNotFound), http.StatusNotFound, "exchange_rate_not_found"},
+		{"driver not found", services.ErrDriverNotFound, http.StatusNotFound, "driver_not_found"},
+		{"invalid status", fmt.Errorf("%w: %q", services.ErrInvalidVehicleStatus, "parked"), http.StatusBadRequest, "invalid_status"},
+		{"invalid metadata", services.ErrInvalidMetadata, http.StatusBadRequest, "invalid_metadata"},
+		{"archived", services.ErrVehicleArchived, http.StatusConflict, "vehicle_archived"},
+		{"not found kind", fmt.Errorf("%w: driver", services.ErrNotFound), http.StatusNotFound, "not_found"},
+		{"validation kind", fmt.Errorf("%w: name", services.ErrValidation), http.StatusBadRequest, "validation_failed"},
+		{"conflict kind", services.ErrConflict, http.StatusConflict, "conflict"},
+		{"unknown", errors.New("connection reset"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serveError(t, tt.err)
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, body.Code)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ExchangeRateHandler manages the exchange rates maintenance costs are
+// converted with
+type ExchangeRateHandler struct {
+	converter *services.CurrencyConverter
+	validator *validator.Validate
+}
+
+func NewExchangeRateHandler(converter *services.CurrencyConverter) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		converter: converter,
+		validator: validator.New(),
+	}
+}
+
+// CreateExchangeRate adds a rate from one currency to another. Maintenance
+// records created afterwards for services performed from its effective time
+// use it; existing records keep the rate they were created with.
+func (h *ExchangeRateHandler) CreateExchangeRate(c *gin.Context) {
+	var req services.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	rate, err := h.converter.AddRate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err, "Failed to create exchange rate")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Exchange rate created successfully", rate)
+}
+
+// GetExchangeRate returns the rate from the from currency to the to currency,
+// the reporting currency by default, in effect at the RFC3339 at time, now by
+// default
+func (h *ExchangeRateHandler) GetExchangeRate(c *gin.Context) {
+	from := c.Query("from")
+	if from == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "from parameter is required", nil)
+		return
+	}
+	to := c.DefaultQuery("to", h.converter.ReportingCurrency())
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid at parameter, expected RFC3339", err)
+			return
+		}
+		at = parsed
+	}
+
+	rate, err := h.converter.RateAt(c.Request.Context(), from, to, at)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve exchange rate")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Exchange rate retrieved successfully", rate)
+}
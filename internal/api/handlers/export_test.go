@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetExportHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	setExportHeaders(c, "vehicles", "csv")
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="vehicles-\d{8}-\d{6}\.csv"$`, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportVehicles_InvalidFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &VehicleHandler{}
+	router := gin.New()
+	router.GET("/vehicles/export", handler.ExportVehicles)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/vehicles/export?format=xml", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportAlerts_InvalidFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewAlertHandler(nil)
+	router := gin.New()
+	router.GET("/alerts/export", handler.ExportAlerts)
+
+	for _, query := range []string{"resolved=maybe", "from=yesterday", "severity=urgent"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/alerts/export?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Empty(t, w.Header().Get("Content-Disposition"), query)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type GeofenceHandler struct {
+	geofenceService *services.GeofenceService
+	validator       *validator.Validate
+}
+
+func NewGeofenceHandler(geofenceService *services.GeofenceService) *GeofenceHandler {
+	return &GeofenceHandler{
+		geofenceService: geofenceService,
+		validator:       validator.New(),
+	}
+}
+
+// CreateGeofence creates a new circular or polygon geofence
+func (h *GeofenceHandler) CreateGeofence(c *gin.Context) {
+	var req services.CreateGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	geofence, err := h.geofenceService.CreateGeofence(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create geofence", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Geofence created successfully", geofence)
+}
+
+// GetGeofences retrieves all geofences
+func (h *GeofenceHandler) GetGeofences(c *gin.Context) {
+	geofences, err := h.geofenceService.GetAllGeofences()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve geofences", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Geofences retrieved successfully", geofences)
+}
+
+// GetGeofence retrieves a specific geofence by ID
+func (h *GeofenceHandler) GetGeofence(c *gin.Context) {
+	geofence, err := h.geofenceService.GetGeofence(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Geofence not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Geofence retrieved successfully", geofence)
+}
+
+// DeleteGeofence deletes a geofence
+func (h *GeofenceHandler) DeleteGeofence(c *gin.Context) {
+	if err := h.geofenceService.DeleteGeofence(c.Param("id")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete geofence", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Geofence deleted successfully", nil)
+}
+
+// GetVehicleGeofences retrieves the geofences a vehicle is currently inside
+func (h *GeofenceHandler) GetVehicleGeofences(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	memberships, err := h.geofenceService.GetVehicleGeofences(c.Request.Context(), vehicleID)
+	if err != nil {
+		if err.Error() == "vehicle not found" {
+			utils.ErrorResponse(c, http.StatusNotFound, "Vehicle not found", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicle geofences", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle geofences retrieved successfully", memberships)
+}
@@ -2,55 +2,134 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fleet-backend/pkg/redis"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const (
+	// defaultHealthCheckTimeout bounds each dependency check so a hung backend
+	// cannot hold a load balancer probe open
+	defaultHealthCheckTimeout = 2 * time.Second
+	// defaultHealthCacheTTL lets frequent probes share one round of checks
+	defaultHealthCacheTTL = 2 * time.Second
+)
+
+// HealthCheckFunc reports whether a dependency is usable
+type HealthCheckFunc func(ctx context.Context) error
+
+// dependencyCheck is a named health check; required dependencies gate readiness
+type dependencyCheck struct {
+	name     string
+	required bool
+	check    HealthCheckFunc
+}
+
+// DependencyStatus is the result of checking a single dependency
+type DependencyStatus struct {
+	Healthy   bool    `json:"healthy"`
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
 type HealthHandler struct {
-	db          *mongo.Database
-	redisClient *redis.Client
+	checks   []dependencyCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	lastRun   time.Time
+	lastCheck map[string]DependencyStatus
 }
 
 type HealthResponse struct {
-	Status    string                 `json:"status"`
-	Timestamp time.Time              `json:"timestamp"`
-	Services  map[string]interface{} `json:"services"`
+	Status    string                      `json:"status"`
+	Timestamp time.Time                   `json:"timestamp"`
+	Services  map[string]DependencyStatus `json:"services"`
 }
 
+// NewHealthHandler creates a health handler checking MongoDB and, when configured, Redis
 func NewHealthHandler(db *mongo.Database, redisClient *redis.Client) *HealthHandler {
-	return &HealthHandler{
-		db:          db,
-		redisClient: redisClient,
+	h := &HealthHandler{
+		timeout:  defaultHealthCheckTimeout,
+		cacheTTL: defaultHealthCacheTTL,
+		now:      time.Now,
+	}
+
+	h.AddCheck("mongodb", true, func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("database client not initialized")
+		}
+		return db.Client().Ping(ctx, nil)
+	})
+
+	// Redis is optional: caching and rate limiting fall back to memory without it
+	if redisClient != nil {
+		h.AddCheck("redis", false, func(ctx context.Context) error {
+			status := redisClient.HealthCheck()
+			if !status.IsConnected {
+				if status.Error == "" {
+					return errors.New("redis not connected")
+				}
+				return errors.New(status.Error)
+			}
+			return nil
+		})
 	}
+
+	return h
+}
+
+// AddCheck registers a dependency check. Required dependencies must be healthy
+// for the instance to report ready; every dependency must be healthy for /health.
+func (h *HealthHandler) AddCheck(name string, required bool, check HealthCheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, dependencyCheck{name: name, required: required, check: check})
+	h.lastCheck = nil
 }
 
+// HealthCheck reports every dependency and returns 503 if any is unhealthy
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
-	response := HealthResponse{
-		Timestamp: time.Now(),
-		Services:  make(map[string]interface{}),
+	services := h.runChecks()
+
+	healthy := true
+	for _, status := range services {
+		if !status.Healthy {
+			healthy = false
+		}
 	}
+	h.respond(c, healthy, services)
+}
 
-	overallHealthy := true
+// Readiness reports every dependency but returns 503 only when a required
+// dependency is unhealthy, so degraded optional backends keep the instance in rotation
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	services := h.runChecks()
 
-	// Check MongoDB
-	mongoStatus := h.checkMongoDB()
-	response.Services["mongodb"] = mongoStatus
-	if !mongoStatus["healthy"].(bool) {
-		overallHealthy = false
+	ready := true
+	for _, status := range services {
+		if status.Required && !status.Healthy {
+			ready = false
+		}
 	}
+	h.respond(c, ready, services)
+}
 
-	// Check Redis
-	redisStatus := h.checkRedis()
-	response.Services["redis"] = redisStatus
-	if !redisStatus["healthy"].(bool) {
-		overallHealthy = false
+func (h *HealthHandler) respond(c *gin.Context, healthy bool, services map[string]DependencyStatus) {
+	response := HealthResponse{
+		Timestamp: h.now(),
+		Services:  services,
 	}
 
-	if overallHealthy {
+	if healthy {
 		response.Status = "healthy"
 		c.JSON(http.StatusOK, response)
 	} else {
@@ -59,54 +138,62 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 	}
 }
 
-func (h *HealthHandler) checkMongoDB() map[string]interface{} {
-	status := map[string]interface{}{
-		"service": "mongodb",
-		"healthy": false,
+// runChecks runs all checks concurrently, reusing results younger than the cache TTL
+func (h *HealthHandler) runChecks() map[string]DependencyStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.lastCheck != nil && h.now().Sub(h.lastRun) < h.cacheTTL {
+		return h.lastCheck
 	}
 
-	if h.db != nil {
-		// Simple ping to check MongoDB connectivity
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		
-		err := h.db.Client().Ping(ctx, nil)
-		if err == nil {
-			status["healthy"] = true
-			status["message"] = "Connected"
-		} else {
-			status["error"] = err.Error()
-		}
-	} else {
-		status["error"] = "Database client not initialized"
+	results := make(map[string]DependencyStatus, len(h.checks))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, dep := range h.checks {
+		wg.Add(1)
+		go func(dep dependencyCheck) {
+			defer wg.Done()
+			status := h.runCheck(dep)
+			resultsMu.Lock()
+			results[dep.name] = status
+			resultsMu.Unlock()
+		}(dep)
 	}
+	wg.Wait()
 
-	return status
+	h.lastRun = h.now()
+	h.lastCheck = results
+	return results
 }
 
-func (h *HealthHandler) checkRedis() map[string]interface{} {
-	status := map[string]interface{}{
-		"service": "redis",
-		"healthy": false,
+// runCheck runs a single check, giving up once the timeout expires even if the
+// check itself ignores its context
+func (h *HealthHandler) runCheck(dep dependencyCheck) DependencyStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- dep.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("health check timed out")
 	}
 
-	if h.redisClient != nil {
-		healthStatus := h.redisClient.HealthCheck()
-		status["healthy"] = healthStatus.IsConnected
-		status["connectionInfo"] = healthStatus.ConnectionInfo
-		status["responseTime"] = healthStatus.ResponseTime.String()
-		status["lastPing"] = healthStatus.LastPing
-
-		if healthStatus.Error != "" {
-			status["error"] = healthStatus.Error
-		}
-
-		// Add connection stats
-		stats := h.redisClient.GetConnectionStats()
-		status["connectionStats"] = stats
-	} else {
-		status["error"] = "Redis client not initialized"
+	status := DependencyStatus{
+		Healthy:   err == nil,
+		Required:  dep.required,
+		LatencyMs: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		status.Error = err.Error()
 	}
-
 	return status
-}
\ No newline at end of file
+}
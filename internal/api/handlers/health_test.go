@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		timeout:  50 * time.Millisecond,
+		cacheTTL: defaultHealthCacheTTL,
+		now:      time.Now,
+	}
+}
+
+func healthy(ctx context.Context) error { return nil }
+
+func probe(t *testing.T, handler gin.HandlerFunc, path string) (int, HealthResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(path, handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	router.ServeHTTP(w, req)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestHealthCheck_AllHealthy(t *testing.T) {
+	h := newTestHealthHandler()
+	h.AddCheck("mongodb", true, healthy)
+	h.AddCheck("redis", false, healthy)
+	h.AddCheck("rate_limiter", false, healthy)
+	h.AddCheck("websocket", true, healthy)
+
+	code, response := probe(t, h.HealthCheck, "/api/v1/health")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "healthy", response.Status)
+	require.Len(t, response.Services, 4)
+	for name, status := range response.Services {
+		assert.True(t, status.Healthy, name)
+		assert.Empty(t, status.Error, name)
+	}
+}
+
+func TestHealthCheck_OptionalDependencyDown(t *testing.T) {
+	h := newTestHealthHandler()
+	h.AddCheck("mongodb", true, healthy)
+	h.AddCheck("redis", false, func(ctx context.Context) error { return errors.New("connection refused") })
+
+	code, response := probe(t, h.HealthCheck, "/api/v1/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy", response.Status)
+	assert.True(t, response.Services["mongodb"].Healthy)
+	assert.False(t, response.Services["redis"].Healthy)
+	assert.Equal(t, "connection refused", response.Services["redis"].Error)
+
+	// Redis is optional, so the instance stays ready
+	code, response = probe(t, h.Readiness, "/api/v1/ready")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, response.Services["redis"].Healthy)
+}
+
+func TestHealthCheck_RequiredDependencyDown(t *testing.T) {
+	h := newTestHealthHandler()
+	h.AddCheck("mongodb", true, func(ctx context.Context) error { return errors.New("server selection timeout") })
+	h.AddCheck("websocket", true, healthy)
+
+	code, _ := probe(t, h.HealthCheck, "/api/v1/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	code, response := probe(t, h.Readiness, "/api/v1/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy", response.Status)
+}
+
+func TestHealthCheck_HungDependencyTimesOut(t *testing.T) {
+	h := newTestHealthHandler()
+	release := make(chan struct{})
+	defer close(release)
+	h.AddCheck("redis", false, func(ctx context.Context) error {
+		<-release // ignores its context
+		return nil
+	})
+
+	start := time.Now()
+	code, response := probe(t, h.HealthCheck, "/api/v1/health")
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "health check timed out", response.Services["redis"].Error)
+}
+
+func TestHealthCheck_CachesResultsWithinTTL(t *testing.T) {
+	h := newTestHealthHandler()
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	calls := 0
+	h.AddCheck("mongodb", true, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	probe(t, h.HealthCheck, "/api/v1/health")
+	probe(t, h.Readiness, "/api/v1/ready")
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(defaultHealthCacheTTL)
+	probe(t, h.HealthCheck, "/api/v1/health")
+	assert.Equal(t, 2, calls)
+}
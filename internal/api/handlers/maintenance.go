@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fleet-backend/internal/models"
 	"fleet-backend/internal/services"
 	"fleet-backend/pkg/utils"
 	"net/http"
@@ -36,7 +35,7 @@ func (h *MaintenanceHandler) CreateMaintenanceRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.maintenanceService.CreateMaintenanceRecord(&req)
+	record, err := h.maintenanceService.CreateMaintenanceRecord(c.Request.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create maintenance record", err)
 		return
@@ -52,43 +51,45 @@ func (h *MaintenanceHandler) GetMaintenanceRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.maintenanceService.GetMaintenanceRecord(id)
+	record, err := h.maintenanceService.GetMaintenanceRecord(c.Request.Context(), id)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Maintenance record not found", err)
+		respondError(c, err, "Failed to retrieve maintenance record")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Maintenance record retrieved successfully", record)
 }
 
+// GetMaintenanceRecords lists maintenance records, optionally for one vehicle,
+// a page at a time with the total count of matching records
 func (h *MaintenanceHandler) GetMaintenanceRecords(c *gin.Context) {
 	vehicleID := c.Query("vehicleId")
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		utils.CodedErrorResponse(c, http.StatusBadRequest, utils.CodeValidationFailed, "Invalid limit parameter", err)
+		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		offset = 0
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		utils.CodedErrorResponse(c, http.StatusBadRequest, utils.CodeValidationFailed, "Invalid offset parameter", err)
+		return
 	}
 
-	var records []*models.MaintenanceRecord
+	var page *services.MaintenanceRecordPage
 	if vehicleID != "" {
-		records, err = h.maintenanceService.GetMaintenanceRecordsByVehicle(vehicleID)
+		page, err = h.maintenanceService.GetMaintenanceRecordsByVehicle(c.Request.Context(), vehicleID, limit, offset)
 	} else {
-		records, err = h.maintenanceService.GetAllMaintenanceRecords(limit, offset)
+		page, err = h.maintenanceService.GetAllMaintenanceRecords(c.Request.Context(), limit, offset)
 	}
 
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve maintenance records", err)
+		respondError(c, err, "Failed to retrieve maintenance records")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Maintenance records retrieved successfully", records)
+	utils.SuccessResponse(c, http.StatusOK, "Maintenance records retrieved successfully", page)
 }
 
 func (h *MaintenanceHandler) UpdateMaintenanceRecord(c *gin.Context) {
@@ -104,9 +105,14 @@ func (h *MaintenanceHandler) UpdateMaintenanceRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.maintenanceService.UpdateMaintenanceRecord(id, &req)
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	record, err := h.maintenanceService.UpdateMaintenanceRecord(c.Request.Context(), id, &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update maintenance record", err)
+		respondError(c, err, "Failed to update maintenance record")
 		return
 	}
 
@@ -120,9 +126,9 @@ func (h *MaintenanceHandler) DeleteMaintenanceRecord(c *gin.Context) {
 		return
 	}
 
-	err := h.maintenanceService.DeleteMaintenanceRecord(id)
+	err := h.maintenanceService.DeleteMaintenanceRecord(c.Request.Context(), id)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete maintenance record", err)
+		respondError(c, err, "Failed to delete maintenance record")
 		return
 	}
 
@@ -142,7 +148,7 @@ func (h *MaintenanceHandler) CreateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.maintenanceService.CreateSchedule(&req)
+	schedule, err := h.maintenanceService.CreateSchedule(c.Request.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create maintenance schedule", err)
 		return
@@ -158,7 +164,7 @@ func (h *MaintenanceHandler) GetSchedulesByVehicle(c *gin.Context) {
 		return
 	}
 
-	schedules, err := h.maintenanceService.GetSchedulesByVehicle(vehicleID)
+	schedules, err := h.maintenanceService.GetSchedulesByVehicle(c.Request.Context(), vehicleID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve maintenance schedules", err)
 		return
@@ -183,7 +189,7 @@ func (h *MaintenanceHandler) GetUpcomingSchedules(c *gin.Context) {
 		days = 0
 	}
 
-	schedules, err := h.maintenanceService.GetUpcomingSchedules(days)
+	schedules, err := h.maintenanceService.GetUpcomingSchedules(c.Request.Context(), days)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve upcoming schedules", err)
 		return
@@ -193,7 +199,7 @@ func (h *MaintenanceHandler) GetUpcomingSchedules(c *gin.Context) {
 }
 
 func (h *MaintenanceHandler) GetAllSchedules(c *gin.Context) {
-	schedules, err := h.maintenanceService.GetAllSchedules()
+	schedules, err := h.maintenanceService.GetAllSchedules(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve schedules", err)
 		return
@@ -202,6 +208,17 @@ func (h *MaintenanceHandler) GetAllSchedules(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Schedules retrieved successfully", schedules)
 }
 
+// GetUnservicedSchedules lists active schedules with no record of ever being serviced
+func (h *MaintenanceHandler) GetUnservicedSchedules(c *gin.Context) {
+	schedules, err := h.maintenanceService.GetUnservicedSchedules(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve unserviced schedules", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unserviced schedules retrieved successfully", schedules)
+}
+
 func (h *MaintenanceHandler) GetSchedule(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -209,7 +226,7 @@ func (h *MaintenanceHandler) GetSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.maintenanceService.GetSchedule(id)
+	schedule, err := h.maintenanceService.GetSchedule(c.Request.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Schedule not found", err)
 		return
@@ -231,7 +248,12 @@ func (h *MaintenanceHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.maintenanceService.UpdateSchedule(id, &req)
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	schedule, err := h.maintenanceService.UpdateSchedule(c.Request.Context(), id, &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update schedule", err)
 		return
@@ -247,7 +269,7 @@ func (h *MaintenanceHandler) DeleteSchedule(c *gin.Context) {
 		return
 	}
 
-	err := h.maintenanceService.DeleteSchedule(id)
+	err := h.maintenanceService.DeleteSchedule(c.Request.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete schedule", err)
 		return
@@ -264,7 +286,7 @@ func (h *MaintenanceHandler) GetServiceReminders(c *gin.Context) {
 		return
 	}
 
-	reminders, err := h.maintenanceService.GetServiceReminders(vehicleID)
+	reminders, err := h.maintenanceService.GetServiceReminders(c.Request.Context(), vehicleID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve service reminders", err)
 		return
@@ -274,7 +296,7 @@ func (h *MaintenanceHandler) GetServiceReminders(c *gin.Context) {
 }
 
 func (h *MaintenanceHandler) GetOverdueReminders(c *gin.Context) {
-	reminders, err := h.maintenanceService.GetOverdueReminders()
+	reminders, err := h.maintenanceService.GetOverdueReminders(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve overdue reminders", err)
 		return
@@ -290,11 +312,46 @@ func (h *MaintenanceHandler) GetNextServiceDue(c *gin.Context) {
 		threshold = 2000
 	}
 
-	reminders, err := h.maintenanceService.GetNextServiceDue(threshold)
+	reminders, err := h.maintenanceService.GetNextServiceDue(c.Request.Context(), threshold)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles due for service", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Vehicles due for service retrieved successfully", reminders)
-}
\ No newline at end of file
+}
+
+// GetMaintenanceForecast lists vehicles predicted to reach their next service
+// within the requested number of days, using telemetry mileage where available
+func (h *MaintenanceHandler) GetMaintenanceForecast(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid days parameter", err)
+		return
+	}
+
+	forecasts, err := h.maintenanceService.GetMaintenanceForecast(c.Request.Context(), days)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to forecast maintenance", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Maintenance forecast retrieved successfully", forecasts)
+}
+
+// GetActiveWarranties lists the replaced parts of a vehicle still under warranty
+func (h *MaintenanceHandler) GetActiveWarranties(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	warranties, err := h.maintenanceService.GetActiveWarranties(c.Request.Context(), vehicleID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve warranties", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Active warranties retrieved successfully", warranties)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAttachment stores a receipt, photo or other document for a maintenance
+// record. The file is sent as multipart form data in the "file" field.
+func (h *MaintenanceHandler) UploadAttachment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Maintenance record ID is required", nil)
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself
+	if maxSize := h.maintenanceService.MaxAttachmentSize(); maxSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Attachment is too large", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "A file is required in the 'file' form field", err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read uploaded file", err)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.maintenanceService.AddAttachment(c.Request.Context(), id, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAttachmentsDisabled):
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "Maintenance attachments are not enabled", err)
+		case errors.Is(err, services.ErrAttachmentTooLarge):
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Attachment is too large", err)
+		default:
+			respondError(c, err, "Failed to upload attachment")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Attachment uploaded successfully", attachment)
+}
+
+// GetAttachments lists the attachments of a maintenance record
+func (h *MaintenanceHandler) GetAttachments(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Maintenance record ID is required", nil)
+		return
+	}
+
+	attachments, err := h.maintenanceService.GetAttachments(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve attachments")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attachments retrieved successfully", attachments)
+}
+
+// DownloadAttachment streams an attachment's contents
+func (h *MaintenanceHandler) DownloadAttachment(c *gin.Context) {
+	id := c.Param("id")
+	attachmentID := c.Param("attachmentId")
+	if id == "" || attachmentID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Maintenance record ID and attachment ID are required", nil)
+		return
+	}
+
+	attachment, content, err := h.maintenanceService.OpenAttachment(c.Request.Context(), id, attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAttachmentsDisabled):
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "Maintenance attachments are not enabled", err)
+		case errors.Is(err, services.ErrAttachmentNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, "Attachment not found", err)
+		default:
+			respondError(c, err, "Failed to download attachment")
+		}
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition": disposition,
+	})
+}
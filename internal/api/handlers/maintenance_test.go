@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/repository"
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newUnavailableMaintenanceHandler serves maintenance requests from a
+// database that can't be reached, so every repository call fails with an
+// error other than "not found"
+func newUnavailableMaintenanceHandler(t *testing.T) *gin.Engine {
+	t.Helper()
+	opts := options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(time.Minute)
+	client, err := mongo.Connect(context.Background(), opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	db := client.Database("fleet_test")
+
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	maintenanceRepo.SetTimeouts(repository.Timeouts{Read: 50 * time.Millisecond, Write: 50 * time.Millisecond})
+	handler := NewMaintenanceHandler(services.NewMaintenanceService(maintenanceRepo, repository.NewVehicleRepository(db)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/maintenance/records/:id", handler.GetMaintenanceRecord)
+	router.PUT("/maintenance/records/:id", handler.UpdateMaintenanceRecord)
+	router.DELETE("/maintenance/records/:id", handler.DeleteMaintenanceRecord)
+	router.GET("/maintenance/:id/attachments", handler.GetAttachments)
+	return router
+}
+
+func serveMaintenanceRequest(t *testing.T, router *gin.Engine, method, path, body string) (int, utils.APIResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var response utils.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestMaintenanceRecordHandlers_DatabaseFailureIsInternalError(t *testing.T) {
+	router := newUnavailableMaintenanceHandler(t)
+	path := "/maintenance/records/" + primitive.NewObjectID().Hex()
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		status, body := serveMaintenanceRequest(t, router, method, path, `{"notes":"checked"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, status, method)
+		assert.Equal(t, "internal_error", body.Code, method)
+	}
+}
+
+func TestMaintenanceRecordHandlers_InvalidIDIsNotFound(t *testing.T) {
+	router := newUnavailableMaintenanceHandler(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		status, body := serveMaintenanceRequest(t, router, method, "/maintenance/records/not-an-id", `{"notes":"checked"}`)
+
+		assert.Equal(t, http.StatusNotFound, status, method)
+		assert.Equal(t, "maintenance_record_not_found", body.Code, method)
+	}
+}
+
+func TestUpdateMaintenanceRecord_InvalidRequestIsBadRequest(t *testing.T) {
+	router := newUnavailableMaintenanceHandler(t)
+
+	status, _ := serveMaintenanceRequest(t, router, http.MethodPut, "/maintenance/records/"+primitive.NewObjectID().Hex(), `{"cost":"free"}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestGetAttachments_DistinguishesMissingRecordFromDatabaseFailure(t *testing.T) {
+	router := newUnavailableMaintenanceHandler(t)
+
+	status, body := serveMaintenanceRequest(t, router, http.MethodGet, "/maintenance/"+primitive.NewObjectID().Hex()+"/attachments", "")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "internal_error", body.Code)
+
+	status, body = serveMaintenanceRequest(t, router, http.MethodGet, "/maintenance/not-an-id/attachments", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "maintenance_record_not_found", body.Code)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fleet-backend/pkg/telemetry"
+	"fleet-backend/pkg/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errTooManySamples stops decoding a bulk request once it exceeds the sample limit
+var errTooManySamples = errors.New("too many telemetry samples")
+
+// maxReplayFileSize bounds an uploaded telemetry recording
+const maxReplayFileSize = 32 << 20
+
+type TelemetryHandler struct {
+	ingestor *telemetry.Ingestor
+	replayer *telemetry.TelemetryReplayer
+}
+
+func NewTelemetryHandler(ingestor *telemetry.Ingestor) *TelemetryHandler {
+	return &TelemetryHandler{
+		ingestor: ingestor,
+	}
+}
+
+// SetReplayer enables replaying recorded telemetry
+func (h *TelemetryHandler) SetReplayer(replayer *telemetry.TelemetryReplayer) {
+	h.replayer = replayer
+}
+
+// IngestBulk accepts telemetry samples for many vehicles and reports which were accepted.
+//
+// A request holds at most the ingestor's MaxBulkSamples samples (1000 by
+// default, TELEMETRY_MAX_BULK_SAMPLES); larger requests get 413 before the
+// rest of the body is read. Backfills beyond that limit are sent with
+// ?chunked=true: samples are then decoded and ingested BulkChunkSize at a
+// time (500 by default, TELEMETRY_BULK_CHUNK_SIZE) with no overall limit, and
+// the response lists only counts and the first rejections.
+func (h *TelemetryHandler) IngestBulk(c *gin.Context) {
+	if c.Query("chunked") == "true" {
+		h.ingestChunked(c)
+		return
+	}
+
+	maxSamples := h.ingestor.MaxBulkSamples()
+	var samples []telemetry.TelemetrySample
+	err := decodeSamples(c.Request.Body, func(sample telemetry.TelemetrySample) error {
+		if len(samples) == maxSamples {
+			return errTooManySamples
+		}
+		samples = append(samples, sample)
+		return nil
+	})
+	if errors.Is(err, errTooManySamples) {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("At most %d telemetry samples are accepted per request; send larger backfills with ?chunked=true", maxSamples), nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if len(samples) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "At least one telemetry sample is required", nil)
+		return
+	}
+
+	result := h.ingestor.IngestBulk(c.Request.Context(), samples)
+	utils.SuccessResponse(c, http.StatusOK, "Telemetry processed", result)
+}
+
+// ingestChunked ingests a backfill of any size while holding one chunk of samples in memory
+func (h *TelemetryHandler) ingestChunked(c *gin.Context) {
+	backfill := h.ingestor.NewBackfill()
+	chunk := make([]telemetry.TelemetrySample, 0, h.ingestor.BulkChunkSize())
+
+	err := decodeSamples(c.Request.Body, func(sample telemetry.TelemetrySample) error {
+		chunk = append(chunk, sample)
+		if len(chunk) == cap(chunk) {
+			backfill.Ingest(c.Request.Context(), chunk)
+			chunk = chunk[:0]
+		}
+		return nil
+	})
+	if err == nil && len(chunk) > 0 {
+		backfill.Ingest(c.Request.Context(), chunk)
+	}
+
+	// Chunks ingested before a malformed sample stay ingested, so report them
+	if err != nil {
+		if backfill.Samples() == 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.APIResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid request format after %d samples were processed", backfill.Samples()),
+			Data:    backfill.Result(),
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if backfill.Samples() == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "At least one telemetry sample is required", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Telemetry backfill processed", backfill.Result())
+}
+
+// ReplayTelemetry feeds an uploaded recording through the update pipeline in
+// the background. The recording is a multipart "file" field or the raw body,
+// in JSONL or CSV (?format=, defaulting to csv for text/csv uploads). ?speed=
+// is the time-compression factor: 60 plays a recorded minute each second,
+// 0 plays records back to back; 1 by default.
+func (h *TelemetryHandler) ReplayTelemetry(c *gin.Context) {
+	if h.replayer == nil {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Telemetry replay is not available", nil)
+		return
+	}
+
+	speed := 1.0
+	if val := c.Query("speed"); val != "" {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil || parsed < 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "speed must be a non-negative number", err)
+			return
+		}
+		speed = parsed
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxReplayFileSize)
+
+	var reader io.Reader = c.Request.Body
+	format := telemetry.ReplayFormatJSONL
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Recording file is required", err)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read recording file", err)
+			return
+		}
+		defer file.Close()
+		reader = file
+		if strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".csv") {
+			format = telemetry.ReplayFormatCSV
+		}
+	} else if c.ContentType() == "text/csv" {
+		format = telemetry.ReplayFormatCSV
+	}
+	if val := c.Query("format"); val != "" {
+		format = strings.ToLower(val)
+	}
+
+	records, err := telemetry.ReadReplayRecords(reader, format)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid telemetry recording", err)
+		return
+	}
+	if len(records) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "The recording holds no records", nil)
+		return
+	}
+
+	if err := h.replayer.Start(records, speed); err != nil {
+		if errors.Is(err, telemetry.ErrReplayInProgress) {
+			utils.ErrorResponse(c, http.StatusConflict, "A telemetry replay is already running", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start telemetry replay", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Telemetry replay started", gin.H{
+		"records": len(records),
+		"speed":   speed,
+	})
+}
+
+// decodeSamples reads a JSON array of samples one element at a time, passing
+// each to handle and stopping at the first error
+func decodeSamples(body io.Reader, handle func(telemetry.TelemetrySample) error) error {
+	decoder := json.NewDecoder(body)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return errors.New("expected a JSON array of telemetry samples")
+	}
+
+	for decoder.More() {
+		var sample telemetry.TelemetrySample
+		if err := decoder.Decode(&sample); err != nil {
+			return err
+		}
+		if err := handle(sample); err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token() // closing bracket
+	return err
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"fleet-backend/pkg/telemetry"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TelemetryOptimizer switches the telemetry service's optimizations
+type TelemetryOptimizer interface {
+	Optimizations() telemetry.Optimizations
+	SetAdaptiveSchedulingEnabled(enabled bool)
+	SetDeltaUpdatesEnabled(enabled bool)
+	SetRateLimitingEnabled(enabled bool)
+	SetBatchingEnabled(enabled bool)
+	SetDeduplicationEnabled(enabled bool)
+}
+
+// UpdateTelemetryOptimizationsRequest switches the optimizations it names;
+// the others are left as they are
+type UpdateTelemetryOptimizationsRequest struct {
+	AdaptiveScheduling *bool `json:"adaptiveScheduling,omitempty"`
+	DeltaUpdates       *bool `json:"deltaUpdates,omitempty"`
+	RateLimiting       *bool `json:"rateLimiting,omitempty"`
+	Batching           *bool `json:"batching,omitempty"`
+	Deduplication      *bool `json:"deduplication,omitempty"`
+}
+
+// TelemetryOptimizationsHandler lets operators switch telemetry optimizations
+// without a restart, e.g. to rule one out while it misbehaves
+type TelemetryOptimizationsHandler struct {
+	optimizer TelemetryOptimizer
+}
+
+func NewTelemetryOptimizationsHandler(optimizer TelemetryOptimizer) *TelemetryOptimizationsHandler {
+	return &TelemetryOptimizationsHandler{
+		optimizer: optimizer,
+	}
+}
+
+// GetOptimizations returns the optimizations currently enabled (admin only)
+func (h *TelemetryOptimizationsHandler) GetOptimizations(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Telemetry optimizations retrieved", h.optimizer.Optimizations())
+}
+
+// UpdateOptimizations switches the optimizations named in the request (admin
+// only). Changes last until the next restart.
+func (h *TelemetryOptimizationsHandler) UpdateOptimizations(c *gin.Context) {
+	var req UpdateTelemetryOptimizationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	for _, toggle := range []struct {
+		enabled *bool
+		set     func(bool)
+	}{
+		{req.AdaptiveScheduling, h.optimizer.SetAdaptiveSchedulingEnabled},
+		{req.DeltaUpdates, h.optimizer.SetDeltaUpdatesEnabled},
+		{req.RateLimiting, h.optimizer.SetRateLimitingEnabled},
+		{req.Batching, h.optimizer.SetBatchingEnabled},
+		{req.Deduplication, h.optimizer.SetDeduplicationEnabled},
+	} {
+		if toggle.enabled != nil {
+			toggle.set(*toggle.enabled)
+		}
+	}
+
+	optimizations := h.optimizer.Optimizations()
+	log.Printf("Telemetry optimizations changed by %s: %+v", c.GetString("user_id"), optimizations)
+	utils.SuccessResponse(c, http.StatusOK, "Telemetry optimizations updated", optimizations)
+}
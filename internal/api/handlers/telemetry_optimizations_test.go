@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fleet-backend/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryOptimizationsHandler_SwitchesNamedOptimizations(t *testing.T) {
+	service := telemetry.NewOptimizedTelemetryService(nil, nil)
+	handler := NewTelemetryOptimizationsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/telemetry/optimizations", handler.GetOptimizations)
+	router.PATCH("/admin/telemetry/optimizations", handler.UpdateOptimizations)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, "/admin/telemetry/optimizations", strings.NewReader(`{"deltaUpdates":false,"rateLimiting":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data telemetry.Optimizations `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	expected := telemetry.Optimizations{AdaptiveScheduling: true, Batching: true}
+	assert.Equal(t, expected, response.Data)
+	assert.Equal(t, expected, service.Optimizations())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/admin/telemetry/optimizations", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, expected, response.Data)
+}
+
+func TestTelemetryOptimizationsHandler_RejectsInvalidBody(t *testing.T) {
+	handler := NewTelemetryOptimizationsHandler(telemetry.NewOptimizedTelemetryService(nil, nil))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/admin/telemetry/optimizations", handler.UpdateOptimizations)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, "/admin/telemetry/optimizations", strings.NewReader(`{"batching":"off"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/cache"
+	"fleet-backend/pkg/telemetry"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TelemetryStatsSource reports the optimized telemetry service's counters
+type TelemetryStatsSource interface {
+	GetStats() telemetry.TelemetryStats
+}
+
+// BatchStatsSource reports batch processing statistics
+type BatchStatsSource interface {
+	GetBatchStats() batch.BatchStats
+}
+
+// CacheStatsSource reports cache statistics
+type CacheStatsSource interface {
+	GetCacheStats() cache.CacheStats
+}
+
+// TelemetryStatsResponse is the telemetry pipeline's statistics. Cache is
+// omitted when caching is disabled.
+type TelemetryStatsResponse struct {
+	Telemetry telemetry.TelemetryStats `json:"telemetry"`
+	Batch     batch.BatchStats         `json:"batch"`
+	Cache     *cache.CacheStats        `json:"cache,omitempty"`
+	// OptimizationEfficiency is the fraction of requested updates skipped
+	OptimizationEfficiency float64 `json:"optimizationEfficiency"`
+}
+
+// TelemetryStatsHandler exposes the counters otherwise only seen in the
+// telemetry service's periodic logs
+type TelemetryStatsHandler struct {
+	telemetry TelemetryStatsSource
+	batch     BatchStatsSource
+	cache     CacheStatsSource
+}
+
+func NewTelemetryStatsHandler(telemetry TelemetryStatsSource, batch BatchStatsSource) *TelemetryStatsHandler {
+	return &TelemetryStatsHandler{
+		telemetry: telemetry,
+		batch:     batch,
+	}
+}
+
+// SetCacheStatsSource includes cache statistics in the response
+func (h *TelemetryStatsHandler) SetCacheStatsSource(source CacheStatsSource) {
+	h.cache = source
+}
+
+// GetStats returns telemetry, batch and cache statistics
+func (h *TelemetryStatsHandler) GetStats(c *gin.Context) {
+	stats := h.telemetry.GetStats()
+	response := TelemetryStatsResponse{
+		Telemetry:              stats,
+		Batch:                  h.batch.GetBatchStats(),
+		OptimizationEfficiency: stats.OptimizationEfficiency(),
+	}
+	if h.cache != nil {
+		cacheStats := h.cache.GetCacheStats()
+		response.Cache = &cacheStats
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Telemetry statistics retrieved", response)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/cache"
+	"fleet-backend/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTelemetryStatsSource struct {
+	mock.Mock
+}
+
+func (m *MockTelemetryStatsSource) GetStats() telemetry.TelemetryStats {
+	args := m.Called()
+	return args.Get(0).(telemetry.TelemetryStats)
+}
+
+type stubCacheStats cache.CacheStats
+
+func (s stubCacheStats) GetCacheStats() cache.CacheStats {
+	return cache.CacheStats(s)
+}
+
+func getTelemetryStats(t *testing.T, handler *TelemetryStatsHandler) map[string]interface{} {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/telemetry/stats", handler.GetStats)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/telemetry/stats", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+func TestTelemetryStatsHandler_GetStats(t *testing.T) {
+	service := new(MockTelemetryStatsSource)
+	service.On("GetStats").Return(telemetry.TelemetryStats{
+		TotalUpdatesRequested: 200,
+		UpdatesSkipped:        150,
+		UpdatesSent:           50,
+		RateLimitRejects:      30,
+		DeltaSkips:            110,
+		DuplicatesDropped:     10,
+		ActiveVehicleCount:    7,
+	})
+	processor := new(MockBatchProcessor)
+	processor.On("GetBatchStats").Return(batch.BatchStats{BatchesProcessed: 4, TotalUpdates: 50, DeadLetters: 2})
+
+	handler := NewTelemetryStatsHandler(service, processor)
+	handler.SetCacheStatsSource(stubCacheStats{HitRate: 0.9, TotalHits: 90, TotalMisses: 10})
+	data := getTelemetryStats(t, handler)
+
+	assert.Equal(t, 0.75, data["optimizationEfficiency"])
+
+	stats := data["telemetry"].(map[string]interface{})
+	assert.Equal(t, float64(200), stats["totalUpdatesRequested"])
+	assert.Equal(t, float64(150), stats["updatesSkipped"])
+	assert.Equal(t, float64(50), stats["updatesSent"])
+	assert.Equal(t, float64(30), stats["rateLimitRejects"])
+	assert.Equal(t, float64(110), stats["deltaSkips"])
+	assert.Equal(t, float64(10), stats["duplicatesDropped"])
+	assert.Equal(t, float64(7), stats["activeVehicleCount"])
+
+	batchStats := data["batch"].(map[string]interface{})
+	assert.Equal(t, float64(4), batchStats["batchesProcessed"])
+	assert.Equal(t, float64(50), batchStats["totalUpdates"])
+	assert.Equal(t, float64(2), batchStats["deadLetters"])
+
+	cacheStats := data["cache"].(map[string]interface{})
+	assert.Equal(t, 0.9, cacheStats["hitRate"])
+	assert.Equal(t, float64(90), cacheStats["totalHits"])
+
+	service.AssertExpectations(t)
+	processor.AssertExpectations(t)
+}
+
+func TestTelemetryStatsHandler_GetStatsWithoutCacheOrUpdates(t *testing.T) {
+	service := new(MockTelemetryStatsSource)
+	service.On("GetStats").Return(telemetry.TelemetryStats{})
+	processor := new(MockBatchProcessor)
+	processor.On("GetBatchStats").Return(batch.BatchStats{})
+
+	data := getTelemetryStats(t, NewTelemetryStatsHandler(service, processor))
+
+	assert.Equal(t, float64(0), data["optimizationEfficiency"])
+	assert.NotContains(t, data, "cache")
+	assert.Contains(t, data, "batch")
+}
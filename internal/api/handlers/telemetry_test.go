@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubVehicleLookup map[string]bool
+
+func (s stubVehicleLookup) GetVehicleByID(_ context.Context, id string) (*models.Vehicle, error) {
+	if s[id] {
+		return &models.Vehicle{}, nil
+	}
+	return nil, errors.New("vehicle not found")
+}
+
+func setupTelemetryRouter(processor *MockBatchProcessor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ingestor := telemetry.NewIngestor(stubVehicleLookup{"v1": true}, processor)
+	handler := NewTelemetryHandler(ingestor)
+
+	router := gin.New()
+	router.POST("/telemetry/bulk", handler.IngestBulk)
+	return router
+}
+
+func TestIngestBulk_PerVehicleResults(t *testing.T) {
+	processor := new(MockBatchProcessor)
+	processor.On("AddUpdate", "v1", mock.Anything).Return(nil).Once()
+	router := setupTelemetryRouter(processor)
+
+	body := `[
+		{"vehicleId": "v1", "location": {"lat": -1.29, "lng": 36.82}, "speed": 40},
+		{"vehicleId": "v1", "location": {"lat": -1.29, "lng": 236.82}},
+		{"vehicleId": "unknown", "speed": 10}
+	]`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/telemetry/bulk", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data telemetry.BulkIngestResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, 1, response.Data.Accepted)
+	assert.Equal(t, 2, response.Data.Rejected)
+	require.Len(t, response.Data.Results, 3)
+	assert.True(t, response.Data.Results[0].Accepted)
+	assert.Equal(t, telemetry.RejectInvalidCoordinates, response.Data.Results[1].Reason)
+	assert.Equal(t, "unknown", response.Data.Results[2].VehicleID)
+	assert.Equal(t, telemetry.RejectUnknownVehicle, response.Data.Results[2].Reason)
+
+	processor.AssertExpectations(t)
+}
+
+func TestIngestBulk_EmptyRequest(t *testing.T) {
+	router := setupTelemetryRouter(new(MockBatchProcessor))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/telemetry/bulk", bytes.NewBufferString(`[]`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// sampleArray builds a JSON array of n valid samples for vehicle v1
+func sampleArray(n int) string {
+	var body strings.Builder
+	body.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		fmt.Fprintf(&body, `{"vehicleId": "v1", "speed": %d}`, i%100)
+	}
+	body.WriteString("]")
+	return body.String()
+}
+
+func TestIngestBulk_OverLimitRejected(t *testing.T) {
+	processor := new(MockBatchProcessor)
+	gin.SetMode(gin.TestMode)
+	ingestor := telemetry.NewIngestor(stubVehicleLookup{"v1": true}, processor)
+	ingestor.SetBulkLimits(10, 0)
+	router := gin.New()
+	router.POST("/telemetry/bulk", NewTelemetryHandler(ingestor).IngestBulk)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/telemetry/bulk", bytes.NewBufferString(sampleArray(11)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	processor.AssertNotCalled(t, "AddUpdate", mock.Anything, mock.Anything)
+}
+
+func TestIngestBulk_ChunkedBackfill(t *testing.T) {
+	processor := new(MockBatchProcessor)
+	processor.On("AddUpdate", "v1", mock.Anything).Return(nil)
+	gin.SetMode(gin.TestMode)
+	ingestor := telemetry.NewIngestor(stubVehicleLookup{"v1": true}, processor)
+	ingestor.SetBulkLimits(10, 100)
+	router := gin.New()
+	router.POST("/telemetry/bulk", NewTelemetryHandler(ingestor).IngestBulk)
+
+	// Far more samples than one request may hold, plus one rejection at the end
+	body := strings.TrimSuffix(sampleArray(1050), "]") + `,{"vehicleId": "ghost", "speed": 10}]`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/telemetry/bulk?chunked=true", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data telemetry.BackfillResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, 1050, response.Data.Accepted)
+	assert.Equal(t, 1, response.Data.Rejected)
+	assert.Equal(t, 11, response.Data.ChunksProcessed)
+	require.Len(t, response.Data.Rejections, 1)
+	assert.Equal(t, 1050, response.Data.Rejections[0].Index)
+	assert.Equal(t, telemetry.RejectUnknownVehicle, response.Data.Rejections[0].Reason)
+	processor.AssertNumberOfCalls(t, "AddUpdate", 1050)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnknownVehicleQueue holds vehicle IDs telemetry arrived for before the
+// vehicles were provisioned
+type UnknownVehicleQueue interface {
+	FindAll(ctx context.Context) ([]*models.UnknownVehicle, error)
+	Delete(ctx context.Context, vehicleID string) error
+}
+
+// VehicleProvisioner creates minimal vehicle records under given IDs
+type VehicleProvisioner interface {
+	ProvisionVehicle(ctx context.Context, id string) (*models.Vehicle, error)
+}
+
+// UnknownVehicleHandler lets operators work through the vehicle IDs queued by
+// telemetry ingestion in queue mode
+type UnknownVehicleHandler struct {
+	queue       UnknownVehicleQueue
+	provisioner VehicleProvisioner
+}
+
+func NewUnknownVehicleHandler(queue UnknownVehicleQueue, provisioner VehicleProvisioner) *UnknownVehicleHandler {
+	return &UnknownVehicleHandler{
+		queue:       queue,
+		provisioner: provisioner,
+	}
+}
+
+// GetUnknownVehicles lists the queued vehicle IDs, most recently reported first
+func (h *UnknownVehicleHandler) GetUnknownVehicles(c *gin.Context) {
+	vehicles, err := h.queue.FindAll(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve unknown vehicles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unknown vehicles retrieved successfully", vehicles)
+}
+
+// ProvisionUnknownVehicle creates a minimal vehicle record for a queued ID and
+// removes it from the queue. Telemetry for the ID is accepted from then on.
+func (h *UnknownVehicleHandler) ProvisionUnknownVehicle(c *gin.Context) {
+	id := c.Param("id")
+
+	vehicle, err := h.provisioner.ProvisionVehicle(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Failed to provision vehicle")
+		return
+	}
+
+	if err := h.queue.Delete(c.Request.Context(), id); err != nil {
+		// The vehicle exists, so later telemetry is accepted; the entry is only stale
+		log.Printf("Failed to remove provisioned vehicle %s from the unknown vehicle queue: %v", id, err)
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Vehicle provisioned successfully", vehicle)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -56,14 +57,58 @@ func (h *VehicleWebSocketHandler) HandleVehicleUpdates(c *gin.Context) {
 		filters.Drivers = drivers
 	}
 	
+	// Parse tags filter
+	if tags := c.QueryArray("tags"); len(tags) > 0 {
+		filters.Tags = tags
+	}
+	
 	// Parse alert types filter
 	if alertTypes := c.QueryArray("alertTypes"); len(alertTypes) > 0 {
 		filters.AlertTypes = alertTypes
 	}
 	
+	// Parse alert severities filter
+	if values := c.QueryArray("severities"); len(values) > 0 {
+		severities, err := websocket.ParseSeverities(values)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.Severities = severities
+	}
+	
+	// Parse map viewport filter
+	if bbox := c.Query("bbox"); bbox != "" {
+		box, err := websocket.ParseBoundingBox(bbox)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.BBox = box
+	}
+	
+	// Parse fields of interest, optionally trimming updates down to them
+	if values := c.QueryArray("fields"); len(values) > 0 {
+		fields, err := websocket.ParseFields(values)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.Fields = fields
+	}
+	filters.Compact = c.Query("compact") == "true"
+	filters.Maintenance = c.Query("maintenance") == "true"
+	
 	// Get the WebSocket manager from the handler
 	manager := h.wsManager.(*websocket.Manager)
 	
+	// Refuse before upgrading while the server is at its client limit
+	if manager.AtCapacity() {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many WebSocket clients, try again later"})
+		return
+	}
+	
 	// Upgrade the HTTP connection to WebSocket
 	conn, err := manager.GetUpgrader().Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -74,6 +119,11 @@ func (h *VehicleWebSocketHandler) HandleVehicleUpdates(c *gin.Context) {
 	
 	// Register the client with the WebSocket manager
 	err = h.wsManager.RegisterClient(clientID, conn, filters)
+	if errors.Is(err, websocket.ErrTooManyClients) {
+		// The client was sent a try-again-later close frame
+		conn.Close()
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to register WebSocket client: %v", err)
 		conn.Close()
@@ -74,11 +74,21 @@ func (m *MockBatchProcessor) AddUpdate(vehicleID string, update batch.VehicleUpd
 	return args.Error(0)
 }
 
+func (m *MockBatchProcessor) AddUpdates(updates map[string]batch.VehicleUpdateData) error {
+	args := m.Called(updates)
+	return args.Error(0)
+}
+
 func (m *MockBatchProcessor) ProcessBatch() error {
 	args := m.Called()
 	return args.Error(0)
 }
 
+func (m *MockBatchProcessor) Flush() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func (m *MockBatchProcessor) SetBatchSize(size int) {
 	m.Called(size)
 }
@@ -92,6 +102,11 @@ func (m *MockBatchProcessor) GetBatchStats() batch.BatchStats {
 	return args.Get(0).(batch.BatchStats)
 }
 
+func (m *MockBatchProcessor) QueuePressure() float64 {
+	args := m.Called()
+	return args.Get(0).(float64)
+}
+
 func (m *MockBatchProcessor) Start() error {
 	args := m.Called()
 	return args.Error(0)
@@ -570,6 +585,8 @@ func TestVehicleWebSocketHandler_HandleVehicleUpdates_Integration(t *testing.T)
 	q.Add("vehicleIds", "vehicle-456")
 	q.Add("statuses", "active")
 	q.Add("alertTypes", "fuel_theft")
+	q.Add("severities", "critical")
+	q.Add("bbox", "-1.45,36.65,-1.15,37.05")
 	u.RawQuery = q.Encode()
 	
 	// Connect to WebSocket
@@ -596,6 +613,13 @@ func TestVehicleWebSocketHandler_HandleVehicleUpdates_Integration(t *testing.T)
 	alertTypes := filters["alertTypes"].([]interface{})
 	assert.Contains(t, alertTypes, "fuel_theft")
 	
+	severities := filters["severities"].([]interface{})
+	assert.Contains(t, severities, "critical")
+	
+	bbox := filters["bbox"].(map[string]interface{})
+	assert.Equal(t, -1.45, bbox["minLat"])
+	assert.Equal(t, 37.05, bbox["maxLng"])
+	
 	// Test broadcasting an update
 	testUpdate := websocket.VehicleUpdate{
 		VehicleID:  "vehicle-123",
@@ -627,4 +651,17 @@ func TestVehicleWebSocketHandler_HandleVehicleUpdates_Integration(t *testing.T)
 	updateData := data["data"].(map[string]interface{})
 	assert.Equal(t, 40.7128, updateData["lat"])
 	assert.Equal(t, -74.0060, updateData["lng"])
-}
\ No newline at end of file
+}
+func TestVehicleWebSocketHandler_HandleVehicleUpdates_InvalidBBox(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	
+	handler := NewVehicleWebSocketHandler(websocket.NewManager(), new(MockBatchProcessor))
+	router := gin.New()
+	router.GET("/ws", handler.HandleVehicleUpdates)
+	
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ws?bbox=10,0,5,1", nil)
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
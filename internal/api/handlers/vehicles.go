@@ -1,14 +1,24 @@
 package handlers
 
 import (
+	"errors"
+	"fleet-backend/internal/models"
 	"fleet-backend/internal/services"
 	"fleet-backend/pkg/utils"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
+// maxVehicleImportSize caps the size of an uploaded vehicle CSV
+const maxVehicleImportSize = 10 << 20
+
 type VehicleHandler struct {
 	vehicleService *services.VehicleService
 	validator      *validator.Validate
@@ -21,15 +31,42 @@ func NewVehicleHandler(vehicleService *services.VehicleService) *VehicleHandler
 	}
 }
 
-// GetVehicles retrieves all vehicles
+// GetVehicles retrieves all vehicles, optionally filtered by one or more
+// statuses and by metadata, e.g. ?metadata.costCenter=ops
 func (h *VehicleHandler) GetVehicles(c *gin.Context) {
-	vehicles, err := h.vehicleService.GetAllVehicles()
+	var vehicles []*models.Vehicle
+	var err error
+	if metadata := metadataFilters(c); len(metadata) > 0 {
+		var statuses []string
+		if status := c.Query("status"); status != "" {
+			statuses = strings.Split(status, ",")
+		}
+		vehicles, err = h.vehicleService.GetVehiclesByMetadata(c.Request.Context(), metadata, statuses)
+	} else if status := c.Query("status"); status != "" {
+		// Comma-separated statuses match any of them, e.g. ?status=active,idle
+		vehicles, err = h.vehicleService.GetVehiclesByStatuses(c.Request.Context(), strings.Split(status, ","))
+	} else if c.Query("includeArchived") == "true" {
+		vehicles, err = h.vehicleService.GetAllVehiclesIncludingArchived(c.Request.Context())
+	} else {
+		vehicles, err = h.vehicleService.GetAllVehicles(c.Request.Context())
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles", err)
+		respondError(c, err, "Failed to retrieve vehicles")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", vehicles)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
+}
+
+// metadataFilters collects the metadata.<key>=value query parameters
+func metadataFilters(c *gin.Context) map[string]string {
+	metadata := make(map[string]string)
+	for param, values := range c.Request.URL.Query() {
+		if key, ok := strings.CutPrefix(param, "metadata."); ok && len(values) > 0 {
+			metadata[key] = values[0]
+		}
+	}
+	return metadata
 }
 
 // GetVehicle retrieves a specific vehicle by ID
@@ -40,13 +77,13 @@ func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 		return
 	}
 
-	vehicle, err := h.vehicleService.GetVehicleByID(vehicleID)
+	vehicle, err := h.vehicleService.GetVehicleByID(c.Request.Context(), vehicleID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Vehicle not found", err)
+		respondError(c, err, "Failed to retrieve vehicle")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicle retrieved successfully", vehicle)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle retrieved successfully", h.vehicleService.PresentVehicle(vehicle))
 }
 
 // CreateVehicle creates a new vehicle
@@ -62,13 +99,13 @@ func (h *VehicleHandler) CreateVehicle(c *gin.Context) {
 		return
 	}
 
-	vehicle, err := h.vehicleService.CreateVehicle(&req)
+	vehicle, err := h.vehicleService.CreateVehicle(c.Request.Context(), &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create vehicle", err)
+		respondError(c, err, "Failed to create vehicle")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Vehicle created successfully", vehicle)
+	utils.SuccessResponse(c, http.StatusCreated, "Vehicle created successfully", h.vehicleService.PresentVehicle(vehicle))
 }
 
 // UpdateVehicle updates an existing vehicle
@@ -90,13 +127,18 @@ func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
 		return
 	}
 
-	vehicle, err := h.vehicleService.UpdateVehicle(vehicleID, &req)
+	if req.ForceStatus && c.GetString("role") != "admin" {
+		utils.ErrorResponse(c, http.StatusForbidden, "Only admins can force a status change", nil)
+		return
+	}
+
+	vehicle, err := h.vehicleService.UpdateVehicle(c.Request.Context(), vehicleID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update vehicle", err)
+		respondError(c, err, "Failed to update vehicle")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicle updated successfully", vehicle)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle updated successfully", h.vehicleService.PresentVehicle(vehicle))
 }
 
 // DeleteVehicle deletes a vehicle
@@ -107,24 +149,168 @@ func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
 		return
 	}
 
-	err := h.vehicleService.DeleteVehicle(vehicleID)
+	err := h.vehicleService.DeleteVehicle(c.Request.Context(), vehicleID)
+	if err != nil {
+		respondError(c, err, "Failed to delete vehicle")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle archived successfully", nil)
+}
+
+// RestoreVehicle restores an archived vehicle
+func (h *VehicleHandler) RestoreVehicle(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	vehicle, err := h.vehicleService.RestoreVehicle(c.Request.Context(), vehicleID)
+	if err != nil {
+		respondError(c, err, "Failed to restore vehicle")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle restored successfully", h.vehicleService.PresentVehicle(vehicle))
+}
+
+// HardDeleteVehicle permanently deletes a vehicle (admin only)
+func (h *VehicleHandler) HardDeleteVehicle(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	err := h.vehicleService.HardDeleteVehicle(c.Request.Context(), vehicleID)
+	if err != nil {
+		respondError(c, err, "Failed to delete vehicle")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle permanently deleted", nil)
+}
+
+// GetDuplicatePlates lists plate numbers shared by more than one vehicle (admin only)
+func (h *VehicleHandler) GetDuplicatePlates(c *gin.Context) {
+	duplicates, err := h.vehicleService.FindDuplicatePlates(c.Request.Context())
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete vehicle", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check for duplicate plate numbers", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicle deleted successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Duplicate plate numbers retrieved successfully", gin.H{
+		"duplicates": duplicates,
+		"count":      len(duplicates),
+	})
+}
+
+// BatchGetVehicles returns the current state of a list of vehicles
+func (h *VehicleHandler) BatchGetVehicles(c *gin.Context) {
+	var req services.BatchGetVehiclesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.vehicleService.GetVehiclesByIDs(c.Request.Context(), req.IDs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles", err)
+		return
+	}
+
+	result.Vehicles = h.vehicleService.PresentVehicles(result.Vehicles)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", result)
+}
+
+// SearchVehicles finds vehicles by partial name, plate number, VIN or driver
+func (h *VehicleHandler) SearchVehicles(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search query parameter 'q' is required", nil)
+		return
+	}
+
+	limit := services.DefaultVehicleSearchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid limit parameter", err)
+			return
+		}
+		limit = parsed
+	}
+
+	vehicles, err := h.vehicleService.SearchVehicles(c.Request.Context(), query, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search vehicles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
+}
+
+// ImportVehicles creates vehicles from an uploaded CSV file, either as a
+// multipart "file" field or as a raw text/csv request body
+func (h *VehicleHandler) ImportVehicles(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxVehicleImportSize)
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "CSV file is required", err)
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read CSV file", err)
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	report, err := h.vehicleService.ImportVehiclesCSV(c.Request.Context(), reader)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to import vehicles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle import completed", report)
+}
+
+// ExportVehicles streams all vehicles as a CSV or JSON download
+func (h *VehicleHandler) ExportVehicles(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", services.ExportFormatCSV))
+	if err := services.ValidateExportFormat(format); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid export format", err)
+		return
+	}
+
+	setExportHeaders(c, "vehicles", format)
+	if err := h.vehicleService.ExportVehicles(c.Request.Context(), c.Writer, format); err != nil {
+		// Headers are already sent, so the truncated download is all the client will see
+		log.Printf("Vehicle export failed: %v", err)
+	}
 }
 
 // GetVehicleUpdates retrieves real-time vehicle updates
 func (h *VehicleHandler) GetVehicleUpdates(c *gin.Context) {
-	vehicles, err := h.vehicleService.GetVehicleUpdates()
+	vehicles, err := h.vehicleService.GetVehicleUpdates(c.Request.Context())
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicle updates", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicle updates retrieved successfully", vehicles)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle updates retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
 }
 
 // GetVehiclesByStatus retrieves vehicles by status
@@ -135,13 +321,13 @@ func (h *VehicleHandler) GetVehiclesByStatus(c *gin.Context) {
 		return
 	}
 
-	vehicles, err := h.vehicleService.GetVehiclesByStatus(status)
+	vehicles, err := h.vehicleService.GetVehiclesByStatus(c.Request.Context(), status)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", vehicles)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
 }
 
 // GetVehiclesByDriver retrieves vehicles by driver
@@ -152,13 +338,52 @@ func (h *VehicleHandler) GetVehiclesByDriver(c *gin.Context) {
 		return
 	}
 
-	vehicles, err := h.vehicleService.GetVehiclesByDriver(driver)
+	vehicles, err := h.vehicleService.GetVehiclesByDriver(c.Request.Context(), driver)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", vehicles)
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
+}
+
+// GetVehiclesByTag retrieves the vehicles carrying a tag
+func (h *VehicleHandler) GetVehiclesByTag(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Tag is required", nil)
+		return
+	}
+
+	vehicles, err := h.vehicleService.GetVehiclesByTag(c.Request.Context(), tag)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve vehicles")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
+}
+
+// GetSilentVehicles lists the vehicles that haven't reported for the number of
+// minutes given by the minutes query parameter, the longest silent first
+func (h *VehicleHandler) GetSilentVehicles(c *gin.Context) {
+	window := services.DefaultSilentWindow
+	if value := c.Query("minutes"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid minutes parameter, expected a positive integer", err)
+			return
+		}
+		window = time.Duration(minutes) * time.Minute
+	}
+
+	vehicles, err := h.vehicleService.GetSilentVehicles(c.Request.Context(), window)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve silent vehicles")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Silent vehicles retrieved successfully", h.vehicleService.PresentVehicles(vehicles))
 }
 
 // UpdateVehicleLocation updates a vehicle's location
@@ -231,4 +456,292 @@ func (h *VehicleHandler) UpdateVehicleFuelLevel(c *gin.Context) {
 	// }
 
 	utils.SuccessResponse(c, http.StatusOK, "Vehicle fuel level updated successfully", nil)
-}
\ No newline at end of file
+}
+// GetIdleStats returns the time a vehicle spent idling between the RFC3339
+// from and to query parameters; the range defaults to the last 24 hours
+func (h *VehicleHandler) GetIdleStats(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to parameter, expected RFC3339", err)
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from parameter, expected RFC3339", err)
+			return
+		}
+		from = parsed
+	}
+
+	stats, err := h.vehicleService.GetIdleStats(c.Request.Context(), vehicleID, from, to)
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "Idle stats retrieved successfully", stats)
+	case errors.Is(err, services.ErrInvalidIdleRange):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid time range", err)
+	case errors.Is(err, services.ErrIdleTrackingDisabled):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Idle tracking is not enabled", err)
+	default:
+		utils.ErrorResponse(c, http.StatusNotFound, "Vehicle not found", err)
+	}
+}
+
+// GetSpeedStats returns a vehicle's speed distribution and time over the limit
+// between from and to (RFC3339), defaulting to the last 24 hours; the range
+// may span at most 7 days
+func (h *VehicleHandler) GetSpeedStats(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to parameter, expected RFC3339", err)
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from parameter, expected RFC3339", err)
+			return
+		}
+		from = parsed
+	}
+
+	stats, err := h.vehicleService.GetSpeedStats(c.Request.Context(), vehicleID, from, to)
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "Speed stats retrieved successfully", stats)
+	case errors.Is(err, services.ErrInvalidSpeedRange), errors.Is(err, services.ErrSpeedRangeTooLong):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid time range", err)
+	case errors.Is(err, services.ErrSpeedHistoryDisabled):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Speed history is not enabled", err)
+	default:
+		utils.ErrorResponse(c, http.StatusNotFound, "Vehicle not found", err)
+	}
+}
+
+// GetUtilizationReport returns the share of time each vehicle spent active,
+// idle, in maintenance, offline or without status data between the RFC3339
+// from and to query parameters, with a fleet rollup; the range defaults to
+// the last 24 hours and may span at most 31 days
+func (h *VehicleHandler) GetUtilizationReport(c *gin.Context) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to parameter, expected RFC3339", err)
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from parameter, expected RFC3339", err)
+			return
+		}
+		from = parsed
+	}
+
+	report, err := h.vehicleService.GetUtilizationReport(c.Request.Context(), from, to)
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "Utilization report generated successfully", report)
+	case errors.Is(err, services.ErrUtilizationTrackingDisabled):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Utilization tracking is not enabled", err)
+	default:
+		respondError(c, err, "Failed to generate utilization report")
+	}
+}
+
+// GetETA estimates when a vehicle reaches the destination given by the lat and
+// lng query parameters, by road when a routing provider is configured and from
+// the straight-line distance otherwise.
+func (h *VehicleHandler) GetETA(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr != nil || lngErr != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Numeric lat and lng query parameters are required", nil)
+		return
+	}
+
+	estimate, err := h.vehicleService.EstimateArrival(c.Request.Context(), vehicleID, models.Location{Lat: lat, Lng: lng})
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "ETA estimated successfully", estimate)
+	case errors.Is(err, services.ErrInvalidDestination):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid destination", err)
+	default:
+		utils.ErrorResponse(c, http.StatusNotFound, "Vehicle not found", err)
+	}
+}
+
+// SetAlertRouting overrides which webhook subscriptions receive a vehicle's alerts
+func (h *VehicleHandler) SetAlertRouting(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	var req services.AlertRoutingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	vehicle, err := h.vehicleService.SetAlertRouting(c.Request.Context(), vehicleID, &req)
+	switch {
+	case err == nil:
+		utils.SuccessResponse(c, http.StatusOK, "Alert routing updated successfully", h.vehicleService.PresentVehicle(vehicle))
+	case errors.Is(err, services.ErrUnknownAlertTarget):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown alert routing target", err)
+	case errors.Is(err, services.ErrAlertRoutingUnavailable):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Alert routing is not enabled", err)
+	default:
+		respondError(c, err, "Failed to update alert routing")
+	}
+}
+
+// ClearAlertRouting returns a vehicle's alerts to the fleet-wide routing
+func (h *VehicleHandler) ClearAlertRouting(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	vehicle, err := h.vehicleService.ClearAlertRouting(c.Request.Context(), vehicleID)
+	if err != nil {
+		respondError(c, err, "Failed to clear alert routing")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Alert routing cleared successfully", h.vehicleService.PresentVehicle(vehicle))
+}
+
+// ReplaceMetadata replaces all of a vehicle's custom metadata
+func (h *VehicleHandler) ReplaceMetadata(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	var req services.ReplaceMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	vehicle, err := h.vehicleService.ReplaceMetadata(c.Request.Context(), vehicleID, &req)
+	h.respondMetadata(c, vehicle, err, "Vehicle metadata updated successfully")
+}
+
+// PatchMetadata sets or removes individual metadata keys of a vehicle
+func (h *VehicleHandler) PatchMetadata(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	var req services.PatchMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	vehicle, err := h.vehicleService.PatchMetadata(c.Request.Context(), vehicleID, &req)
+	h.respondMetadata(c, vehicle, err, "Vehicle metadata updated successfully")
+}
+
+// ClearMetadata removes all of a vehicle's custom metadata
+func (h *VehicleHandler) ClearMetadata(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	vehicle, err := h.vehicleService.ClearMetadata(c.Request.Context(), vehicleID)
+	h.respondMetadata(c, vehicle, err, "Vehicle metadata cleared successfully")
+}
+
+// SetTags replaces all of a vehicle's tags
+func (h *VehicleHandler) SetTags(c *gin.Context) {
+	vehicleID := c.Param("id")
+	if vehicleID == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Vehicle ID is required", nil)
+		return
+	}
+
+	var req services.SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	vehicle, err := h.vehicleService.SetTags(c.Request.Context(), vehicleID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update vehicle tags")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vehicle tags updated successfully", h.vehicleService.PresentVehicle(vehicle))
+}
+
+func (h *VehicleHandler) respondMetadata(c *gin.Context, vehicle *models.Vehicle, err error, message string) {
+	if err != nil {
+		respondError(c, err, "Failed to update vehicle metadata")
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, h.vehicleService.PresentVehicle(vehicle))
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	validator      *validator.Validate
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator.New(),
+	}
+}
+
+// CreateWebhook subscribes a URL to a set of event types
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req services.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.CreateWebhook(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create webhook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Webhook created successfully", subscription)
+}
+
+// GetWebhooks retrieves all webhook subscriptions
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhookService.GetAllWebhooks()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve webhooks", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhooks retrieved successfully", subscriptions)
+}
+
+// GetWebhook retrieves a specific webhook subscription by ID
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	subscription, err := h.webhookService.GetWebhook(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Webhook not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook retrieved successfully", subscription)
+}
+
+// UpdateWebhook changes a subscription's URL, event types, secret, active flag or delivery settings
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var req services.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateWebhook(c.Param("id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update webhook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook updated successfully", subscription)
+}
+
+// DeleteWebhook deletes a webhook subscription
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhookService.DeleteWebhook(c.Param("id")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete webhook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook deleted successfully", nil)
+}
+
+// TestWebhook sends a test event to a subscription and reports the delivery outcome
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	result, err := h.webhookService.TestDelivery(c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrWebhookTargetNotAllowed):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Webhook URL must resolve to a public address", err)
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusNotFound, "Webhook not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Test delivery completed", result)
+}
+
+// GetWebhookStats returns a subscription's delivery queue depth and outcome counters
+func (h *WebhookHandler) GetWebhookStats(c *gin.Context) {
+	stats, err := h.webhookService.GetDeliveryStats(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Webhook not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook delivery stats retrieved successfully", stats)
+}
@@ -1,20 +1,25 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"fleet-backend/internal/websocket"
 	"fleet-backend/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	gorillaws "github.com/gorilla/websocket"
 )
 
 // WebSocketHandler handles WebSocket connections for real-time updates
 type WebSocketHandler struct {
-	manager websocket.WebSocketManager
+	manager   websocket.WebSocketManager
+	snapshots *SnapshotThrottle
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
@@ -24,6 +29,12 @@ func NewWebSocketHandler(manager websocket.WebSocketManager) *WebSocketHandler {
 	}
 }
 
+// SetSnapshotThrottle lets clients connecting with snapshot=true receive the
+// current fleet before live updates
+func (h *WebSocketHandler) SetSnapshotThrottle(snapshots *SnapshotThrottle) {
+	h.snapshots = snapshots
+}
+
 // HandleWebSocket upgrades HTTP connections to WebSocket for real-time vehicle updates
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Validate JWT token from query parameter or Authorization header
@@ -43,7 +54,7 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	
 	// Validate the JWT token
 	jwtUtil := jwt.NewJWTUtil()
-	_, err := jwtUtil.ValidateToken(token)
+	claims, err := jwtUtil.ValidateToken(token)
 	if err != nil {
 		log.Printf("WebSocket connection rejected: invalid token - %v", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
@@ -71,14 +82,58 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		filters.Drivers = drivers
 	}
 	
+	// Parse tags filter
+	if tags := c.QueryArray("tags"); len(tags) > 0 {
+		filters.Tags = tags
+	}
+	
 	// Parse alert types filter
 	if alertTypes := c.QueryArray("alertTypes"); len(alertTypes) > 0 {
 		filters.AlertTypes = alertTypes
 	}
 	
+	// Parse alert severities filter
+	if values := c.QueryArray("severities"); len(values) > 0 {
+		severities, err := websocket.ParseSeverities(values)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.Severities = severities
+	}
+	
+	// Parse map viewport filter
+	if bbox := c.Query("bbox"); bbox != "" {
+		box, err := websocket.ParseBoundingBox(bbox)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.BBox = box
+	}
+	
+	// Parse fields of interest, optionally trimming updates down to them
+	if values := c.QueryArray("fields"); len(values) > 0 {
+		fields, err := websocket.ParseFields(values)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters.Fields = fields
+	}
+	filters.Compact = c.Query("compact") == "true"
+	filters.Maintenance = c.Query("maintenance") == "true"
+	
 	// Get the WebSocket manager from the handler
 	manager := h.manager.(*websocket.Manager)
 	
+	// Refuse before upgrading while the server is at its client limit
+	if manager.AtCapacity() {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many WebSocket clients, try again later"})
+		return
+	}
+	
 	// Upgrade the HTTP connection to WebSocket
 	conn, err := manager.GetUpgrader().Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -87,8 +142,23 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 	
+	// The snapshot is written before the client is registered, since only the
+	// client's writer may write to the connection once it is
+	if c.Query("snapshot") == "true" && h.snapshots != nil {
+		identity := claims.UserID
+		if identity == "" {
+			identity = c.ClientIP()
+		}
+		h.sendSnapshot(c.Request.Context(), clientID, identity, conn, filters)
+	}
+	
 	// Register the client with the WebSocket manager
 	err = h.manager.RegisterClient(clientID, conn, filters)
+	if errors.Is(err, websocket.ErrTooManyClients) {
+		// The client was sent a try-again-later close frame
+		conn.Close()
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to register WebSocket client: %v", err)
 		conn.Close()
@@ -99,6 +169,27 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	log.Printf("WebSocket client %s connected with filters: %+v", clientID, filters)
 }
 
+// sendSnapshot writes the vehicles matching a client's filters to its connection
+func (h *WebSocketHandler) sendSnapshot(ctx context.Context, clientID, identity string, conn *gorillaws.Conn, filters websocket.VehicleFilters) {
+	vehicles, cached, err := h.snapshots.Snapshot(ctx, identity)
+	if err != nil {
+		log.Printf("Failed to load snapshot for WebSocket client %s: %v", clientID, err)
+		return
+	}
+	
+	vehicles = filterSnapshot(vehicles, filters)
+	message := gin.H{
+		"type":      "snapshot",
+		"vehicles":  vehicles,
+		"count":     len(vehicles),
+		"cached":    cached,
+		"timestamp": time.Now(),
+	}
+	if err := conn.WriteJSON(message); err != nil {
+		log.Printf("Failed to send snapshot to WebSocket client %s: %v", clientID, err)
+	}
+}
+
 // GetConnectedClients returns the number of connected WebSocket clients
 func (h *WebSocketHandler) GetConnectedClients(c *gin.Context) {
 	count := h.manager.GetConnectedClients()
@@ -127,6 +218,21 @@ func (h *WebSocketHandler) BroadcastUpdate(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Update broadcasted successfully"})
 }
 
+// GetBroadcastHealth returns the health of the WebSocket broadcast pipeline
+func (h *WebSocketHandler) GetBroadcastHealth(c *gin.Context) {
+	provider, ok := h.manager.(interface {
+		GetBroadcastHealth() websocket.BroadcastHealth
+	})
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Broadcast health is not available"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"health": provider.GetBroadcastHealth(),
+	})
+}
+
 // DisconnectClient allows manual disconnection of a client (for admin purposes)
 func (h *WebSocketHandler) DisconnectClient(c *gin.Context) {
 	clientID := c.Param("clientId")
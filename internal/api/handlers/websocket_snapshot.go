@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
+	"fleet-backend/pkg/ratelimit"
+)
+
+// snapshotEndpoint identifies snapshot generation to the rate limiter
+const snapshotEndpoint = "ws_snapshot"
+
+// SnapshotSource loads the fleet sent to WebSocket clients that ask for a snapshot on connect
+type SnapshotSource interface {
+	GetAllVehicles(ctx context.Context) ([]*models.Vehicle, error)
+}
+
+// fleetSnapshot is the fleet state last generated for a client identity
+type fleetSnapshot struct {
+	vehicles []*models.Vehicle
+	takenAt  time.Time
+}
+
+// SnapshotThrottle generates fleet snapshots for WebSocket clients, at most
+// once per interval per identity. Within the interval the identity's last
+// snapshot is served again, so a client reconnecting in a loop can't make
+// every connect load the whole fleet.
+type SnapshotThrottle struct {
+	source   SnapshotSource
+	limiter  ratelimit.RateLimiter
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]*fleetSnapshot
+}
+
+// NewSnapshotThrottle creates a snapshot throttle. The limiter should allow
+// one snapshot per interval, as configured by SnapshotRateLimit.
+func NewSnapshotThrottle(source SnapshotSource, limiter ratelimit.RateLimiter, interval time.Duration) *SnapshotThrottle {
+	return &SnapshotThrottle{
+		source:   source,
+		limiter:  limiter,
+		interval: interval,
+		last:     make(map[string]*fleetSnapshot),
+	}
+}
+
+// SnapshotRateLimit returns the rate limit allowing one snapshot per interval.
+// The in-memory limiter refills per minute, so it allows at least one
+// snapshot a minute whatever the interval.
+func SnapshotRateLimit(interval time.Duration) ratelimit.RateLimit {
+	perMinute := 1
+	if interval > 0 && interval < time.Minute {
+		perMinute = int(time.Minute / interval)
+	}
+	return ratelimit.RateLimit{
+		RequestsPerMinute: perMinute,
+		BurstSize:         1,
+		WindowSize:        interval,
+	}
+}
+
+// Snapshot returns the fleet for identity and whether it is a snapshot
+// generated earlier within the interval
+func (t *SnapshotThrottle) Snapshot(ctx context.Context, identity string) ([]*models.Vehicle, bool, error) {
+	allowed, _, err := t.limiter.Allow(identity, snapshotEndpoint)
+	if err != nil {
+		// Fail open, as the API rate limiter does
+		log.Printf("Snapshot rate limiter unavailable for %s: %v", identity, err)
+		allowed = true
+	}
+
+	t.mu.Lock()
+	previous := t.last[identity]
+	t.mu.Unlock()
+
+	// Another replica may have served the snapshot allowed in this window, in
+	// which case there is nothing to reuse here
+	if !allowed && previous != nil {
+		return previous.vehicles, true, nil
+	}
+
+	vehicles, err := t.source.GetAllVehicles(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	t.last[identity] = &fleetSnapshot{vehicles: vehicles, takenAt: now}
+	// Snapshots past the interval won't be reused, since the limiter allows a new one
+	for id, snapshot := range t.last {
+		if now.Sub(snapshot.takenAt) > t.interval {
+			delete(t.last, id)
+		}
+	}
+	t.mu.Unlock()
+
+	return vehicles, false, nil
+}
+
+// filterSnapshot keeps the vehicles a client's subscription filters match
+func filterSnapshot(vehicles []*models.Vehicle, filters websocket.VehicleFilters) []*models.Vehicle {
+	matched := make([]*models.Vehicle, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		if snapshotMatches(vehicle, filters) {
+			matched = append(matched, vehicle)
+		}
+	}
+	return matched
+}
+
+func snapshotMatches(vehicle *models.Vehicle, filters websocket.VehicleFilters) bool {
+	if len(filters.VehicleIDs) > 0 && !containsString(filters.VehicleIDs, vehicle.ID.Hex()) {
+		return false
+	}
+	if len(filters.Statuses) > 0 && !containsString(filters.Statuses, vehicle.Status) {
+		return false
+	}
+	if len(filters.Drivers) > 0 && !containsString(filters.Drivers, vehicle.Driver) {
+		return false
+	}
+	if len(filters.Tags) > 0 && !websocket.HasAnyTag(vehicle.Tags, filters.Tags) {
+		return false
+	}
+	if filters.BBox != nil && !filters.BBox.Contains(vehicle.Location.Lat, vehicle.Location.Lng) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
+	"fleet-backend/pkg/jwt"
+	"fleet-backend/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingSnapshotSource serves a fixed fleet and counts how often it is loaded
+type countingSnapshotSource struct {
+	vehicles []*models.Vehicle
+	loads    int32
+}
+
+func (s *countingSnapshotSource) GetAllVehicles(_ context.Context) ([]*models.Vehicle, error) {
+	atomic.AddInt32(&s.loads, 1)
+	return s.vehicles, nil
+}
+
+func newTestSnapshotThrottle(source SnapshotSource, interval time.Duration) *SnapshotThrottle {
+	limiter := ratelimit.NewMemoryRateLimiter(&ratelimit.Config{
+		DefaultLimits:   map[string]ratelimit.RateLimit{"default": SnapshotRateLimit(interval)},
+		CleanupInterval: time.Minute,
+		Enabled:         true,
+	})
+	return NewSnapshotThrottle(source, limiter, interval)
+}
+
+func TestSnapshotThrottle_RapidRequestsReuseSnapshot(t *testing.T) {
+	source := &countingSnapshotSource{vehicles: []*models.Vehicle{{ID: primitive.NewObjectID(), Name: "Truck 1"}}}
+	throttle := newTestSnapshotThrottle(source, 30*time.Second)
+
+	vehicles, cached, err := throttle.Snapshot(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Len(t, vehicles, 1)
+
+	for i := 0; i < 5; i++ {
+		vehicles, cached, err = throttle.Snapshot(context.Background(), "user-1")
+		require.NoError(t, err)
+		assert.True(t, cached)
+		assert.Len(t, vehicles, 1)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&source.loads))
+
+	// Each identity has its own interval
+	_, cached, err = throttle.Snapshot(context.Background(), "user-2")
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&source.loads))
+}
+
+func TestSnapshotThrottle_RecomputesAfterInterval(t *testing.T) {
+	source := &countingSnapshotSource{}
+	throttle := newTestSnapshotThrottle(source, 100*time.Millisecond)
+
+	_, _, err := throttle.Snapshot(context.Background(), "user-1")
+	require.NoError(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, cached, err := throttle.Snapshot(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&source.loads))
+}
+
+func TestFilterSnapshot(t *testing.T) {
+	truck := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Driver: "alice", Location: models.Location{Lat: 1, Lng: 36}}
+	van := &models.Vehicle{ID: primitive.NewObjectID(), Status: "idle", Driver: "bob", Location: models.Location{Lat: 50, Lng: 0}, Tags: []string{"depot-north"}}
+	fleet := []*models.Vehicle{truck, van}
+
+	assert.Len(t, filterSnapshot(fleet, websocket.VehicleFilters{}), 2)
+	assert.Equal(t, []*models.Vehicle{truck}, filterSnapshot(fleet, websocket.VehicleFilters{Statuses: []string{"active"}}))
+	assert.Equal(t, []*models.Vehicle{van}, filterSnapshot(fleet, websocket.VehicleFilters{Drivers: []string{"bob"}}))
+	assert.Equal(t, []*models.Vehicle{van}, filterSnapshot(fleet, websocket.VehicleFilters{VehicleIDs: []string{van.ID.Hex()}}))
+	assert.Equal(t, []*models.Vehicle{truck}, filterSnapshot(fleet, websocket.VehicleFilters{
+		BBox: &websocket.BoundingBox{MinLat: -5, MinLng: 30, MaxLat: 5, MaxLng: 40},
+	}))
+	assert.Equal(t, []*models.Vehicle{van}, filterSnapshot(fleet, websocket.VehicleFilters{Tags: []string{"depot-north"}}))
+}
+
+func TestHandleWebSocket_ReconnectsReuseCachedSnapshot(t *testing.T) {
+	manager := websocket.NewManager()
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+
+	source := &countingSnapshotSource{vehicles: []*models.Vehicle{
+		{ID: primitive.NewObjectID(), Name: "Truck 1", Status: "active"},
+		{ID: primitive.NewObjectID(), Name: "Truck 2", Status: "idle"},
+	}}
+	handler := NewWebSocketHandler(manager)
+	handler.SetSnapshotThrottle(newTestSnapshotThrottle(source, time.Minute))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", handler.HandleWebSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	token, err := jwt.NewJWTUtil().GenerateToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?snapshot=true&statuses=active&token=" + token
+
+	for i := 0; i < 3; i++ {
+		conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+
+		var message map[string]interface{}
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		require.NoError(t, conn.ReadJSON(&message))
+		conn.Close()
+
+		assert.Equal(t, "snapshot", message["type"])
+		assert.Equal(t, float64(1), message["count"], "snapshot is filtered by the subscription")
+		assert.Equal(t, i > 0, message["cached"])
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&source.loads))
+}
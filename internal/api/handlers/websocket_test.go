@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"fleet-backend/internal/models"
 	"fleet-backend/internal/websocket"
+	"fleet-backend/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -202,4 +206,118 @@ func TestHandleWebSocketQueryFilters(t *testing.T) {
 	// Note: We can't test the actual WebSocket upgrade in unit tests
 	// because httptest.ResponseRecorder doesn't support hijacking
 	// This test verifies that query parameter parsing works correctly
-}
\ No newline at end of file
+}
+func TestGetBroadcastHealth(t *testing.T) {
+	// Manager is intentionally not started so broadcasts remain queued
+	manager := websocket.NewManager()
+	handler := NewWebSocketHandler(manager)
+	
+	for i := 0; i < 2; i++ {
+		err := manager.BroadcastVehicleUpdate("vehicle-123", websocket.VehicleUpdate{
+			VehicleID: "vehicle-123",
+			Priority:  websocket.PriorityMedium,
+		})
+		require.NoError(t, err)
+	}
+	
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws/health", handler.GetBroadcastHealth)
+	
+	req, _ := http.NewRequest("GET", "/ws/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	var response struct {
+		Health websocket.BroadcastHealth `json:"health"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	
+	assert.Equal(t, 2, response.Health.QueueDepth)
+	assert.Equal(t, 1000, response.Health.QueueCapacity)
+	assert.Equal(t, 0, response.Health.TotalClients)
+}
+
+// newRoutedWebSocketServer serves HandleWebSocket the way the routes mount it,
+// returning the server and a valid token for it
+func newRoutedWebSocketServer(t *testing.T, manager *websocket.Manager) (*httptest.Server, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws/secure", NewWebSocketHandler(manager).HandleWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	token, err := jwt.NewJWTUtil().GenerateToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	return server, token
+}
+
+// readVehicleUpdate reads the next vehicle update frame from a connection
+func readVehicleUpdate(t *testing.T, conn *gorillaws.Conn) websocket.VehicleUpdate {
+	t.Helper()
+	var message struct {
+		Type string                  `json:"type"`
+		Data websocket.VehicleUpdate `json:"data"`
+	}
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&message))
+	require.Equal(t, websocket.MessageTypeVehicleUpdate, message.Type)
+	return message.Data
+}
+
+func TestHandleWebSocket_BBoxFilter(t *testing.T) {
+	manager := websocket.NewManager()
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+	server, token := newRoutedWebSocketServer(t, manager)
+
+	resp, err := http.Get(server.URL + "/ws/secure?bbox=10,0,5,1&token=" + token)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/secure?bbox=-1.45,36.65,-1.15,37.05&token=" + token
+	conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return manager.GetConnectedClients() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// Mombasa is outside the Nairobi viewport, so only the second update arrives
+	for _, update := range []websocket.VehicleUpdate{
+		{VehicleID: "v1", UpdateType: "location", Data: map[string]interface{}{"location": models.Location{Lat: -4.0435, Lng: 39.6682}}},
+		{VehicleID: "v2", UpdateType: "location", Data: map[string]interface{}{"location": models.Location{Lat: -1.2921, Lng: 36.8219}}},
+	} {
+		require.NoError(t, manager.BroadcastVehicleUpdate(update.VehicleID, update))
+	}
+	assert.Equal(t, "v2", readVehicleUpdate(t, conn).VehicleID)
+}
+
+func TestHandleWebSocket_SeveritiesFilter(t *testing.T) {
+	manager := websocket.NewManager()
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+	server, token := newRoutedWebSocketServer(t, manager)
+
+	resp, err := http.Get(server.URL + "/ws/secure?severities=urgent&token=" + token)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/secure?severities=high,critical&token=" + token
+	conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return manager.GetConnectedClients() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	for _, update := range []websocket.VehicleUpdate{
+		{VehicleID: "v1", UpdateType: "alert", Data: map[string]interface{}{"alertType": "fuel_low", "severity": "low"}},
+		{VehicleID: "v2", UpdateType: "alert", Data: map[string]interface{}{"alertType": "speeding", "severity": "critical"}},
+	} {
+		require.NoError(t, manager.BroadcastVehicleUpdate(update.VehicleID, update))
+	}
+	assert.Equal(t, "v2", readVehicleUpdate(t, conn).VehicleID)
+}
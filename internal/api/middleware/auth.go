@@ -43,10 +43,30 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		if claims.TenantID != "" {
+			c.Set("tenant_id", claims.TenantID)
+		}
 		c.Next()
 	}
 }
 
+// RequireRole rejects requests whose authenticated role is not one of roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
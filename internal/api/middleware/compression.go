@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig controls gzip compression of JSON responses
+type CompressionConfig struct {
+	Enabled bool
+	Level   int
+	// MinSize is the smallest body, in bytes, that is compressed
+	MinSize int
+	// ExcludedPaths are path prefixes never compressed, e.g. streaming exports
+	ExcludedPaths []string
+}
+
+// CompressionMiddleware gzips JSON responses of at least MinSize bytes for
+// clients that accept gzip. WebSocket upgrades and excluded paths pass through.
+func CompressionMiddleware(config CompressionConfig) (gin.HandlerFunc, error) {
+	if _, err := gzip.NewWriterLevel(nil, config.Level); err != nil {
+		return nil, fmt.Errorf("invalid gzip level %d: %w", config.Level, err)
+	}
+
+	writers := sync.Pool{New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, config.Level)
+		return gz
+	}}
+
+	return func(c *gin.Context) {
+		if !config.Enabled || !shouldCompress(c.Request, config.ExcludedPaths) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{
+			ResponseWriter: c.Writer,
+			minSize:        config.MinSize,
+			writers:        &writers,
+		}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}, nil
+}
+
+// shouldCompress reports whether the request may get a gzipped response
+func shouldCompress(r *http.Request, excludedPaths []string) bool {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, prefix := range excludedPaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return acceptsGzip(r.Header.Get("Accept-Encoding"))
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if !strings.EqualFold(coding, "gzip") && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response until it knows whether
+// the body reaches the size threshold, then writes it gzipped or as is
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+	writers *sync.Pool
+
+	status  int
+	buffer  bytes.Buffer
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	w.decide(false)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *gzipResponseWriter) Status() int {
+	if !w.decided && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer.Write(data)
+		if w.buffer.Len() < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends what has been written so far; a streamed response that is
+// flushed before reaching the threshold is left uncompressed
+func (w *gzipResponseWriter) Flush() {
+	w.decide(false)
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide writes the headers and buffered body, compressing them if compress
+// is set and the response is JSON that isn't already encoded
+func (w *gzipResponseWriter) decide(compress bool) error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	header := w.Header()
+	compress = compress && header.Get("Content-Encoding") == "" &&
+		strings.Contains(header.Get("Content-Type"), "json")
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.gz = w.writers.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+// finish writes a response that stayed below the threshold and closes the gzip stream
+func (w *gzipResponseWriter) finish() {
+	w.decide(false)
+	if w.gz != nil {
+		w.gz.Close()
+		w.writers.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCompressionRouter(t *testing.T) *gin.Engine {
+	compression, err := CompressionMiddleware(CompressionConfig{
+		Enabled:       true,
+		Level:         gzip.DefaultCompression,
+		MinSize:       1024,
+		ExcludedPaths: []string{"/api/v1/vehicles/export"},
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(compression)
+
+	large := strings.Repeat("vehicle ", 500)
+	router.GET("/api/v1/vehicles", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": large})
+	})
+	router.GET("/api/v1/vehicles/export", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": large})
+	})
+	router.GET("/api/v1/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func TestCompressionMiddleware_GzipsLargeResponseWhenAccepted(t *testing.T) {
+	router := setupCompressionRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/vehicles", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"data":"vehicle vehicle`)
+}
+
+func TestCompressionMiddleware_LeavesResponseUncompressed(t *testing.T) {
+	router := setupCompressionRouter(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+	}{
+		{name: "client doesn't accept gzip", path: "/api/v1/vehicles"},
+		{name: "gzip refused", path: "/api/v1/vehicles", acceptEncoding: "gzip;q=0"},
+		{name: "below threshold", path: "/api/v1/health", acceptEncoding: "gzip"},
+		{name: "excluded path", path: "/api/v1/vehicles/export", acceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.True(t, strings.HasPrefix(w.Body.String(), "{"), "body should be plain JSON")
+		})
+	}
+}
+
+func TestCompressionMiddleware_RejectsInvalidLevel(t *testing.T) {
+	_, err := CompressionMiddleware(CompressionConfig{Enabled: true, Level: 42})
+	assert.Error(t, err)
+}
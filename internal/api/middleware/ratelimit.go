@@ -24,11 +24,21 @@ func RateLimitMiddleware(limiter ratelimit.RateLimiter) gin.HandlerFunc {
 		// Get client identifier
 		clientID := getClientID(c)
 		
+		// Allowlisted internal clients skip rate limiting entirely. The
+		// decision is kept for TenantRateLimitMiddleware so the bypass is
+		// only counted once per request
+		if limiter.Bypass(clientID, getClientIP(c)) {
+			c.Set("ratelimit_bypass", true)
+			c.Header("X-RateLimit-Bypass", "allowlisted")
+			c.Next()
+			return
+		}
+		
 		// Get endpoint identifier
 		endpoint := getEndpointID(c)
 		
 		// Check rate limit
-		allowed, resetTime, err := limiter.Allow(clientID, endpoint)
+		info, err := limiter.AllowWithInfo(clientID, endpoint)
 		if err != nil {
 			// Log error but don't block request on rate limiter failure
 			c.Header("X-RateLimit-Error", "Rate limiter unavailable")
@@ -36,26 +46,10 @@ func RateLimitMiddleware(limiter ratelimit.RateLimiter) gin.HandlerFunc {
 			return
 		}
 		
-		// Get current limits for headers
-		limits := limiter.GetLimits(clientID)
-		endpointKey := getEndpointKey(endpoint, c.Request.Method)
-		
-		var currentLimit ratelimit.RateLimit
-		if limit, exists := limits[endpointKey]; exists {
-			currentLimit = limit
-		} else if limit, exists := limits["default"]; exists {
-			currentLimit = limit
-		} else {
-			// Fallback default
-			currentLimit = ratelimit.RateLimit{
-				RequestsPerMinute: 60,
-				BurstSize:         15,
-				WindowSize:        time.Minute,
-			}
-		}
+		allowed, resetTime := info.Allowed, info.RetryAfter
 		
 		// Set rate limit headers
-		setRateLimitHeaders(c, currentLimit, allowed, resetTime)
+		setRateLimitHeaders(c, info)
 		
 		if !allowed {
 			// Request blocked by rate limiter
@@ -63,7 +57,55 @@ func RateLimitMiddleware(limiter ratelimit.RateLimiter) gin.HandlerFunc {
 				"error":   "Rate limit exceeded",
 				"message": fmt.Sprintf("Too many requests. Try again in %v", resetTime),
 				"code":    "RATE_LIMIT_EXCEEDED",
-				"retryAfter": int(resetTime.Seconds()),
+				"retryAfter": retryAfterSeconds(resetTime),
+				"retryAfterMs": resetTime.Milliseconds(),
+			})
+			c.Abort()
+			return
+		}
+		
+		c.Next()
+	}
+}
+
+// TenantRateLimitMiddleware caps the combined request rate of all clients of a
+// tenant, in addition to the per-client limit. It must run after
+// AuthMiddleware, which puts the tenant in the context, and after
+// RateLimitMiddleware, whose allowlist decision it reuses; requests without a
+// tenant pass through.
+func TenantRateLimitMiddleware(limiter ratelimit.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := c.GetString("tenant_id")
+		if tenantID == "" || c.GetBool("ratelimit_bypass") {
+			c.Next()
+			return
+		}
+		
+		info, err := limiter.AllowTenant(tenantID)
+		if err != nil {
+			// Don't block requests on rate limiter failure
+			c.Header("X-RateLimit-Error", "Rate limiter unavailable")
+			c.Next()
+			return
+		}
+		
+		// Unlimited tenants get no headers
+		if info.Limit.BurstSize > 0 {
+			c.Header("X-Tenant-RateLimit-Limit", strconv.Itoa(info.Limit.RequestsPerMinute))
+			c.Header("X-Tenant-RateLimit-Remaining", strconv.Itoa(info.Remaining))
+			if !info.ResetAt.IsZero() {
+				c.Header("X-Tenant-RateLimit-Reset", strconv.FormatInt(info.ResetAt.Unix(), 10))
+			}
+		}
+		
+		if !info.Allowed {
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(info.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":        "Tenant rate limit exceeded",
+				"message":      fmt.Sprintf("Too many requests from your organization. Try again in %v", info.RetryAfter),
+				"code":         "TENANT_RATE_LIMIT_EXCEEDED",
+				"retryAfter":   retryAfterSeconds(info.RetryAfter),
+				"retryAfterMs": info.RetryAfter.Milliseconds(),
 			})
 			c.Abort()
 			return
@@ -100,20 +142,10 @@ func getClientID(c *gin.Context) string {
 	return fmt.Sprintf("anon:%s:%s", ip, hashString(userAgent))
 }
 
-// getClientIP extracts the real client IP address
+// getClientIP returns the client IP address. Forwarding headers are only
+// believed when the request came through one of the engine's trusted
+// proxies, so clients can't pick the IP used for allowlisting and limits.
 func getClientIP(c *gin.Context) string {
-	// Check for forwarded headers first
-	if forwarded := c.GetHeader("X-Forwarded-For"); forwarded != "" {
-		// Take the first IP in the chain
-		ips := strings.Split(forwarded, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	
-	if realIP := c.GetHeader("X-Real-IP"); realIP != "" {
-		return realIP
-	}
-	
-	// Fallback to remote address
 	return c.ClientIP()
 }
 
@@ -167,12 +199,7 @@ func isID(s string) bool {
 	}
 	
 	// Check for MongoDB ObjectID (24 hex characters)
-	if len(s) == 24 {
-		for _, c := range s {
-			if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-				break
-			}
-		}
+	if len(s) == 24 && isHex(s) {
 		return true
 	}
 	
@@ -186,77 +213,56 @@ func isID(s string) bool {
 		return true
 	}
 	
-	return false
-}
-
-// getEndpointKey maps an endpoint to a rate limit category
-func getEndpointKey(endpoint, method string) string {
-	// This should match the logic in config.go
-	endpointMap := map[string]string{
-		"POST:/api/v1/auth/login":    "auth_login",
-		"POST:/api/v1/auth/logout":   "auth_logout",
-		"POST:/api/v1/auth/register": "auth",
-		"GET:/api/v1/auth/profile":   "auth",
-		
-		"GET:/api/v1/vehicles":      "vehicles",
-		"POST:/api/v1/vehicles":     "vehicles_create",
-		"PATCH:/api/v1/vehicles/*":  "vehicles_update",
-		"DELETE:/api/v1/vehicles/*": "vehicles_delete",
-		
-		"GET:/api/v1/alerts":     "alerts",
-		"POST:/api/v1/alerts":    "alerts_create",
-		
-		"GET:/api/v1/users":      "users",
-		"POST:/api/v1/users":     "users_create",
-		"PATCH:/api/v1/users/*":  "users_update",
-		"DELETE:/api/v1/users/*": "users_delete",
-		
-		"GET:/api/v1/maintenance":  "maintenance",
-		"POST:/api/v1/maintenance": "maintenance_create",
-		
-		"GET:/api/v1/health": "health",
-	}
-	
-	if category, exists := endpointMap[endpoint]; exists {
-		return category
-	}
-	
-	// Check for wildcard matches
-	for pattern, category := range endpointMap {
-		if matchesEndpointPattern(endpoint, pattern) {
-			return category
+	// API version segments such as "v1" are part of the route, not IDs
+	if len(s) > 1 && s[0] == 'v' {
+		if _, err := strconv.Atoi(s[1:]); err == nil {
+			return false
 		}
 	}
 	
-	return "default"
+	// Treat mixed segments containing digits (e.g. "abc123def", "user-123") as IDs
+	return strings.ContainsAny(s, "0123456789")
 }
 
-// matchesEndpointPattern checks if an endpoint matches a pattern with wildcards
-func matchesEndpointPattern(endpoint, pattern string) bool {
-	if strings.HasSuffix(pattern, "*") {
-		prefix := pattern[:len(pattern)-1]
-		return len(endpoint) >= len(prefix) && endpoint[:len(prefix)] == prefix
+// isHex reports whether s consists only of hexadecimal characters
+func isHex(s string) bool {
+	for _, c := range s {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
 	}
-	return endpoint == pattern
+	return true
 }
 
 // setRateLimitHeaders sets standard rate limiting headers
-func setRateLimitHeaders(c *gin.Context, limit ratelimit.RateLimit, allowed bool, resetTime time.Duration) {
+func setRateLimitHeaders(c *gin.Context, info ratelimit.RateLimitInfo) {
+	limit := info.Limit
+	
 	// Set standard rate limit headers
 	c.Header("X-RateLimit-Limit", strconv.Itoa(limit.RequestsPerMinute))
 	c.Header("X-RateLimit-Window", strconv.Itoa(int(limit.WindowSize.Seconds())))
 	c.Header("X-RateLimit-Burst", strconv.Itoa(limit.BurstSize))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(info.Remaining))
+	
+	if !info.ResetAt.IsZero() {
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(info.ResetAt.Unix(), 10))
+	}
 	
-	if !allowed {
-		c.Header("Retry-After", strconv.Itoa(int(resetTime.Seconds())))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(resetTime).Unix(), 10))
+	if !info.Allowed {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(info.RetryAfter)))
 	}
 	
 	// Add custom headers for debugging (only in debug mode)
 	if gin.Mode() == gin.DebugMode {
-		c.Header("X-RateLimit-Allowed", strconv.FormatBool(allowed))
-		if resetTime > 0 {
-			c.Header("X-RateLimit-Reset-Time", resetTime.String())
+		c.Header("X-RateLimit-Allowed", strconv.FormatBool(info.Allowed))
+		if info.RetryAfter > 0 {
+			c.Header("X-RateLimit-Reset-Time", info.RetryAfter.String())
 		}
 	}
-}
\ No newline at end of file
+}
+
+// retryAfterSeconds rounds a retry duration up to whole seconds, as required by
+// the Retry-After header, so sub-second waits are never reported as zero
+func retryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
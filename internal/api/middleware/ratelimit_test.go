@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -194,6 +195,132 @@ func TestRateLimitMiddleware_Headers(t *testing.T) {
 	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Burst"))
 }
 
+func TestRateLimitMiddleware_RemainingHeaders(t *testing.T) {
+	router, cleanup := setupTestMiddleware(t)
+	defer cleanup()
+	
+	// The default limit in the test config allows a burst of 2
+	expectedRemaining := []string{"1", "0"}
+	for i, expected := range expectedRemaining {
+		req := httptest.NewRequest("GET", "/api/v1/test", nil)
+		req.Header.Set("X-Forwarded-For", "192.168.1.6")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusNotFound, w.Code, "request %d should pass the limiter", i+1)
+		assert.Equal(t, expected, w.Header().Get("X-RateLimit-Remaining"))
+		
+		reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, reset, time.Now().Unix())
+	}
+	
+	// Quota exhausted: the next request is rejected
+	req := httptest.NewRequest("GET", "/api/v1/test", nil)
+	req.Header.Set("X-Forwarded-For", "192.168.1.6")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitMiddleware_AllowlistedAPIKey(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	
+	config := ratelimit.DefaultConfig()
+	config.DefaultLimits["auth_login"] = ratelimit.RateLimit{
+		RequestsPerMinute: 1,
+		BurstSize:         1,
+		WindowSize:        time.Minute,
+	}
+	config.Allowlist = ratelimit.Allowlist{APIKeys: []string{"internal-service"}}
+	
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitMiddleware(ratelimit.NewRedisRateLimiter(client, config)))
+	router.POST("/api/v1/auth/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "login successful"})
+	})
+	
+	// Allowlisted API key is never blocked past the burst size
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+		req.Header.Set("X-API-Key", "internal-service")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		
+		assert.Equal(t, http.StatusOK, w.Code, "allowlisted request %d should pass", i+1)
+		assert.Equal(t, "allowlisted", w.Header().Get("X-RateLimit-Bypass"))
+	}
+	
+	// A normal API key is blocked after the burst
+	codes := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+		req.Header.Set("X-API-Key", "public-client")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimitMiddleware_AllowlistedCIDRIgnoresSpoofedForwardingHeaders(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	
+	config := ratelimit.DefaultConfig()
+	config.DefaultLimits["auth_login"] = ratelimit.RequestsPerMinute(1)
+	config.Allowlist = ratelimit.Allowlist{CIDRs: []string{"10.0.0.0/8"}}
+	
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Only the load balancer at 10.1.1.1 may set forwarding headers
+	require.NoError(t, router.SetTrustedProxies([]string{"10.1.1.1"}))
+	router.Use(RateLimitMiddleware(ratelimit.NewRedisRateLimiter(client, config)))
+	router.POST("/api/v1/auth/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "login successful"})
+	})
+	
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		req.Header.Set("X-Real-IP", "10.0.0.1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	
+	// A client on the internet claiming an internal address is still limited
+	var codes []int
+	for i := 0; i < 2; i++ {
+		w := request("203.0.113.7:4000")
+		assert.Empty(t, w.Header().Get("X-RateLimit-Bypass"))
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+	
+	// The same header forwarded by the trusted proxy is believed
+	for i := 0; i < 3; i++ {
+		w := request("10.1.1.1:4000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "allowlisted", w.Header().Get("X-RateLimit-Bypass"))
+	}
+}
+
 func TestGetClientID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -279,4 +406,120 @@ func TestIsID(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected int
+	}{
+		{0, 0},
+		{150 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryAfterSeconds(tt.input))
+		})
+	}
+}
+
+func TestTenantRateLimitMiddleware_CapsTenantAcrossClients(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	
+	config := ratelimit.DefaultConfig()
+	config.DefaultLimits["default"] = ratelimit.RequestsPerMinute(100)
+	config.TenantLimit = ratelimit.RequestsPerMinute(4)
+	config.TenantLimits = map[string]ratelimit.RateLimit{"internal": {}}
+	limiter := ratelimit.NewRedisRateLimiter(client, config)
+	
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Stands in for AuthMiddleware
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+		c.Set("tenant_id", c.GetHeader("X-Test-Tenant"))
+		c.Next()
+	})
+	router.Use(RateLimitMiddleware(limiter), TenantRateLimitMiddleware(limiter))
+	router.GET("/api/v1/vehicles", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "vehicles"})
+	})
+	
+	request := func(user, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/vehicles", nil)
+		req.Header.Set("X-Test-User", user)
+		req.Header.Set("X-Test-Tenant", tenant)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	
+	// Three users of one tenant share its cap, each well under their own limit
+	var codes []int
+	for i := 0; i < 6; i++ {
+		codes = append(codes, request("user-"+strconv.Itoa(i%3), "acme").Code)
+	}
+	assert.Equal(t, []int{200, 200, 200, 200, 429, 429}, codes)
+	
+	blocked := request("user-3", "acme")
+	assert.Equal(t, http.StatusTooManyRequests, blocked.Code)
+	assert.Contains(t, blocked.Body.String(), "TENANT_RATE_LIMIT_EXCEEDED")
+	assert.NotEmpty(t, blocked.Header().Get("Retry-After"))
+	
+	// Another tenant is unaffected
+	w := request("user-9", "globex")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3", w.Header().Get("X-Tenant-RateLimit-Remaining"))
+	
+	// Exempt tenants and requests without a tenant are only limited per client
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, http.StatusOK, request("user-10", "internal").Code)
+		assert.Equal(t, http.StatusOK, request("user-11", "").Code)
+	}
+	
+	assert.Equal(t, int64(3), limiter.GetStats().TenantBlockedRequests)
+}
+
+func TestTenantRateLimitMiddleware_CountsAllowlistBypassOnce(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	
+	config := ratelimit.DefaultConfig()
+	config.TenantLimit = ratelimit.RequestsPerMinute(1)
+	config.Allowlist = ratelimit.Allowlist{APIKeys: []string{"internal-service"}}
+	limiter := ratelimit.NewRedisRateLimiter(client, config)
+	
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter))
+	// Stands in for AuthMiddleware
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", "acme")
+		c.Next()
+	})
+	router.Use(TenantRateLimitMiddleware(limiter))
+	router.GET("/api/v1/vehicles", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "vehicles"})
+	})
+	
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/api/v1/vehicles", nil)
+		req.Header.Set("X-API-Key", "internal-service")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "allowlisted request %d should skip the tenant cap", i+1)
+	}
+	
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(3), stats.BypassedRequests)
+	assert.Zero(t, stats.TenantBlockedRequests)
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-backend/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testReplaySecret = "integration-secret"
+
+func setupReplayRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Stands in for AuthMiddleware
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+		c.Next()
+	})
+	router.Use(ReplayProtectionMiddleware(ReplayProtectionConfig{
+		Secrets: map[string]string{"integration-1": testReplaySecret},
+		Window:  time.Minute,
+	}, telemetry.NewMemoryDeduplicator(2*time.Minute)))
+	router.POST("/telemetry/bulk", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+func signedRequest(user, secret string, timestamp time.Time, nonce, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/telemetry/bulk", strings.NewReader(body))
+	req.Header.Set("X-Test-User", user)
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req.Header.Set(SignatureTimestampHeader, ts)
+	req.Header.Set(SignatureNonceHeader, nonce)
+	req.Header.Set(SignatureHeader, SignRequest(secret, ts, nonce, "POST", "/telemetry/bulk", []byte(body)))
+	return req
+}
+
+func TestReplayProtection_AcceptsFreshRequest(t *testing.T) {
+	router := setupReplayRouter()
+	body := `[{"vehicleId": "v1", "speed": 40}]`
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("integration-1", testReplaySecret, time.Now(), "nonce-1", body))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String(), "body is still readable by the handler")
+}
+
+func TestReplayProtection_RejectsExpiredTimestamp(t *testing.T) {
+	router := setupReplayRouter()
+
+	for _, timestamp := range []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(2 * time.Minute)} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("integration-1", testReplaySecret, timestamp, "nonce-"+timestamp.String(), `[]`))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "TIMESTAMP_OUT_OF_WINDOW")
+	}
+}
+
+func TestReplayProtection_RejectsReplayedNonce(t *testing.T) {
+	router := setupReplayRouter()
+	req := func() *http.Request {
+		return signedRequest("integration-1", testReplaySecret, time.Now(), "nonce-1", `[{"vehicleId": "v1"}]`)
+	}
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, req())
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	replayed := httptest.NewRecorder()
+	router.ServeHTTP(replayed, req())
+	assert.Equal(t, http.StatusUnauthorized, replayed.Code)
+	assert.Contains(t, replayed.Body.String(), "NONCE_REUSED")
+}
+
+func TestReplayProtection_RejectsBadOrMissingSignature(t *testing.T) {
+	router := setupReplayRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("integration-1", "wrong-secret", time.Now(), "nonce-1", `[]`))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_SIGNATURE")
+
+	// A tampered body doesn't match the signature either
+	tampered := signedRequest("integration-1", testReplaySecret, time.Now(), "nonce-2", `[{"vehicleId": "v1"}]`)
+	tampered.Body = io.NopCloser(strings.NewReader(`[{"vehicleId": "v2"}]`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tampered)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	unsigned := httptest.NewRequest("POST", "/telemetry/bulk", strings.NewReader(`[]`))
+	unsigned.Header.Set("X-Test-User", "integration-1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, unsigned)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "SIGNATURE_REQUIRED")
+}
+
+func TestReplayProtection_SkipsCallersNotOptedIn(t *testing.T) {
+	router := setupReplayRouter()
+
+	req := httptest.NewRequest("POST", "/telemetry/bulk", strings.NewReader(`[]`))
+	req.Header.Set("X-Test-User", "dashboard-user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
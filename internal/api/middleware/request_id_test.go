@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fleet-backend/pkg/logger"
+	"fleet-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs routes the default structured logger into a buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// logLines decodes the captured JSON log lines
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(buf.String()))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func setupRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/ok", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("handling request")
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve vehicles", errors.New("connection reset"))
+	})
+	return router
+}
+
+func TestRequestIDMiddleware_GeneratesIDForHeaderAndLogs(t *testing.T) {
+	logs := captureLogs(t)
+	router := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	requestID := w.Header().Get(RequestIDHeader)
+	_, err := uuid.Parse(requestID)
+	require.NoError(t, err, "a UUID is generated when the client sends none")
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "handling request", lines[0]["msg"])
+	assert.Equal(t, "request completed", lines[1]["msg"])
+	for _, line := range lines {
+		assert.Equal(t, requestID, line["request_id"])
+	}
+	assert.Equal(t, float64(http.StatusOK), lines[1]["status"])
+}
+
+func TestRequestIDMiddleware_KeepsClientID(t *testing.T) {
+	logs := captureLogs(t)
+	router := setupRequestIDRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(RequestIDHeader, "upstream-trace-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "upstream-trace-42", w.Header().Get(RequestIDHeader))
+	for _, line := range logLines(t, logs) {
+		assert.Equal(t, "upstream-trace-42", line["request_id"])
+	}
+}
+
+func TestRequestIDMiddleware_ReplacesInvalidClientID(t *testing.T) {
+	captureLogs(t)
+	router := setupRequestIDRouter()
+
+	for _, invalid := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+		req.Header.Set(RequestIDHeader, invalid)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		requestID := w.Header().Get(RequestIDHeader)
+		assert.NotEqual(t, invalid, requestID)
+		_, err := uuid.Parse(requestID)
+		assert.NoError(t, err)
+	}
+}
+
+func TestRequestIDMiddleware_ServerErrorsAreLoggedWithID(t *testing.T) {
+	logs := captureLogs(t)
+	router := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Failed to retrieve vehicles", lines[0]["msg"])
+	assert.Equal(t, "connection reset", lines[0]["error"])
+	assert.Equal(t, w.Header().Get(RequestIDHeader), lines[0]["request_id"])
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"fleet-backend/internal/api/handlers"
 	"fleet-backend/internal/api/middleware"
 	"fleet-backend/internal/config"
@@ -8,24 +10,78 @@ import (
 	"fleet-backend/internal/services"
 	"fleet-backend/internal/websocket"
 	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/blobstore"
+	"fleet-backend/pkg/cache"
 	"fleet-backend/pkg/cleanup"
 	"fleet-backend/pkg/email"
+	"fleet-backend/pkg/lock"
 	"fleet-backend/pkg/ratelimit"
 	"fleet-backend/pkg/redis"
+	"fleet-backend/pkg/routing"
 	"fleet-backend/pkg/telemetry"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Client, cfg *config.Config) {
+// ShutdownFunc stops the background components started by SetupRoutes,
+// waiting at most timeout for in-flight work to finish
+type ShutdownFunc func(timeout time.Duration)
+
+// App exposes the components main needs after the routes are set up
+type App struct {
+	// VehicleService runs startup tasks such as cache warming
+	VehicleService *services.VehicleService
+	Shutdown       ShutdownFunc
+}
+
+func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Client, cfg *config.Config) *App {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	vehicleRepo := repository.NewVehicleRepository(db)
 	alertRepo := repository.NewAlertRepository(db)
 	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	geofenceRepo := repository.NewGeofenceRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	// Bound each kind of query; queries made for a request also stop when it is cancelled
+	dbTimeouts := repository.Timeouts{
+		Read:      cfg.DBTimeouts.Read,
+		Write:     cfg.DBTimeouts.Write,
+		Aggregate: cfg.DBTimeouts.Aggregate,
+		Stream:    cfg.DBTimeouts.Stream,
+	}
+	vehicleRepo.SetTimeouts(dbTimeouts)
+	alertRepo.SetTimeouts(dbTimeouts)
+	maintenanceRepo.SetTimeouts(dbTimeouts)
+	vehicleRepo.SetIndexedMetadataKeys(cfg.Vehicles.IndexedMetadataKeys)
+
+	idleSegmentRepo := repository.NewIdleSegmentRepository(db)
+	if err := idleSegmentRepo.CreateIndexes(); err != nil {
+		log.Printf("Warning: failed to create idle segment indexes: %v", err)
+	}
+	statusSegmentRepo := repository.NewStatusSegmentRepository(db)
+	if err := statusSegmentRepo.CreateIndexes(); err != nil {
+		log.Printf("Warning: failed to create status segment indexes: %v", err)
+	}
+	unknownVehicleRepo := repository.NewUnknownVehicleRepository(db)
+	unknownVehicleRepo.SetTimeouts(dbTimeouts)
+	if err := unknownVehicleRepo.CreateIndexes(context.Background()); err != nil {
+		log.Printf("Warning: failed to create unknown vehicle indexes: %v", err)
+	}
+	speedSampleRepo := repository.NewSpeedSampleRepository(db)
+	if err := speedSampleRepo.CreateIndexes(); err != nil {
+		log.Printf("Warning: failed to create speed sample indexes: %v", err)
+	}
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	exchangeRateRepo.SetTimeouts(dbTimeouts)
+	if err := exchangeRateRepo.CreateIndexes(context.Background()); err != nil {
+		log.Printf("Warning: failed to create exchange rate indexes: %v", err)
+	}
 
 	// Initialize services
 	emailService := email.NewEmailService(
@@ -40,12 +96,163 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 	authService := services.NewAuthService(userRepo, emailService)
 	userService := services.NewUserService(userRepo)
 	vehicleService := services.NewVehicleService(vehicleRepo)
+
+	// Legacy data may already share plate numbers, which keeps the unique index
+	// from being created; report them rather than silently run without it
+	if duplicates, err := vehicleService.EnsureIndexes(context.Background()); errors.Is(err, services.ErrDuplicatePlates) {
+		for _, duplicate := range duplicates {
+			ids := make([]string, len(duplicate.Vehicles))
+			for i, vehicle := range duplicate.Vehicles {
+				ids[i] = vehicle.ID.Hex()
+			}
+			log.Printf("Duplicate plate number %q on vehicles %s", duplicate.PlateNumber, strings.Join(ids, ", "))
+		}
+		if cfg.Vehicles.FailOnDuplicatePlates {
+			log.Fatalf("Refusing to start: %v", err)
+		}
+		log.Printf("Warning: unique plate number index not created: %v", err)
+	} else if err != nil {
+		log.Printf("Warning: failed to create vehicle indexes: %v", err)
+	}
+	severityPolicy := services.DefaultAlertSeverityPolicy()
+	if err := severityPolicy.Replace(cfg.Alerts.SeverityOverrides); err != nil {
+		log.Printf("Warning: alert severity overrides ignored: %v", err)
+	}
+	vehicleService.SetAlertSeverityPolicy(severityPolicy)
+	if err := vehicleService.SetFuelUnit(cfg.Vehicles.FuelUnit); err != nil {
+		log.Printf("Warning: %v, presenting fuel in liters", err)
+	}
+	fuelAnomalyPolicy := services.DefaultFuelAnomalyPolicy()
+	fuelAnomalyPolicy.Sensitivity = cfg.Alerts.FuelTheftSensitivity
+	fuelAnomalyPolicy.Window = cfg.Alerts.FuelTheftWindow
+	vehicleService.SetFuelAnomalyPolicy(fuelAnomalyPolicy)
 	alertService := services.NewAlertService(alertRepo)
 	maintenanceService := services.NewMaintenanceService(maintenanceRepo, vehicleRepo)
+	maintenanceService.SetScheduleIntervalBounds(services.ScheduleIntervalBounds{
+		MinKm:   cfg.Maintenance.MinIntervalKm,
+		MaxKm:   cfg.Maintenance.MaxIntervalKm,
+		MinDays: cfg.Maintenance.MinIntervalDays,
+		MaxDays: cfg.Maintenance.MaxIntervalDays,
+	})
+
+	// Keep recurring schedules going from completed maintenance records
+	recurringRules := make(map[string]services.RecurringScheduleRule, len(cfg.Maintenance.RecurringSchedules))
+	for maintenanceType, schedule := range cfg.Maintenance.RecurringSchedules {
+		rule := services.RecurringScheduleRule{IntervalKm: schedule.IntervalKm}
+		if schedule.IntervalDays > 0 {
+			days := schedule.IntervalDays
+			rule.IntervalDays = &days
+		}
+		recurringRules[maintenanceType] = rule
+	}
+	if err := maintenanceService.SetRecurringScheduleRules(recurringRules); err != nil {
+		log.Printf("Warning: recurring maintenance schedules disabled: %v", err)
+	}
+	maintenanceService.SetSuggestedParts(cfg.Maintenance.SuggestedParts)
+
+	// Publish alerts, status changes and maintenance events to webhook subscribers
+	webhookService := services.NewWebhookService(webhookRepo)
+	vehicleService.SetAlertNotifier(webhookService)
+	vehicleService.SetEventPublisher(webhookService)
+	vehicleService.SetAlertTargetValidator(webhookService)
+	maintenanceService.SetEventPublisher(webhookService)
+
+	// Feed telemetry odometer growth into service date predictions
+	mileageForecaster := services.NewMileageForecaster()
+	vehicleService.SetMileageForecaster(mileageForecaster)
+	maintenanceService.SetMileageForecaster(mileageForecaster)
+
+	// Store maintenance attachment contents outside Mongo
+	if blobs, err := newAttachmentStore(cfg.Attachments); err != nil {
+		log.Printf("Warning: maintenance attachments disabled: %v", err)
+	} else if blobs != nil {
+		maintenanceService.SetBlobStore(blobs, cfg.Attachments.MaxSize)
+	}
+
+	// Record the rate into the reporting currency on each maintenance record
+	currencyConverter := services.NewCurrencyConverter(exchangeRateRepo, cfg.Maintenance.ReportingCurrency)
+	maintenanceService.SetCurrencyConverter(currencyConverter)
+
+	// Accumulate idle time from telemetry state changes
+	idleTracker := services.NewIdleTracker(idleSegmentRepo)
+	vehicleService.SetIdleTracker(idleTracker)
+
+	// Record status periods for utilization reports. Reports of active and
+	// idle vehicles vouch for their status as long as a silent vehicle stays
+	// online, so telemetry gaps are reported as unknown.
+	utilizationTracker := services.NewUtilizationTracker(statusSegmentRepo, cfg.Offline.OfflineAfter)
+	vehicleService.SetUtilizationTracker(utilizationTracker)
+
+	// Keep speed readings for speed statistics
+	speedHistory := services.NewSpeedHistory(speedSampleRepo)
+	speedHistory.SetWindowSize(cfg.Vehicles.SpeedWindowSize)
+	go speedHistory.Start()
+	vehicleService.SetSpeedHistory(speedHistory)
+
+	// Score drivers on speeding alerts, harsh driving and idling
+	scorecardService := services.NewScorecardService(vehicleRepo, alertRepo, speedHistory, idleTracker)
+
+	// Estimate ETAs by road when a routing provider is configured
+	if provider, err := newRoutingProvider(cfg.Routing); err != nil {
+		log.Printf("Warning: routing provider disabled, ETAs use straight-line distance: %v", err)
+	} else if provider != nil {
+		if cfg.RedisEnabled && redisClient != nil {
+			provider = routing.NewCachedProvider(provider, redisClient.GetClient(), "routing:route:", cfg.Routing.CacheTTL, routing.DefaultCachePrecision)
+		}
+		vehicleService.SetRoutingProvider(provider)
+	}
+	geofenceService := services.NewGeofenceService(geofenceRepo, vehicleRepo)
+
+	// Speeding alerts use geofence speed zones and per-vehicle limits
+	vehicleService.SetSpeedLimitResolver(services.NewZoneSpeedLimitResolver(geofenceRepo))
+
+	// Initialize vehicle cache (falls back to an in-memory LRU while Redis is down)
+	var cacheManager cache.CacheManager
+	if cfg.RedisEnabled && redisClient != nil {
+		cacheConfig := cache.DefaultCacheConfig()
+		cacheConfig.FallbackEnabled = cfg.Cache.FallbackEnabled
+		cacheConfig.FallbackMaxEntries = cfg.Cache.FallbackMaxEntries
+		cacheConfig.AdaptiveTTLEnabled = cfg.Cache.AdaptiveTTL
+
+		cacheManager = cache.NewCacheManager(redisClient, cacheConfig)
+		vehicleRepo.SetCacheManager(cacheManager)
+		vehicleService.SetCacheManager(cacheManager)
+
+		// Serve the full vehicle list while it is refreshed, if configured
+		serviceCacheConfig := vehicleService.GetCacheConfig()
+		serviceCacheConfig.VehicleListSoftTTL = cfg.Cache.VehicleListSoftTTL
+		vehicleService.SetCacheConfig(serviceCacheConfig)
+	}
 
 	// Initialize WebSocket manager
-	wsManager := websocket.NewManager()
+	wsManager, err := websocket.NewManagerWithKeepalive(websocket.KeepaliveConfig{
+		PingInterval:  cfg.WebSocket.PingInterval,
+		ReadDeadline:  cfg.WebSocket.ReadDeadline,
+		ClientTimeout: cfg.WebSocket.ClientTimeout,
+	})
+	if err != nil {
+		log.Printf("Warning: using default WebSocket keepalive: %v", err)
+		wsManager = websocket.NewManager()
+	}
+	wsManager.SetClientBufferSize(cfg.WebSocket.ClientBufferSize)
+	wsManager.SetAdaptiveClientBuffers(cfg.WebSocket.AdaptiveClientBuffers)
+	wsManager.SetMaxClients(cfg.WebSocket.MaxClients)
+	wsManager.SetDrainTimeout(cfg.WebSocket.DrainTimeout)
+	wsManager.SetAckResendTimeout(cfg.WebSocket.AckResendTimeout)
+	if err := wsManager.SetCompression(websocket.CompressionConfig{
+		Enabled:   cfg.WebSocket.CompressionEnabled,
+		Level:     cfg.WebSocket.CompressionLevel,
+		Threshold: cfg.WebSocket.CompressionThreshold,
+	}); err != nil {
+		log.Printf("Warning: WebSocket compression disabled: %v", err)
+	}
 	wsManager.Start()
+	alertService.SetWebSocketManager(wsManager)
+	maintenanceService.SetWebSocketManager(wsManager)
+	vehicleService.SetVehicleTagIndex(wsManager)
+	if err := vehicleService.LoadTagIndex(context.Background()); err != nil {
+		log.Printf("Warning: Failed to load vehicle tags for WebSocket filters: %v", err)
+	}
 
 	// Initialize batch processor
 	batchConfig := batch.LoadBatchConfigFromEnv()
@@ -55,6 +262,92 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 	// Initialize optimized telemetry service
 	telemetryService := telemetry.NewOptimizedTelemetryService(vehicleService, batchProcessor)
 
+	telemetryIngestor := telemetry.NewIngestor(vehicleService, batchProcessor)
+	telemetryIngestor.SetOdometerRecorder(mileageForecaster)
+	telemetryService.SetIdleRecorder(idleTracker)
+	telemetryIngestor.SetIdleRecorder(idleTracker)
+	telemetryIngestor.SetStatusRecorder(utilizationTracker)
+	telemetryService.SetSpeedRecorder(speedHistory)
+	telemetryIngestor.SetSpeedRecorder(speedHistory)
+
+	telemetryConfig := telemetry.LoadTelemetryConfig()
+
+	// Raw telemetry samples are kept as a time series until they expire
+	telemetrySampleRepo := repository.NewTelemetrySampleRepository(db, telemetryConfig.SampleRetention)
+	telemetrySampleRepo.SetTimeouts(dbTimeouts)
+	if err := telemetrySampleRepo.CreateIndexes(context.Background()); err != nil {
+		log.Printf("Warning: failed to create telemetry sample indexes: %v", err)
+	}
+
+	// Reject implausible speed readings in every ingestion path
+	speedBounds := services.SpeedPlausibility{
+		MinKmh: telemetryConfig.MinPlausibleSpeedKmh,
+		MaxKmh: telemetryConfig.MaxPlausibleSpeedKmh,
+	}
+	vehicleService.SetSpeedPlausibility(speedBounds)
+	telemetryService.SetSpeedPlausibility(speedBounds)
+	telemetryService.SetShedPressure(telemetryConfig.ShedPressure)
+
+	// Optimizations can be switched off by environment, and at runtime by admins
+	telemetryService.SetAdaptiveSchedulingEnabled(telemetryConfig.EnableAdaptiveScheduling)
+	telemetryService.SetDeltaUpdatesEnabled(telemetryConfig.EnableDeltaUpdates)
+	telemetryService.SetRateLimitingEnabled(telemetryConfig.EnableRateLimiting)
+	telemetryService.SetBatchingEnabled(telemetryConfig.EnableBatching)
+	telemetryIngestor.SetSpeedPlausibility(speedBounds)
+
+	// Bound the samples held in memory for one bulk request
+	telemetryIngestor.SetBulkLimits(telemetryConfig.MaxBulkSamples, telemetryConfig.BulkChunkSize)
+
+	// Reject, provision or queue telemetry for vehicle IDs no vehicle has
+	unknownVehicleHandling := telemetry.UnknownVehicleHandling{
+		Mode:        telemetryConfig.UnknownVehicleMode,
+		Provisioner: vehicleService,
+		Queue:       unknownVehicleRepo,
+		Events:      webhookService,
+	}
+	if err := telemetryIngestor.SetUnknownVehicleHandling(unknownVehicleHandling); err != nil {
+		log.Printf("Warning: keeping default unknown vehicle handling: %v", err)
+	}
+
+	// Drop replayed telemetry messages from at-least-once transports
+	if telemetryConfig.EnableDeduplication {
+		var deduplicator telemetry.Deduplicator
+		if cfg.RedisEnabled && redisClient != nil {
+			deduplicator = telemetry.NewRedisDeduplicator(redisClient.GetClient(), "telemetry:dedup:", telemetryConfig.DeduplicationWindow)
+		} else {
+			deduplicator = telemetry.NewMemoryDeduplicator(telemetryConfig.DeduplicationWindow)
+		}
+		telemetryService.SetDeduplicator(deduplicator)
+		telemetryIngestor.SetDeduplicator(deduplicator)
+	}
+
+	// With Redis shared between replicas, each vehicle's scheduled updates run on one replica
+	var vehicleLocker *lock.RedisLocker
+	if cfg.RedisEnabled && redisClient != nil {
+		vehicleLocker = lock.NewRedisLocker(redisClient.GetClient(), "telemetry:lock:", lock.NewOwnerID())
+		telemetryService.SetVehicleLocker(vehicleLocker, telemetryConfig.VehicleLockTTL)
+		go vehicleLocker.Start(telemetryConfig.VehicleLockTTL / 3)
+	}
+
+	// Apply threshold and interval changes from the runtime config file without a restart
+	var runtimeWatcher *config.Watcher
+	if cfg.Reload.File != "" {
+		targets := runtimeTargets{
+			batchProcessor:   batchProcessor,
+			vehicleService:   vehicleService,
+			telemetryService: telemetryService,
+			ingestor:         telemetryIngestor,
+			severityPolicy:   severityPolicy,
+			batch:            batchProcessor.GetConfig(),
+			cache:            vehicleService.GetCacheConfig(),
+			speed:            speedBounds,
+			severities:       cfg.Alerts.SeverityOverrides,
+		}
+		runtimeWatcher = config.NewWatcher(cfg.Reload.File, cfg.Reload.PollInterval)
+		runtimeWatcher.OnChange(targets.apply)
+		go runtimeWatcher.Start()
+	}
+
 	// Start telemetry service
 	if err := telemetryService.Start(); err != nil {
 		log.Printf("Warning: Failed to start telemetry service: %v", err)
@@ -66,15 +359,101 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 	cleanupService := cleanup.NewCleanupService(userRepo, 1*time.Hour)
 	go cleanupService.Start()
 
+	// Mark vehicles that stopped reporting as offline
+	offlineSweeper := services.NewOfflineSweeper(vehicleRepo, services.OfflinePolicy{
+		GracePeriod:  cfg.Offline.GracePeriod,
+		OfflineAfter: cfg.Offline.OfflineAfter,
+	}, cfg.Offline.SweepInterval)
+	offlineSweeper.SetStatusRecorder(utilizationTracker)
+	offlineSweeper.SetVehicleService(vehicleService)
+	// With Redis shared between replicas, one of them sweeps at a time
+	if cfg.RedisEnabled && redisClient != nil {
+		offlineSweeper.SetLocker(lock.NewRedisLocker(redisClient.GetClient(), "jobs:lock:", lock.NewOwnerID()), time.Minute)
+	}
+	go offlineSweeper.Start()
+
+	// Email fleet managers a daily digest of overdue service reminders
+	var reminderNotifier *services.OverdueReminderNotifier
+	if len(cfg.Maintenance.OverdueDigestRecipients) > 0 {
+		reminderNotifier = services.NewOverdueReminderNotifier(maintenanceRepo, vehicleRepo, emailService,
+			cfg.Maintenance.OverdueDigestRecipients, cfg.Maintenance.OverdueDigestTime)
+		reminderNotifier.SetScanLimits(services.ReminderScanLimits{
+			PageSize:    cfg.Maintenance.OverdueScanPageSize,
+			Concurrency: cfg.Maintenance.OverdueScanConcurrency,
+		})
+		// With Redis shared between replicas, one of them scans and sends the digest
+		if cfg.RedisEnabled && redisClient != nil {
+			reminderNotifier.SetLocker(lock.NewRedisLocker(redisClient.GetClient(), "jobs:lock:", lock.NewOwnerID()), time.Minute)
+		}
+		go reminderNotifier.Start()
+	}
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
 	vehicleHandler := handlers.NewVehicleHandler(vehicleService)
 	alertHandler := handlers.NewAlertHandler(alertService)
 	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(currencyConverter)
+	driverHandler := handlers.NewDriverHandler(scorecardService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	geofenceHandler := handlers.NewGeofenceHandler(geofenceService)
+	telemetryHandler := handlers.NewTelemetryHandler(telemetryIngestor)
+	telemetryReplayer := telemetry.NewTelemetryReplayer(telemetryService, vehicleService)
+	telemetryHandler.SetReplayer(telemetryReplayer)
+
+	// Integrations listed in TELEMETRY_REPLAY_SECRETS must sign ingestion requests
+	var replayNonces middleware.NonceStore
+	nonceWindow := 2 * telemetryConfig.ReplayWindow
+	if cfg.RedisEnabled && redisClient != nil {
+		replayNonces = telemetry.NewRedisDeduplicator(redisClient.GetClient(), "telemetry:nonce:", nonceWindow)
+	} else {
+		replayNonces = telemetry.NewMemoryDeduplicator(nonceWindow)
+	}
+	replayProtection := middleware.ReplayProtectionMiddleware(middleware.ReplayProtectionConfig{
+		Secrets: telemetryConfig.ReplaySecrets,
+		Window:  telemetryConfig.ReplayWindow,
+	}, replayNonces)
+	if len(telemetryConfig.ReplaySecrets) > 0 {
+		log.Printf("Telemetry replay protection enabled for %d integrations", len(telemetryConfig.ReplaySecrets))
+	}
+	deadLetterHandler := handlers.NewDeadLetterHandler(batchProcessor)
+	telemetryStatsHandler := handlers.NewTelemetryStatsHandler(telemetryService, batchProcessor)
+	batchAdminHandler := handlers.NewBatchAdminHandler(batchProcessor)
+	telemetryOptimizationsHandler := handlers.NewTelemetryOptimizationsHandler(telemetryService)
+	unknownVehicleHandler := handlers.NewUnknownVehicleHandler(unknownVehicleRepo, vehicleService)
+	if cacheManager != nil {
+		telemetryStatsHandler.SetCacheStatsSource(cacheManager)
+	}
 	healthHandler := handlers.NewHealthHandler(db, redisClient)
+	if cacheManager != nil {
+		healthHandler.AddCheck("cache", false, func(ctx context.Context) error {
+			return cacheManager.HealthCheck()
+		})
+	}
+	healthHandler.AddCheck("websocket", true, func(ctx context.Context) error {
+		if !wsManager.IsRunning() {
+			return errors.New("websocket manager is not running")
+		}
+		return nil
+	})
 	wsHandler := handlers.NewWebSocketHandler(wsManager)
 
+	// Limit fleet snapshots on connect per user, so reconnect loops reuse the last one
+	snapshotLimitConfig := &ratelimit.Config{
+		DefaultLimits:   map[string]ratelimit.RateLimit{"default": handlers.SnapshotRateLimit(cfg.WebSocket.SnapshotMinInterval)},
+		RedisKeyPrefix:  cfg.RateLimit.RedisKeyPrefix + "ws_snapshot:",
+		CleanupInterval: cfg.RateLimit.CleanupInterval,
+		Enabled:         true,
+	}
+	var snapshotLimiter ratelimit.RateLimiter
+	if cfg.RedisEnabled && redisClient != nil {
+		snapshotLimiter = ratelimit.NewRedisRateLimiter(redisClient.GetClient(), snapshotLimitConfig)
+	} else {
+		snapshotLimiter = ratelimit.NewMemoryRateLimiter(snapshotLimitConfig)
+	}
+	wsHandler.SetSnapshotThrottle(handlers.NewSnapshotThrottle(vehicleService, snapshotLimiter, cfg.WebSocket.SnapshotMinInterval))
+
 	// Initialize vehicle WebSocket handler (for testing)
 	// vehicleWSHandler := handlers.NewVehicleWebSocketHandler(wsManager, nil)
 
@@ -84,6 +463,22 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 		RedisKeyPrefix:  cfg.RateLimit.RedisKeyPrefix,
 		CleanupInterval: cfg.RateLimit.CleanupInterval,
 		Enabled:         cfg.RateLimit.Enabled,
+
+		CustomLimitsMaxEntries: cfg.RateLimit.CustomLimitsMaxEntries,
+		CustomLimitsIdleTTL:    cfg.RateLimit.CustomLimitsIdleTTL,
+
+		TenantLimit:  ratelimit.RequestsPerMinute(cfg.RateLimit.TenantRequestsPerMinute),
+		TenantLimits: make(map[string]ratelimit.RateLimit),
+
+		Allowlist: ratelimit.Allowlist{
+			ClientIDPrefixes: cfg.RateLimit.AllowlistClientPrefixes,
+			APIKeys:          cfg.RateLimit.AllowlistAPIKeys,
+			CIDRs:            cfg.RateLimit.AllowlistCIDRs,
+		},
+	}
+
+	for tenantID, requests := range cfg.RateLimit.TenantOverrides {
+		rateLimitConfig.TenantLimits[tenantID] = ratelimit.RequestsPerMinute(requests)
 	}
 
 	var rateLimiter ratelimit.RateLimiter
@@ -92,18 +487,38 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 		// Load existing custom limits
 		redisLimiter.LoadCustomLimits()
 		rateLimiter = redisLimiter
+		healthHandler.AddCheck("rate_limiter", false, redisLimiter.Ping)
 	} else {
 		rateLimiter = ratelimit.NewMemoryRateLimiter(rateLimitConfig)
 		log.Println("Using in-memory rate limiter (Redis is disabled)")
 	}
 
-	// Health check endpoint (public - before rate limiting)
+	// Health check endpoints (public - before rate limiting)
 	router.GET("/health", healthHandler.HealthCheck)
+	router.GET("/api/v1/health", healthHandler.HealthCheck)
+	router.GET("/api/v1/ready", healthHandler.Readiness)
 
 	// API routes with rate limiting
 	api := router.Group("/api/v1")
 	api.Use(middleware.RateLimitMiddleware(rateLimiter))
 
+	// Gzip large JSON responses; exports stream their own format and WebSockets are upgraded
+	compression, err := middleware.CompressionMiddleware(middleware.CompressionConfig{
+		Enabled: cfg.Compression.Enabled,
+		Level:   cfg.Compression.Level,
+		MinSize: cfg.Compression.MinSize,
+		ExcludedPaths: []string{
+			"/api/v1/vehicles/export",
+			"/api/v1/alerts/export",
+			"/api/v1/ws",
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: response compression disabled: %v", err)
+	} else {
+		api.Use(compression)
+	}
+
 	// Public routes
 	auth := api.Group("/auth")
 	{
@@ -117,7 +532,7 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 
 	// Protected auth routes
 	authProtected := api.Group("/auth")
-	authProtected.Use(middleware.AuthMiddleware())
+	authProtected.Use(middleware.AuthMiddleware(), middleware.TenantRateLimitMiddleware(rateLimiter))
 	{
 		authProtected.GET("/profile", authHandler.GetProfile)
 		authProtected.POST("/change-password", authHandler.ChangePassword)
@@ -126,17 +541,76 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 
 	// Protected routes
 	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(), middleware.TenantRateLimitMiddleware(rateLimiter))
 	{
 		// Vehicles
 		vehicles := protected.Group("/vehicles")
 		{
 			vehicles.GET("", vehicleHandler.GetVehicles)
 			vehicles.POST("", vehicleHandler.CreateVehicle)
+			vehicles.POST("/import", vehicleHandler.ImportVehicles)
+			vehicles.GET("/export", vehicleHandler.ExportVehicles)
+			vehicles.GET("/search", vehicleHandler.SearchVehicles)
+			vehicles.GET("/silent", vehicleHandler.GetSilentVehicles)
+			vehicles.POST("/batch-get", vehicleHandler.BatchGetVehicles)
+			vehicles.GET("/tags/:tag", vehicleHandler.GetVehiclesByTag)
 			vehicles.GET("/:id", vehicleHandler.GetVehicle)
 			vehicles.PATCH("/:id", vehicleHandler.UpdateVehicle)
 			vehicles.DELETE("/:id", vehicleHandler.DeleteVehicle)
+			vehicles.POST("/:id/restore", vehicleHandler.RestoreVehicle)
+			vehicles.DELETE("/:id/permanent", middleware.RequireRole("admin"), vehicleHandler.HardDeleteVehicle)
 			vehicles.GET("/updates", vehicleHandler.GetVehicleUpdates)
+			vehicles.GET("/:id/geofences", geofenceHandler.GetVehicleGeofences)
+			vehicles.GET("/:id/warranties", maintenanceHandler.GetActiveWarranties)
+			vehicles.GET("/:id/idle-stats", vehicleHandler.GetIdleStats)
+			vehicles.GET("/:id/speed-stats", vehicleHandler.GetSpeedStats)
+			vehicles.GET("/:id/eta", vehicleHandler.GetETA)
+			vehicles.PUT("/:id/alert-routing", vehicleHandler.SetAlertRouting)
+			vehicles.DELETE("/:id/alert-routing", vehicleHandler.ClearAlertRouting)
+			vehicles.PUT("/:id/metadata", vehicleHandler.ReplaceMetadata)
+			vehicles.PATCH("/:id/metadata", vehicleHandler.PatchMetadata)
+			vehicles.DELETE("/:id/metadata", vehicleHandler.ClearMetadata)
+			vehicles.PUT("/:id/tags", vehicleHandler.SetTags)
+		}
+
+		// Telemetry ingestion
+		telemetryRoutes := protected.Group("/telemetry")
+		{
+			telemetryRoutes.POST("/bulk", replayProtection, telemetryHandler.IngestBulk)
+			telemetryRoutes.GET("/stats", telemetryStatsHandler.GetStats)
+			telemetryRoutes.POST("/replay", middleware.RequireRole("admin"), telemetryHandler.ReplayTelemetry)
+
+			// Vehicle IDs queued by ingestion in queue mode
+			unknownVehicles := telemetryRoutes.Group("/unknown-vehicles")
+			unknownVehicles.Use(middleware.RequireRole("admin"))
+			{
+				unknownVehicles.GET("", unknownVehicleHandler.GetUnknownVehicles)
+				unknownVehicles.POST("/:id/provision", unknownVehicleHandler.ProvisionUnknownVehicle)
+			}
+
+			// Permanently failed batch updates
+			deadLetters := telemetryRoutes.Group("/deadletters")
+			deadLetters.Use(middleware.RequireRole("admin"))
+			{
+				deadLetters.GET("", deadLetterHandler.GetDeadLetters)
+				deadLetters.POST("/retry", deadLetterHandler.RetryDeadLetters)
+				deadLetters.DELETE("", deadLetterHandler.DiscardDeadLetters)
+			}
+		}
+
+		// Drivers
+		drivers := protected.Group("/drivers")
+		{
+			drivers.GET("/:id/scorecard", driverHandler.GetScorecard)
+		}
+
+		// Geofences
+		geofences := protected.Group("/geofences")
+		{
+			geofences.GET("", geofenceHandler.GetGeofences)
+			geofences.POST("", geofenceHandler.CreateGeofence)
+			geofences.GET("/:id", geofenceHandler.GetGeofence)
+			geofences.DELETE("/:id", geofenceHandler.DeleteGeofence)
 		}
 
 		// Users
@@ -154,9 +628,12 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 		{
 			alerts.GET("", alertHandler.GetAlerts)
 			alerts.POST("", alertHandler.CreateAlert)
+			alerts.GET("/export", alertHandler.ExportAlerts)
 			alerts.GET("/:id", alertHandler.GetAlert)
 			alerts.PATCH("/:id", alertHandler.UpdateAlert)
 			alerts.PATCH("/:id/resolve", alertHandler.ResolveAlert)
+			alerts.POST("/ack", middleware.RequireRole("operator", "manager", "admin"), alertHandler.BulkAcknowledgeAlerts)
+			alerts.POST("/:id/ack", alertHandler.AcknowledgeAlert)
 			alerts.DELETE("/:id/dismiss", alertHandler.DismissAlert)
 			alerts.GET("/vehicle/:vehicleId", alertHandler.GetAlertsByVehicle)
 			alerts.GET("/type", alertHandler.GetAlertsByType)
@@ -177,10 +654,16 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 			maintenance.PATCH("/records/:id", maintenanceHandler.UpdateMaintenanceRecord)
 			maintenance.DELETE("/records/:id", maintenanceHandler.DeleteMaintenanceRecord)
 
+			// Maintenance Record Attachments
+			maintenance.POST("/:id/attachments", middleware.RequireRole("operator", "manager", "admin"), maintenanceHandler.UploadAttachment)
+			maintenance.GET("/:id/attachments", maintenanceHandler.GetAttachments)
+			maintenance.GET("/:id/attachments/:attachmentId", maintenanceHandler.DownloadAttachment)
+
 			// Maintenance Schedules
 			maintenance.POST("/schedules", maintenanceHandler.CreateSchedule)
 			maintenance.GET("/schedules", maintenanceHandler.GetAllSchedules)
 			maintenance.GET("/schedules/upcoming", maintenanceHandler.GetUpcomingSchedules)
+			maintenance.GET("/schedules/unserviced", maintenanceHandler.GetUnservicedSchedules)
 			maintenance.GET("/schedules/vehicle/:vehicleId", maintenanceHandler.GetSchedulesByVehicle)
 			maintenance.GET("/schedules/:id", maintenanceHandler.GetSchedule)
 			maintenance.PATCH("/schedules/:id", maintenanceHandler.UpdateSchedule)
@@ -190,15 +673,204 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, redisClient *redis.Clie
 			maintenance.GET("/reminders/vehicle/:vehicleId", maintenanceHandler.GetServiceReminders)
 			maintenance.GET("/reminders/overdue", maintenanceHandler.GetOverdueReminders)
 			maintenance.GET("/reminders/due", maintenanceHandler.GetNextServiceDue)
+
+			// Exchange rates for maintenance costs
+			maintenance.GET("/exchange-rates", exchangeRateHandler.GetExchangeRate)
+			maintenance.POST("/exchange-rates", middleware.RequireRole("manager", "admin"), exchangeRateHandler.CreateExchangeRate)
+		}
+
+		// Webhooks
+		webhooks := protected.Group("/webhooks")
+		webhooks.Use(middleware.RequireRole("manager", "admin"))
+		{
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("", webhookHandler.GetWebhooks)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PATCH("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.POST("/:id/test", webhookHandler.TestWebhook)
+			webhooks.GET("/:id/stats", webhookHandler.GetWebhookStats)
+		}
+
+		// Admin diagnostics
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireRole("admin"))
+		{
+			admin.GET("/duplicate-plates", vehicleHandler.GetDuplicatePlates)
+			admin.POST("/batch/flush", batchAdminHandler.FlushBatch)
+			admin.GET("/telemetry/optimizations", telemetryOptimizationsHandler.GetOptimizations)
+			admin.PATCH("/telemetry/optimizations", telemetryOptimizationsHandler.UpdateOptimizations)
+		}
+
+		// Reports
+		reports := protected.Group("/reports")
+		{
+			reports.GET("/maintenance-forecast", maintenanceHandler.GetMaintenanceForecast)
+			reports.GET("/utilization", vehicleHandler.GetUtilizationReport)
 		}
 
 		// WebSocket routes (protected)
 		ws := protected.Group("/ws")
 		{
 			ws.GET("/secure", wsHandler.HandleWebSocket)
+			ws.GET("/health", wsHandler.GetBroadcastHealth)
 			ws.GET("/secure/clients", wsHandler.GetConnectedClients)
 			ws.POST("/secure/broadcast", wsHandler.BroadcastUpdate)
 			ws.DELETE("/secure/clients/:clientId", wsHandler.DisconnectClient)
 		}
 	}
+
+	// Shutdown order matters: the batch processor's final flush broadcasts through the
+	// WebSocket manager, so ingestion and batching stop before clients are drained.
+	// The HTTP server must already have stopped accepting requests.
+	shutdown := func(timeout time.Duration) {
+		deadline := time.Now().Add(timeout)
+		// Stop any telemetry replay before the pipeline it feeds
+		telemetryReplayer.Stop()
+		// Stop scheduled ingestion and flush the final batch (queues its broadcasts)
+		if err := telemetryService.Stop(); err != nil {
+			log.Printf("Error stopping telemetry service: %v", err)
+		}
+		// Hand this replica's vehicles over to the others
+		if vehicleLocker != nil {
+			vehicleLocker.Stop()
+		}
+		// Persist the idle time of vehicles still idling
+		idleTracker.Flush(time.Now())
+		// Close the status periods in progress; they are unknown until vehicles report again
+		utilizationTracker.Flush(time.Now())
+		// Write the speed readings still buffered
+		speedHistory.Stop()
+
+		// Deliver the final broadcasts and let client send buffers flush, within
+		// both the configured drain timeout and the shutdown timeout
+		if err := wsManager.Drain(min(timeout, cfg.WebSocket.DrainTimeout)); err != nil {
+			log.Printf("Error draining WebSocket manager: %v", err)
+		}
+
+		cleanupService.Stop()
+		offlineSweeper.Stop()
+		if reminderNotifier != nil {
+			reminderNotifier.Stop()
+		}
+		if runtimeWatcher != nil {
+			runtimeWatcher.Stop()
+		}
+
+		// Let queued webhook deliveries finish within what is left of the
+		// shutdown timeout; the rest are dropped
+		if err := webhookService.Drain(time.Until(deadline)); err != nil {
+			log.Printf("Error draining webhook deliveries: %v", err)
+		}
+
+		// Stop the rate limiters' cleanup; no more requests arrive
+		rateLimiter.Close()
+		snapshotLimiter.Close()
+	}
+
+	return &App{VehicleService: vehicleService, Shutdown: shutdown}
+}
+
+// runtimeTargets applies hot-reloaded settings over the values the server
+// started with, so a setting removed from the file reverts to its startup value
+type runtimeTargets struct {
+	batchProcessor   *batch.DefaultBatchProcessor
+	vehicleService   *services.VehicleService
+	telemetryService *telemetry.OptimizedTelemetryService
+	ingestor         *telemetry.Ingestor
+	severityPolicy   *services.AlertSeverityPolicy
+
+	// Startup values
+	batch      batch.BatchConfig
+	cache      cache.CacheConfig
+	speed      services.SpeedPlausibility
+	severities map[string]string
+}
+
+func (t runtimeTargets) apply(runtime config.RuntimeConfig) {
+	batchSize, batchInterval := t.batch.MaxBatchSize, t.batch.BatchInterval
+	if runtime.Batch.MaxBatchSize > 0 {
+		batchSize = runtime.Batch.MaxBatchSize
+	}
+	if runtime.Batch.BatchInterval > 0 {
+		batchInterval = time.Duration(runtime.Batch.BatchInterval)
+	}
+	t.batchProcessor.SetBatchSize(batchSize)
+	if t.batchProcessor.GetConfig().BatchInterval != batchInterval {
+		t.batchProcessor.SetBatchInterval(batchInterval)
+	}
+
+	cacheConfig := t.cache
+	if runtime.Cache.VehicleDataTTL > 0 {
+		cacheConfig.VehicleDataTTL = time.Duration(runtime.Cache.VehicleDataTTL)
+	}
+	if runtime.Cache.VehicleListTTL > 0 {
+		cacheConfig.VehicleListTTL = time.Duration(runtime.Cache.VehicleListTTL)
+	}
+	if runtime.Cache.SearchResultTTL > 0 {
+		cacheConfig.SearchResultTTL = time.Duration(runtime.Cache.SearchResultTTL)
+	}
+	if runtime.Cache.FilteredListTTL > 0 {
+		cacheConfig.FilteredListTTL = time.Duration(runtime.Cache.FilteredListTTL)
+	}
+	t.vehicleService.SetCacheConfig(cacheConfig)
+
+	speed := t.speed
+	if runtime.Telemetry.MaxPlausibleSpeedKmh > 0 {
+		speed = services.SpeedPlausibility{
+			MinKmh: runtime.Telemetry.MinPlausibleSpeedKmh,
+			MaxKmh: runtime.Telemetry.MaxPlausibleSpeedKmh,
+		}
+	}
+	t.vehicleService.SetSpeedPlausibility(speed)
+	t.telemetryService.SetSpeedPlausibility(speed)
+	t.ingestor.SetSpeedPlausibility(speed)
+
+	severities := make(map[string]string, len(t.severities)+len(runtime.Alerts.SeverityOverrides))
+	for alertType, severity := range t.severities {
+		severities[alertType] = severity
+	}
+	for alertType, severity := range runtime.Alerts.SeverityOverrides {
+		severities[alertType] = severity
+	}
+	if err := t.severityPolicy.Replace(severities); err != nil {
+		log.Printf("Warning: runtime alert severity overrides ignored: %v", err)
+	}
+}
+
+// newAttachmentStore builds the configured blob store for maintenance
+// attachments; it returns nil when attachments are turned off
+func newAttachmentStore(cfg config.AttachmentConfig) (blobstore.BlobStore, error) {
+	switch cfg.Store {
+	case "local":
+		return blobstore.NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+		})
+	default:
+		return nil, nil
+	}
+}
+
+// newRoutingProvider builds the configured routing provider; it returns nil
+// when none is configured
+func newRoutingProvider(cfg config.RoutingConfig) (routing.Provider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "osrm":
+		return routing.NewOSRMProvider(cfg.OSRMURL, cfg.Timeout), nil
+	case "google":
+		if cfg.GoogleAPIKey == "" {
+			return nil, errors.New("ROUTING_GOOGLE_API_KEY is required for the google provider")
+		}
+		return routing.NewGoogleProvider(cfg.GoogleAPIKey, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown routing provider %q", cfg.Provider)
+	}
 }
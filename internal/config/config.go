@@ -20,8 +20,28 @@ type Config struct {
 	Redis          RedisConfig
 	RedisEnabled   bool
 	RateLimit      RateLimitConfig
+	Cache          CacheConfig
+	WebSocket      WebSocketConfig
+	Compression    CompressionConfig
+	Maintenance    MaintenanceConfig
+	Alerts         AlertConfig
+	Offline        OfflineConfig
+	Vehicles       VehicleConfig
+	Reload         ReloadConfig
 	SMTP           SMTPConfig
+	Attachments    AttachmentConfig
+	Routing        RoutingConfig
+	DBTimeouts     DBTimeoutConfig
+	SelfCheck      SelfCheckConfig
 	AppURL         string
+
+	// TrustedProxies lists the proxy addresses or CIDRs whose X-Forwarded-For
+	// and X-Real-IP headers are believed when working out a client's IP. With
+	// none, the connection's remote address is the client IP.
+	TrustedProxies []string
+
+	// ShutdownTimeout bounds how long graceful shutdown waits for in-flight work
+	ShutdownTimeout time.Duration
 }
 
 type RedisConfig struct {
@@ -46,6 +66,207 @@ type RateLimitConfig struct {
 	Enabled         bool          `json:"enabled"`
 	RedisKeyPrefix  string        `json:"redisKeyPrefix"`
 	CleanupInterval time.Duration `json:"cleanupInterval"`
+
+	// Bounds on the per-client custom limits held in memory
+	CustomLimitsMaxEntries int           `json:"customLimitsMaxEntries"`
+	CustomLimitsIdleTTL    time.Duration `json:"customLimitsIdleTtl"`
+
+	// TenantRequestsPerMinute caps the combined requests of each tenant's
+	// clients; zero disables tenant limits. TenantOverrides sets the cap of
+	// individual tenants, where zero exempts the tenant.
+	TenantRequestsPerMinute int            `json:"tenantRequestsPerMinute"`
+	TenantOverrides         map[string]int `json:"tenantOverrides"`
+
+	// Clients that bypass rate limiting. Client IDs are matched before
+	// authentication, so only "api:" and "anon:" prefixes can match; CIDRs
+	// are matched against the client IP, which only comes from forwarding
+	// headers set by TrustedProxies
+	AllowlistClientPrefixes []string `json:"allowlistClientPrefixes"`
+	AllowlistAPIKeys        []string `json:"allowlistApiKeys"`
+	AllowlistCIDRs          []string `json:"allowlistCidrs"`
+}
+
+type CacheConfig struct {
+	FallbackEnabled    bool `json:"fallbackEnabled"`
+	FallbackMaxEntries int  `json:"fallbackMaxEntries"`
+	// WarmOnStartup loads vehicles into the cache in the background at startup
+	WarmOnStartup bool `json:"warmOnStartup"`
+	// VehicleListSoftTTL serves the cached full vehicle list past this age while
+	// it is refreshed in the background; 0 disables stale-while-revalidate
+	VehicleListSoftTTL time.Duration `json:"vehicleListSoftTTL"`
+	// AdaptiveTTL lengthens the Redis TTL of frequently hit keys and shortens
+	// it for frequently invalidated ones
+	AdaptiveTTL bool `json:"adaptiveTTL"`
+}
+
+type WebSocketConfig struct {
+	// ClientBufferSize is the per-client outbound message queue size
+	ClientBufferSize int `json:"clientBufferSize"`
+	// AdaptiveClientBuffers sizes each client's queue by the breadth of its filters
+	AdaptiveClientBuffers bool `json:"adaptiveClientBuffers"`
+
+	// permessage-deflate settings, negotiated per connection
+	CompressionEnabled   bool `json:"compressionEnabled"`
+	CompressionLevel     int  `json:"compressionLevel"`
+	CompressionThreshold int  `json:"compressionThreshold"`
+
+	// SnapshotMinInterval is how often each user may have a fleet snapshot
+	// generated on connect; reconnects within it reuse the last snapshot
+	SnapshotMinInterval time.Duration `json:"snapshotMinInterval"`
+
+	// MaxClients caps concurrent WebSocket connections; 0 means unlimited
+	MaxClients int `json:"maxClients"`
+
+	// DrainTimeout is how long clients may flush queued messages on shutdown
+	// before their connections are closed; 0 closes them right away
+	DrainTimeout time.Duration `json:"drainTimeout"`
+
+	// AckResendTimeout is how long a critical update may go unacknowledged
+	// before it is sent to the client once more; 0 never re-sends
+	AckResendTimeout time.Duration `json:"ackResendTimeout"`
+
+	// Keepalive timings: clients are pinged every PingInterval, disconnected
+	// after ReadDeadline without a frame and removed by the health check
+	// after ClientTimeout without a pong. They must increase in that order.
+	PingInterval  time.Duration `json:"pingInterval"`
+	ReadDeadline  time.Duration `json:"readDeadline"`
+	ClientTimeout time.Duration `json:"clientTimeout"`
+}
+
+type CompressionConfig struct {
+	// Gzip JSON API responses for clients that accept it
+	Enabled bool `json:"enabled"`
+	Level   int  `json:"level"`
+	// MinSize is the smallest response body, in bytes, worth compressing
+	MinSize int `json:"minSize"`
+}
+
+type MaintenanceConfig struct {
+	// Sane bounds for maintenance schedule intervals
+	MinIntervalKm   int `json:"minIntervalKm"`
+	MaxIntervalKm   int `json:"maxIntervalKm"`
+	MinIntervalDays int `json:"minIntervalDays"`
+	MaxIntervalDays int `json:"maxIntervalDays"`
+	// RecurringSchedules lists the maintenance types whose completed records
+	// keep a recurring schedule going
+	RecurringSchedules map[string]RecurringScheduleConfig `json:"recurringSchedules"`
+	// SuggestedParts overrides the parts suggested for maintenance types on
+	// records created with suggestParts; other types keep the common parts
+	SuggestedParts map[string][]string `json:"suggestedParts"`
+	// OverdueDigestRecipients receive the daily overdue service reminder
+	// email; none turns the digest off
+	OverdueDigestRecipients []string `json:"overdueDigestRecipients"`
+	// OverdueDigestTime is the local time of day the digest is sent, as an
+	// offset from midnight
+	OverdueDigestTime time.Duration `json:"overdueDigestTime"`
+	// OverdueScanPageSize is how many reminders the digest scan reads per
+	// query, and OverdueScanConcurrency how many vehicle lookups it runs at
+	// once; together they bound the scan's database load
+	OverdueScanPageSize    int `json:"overdueScanPageSize"`
+	OverdueScanConcurrency int `json:"overdueScanConcurrency"`
+	// ReportingCurrency is the ISO 4217 currency maintenance costs are
+	// converted into; each record keeps the rate in effect when the service
+	// was performed
+	ReportingCurrency string `json:"reportingCurrency"`
+}
+
+// RecurringScheduleConfig sets the intervals of a recurring schedule. A zero
+// IntervalKm uses the maintenance type's default; a zero IntervalDays leaves
+// the schedule without a time interval.
+type RecurringScheduleConfig struct {
+	IntervalKm   int `json:"intervalKm"`
+	IntervalDays int `json:"intervalDays"`
+}
+
+// AttachmentConfig selects where maintenance attachments are stored. Store is
+// "local" or "s3"; any other value disables attachments.
+type AttachmentConfig struct {
+	Store    string
+	LocalDir string
+	S3       S3AttachmentConfig
+	// MaxSize is the largest attachment accepted, in bytes
+	MaxSize int64
+}
+
+// RoutingConfig selects the routing provider used for road ETAs. Provider is
+// "osrm" or "google"; empty keeps the straight-line estimate.
+type RoutingConfig struct {
+	Provider     string
+	OSRMURL      string
+	GoogleAPIKey string
+	// Timeout bounds each request to the provider
+	Timeout time.Duration
+	// CacheTTL is how long routes are cached in Redis
+	CacheTTL time.Duration
+}
+
+// DBTimeoutConfig bounds each kind of database operation; a zero timeout
+// keeps the repository default
+type DBTimeoutConfig struct {
+	Read      time.Duration `json:"read"`
+	Write     time.Duration `json:"write"`
+	Aggregate time.Duration `json:"aggregate"`
+	Stream    time.Duration `json:"stream"`
+}
+
+type SelfCheckConfig struct {
+	// Strict refuses to start when the startup self-check finds a fatal problem;
+	// otherwise problems are only logged
+	Strict bool `json:"strict"`
+	// Timeout bounds each dependency ping
+	Timeout time.Duration `json:"timeout"`
+}
+
+type S3AttachmentConfig struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type AlertConfig struct {
+	// SeverityOverrides replaces the default severity of generated alerts by type
+	SeverityOverrides map[string]string `json:"severityOverrides"`
+
+	// FuelTheftSensitivity is how many standard deviations above a vehicle's
+	// normal consumption rate a fuel drop must be to raise a theft alert
+	FuelTheftSensitivity float64 `json:"fuelTheftSensitivity"`
+	// FuelTheftWindow is how far back fuel drops are measured
+	FuelTheftWindow time.Duration `json:"fuelTheftWindow"`
+}
+
+type OfflineConfig struct {
+	// GracePeriod after creation before a vehicle that never reported is flagged
+	GracePeriod time.Duration `json:"gracePeriod"`
+	// OfflineAfter is how long without telemetry before a vehicle is marked offline
+	OfflineAfter  time.Duration `json:"offlineAfter"`
+	SweepInterval time.Duration `json:"sweepInterval"`
+}
+
+type VehicleConfig struct {
+	// FailOnDuplicatePlates refuses to start while vehicles share plate numbers,
+	// instead of running without the unique plate number index
+	FailOnDuplicatePlates bool `json:"failOnDuplicatePlates"`
+
+	// IndexedMetadataKeys are the custom metadata keys given an index, for
+	// keys that vehicle lists are commonly filtered by
+	IndexedMetadataKeys []string `json:"indexedMetadataKeys"`
+
+	// FuelUnit is the unit fuel is presented in, "liters" or "gallons", for
+	// vehicles without their own. Fuel is always stored in liters.
+	FuelUnit string `json:"fuelUnit"`
+
+	// SpeedWindowSize is how many recent speed readings are kept in memory per
+	// vehicle. Larger windows cost memory per vehicle; windows holding fewer
+	// readings than arrive in 30 minutes make ETAs average a shorter period.
+	SpeedWindowSize int `json:"speedWindowSize"`
+}
+
+type ReloadConfig struct {
+	// File is a JSON RuntimeConfig polled for changes; empty disables hot reload
+	File         string        `json:"file"`
+	PollInterval time.Duration `json:"pollInterval"`
 }
 
 type SMTPConfig struct {
@@ -87,8 +308,23 @@ func Load() *Config {
 		Redis:          loadRedisConfig(),
 		RedisEnabled:   loadRedisEnabled(),
 		RateLimit:      loadRateLimitConfig(),
+		Cache:          loadCacheConfig(),
+		WebSocket:      loadWebSocketConfig(),
+		Compression:    loadCompressionConfig(),
+		Maintenance:    loadMaintenanceConfig(),
+		Alerts:         loadAlertConfig(),
+		Offline:        loadOfflineConfig(),
+		Vehicles:       loadVehicleConfig(),
+		Reload:         loadReloadConfig(),
 		SMTP:           loadSMTPConfig(),
+		Attachments:    loadAttachmentConfig(),
+		Routing:        loadRoutingConfig(),
+		DBTimeouts:     loadDBTimeoutConfig(),
+		SelfCheck:      loadSelfCheckConfig(),
 		AppURL:         getEnvOrDefault("APP_URL", "http://localhost:3000"),
+		TrustedProxies: loadTrustedProxies(),
+
+		ShutdownTimeout: loadShutdownTimeout(),
 	}
 }
 func loadRedisConfig() RedisConfig {
@@ -218,18 +454,488 @@ func loadRateLimitConfig() RateLimitConfig {
 		return defaultValue
 	}
 
+	// Helper function to parse a positive int with default
+	parseInt := func(envVar string, defaultValue int) int {
+		if val := os.Getenv(envVar); val != "" {
+			if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+				return intVal
+			}
+		}
+		return defaultValue
+	}
+
+	// Helper function to parse a comma-separated list
+	parseList := func(envVar string) []string {
+		var values []string
+		for _, val := range strings.Split(os.Getenv(envVar), ",") {
+			if val = strings.TrimSpace(val); val != "" {
+				values = append(values, val)
+			}
+		}
+		return values
+	}
+
 	keyPrefix := os.Getenv("RATE_LIMIT_KEY_PREFIX")
 	if keyPrefix == "" {
 		keyPrefix = "ratelimit:"
 	}
 
+	// Comma-separated tenant=requestsPerMinute pairs, e.g. "acme=1200,globex=300"
+	tenantOverrides := make(map[string]int)
+	for _, pair := range parseList("RATE_LIMIT_TENANT_OVERRIDES") {
+		tenantID, value, ok := strings.Cut(pair, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		requests, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || tenantID == "" || err != nil || requests < 0 {
+			log.Printf("Warning: ignoring malformed tenant rate limit override %q", pair)
+			continue
+		}
+		tenantOverrides[tenantID] = requests
+	}
+
 	return RateLimitConfig{
 		Enabled:         parseBool("RATE_LIMIT_ENABLED", true),
 		RedisKeyPrefix:  keyPrefix,
 		CleanupInterval: parseDuration("RATE_LIMIT_CLEANUP_INTERVAL", 5*time.Minute),
+
+		CustomLimitsMaxEntries: parseInt("RATE_LIMIT_CUSTOM_LIMITS_MAX_ENTRIES", 10000),
+		CustomLimitsIdleTTL:    parseDuration("RATE_LIMIT_CUSTOM_LIMITS_IDLE_TTL", time.Hour),
+
+		TenantRequestsPerMinute: parseInt("RATE_LIMIT_TENANT_REQUESTS_PER_MINUTE", 0),
+		TenantOverrides:         tenantOverrides,
+
+		AllowlistClientPrefixes: parseList("RATE_LIMIT_ALLOWLIST_CLIENT_PREFIXES"),
+		AllowlistAPIKeys:        parseList("RATE_LIMIT_ALLOWLIST_API_KEYS"),
+		AllowlistCIDRs:          parseList("RATE_LIMIT_ALLOWLIST_CIDRS"),
+	}
+}
+
+func loadCacheConfig() CacheConfig {
+	// Helper function to parse bool with default
+	parseBool := func(envVar string, defaultValue bool) bool {
+		if val := os.Getenv(envVar); val != "" {
+			if boolVal, err := strconv.ParseBool(val); err == nil {
+				return boolVal
+			}
+		}
+		return defaultValue
+	}
+
+	// Helper function to parse int with default
+	parseInt := func(envVar string, defaultValue int) int {
+		if val := os.Getenv(envVar); val != "" {
+			if intVal, err := strconv.Atoi(val); err == nil {
+				return intVal
+			}
+		}
+		return defaultValue
+	}
+
+	// Helper function to parse duration with default
+	parseDuration := func(envVar string, defaultValue time.Duration) time.Duration {
+		if val := os.Getenv(envVar); val != "" {
+			if duration, err := time.ParseDuration(val); err == nil && duration >= 0 {
+				return duration
+			}
+		}
+		return defaultValue
+	}
+
+	return CacheConfig{
+		FallbackEnabled:    parseBool("CACHE_FALLBACK_ENABLED", true),
+		FallbackMaxEntries: parseInt("CACHE_FALLBACK_MAX_ENTRIES", 1000),
+		WarmOnStartup:      parseBool("CACHE_WARM_ON_STARTUP", false),
+		VehicleListSoftTTL: parseDuration("CACHE_VEHICLE_LIST_SOFT_TTL", 0),
+		AdaptiveTTL:        parseBool("CACHE_ADAPTIVE_TTL", false),
+	}
+}
+
+func loadWebSocketConfig() WebSocketConfig {
+	config := WebSocketConfig{
+		ClientBufferSize:      256,
+		AdaptiveClientBuffers: false,
+		CompressionEnabled:    false,
+		CompressionLevel:      1,   // flate.BestSpeed
+		CompressionThreshold:  512, // bytes
+		SnapshotMinInterval:   30 * time.Second,
+		DrainTimeout:          5 * time.Second,
+		PingInterval:          54 * time.Second,
+		ReadDeadline:          60 * time.Second,
+		ClientTimeout:         90 * time.Second,
+	}
+
+	if val := os.Getenv("WS_CLIENT_BUFFER_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			config.ClientBufferSize = size
+		}
+	}
+
+	if val := os.Getenv("WS_ADAPTIVE_CLIENT_BUFFERS"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			config.AdaptiveClientBuffers = enabled
+		}
+	}
+
+	if val := os.Getenv("WS_COMPRESSION_ENABLED"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			config.CompressionEnabled = enabled
+		}
+	}
+
+	if val := os.Getenv("WS_COMPRESSION_LEVEL"); val != "" {
+		if level, err := strconv.Atoi(val); err == nil {
+			config.CompressionLevel = level
+		}
+	}
+
+	if val := os.Getenv("WS_COMPRESSION_THRESHOLD"); val != "" {
+		if threshold, err := strconv.Atoi(val); err == nil && threshold >= 0 {
+			config.CompressionThreshold = threshold
+		}
+	}
+
+	if val := os.Getenv("WS_SNAPSHOT_MIN_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval > 0 {
+			config.SnapshotMinInterval = interval
+		}
+	}
+
+	if val := os.Getenv("WS_MAX_CLIENTS"); val != "" {
+		if maxClients, err := strconv.Atoi(val); err == nil && maxClients >= 0 {
+			config.MaxClients = maxClients
+		}
+	}
+
+	if val := os.Getenv("WS_DRAIN_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout >= 0 {
+			config.DrainTimeout = timeout
+		}
+	}
+
+	if val := os.Getenv("WS_ACK_RESEND_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout >= 0 {
+			config.AckResendTimeout = timeout
+		}
+	}
+
+	if val := os.Getenv("WS_PING_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval > 0 {
+			config.PingInterval = interval
+		}
+	}
+
+	if val := os.Getenv("WS_READ_DEADLINE"); val != "" {
+		if deadline, err := time.ParseDuration(val); err == nil && deadline > 0 {
+			config.ReadDeadline = deadline
+		}
+	}
+
+	if val := os.Getenv("WS_CLIENT_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout > 0 {
+			config.ClientTimeout = timeout
+		}
+	}
+
+	return config
+}
+
+func loadCompressionConfig() CompressionConfig {
+	config := CompressionConfig{
+		Enabled: true,
+		Level:   -1,   // gzip.DefaultCompression
+		MinSize: 1024, // bytes
+	}
+
+	if val := os.Getenv("HTTP_COMPRESSION_ENABLED"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			config.Enabled = enabled
+		}
+	}
+
+	if val := os.Getenv("HTTP_COMPRESSION_LEVEL"); val != "" {
+		if level, err := strconv.Atoi(val); err == nil {
+			config.Level = level
+		}
+	}
+
+	if val := os.Getenv("HTTP_COMPRESSION_MIN_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size >= 0 {
+			config.MinSize = size
+		}
+	}
+
+	return config
+}
+
+func defaultMaintenanceConfig() MaintenanceConfig {
+	return MaintenanceConfig{
+		MinIntervalKm:   500,
+		MaxIntervalKm:   200000,
+		MinIntervalDays: 7,
+		MaxIntervalDays: 1825,
+
+		OverdueScanPageSize:    500,
+		OverdueScanConcurrency: 4,
+
+		ReportingCurrency: "USD",
 	}
 }
 
+func loadMaintenanceConfig() MaintenanceConfig {
+	config := defaultMaintenanceConfig()
+
+	if val := os.Getenv("MAINTENANCE_MIN_INTERVAL_KM"); val != "" {
+		if km, err := strconv.Atoi(val); err == nil && km > 0 {
+			config.MinIntervalKm = km
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_MAX_INTERVAL_KM"); val != "" {
+		if km, err := strconv.Atoi(val); err == nil && km > 0 {
+			config.MaxIntervalKm = km
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_MIN_INTERVAL_DAYS"); val != "" {
+		if days, err := strconv.Atoi(val); err == nil && days > 0 {
+			config.MinIntervalDays = days
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_MAX_INTERVAL_DAYS"); val != "" {
+		if days, err := strconv.Atoi(val); err == nil && days > 0 {
+			config.MaxIntervalDays = days
+		}
+	}
+
+	if config.MinIntervalKm > config.MaxIntervalKm || config.MinIntervalDays > config.MaxIntervalDays {
+		log.Printf("Warning: maintenance interval bounds are inverted, using defaults")
+		return defaultMaintenanceConfig()
+	}
+
+	config.RecurringSchedules = parseRecurringSchedules(os.Getenv("MAINTENANCE_RECURRING_SCHEDULES"))
+	config.SuggestedParts = parseSuggestedParts(os.Getenv("MAINTENANCE_SUGGESTED_PARTS"))
+
+	config.OverdueDigestRecipients = parseRecipients(os.Getenv("MAINTENANCE_OVERDUE_DIGEST_RECIPIENTS"))
+	config.OverdueDigestTime = 8 * time.Hour
+	if val := os.Getenv("MAINTENANCE_OVERDUE_DIGEST_TIME"); val != "" {
+		if at, err := time.Parse("15:04", val); err == nil {
+			config.OverdueDigestTime = time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+		} else {
+			log.Printf("Warning: invalid MAINTENANCE_OVERDUE_DIGEST_TIME %q, expected HH:MM", val)
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_OVERDUE_SCAN_PAGE_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			config.OverdueScanPageSize = size
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_OVERDUE_SCAN_CONCURRENCY"); val != "" {
+		if concurrency, err := strconv.Atoi(val); err == nil && concurrency > 0 {
+			config.OverdueScanConcurrency = concurrency
+		}
+	}
+
+	if val := os.Getenv("MAINTENANCE_REPORTING_CURRENCY"); val != "" {
+		if currency := strings.ToUpper(strings.TrimSpace(val)); len(currency) == 3 {
+			config.ReportingCurrency = currency
+		} else {
+			log.Printf("Warning: invalid MAINTENANCE_REPORTING_CURRENCY %q, expected an ISO 4217 code", val)
+		}
+	}
+
+	return config
+}
+
+// parseRecipients reads a comma-separated list of email addresses
+func parseRecipients(val string) []string {
+	var recipients []string
+	for _, recipient := range strings.Split(val, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients
+}
+
+// parseRecurringSchedules reads a comma-separated list of maintenance types,
+// each optionally followed by =km or =km:days, e.g. "oil_change=10000:180,inspection"
+func parseRecurringSchedules(val string) map[string]RecurringScheduleConfig {
+	schedules := make(map[string]RecurringScheduleConfig)
+	if val == "" {
+		return schedules
+	}
+
+	for _, entry := range strings.Split(val, ",") {
+		maintenanceType, intervals, hasIntervals := strings.Cut(strings.TrimSpace(entry), "=")
+		maintenanceType = strings.TrimSpace(maintenanceType)
+		if maintenanceType == "" {
+			continue
+		}
+
+		var schedule RecurringScheduleConfig
+		if hasIntervals {
+			km, days, hasDays := strings.Cut(intervals, ":")
+			var err error
+			if schedule.IntervalKm, err = strconv.Atoi(strings.TrimSpace(km)); err != nil || schedule.IntervalKm < 0 {
+				log.Printf("Warning: ignoring malformed recurring schedule %q", entry)
+				continue
+			}
+			if hasDays {
+				if schedule.IntervalDays, err = strconv.Atoi(strings.TrimSpace(days)); err != nil || schedule.IntervalDays < 0 {
+					log.Printf("Warning: ignoring malformed recurring schedule %q", entry)
+					continue
+				}
+			}
+		}
+		schedules[maintenanceType] = schedule
+	}
+
+	return schedules
+}
+
+// parseSuggestedParts reads a comma-separated list of maintenance types, each
+// followed by =parts with the parts separated by |, e.g.
+// "oil_change=engine_oil|oil_filter,tire_rotation=". An empty parts list
+// suggests nothing for the type.
+func parseSuggestedParts(val string) map[string][]string {
+	suggestions := make(map[string][]string)
+	if val == "" {
+		return suggestions
+	}
+
+	for _, entry := range strings.Split(val, ",") {
+		maintenanceType, parts, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		maintenanceType = strings.TrimSpace(maintenanceType)
+		if !ok || maintenanceType == "" {
+			log.Printf("Warning: ignoring malformed suggested parts %q", entry)
+			continue
+		}
+
+		suggested := []string{}
+		for _, part := range strings.Split(parts, "|") {
+			if part = strings.TrimSpace(part); part != "" {
+				suggested = append(suggested, part)
+			}
+		}
+		suggestions[maintenanceType] = suggested
+	}
+
+	return suggestions
+}
+
+// loadAlertConfig reads ALERT_SEVERITY_OVERRIDES as a comma-separated list of
+// type=severity pairs, e.g. "speeding=critical,low_fuel=low", and the fuel
+// theft detection settings
+func loadAlertConfig() AlertConfig {
+	config := AlertConfig{
+		SeverityOverrides:    make(map[string]string),
+		FuelTheftSensitivity: 4,
+		FuelTheftWindow:      30 * time.Minute,
+	}
+
+	if val := os.Getenv("ALERT_SEVERITY_OVERRIDES"); val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			alertType, severity, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			alertType = strings.TrimSpace(alertType)
+			severity = strings.ToLower(strings.TrimSpace(severity))
+			if !ok || alertType == "" || severity == "" {
+				log.Printf("Warning: ignoring malformed alert severity override %q", pair)
+				continue
+			}
+			config.SeverityOverrides[alertType] = severity
+		}
+	}
+
+	if val := os.Getenv("FUEL_THEFT_SENSITIVITY"); val != "" {
+		if sensitivity, err := strconv.ParseFloat(val, 64); err == nil && sensitivity > 0 {
+			config.FuelTheftSensitivity = sensitivity
+		}
+	}
+
+	if val := os.Getenv("FUEL_THEFT_WINDOW"); val != "" {
+		if window, err := time.ParseDuration(val); err == nil && window > 0 {
+			config.FuelTheftWindow = window
+		}
+	}
+
+	return config
+}
+
+func loadOfflineConfig() OfflineConfig {
+	config := OfflineConfig{
+		GracePeriod:   30 * time.Minute,
+		OfflineAfter:  15 * time.Minute,
+		SweepInterval: time.Minute,
+	}
+
+	if val := os.Getenv("VEHICLE_OFFLINE_GRACE_PERIOD"); val != "" {
+		if grace, err := time.ParseDuration(val); err == nil && grace >= 0 {
+			config.GracePeriod = grace
+		}
+	}
+
+	if val := os.Getenv("VEHICLE_OFFLINE_AFTER"); val != "" {
+		if after, err := time.ParseDuration(val); err == nil && after > 0 {
+			config.OfflineAfter = after
+		}
+	}
+
+	if val := os.Getenv("VEHICLE_OFFLINE_SWEEP_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval > 0 {
+			config.SweepInterval = interval
+		}
+	}
+
+	return config
+}
+
+func loadVehicleConfig() VehicleConfig {
+	config := VehicleConfig{FuelUnit: "liters", SpeedWindowSize: 60}
+
+	if val := os.Getenv("VEHICLE_FAIL_ON_DUPLICATE_PLATES"); val != "" {
+		if fail, err := strconv.ParseBool(val); err == nil {
+			config.FailOnDuplicatePlates = fail
+		}
+	}
+
+	// Comma-separated, e.g. "costCenter,assetTag"
+	for _, key := range strings.Split(os.Getenv("VEHICLE_INDEXED_METADATA_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			config.IndexedMetadataKeys = append(config.IndexedMetadataKeys, key)
+		}
+	}
+
+	if val := os.Getenv("VEHICLE_FUEL_UNIT"); val != "" {
+		config.FuelUnit = strings.ToLower(strings.TrimSpace(val))
+	}
+
+	if val := os.Getenv("VEHICLE_SPEED_WINDOW_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			config.SpeedWindowSize = size
+		}
+	}
+
+	return config
+}
+
+func loadReloadConfig() ReloadConfig {
+	config := ReloadConfig{
+		File:         os.Getenv("RUNTIME_CONFIG_FILE"),
+		PollInterval: 10 * time.Second,
+	}
+
+	if val := os.Getenv("RUNTIME_CONFIG_POLL_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval > 0 {
+			config.PollInterval = interval
+		}
+	}
+
+	return config
+}
+
 func loadSMTPConfig() SMTPConfig {
 	return SMTPConfig{
 		Host:      getEnvOrDefault("SMTP_HOST", "smtp.gmail.com"),
@@ -241,6 +947,108 @@ func loadSMTPConfig() SMTPConfig {
 	}
 }
 
+func loadAttachmentConfig() AttachmentConfig {
+	config := AttachmentConfig{
+		Store:    getEnvOrDefault("ATTACHMENT_STORE", "local"),
+		LocalDir: getEnvOrDefault("ATTACHMENT_LOCAL_DIR", "data/attachments"),
+		S3: S3AttachmentConfig{
+			Endpoint:        os.Getenv("ATTACHMENT_S3_ENDPOINT"),
+			Region:          os.Getenv("ATTACHMENT_S3_REGION"),
+			Bucket:          os.Getenv("ATTACHMENT_S3_BUCKET"),
+			AccessKeyID:     os.Getenv("ATTACHMENT_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("ATTACHMENT_S3_SECRET_ACCESS_KEY"),
+		},
+		MaxSize: 10 << 20,
+	}
+
+	if val := os.Getenv("ATTACHMENT_MAX_SIZE"); val != "" {
+		if size, err := strconv.ParseInt(val, 10, 64); err == nil && size > 0 {
+			config.MaxSize = size
+		}
+	}
+
+	return config
+}
+
+func loadRoutingConfig() RoutingConfig {
+	config := RoutingConfig{
+		Provider:     os.Getenv("ROUTING_PROVIDER"),
+		OSRMURL:      os.Getenv("ROUTING_OSRM_URL"),
+		GoogleAPIKey: os.Getenv("ROUTING_GOOGLE_API_KEY"),
+		Timeout:      5 * time.Second,
+		CacheTTL:     15 * time.Minute,
+	}
+
+	if val := os.Getenv("ROUTING_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout > 0 {
+			config.Timeout = timeout
+		}
+	}
+
+	if val := os.Getenv("ROUTING_CACHE_TTL"); val != "" {
+		if ttl, err := time.ParseDuration(val); err == nil && ttl > 0 {
+			config.CacheTTL = ttl
+		}
+	}
+
+	return config
+}
+
+func loadDBTimeoutConfig() DBTimeoutConfig {
+	var config DBTimeoutConfig
+	for key, timeout := range map[string]*time.Duration{
+		"DB_READ_TIMEOUT":      &config.Read,
+		"DB_WRITE_TIMEOUT":     &config.Write,
+		"DB_AGGREGATE_TIMEOUT": &config.Aggregate,
+		"DB_STREAM_TIMEOUT":    &config.Stream,
+	} {
+		if val := os.Getenv(key); val != "" {
+			if duration, err := time.ParseDuration(val); err == nil && duration > 0 {
+				*timeout = duration
+			}
+		}
+	}
+	return config
+}
+
+func loadSelfCheckConfig() SelfCheckConfig {
+	config := SelfCheckConfig{Timeout: 5 * time.Second}
+
+	if val := os.Getenv("SELF_CHECK_STRICT"); val != "" {
+		if strict, err := strconv.ParseBool(val); err == nil {
+			config.Strict = strict
+		}
+	}
+
+	if val := os.Getenv("SELF_CHECK_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout > 0 {
+			config.Timeout = timeout
+		}
+	}
+
+	return config
+}
+
+func loadShutdownTimeout() time.Duration {
+	if val := os.Getenv("SHUTDOWN_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return 15 * time.Second
+}
+
+// loadTrustedProxies reads the comma-separated TRUSTED_PROXIES list
+func loadTrustedProxies() []string {
+	var proxies []string
+	for _, val := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if val = strings.TrimSpace(val); val != "" {
+			proxies = append(proxies, val)
+		}
+	}
+	return proxies
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckSeverity says whether a self-check finding stops a strict startup
+type CheckSeverity string
+
+const (
+	// CheckFatal findings leave the server unable to work correctly
+	CheckFatal CheckSeverity = "fatal"
+	// CheckWarning findings are likely mistakes the server can run with
+	CheckWarning CheckSeverity = "warning"
+)
+
+// CheckFinding is a problem found by the startup self-check
+type CheckFinding struct {
+	// Setting names the environment variable or dependency at fault
+	Setting  string
+	Severity CheckSeverity
+	Message  string
+}
+
+// DependencyCheck probes an external dependency during the self-check
+type DependencyCheck struct {
+	Name string
+	// Required dependencies are fatal when unreachable; others only warn
+	Required bool
+	Ping     func(ctx context.Context) error
+}
+
+// SelfCheckReport holds everything the startup self-check found
+type SelfCheckReport struct {
+	Findings []CheckFinding
+}
+
+// Fatal reports whether any finding is fatal
+func (r *SelfCheckReport) Fatal() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == CheckFatal {
+			return true
+		}
+	}
+	return false
+}
+
+// Log writes the report, one line per finding
+func (r *SelfCheckReport) Log() {
+	if len(r.Findings) == 0 {
+		log.Println("Startup self-check passed")
+		return
+	}
+	log.Printf("Startup self-check found %d problem(s):", len(r.Findings))
+	for _, finding := range r.Findings {
+		log.Printf("  [%s] %s: %s", finding.Severity, finding.Setting, finding.Message)
+	}
+}
+
+// SelfCheck validates the configuration and pings each dependency, giving
+// every ping up to timeout. Dependencies are checked one after another so the
+// report reads in a stable order.
+func SelfCheck(ctx context.Context, cfg *Config, timeout time.Duration, dependencies ...DependencyCheck) *SelfCheckReport {
+	report := &SelfCheckReport{Findings: ValidateConfig(cfg)}
+
+	for _, dependency := range dependencies {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := dependency.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			continue
+		}
+		severity := CheckWarning
+		if dependency.Required {
+			severity = CheckFatal
+		}
+		report.Findings = append(report.Findings, CheckFinding{
+			Setting:  dependency.Name,
+			Severity: severity,
+			Message:  fmt.Sprintf("unreachable: %v", err),
+		})
+	}
+
+	return report
+}
+
+// ValidateConfig checks required settings, origins, durations and thresholds.
+// The loaders fall back to defaults for unparseable values, so this catches
+// what they let through: missing values, values that are valid on their own
+// but can't work together, and values only rejected once in use.
+func ValidateConfig(cfg *Config) []CheckFinding {
+	v := &configValidator{}
+
+	// Required settings
+	if cfg.MongoURI == "" {
+		v.fatal("MONGO_URI", "is required")
+	} else if !strings.HasPrefix(cfg.MongoURI, "mongodb://") && !strings.HasPrefix(cfg.MongoURI, "mongodb+srv://") {
+		v.fatal("MONGO_URI", "must start with mongodb:// or mongodb+srv://")
+	}
+	if cfg.JWTSecret == "" {
+		v.fatal("JWT_SECRET", "is required; tokens would be signed with a well-known default key")
+	}
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		v.fatal("PORT", fmt.Sprintf("%q is not a port number", cfg.Port))
+	}
+	v.origins(cfg.AllowedOrigins)
+
+	// Durations
+	if cfg.RateLimit.Enabled {
+		v.positive("RATE_LIMIT_CLEANUP_INTERVAL", cfg.RateLimit.CleanupInterval)
+	}
+	v.notNegative("CACHE_VEHICLE_LIST_SOFT_TTL", cfg.Cache.VehicleListSoftTTL)
+	v.notNegative("WS_SNAPSHOT_MIN_INTERVAL", cfg.WebSocket.SnapshotMinInterval)
+	v.positive("FUEL_THEFT_WINDOW", cfg.Alerts.FuelTheftWindow)
+	v.notNegative("VEHICLE_OFFLINE_GRACE_PERIOD", cfg.Offline.GracePeriod)
+	v.positive("VEHICLE_OFFLINE_AFTER", cfg.Offline.OfflineAfter)
+	v.positive("VEHICLE_OFFLINE_SWEEP_INTERVAL", cfg.Offline.SweepInterval)
+	if cfg.Offline.SweepInterval > cfg.Offline.OfflineAfter {
+		v.warning("VEHICLE_OFFLINE_SWEEP_INTERVAL", "is longer than VEHICLE_OFFLINE_AFTER, so vehicles are marked offline late")
+	}
+	if cfg.Reload.File != "" {
+		v.positive("RUNTIME_CONFIG_POLL_INTERVAL", cfg.Reload.PollInterval)
+	}
+	if cfg.Routing.Provider != "" {
+		v.positive("ROUTING_TIMEOUT", cfg.Routing.Timeout)
+		v.positive("ROUTING_CACHE_TTL", cfg.Routing.CacheTTL)
+	}
+	v.notNegative("DB_READ_TIMEOUT", cfg.DBTimeouts.Read)
+	v.notNegative("DB_WRITE_TIMEOUT", cfg.DBTimeouts.Write)
+	v.notNegative("DB_AGGREGATE_TIMEOUT", cfg.DBTimeouts.Aggregate)
+	v.notNegative("DB_STREAM_TIMEOUT", cfg.DBTimeouts.Stream)
+	v.positive("SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout)
+	if cfg.RedisEnabled {
+		v.positive("REDIS_DIAL_TIMEOUT", cfg.Redis.DialTimeout)
+		v.positive("REDIS_READ_TIMEOUT", cfg.Redis.ReadTimeout)
+		v.positive("REDIS_WRITE_TIMEOUT", cfg.Redis.WriteTimeout)
+		if cfg.Redis.PoolSize <= 0 {
+			v.fatal("REDIS_POOL_SIZE", "must be positive")
+		}
+	}
+
+	// Thresholds and sizes
+	if cfg.Alerts.FuelTheftSensitivity <= 0 {
+		v.fatal("FUEL_THEFT_SENSITIVITY", fmt.Sprintf("must be positive, got %g", cfg.Alerts.FuelTheftSensitivity))
+	}
+	for alertType, severity := range cfg.Alerts.SeverityOverrides {
+		switch severity {
+		case "low", "medium", "high", "critical":
+		default:
+			v.fatal("ALERT_SEVERITY_OVERRIDES", fmt.Sprintf("%q is not a severity for %s alerts", severity, alertType))
+		}
+	}
+	if cfg.Cache.FallbackEnabled && cfg.Cache.FallbackMaxEntries <= 0 {
+		v.fatal("CACHE_FALLBACK_MAX_ENTRIES", "must be positive while the fallback cache is enabled")
+	}
+	if cfg.WebSocket.ClientBufferSize <= 0 {
+		v.fatal("WS_CLIENT_BUFFER_SIZE", "must be positive")
+	}
+	// flate and gzip accept -2 (Huffman only) through 9 (best compression)
+	if cfg.WebSocket.CompressionEnabled && (cfg.WebSocket.CompressionLevel < -2 || cfg.WebSocket.CompressionLevel > 9) {
+		v.fatal("WS_COMPRESSION_LEVEL", fmt.Sprintf("must be between -2 and 9, got %d", cfg.WebSocket.CompressionLevel))
+	}
+	if cfg.Compression.Enabled && (cfg.Compression.Level < -2 || cfg.Compression.Level > 9) {
+		v.fatal("HTTP_COMPRESSION_LEVEL", fmt.Sprintf("must be between -2 and 9, got %d", cfg.Compression.Level))
+	}
+	if cfg.Maintenance.MinIntervalKm <= 0 || cfg.Maintenance.MinIntervalKm > cfg.Maintenance.MaxIntervalKm {
+		v.fatal("MAINTENANCE_MIN_INTERVAL_KM", "must be positive and no more than MAINTENANCE_MAX_INTERVAL_KM")
+	}
+	if cfg.Maintenance.MinIntervalDays <= 0 || cfg.Maintenance.MinIntervalDays > cfg.Maintenance.MaxIntervalDays {
+		v.fatal("MAINTENANCE_MIN_INTERVAL_DAYS", "must be positive and no more than MAINTENANCE_MAX_INTERVAL_DAYS")
+	}
+	if cfg.Maintenance.OverdueScanPageSize <= 0 {
+		v.fatal("MAINTENANCE_OVERDUE_SCAN_PAGE_SIZE", "must be positive")
+	}
+	if cfg.Maintenance.OverdueScanConcurrency <= 0 {
+		v.fatal("MAINTENANCE_OVERDUE_SCAN_CONCURRENCY", "must be positive")
+	}
+	if cfg.Vehicles.FuelUnit != "liters" && cfg.Vehicles.FuelUnit != "gallons" {
+		v.fatal("VEHICLE_FUEL_UNIT", fmt.Sprintf("must be liters or gallons, got %q", cfg.Vehicles.FuelUnit))
+	}
+	if len(cfg.Maintenance.OverdueDigestRecipients) > 0 && cfg.SMTP.Username == "" {
+		v.warning("SMTP_USERNAME", "is not set, so the overdue reminder digest can't be sent")
+	}
+
+	// Optional integrations
+	switch cfg.Attachments.Store {
+	case "local", "s3":
+		if cfg.Attachments.MaxSize <= 0 {
+			v.fatal("ATTACHMENT_MAX_SIZE", "must be positive")
+		}
+		if cfg.Attachments.Store == "s3" && cfg.Attachments.S3.Bucket == "" {
+			v.fatal("ATTACHMENT_S3_BUCKET", "is required for the s3 attachment store")
+		}
+	}
+	switch cfg.Routing.Provider {
+	case "", "osrm":
+	case "google":
+		if cfg.Routing.GoogleAPIKey == "" {
+			v.fatal("ROUTING_GOOGLE_API_KEY", "is required for the google routing provider")
+		}
+	default:
+		v.fatal("ROUTING_PROVIDER", fmt.Sprintf("unknown provider %q", cfg.Routing.Provider))
+	}
+
+	return v.findings
+}
+
+// configValidator collects findings in the order they are made
+type configValidator struct {
+	findings []CheckFinding
+}
+
+func (v *configValidator) fatal(setting, message string) {
+	v.findings = append(v.findings, CheckFinding{Setting: setting, Severity: CheckFatal, Message: message})
+}
+
+func (v *configValidator) warning(setting, message string) {
+	v.findings = append(v.findings, CheckFinding{Setting: setting, Severity: CheckWarning, Message: message})
+}
+
+func (v *configValidator) positive(setting string, d time.Duration) {
+	if d <= 0 {
+		v.fatal(setting, fmt.Sprintf("must be positive, got %s", d))
+	}
+}
+
+func (v *configValidator) notNegative(setting string, d time.Duration) {
+	if d < 0 {
+		v.fatal(setting, fmt.Sprintf("must not be negative, got %s", d))
+	}
+}
+
+// origins checks that allowed origins are either a lone "*" or http(s)
+// origins without a path, which is all the CORS middleware can match
+func (v *configValidator) origins(origins []string) {
+	if len(origins) == 0 {
+		v.fatal("ALLOWED_ORIGINS", "is empty")
+		return
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			if len(origins) > 1 {
+				v.fatal("ALLOWED_ORIGINS", `"*" can't be combined with other origins`)
+			}
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "" {
+			v.fatal("ALLOWED_ORIGINS", fmt.Sprintf("%q is not an http(s) origin", origin))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadValidConfig loads the defaults with the required settings present
+func loadValidConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("MONGO_URI", "mongodb://localhost:27017/fleet")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("ALLOWED_ORIGINS", "https://fleet.example.com,http://localhost:3000")
+	return config.Load()
+}
+
+// findingFor returns the finding about setting, if any
+func findingFor(findings []config.CheckFinding, setting string) (config.CheckFinding, bool) {
+	for _, finding := range findings {
+		if finding.Setting == setting {
+			return finding, true
+		}
+	}
+	return config.CheckFinding{}, false
+}
+
+func TestValidateConfig_DefaultsPass(t *testing.T) {
+	cfg := loadValidConfig(t)
+
+	assert.Empty(t, config.ValidateConfig(cfg))
+}
+
+func TestValidateConfig_MissingRequiredSetting(t *testing.T) {
+	cfg := loadValidConfig(t)
+	cfg.JWTSecret = ""
+
+	finding, ok := findingFor(config.ValidateConfig(cfg), "JWT_SECRET")
+
+	require.True(t, ok)
+	assert.Equal(t, config.CheckFatal, finding.Severity)
+}
+
+func TestValidateConfig_InvalidThreshold(t *testing.T) {
+	cfg := loadValidConfig(t)
+	cfg.Alerts.FuelTheftSensitivity = -2
+	cfg.Maintenance.MinIntervalKm = cfg.Maintenance.MaxIntervalKm + 1
+
+	findings := config.ValidateConfig(cfg)
+
+	finding, ok := findingFor(findings, "FUEL_THEFT_SENSITIVITY")
+	require.True(t, ok)
+	assert.Equal(t, config.CheckFatal, finding.Severity)
+	_, ok = findingFor(findings, "MAINTENANCE_MIN_INTERVAL_KM")
+	assert.True(t, ok)
+}
+
+func TestValidateConfig_Origins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		valid   bool
+	}{
+		{"wildcard", []string{"*"}, true},
+		{"origins", []string{"https://fleet.example.com", "http://localhost:3000"}, true},
+		{"wildcard with others", []string{"*", "https://fleet.example.com"}, false},
+		{"missing scheme", []string{"fleet.example.com"}, false},
+		{"with path", []string{"https://fleet.example.com/app"}, false},
+		{"leading space", []string{"https://a.example.com", " https://b.example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadValidConfig(t)
+			cfg.AllowedOrigins = tt.origins
+
+			_, found := findingFor(config.ValidateConfig(cfg), "ALLOWED_ORIGINS")
+
+			assert.Equal(t, !tt.valid, found)
+		})
+	}
+}
+
+func TestValidateConfig_NonPositiveInterval(t *testing.T) {
+	cfg := loadValidConfig(t)
+	cfg.Offline.SweepInterval = 0
+
+	finding, ok := findingFor(config.ValidateConfig(cfg), "VEHICLE_OFFLINE_SWEEP_INTERVAL")
+
+	require.True(t, ok)
+	assert.Equal(t, config.CheckFatal, finding.Severity)
+}
+
+func TestSelfCheck_UnreachableDependencies(t *testing.T) {
+	cfg := loadValidConfig(t)
+	unreachable := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	report := config.SelfCheck(context.Background(), cfg, time.Second,
+		config.DependencyCheck{Name: "Redis", Ping: unreachable},
+	)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, config.CheckWarning, report.Findings[0].Severity)
+	assert.False(t, report.Fatal())
+
+	report = config.SelfCheck(context.Background(), cfg, time.Second,
+		config.DependencyCheck{Name: "MongoDB", Required: true, Ping: unreachable},
+	)
+	assert.True(t, report.Fatal())
+}
+
+func TestSelfCheck_PingsAreBoundedByTimeout(t *testing.T) {
+	cfg := loadValidConfig(t)
+	hangs := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	start := time.Now()
+	report := config.SelfCheck(context.Background(), cfg, 20*time.Millisecond,
+		config.DependencyCheck{Name: "MongoDB", Required: true, Ping: hangs},
+	)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, report.Fatal())
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Bounds for settings that can be changed while the server is running
+const (
+	maxRuntimeBatchSize     = 10000
+	minRuntimeBatchInterval = 100 * time.Millisecond
+	maxRuntimeCacheTTL      = 24 * time.Hour
+)
+
+var runtimeAlertSeverities = map[string]bool{"low": true, "medium": true, "high": true, "critical": true}
+
+// Duration is a time.Duration written as a Go duration string, e.g. "30s"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RuntimeConfig holds the settings that can be changed without a restart.
+// Omitted (zero) settings keep their current values.
+type RuntimeConfig struct {
+	Batch     RuntimeBatchConfig     `json:"batch"`
+	Cache     RuntimeCacheConfig     `json:"cache"`
+	Telemetry RuntimeTelemetryConfig `json:"telemetry"`
+	Alerts    AlertConfig            `json:"alerts"`
+}
+
+type RuntimeBatchConfig struct {
+	MaxBatchSize  int      `json:"maxBatchSize,omitempty"`
+	BatchInterval Duration `json:"batchInterval,omitempty"`
+}
+
+type RuntimeCacheConfig struct {
+	VehicleDataTTL  Duration `json:"vehicleDataTTL,omitempty"`
+	VehicleListTTL  Duration `json:"vehicleListTTL,omitempty"`
+	SearchResultTTL Duration `json:"searchResultTTL,omitempty"`
+	FilteredListTTL Duration `json:"filteredListTTL,omitempty"`
+}
+
+type RuntimeTelemetryConfig struct {
+	// Speed plausibility bounds; both are applied when MaxPlausibleSpeedKmh is set
+	MinPlausibleSpeedKmh int `json:"minPlausibleSpeedKmh,omitempty"`
+	MaxPlausibleSpeedKmh int `json:"maxPlausibleSpeedKmh,omitempty"`
+}
+
+// Validate checks every setting is within safe bounds
+func (c RuntimeConfig) Validate() error {
+	if c.Batch.MaxBatchSize < 0 || c.Batch.MaxBatchSize > maxRuntimeBatchSize {
+		return fmt.Errorf("batch.maxBatchSize must be between 1 and %d", maxRuntimeBatchSize)
+	}
+	if interval := time.Duration(c.Batch.BatchInterval); interval != 0 && interval < minRuntimeBatchInterval {
+		return fmt.Errorf("batch.batchInterval must be at least %v", minRuntimeBatchInterval)
+	}
+
+	ttls := map[string]Duration{
+		"cache.vehicleDataTTL":  c.Cache.VehicleDataTTL,
+		"cache.vehicleListTTL":  c.Cache.VehicleListTTL,
+		"cache.searchResultTTL": c.Cache.SearchResultTTL,
+		"cache.filteredListTTL": c.Cache.FilteredListTTL,
+	}
+	for name, ttl := range ttls {
+		if ttl < 0 || time.Duration(ttl) > maxRuntimeCacheTTL {
+			return fmt.Errorf("%s must be between 0 and %v", name, maxRuntimeCacheTTL)
+		}
+	}
+
+	if c.Telemetry.MinPlausibleSpeedKmh < 0 {
+		return errors.New("telemetry.minPlausibleSpeedKmh must not be negative")
+	}
+	if c.Telemetry.MaxPlausibleSpeedKmh != 0 && c.Telemetry.MaxPlausibleSpeedKmh <= c.Telemetry.MinPlausibleSpeedKmh {
+		return errors.New("telemetry.maxPlausibleSpeedKmh must be greater than telemetry.minPlausibleSpeedKmh")
+	}
+
+	for alertType, severity := range c.Alerts.SeverityOverrides {
+		if !runtimeAlertSeverities[severity] {
+			return fmt.Errorf("alerts.severityOverrides.%s: invalid severity %q, expected low, medium, high or critical", alertType, severity)
+		}
+	}
+	return nil
+}
+
+// settings flattens the configured values into dotted names for change logging
+func (c RuntimeConfig) settings() map[string]string {
+	settings := make(map[string]string)
+	setInt := func(name string, value int) {
+		if value != 0 {
+			settings[name] = strconv.Itoa(value)
+		}
+	}
+	setDuration := func(name string, value Duration) {
+		if value != 0 {
+			settings[name] = time.Duration(value).String()
+		}
+	}
+
+	setInt("batch.maxBatchSize", c.Batch.MaxBatchSize)
+	setDuration("batch.batchInterval", c.Batch.BatchInterval)
+	setDuration("cache.vehicleDataTTL", c.Cache.VehicleDataTTL)
+	setDuration("cache.vehicleListTTL", c.Cache.VehicleListTTL)
+	setDuration("cache.searchResultTTL", c.Cache.SearchResultTTL)
+	setDuration("cache.filteredListTTL", c.Cache.FilteredListTTL)
+	setInt("telemetry.minPlausibleSpeedKmh", c.Telemetry.MinPlausibleSpeedKmh)
+	setInt("telemetry.maxPlausibleSpeedKmh", c.Telemetry.MaxPlausibleSpeedKmh)
+	for alertType, severity := range c.Alerts.SeverityOverrides {
+		settings["alerts.severityOverrides."+alertType] = severity
+	}
+	return settings
+}
+
+// changes describes each setting that differs between two configs
+func changes(from, to RuntimeConfig) []string {
+	before, after := from.settings(), to.settings()
+
+	var changed []string
+	for name, value := range after {
+		if previous, ok := before[name]; !ok {
+			changed = append(changed, fmt.Sprintf("%s: (unset) -> %s", name, value))
+		} else if previous != value {
+			changed = append(changed, fmt.Sprintf("%s: %s -> %s", name, previous, value))
+		}
+	}
+	for name, previous := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, fmt.Sprintf("%s: %s -> (unset)", name, previous))
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Watcher polls a JSON runtime config file and hands validated changes to the
+// registered appliers, so thresholds and intervals can be tuned without a
+// restart. Invalid files are logged and ignored; the last good config stays in effect.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	mu       sync.Mutex
+	appliers []func(RuntimeConfig)
+	current  RuntimeConfig
+	lastRaw  []byte
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher creates a watcher for the runtime config file at path
+func NewWatcher(path string, interval time.Duration) *Watcher {
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// OnChange registers a function called with the full config after each
+// accepted change. Appliers should skip zero (omitted) settings.
+func (w *Watcher) OnChange(apply func(RuntimeConfig)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.appliers = append(w.appliers, apply)
+}
+
+// Current returns the config most recently applied
+func (w *Watcher) Current() RuntimeConfig {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start loads the file and then polls it for changes until Stop is called
+func (w *Watcher) Start() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.reloadAndLog()
+	for {
+		select {
+		case <-ticker.C:
+			w.reloadAndLog()
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// Stop stops polling
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stopChan) })
+}
+
+func (w *Watcher) reloadAndLog() {
+	if _, err := w.Reload(); err != nil {
+		log.Printf("Warning: runtime config %s not applied: %v", w.path, err)
+	}
+}
+
+// Reload reads the file and applies it if it changed and is valid. It returns
+// whether a new config was applied.
+func (w *Watcher) Reload() (bool, error) {
+	raw, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lastRaw != nil && bytes.Equal(raw, w.lastRaw) {
+		return false, nil
+	}
+
+	var next RuntimeConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&next); err != nil {
+		w.lastRaw = raw // don't report the same bad file every poll
+		return false, fmt.Errorf("invalid runtime config: %w", err)
+	}
+	if err := next.Validate(); err != nil {
+		w.lastRaw = raw
+		return false, fmt.Errorf("invalid runtime config: %w", err)
+	}
+	w.lastRaw = raw
+
+	changed := changes(w.current, next)
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	for _, change := range changed {
+		log.Printf("Runtime config changed %s", change)
+	}
+	w.current = next
+	for _, apply := range w.appliers {
+		apply(next)
+	}
+	return true, nil
+}
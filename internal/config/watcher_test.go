@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/config"
+	"fleet-backend/pkg/batch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noopRepository struct{}
+
+func (noopRepository) UpdateVehicle(vehicleID string, update batch.VehicleUpdateData) error {
+	return nil
+}
+
+func (noopRepository) UpdateVehiclesBatch(updates map[string]batch.VehicleUpdateData) error {
+	return nil
+}
+
+func writeRuntimeConfig(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func TestWatcher_AppliesNewBatchSizeWithinPollInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"batch": {"maxBatchSize": 50}}`)
+
+	processor := batch.NewBatchProcessor(batch.DefaultBatchConfig(), noopRepository{})
+	require.NoError(t, processor.Start())
+	defer processor.Stop()
+
+	const pollInterval = 50 * time.Millisecond
+	watcher := config.NewWatcher(path, pollInterval)
+	watcher.OnChange(func(runtime config.RuntimeConfig) {
+		if runtime.Batch.MaxBatchSize > 0 {
+			processor.SetBatchSize(runtime.Batch.MaxBatchSize)
+		}
+		if runtime.Batch.BatchInterval > 0 {
+			processor.SetBatchInterval(time.Duration(runtime.Batch.BatchInterval))
+		}
+	})
+	go watcher.Start()
+	defer watcher.Stop()
+
+	require.Eventually(t, func() bool {
+		return watcher.Current().Batch.MaxBatchSize == 50
+	}, time.Second, 5*time.Millisecond)
+
+	writeRuntimeConfig(t, path, `{"batch": {"maxBatchSize": 200, "batchInterval": "5s"}}`)
+
+	assert.Eventually(t, func() bool {
+		return processor.GetConfig().MaxBatchSize == 200
+	}, 2*pollInterval, 5*time.Millisecond)
+	assert.Equal(t, 5*time.Second, processor.GetConfig().BatchInterval)
+}
+
+func TestWatcher_RejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"batch": {"maxBatchSize": 100}, "alerts": {"severityOverrides": {"speeding": "critical"}}}`)
+
+	applied := 0
+	watcher := config.NewWatcher(path, time.Hour)
+	watcher.OnChange(func(config.RuntimeConfig) { applied++ })
+
+	changed, err := watcher.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, applied)
+
+	invalid := []string{
+		`{"batch": {"maxBatchSize": -5}}`,
+		`{"batch": {"batchInterval": "1ms"}}`,
+		`{"batch": {"batchInterval": 30}}`,
+		`{"cache": {"vehicleDataTTL": "-1s"}}`,
+		`{"telemetry": {"minPlausibleSpeedKmh": 100, "maxPlausibleSpeedKmh": 50}}`,
+		`{"alerts": {"severityOverrides": {"speeding": "urgent"}}}`,
+		`{"batch": {"maxBatchSize": 100}, "unknownSection": {}}`,
+		`{"batch": `,
+	}
+	for _, contents := range invalid {
+		writeRuntimeConfig(t, path, contents)
+		changed, err := watcher.Reload()
+		assert.Error(t, err, contents)
+		assert.False(t, changed, contents)
+	}
+
+	// The last good config stays in effect
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 100, watcher.Current().Batch.MaxBatchSize)
+	assert.Equal(t, "critical", watcher.Current().Alerts.SeverityOverrides["speeding"])
+}
+
+func TestWatcher_IgnoresUnchangedSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"cache": {"vehicleDataTTL": "45s"}}`)
+
+	applied := 0
+	watcher := config.NewWatcher(path, time.Hour)
+	watcher.OnChange(func(config.RuntimeConfig) { applied++ })
+
+	_, err := watcher.Reload()
+	require.NoError(t, err)
+
+	// Reformatting the file doesn't change any setting
+	writeRuntimeConfig(t, path, "{\n  \"cache\": {\"vehicleDataTTL\": \"45000ms\"}\n}\n")
+	changed, err := watcher.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, applied)
+}
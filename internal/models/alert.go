@@ -9,10 +9,15 @@ import (
 type Alert struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	VehicleID  string             `bson:"vehicle_id" json:"vehicleId" validate:"required"`
-	Type       string             `bson:"type" json:"type" validate:"required,oneof=fuel_theft maintenance speeding unauthorized low_fuel"`
+	Type       string             `bson:"type" json:"type" validate:"required,oneof=fuel_theft maintenance speeding unauthorized low_fuel geofence_exit odometer_anomaly speed_anomaly"`
 	Message    string             `bson:"message" json:"message" validate:"required"`
 	Severity   string             `bson:"severity" json:"severity" validate:"required,oneof=low medium high critical"`
 	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
 	Resolved   bool               `bson:"resolved" json:"resolved"`
 	ResolvedAt *time.Time         `bson:"resolved_at,omitempty" json:"resolvedAt,omitempty"`
+	// Acknowledged alerts have been claimed by a user but stay unresolved
+	Acknowledged   bool       `bson:"acknowledged" json:"acknowledged"`
+	AcknowledgedBy string     `bson:"acknowledged_by,omitempty" json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `bson:"acknowledged_at,omitempty" json:"acknowledgedAt,omitempty"`
+	AckNote        string     `bson:"ack_note,omitempty" json:"ackNote,omitempty"`
 }
\ No newline at end of file
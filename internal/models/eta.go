@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ETA methods describing how an arrival estimate was computed
+const (
+	// ETAMethodStraightLine uses the great-circle distance and the vehicle's
+	// recent average speed; it ignores roads, so it is only an approximation
+	ETAMethodStraightLine = "straight_line"
+	// ETAMethodRoad uses the road distance and driving time from a routing provider
+	ETAMethodRoad = "road"
+)
+
+// ETAEstimate is an estimated time of arrival of a vehicle at a destination.
+// A stationary vehicle has no estimate: Moving is false and DurationSeconds
+// and EstimatedArrival are left empty.
+type ETAEstimate struct {
+	VehicleID   string   `json:"vehicleId"`
+	Origin      Location `json:"origin"`
+	Destination Location `json:"destination"`
+	DistanceKm  float64  `json:"distanceKm"`
+	// AverageSpeedKmh is the vehicle's recent average speed, which the
+	// straight-line estimate assumes it keeps
+	AverageSpeedKmh  float64    `json:"averageSpeedKmh"`
+	Moving           bool       `json:"moving"`
+	DurationSeconds  *float64   `json:"durationSeconds,omitempty"`
+	EstimatedArrival *time.Time `json:"estimatedArrival,omitempty"`
+	Method           string     `json:"method"`
+	Provider         string     `json:"provider,omitempty"`
+	Approximate      bool       `json:"approximate"`
+	Note             string     `json:"note,omitempty"`
+	ComputedAt       time.Time  `json:"computedAt"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExchangeRate is the price of one unit of BaseCurrency in QuoteCurrency from
+// EffectiveAt until a later rate for the same pair takes effect. Currencies
+// are ISO 4217 codes.
+type ExchangeRate struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BaseCurrency  string             `json:"baseCurrency" bson:"base_currency"`
+	QuoteCurrency string             `json:"quoteCurrency" bson:"quote_currency"`
+	Rate          float64            `json:"rate" bson:"rate"`
+	EffectiveAt   time.Time          `json:"effectiveAt" bson:"effective_at"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// RecordedExchangeRate is the rate that converted a maintenance record's cost
+// into the reporting currency when the service was performed. It stays with
+// the record, so reports keep using it after newer rates are added.
+type RecordedExchangeRate struct {
+	Currency    string    `json:"currency" bson:"currency"`
+	Rate        float64   `json:"rate" bson:"rate"`
+	EffectiveAt time.Time `json:"effectiveAt" bson:"effective_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// Units fuel can be presented in. Fuel levels and capacities are always
+// stored in liters and converted at the API boundary; telemetry and CSV
+// imports and exports use liters.
+const (
+	FuelUnitLiters  = "liters"
+	FuelUnitGallons = "gallons" // US gallons
+)
+
+// LitersPerGallon is the size of a US gallon
+const LitersPerGallon = 3.785411784
+
+// IsValidFuelUnit reports whether unit is a known fuel unit
+func IsValidFuelUnit(unit string) bool {
+	return unit == FuelUnitLiters || unit == FuelUnitGallons
+}
+
+// FuelToLiters converts an amount of fuel in unit to liters
+func FuelToLiters(amount float64, unit string) float64 {
+	if unit == FuelUnitGallons {
+		return amount * LitersPerGallon
+	}
+	return amount
+}
+
+// FuelFromLiters converts an amount of fuel in liters to unit
+func FuelFromLiters(liters float64, unit string) float64 {
+	if unit == FuelUnitGallons {
+		return liters / LitersPerGallon
+	}
+	return liters
+}
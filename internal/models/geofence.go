@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Geofence shapes
+const (
+	GeofenceTypeCircle  = "circle"
+	GeofenceTypePolygon = "polygon"
+)
+
+type Geofence struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name          string             `bson:"name" json:"name" validate:"required"`
+	Description   string             `bson:"description,omitempty" json:"description,omitempty"`
+	Type          string             `bson:"type" json:"type" validate:"required,oneof=circle polygon"`
+	Center        *GeoPoint          `bson:"center,omitempty" json:"center,omitempty"`                 // circle only
+	RadiusMeters  float64            `bson:"radius_meters,omitempty" json:"radiusMeters,omitempty"`    // circle only
+	Polygon       []GeoPoint         `bson:"polygon,omitempty" json:"polygon,omitempty"`               // polygon only, ordered vertices
+	SpeedLimitKmh int                `bson:"speed_limit_kmh,omitempty" json:"speedLimitKmh,omitempty"` // speed limit inside the zone, 0 for none
+	CreatedAt     time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+type GeoPoint struct {
+	Lat float64 `bson:"lat" json:"lat" validate:"min=-90,max=90"`
+	Lng float64 `bson:"lng" json:"lng" validate:"min=-180,max=180"`
+}
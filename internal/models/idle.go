@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdleSegment is a closed period a vehicle spent idling: stopped with the engine on
+type IdleSegment struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID       string             `bson:"vehicle_id" json:"vehicleId"`
+	StartedAt       time.Time          `bson:"started_at" json:"startedAt"`
+	EndedAt         time.Time          `bson:"ended_at" json:"endedAt"`
+	DurationSeconds float64            `bson:"duration_seconds" json:"durationSeconds"`
+}
+
+// IdleStats is the idle time a vehicle accumulated within a time range.
+// Segments overlapping the range edges are clipped to it.
+type IdleStats struct {
+	VehicleID    string         `json:"vehicleId"`
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	IdleSeconds  float64        `json:"idleSeconds"`
+	SegmentCount int            `json:"segmentCount"`
+	Segments     []*IdleSegment `json:"segments"`
+	// IdleSince is set while the vehicle is idling; the open segment is included in IdleSeconds
+	IdleSince *time.Time `json:"idleSince,omitempty"`
+}
@@ -13,6 +13,7 @@ type MaintenanceRecord struct {
 	Description          string             `json:"description" bson:"description"`
 	Cost                 float64            `json:"cost" bson:"cost"`
 	Currency             string             `json:"currency" bson:"currency"`
+	ExchangeRate         *RecordedExchangeRate `json:"exchangeRate,omitempty" bson:"exchange_rate,omitempty"`
 	ServiceCenter        string             `json:"serviceCenter" bson:"service_center"`
 	PerformedAt          time.Time          `json:"performedAt" bson:"performed_at"`
 	Odometer             int                `json:"odometer" bson:"odometer"`
@@ -20,12 +21,49 @@ type MaintenanceRecord struct {
 	NextServiceOdometer  int                `json:"nextServiceOdometer" bson:"next_service_odometer"`
 	NextServiceDate      *time.Time         `json:"nextServiceDate,omitempty" bson:"next_service_date,omitempty"`
 	PartsReplaced        []string           `json:"partsReplaced" bson:"parts_replaced"`
+	Replacements         []PartReplacement  `json:"replacements,omitempty" bson:"replacements,omitempty"`
 	Notes                string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	Attachments          []Attachment       `json:"attachments,omitempty" bson:"attachments,omitempty"`
 	Status               string             `json:"status" bson:"status"`
 	CreatedAt            time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt            time.Time          `json:"updatedAt" bson:"updated_at"`
 }
 
+// Attachment is a supporting document of a maintenance record, such as a
+// receipt or a photo. Only its metadata is kept with the record; the contents
+// live in the blob store under StorageKey.
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"contentType" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	StorageKey  string             `json:"-" bson:"storage_key"`
+	UploadedAt  time.Time          `json:"uploadedAt" bson:"uploaded_at"`
+}
+
+// PartReplacement is a replaced part with its warranty terms. A warranty ends
+// at whichever of WarrantyMonths or WarrantyKm is reached first; zero means
+// that limit does not apply.
+type PartReplacement struct {
+	Name           string  `json:"name" bson:"name" validate:"required"`
+	WarrantyMonths int     `json:"warrantyMonths,omitempty" bson:"warranty_months,omitempty" validate:"min=0"`
+	WarrantyKm     int     `json:"warrantyKm,omitempty" bson:"warranty_km,omitempty" validate:"min=0"`
+	Cost           float64 `json:"cost,omitempty" bson:"cost,omitempty" validate:"min=0"`
+}
+
+// PartWarranty is a replaced part that is still under warranty
+type PartWarranty struct {
+	RecordID         primitive.ObjectID `json:"recordId"`
+	PartName         string             `json:"partName"`
+	ReplacedAt       time.Time          `json:"replacedAt"`
+	ReplacedOdometer int                `json:"replacedOdometer"`
+	Cost             float64            `json:"cost,omitempty"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty"`
+	ExpiresOdometer  *int               `json:"expiresOdometer,omitempty"`
+	DaysRemaining    *int               `json:"daysRemaining,omitempty"`
+	KmRemaining      *int               `json:"kmRemaining,omitempty"`
+}
+
 type MaintenanceSchedule struct {
 	ID                   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	VehicleID            primitive.ObjectID `json:"vehicleId" bson:"vehicle_id"`
@@ -54,6 +92,8 @@ type ServiceReminder struct {
 	OdometerUntilDue  *int               `json:"odometerUntilDue,omitempty" bson:"odometer_until_due,omitempty"`
 	Priority          string             `json:"priority" bson:"priority"`
 	IsOverdue         bool               `json:"isOverdue" bson:"is_overdue"`
+	// LastNotifiedAt is when the reminder was last included in an overdue digest email
+	LastNotifiedAt    *time.Time         `json:"lastNotifiedAt,omitempty" bson:"last_notified_at,omitempty"`
 	CreatedAt         time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt         time.Time          `json:"updatedAt" bson:"updated_at"`
 }
@@ -203,4 +243,34 @@ var CommonPartsForService = map[string][]string{
 	MaintenanceTypeTireRotation: {
 		// Usually no parts replaced, just service
 	},
-}
\ No newline at end of file
+}
+
+// MaintenanceForecast predicts when a vehicle will reach its next service
+// odometer from its recent daily mileage
+type MaintenanceForecast struct {
+	VehicleID           primitive.ObjectID `json:"vehicleId"`
+	VehicleName         string             `json:"vehicleName"`
+	CurrentOdometer     int                `json:"currentOdometer"`
+	NextServiceOdometer int                `json:"nextServiceOdometer"`
+	KmRemaining         int                `json:"kmRemaining"`
+	RecordDailyKm       float64            `json:"recordDailyKm"`                // from maintenance history
+	TelemetryDailyKm    float64            `json:"telemetryDailyKm,omitempty"`   // from recent odometer samples
+	DailyKm             float64            `json:"dailyKm"`                      // blended estimate used for the prediction
+	StaticDueDate       *time.Time         `json:"staticDueDate,omitempty"`      // estimate stored with the last service
+	PredictedDueDate    time.Time          `json:"predictedDueDate"`
+	DaysUntilDue        int                `json:"daysUntilDue"`
+}
+
+// Reasons an active schedule is reported as never serviced
+const (
+	UnservicedReasonNoRecords             = "no_matching_records"
+	UnservicedReasonPredatesCommissioning = "last_service_predates_commissioning"
+)
+
+// UnservicedSchedule is an active maintenance schedule with no evidence that
+// the vehicle was ever serviced under it
+type UnservicedSchedule struct {
+	Schedule              *MaintenanceSchedule `json:"schedule"`
+	Reasons               []string             `json:"reasons"`
+	VehicleCommissionedAt *time.Time           `json:"vehicleCommissionedAt,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// DriverScorecard rates a driver's safety and efficiency over a period from
+// 0 to 100, higher being better. Every component is normalized per 100 km
+// driven, so drivers covering different distances compare fairly.
+type DriverScorecard struct {
+	DriverID   string    `json:"driverId"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	VehicleIDs []string  `json:"vehicleIds"`
+	DistanceKm float64   `json:"distanceKm"`
+	// Speeding counts speeding alerts, HarshEvents harsh accelerations and
+	// brakings, and Idle minutes spent idling
+	Speeding    ScorecardComponent `json:"speeding"`
+	HarshEvents ScorecardComponent `json:"harshEvents"`
+	Idle        ScorecardComponent `json:"idle"`
+	// Score is the weighted average of the component scores
+	Score float64 `json:"score"`
+}
+
+// ScorecardComponent is one behavior counted towards a scorecard
+type ScorecardComponent struct {
+	Total    float64 `json:"total"`
+	Per100Km float64 `json:"per100Km"`
+	Score    float64 `json:"score"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SpeedSample is one speed reading kept for speed statistics
+type SpeedSample struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID string             `bson:"vehicle_id" json:"vehicleId"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+	SpeedKmh  int                `bson:"speed_kmh" json:"speedKmh"`
+	Location  *Location          `bson:"location,omitempty" json:"location,omitempty"`
+}
+
+// SpeedStats summarizes a vehicle's speed readings within a time range. Each
+// reading holds until the next one; readings further apart than the gap
+// threshold leave a gap that counts towards neither the average nor the time
+// over the limit.
+type SpeedStats struct {
+	VehicleID   string    `json:"vehicleId"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	SampleCount int       `json:"sampleCount"`
+	// AverageKmh is weighted by how long each reading held
+	AverageKmh       float64 `json:"averageKmh"`
+	MaxKmh           int     `json:"maxKmh"`
+	P95Kmh           int     `json:"p95Kmh"`
+	OverLimitSeconds float64 `json:"overLimitSeconds"`
+	// CoveredSeconds is the time between readings, excluding gaps
+	CoveredSeconds float64 `json:"coveredSeconds"`
+	GapCount       int     `json:"gapCount"`
+	GapSeconds     float64 `json:"gapSeconds"`
+}
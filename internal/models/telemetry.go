@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelemetrySample is one raw telemetry reading of a vehicle, kept for the
+// telemetry retention period
+type TelemetrySample struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID string             `bson:"vehicle_id" json:"vehicleId"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+	Location  *Location          `bson:"location,omitempty" json:"location,omitempty"`
+	Speed     *int               `bson:"speed,omitempty" json:"speed,omitempty"`
+	FuelLevel *float64           `bson:"fuel_level,omitempty" json:"fuelLevel,omitempty"`
+	Odometer  *int               `bson:"odometer,omitempty" json:"odometer,omitempty"`
+	Status    string             `bson:"status,omitempty" json:"status,omitempty"`
+}
+
+// TelemetryBucket summarizes a vehicle's telemetry samples within one
+// downsampling interval. Fields no sample in the interval reported are nil.
+type TelemetryBucket struct {
+	Start   time.Time `bson:"_id" json:"start"`
+	Samples int       `bson:"samples" json:"samples"`
+	// AvgLat and AvgLng are the mean position over the interval
+	AvgLat   *float64 `bson:"avg_lat" json:"avgLat,omitempty"`
+	AvgLng   *float64 `bson:"avg_lng" json:"avgLng,omitempty"`
+	AvgSpeed *float64 `bson:"avg_speed" json:"avgSpeed,omitempty"`
+	MaxSpeed *int     `bson:"max_speed" json:"maxSpeed,omitempty"`
+	// FuelLevel is the last fuel reading in the interval, Odometer the highest
+	FuelLevel *float64 `bson:"fuel_level" json:"fuelLevel,omitempty"`
+	Odometer  *int     `bson:"odometer" json:"odometer,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UnknownVehicle is a vehicle ID telemetry was pushed for before any vehicle
+// had it, held until an operator provisions the vehicle
+type UnknownVehicle struct {
+	VehicleID   string    `bson:"_id" json:"vehicleId"`
+	FirstSeenAt time.Time `bson:"first_seen_at" json:"firstSeenAt"`
+	LastSeenAt  time.Time `bson:"last_seen_at" json:"lastSeenAt"`
+	// Reports counts the telemetry requests that carried the ID
+	Reports      int64     `bson:"reports" json:"reports"`
+	LastLocation *Location `bson:"last_location,omitempty" json:"lastLocation,omitempty"`
+}
@@ -16,6 +16,7 @@ type User struct {
 	Role                string             `bson:"role" json:"role" validate:"required,oneof=admin manager operator viewer"`
 	Status              string             `bson:"status" json:"status" validate:"required,oneof=active inactive suspended"`
 	Permissions         []string           `bson:"permissions" json:"permissions"`
+	TenantID            string             `bson:"tenant_id,omitempty" json:"tenantId,omitempty"` // customer the user belongs to in multi-tenant deployments
 	PasswordResetToken  string             `bson:"password_reset_token,omitempty" json:"-"`
 	PasswordResetExpiry *time.Time         `bson:"password_reset_expiry,omitempty" json:"-"`
 	LastLogin           *time.Time         `bson:"last_login,omitempty" json:"lastLogin,omitempty"`
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatusSegment is a closed period a vehicle was known to be in one status
+type StatusSegment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID string             `bson:"vehicle_id" json:"vehicleId"`
+	Status    string             `bson:"status" json:"status"`
+	StartedAt time.Time          `bson:"started_at" json:"startedAt"`
+	EndedAt   time.Time          `bson:"ended_at" json:"endedAt"`
+}
+
+// UtilizationUnknown is the state of time no status report covers
+const UtilizationUnknown = "unknown"
+
+// UtilizationBreakdown is the time spent in each status within a range, in
+// seconds and as a percentage of the range, keyed by status. Time without
+// status data is counted as UtilizationUnknown rather than as any status.
+type UtilizationBreakdown struct {
+	Seconds map[string]float64 `json:"seconds"`
+	Percent map[string]float64 `json:"percent"`
+}
+
+// VehicleUtilization is one vehicle's utilization within a report's range
+type VehicleUtilization struct {
+	VehicleID   string `json:"vehicleId"`
+	VehicleName string `json:"vehicleName"`
+	UtilizationBreakdown
+}
+
+// UtilizationReport is the utilization of each vehicle within [From, To),
+// with a fleet rollup over the time of all vehicles together
+type UtilizationReport struct {
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	Fleet    UtilizationBreakdown  `json:"fleet"`
+	Vehicles []*VehicleUtilization `json:"vehicles"`
+}
@@ -20,17 +20,64 @@ type Vehicle struct {
 	LastUpdate       time.Time          `bson:"last_update" json:"lastUpdate"`
 	Odometer         int                `bson:"odometer" json:"odometer"`
 	FuelConsumption  float64            `bson:"fuel_consumption" json:"fuelConsumption"`
-	Alerts           []Alert            `bson:"alerts" json:"alerts"`
+	Alerts           []Alert            `bson:"alerts" json:"alerts"` // newest unresolved alerts, capped; the alerts collection has them all
 	Make             string             `bson:"make" json:"make"`
 	Model            string             `bson:"model" json:"model"`
 	Year             int                `bson:"year" json:"year"`
 	VIN              string             `bson:"vin" json:"vin"`
 	CreatedAt        time.Time          `bson:"created_at" json:"createdAt"`
 	UpdatedAt        time.Time          `bson:"updated_at" json:"updatedAt"`
+	DeletedAt        *time.Time         `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+	LastReportedAt   *time.Time         `bson:"last_reported_at,omitempty" json:"lastReportedAt,omitempty"`
+	Connectivity     string             `bson:"connectivity,omitempty" json:"connectivity,omitempty"`
+	SpeedLimitKmh    int                `bson:"speed_limit_kmh,omitempty" json:"speedLimitKmh,omitempty"` // overrides the default speeding threshold
+	AlertRouting     *AlertRouting      `bson:"alert_routing,omitempty" json:"alertRouting,omitempty"`
+	Metadata         map[string]string  `bson:"metadata,omitempty" json:"metadata,omitempty"` // customer-defined fields, e.g. asset tag or cost center
+	Tags             []string           `bson:"tags,omitempty" json:"tags,omitempty"`         // groups the vehicle belongs to, e.g. depot or region
+	FuelUnit         string             `bson:"fuel_unit,omitempty" json:"fuelUnit,omitempty"` // unit fuel is presented in; unset uses the fleet's unit
+}
+
+// AlertRouting overrides which webhook subscriptions receive a vehicle's
+// alerts. The listed subscriptions receive every alert of the vehicle; the
+// fleet-wide subscriptions selecting the alert's type only receive it when
+// IncludeDefault is set.
+type AlertRouting struct {
+	WebhookIDs     []string `bson:"webhook_ids" json:"webhookIds"`
+	IncludeDefault bool     `bson:"include_default" json:"includeDefault"`
+}
+
+// Targets reports whether the routing sends alerts to a subscription
+func (r *AlertRouting) Targets(webhookID string) bool {
+	for _, id := range r.WebhookIDs {
+		if id == webhookID {
+			return true
+		}
+	}
+	return false
+}
+
+// Connectivity states of a vehicle's telemetry link
+const (
+	ConnectivityPending       = "pending"        // created recently, no telemetry yet
+	ConnectivityOnline        = "online"         // reported recently
+	ConnectivityOffline       = "offline"        // reported before, but not recently
+	ConnectivityNeverReported = "never_reported" // no telemetry since creation
+)
+
+// IsArchived reports whether the vehicle has been soft-deleted
+func (v *Vehicle) IsArchived() bool {
+	return v.DeletedAt != nil
 }
 
 type Location struct {
 	Lat     float64 `bson:"lat" json:"lat"`
 	Lng     float64 `bson:"lng" json:"lng"`
 	Address string  `bson:"address" json:"address"`
+}
+
+// DuplicatePlate is a plate number shared by more than one vehicle, which
+// keeps the unique plate number index from being created
+type DuplicatePlate struct {
+	PlateNumber string     `json:"plateNumber"`
+	Vehicles    []*Vehicle `json:"vehicles"`
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook event types. Alert events are "alert." followed by the alert type.
+const (
+	EventAlertPrefix             = "alert."
+	EventVehicleStatusChanged    = "vehicle.status_changed"
+	EventMaintenanceCreated      = "maintenance.record_created"
+	EventMaintenanceCompleted    = "maintenance.record_completed"
+	EventTelemetryUnknownVehicle = "telemetry.unknown_vehicle"
+	EventWebhookTest             = "webhook.test"
+	eventCategoryWildcardSuffix  = ".*"
+)
+
+// WebhookSubscription delivers events of the selected types to a URL. Event
+// types may name a whole category with a wildcard, e.g. "alert.*".
+// Events for the same vehicle are delivered in order; Concurrency sets how many
+// vehicles' events may be in flight at once and QueueSize bounds the backlog.
+type WebhookSubscription struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	URL         string             `bson:"url" json:"url"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	EventTypes  []string           `bson:"event_types" json:"eventTypes"`
+	Secret      string             `bson:"secret" json:"secret,omitempty"` // only returned when the subscription is created
+	Active      bool               `bson:"active" json:"active"`
+	Concurrency int                `bson:"concurrency,omitempty" json:"concurrency,omitempty"` // delivery workers; 0 uses the default
+	QueueSize   int                `bson:"queue_size,omitempty" json:"queueSize,omitempty"`    // queued events; 0 uses the default
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// Matches reports whether the subscription is active and selects the event type
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	for _, selected := range s.EventTypes {
+		if selected == eventType {
+			return true
+		}
+		if category, ok := strings.CutSuffix(selected, eventCategoryWildcardSuffix); ok && strings.HasPrefix(eventType, category+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the payload delivered to webhook subscriptions
+type WebhookEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	VehicleID string                 `json:"vehicleId,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// WebhookDeliveryResult reports the outcome of delivering an event to a subscription
+type WebhookDeliveryResult struct {
+	SubscriptionID primitive.ObjectID `json:"subscriptionId"`
+	EventID        string             `json:"eventId"`
+	StatusCode     int                `json:"statusCode,omitempty"`
+	Success        bool               `json:"success"`
+	Error          string             `json:"error,omitempty"`
+	DurationMs     int64              `json:"durationMs"`
+}
+
+// WebhookDeliveryStats describes a subscription's delivery queue and outcomes
+type WebhookDeliveryStats struct {
+	SubscriptionID   string     `json:"subscriptionId"`
+	Workers          int        `json:"workers"`
+	QueueCapacity    int        `json:"queueCapacity"`
+	Queued           int        `json:"queued"`
+	InFlight         int        `json:"inFlight"`
+	Delivered        int64      `json:"delivered"`
+	Failed           int64      `json:"failed"`
+	Shed             int64      `json:"shed"`             // events dropped because the queue was full
+	AverageLatencyMs float64    `json:"averageLatencyMs"` // across delivered and failed attempts
+	LastDeliveryAt   *time.Time `json:"lastDeliveryAt,omitempty"`
+	LastError        string     `json:"lastError,omitempty"`
+}
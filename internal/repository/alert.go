@@ -14,16 +14,24 @@ import (
 
 type AlertRepository struct {
 	collection *mongo.Collection
+	timeouts   Timeouts
 }
 
 func NewAlertRepository(db *mongo.Database) *AlertRepository {
 	return &AlertRepository{
 		collection: db.Collection("alerts"),
+		timeouts:   DefaultTimeouts(),
 	}
 }
 
-func (r *AlertRepository) Create(alert *models.Alert) (*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *AlertRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
+func (r *AlertRepository) Create(ctx context.Context, alert *models.Alert) (*models.Alert, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	result, err := r.collection.InsertOne(ctx, alert)
@@ -35,8 +43,8 @@ func (r *AlertRepository) Create(alert *models.Alert) (*models.Alert, error) {
 	return alert, nil
 }
 
-func (r *AlertRepository) FindByID(id string) (*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindByID(ctx context.Context, id string) (*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -56,13 +64,11 @@ func (r *AlertRepository) FindByID(id string) (*models.Alert, error) {
 	return &alert, nil
 }
 
-func (r *AlertRepository) FindAll() ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindAll(ctx context.Context) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
-	// Sort by timestamp descending to get most recent alerts first
-	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.findAllCursor(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +86,141 @@ func (r *AlertRepository) FindAll() ([]*models.Alert, error) {
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindByVehicleID(vehicleID string) ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// AlertFilter narrows alert queries. Empty fields match every alert.
+type AlertFilter struct {
+	IDs          []primitive.ObjectID
+	VehicleID    string
+	Severity     string
+	Type         string
+	Resolved     *bool
+	Acknowledged *bool
+	From         *time.Time
+	To           *time.Time
+}
+
+// BuildAlertFilter converts an AlertFilter into a MongoDB query
+func BuildAlertFilter(f AlertFilter) bson.M {
+	filter := bson.M{}
+	if len(f.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": f.IDs}
+	}
+	if f.VehicleID != "" {
+		filter["vehicle_id"] = f.VehicleID
+	}
+	if f.Severity != "" {
+		filter["severity"] = f.Severity
+	}
+	if f.Type != "" {
+		filter["type"] = f.Type
+	}
+	if f.Resolved != nil {
+		filter["resolved"] = *f.Resolved
+	}
+	if f.Acknowledged != nil {
+		if *f.Acknowledged {
+			filter["acknowledged"] = true
+		} else {
+			// Alerts raised before acknowledgement existed have no field
+			filter["acknowledged"] = bson.M{"$ne": true}
+		}
+	}
+	if f.From != nil || f.To != nil {
+		timestamp := bson.M{}
+		if f.From != nil {
+			timestamp["$gte"] = *f.From
+		}
+		if f.To != nil {
+			timestamp["$lte"] = *f.To
+		}
+		filter["timestamp"] = timestamp
+	}
+	return filter
+}
+
+// FindWithFilters returns one page of the alerts matching filter, most recent
+// first, along with the total number of matching alerts. A limit of 0 returns
+// every alert from offset on.
+func (r *AlertRepository) FindWithFilters(ctx context.Context, filter AlertFilter, limit, offset int) ([]*models.Alert, int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	query := BuildAlertFilter(filter)
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	alerts := []*models.Alert{}
+	for cursor.Next(ctx) {
+		var alert models.Alert
+		if err := cursor.Decode(&alert); err != nil {
+			return nil, 0, err
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	return alerts, total, cursor.Err()
+}
+
+// StreamAll decodes alerts one at a time in FindAll order and passes each to fn,
+// so exports never hold the whole collection in memory. Iteration stops at the
+// first error returned by fn.
+func (r *AlertRepository) StreamAll(ctx context.Context, fn func(*models.Alert) error) error {
+	return r.StreamFiltered(ctx, AlertFilter{}, fn)
+}
+
+// StreamFiltered is StreamAll restricted to the alerts matching filter
+func (r *AlertRepository) StreamFiltered(ctx context.Context, filter AlertFilter, fn func(*models.Alert) error) error {
+	ctx, cancel := r.timeouts.stream(ctx)
+	defer cancel()
+
+	cursor, err := r.findCursor(ctx, BuildAlertFilter(filter))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var alert models.Alert
+		if err := cursor.Decode(&alert); err != nil {
+			return err
+		}
+		if err := fn(&alert); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// findAllCursor opens a cursor over every alert
+func (r *AlertRepository) findAllCursor(ctx context.Context) (*mongo.Cursor, error) {
+	return r.findCursor(ctx, bson.M{})
+}
+
+// findCursor opens a cursor over the alerts matching filter
+func (r *AlertRepository) findCursor(ctx context.Context, filter bson.M) (*mongo.Cursor, error) {
+	// Sort by timestamp descending to get most recent alerts first
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	return r.collection.Find(ctx, filter, opts)
+}
+
+func (r *AlertRepository) FindByVehicleID(ctx context.Context, vehicleID string) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
@@ -103,8 +242,8 @@ func (r *AlertRepository) FindByVehicleID(vehicleID string) ([]*models.Alert, er
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindByType(alertType string) ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindByType(ctx context.Context, alertType string) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
@@ -126,8 +265,8 @@ func (r *AlertRepository) FindByType(alertType string) ([]*models.Alert, error)
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindBySeverity(severity string) ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindBySeverity(ctx context.Context, severity string) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
@@ -149,8 +288,8 @@ func (r *AlertRepository) FindBySeverity(severity string) ([]*models.Alert, erro
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindUnresolved() ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindUnresolved(ctx context.Context) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
@@ -172,8 +311,8 @@ func (r *AlertRepository) FindUnresolved() ([]*models.Alert, error) {
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindResolved() ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindResolved(ctx context.Context) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "resolved_at", Value: -1}})
@@ -195,8 +334,8 @@ func (r *AlertRepository) FindResolved() ([]*models.Alert, error) {
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindByDateRange(startDate, endDate time.Time) ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	filter := bson.M{
@@ -225,8 +364,8 @@ func (r *AlertRepository) FindByDateRange(startDate, endDate time.Time) ([]*mode
 	return alerts, nil
 }
 
-func (r *AlertRepository) FindCriticalAlerts() ([]*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) FindCriticalAlerts(ctx context.Context) ([]*models.Alert, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	filter := bson.M{
@@ -253,8 +392,8 @@ func (r *AlertRepository) FindCriticalAlerts() ([]*models.Alert, error) {
 	return alerts, nil
 }
 
-func (r *AlertRepository) Update(id string, alert *models.Alert) (*models.Alert, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) Update(ctx context.Context, id string, alert *models.Alert) (*models.Alert, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -284,8 +423,8 @@ func (r *AlertRepository) Update(id string, alert *models.Alert) (*models.Alert,
 	return &updatedAlert, nil
 }
 
-func (r *AlertRepository) MarkAsResolved(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) MarkAsResolved(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -313,8 +452,58 @@ func (r *AlertRepository) MarkAsResolved(id string) error {
 	return nil
 }
 
-func (r *AlertRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// BuildAcknowledgeUpdate returns the update that marks an alert as claimed by
+// userID. It leaves the resolved state untouched.
+func BuildAcknowledgeUpdate(userID, note string, at time.Time) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"acknowledged":    true,
+			"acknowledged_by": userID,
+			"acknowledged_at": at,
+			"ack_note":        note,
+		},
+	}
+}
+
+// Acknowledge records that userID has claimed the alert and returns the updated alert
+func (r *AlertRepository) Acknowledge(ctx context.Context, id, userID, note string) (*models.Alert, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid alert ID")
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var alert models.Alert
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, BuildAcknowledgeUpdate(userID, note, time.Now()), opts).Decode(&alert)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+// AcknowledgeMany records that userID has claimed the unresolved alerts among
+// ids and returns how many were acknowledged
+func (r *AlertRepository) AcknowledgeMany(ctx context.Context, ids []primitive.ObjectID, userID, note string, at time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}, "resolved": false}
+	result, err := r.collection.UpdateMany(ctx, filter, BuildAcknowledgeUpdate(userID, note, at))
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -334,8 +523,8 @@ func (r *AlertRepository) Delete(id string) error {
 	return nil
 }
 
-func (r *AlertRepository) DeleteResolvedBefore(cutoffDate time.Time) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) DeleteResolvedBefore(ctx context.Context, cutoffDate time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	filter := bson.M{
@@ -349,48 +538,48 @@ func (r *AlertRepository) DeleteResolvedBefore(cutoffDate time.Time) error {
 	return err
 }
 
-func (r *AlertRepository) Count() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{})
 	return count, err
 }
 
-func (r *AlertRepository) CountUnresolved() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) CountUnresolved(ctx context.Context) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{"resolved": false})
 	return count, err
 }
 
-func (r *AlertRepository) CountByType(alertType string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) CountByType(ctx context.Context, alertType string) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{"type": alertType})
 	return count, err
 }
 
-func (r *AlertRepository) CountBySeverity(severity string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) CountBySeverity(ctx context.Context, severity string) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{"severity": severity})
 	return count, err
 }
 
-func (r *AlertRepository) CountByVehicle(vehicleID string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) CountByVehicle(ctx context.Context, vehicleID string) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{"vehicle_id": vehicleID})
 	return count, err
 }
 
-func (r *AlertRepository) GetAlertStatistics() (map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) GetAlertStatistics(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := r.timeouts.aggregate(ctx)
 	defer cancel()
 
 	pipeline := []bson.M{
@@ -446,8 +635,8 @@ func (r *AlertRepository) GetAlertStatistics() (map[string]interface{}, error) {
 }
 
 // CreateIndexes creates necessary indexes for the alerts collection
-func (r *AlertRepository) CreateIndexes() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlertRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	indexes := []mongo.IndexModel{
@@ -485,4 +674,4 @@ func (r *AlertRepository) CreateIndexes() error {
 
 	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
-}
\ No newline at end of file
+}
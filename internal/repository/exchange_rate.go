@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrExchangeRateNotFound is returned when no rate for a currency pair was in
+// effect at the requested time
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+type ExchangeRateRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewExchangeRateRepository(db *mongo.Database) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		collection: db.Collection("exchange_rates"),
+		timeouts:   DefaultTimeouts(),
+	}
+}
+
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *ExchangeRateRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
+// Create stores a rate. Rates are never overwritten; a newer EffectiveAt
+// supersedes older rates for the same pair.
+func (r *ExchangeRateRepository) Create(ctx context.Context, rate *models.ExchangeRate) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	rate.ID = primitive.NewObjectID()
+	rate.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, rate)
+	return err
+}
+
+// FindEffective returns the rate from base to quote in effect at at: the one
+// that took effect most recently at or before it
+func (r *ExchangeRateRepository) FindEffective(ctx context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"base_currency":  base,
+		"quote_currency": quote,
+		"effective_at":   bson.M{"$lte": at},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "effective_at", Value: -1}, {Key: "created_at", Value: -1}})
+
+	var rate models.ExchangeRate
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&rate); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// CreateIndexes creates the index used to look up the rate in effect
+func (r *ExchangeRateRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "base_currency", Value: 1},
+			{Key: "quote_currency", Value: 1},
+			{Key: "effective_at", Value: -1},
+		},
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GeofenceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGeofenceRepository(db *mongo.Database) *GeofenceRepository {
+	return &GeofenceRepository{
+		collection: db.Collection("geofences"),
+	}
+}
+
+func (r *GeofenceRepository) Create(geofence *models.Geofence) (*models.Geofence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, geofence)
+	if err != nil {
+		return nil, err
+	}
+
+	geofence.ID = result.InsertedID.(primitive.ObjectID)
+	return geofence, nil
+}
+
+func (r *GeofenceRepository) FindByID(id string) (*models.Geofence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid geofence ID")
+	}
+
+	var geofence models.Geofence
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&geofence)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("geofence not found")
+		}
+		return nil, err
+	}
+
+	return &geofence, nil
+}
+
+func (r *GeofenceRepository) FindAll() ([]*models.Geofence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var geofences []*models.Geofence
+	for cursor.Next(ctx) {
+		var geofence models.Geofence
+		if err := cursor.Decode(&geofence); err != nil {
+			return nil, err
+		}
+		geofences = append(geofences, &geofence)
+	}
+
+	return geofences, nil
+}
+
+func (r *GeofenceRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid geofence ID")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("geofence not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IdleSegmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewIdleSegmentRepository(db *mongo.Database) *IdleSegmentRepository {
+	return &IdleSegmentRepository{
+		collection: db.Collection("idle_segments"),
+	}
+}
+
+func (r *IdleSegmentRepository) Create(segment *models.IdleSegment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, segment)
+	if err != nil {
+		return err
+	}
+
+	segment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindOverlapping returns a vehicle's idle segments that overlap [from, to), oldest first
+func (r *IdleSegmentRepository) FindOverlapping(vehicleID string, from, to time.Time) ([]*models.IdleSegment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"vehicle_id": vehicleID,
+		"started_at": bson.M{"$lt": to},
+		"ended_at":   bson.M{"$gt": from},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var segments []*models.IdleSegment
+	for cursor.Next(ctx) {
+		var segment models.IdleSegment
+		if err := cursor.Decode(&segment); err != nil {
+			return nil, err
+		}
+		segments = append(segments, &segment)
+	}
+
+	return segments, cursor.Err()
+}
+
+// CreateIndexes creates the index used by range queries
+func (r *IdleSegmentRepository) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "vehicle_id", Value: 1}, {Key: "started_at", Value: 1}},
+	})
+	return err
+}
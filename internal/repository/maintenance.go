@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fleet-backend/internal/models"
 	"time"
 
@@ -11,10 +12,18 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	// ErrMaintenanceRecordNotFound is returned when no maintenance record has the given ID
+	ErrMaintenanceRecordNotFound = errors.New("maintenance record not found")
+	// ErrInvalidMaintenanceRecordID is returned when a record ID isn't a valid object ID
+	ErrInvalidMaintenanceRecordID = errors.New("invalid maintenance record ID")
+)
+
 type MaintenanceRepository struct {
 	collection         *mongo.Collection
 	scheduleCollection *mongo.Collection
 	reminderCollection *mongo.Collection
+	timeouts           Timeouts
 }
 
 func NewMaintenanceRepository(db *mongo.Database) *MaintenanceRepository {
@@ -22,48 +31,67 @@ func NewMaintenanceRepository(db *mongo.Database) *MaintenanceRepository {
 		collection:         db.Collection("maintenance_records"),
 		scheduleCollection: db.Collection("maintenance_schedules"),
 		reminderCollection: db.Collection("service_reminders"),
+		timeouts:           DefaultTimeouts(),
 	}
 }
 
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *MaintenanceRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
 // Maintenance Records
-func (r *MaintenanceRepository) Create(record *models.MaintenanceRecord) error {
+func (r *MaintenanceRepository) Create(ctx context.Context, record *models.MaintenanceRecord) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	record.ID = primitive.NewObjectID()
 	record.CreatedAt = time.Now()
 	record.UpdatedAt = time.Now()
 
-	_, err := r.collection.InsertOne(context.Background(), record)
+	_, err := r.collection.InsertOne(ctx, record)
 	return err
 }
 
-func (r *MaintenanceRepository) FindByID(id string) (*models.MaintenanceRecord, error) {
+func (r *MaintenanceRepository) FindByID(ctx context.Context, id string) (*models.MaintenanceRecord, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidMaintenanceRecordID
 	}
 
 	var record models.MaintenanceRecord
-	err = r.collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&record)
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&record)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrMaintenanceRecordNotFound
+		}
 		return nil, err
 	}
 
 	return &record, nil
 }
 
-func (r *MaintenanceRepository) FindByVehicleID(vehicleID string) ([]*models.MaintenanceRecord, error) {
+func (r *MaintenanceRepository) FindByVehicleID(ctx context.Context, vehicleID string) ([]*models.MaintenanceRecord, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(vehicleID)
 	if err != nil {
 		return nil, err
 	}
 
-	cursor, err := r.collection.Find(context.Background(), bson.M{"vehicle_id": objectID}, options.Find().SetSort(bson.D{{Key: "performed_at", Value: -1}}))
+	cursor, err := r.collection.Find(ctx, bson.M{"vehicle_id": objectID}, options.Find().SetSort(bson.D{{Key: "performed_at", Value: -1}}))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var records []*models.MaintenanceRecord
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var record models.MaintenanceRecord
 		if err := cursor.Decode(&record); err != nil {
 			return nil, err
@@ -74,7 +102,82 @@ func (r *MaintenanceRepository) FindByVehicleID(vehicleID string) ([]*models.Mai
 	return records, nil
 }
 
-func (r *MaintenanceRepository) FindAll(limit, offset int) ([]*models.MaintenanceRecord, error) {
+// FindByVehicleIDs returns the maintenance history of each of the given
+// vehicles in a single query, keyed by vehicle ID hex with each history most
+// recent first. Vehicles without any records are absent from the map.
+func (r *MaintenanceRepository) FindByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) (map[string][]*models.MaintenanceRecord, error) {
+	histories := make(map[string][]*models.MaintenanceRecord, len(vehicleIDs))
+	if len(vehicleIDs) == 0 {
+		return histories, nil
+	}
+
+	ctx, cancel := r.timeouts.aggregate(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "performed_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"vehicle_id": bson.M{"$in": vehicleIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record models.MaintenanceRecord
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		vehicleID := record.VehicleID.Hex()
+		histories[vehicleID] = append(histories[vehicleID], &record)
+	}
+
+	return histories, cursor.Err()
+}
+
+// FindLatestByVehicleIDs returns the most recent maintenance record for each of
+// the given vehicles in a single aggregation, keyed by vehicle ID hex. Vehicles
+// without any records are absent from the map.
+func (r *MaintenanceRepository) FindLatestByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) (map[string]*models.MaintenanceRecord, error) {
+	latest := make(map[string]*models.MaintenanceRecord, len(vehicleIDs))
+	if len(vehicleIDs) == 0 {
+		return latest, nil
+	}
+
+	ctx, cancel := r.timeouts.aggregate(ctx)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"vehicle_id": bson.M{"$in": vehicleIDs}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "performed_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$vehicle_id",
+			"record": bson.M{"$first": "$$ROOT"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var result struct {
+			Record models.MaintenanceRecord `bson:"record"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		record := result.Record
+		latest[record.VehicleID.Hex()] = &record
+	}
+
+	return latest, cursor.Err()
+}
+
+func (r *MaintenanceRepository) FindAll(ctx context.Context, limit, offset int) ([]*models.MaintenanceRecord, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	opts := options.Find().SetSort(bson.D{{Key: "performed_at", Value: -1}})
 	if limit > 0 {
 		opts.SetLimit(int64(limit))
@@ -83,14 +186,14 @@ func (r *MaintenanceRepository) FindAll(limit, offset int) ([]*models.Maintenanc
 		opts.SetSkip(int64(offset))
 	}
 
-	cursor, err := r.collection.Find(context.Background(), bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var records []*models.MaintenanceRecord
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var record models.MaintenanceRecord
 		if err := cursor.Decode(&record); err != nil {
 			return nil, err
@@ -101,47 +204,146 @@ func (r *MaintenanceRepository) FindAll(limit, offset int) ([]*models.Maintenanc
 	return records, nil
 }
 
-func (r *MaintenanceRepository) Update(id string, record *models.MaintenanceRecord) error {
+// FindPageByVehicleID returns up to limit of the vehicle's maintenance records,
+// most recent first, skipping the first offset
+func (r *MaintenanceRepository) FindPageByVehicleID(ctx context.Context, vehicleID string, limit, offset int) ([]*models.MaintenanceRecord, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "performed_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"vehicle_id": objectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []*models.MaintenanceRecord
+	for cursor.Next(ctx) {
+		var record models.MaintenanceRecord
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, cursor.Err()
+}
+
+// CountAll returns the number of maintenance records
+func (r *MaintenanceRepository) CountAll(ctx context.Context) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, bson.M{})
+}
+
+// CountByVehicle returns the number of maintenance records of the vehicle
+func (r *MaintenanceRepository) CountByVehicle(ctx context.Context, vehicleID string) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.collection.CountDocuments(ctx, bson.M{"vehicle_id": objectID})
+}
+
+func (r *MaintenanceRepository) Update(ctx context.Context, id string, record *models.MaintenanceRecord) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
 	record.UpdatedAt = time.Now()
-	update := bson.M{"$set": record}
 
-	_, err = r.collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	// Attachments are only added through AddAttachment, so an upload racing
+	// with this update isn't overwritten by a stale copy of the list
+	fields := *record
+	fields.Attachments = nil
+	update := bson.M{"$set": fields}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
 
-func (r *MaintenanceRepository) Delete(id string) error {
+// AddAttachment appends attachment metadata to a maintenance record
+func (r *MaintenanceRepository) AddAttachment(ctx context.Context, recordID string, attachment *models.Attachment) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$push": bson.M{"attachments": attachment},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MaintenanceRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
 	return err
 }
 
 // Maintenance Schedules
-func (r *MaintenanceRepository) CreateSchedule(schedule *models.MaintenanceSchedule) error {
+func (r *MaintenanceRepository) CreateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	schedule.ID = primitive.NewObjectID()
 	schedule.CreatedAt = time.Now()
 	schedule.UpdatedAt = time.Now()
 
-	_, err := r.scheduleCollection.InsertOne(context.Background(), schedule)
+	_, err := r.scheduleCollection.InsertOne(ctx, schedule)
 	return err
 }
 
-func (r *MaintenanceRepository) FindScheduleByID(id string) (*models.MaintenanceSchedule, error) {
+func (r *MaintenanceRepository) FindScheduleByID(ctx context.Context, id string) (*models.MaintenanceSchedule, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
 	var schedule models.MaintenanceSchedule
-	err = r.scheduleCollection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&schedule)
+	err = r.scheduleCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -149,20 +351,23 @@ func (r *MaintenanceRepository) FindScheduleByID(id string) (*models.Maintenance
 	return &schedule, nil
 }
 
-func (r *MaintenanceRepository) FindSchedulesByVehicleID(vehicleID string) ([]*models.MaintenanceSchedule, error) {
+func (r *MaintenanceRepository) FindSchedulesByVehicleID(ctx context.Context, vehicleID string) ([]*models.MaintenanceSchedule, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(vehicleID)
 	if err != nil {
 		return nil, err
 	}
 
-	cursor, err := r.scheduleCollection.Find(context.Background(), bson.M{"vehicle_id": objectID}, options.Find().SetSort(bson.D{{Key: "next_service_date", Value: 1}}))
+	cursor, err := r.scheduleCollection.Find(ctx, bson.M{"vehicle_id": objectID}, options.Find().SetSort(bson.D{{Key: "next_service_date", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var schedules []*models.MaintenanceSchedule
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var schedule models.MaintenanceSchedule
 		if err := cursor.Decode(&schedule); err != nil {
 			return nil, err
@@ -173,7 +378,10 @@ func (r *MaintenanceRepository) FindSchedulesByVehicleID(vehicleID string) ([]*m
 	return schedules, nil
 }
 
-func (r *MaintenanceRepository) FindUpcomingSchedules(days int) ([]*models.MaintenanceSchedule, error) {
+func (r *MaintenanceRepository) FindUpcomingSchedules(ctx context.Context, days int) ([]*models.MaintenanceSchedule, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	now := time.Now()
 	
 	var filter bson.M
@@ -216,14 +424,14 @@ func (r *MaintenanceRepository) FindUpcomingSchedules(days int) ([]*models.Maint
 		}
 	}
 
-	cursor, err := r.scheduleCollection.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "next_service_date", Value: 1}}))
+	cursor, err := r.scheduleCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "next_service_date", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var schedules []*models.MaintenanceSchedule
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var schedule models.MaintenanceSchedule
 		if err := cursor.Decode(&schedule); err != nil {
 			return nil, err
@@ -234,15 +442,18 @@ func (r *MaintenanceRepository) FindUpcomingSchedules(days int) ([]*models.Maint
 	return schedules, nil
 }
 
-func (r *MaintenanceRepository) FindAllSchedules() ([]*models.MaintenanceSchedule, error) {
-	cursor, err := r.scheduleCollection.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+func (r *MaintenanceRepository) FindAllSchedules(ctx context.Context) ([]*models.MaintenanceSchedule, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.scheduleCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var schedules []*models.MaintenanceSchedule
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var schedule models.MaintenanceSchedule
 		if err := cursor.Decode(&schedule); err != nil {
 			return nil, err
@@ -253,7 +464,67 @@ func (r *MaintenanceRepository) FindAllSchedules() ([]*models.MaintenanceSchedul
 	return schedules, nil
 }
 
-func (r *MaintenanceRepository) UpdateSchedule(id string, schedule *models.MaintenanceSchedule) error {
+// FindActiveSchedules returns every active schedule, oldest first
+func (r *MaintenanceRepository) FindActiveSchedules(ctx context.Context) ([]*models.MaintenanceSchedule, error) {
+	ctx, cancel := r.timeouts.aggregate(ctx)
+	defer cancel()
+
+	cursor, err := r.scheduleCollection.Find(ctx, bson.M{"is_active": true}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schedules []*models.MaintenanceSchedule
+	for cursor.Next(ctx) {
+		var schedule models.MaintenanceSchedule
+		if err := cursor.Decode(&schedule); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	return schedules, cursor.Err()
+}
+
+// FindCompletedByVehicleIDs returns the completed maintenance records of the
+// given vehicles in a single query
+func (r *MaintenanceRepository) FindCompletedByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) ([]*models.MaintenanceRecord, error) {
+	if len(vehicleIDs) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.timeouts.aggregate(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"vehicle_id": bson.M{"$in": vehicleIDs},
+		"status":     models.MaintenanceStatusCompleted,
+	}
+	projection := options.Find().SetProjection(bson.M{"vehicle_id": 1, "types": 1, "performed_at": 1, "status": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []*models.MaintenanceRecord
+	for cursor.Next(ctx) {
+		var record models.MaintenanceRecord
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, cursor.Err()
+}
+
+func (r *MaintenanceRepository) UpdateSchedule(ctx context.Context, id string, schedule *models.MaintenanceSchedule) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
@@ -262,44 +533,53 @@ func (r *MaintenanceRepository) UpdateSchedule(id string, schedule *models.Maint
 	schedule.UpdatedAt = time.Now()
 	update := bson.M{"$set": schedule}
 
-	_, err = r.scheduleCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	_, err = r.scheduleCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
 
-func (r *MaintenanceRepository) DeleteSchedule(id string) error {
+func (r *MaintenanceRepository) DeleteSchedule(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.scheduleCollection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	_, err = r.scheduleCollection.DeleteOne(ctx, bson.M{"_id": objectID})
 	return err
 }
 
 // Service Reminders
-func (r *MaintenanceRepository) CreateReminder(reminder *models.ServiceReminder) error {
+func (r *MaintenanceRepository) CreateReminder(ctx context.Context, reminder *models.ServiceReminder) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	reminder.ID = primitive.NewObjectID()
 	reminder.CreatedAt = time.Now()
 	reminder.UpdatedAt = time.Now()
 
-	_, err := r.reminderCollection.InsertOne(context.Background(), reminder)
+	_, err := r.reminderCollection.InsertOne(ctx, reminder)
 	return err
 }
 
-func (r *MaintenanceRepository) FindRemindersByVehicleID(vehicleID string) ([]*models.ServiceReminder, error) {
+func (r *MaintenanceRepository) FindRemindersByVehicleID(ctx context.Context, vehicleID string) ([]*models.ServiceReminder, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(vehicleID)
 	if err != nil {
 		return nil, err
 	}
 
-	cursor, err := r.reminderCollection.Find(context.Background(), bson.M{"vehicle_id": objectID})
+	cursor, err := r.reminderCollection.Find(ctx, bson.M{"vehicle_id": objectID})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var reminders []*models.ServiceReminder
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var reminder models.ServiceReminder
 		if err := cursor.Decode(&reminder); err != nil {
 			return nil, err
@@ -310,17 +590,20 @@ func (r *MaintenanceRepository) FindRemindersByVehicleID(vehicleID string) ([]*m
 	return reminders, nil
 }
 
-func (r *MaintenanceRepository) FindOverdueReminders() ([]*models.ServiceReminder, error) {
+func (r *MaintenanceRepository) FindOverdueReminders(ctx context.Context) ([]*models.ServiceReminder, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
 	filter := bson.M{"is_overdue": true}
 
-	cursor, err := r.reminderCollection.Find(context.Background(), filter)
+	cursor, err := r.reminderCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var reminders []*models.ServiceReminder
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var reminder models.ServiceReminder
 		if err := cursor.Decode(&reminder); err != nil {
 			return nil, err
@@ -331,7 +614,41 @@ func (r *MaintenanceRepository) FindOverdueReminders() ([]*models.ServiceReminde
 	return reminders, nil
 }
 
-func (r *MaintenanceRepository) UpdateReminder(id string, reminder *models.ServiceReminder) error {
+// FindOverdueRemindersPage returns up to limit overdue reminders with IDs after
+// afterID, in ID order, so a scan can page through them with bounded queries.
+// A zero afterID starts from the first reminder.
+func (r *MaintenanceRepository) FindOverdueRemindersPage(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.ServiceReminder, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	filter := bson.M{"is_overdue": true}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.reminderCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reminders []*models.ServiceReminder
+	for cursor.Next(ctx) {
+		var reminder models.ServiceReminder
+		if err := cursor.Decode(&reminder); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, &reminder)
+	}
+
+	return reminders, cursor.Err()
+}
+
+func (r *MaintenanceRepository) UpdateReminder(ctx context.Context, id string, reminder *models.ServiceReminder) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
@@ -340,16 +657,35 @@ func (r *MaintenanceRepository) UpdateReminder(id string, reminder *models.Servi
 	reminder.UpdatedAt = time.Now()
 	update := bson.M{"$set": reminder}
 
-	_, err = r.reminderCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	_, err = r.reminderCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+// MarkRemindersNotified records that the reminders were sent in a digest at the given time
+func (r *MaintenanceRepository) MarkRemindersNotified(ctx context.Context, ids []primitive.ObjectID, at time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	update := bson.M{"$set": bson.M{"last_notified_at": at}}
+
+	_, err := r.reminderCollection.UpdateMany(ctx, filter, update)
 	return err
 }
 
-func (r *MaintenanceRepository) DeleteReminder(id string) error {
+func (r *MaintenanceRepository) DeleteReminder(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.reminderCollection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	_, err = r.reminderCollection.DeleteOne(ctx, bson.M{"_id": objectID})
 	return err
 }
\ No newline at end of file
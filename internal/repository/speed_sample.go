@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// speedSampleRetention is how long speed readings are kept
+const speedSampleRetention = 90 * 24 * time.Hour
+
+type SpeedSampleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSpeedSampleRepository(db *mongo.Database) *SpeedSampleRepository {
+	return &SpeedSampleRepository{
+		collection: db.Collection("speed_samples"),
+	}
+}
+
+// InsertMany stores a batch of speed readings
+func (r *SpeedSampleRepository) InsertMany(samples []*models.SpeedSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	documents := make([]interface{}, len(samples))
+	for i, sample := range samples {
+		documents[i] = sample
+	}
+
+	// Unordered so one bad document doesn't drop the rest of the batch
+	_, err := r.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// FindInRange returns a vehicle's speed readings within [from, to], oldest first
+func (r *SpeedSampleRepository) FindInRange(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"vehicle_id": vehicleID,
+		"timestamp":  bson.M{"$gte": from, "$lte": to},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var samples []*models.SpeedSample
+	for cursor.Next(ctx) {
+		var sample models.SpeedSample
+		if err := cursor.Decode(&sample); err != nil {
+			return nil, err
+		}
+		samples = append(samples, &sample)
+	}
+
+	return samples, cursor.Err()
+}
+
+// CreateIndexes creates the index used by range queries and the retention index
+func (r *SpeedSampleRepository) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "vehicle_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "timestamp", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(speedSampleRetention.Seconds())),
+		},
+	})
+	return err
+}
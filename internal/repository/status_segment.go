@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StatusSegmentRepository stores the periods vehicles spent in each status
+type StatusSegmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStatusSegmentRepository(db *mongo.Database) *StatusSegmentRepository {
+	return &StatusSegmentRepository{
+		collection: db.Collection("status_segments"),
+	}
+}
+
+func (r *StatusSegmentRepository) Create(segment *models.StatusSegment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, segment)
+	if err != nil {
+		return err
+	}
+
+	segment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindOverlapping returns every vehicle's status segments that overlap
+// [from, to), oldest first
+func (r *StatusSegmentRepository) FindOverlapping(from, to time.Time) ([]*models.StatusSegment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"started_at": bson.M{"$lt": to},
+		"ended_at":   bson.M{"$gt": from},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var segments []*models.StatusSegment
+	for cursor.Next(ctx) {
+		var segment models.StatusSegment
+		if err := cursor.Decode(&segment); err != nil {
+			return nil, err
+		}
+		segments = append(segments, &segment)
+	}
+
+	return segments, cursor.Err()
+}
+
+// CreateIndexes creates the index used by range queries. Segments ending
+// after the range start are the selective side of the overlap test.
+func (r *StatusSegmentRepository) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "ended_at", Value: 1}, {Key: "started_at", Value: 1}},
+	})
+	return err
+}
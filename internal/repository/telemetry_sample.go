@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultTelemetrySampleRetention is how long raw telemetry samples are kept
+	DefaultTelemetrySampleRetention = 30 * 24 * time.Hour
+	// telemetrySampleTTLIndex names the retention index, so its expiry can be changed
+	telemetrySampleTTLIndex = "telemetry_sample_ttl"
+	// indexOptionsConflictCode is the server error for an existing index with other options
+	indexOptionsConflictCode = 85
+)
+
+// ErrInvalidDownsampleInterval is returned for downsampling intervals under a second
+var ErrInvalidDownsampleInterval = errors.New("downsample interval must be at least one second")
+
+// TelemetrySampleRepository stores raw telemetry samples as a time series per
+// vehicle. Samples expire after the retention period.
+type TelemetrySampleRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+	retention  time.Duration
+}
+
+// NewTelemetrySampleRepository creates a repository keeping samples for
+// retention, or DefaultTelemetrySampleRetention if it isn't positive
+func NewTelemetrySampleRepository(db *mongo.Database, retention time.Duration) *TelemetrySampleRepository {
+	if retention <= 0 {
+		retention = DefaultTelemetrySampleRetention
+	}
+	return &TelemetrySampleRepository{
+		collection: db.Collection("telemetry_samples"),
+		timeouts:   DefaultTimeouts(),
+		retention:  retention,
+	}
+}
+
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *TelemetrySampleRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
+// InsertMany stores a batch of samples
+func (r *TelemetrySampleRepository) InsertMany(ctx context.Context, samples []*models.TelemetrySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	documents := make([]interface{}, len(samples))
+	for i, sample := range samples {
+		documents[i] = sample
+	}
+
+	// Unordered so one bad document doesn't drop the rest of the batch
+	_, err := r.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// FindInRange returns a vehicle's samples within [from, to], oldest first. A
+// positive limit caps the samples returned.
+func (r *TelemetrySampleRepository) FindInRange(ctx context.Context, vehicleID string, from, to time.Time, limit int64) ([]*models.TelemetrySample, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, BuildTelemetryRangeFilter(vehicleID, from, to), telemetryRangeOptions(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	samples := []*models.TelemetrySample{}
+	for cursor.Next(ctx) {
+		var sample models.TelemetrySample
+		if err := cursor.Decode(&sample); err != nil {
+			return nil, err
+		}
+		samples = append(samples, &sample)
+	}
+
+	return samples, cursor.Err()
+}
+
+// Downsample summarizes a vehicle's samples within [from, to] into one bucket
+// per interval, oldest first. Intervals without samples are left out.
+func (r *TelemetrySampleRepository) Downsample(ctx context.Context, vehicleID string, from, to time.Time, interval time.Duration) ([]*models.TelemetryBucket, error) {
+	pipeline, err := BuildTelemetryDownsamplePipeline(vehicleID, from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.timeouts.aggregate(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []*models.TelemetryBucket{}
+	for cursor.Next(ctx) {
+		var bucket models.TelemetryBucket
+		if err := cursor.Decode(&bucket); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, &bucket)
+	}
+
+	return buckets, cursor.Err()
+}
+
+// BuildTelemetryRangeFilter matches a vehicle's samples within [from, to]. It
+// is served by the (vehicle_id, timestamp) index.
+func BuildTelemetryRangeFilter(vehicleID string, from, to time.Time) bson.M {
+	return bson.M{
+		"vehicle_id": vehicleID,
+		"timestamp":  bson.M{"$gte": from, "$lte": to},
+	}
+}
+
+func telemetryRangeOptions(limit int64) *options.FindOptions {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	return opts
+}
+
+// BuildTelemetryDownsamplePipeline groups a vehicle's samples within
+// [from, to] into buckets aligned to multiples of interval since the epoch
+func BuildTelemetryDownsamplePipeline(vehicleID string, from, to time.Time, interval time.Duration) (mongo.Pipeline, error) {
+	if interval < time.Second {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDownsampleInterval, interval)
+	}
+	intervalMs := interval.Milliseconds()
+
+	// The latest of the interval's fuel readings; samples without one are skipped
+	lastFuel := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{"$fuel_level", nil}},
+		bson.D{{Key: "t", Value: "$timestamp"}, {Key: "v", Value: "$fuel_level"}},
+		"$$REMOVE",
+	}}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: BuildTelemetryRangeFilter(vehicleID, from, to)}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$subtract": bson.A{
+				"$timestamp",
+				bson.M{"$mod": bson.A{bson.M{"$toLong": "$timestamp"}, intervalMs}},
+			}},
+			"samples":   bson.M{"$sum": 1},
+			"avg_lat":   bson.M{"$avg": "$location.lat"},
+			"avg_lng":   bson.M{"$avg": "$location.lng"},
+			"avg_speed": bson.M{"$avg": "$speed"},
+			"max_speed": bson.M{"$max": "$speed"},
+			"last_fuel": bson.M{"$max": lastFuel},
+			"odometer":  bson.M{"$max": "$odometer"},
+		}}},
+		{{Key: "$set", Value: bson.M{"fuel_level": "$last_fuel.v"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}, nil
+}
+
+// CreateIndexes creates the index used by range queries and the retention
+// index. A changed retention is applied to an existing retention index.
+func (r *TelemetrySampleRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, telemetrySampleIndexes(r.retention))
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == indexOptionsConflictCode {
+		return r.collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: r.collection.Name()},
+			{Key: "index", Value: bson.M{
+				"name":               telemetrySampleTTLIndex,
+				"expireAfterSeconds": int64(r.retention.Seconds()),
+			}},
+		}).Err()
+	}
+	return err
+}
+
+func telemetrySampleIndexes(retention time.Duration) []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "vehicle_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "timestamp", Value: 1}},
+			Options: options.Index().
+				SetName(telemetrySampleTTLIndex).
+				SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	}
+}
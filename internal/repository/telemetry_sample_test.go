@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuildTelemetryRangeFilter(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	filter := BuildTelemetryRangeFilter("vehicle1", from, to)
+
+	assert.Equal(t, bson.M{
+		"vehicle_id": "vehicle1",
+		"timestamp":  bson.M{"$gte": from, "$lte": to},
+	}, filter)
+}
+
+func TestTelemetryRangeOptions_ReturnsSamplesOldestFirst(t *testing.T) {
+	opts := telemetryRangeOptions(500)
+
+	assert.Equal(t, bson.D{{Key: "timestamp", Value: 1}}, opts.Sort)
+	require.NotNil(t, opts.Limit)
+	assert.Equal(t, int64(500), *opts.Limit)
+	assert.Nil(t, telemetryRangeOptions(0).Limit)
+}
+
+func TestTelemetrySampleIndexes(t *testing.T) {
+	indexes := telemetrySampleIndexes(7 * 24 * time.Hour)
+	require.Len(t, indexes, 2)
+
+	// Range queries are served by the compound index
+	assert.Equal(t, bson.D{{Key: "vehicle_id", Value: 1}, {Key: "timestamp", Value: 1}}, indexes[0].Keys)
+
+	// Old samples expire after the retention period
+	assert.Equal(t, bson.D{{Key: "timestamp", Value: 1}}, indexes[1].Keys)
+	require.NotNil(t, indexes[1].Options.ExpireAfterSeconds)
+	assert.Equal(t, int32(7*24*60*60), *indexes[1].Options.ExpireAfterSeconds)
+	assert.Equal(t, telemetrySampleTTLIndex, *indexes[1].Options.Name)
+}
+
+func TestNewTelemetrySampleRepository_DefaultRetention(t *testing.T) {
+	repo := NewTelemetrySampleRepository(newUnreachableDatabase(t), 0)
+
+	assert.Equal(t, DefaultTelemetrySampleRetention, repo.retention)
+}
+
+func TestBuildTelemetryDownsamplePipeline(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	pipeline, err := BuildTelemetryDownsamplePipeline("vehicle1", from, to, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 4)
+
+	assert.Equal(t, "$match", pipeline[0][0].Key)
+	assert.Equal(t, BuildTelemetryRangeFilter("vehicle1", from, to), pipeline[0][0].Value)
+
+	group := pipeline[1][0].Value.(bson.M)
+	bucketStart := group["_id"].(bson.M)["$subtract"].(bson.A)
+	assert.Equal(t, bson.M{"$mod": bson.A{bson.M{"$toLong": "$timestamp"}, int64(300000)}}, bucketStart[1])
+
+	// Buckets come back oldest first
+	assert.Equal(t, bson.D{{Key: "_id", Value: 1}}, pipeline[3][0].Value)
+}
+
+func TestBuildTelemetryDownsamplePipeline_RejectsSubSecondIntervals(t *testing.T) {
+	_, err := BuildTelemetryDownsamplePipeline("vehicle1", time.Now().Add(-time.Hour), time.Now(), 500*time.Millisecond)
+
+	assert.ErrorIs(t, err, ErrInvalidDownsampleInterval)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long each kind of database operation may run. The
+// deadline is applied on top of the caller's context, so cancelling a request
+// stops its queries straight away and whichever ends first wins.
+type Timeouts struct {
+	// Read bounds single-document lookups, list queries and counts
+	Read time.Duration
+	// Write bounds inserts, updates and deletes, including index builds
+	Write time.Duration
+	// Aggregate bounds aggregations and queries spanning many vehicles
+	Aggregate time.Duration
+	// Stream bounds cursor iteration for streamed exports, which run far
+	// longer than a regular query
+	Stream time.Duration
+}
+
+// DefaultTimeouts returns the timeouts used unless SetTimeouts is called
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:      10 * time.Second,
+		Write:     10 * time.Second,
+		Aggregate: 30 * time.Second,
+		Stream:    5 * time.Minute,
+	}
+}
+
+// withDefaults fills in the default for every unset timeout
+func (t Timeouts) withDefaults() Timeouts {
+	defaults := DefaultTimeouts()
+	if t.Read <= 0 {
+		t.Read = defaults.Read
+	}
+	if t.Write <= 0 {
+		t.Write = defaults.Write
+	}
+	if t.Aggregate <= 0 {
+		t.Aggregate = defaults.Aggregate
+	}
+	if t.Stream <= 0 {
+		t.Stream = defaults.Stream
+	}
+	return t
+}
+
+func (t Timeouts) read(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Read)
+}
+
+func (t Timeouts) write(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Write)
+}
+
+func (t Timeouts) aggregate(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Aggregate)
+}
+
+func (t Timeouts) stream(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Stream)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newUnreachableDatabase returns a database on a server that never answers.
+// Nothing listens on the port, so every query waits on server selection until
+// its context ends, like a query stuck on a slow server.
+func newUnreachableDatabase(t *testing.T) *mongo.Database {
+	opts := options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(time.Minute)
+	client, err := mongo.Connect(context.Background(), opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	return client.Database("fleet_test")
+}
+
+func TestVehicleRepository_CancelledContextAbortsFind(t *testing.T) {
+	repo := NewVehicleRepository(newUnreachableDatabase(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := repo.FindAll(ctx)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second, "the find returns once the request is cancelled")
+}
+
+func TestAlertRepository_CancelledContextAbortsFind(t *testing.T) {
+	repo := NewAlertRepository(newUnreachableDatabase(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := repo.FindWithFilters(ctx, AlertFilter{}, 10, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMaintenanceRepository_ReadTimeoutAbortsFind(t *testing.T) {
+	repo := NewMaintenanceRepository(newUnreachableDatabase(t))
+	repo.SetTimeouts(Timeouts{Read: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := repo.FindByVehicleID(context.Background(), primitive.NewObjectID().Hex())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTimeouts_WithDefaults(t *testing.T) {
+	timeouts := Timeouts{Read: time.Second}.withDefaults()
+	defaults := DefaultTimeouts()
+
+	assert.Equal(t, time.Second, timeouts.Read)
+	assert.Equal(t, defaults.Write, timeouts.Write)
+	assert.Equal(t, defaults.Aggregate, timeouts.Aggregate)
+	assert.Equal(t, defaults.Stream, timeouts.Stream)
+}
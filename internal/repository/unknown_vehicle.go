@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// unknownVehicleRetention is how long a queued vehicle ID is kept after its
+// last report, so IDs from trackers that stopped reporting or were never
+// meant for this fleet don't pile up
+const unknownVehicleRetention = 30 * 24 * time.Hour
+
+// UnknownVehicleRepository queues vehicle IDs telemetry arrived for before
+// the vehicles were provisioned, one document per ID
+type UnknownVehicleRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewUnknownVehicleRepository(db *mongo.Database) *UnknownVehicleRepository {
+	return &UnknownVehicleRepository{
+		collection: db.Collection("unknown_vehicles"),
+		timeouts:   DefaultTimeouts(),
+	}
+}
+
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *UnknownVehicleRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
+// CreateIndexes creates the retention index that drops IDs not reported for
+// unknownVehicleRetention
+func (r *UnknownVehicleRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "last_seen_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(unknownVehicleRetention.Seconds())),
+	})
+	return err
+}
+
+// Enqueue records a telemetry report for an unknown vehicle ID, adding the ID
+// to the queue if it isn't there yet
+func (r *UnknownVehicleRepository) Enqueue(ctx context.Context, vehicleID string, location *models.Location, at time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	set := bson.M{"last_seen_at": at}
+	if location != nil {
+		set["last_location"] = location
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{"first_seen_at": at},
+		"$set":         set,
+		"$inc":         bson.M{"reports": 1},
+	}
+	_, err := r.collection.UpdateByID(ctx, vehicleID, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// FindAll returns the queued vehicle IDs, most recently reported first
+func (r *UnknownVehicleRepository) FindAll(ctx context.Context) ([]*models.UnknownVehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	vehicles := []*models.UnknownVehicle{}
+	for cursor.Next(ctx) {
+		var vehicle models.UnknownVehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, cursor.Err()
+}
+
+// Delete removes a vehicle ID from the queue; IDs not queued are ignored
+func (r *UnknownVehicleRepository) Delete(ctx context.Context, vehicleID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": vehicleID})
+	return err
+}
@@ -5,7 +5,8 @@ import (
 	"errors"
 	"fleet-backend/internal/models"
 	"fleet-backend/pkg/cache"
-	"fmt"
+	"fleet-backend/pkg/logger"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,24 +15,48 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	// ErrVehicleNotFound is returned when no vehicle has the given ID
+	ErrVehicleNotFound = errors.New("vehicle not found")
+	// ErrInvalidVehicleID is returned when a vehicle ID isn't a valid object ID
+	ErrInvalidVehicleID = errors.New("invalid vehicle ID")
+)
+
 type VehicleRepository struct {
 	collection   *mongo.Collection
 	cacheManager cache.CacheManager
+	timeouts     Timeouts
+
+	// indexedMetadataKeys are the metadata keys CreateIndexes indexes
+	indexedMetadataKeys []string
 }
 
 func NewVehicleRepository(db *mongo.Database) *VehicleRepository {
 	return &VehicleRepository{
 		collection: db.Collection("vehicles"),
+		timeouts:   DefaultTimeouts(),
 	}
 }
 
+// SetTimeouts sets how long each kind of query may run; unset timeouts keep
+// their defaults
+func (r *VehicleRepository) SetTimeouts(timeouts Timeouts) {
+	r.timeouts = timeouts.withDefaults()
+}
+
+// SetIndexedMetadataKeys sets the metadata keys CreateIndexes indexes. Other
+// keys stay queryable but are matched by a collection scan.
+func (r *VehicleRepository) SetIndexedMetadataKeys(keys []string) {
+	r.indexedMetadataKeys = keys
+}
+
 // SetCacheManager allows setting the cache manager for cache invalidation
 func (r *VehicleRepository) SetCacheManager(cacheManager cache.CacheManager) {
 	r.cacheManager = cacheManager
 }
 
-func (r *VehicleRepository) Create(vehicle *models.Vehicle) (*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) Create(ctx context.Context, vehicle *models.Vehicle) (*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	result, err := r.collection.InsertOne(ctx, vehicle)
@@ -43,20 +68,20 @@ func (r *VehicleRepository) Create(vehicle *models.Vehicle) (*models.Vehicle, er
 	return vehicle, nil
 }
 
-func (r *VehicleRepository) FindByID(id string) (*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) FindByID(ctx context.Context, id string) (*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid vehicle ID")
+		return nil, ErrInvalidVehicleID
 	}
 
 	var vehicle models.Vehicle
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&vehicle)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("vehicle not found")
+			return nil, ErrVehicleNotFound
 		}
 		return nil, err
 	}
@@ -64,15 +89,66 @@ func (r *VehicleRepository) FindByID(id string) (*models.Vehicle, error) {
 	return &vehicle, nil
 }
 
-func (r *VehicleRepository) FindByPlateNumber(plateNumber string) (*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// FindByIDs fetches the vehicles with the given IDs in a single $in query.
+// Invalid and unknown IDs are skipped; the result is in no particular order.
+func (r *VehicleRepository) FindByIDs(ctx context.Context, ids []string) ([]*models.Vehicle, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	if len(objectIDs) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, cursor.Err()
+}
+
+// FindByVIN returns the vehicle with the given VIN, archived vehicles included
+func (r *VehicleRepository) FindByVIN(ctx context.Context, vin string) (*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var vehicle models.Vehicle
+	err := r.collection.FindOne(ctx, bson.M{"vin": vin}).Decode(&vehicle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrVehicleNotFound
+		}
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
+
+func (r *VehicleRepository) FindByPlateNumber(ctx context.Context, plateNumber string) (*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	var vehicle models.Vehicle
 	err := r.collection.FindOne(ctx, bson.M{"plate_number": plateNumber}).Decode(&vehicle)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("vehicle not found")
+			return nil, ErrVehicleNotFound
 		}
 		return nil, err
 	}
@@ -80,13 +156,88 @@ func (r *VehicleRepository) FindByPlateNumber(plateNumber string) (*models.Vehic
 	return &vehicle, nil
 }
 
-func (r *VehicleRepository) FindAll() ([]*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// FindAll returns every vehicle that has not been archived
+func (r *VehicleRepository) FindAll(ctx context.Context) ([]*models.Vehicle, error) {
+	return r.findAll(ctx, false)
+}
+
+// FindAllIncludingArchived returns every vehicle, archived or not
+func (r *VehicleRepository) FindAllIncludingArchived(ctx context.Context) ([]*models.Vehicle, error) {
+	return r.findAll(ctx, true)
+}
+
+func (r *VehicleRepository) findAll(ctx context.Context, includeArchived bool) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
+	cursor, err := r.findAllCursor(ctx, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, nil
+}
+
+// StreamAll decodes vehicles one at a time in FindAll order and passes each to fn,
+// so exports never hold the whole collection in memory. Iteration stops at the
+// first error returned by fn.
+func (r *VehicleRepository) StreamAll(ctx context.Context, fn func(*models.Vehicle) error) error {
+	ctx, cancel := r.timeouts.stream(ctx)
+	defer cancel()
+
+	cursor, err := r.findAllCursor(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return err
+		}
+		if err := fn(&vehicle); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// findAllCursor opens a cursor over every vehicle
+func (r *VehicleRepository) findAllCursor(ctx context.Context, includeArchived bool) (*mongo.Cursor, error) {
+	filter := bson.M{}
+	if !includeArchived {
+		filter = notArchived(filter)
+	}
+
 	// Sort by last_update descending to get most recent updates first
 	opts := options.Find().SetSort(bson.D{{Key: "last_update", Value: -1}})
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	return r.collection.Find(ctx, filter, opts)
+}
+
+// notArchived restricts a filter to vehicles without a deleted_at timestamp.
+// A null match also covers documents written before archiving existed.
+func notArchived(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+func (r *VehicleRepository) FindByStatus(ctx context.Context, status string) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, notArchived(bson.M{"status": status}))
 	if err != nil {
 		return nil, err
 	}
@@ -104,11 +255,12 @@ func (r *VehicleRepository) FindAll() ([]*models.Vehicle, error) {
 	return vehicles, nil
 }
 
-func (r *VehicleRepository) FindByStatus(status string) ([]*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// FindByStatuses returns vehicles in any of the given statuses
+func (r *VehicleRepository) FindByStatuses(ctx context.Context, statuses []string) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{"status": status})
+	cursor, err := r.collection.Find(ctx, notArchived(bson.M{"status": bson.M{"$in": statuses}}))
 	if err != nil {
 		return nil, err
 	}
@@ -123,11 +275,43 @@ func (r *VehicleRepository) FindByStatus(status string) ([]*models.Vehicle, erro
 		vehicles = append(vehicles, &vehicle)
 	}
 
-	return vehicles, nil
+	return vehicles, cursor.Err()
+}
+
+// FindByMetadata returns vehicles whose metadata has every given key set to the
+// given value and, when statuses is not empty, that are in one of the statuses
+func (r *VehicleRepository) FindByMetadata(ctx context.Context, metadata map[string]string, statuses []string) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	filter := bson.M{}
+	for key, value := range metadata {
+		filter["metadata."+key] = value
+	}
+	if len(statuses) > 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	cursor, err := r.collection.Find(ctx, notArchived(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, cursor.Err()
 }
 
-func (r *VehicleRepository) FindByDriver(driver string) ([]*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) FindByDriver(ctx context.Context, driver string) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	cursor, err := r.collection.Find(ctx, bson.M{"driver": driver})
@@ -148,8 +332,134 @@ func (r *VehicleRepository) FindByDriver(driver string) ([]*models.Vehicle, erro
 	return vehicles, nil
 }
 
-func (r *VehicleRepository) FindByFuelLevelBelow(threshold float64) ([]*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// FindByTag returns the vehicles carrying the tag
+func (r *VehicleRepository) FindByTag(ctx context.Context, tag string) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, notArchived(bson.M{"tags": tag}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, nil
+}
+
+// FindTags returns the tags of every tagged vehicle, keyed by vehicle ID hex
+func (r *VehicleRepository) FindTags(ctx context.Context) (map[string][]string, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{"tags": 1})
+	cursor, err := r.collection.Find(ctx, notArchived(bson.M{"tags.0": bson.M{"$exists": true}}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := make(map[string][]string)
+	for cursor.Next(ctx) {
+		var vehicle struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Tags []string           `bson:"tags"`
+		}
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		tags[vehicle.ID.Hex()] = vehicle.Tags
+	}
+
+	return tags, cursor.Err()
+}
+
+// Search finds vehicles whose name, plate number, VIN or driver contains the
+// query, case-insensitively, ordered by name
+func (r *VehicleRepository) Search(ctx context.Context, query string, limit int) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, notArchived(BuildVehicleSearchFilter(query)), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, nil
+}
+
+// BuildVehicleSearchFilter matches the query as a literal substring of any searchable field.
+// A text index would only support whole-word matches, so partial plates and VINs need a regex.
+func BuildVehicleSearchFilter(query string) bson.M {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"name": pattern},
+			bson.M{"plate_number": pattern},
+			bson.M{"vin": pattern},
+			bson.M{"driver": pattern},
+		},
+	}
+}
+
+// FindSilentSince returns vehicles not updated since cutoff, whatever their
+// stored status, the longest silent first
+func (r *VehicleRepository) FindSilentSince(ctx context.Context, cutoff time.Time) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "last_update", Value: 1}})
+	cursor, err := r.collection.Find(ctx, notArchived(BuildSilentVehicleFilter(cutoff)), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []*models.Vehicle
+	for cursor.Next(ctx) {
+		var vehicle models.Vehicle
+		if err := cursor.Decode(&vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	return vehicles, cursor.Err()
+}
+
+// BuildSilentVehicleFilter matches vehicles last updated before cutoff, and
+// vehicles without a last update at all
+func BuildSilentVehicleFilter(cutoff time.Time) bson.M {
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"last_update": bson.M{"$lt": cutoff}},
+			bson.M{"last_update": nil},
+		},
+	}
+}
+
+func (r *VehicleRepository) FindByFuelLevelBelow(ctx context.Context, threshold float64) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	cursor, err := r.collection.Find(ctx, bson.M{"fuel_level": bson.M{"$lt": threshold}})
@@ -170,8 +480,8 @@ func (r *VehicleRepository) FindByFuelLevelBelow(threshold float64) ([]*models.V
 	return vehicles, nil
 }
 
-func (r *VehicleRepository) FindInLocationRadius(lat, lng, radiusKm float64) ([]*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) FindInLocationRadius(ctx context.Context, lat, lng, radiusKm float64) ([]*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	// Simple radius calculation (for more precise geospatial queries, use MongoDB's geospatial features)
@@ -207,13 +517,13 @@ func (r *VehicleRepository) FindInLocationRadius(lat, lng, radiusKm float64) ([]
 	return vehicles, nil
 }
 
-func (r *VehicleRepository) Update(id string, vehicle *models.Vehicle) (*models.Vehicle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) Update(ctx context.Context, id string, vehicle *models.Vehicle) (*models.Vehicle, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid vehicle ID")
+		return nil, ErrInvalidVehicleID
 	}
 
 	vehicle.UpdatedAt = time.Now()
@@ -232,26 +542,26 @@ func (r *VehicleRepository) Update(id string, vehicle *models.Vehicle) (*models.
 	var updatedVehicle models.Vehicle
 	if err := result.Decode(&updatedVehicle); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("vehicle not found")
+			return nil, ErrVehicleNotFound
 		}
 		return nil, err
 	}
 
 	// Trigger cache invalidation if cache manager is available
 	if r.cacheManager != nil {
-		r.invalidateVehicleCache(id)
+		r.invalidateVehicleCache(ctx, id)
 	}
 
 	return &updatedVehicle, nil
 }
 
-func (r *VehicleRepository) UpdateLocation(id string, location models.Location) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) UpdateLocation(ctx context.Context, id string, location models.Location) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid vehicle ID")
+		return ErrInvalidVehicleID
 	}
 
 	update := bson.M{
@@ -268,24 +578,24 @@ func (r *VehicleRepository) UpdateLocation(id string, location models.Location)
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("vehicle not found")
+		return ErrVehicleNotFound
 	}
 
 	// Trigger cache invalidation if cache manager is available
 	if r.cacheManager != nil {
-		r.invalidateVehicleCache(id)
+		r.invalidateVehicleCache(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *VehicleRepository) UpdateFuelLevel(id string, fuelLevel float64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) UpdateFuelLevel(ctx context.Context, id string, fuelLevel float64) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid vehicle ID")
+		return ErrInvalidVehicleID
 	}
 
 	update := bson.M{
@@ -302,24 +612,24 @@ func (r *VehicleRepository) UpdateFuelLevel(id string, fuelLevel float64) error
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("vehicle not found")
+		return ErrVehicleNotFound
 	}
 
 	// Trigger cache invalidation if cache manager is available
 	if r.cacheManager != nil {
-		r.invalidateVehicleCache(id)
+		r.invalidateVehicleCache(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *VehicleRepository) UpdateStatus(id string, status string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid vehicle ID")
+		return ErrInvalidVehicleID
 	}
 
 	update := bson.M{
@@ -336,24 +646,260 @@ func (r *VehicleRepository) UpdateStatus(id string, status string) error {
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("vehicle not found")
+		return ErrVehicleNotFound
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// UpdateAlertRouting sets a vehicle's alert routing override, or removes it when routing is nil
+func (r *VehicleRepository) UpdateAlertRouting(ctx context.Context, id string, routing *models.AlertRouting) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
+	}
+
+	update := bson.M{
+		"$set": bson.M{"alert_routing": routing, "updated_at": time.Now()},
+	}
+	if routing == nil {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"alert_routing": ""},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrVehicleNotFound
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// ReplaceMetadata replaces a vehicle's metadata, or removes it when metadata is empty
+func (r *VehicleRepository) ReplaceMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	update := bson.M{
+		"$set": bson.M{"metadata": metadata, "updated_at": time.Now()},
+	}
+	if len(metadata) == 0 {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"metadata": ""},
+		}
+	}
+	return r.updateMetadata(ctx, id, update)
+}
+
+// PatchMetadata sets the given metadata keys and removes the keys in remove,
+// leaving the vehicle's other keys untouched
+func (r *VehicleRepository) PatchMetadata(ctx context.Context, id string, set map[string]string, remove []string) error {
+	fields := bson.M{"updated_at": time.Now()}
+	for key, value := range set {
+		fields["metadata."+key] = value
+	}
+	update := bson.M{"$set": fields}
+	if len(remove) > 0 {
+		unset := bson.M{}
+		for _, key := range remove {
+			unset["metadata."+key] = ""
+		}
+		update["$unset"] = unset
+	}
+	return r.updateMetadata(ctx, id, update)
+}
+
+func (r *VehicleRepository) updateMetadata(ctx context.Context, id string, update bson.M) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrVehicleNotFound
 	}
 
 	// Trigger cache invalidation if cache manager is available
 	if r.cacheManager != nil {
-		r.invalidateVehicleCache(id)
+		r.invalidateVehicleCache(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *VehicleRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// SetTags replaces a vehicle's tags, or removes them when tags is empty
+func (r *VehicleRepository) SetTags(ctx context.Context, id string, tags []string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid vehicle ID")
+		return ErrInvalidVehicleID
+	}
+
+	update := bson.M{
+		"$set": bson.M{"tags": tags, "updated_at": time.Now()},
+	}
+	if len(tags) == 0 {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"tags": ""},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrVehicleNotFound
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// UpdateConnectivity records a vehicle's connectivity state and, when status
+// is not empty, its status. last_update is left alone since no telemetry arrived.
+func (r *VehicleRepository) UpdateConnectivity(ctx context.Context, id string, connectivity string, status string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
+	}
+
+	set := bson.M{
+		"connectivity": connectivity,
+		"updated_at":   time.Now(),
+	}
+	if status != "" {
+		set["status"] = status
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrVehicleNotFound
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// Archive soft-deletes a vehicle by stamping deleted_at. The document is kept so
+// maintenance records and alerts that reference it stay resolvable.
+func (r *VehicleRepository) Archive(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
+	}
+
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		notArchived(bson.M{"_id": objectID}),
+		bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrVehicleNotFound
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// Restore clears deleted_at on an archived vehicle
+func (r *VehicleRepository) Restore(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{
+			"$unset": bson.M{"deleted_at": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("archived vehicle not found")
+	}
+
+	// Trigger cache invalidation if cache manager is available
+	if r.cacheManager != nil {
+		r.invalidateVehicleCache(ctx, id)
+	}
+
+	return nil
+}
+
+// Delete permanently removes a vehicle document
+func (r *VehicleRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidVehicleID
 	}
 
 	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
@@ -362,38 +908,41 @@ func (r *VehicleRepository) Delete(id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return errors.New("vehicle not found")
+		return ErrVehicleNotFound
 	}
 
 	// Trigger cache invalidation if cache manager is available
 	if r.cacheManager != nil {
-		r.invalidateVehicleCache(id)
+		r.invalidateVehicleCache(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *VehicleRepository) Count() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
-	count, err := r.collection.CountDocuments(ctx, bson.M{})
+	count, err := r.collection.CountDocuments(ctx, notArchived(bson.M{}))
 	return count, err
 }
 
-func (r *VehicleRepository) CountByStatus(status string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
-	count, err := r.collection.CountDocuments(ctx, bson.M{"status": status})
+	count, err := r.collection.CountDocuments(ctx, notArchived(bson.M{"status": status}))
 	return count, err
 }
 
-func (r *VehicleRepository) GetFleetStatistics() (map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *VehicleRepository) GetFleetStatistics(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := r.timeouts.aggregate(ctx)
 	defer cancel()
 
 	pipeline := []bson.M{
+		{
+			"$match": notArchived(bson.M{}),
+		},
 		{
 			"$group": bson.M{
 				"_id": nil,
@@ -421,16 +970,13 @@ func (r *VehicleRepository) GetFleetStatistics() (map[string]interface{}, error)
 	return result, nil
 }
 
-// CreateIndexes creates necessary indexes for the vehicles collection
-func (r *VehicleRepository) CreateIndexes() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// CreateIndexes creates necessary indexes for the vehicles collection. The
+// unique plate number index is created separately by CreatePlateIndex.
+func (r *VehicleRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	indexes := []mongo.IndexModel{
-		{
-			Keys:    bson.D{{Key: "plate_number", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		},
 		{
 			Keys: bson.D{{Key: "status", Value: 1}},
 		},
@@ -452,20 +998,69 @@ func (r *VehicleRepository) CreateIndexes() error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}},
+		},
+	}
+
+	// Only configured metadata keys are indexed; the partial filter keeps
+	// vehicles without the key out of the index
+	for _, key := range r.indexedMetadataKeys {
+		field := "metadata." + key
+		indexes = append(indexes, mongo.IndexModel{
+			Keys: bson.D{{Key: field, Value: 1}},
+			Options: options.Index().
+				SetName("metadata_" + key).
+				SetPartialFilterExpression(bson.M{field: bson.M{"$exists": true}}),
+		})
 	}
 
 	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
 
+// CreatePlateIndex creates the unique plate number index. It fails while any
+// two vehicles, archived ones included, share a plate number.
+func (r *VehicleRepository) CreatePlateIndex(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "plate_number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// VINIndexName names the unique VIN index; duplicate key errors name the
+// index they violated
+const VINIndexName = "vin_unique"
+
+// CreateVINIndex creates the unique VIN index. VINs are optional and vehicles
+// without one store an empty string, so like a sparse index it only covers
+// vehicles that have a VIN. It fails while any two vehicles share a VIN.
+func (r *VehicleRepository) CreateVINIndex(ctx context.Context) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "vin", Value: 1}},
+		Options: options.Index().
+			SetName(VINIndexName).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"vin": bson.M{"$gt": ""}}),
+	})
+	return err
+}
+
 // invalidateVehicleCache invalidates cache entries for a specific vehicle
-func (r *VehicleRepository) invalidateVehicleCache(vehicleID string) {
+func (r *VehicleRepository) invalidateVehicleCache(ctx context.Context, vehicleID string) {
 	if err := r.cacheManager.InvalidateVehicle(vehicleID); err != nil {
-		fmt.Printf("Failed to invalidate vehicle cache for %s: %v\n", vehicleID, err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicle cache", "vehicle_id", vehicleID, "error", err)
 	}
 	
 	// Also invalidate list caches that might contain this vehicle
 	if err := r.cacheManager.Delete("fleet:vehicle_list:all_vehicles"); err != nil {
-		fmt.Printf("Failed to invalidate all vehicles cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate all vehicles cache", "error", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookRepository(db *mongo.Database) *WebhookRepository {
+	return &WebhookRepository{
+		collection: db.Collection("webhook_subscriptions"),
+	}
+}
+
+func (r *WebhookRepository) Create(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.ID = result.InsertedID.(primitive.ObjectID)
+	return subscription, nil
+}
+
+func (r *WebhookRepository) FindByID(id string) (*models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid webhook ID")
+	}
+
+	var subscription models.WebhookSubscription
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *WebhookRepository) FindAll() ([]*models.WebhookSubscription, error) {
+	return r.find(bson.M{})
+}
+
+// FindActive returns the subscriptions that currently receive events
+func (r *WebhookRepository) FindActive() ([]*models.WebhookSubscription, error) {
+	return r.find(bson.M{"active": true})
+}
+
+func (r *WebhookRepository) find(filter bson.M) ([]*models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []*models.WebhookSubscription
+	for cursor.Next(ctx) {
+		var subscription models.WebhookSubscription
+		if err := cursor.Decode(&subscription); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) Update(id string, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid webhook ID")
+	}
+
+	subscription.UpdatedAt = time.Now()
+
+	result := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": subscription},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated models.WebhookSubscription
+	if err := result.Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (r *WebhookRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid webhook ID")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("webhook not found")
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fleet-backend/internal/models"
 	"fleet-backend/internal/repository"
+	"fleet-backend/internal/websocket"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,6 +14,7 @@ import (
 type AlertService struct {
 	alertRepo   *repository.AlertRepository
 	vehicleRepo *repository.VehicleRepository
+	wsManager   websocket.WebSocketManager
 }
 
 func NewAlertService(alertRepo *repository.AlertRepository) *AlertService {
@@ -25,47 +28,155 @@ func (s *AlertService) SetVehicleRepository(vehicleRepo *repository.VehicleRepos
 	s.vehicleRepo = vehicleRepo
 }
 
+// SetWebSocketManager allows broadcasting alert changes to connected dashboards
+func (s *AlertService) SetWebSocketManager(wsManager websocket.WebSocketManager) {
+	s.wsManager = wsManager
+}
+
 type CreateAlertRequest struct {
 	VehicleID string `json:"vehicleId" validate:"required"`
-	Type      string `json:"type" validate:"required,oneof=fuel_theft maintenance speeding unauthorized low_fuel"`
+	Type      string `json:"type" validate:"required,oneof=fuel_theft maintenance speeding unauthorized low_fuel geofence_exit odometer_anomaly speed_anomaly"`
 	Message   string `json:"message" validate:"required,min=1,max=500"`
 	Severity  string `json:"severity" validate:"required,oneof=low medium high critical"`
 }
 
+// AlertExportRequest holds the query filters of an alert export
+type AlertExportRequest struct {
+	Severity string     `form:"severity" validate:"omitempty,oneof=low medium high critical"`
+	Type     string     `form:"type"`
+	Resolved *bool      `form:"resolved"`
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Filter converts the request into a repository alert filter
+func (r *AlertExportRequest) Filter() repository.AlertFilter {
+	return repository.AlertFilter{
+		Severity: r.Severity,
+		Type:     r.Type,
+		Resolved: r.Resolved,
+		From:     r.From,
+		To:       r.To,
+	}
+}
+
+// ErrInvalidAlertRange is returned when an alert listing's from is after its to
+var ErrInvalidAlertRange = errors.New("from must not be after to")
+
+// Alert listing page sizes
+const (
+	DefaultAlertPageLimit = 50
+	MaxAlertPageLimit     = 200
+)
+
+// AlertListRequest holds the query filters and pagination of an alert listing
+type AlertListRequest struct {
+	VehicleID string     `form:"vehicleId"`
+	Severity  string     `form:"severity" validate:"omitempty,oneof=low medium high critical"`
+	Type      string     `form:"type"`
+	Resolved  *bool      `form:"resolved"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int        `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset    int        `form:"offset" validate:"omitempty,min=0"`
+}
+
+// Filter converts the request into a repository alert filter
+func (r *AlertListRequest) Filter() repository.AlertFilter {
+	return repository.AlertFilter{
+		VehicleID: r.VehicleID,
+		Severity:  r.Severity,
+		Type:      r.Type,
+		Resolved:  r.Resolved,
+		From:      r.From,
+		To:        r.To,
+	}
+}
+
+// AlertPage is one page of an alert listing
+type AlertPage struct {
+	Alerts  []*models.Alert `json:"alerts"`
+	Total   int64           `json:"total"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+	HasMore bool            `json:"hasMore"`
+}
+
+// alertPageFinder is the subset of the alert repository used by listings
+type alertPageFinder interface {
+	FindWithFilters(ctx context.Context, filter repository.AlertFilter, limit, offset int) ([]*models.Alert, int64, error)
+}
+
+// ListAlerts returns the page of alerts matching the request's filters, most recent first
+func (s *AlertService) ListAlerts(ctx context.Context, req *AlertListRequest) (*AlertPage, error) {
+	return listAlerts(ctx, s.alertRepo, req)
+}
+
+func listAlerts(ctx context.Context, finder alertPageFinder, req *AlertListRequest) (*AlertPage, error) {
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		return nil, ErrInvalidAlertRange
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultAlertPageLimit
+	}
+	if limit > MaxAlertPageLimit {
+		limit = MaxAlertPageLimit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	alerts, total, err := finder.FindWithFilters(ctx, req.Filter(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AlertPage{
+		Alerts:  alerts,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: int64(offset+len(alerts)) < total,
+	}, nil
+}
+
 type UpdateAlertRequest struct {
 	Message  string `json:"message,omitempty"`
 	Severity string `json:"severity,omitempty" validate:"omitempty,oneof=low medium high critical"`
 	Resolved bool   `json:"resolved,omitempty"`
 }
 
-func (s *AlertService) GetAllAlerts() ([]*models.Alert, error) {
-	return s.alertRepo.FindAll()
+func (s *AlertService) GetAllAlerts(ctx context.Context) ([]*models.Alert, error) {
+	return s.alertRepo.FindAll(ctx)
 }
 
-func (s *AlertService) GetAlertByID(id string) (*models.Alert, error) {
-	return s.alertRepo.FindByID(id)
+func (s *AlertService) GetAlertByID(ctx context.Context, id string) (*models.Alert, error) {
+	return s.alertRepo.FindByID(ctx, id)
 }
 
-func (s *AlertService) GetAlertsByVehicle(vehicleID string) ([]*models.Alert, error) {
-	return s.alertRepo.FindByVehicleID(vehicleID)
+func (s *AlertService) GetAlertsByVehicle(ctx context.Context, vehicleID string) ([]*models.Alert, error) {
+	return s.alertRepo.FindByVehicleID(ctx, vehicleID)
 }
 
-func (s *AlertService) GetAlertsByType(alertType string) ([]*models.Alert, error) {
-	return s.alertRepo.FindByType(alertType)
+func (s *AlertService) GetAlertsByType(ctx context.Context, alertType string) ([]*models.Alert, error) {
+	return s.alertRepo.FindByType(ctx, alertType)
 }
 
-func (s *AlertService) GetAlertsBySeverity(severity string) ([]*models.Alert, error) {
-	return s.alertRepo.FindBySeverity(severity)
+func (s *AlertService) GetAlertsBySeverity(ctx context.Context, severity string) ([]*models.Alert, error) {
+	return s.alertRepo.FindBySeverity(ctx, severity)
 }
 
-func (s *AlertService) GetUnresolvedAlerts() ([]*models.Alert, error) {
-	return s.alertRepo.FindUnresolved()
+func (s *AlertService) GetUnresolvedAlerts(ctx context.Context) ([]*models.Alert, error) {
+	return s.alertRepo.FindUnresolved(ctx)
 }
 
-func (s *AlertService) CreateAlert(req *CreateAlertRequest) (*models.Alert, error) {
+func (s *AlertService) CreateAlert(ctx context.Context, req *CreateAlertRequest) (*models.Alert, error) {
 	// Verify vehicle exists
 	if s.vehicleRepo != nil {
-		_, err := s.vehicleRepo.FindByID(req.VehicleID)
+		_, err := s.vehicleRepo.FindByID(ctx, req.VehicleID)
 		if err != nil {
 			return nil, errors.New("vehicle not found")
 		}
@@ -82,22 +193,22 @@ func (s *AlertService) CreateAlert(req *CreateAlertRequest) (*models.Alert, erro
 		Resolved:  false,
 	}
 
-	createdAlert, err := s.alertRepo.Create(alert)
+	createdAlert, err := s.alertRepo.Create(ctx, alert)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update vehicle with new alert if vehicle repo is available
 	if s.vehicleRepo != nil {
-		s.addAlertToVehicle(req.VehicleID, createdAlert)
+		s.addAlertToVehicle(ctx, req.VehicleID, createdAlert)
 	}
 
 	return createdAlert, nil
 }
 
-func (s *AlertService) UpdateAlert(id string, req *UpdateAlertRequest) (*models.Alert, error) {
+func (s *AlertService) UpdateAlert(ctx context.Context, id string, req *UpdateAlertRequest) (*models.Alert, error) {
 	// Find existing alert
-	alert, err := s.alertRepo.FindByID(id)
+	alert, err := s.alertRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, errors.New("alert not found")
 	}
@@ -120,21 +231,21 @@ func (s *AlertService) UpdateAlert(id string, req *UpdateAlertRequest) (*models.
 		alert.ResolvedAt = nil
 	}
 
-	updatedAlert, err := s.alertRepo.Update(id, alert)
+	updatedAlert, err := s.alertRepo.Update(ctx, id, alert)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update vehicle alerts if vehicle repo is available
 	if s.vehicleRepo != nil {
-		s.updateVehicleAlert(alert.VehicleID, updatedAlert)
+		s.updateVehicleAlert(ctx, alert.VehicleID, updatedAlert)
 	}
 
 	return updatedAlert, nil
 }
 
-func (s *AlertService) ResolveAlert(id string) (*models.Alert, error) {
-	alert, err := s.alertRepo.FindByID(id)
+func (s *AlertService) ResolveAlert(ctx context.Context, id string) (*models.Alert, error) {
+	alert, err := s.alertRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, errors.New("alert not found")
 	}
@@ -147,51 +258,51 @@ func (s *AlertService) ResolveAlert(id string) (*models.Alert, error) {
 	alert.ResolvedAt = &time.Time{}
 	*alert.ResolvedAt = time.Now()
 
-	updatedAlert, err := s.alertRepo.Update(id, alert)
+	updatedAlert, err := s.alertRepo.Update(ctx, id, alert)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update vehicle alerts if vehicle repo is available
 	if s.vehicleRepo != nil {
-		s.updateVehicleAlert(alert.VehicleID, updatedAlert)
+		s.updateVehicleAlert(ctx, alert.VehicleID, updatedAlert)
 	}
 
 	return updatedAlert, nil
 }
 
-func (s *AlertService) DismissAlert(id string) error {
+func (s *AlertService) DismissAlert(ctx context.Context, id string) error {
 	// Check if alert exists
-	alert, err := s.alertRepo.FindByID(id)
+	alert, err := s.alertRepo.FindByID(ctx, id)
 	if err != nil {
 		return errors.New("alert not found")
 	}
 
 	// Remove from vehicle alerts if vehicle repo is available
 	if s.vehicleRepo != nil {
-		s.removeAlertFromVehicle(alert.VehicleID, id)
+		s.removeAlertFromVehicle(ctx, alert.VehicleID, id)
 	}
 
-	return s.alertRepo.Delete(id)
+	return s.alertRepo.Delete(ctx, id)
 }
 
-func (s *AlertService) DeleteAlert(id string) error {
+func (s *AlertService) DeleteAlert(ctx context.Context, id string) error {
 	// Check if alert exists
-	alert, err := s.alertRepo.FindByID(id)
+	alert, err := s.alertRepo.FindByID(ctx, id)
 	if err != nil {
 		return errors.New("alert not found")
 	}
 
 	// Remove from vehicle alerts if vehicle repo is available
 	if s.vehicleRepo != nil {
-		s.removeAlertFromVehicle(alert.VehicleID, id)
+		s.removeAlertFromVehicle(ctx, alert.VehicleID, id)
 	}
 
-	return s.alertRepo.Delete(id)
+	return s.alertRepo.Delete(ctx, id)
 }
 
-func (s *AlertService) GetAlertStatistics() (map[string]interface{}, error) {
-	allAlerts, err := s.alertRepo.FindAll()
+func (s *AlertService) GetAlertStatistics(ctx context.Context) (map[string]interface{}, error) {
+	allAlerts, err := s.alertRepo.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -235,36 +346,43 @@ func (s *AlertService) GetAlertStatistics() (map[string]interface{}, error) {
 }
 
 // Helper methods for vehicle alert synchronization
-func (s *AlertService) addAlertToVehicle(vehicleID string, alert *models.Alert) {
-	vehicle, err := s.vehicleRepo.FindByID(vehicleID)
+func (s *AlertService) addAlertToVehicle(ctx context.Context, vehicleID string, alert *models.Alert) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return
 	}
 
-	// Add alert to vehicle's alerts array
-	vehicle.Alerts = append(vehicle.Alerts, *alert)
-	s.vehicleRepo.Update(vehicleID, vehicle)
+	syncEmbeddedAlert(vehicle, *alert)
+	s.vehicleRepo.Update(ctx, vehicleID, vehicle)
 }
 
-func (s *AlertService) updateVehicleAlert(vehicleID string, alert *models.Alert) {
-	vehicle, err := s.vehicleRepo.FindByID(vehicleID)
+func (s *AlertService) updateVehicleAlert(ctx context.Context, vehicleID string, alert *models.Alert) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return
 	}
 
-	// Update the alert in vehicle's alerts array
-	for i, vehicleAlert := range vehicle.Alerts {
-		if vehicleAlert.ID.Hex() == alert.ID.Hex() {
-			vehicle.Alerts[i] = *alert
-			break
-		}
+	// Resolved alerts leave the vehicle's embedded alerts
+	syncEmbeddedAlert(vehicle, *alert)
+	s.vehicleRepo.Update(ctx, vehicleID, vehicle)
+}
+
+// updateVehicleAlerts applies several changed alerts of one vehicle with a
+// single vehicle update
+func (s *AlertService) updateVehicleAlerts(ctx context.Context, vehicleID string, alerts []*models.Alert) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+	if err != nil {
+		return
 	}
 
-	s.vehicleRepo.Update(vehicleID, vehicle)
+	for _, alert := range alerts {
+		syncEmbeddedAlert(vehicle, *alert)
+	}
+	s.vehicleRepo.Update(ctx, vehicleID, vehicle)
 }
 
-func (s *AlertService) removeAlertFromVehicle(vehicleID string, alertID string) {
-	vehicle, err := s.vehicleRepo.FindByID(vehicleID)
+func (s *AlertService) removeAlertFromVehicle(ctx context.Context, vehicleID string, alertID string) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return
 	}
@@ -277,41 +395,41 @@ func (s *AlertService) removeAlertFromVehicle(vehicleID string, alertID string)
 		}
 	}
 
-	s.vehicleRepo.Update(vehicleID, vehicle)
+	s.vehicleRepo.Update(ctx, vehicleID, vehicle)
 }
 
 // Bulk operations
-func (s *AlertService) ResolveAlertsByVehicle(vehicleID string) error {
-	alerts, err := s.alertRepo.FindByVehicleID(vehicleID)
+func (s *AlertService) ResolveAlertsByVehicle(ctx context.Context, vehicleID string) error {
+	alerts, err := s.alertRepo.FindByVehicleID(ctx, vehicleID)
 	if err != nil {
 		return err
 	}
 
 	for _, alert := range alerts {
 		if !alert.Resolved {
-			s.ResolveAlert(alert.ID.Hex())
+			s.ResolveAlert(ctx, alert.ID.Hex())
 		}
 	}
 
 	return nil
 }
 
-func (s *AlertService) ResolveAlertsByType(alertType string) error {
-	alerts, err := s.alertRepo.FindByType(alertType)
+func (s *AlertService) ResolveAlertsByType(ctx context.Context, alertType string) error {
+	alerts, err := s.alertRepo.FindByType(ctx, alertType)
 	if err != nil {
 		return err
 	}
 
 	for _, alert := range alerts {
 		if !alert.Resolved {
-			s.ResolveAlert(alert.ID.Hex())
+			s.ResolveAlert(ctx, alert.ID.Hex())
 		}
 	}
 
 	return nil
 }
 
-func (s *AlertService) CleanupOldResolvedAlerts(daysOld int) error {
+func (s *AlertService) CleanupOldResolvedAlerts(ctx context.Context, daysOld int) error {
 	cutoffDate := time.Now().AddDate(0, 0, -daysOld)
-	return s.alertRepo.DeleteResolvedBefore(cutoffDate)
+	return s.alertRepo.DeleteResolvedBefore(ctx, cutoffDate)
 }
\ No newline at end of file
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/internal/websocket"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrAlertAlreadyResolved is returned when acknowledging a resolved alert
+var ErrAlertAlreadyResolved = errors.New("alert is already resolved")
+
+var (
+	// ErrEmptyAlertSelection is returned for bulk acknowledgements without
+	// any IDs or filters, so every open alert isn't claimed by accident
+	ErrEmptyAlertSelection = newDomainError(ErrValidation, "select alerts by ID or by at least one filter")
+	// ErrInvalidAlertID is returned for alert IDs that aren't valid object IDs
+	ErrInvalidAlertID = newDomainError(ErrValidation, "invalid alert ID")
+)
+
+// MaxBulkAcknowledge caps the alerts claimed by one bulk acknowledgement;
+// the rest are reported as remaining for a follow-up request
+const MaxBulkAcknowledge = 1000
+
+// AcknowledgeAlertRequest is the optional note left when claiming an alert
+type AcknowledgeAlertRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// alertAcknowledger is the subset of the alert repository used to acknowledge alerts
+type alertAcknowledger interface {
+	FindByID(ctx context.Context, id string) (*models.Alert, error)
+	Acknowledge(ctx context.Context, id, userID, note string) (*models.Alert, error)
+}
+
+// AcknowledgeAlert marks an unresolved alert as claimed by userID. Acknowledged
+// alerts stay unresolved; acknowledging again hands the alert to the new user.
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, id, userID string, req *AcknowledgeAlertRequest) (*models.Alert, error) {
+	alert, err := acknowledgeAlert(ctx, s.alertRepo, id, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update vehicle alerts if vehicle repo is available
+	if s.vehicleRepo != nil {
+		s.updateVehicleAlert(ctx, alert.VehicleID, alert)
+	}
+
+	s.broadcastAcknowledgement(alert)
+	return alert, nil
+}
+
+// BulkAcknowledgeRequest selects the alerts to claim, by ID, by filter or by
+// both. Only unresolved alerts nobody has acknowledged yet are claimed.
+type BulkAcknowledgeRequest struct {
+	IDs       []string   `json:"ids" validate:"omitempty,max=1000"`
+	Type      string     `json:"type"`
+	Severity  string     `json:"severity" validate:"omitempty,oneof=low medium high critical"`
+	VehicleID string     `json:"vehicleId"`
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	Note      string     `json:"note" validate:"max=500"`
+}
+
+// BulkAcknowledgeResult reports a bulk acknowledgement
+type BulkAcknowledgeResult struct {
+	Acknowledged int64 `json:"acknowledged"`
+	// Remaining is how many more alerts matched beyond MaxBulkAcknowledge
+	Remaining int64 `json:"remaining"`
+}
+
+// alertBulkAcknowledger is the subset of the alert repository used to
+// acknowledge alerts in bulk
+type alertBulkAcknowledger interface {
+	FindWithFilters(ctx context.Context, filter repository.AlertFilter, limit, offset int) ([]*models.Alert, int64, error)
+	AcknowledgeMany(ctx context.Context, ids []primitive.ObjectID, userID, note string, at time.Time) (int64, error)
+}
+
+// BulkAcknowledgeAlerts marks the selected alerts as claimed by userID and
+// broadcasts each acknowledgement
+func (s *AlertService) BulkAcknowledgeAlerts(ctx context.Context, userID string, req *BulkAcknowledgeRequest) (*BulkAcknowledgeResult, error) {
+	result, acknowledged, err := bulkAcknowledgeAlerts(ctx, s.alertRepo, userID, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if s.vehicleRepo != nil {
+		byVehicle := make(map[string][]*models.Alert)
+		for _, alert := range acknowledged {
+			byVehicle[alert.VehicleID] = append(byVehicle[alert.VehicleID], alert)
+		}
+		for vehicleID, alerts := range byVehicle {
+			s.updateVehicleAlerts(ctx, vehicleID, alerts)
+		}
+	}
+	for _, alert := range acknowledged {
+		s.broadcastAcknowledgement(alert)
+	}
+
+	return result, nil
+}
+
+// bulkAcknowledgeAlerts claims up to MaxBulkAcknowledge of the selected alerts
+// and returns them as acknowledged
+func bulkAcknowledgeAlerts(ctx context.Context, store alertBulkAcknowledger, userID string, req *BulkAcknowledgeRequest, now time.Time) (*BulkAcknowledgeResult, []*models.Alert, error) {
+	if len(req.IDs) == 0 && req.Type == "" && req.Severity == "" && req.VehicleID == "" && req.From == nil && req.To == nil {
+		return nil, nil, ErrEmptyAlertSelection
+	}
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		return nil, nil, ErrInvalidAlertRange
+	}
+
+	unresolved, unacknowledged := false, false
+	filter := repository.AlertFilter{
+		VehicleID:    req.VehicleID,
+		Severity:     req.Severity,
+		Type:         req.Type,
+		Resolved:     &unresolved,
+		Acknowledged: &unacknowledged,
+		From:         req.From,
+		To:           req.To,
+	}
+	for _, id := range req.IDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrInvalidAlertID, id)
+		}
+		filter.IDs = append(filter.IDs, objectID)
+	}
+
+	alerts, total, err := store.FindWithFilters(ctx, filter, MaxBulkAcknowledge, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &BulkAcknowledgeResult{Remaining: total - int64(len(alerts))}
+	if len(alerts) == 0 {
+		return result, nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(alerts))
+	for i, alert := range alerts {
+		ids[i] = alert.ID
+	}
+	result.Acknowledged, err = store.AcknowledgeMany(ctx, ids, userID, req.Note, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, alert := range alerts {
+		at := now
+		alert.Acknowledged = true
+		alert.AcknowledgedBy = userID
+		alert.AcknowledgedAt = &at
+		alert.AckNote = req.Note
+	}
+	return result, alerts, nil
+}
+
+func acknowledgeAlert(ctx context.Context, store alertAcknowledger, id, userID string, req *AcknowledgeAlertRequest) (*models.Alert, error) {
+	alert, err := store.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("alert not found")
+	}
+	if alert.Resolved {
+		return nil, ErrAlertAlreadyResolved
+	}
+
+	return store.Acknowledge(ctx, id, userID, req.Note)
+}
+
+// broadcastAcknowledgement tells connected dashboards that an alert was claimed
+func (s *AlertService) broadcastAcknowledgement(alert *models.Alert) {
+	if s.wsManager == nil {
+		return
+	}
+
+	timestamp := time.Now()
+	if alert.AcknowledgedAt != nil {
+		timestamp = *alert.AcknowledgedAt
+	}
+
+	wsUpdate := websocket.VehicleUpdate{
+		VehicleID:  alert.VehicleID,
+		UpdateType: "alert_ack",
+		Data: map[string]interface{}{
+			"alertId":        alert.ID.Hex(),
+			"alertType":      alert.Type,
+			"severity":       alert.Severity,
+			"acknowledgedBy": alert.AcknowledgedBy,
+			"acknowledgedAt": timestamp,
+			"ackNote":        alert.AckNote,
+		},
+		Timestamp: timestamp,
+		Priority:  alert.Severity,
+	}
+	if err := s.wsManager.BroadcastVehicleUpdate(alert.VehicleID, wsUpdate); err != nil {
+		fmt.Printf("Failed to broadcast acknowledgement of alert %s: %v\n", alert.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryAlertStore keeps alerts in memory and acknowledges them with the
+// repository's update
+type memoryAlertStore struct {
+	alerts map[string]*models.Alert
+}
+
+func newMemoryAlertStore(alerts ...*models.Alert) *memoryAlertStore {
+	store := &memoryAlertStore{alerts: make(map[string]*models.Alert)}
+	for _, alert := range alerts {
+		alert.ID = primitive.NewObjectID()
+		store.alerts[alert.ID.Hex()] = alert
+	}
+	return store
+}
+
+func (m *memoryAlertStore) FindByID(_ context.Context, id string) (*models.Alert, error) {
+	alert, ok := m.alerts[id]
+	if !ok {
+		return nil, errors.New("alert not found")
+	}
+	found := *alert
+	return &found, nil
+}
+
+func (m *memoryAlertStore) Acknowledge(_ context.Context, id, userID, note string) (*models.Alert, error) {
+	alert, ok := m.alerts[id]
+	if !ok {
+		return nil, errors.New("alert not found")
+	}
+
+	set := repository.BuildAcknowledgeUpdate(userID, note, time.Now())["$set"].(bson.M)
+	alert.Acknowledged = set["acknowledged"].(bool)
+	alert.AcknowledgedBy = set["acknowledged_by"].(string)
+	at := set["acknowledged_at"].(time.Time)
+	alert.AcknowledgedAt = &at
+	alert.AckNote = set["ack_note"].(string)
+
+	found := *alert
+	return &found, nil
+}
+
+// FindWithFilters matches the ID, type, severity, vehicle, resolved and
+// acknowledged criteria of the repository's filter
+func (m *memoryAlertStore) FindWithFilters(_ context.Context, filter repository.AlertFilter, limit, _ int) ([]*models.Alert, int64, error) {
+	ids := make(map[primitive.ObjectID]bool, len(filter.IDs))
+	for _, id := range filter.IDs {
+		ids[id] = true
+	}
+
+	var matched []*models.Alert
+	for _, alert := range m.alerts {
+		switch {
+		case len(ids) > 0 && !ids[alert.ID],
+			filter.Type != "" && alert.Type != filter.Type,
+			filter.Severity != "" && alert.Severity != filter.Severity,
+			filter.VehicleID != "" && alert.VehicleID != filter.VehicleID,
+			filter.Resolved != nil && alert.Resolved != *filter.Resolved,
+			filter.Acknowledged != nil && alert.Acknowledged != *filter.Acknowledged:
+			continue
+		}
+		found := *alert
+		matched = append(matched, &found)
+	}
+
+	total := int64(len(matched))
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (m *memoryAlertStore) AcknowledgeMany(ctx context.Context, ids []primitive.ObjectID, userID, note string, _ time.Time) (int64, error) {
+	var acknowledged int64
+	for _, id := range ids {
+		if alert, ok := m.alerts[id.Hex()]; ok && !alert.Resolved {
+			if _, err := m.Acknowledge(ctx, id.Hex(), userID, note); err != nil {
+				return acknowledged, err
+			}
+			acknowledged++
+		}
+	}
+	return acknowledged, nil
+}
+
+// FindUnresolved matches alerts the way the repository's unresolved query does
+func (m *memoryAlertStore) FindUnresolved() []*models.Alert {
+	var unresolved []*models.Alert
+	for _, alert := range m.alerts {
+		if !alert.Resolved {
+			found := *alert
+			unresolved = append(unresolved, &found)
+		}
+	}
+	return unresolved
+}
+
+func TestAcknowledgeAlert_StaysUnresolvedWithAckMetadata(t *testing.T) {
+	speeding := &models.Alert{VehicleID: "v1", Type: "speeding", Severity: "high"}
+	store := newMemoryAlertStore(speeding)
+	id := speeding.ID.Hex()
+
+	acked, err := acknowledgeAlert(context.Background(), store, id, "user-1", &AcknowledgeAlertRequest{Note: "on my way"})
+	require.NoError(t, err)
+	assert.True(t, acked.Acknowledged)
+	assert.False(t, acked.Resolved)
+
+	unresolved := store.FindUnresolved()
+	require.Len(t, unresolved, 1)
+	alert := unresolved[0]
+	assert.Equal(t, id, alert.ID.Hex())
+	assert.True(t, alert.Acknowledged)
+	assert.Equal(t, "user-1", alert.AcknowledgedBy)
+	assert.Equal(t, "on my way", alert.AckNote)
+	require.NotNil(t, alert.AcknowledgedAt)
+	assert.WithinDuration(t, time.Now(), *alert.AcknowledgedAt, time.Second)
+
+	// Another user can take over the alert
+	acked, err = acknowledgeAlert(context.Background(), store, id, "user-2", &AcknowledgeAlertRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", acked.AcknowledgedBy)
+	assert.Empty(t, acked.AckNote)
+}
+
+func TestAcknowledgeAlert_RejectsResolvedAndMissingAlerts(t *testing.T) {
+	resolved := &models.Alert{VehicleID: "v1", Type: "low_fuel", Severity: "medium", Resolved: true}
+	store := newMemoryAlertStore(resolved)
+
+	_, err := acknowledgeAlert(context.Background(), store, resolved.ID.Hex(), "user-1", &AcknowledgeAlertRequest{})
+	assert.ErrorIs(t, err, ErrAlertAlreadyResolved)
+	assert.False(t, store.alerts[resolved.ID.Hex()].Acknowledged)
+
+	_, err = acknowledgeAlert(context.Background(), store, primitive.NewObjectID().Hex(), "user-1", &AcknowledgeAlertRequest{})
+	assert.Error(t, err)
+}
+
+func TestBuildAcknowledgeUpdate_LeavesResolvedStateAlone(t *testing.T) {
+	set := repository.BuildAcknowledgeUpdate("user-1", "", time.Now())["$set"].(bson.M)
+	assert.NotContains(t, set, "resolved")
+	assert.NotContains(t, set, "resolved_at")
+}
+
+func TestBulkAcknowledgeAlerts_ByFilter(t *testing.T) {
+	lowFuelA := &models.Alert{VehicleID: "v1", Type: "low_fuel", Severity: "medium"}
+	lowFuelB := &models.Alert{VehicleID: "v2", Type: "low_fuel", Severity: "medium"}
+	resolvedLowFuel := &models.Alert{VehicleID: "v3", Type: "low_fuel", Severity: "medium", Resolved: true}
+	speeding := &models.Alert{VehicleID: "v1", Type: "speeding", Severity: "high"}
+	store := newMemoryAlertStore(lowFuelA, lowFuelB, resolvedLowFuel, speeding)
+
+	result, acked, err := bulkAcknowledgeAlerts(context.Background(), store, "user-1", &BulkAcknowledgeRequest{Type: "low_fuel", Note: "refuel scheduled"}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Acknowledged)
+	assert.Zero(t, result.Remaining)
+	require.Len(t, acked, 2)
+	for _, alert := range acked {
+		assert.True(t, alert.Acknowledged)
+		assert.Equal(t, "user-1", alert.AcknowledgedBy)
+	}
+
+	assert.True(t, store.alerts[lowFuelA.ID.Hex()].Acknowledged)
+	assert.True(t, store.alerts[lowFuelB.ID.Hex()].Acknowledged)
+	assert.Equal(t, "refuel scheduled", store.alerts[lowFuelB.ID.Hex()].AckNote)
+	assert.False(t, store.alerts[resolvedLowFuel.ID.Hex()].Acknowledged)
+	assert.False(t, store.alerts[speeding.ID.Hex()].Acknowledged)
+
+	// Alerts already claimed aren't taken over by a later bulk acknowledgement
+	result, _, err = bulkAcknowledgeAlerts(context.Background(), store, "user-2", &BulkAcknowledgeRequest{Type: "low_fuel"}, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, result.Acknowledged)
+	assert.Equal(t, "user-1", store.alerts[lowFuelA.ID.Hex()].AcknowledgedBy)
+}
+
+func TestBulkAcknowledgeAlerts_ByIDList(t *testing.T) {
+	first := &models.Alert{VehicleID: "v1", Type: "speeding", Severity: "high"}
+	second := &models.Alert{VehicleID: "v2", Type: "maintenance", Severity: "low"}
+	untouched := &models.Alert{VehicleID: "v1", Type: "speeding", Severity: "high"}
+	store := newMemoryAlertStore(first, second, untouched)
+
+	req := &BulkAcknowledgeRequest{IDs: []string{first.ID.Hex(), second.ID.Hex()}}
+	result, acked, err := bulkAcknowledgeAlerts(context.Background(), store, "user-1", req, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Acknowledged)
+	assert.Len(t, acked, 2)
+	assert.True(t, store.alerts[first.ID.Hex()].Acknowledged)
+	assert.True(t, store.alerts[second.ID.Hex()].Acknowledged)
+	assert.False(t, store.alerts[untouched.ID.Hex()].Acknowledged)
+}
+
+func TestBulkAcknowledgeAlerts_RejectsEmptyOrInvalidSelection(t *testing.T) {
+	store := newMemoryAlertStore(&models.Alert{VehicleID: "v1", Type: "speeding", Severity: "high"})
+
+	_, _, err := bulkAcknowledgeAlerts(context.Background(), store, "user-1", &BulkAcknowledgeRequest{Note: "all of them"}, time.Now())
+	assert.ErrorIs(t, err, ErrEmptyAlertSelection)
+
+	_, _, err = bulkAcknowledgeAlerts(context.Background(), store, "user-1", &BulkAcknowledgeRequest{IDs: []string{"not-an-id"}}, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidAlertID)
+
+	for _, alert := range store.alerts {
+		assert.False(t, alert.Acknowledged)
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
+
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingAlertStore records persisted alerts
+type recordingAlertStore struct {
+	created []*models.Alert
+}
+
+func (r *recordingAlertStore) Create(_ context.Context, alert *models.Alert) (*models.Alert, error) {
+	r.created = append(r.created, alert)
+	return alert, nil
+}
+
+func (r *recordingAlertStore) MarkAsResolved(_ context.Context, id string) error { return nil }
+
+// recordingBroadcaster records broadcast updates
+type recordingBroadcaster struct {
+	updates []websocket.VehicleUpdate
+}
+
+func (r *recordingBroadcaster) RegisterClient(clientID string, conn *gorillaws.Conn, filters websocket.VehicleFilters) error {
+	return nil
+}
+func (r *recordingBroadcaster) UnregisterClient(clientID string) error { return nil }
+func (r *recordingBroadcaster) BroadcastVehicleUpdate(vehicleID string, update websocket.VehicleUpdate) error {
+	r.updates = append(r.updates, update)
+	return nil
+}
+func (r *recordingBroadcaster) BroadcastBatchUpdates(updates []websocket.VehicleUpdate) error {
+	r.updates = append(r.updates, updates...)
+	return nil
+}
+func (r *recordingBroadcaster) GetConnectedClients() int              { return 0 }
+func (r *recordingBroadcaster) Start() error                          { return nil }
+func (r *recordingBroadcaster) Stop() error                           { return nil }
+func (r *recordingBroadcaster) GetClientStats() websocket.ClientStats { return websocket.ClientStats{} }
+
+// recordingNotifier records notified alerts
+type recordingNotifier struct {
+	alerts []*models.Alert
+}
+
+func (r *recordingNotifier) NotifyAlert(vehicle *models.Vehicle, alert *models.Alert) {
+	r.alerts = append(r.alerts, alert)
+}
+
+func newDispatchTestService() (*VehicleService, *recordingAlertStore, *recordingBroadcaster, *recordingNotifier) {
+	store := &recordingAlertStore{}
+	broadcaster := &recordingBroadcaster{}
+	notifier := &recordingNotifier{}
+
+	service := &VehicleService{
+		alertRepo:      store,
+		wsManager:      broadcaster,
+		alertNotifier:  notifier,
+		severityPolicy: DefaultAlertSeverityPolicy(),
+		fuelAnomalies:  NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy()),
+	}
+	return service, store, broadcaster, notifier
+}
+
+func TestCreateAndDispatchAlert_SideEffectsFireOnce(t *testing.T) {
+	tests := []struct {
+		name      string
+		alertType string
+		severity  string
+		raise     func(s *VehicleService, v *models.Vehicle)
+	}{
+		{
+			name: "fuel theft", alertType: "fuel_theft", severity: "critical",
+			raise: func(s *VehicleService, v *models.Vehicle) {
+				v.FuelLevel = 20
+				s.checkFuelTheft(context.Background(), v, 60)
+			},
+		},
+		{
+			name: "low fuel", alertType: "low_fuel", severity: "medium",
+			raise: func(s *VehicleService, v *models.Vehicle) { v.FuelLevel = 5; s.checkLowFuel(context.Background(), v) },
+		},
+		{
+			name: "speeding", alertType: "speeding", severity: "high",
+			raise: func(s *VehicleService, v *models.Vehicle) { v.Speed = 120; s.checkSpeeding(context.Background(), v) },
+		},
+		{
+			name: "simulated fuel theft", alertType: "fuel_theft", severity: "critical",
+			raise: func(s *VehicleService, v *models.Vehicle) { s.broadcastFuelTheftAlert(context.Background(), v, 60, 20) },
+		},
+		{
+			name: "simulated speeding", alertType: "speeding", severity: "high",
+			raise: func(s *VehicleService, v *models.Vehicle) {
+				s.broadcastSpeedingAlert(context.Background(), v, 120, vehicleSpeedLimit(v))
+			},
+		},
+		{
+			name: "odometer rollback", alertType: "odometer_anomaly", severity: "high",
+			raise: func(s *VehicleService, v *models.Vehicle) { s.checkOdometerRollback(context.Background(), v, 100) },
+		},
+		{
+			name: "implausible speed", alertType: "speed_anomaly", severity: "low",
+			raise: func(s *VehicleService, v *models.Vehicle) { s.checkSpeedPlausible(context.Background(), v, 5000) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store, broadcaster, notifier := newDispatchTestService()
+			vehicle := &models.Vehicle{ID: primitive.NewObjectID(), FuelLevel: 60, MaxFuelCapacity: 100, Odometer: 50000}
+
+			tt.raise(service, vehicle)
+
+			require.Len(t, store.created, 1)
+			require.Len(t, vehicle.Alerts, 1)
+			require.Len(t, broadcaster.updates, 1)
+			require.Len(t, notifier.alerts, 1)
+
+			alert := store.created[0]
+			assert.Equal(t, tt.alertType, alert.Type)
+			assert.Equal(t, tt.severity, alert.Severity)
+			assert.Equal(t, alert.ID, vehicle.Alerts[0].ID)
+			assert.Same(t, alert, notifier.alerts[0])
+
+			update := broadcaster.updates[0]
+			assert.Equal(t, "alert", update.UpdateType)
+			assert.Equal(t, tt.severity, update.Priority)
+			assert.Equal(t, alert.ID.Hex(), update.Data["alertId"])
+			assert.Equal(t, tt.alertType, update.Data["alertType"])
+		})
+	}
+}
+
+func TestCreateAndDispatchAlert_ExplicitSeverityAndData(t *testing.T) {
+	service, store, broadcaster, _ := newDispatchTestService()
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+
+	alert := service.createAndDispatchAlert(context.Background(), vehicle, "geofence_exit", "Left depot", "critical",
+		map[string]interface{}{"geofenceId": "depot"})
+
+	assert.Equal(t, "critical", alert.Severity)
+	require.Len(t, store.created, 1)
+	require.Len(t, broadcaster.updates, 1)
+	assert.Equal(t, "depot", broadcaster.updates[0].Data["geofenceId"])
+	assert.Equal(t, "Left depot", broadcaster.updates[0].Data["message"])
+}
+
+func TestCreateAndDispatchAlert_WithoutDependencies(t *testing.T) {
+	service := &VehicleService{}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 120}
+
+	service.checkSpeeding(context.Background(), vehicle)
+
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "high", vehicle.Alerts[0].Severity)
+}
@@ -0,0 +1,36 @@
+package services
+
+import "fleet-backend/internal/models"
+
+// maxEmbeddedAlerts caps the alerts embedded on a vehicle. The embedded alerts
+// are a summary for vehicle listings; the alerts collection holds every alert.
+const maxEmbeddedAlerts = 20
+
+// boundEmbeddedAlerts keeps the newest unresolved alerts, up to the cap.
+// Alerts are embedded in the order they were raised.
+func boundEmbeddedAlerts(alerts []models.Alert) []models.Alert {
+	// Empty rather than nil, as for new vehicles
+	unresolved := []models.Alert{}
+	for _, alert := range alerts {
+		if !alert.Resolved {
+			unresolved = append(unresolved, alert)
+		}
+	}
+	if len(unresolved) > maxEmbeddedAlerts {
+		unresolved = unresolved[len(unresolved)-maxEmbeddedAlerts:]
+	}
+	return unresolved
+}
+
+// syncEmbeddedAlert applies a raised or changed alert to the vehicle's
+// embedded alerts: unresolved alerts are added or updated, resolved ones removed
+func syncEmbeddedAlert(vehicle *models.Vehicle, alert models.Alert) {
+	for i := range vehicle.Alerts {
+		if vehicle.Alerts[i].ID == alert.ID {
+			vehicle.Alerts[i] = alert
+			vehicle.Alerts = boundEmbeddedAlerts(vehicle.Alerts)
+			return
+		}
+	}
+	vehicle.Alerts = boundEmbeddedAlerts(append(vehicle.Alerts, alert))
+}
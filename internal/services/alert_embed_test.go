@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateAndDispatchAlert_CapsEmbeddedAlerts(t *testing.T) {
+	service := &VehicleService{}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+
+	var raised []*models.Alert
+	for i := 0; i < maxEmbeddedAlerts+10; i++ {
+		raised = append(raised, service.createAndDispatchAlert(context.Background(), vehicle, "speeding", fmt.Sprintf("alert %d", i), "high", nil))
+		assert.LessOrEqual(t, len(vehicle.Alerts), maxEmbeddedAlerts)
+	}
+
+	require.Len(t, vehicle.Alerts, maxEmbeddedAlerts)
+	assert.Equal(t, raised[10].ID, vehicle.Alerts[0].ID, "the oldest alerts are dropped first")
+	assert.Equal(t, raised[len(raised)-1].ID, vehicle.Alerts[maxEmbeddedAlerts-1].ID)
+}
+
+func TestSyncEmbeddedAlert_TracksResolution(t *testing.T) {
+	lowFuel := newTestAlert("low_fuel")
+	speeding := newTestAlert("speeding")
+	vehicle := &models.Vehicle{Alerts: []models.Alert{lowFuel, speeding}}
+
+	// Acknowledging updates the embedded copy in place
+	acknowledged := speeding
+	acknowledged.AcknowledgedBy = "user-1"
+	syncEmbeddedAlert(vehicle, acknowledged)
+	require.Len(t, vehicle.Alerts, 2)
+	assert.Equal(t, "user-1", vehicle.Alerts[1].AcknowledgedBy)
+
+	// Resolving removes it
+	resolvedAt := time.Now()
+	resolved := lowFuel
+	resolved.Resolved = true
+	resolved.ResolvedAt = &resolvedAt
+	syncEmbeddedAlert(vehicle, resolved)
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, speeding.ID, vehicle.Alerts[0].ID)
+
+	// Reopening brings it back
+	syncEmbeddedAlert(vehicle, lowFuel)
+	require.Len(t, vehicle.Alerts, 2)
+	assert.Equal(t, lowFuel.ID, vehicle.Alerts[1].ID)
+
+	// Resolving an alert that isn't embedded changes nothing
+	other := newTestAlert("geofence_exit")
+	other.Resolved = true
+	syncEmbeddedAlert(vehicle, other)
+	assert.Len(t, vehicle.Alerts, 2)
+}
+
+func TestBoundEmbeddedAlerts_TrimsLegacyArrays(t *testing.T) {
+	var alerts []models.Alert
+	for i := 0; i < 50; i++ {
+		alert := newTestAlert("speeding")
+		alert.Resolved = i%2 == 0
+		alerts = append(alerts, alert)
+	}
+
+	bounded := boundEmbeddedAlerts(alerts)
+
+	require.Len(t, bounded, maxEmbeddedAlerts)
+	for _, alert := range bounded {
+		assert.False(t, alert.Resolved)
+	}
+	assert.Equal(t, alerts[49].ID, bounded[maxEmbeddedAlerts-1].ID)
+	assert.Empty(t, boundEmbeddedAlerts([]models.Alert{alerts[0]}))
+	assert.NotNil(t, boundEmbeddedAlerts(nil))
+}
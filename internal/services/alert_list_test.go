@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memoryAlertFinder applies alert filters and pagination to alerts in memory
+type memoryAlertFinder struct {
+	alerts []*models.Alert
+	filter repository.AlertFilter
+}
+
+func (m *memoryAlertFinder) FindWithFilters(_ context.Context, filter repository.AlertFilter, limit, offset int) ([]*models.Alert, int64, error) {
+	m.filter = filter
+
+	matched := []*models.Alert{}
+	for _, alert := range m.alerts {
+		switch {
+		case filter.VehicleID != "" && alert.VehicleID != filter.VehicleID,
+			filter.Severity != "" && alert.Severity != filter.Severity,
+			filter.Type != "" && alert.Type != filter.Type,
+			filter.Resolved != nil && alert.Resolved != *filter.Resolved,
+			filter.From != nil && alert.Timestamp.Before(*filter.From),
+			filter.To != nil && alert.Timestamp.After(*filter.To):
+			continue
+		}
+		matched = append(matched, alert)
+	}
+
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []*models.Alert{}, total, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func TestListAlerts_CombinesFilters(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	inRange := from.Add(48 * time.Hour)
+
+	finder := &memoryAlertFinder{alerts: []*models.Alert{
+		{VehicleID: "v1", Severity: "critical", Timestamp: inRange, Message: "match"},
+		{VehicleID: "v1", Severity: "critical", Timestamp: inRange.Add(time.Hour), Message: "match"},
+		{VehicleID: "v2", Severity: "critical", Timestamp: inRange, Message: "other vehicle"},
+		{VehicleID: "v1", Severity: "low", Timestamp: inRange, Message: "other severity"},
+		{VehicleID: "v1", Severity: "critical", Timestamp: inRange, Resolved: true, Message: "resolved"},
+		{VehicleID: "v1", Severity: "critical", Timestamp: to.Add(time.Hour), Message: "after range"},
+	}}
+
+	unresolved := false
+	page, err := listAlerts(context.Background(), finder, &AlertListRequest{
+		VehicleID: "v1",
+		Severity:  "critical",
+		Resolved:  &unresolved,
+		From:      &from,
+		To:        &to,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), page.Total)
+	require.Len(t, page.Alerts, 2)
+	for _, alert := range page.Alerts {
+		assert.Equal(t, "match", alert.Message)
+	}
+	assert.Equal(t, DefaultAlertPageLimit, page.Limit)
+	assert.False(t, page.HasMore)
+
+	assert.Equal(t, bson.M{
+		"vehicle_id": "v1",
+		"severity":   "critical",
+		"resolved":   false,
+		"timestamp":  bson.M{"$gte": from, "$lte": to},
+	}, repository.BuildAlertFilter(finder.filter))
+}
+
+func TestListAlerts_Paginates(t *testing.T) {
+	finder := &memoryAlertFinder{}
+	for i := 0; i < 5; i++ {
+		finder.alerts = append(finder.alerts, &models.Alert{VehicleID: "v1", Severity: "high"})
+	}
+
+	page, err := listAlerts(context.Background(), finder, &AlertListRequest{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Alerts, 2)
+	assert.Equal(t, int64(5), page.Total)
+	assert.True(t, page.HasMore)
+
+	page, err = listAlerts(context.Background(), finder, &AlertListRequest{Limit: 2, Offset: 4})
+	require.NoError(t, err)
+	assert.Len(t, page.Alerts, 1)
+	assert.False(t, page.HasMore)
+
+	page, err = listAlerts(context.Background(), finder, &AlertListRequest{Limit: MaxAlertPageLimit + 1})
+	require.NoError(t, err)
+	assert.Equal(t, MaxAlertPageLimit, page.Limit)
+}
+
+func TestListAlerts_RejectsInvertedRange(t *testing.T) {
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	_, err := listAlerts(context.Background(), &memoryAlertFinder{}, &AlertListRequest{From: &from, To: &to})
+	assert.ErrorIs(t, err, ErrInvalidAlertRange)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+)
+
+// ErrAlertRoutingUnavailable is returned when routing targets can't be validated
+var ErrAlertRoutingUnavailable = errors.New("alert routing is not enabled")
+
+// AlertTargetValidator checks that alert routing targets exist
+type AlertTargetValidator interface {
+	ValidateAlertTargets(webhookIDs []string) error
+}
+
+// AlertRoutingRequest sets the webhook subscriptions that receive a vehicle's alerts
+type AlertRoutingRequest struct {
+	WebhookIDs     []string `json:"webhookIds" validate:"required,min=1,dive,required"`
+	IncludeDefault bool     `json:"includeDefault"`
+}
+
+// alertRoutingStore is the subset of the vehicle repository used to store alert routing
+type alertRoutingStore interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+	UpdateAlertRouting(ctx context.Context, id string, routing *models.AlertRouting) error
+}
+
+// SetAlertTargetValidator sets how alert routing targets are validated
+func (s *VehicleService) SetAlertTargetValidator(validator AlertTargetValidator) {
+	s.alertTargets = validator
+}
+
+// SetAlertRouting overrides which webhook subscriptions receive the vehicle's alerts
+func (s *VehicleService) SetAlertRouting(ctx context.Context, id string, req *AlertRoutingRequest) (*models.Vehicle, error) {
+	return s.updateAlertRouting(ctx, s.vehicleRepo, id, req)
+}
+
+// ClearAlertRouting returns the vehicle's alerts to the fleet-wide routing
+func (s *VehicleService) ClearAlertRouting(ctx context.Context, id string) (*models.Vehicle, error) {
+	return s.updateAlertRouting(ctx, s.vehicleRepo, id, nil)
+}
+
+// updateAlertRouting stores routing for a vehicle; a nil request removes the override
+func (s *VehicleService) updateAlertRouting(ctx context.Context, store alertRoutingStore, id string, req *AlertRoutingRequest) (*models.Vehicle, error) {
+	vehicle, err := store.FindByID(ctx, id)
+	if err != nil {
+		return nil, vehicleLookupError(err)
+	}
+
+	var routing *models.AlertRouting
+	if req != nil {
+		if s.alertTargets == nil {
+			return nil, ErrAlertRoutingUnavailable
+		}
+		webhookIDs := uniqueIDs(req.WebhookIDs)
+		if err := s.alertTargets.ValidateAlertTargets(webhookIDs); err != nil {
+			return nil, err
+		}
+		routing = &models.AlertRouting{WebhookIDs: webhookIDs, IncludeDefault: req.IncludeDefault}
+	}
+
+	if err := store.UpdateAlertRouting(ctx, id, routing); err != nil {
+		return nil, err
+	}
+	vehicle.AlertRouting = routing
+
+	if s.cacheManager != nil {
+		s.invalidateCacheOnUpdate(ctx, vehicle, vehicle.Driver, vehicle.Status)
+	}
+	return vehicle, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
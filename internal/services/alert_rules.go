@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fleet-backend/internal/models"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Alert thresholds shared by alert generation and auto-resolution
+const (
+	lowFuelThresholdPercent = 20.0 // fuel percentage below which a low_fuel alert is raised
+	speedLimitKmh           = 80   // speed above which a speeding alert is raised, unless a vehicle or zone limit applies
+
+	odometerRollbackToleranceKm = 1 // odometer decrease tolerated as sensor jitter before an odometer_anomaly alert
+
+	defaultMaxPlausibleSpeedKmh = 250 // speeds above this are sensor faults, not driving
+)
+
+// SpeedPlausibility bounds the speeds accepted from telemetry. Readings outside
+// the range are rejected as sensor faults instead of raising speeding alerts.
+// A zero MaxKmh uses the default upper bound.
+type SpeedPlausibility struct {
+	MinKmh int
+	MaxKmh int
+}
+
+// DefaultSpeedPlausibility accepts 0 to 250 km/h
+func DefaultSpeedPlausibility() SpeedPlausibility {
+	return SpeedPlausibility{MinKmh: 0, MaxKmh: defaultMaxPlausibleSpeedKmh}
+}
+
+// IsPlausible reports whether a speed reading is within bounds
+func (p SpeedPlausibility) IsPlausible(speedKmh int) bool {
+	maxKmh := p.MaxKmh
+	if maxKmh == 0 {
+		maxKmh = defaultMaxPlausibleSpeedKmh
+	}
+	return speedKmh >= p.MinKmh && speedKmh <= maxKmh
+}
+
+// defaultAlertSeverities is the built-in severity of each generated alert type
+var defaultAlertSeverities = map[string]string{
+	"fuel_theft":       "critical",
+	"unauthorized":     "critical",
+	"speeding":         "high",
+	"odometer_anomaly": "high",
+	"low_fuel":         "medium",
+	"maintenance":      "medium",
+	"geofence_exit":    "medium",
+	"speed_anomaly":    "low",
+}
+
+// fallbackAlertSeverity is used for alert types without a configured severity
+const fallbackAlertSeverity = "medium"
+
+var validAlertSeverities = map[string]bool{"low": true, "medium": true, "high": true, "critical": true}
+
+// AlertSeverityPolicy maps alert types to the severity newly created alerts of
+// that type are given, so a fleet can tune its severity policy in one place
+type AlertSeverityPolicy struct {
+	severities map[string]string
+	mu         sync.RWMutex
+}
+
+// DefaultAlertSeverityPolicy creates a policy with the built-in severities
+func DefaultAlertSeverityPolicy() *AlertSeverityPolicy {
+	severities := make(map[string]string, len(defaultAlertSeverities))
+	for alertType, severity := range defaultAlertSeverities {
+		severities[alertType] = severity
+	}
+	return &AlertSeverityPolicy{severities: severities}
+}
+
+// NewAlertSeverityPolicy creates a policy with the built-in severities replaced
+// by overrides, keyed by alert type
+func NewAlertSeverityPolicy(overrides map[string]string) (*AlertSeverityPolicy, error) {
+	policy := DefaultAlertSeverityPolicy()
+	for alertType, severity := range overrides {
+		if err := policy.Set(alertType, severity); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// Set changes the severity given to new alerts of a type
+func (p *AlertSeverityPolicy) Set(alertType, severity string) error {
+	if !validAlertSeverities[severity] {
+		return fmt.Errorf("invalid severity %q for alert type %s, expected low, medium, high or critical", severity, alertType)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.severities[alertType] = severity
+	return nil
+}
+
+// Replace resets the policy to the built-in severities with overrides applied.
+// The policy is left unchanged if any override is invalid.
+func (p *AlertSeverityPolicy) Replace(overrides map[string]string) error {
+	replacement, err := NewAlertSeverityPolicy(overrides)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.severities = replacement.severities
+	return nil
+}
+
+// SeverityFor returns the severity for a new alert of the given type. A nil
+// policy uses the built-in severities.
+func (p *AlertSeverityPolicy) SeverityFor(alertType string) string {
+	if p == nil {
+		if severity, ok := defaultAlertSeverities[alertType]; ok {
+			return severity
+		}
+		return fallbackAlertSeverity
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if severity, ok := p.severities[alertType]; ok {
+		return severity
+	}
+	return fallbackAlertSeverity
+}
+
+// AutoResolveRule reports whether an unresolved alert should be resolved given
+// the vehicle's current state
+type AutoResolveRule func(vehicle *models.Vehicle, alert *models.Alert) bool
+
+// AutoResolveRegistry maps alert types to the condition under which they resolve
+// themselves. Alert types without a registered rule stay open until resolved manually.
+type AutoResolveRegistry struct {
+	rules map[string]AutoResolveRule
+	mu    sync.RWMutex
+}
+
+// NewAutoResolveRegistry creates an empty rule registry
+func NewAutoResolveRegistry() *AutoResolveRegistry {
+	return &AutoResolveRegistry{
+		rules: make(map[string]AutoResolveRule),
+	}
+}
+
+// DefaultAutoResolveRegistry creates a registry with the built-in resolution rules
+func DefaultAutoResolveRegistry() *AutoResolveRegistry {
+	registry := NewAutoResolveRegistry()
+
+	// Low fuel resolves once the vehicle has been refuelled above the threshold
+	registry.Register("low_fuel", func(vehicle *models.Vehicle, alert *models.Alert) bool {
+		if vehicle.MaxFuelCapacity <= 0 {
+			return false
+		}
+		return fuelPercentage(vehicle) >= lowFuelThresholdPercent
+	})
+
+	// Speeding resolves once the vehicle is back within the limit
+	registry.Register("speeding", func(vehicle *models.Vehicle, alert *models.Alert) bool {
+		return vehicle.Speed <= vehicleSpeedLimit(vehicle).Kmh
+	})
+
+	// Geofence exits are instantaneous events and resolve on the next update
+	registry.Register("geofence_exit", func(vehicle *models.Vehicle, alert *models.Alert) bool {
+		return true
+	})
+
+	return registry
+}
+
+// Register sets the resolution rule for an alert type, replacing any existing rule
+func (r *AutoResolveRegistry) Register(alertType string, rule AutoResolveRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[alertType] = rule
+}
+
+// Unregister removes the resolution rule for an alert type
+func (r *AutoResolveRegistry) Unregister(alertType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, alertType)
+}
+
+// HasRule reports whether an alert type auto-resolves
+func (r *AutoResolveRegistry) HasRule(alertType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.rules[alertType]
+	return exists
+}
+
+// Apply evaluates the registered rules against the vehicle's unresolved alerts,
+// marks the matching alerts as resolved in place and returns them
+func (r *AutoResolveRegistry) Apply(vehicle *models.Vehicle, now time.Time) []models.Alert {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var resolved []models.Alert
+	for i := range vehicle.Alerts {
+		alert := &vehicle.Alerts[i]
+		if alert.Resolved {
+			continue
+		}
+
+		rule, exists := r.rules[alert.Type]
+		if !exists || !rule(vehicle, alert) {
+			continue
+		}
+
+		resolvedAt := now
+		alert.Resolved = true
+		alert.ResolvedAt = &resolvedAt
+		resolved = append(resolved, *alert)
+	}
+
+	return resolved
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestAlert(alertType string) models.Alert {
+	return models.Alert{
+		ID:        primitive.NewObjectID(),
+		Type:      alertType,
+		Timestamp: time.Now(),
+	}
+}
+
+func TestAutoResolveRegistry_LowFuelResolvesOnRefuel(t *testing.T) {
+	registry := DefaultAutoResolveRegistry()
+	vehicle := &models.Vehicle{
+		FuelLevel:       10,
+		MaxFuelCapacity: 100,
+		Alerts:          []models.Alert{newTestAlert("low_fuel")},
+	}
+
+	// Still below threshold, alert stays open
+	resolved := registry.Apply(vehicle, time.Now())
+	assert.Empty(t, resolved)
+	assert.False(t, vehicle.Alerts[0].Resolved)
+
+	// Refuelled above threshold
+	vehicle.FuelLevel = 60
+	now := time.Now()
+	resolved = registry.Apply(vehicle, now)
+
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "low_fuel", resolved[0].Type)
+	assert.True(t, vehicle.Alerts[0].Resolved)
+	require.NotNil(t, vehicle.Alerts[0].ResolvedAt)
+	assert.Equal(t, now, *vehicle.Alerts[0].ResolvedAt)
+}
+
+func TestAutoResolveRegistry_SpeedingResolvesOnSlowdown(t *testing.T) {
+	registry := DefaultAutoResolveRegistry()
+	vehicle := &models.Vehicle{
+		Speed:  95,
+		Alerts: []models.Alert{newTestAlert("speeding"), newTestAlert("fuel_theft")},
+	}
+
+	assert.Empty(t, registry.Apply(vehicle, time.Now()))
+
+	vehicle.Speed = 60
+	resolved := registry.Apply(vehicle, time.Now())
+
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "speeding", resolved[0].Type)
+	assert.True(t, vehicle.Alerts[0].Resolved)
+	// Alert types without a rule are left for manual resolution
+	assert.False(t, vehicle.Alerts[1].Resolved)
+}
+
+func TestAutoResolveRegistry_GeofenceExitIsInstantaneous(t *testing.T) {
+	registry := DefaultAutoResolveRegistry()
+	vehicle := &models.Vehicle{
+		Alerts: []models.Alert{newTestAlert("geofence_exit")},
+	}
+
+	resolved := registry.Apply(vehicle, time.Now())
+
+	require.Len(t, resolved, 1)
+	assert.True(t, vehicle.Alerts[0].Resolved)
+}
+
+func TestAutoResolveRegistry_SkipsAlreadyResolved(t *testing.T) {
+	registry := DefaultAutoResolveRegistry()
+	resolvedAt := time.Now().Add(-time.Hour)
+	alert := newTestAlert("geofence_exit")
+	alert.Resolved = true
+	alert.ResolvedAt = &resolvedAt
+	vehicle := &models.Vehicle{Alerts: []models.Alert{alert}}
+
+	assert.Empty(t, registry.Apply(vehicle, time.Now()))
+	assert.Equal(t, resolvedAt, *vehicle.Alerts[0].ResolvedAt)
+}
+
+func TestAutoResolveRegistry_CustomRule(t *testing.T) {
+	registry := NewAutoResolveRegistry()
+	registry.Register("unauthorized", func(vehicle *models.Vehicle, alert *models.Alert) bool {
+		return vehicle.Status == "idle"
+	})
+	assert.True(t, registry.HasRule("unauthorized"))
+
+	vehicle := &models.Vehicle{
+		Status: "active",
+		Alerts: []models.Alert{newTestAlert("unauthorized")},
+	}
+	assert.Empty(t, registry.Apply(vehicle, time.Now()))
+
+	vehicle.Status = "idle"
+	assert.Len(t, registry.Apply(vehicle, time.Now()), 1)
+
+	registry.Unregister("unauthorized")
+	assert.False(t, registry.HasRule("unauthorized"))
+}
+
+func TestVehicleService_AutoResolveAlerts(t *testing.T) {
+	service := &VehicleService{autoResolve: DefaultAutoResolveRegistry()}
+	vehicle := &models.Vehicle{
+		FuelLevel:       50,
+		MaxFuelCapacity: 100,
+		Speed:           40,
+		Alerts:          []models.Alert{newTestAlert("low_fuel"), newTestAlert("speeding"), newTestAlert("fuel_theft")},
+	}
+
+	service.autoResolveAlerts(context.Background(), vehicle)
+
+	// Resolved alerts leave the embedded alerts
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "fuel_theft", vehicle.Alerts[0].Type)
+	assert.False(t, vehicle.Alerts[0].Resolved)
+}
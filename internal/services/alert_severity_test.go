@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAlertSeverityPolicy_Defaults(t *testing.T) {
+	policy := DefaultAlertSeverityPolicy()
+
+	assert.Equal(t, "critical", policy.SeverityFor("fuel_theft"))
+	assert.Equal(t, "high", policy.SeverityFor("speeding"))
+	assert.Equal(t, "medium", policy.SeverityFor("low_fuel"))
+	assert.Equal(t, fallbackAlertSeverity, policy.SeverityFor("unknown_type"))
+
+	var nilPolicy *AlertSeverityPolicy
+	assert.Equal(t, "high", nilPolicy.SeverityFor("speeding"))
+}
+
+func TestAlertSeverityPolicy_RejectsInvalidSeverity(t *testing.T) {
+	policy := DefaultAlertSeverityPolicy()
+
+	assert.Error(t, policy.Set("speeding", "urgent"))
+	assert.Equal(t, "high", policy.SeverityFor("speeding"))
+
+	_, err := NewAlertSeverityPolicy(map[string]string{"low_fuel": "severe"})
+	assert.Error(t, err)
+}
+
+func TestAlertSeverityPolicy_AppliesToNewAlerts(t *testing.T) {
+	policy, err := NewAlertSeverityPolicy(map[string]string{"speeding": "critical"})
+	require.NoError(t, err)
+
+	service := &VehicleService{severityPolicy: policy}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Speed: speedLimitKmh + 20}
+
+	service.checkSpeeding(context.Background(), vehicle)
+
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "speeding", vehicle.Alerts[0].Type)
+	assert.Equal(t, "critical", vehicle.Alerts[0].Severity)
+
+	// Other types keep their defaults
+	vehicle = &models.Vehicle{ID: primitive.NewObjectID(), FuelLevel: 5, MaxFuelCapacity: 100}
+	service.checkLowFuel(context.Background(), vehicle)
+
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "medium", vehicle.Alerts[0].Severity)
+
+	// Changes apply to alerts created afterwards
+	require.NoError(t, policy.Set("low_fuel", "high"))
+	vehicle = &models.Vehicle{ID: primitive.NewObjectID(), FuelLevel: 5, MaxFuelCapacity: 100}
+	service.checkLowFuel(context.Background(), vehicle)
+
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "high", vehicle.Alerts[0].Severity)
+}
@@ -61,7 +61,7 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 	s.userRepo.Update(user.ID.Hex(), user)
 
 	// Generate JWT token
-	token, err := s.jwtUtil.GenerateToken(user.ID.Hex(), user.Email, user.Role)
+	token, err := s.jwtUtil.GenerateTenantToken(user.ID.Hex(), user.Email, user.Role, user.TenantID)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
@@ -96,7 +96,7 @@ func (s *AuthService) RefreshToken(userID string) (string, error) {
 	}
 
 	// Generate new token
-	token, err := s.jwtUtil.GenerateToken(user.ID.Hex(), user.Email, user.Role)
+	token, err := s.jwtUtil.GenerateTenantToken(user.ID.Hex(), user.Email, user.Role, user.TenantID)
 	if err != nil {
 		return "", errors.New("failed to generate token")
 	}
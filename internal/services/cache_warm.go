@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/logger"
+	"fmt"
+)
+
+// ErrCacheDisabled is returned when warming the cache without a cache manager
+var ErrCacheDisabled = errors.New("vehicle cache is not enabled")
+
+// cacheWarmSource is the subset of the vehicle repository used to warm the cache
+type cacheWarmSource interface {
+	FindAll(ctx context.Context) ([]*models.Vehicle, error)
+	FindByDriver(ctx context.Context, driver string) ([]*models.Vehicle, error)
+}
+
+// WarmCache loads every vehicle, the full vehicle list and the per-status and
+// per-driver lists into the cache, using the configured TTLs, so requests
+// after a deploy don't all fall through to the database. It returns the
+// number of cache entries populated and can be called at any time.
+func (s *VehicleService) WarmCache(ctx context.Context) (int, error) {
+	return s.warmCache(ctx, s.vehicleRepo)
+}
+
+func (s *VehicleService) warmCache(ctx context.Context, source cacheWarmSource) (int, error) {
+	if s.cacheManager == nil {
+		return 0, ErrCacheDisabled
+	}
+
+	vehicles, err := source.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	populated := 0
+	failed := 0
+	count := func(err error) {
+		if err != nil {
+			failed++
+			return
+		}
+		populated++
+	}
+
+	vehicleTTL := s.cacheTTL("vehicle")
+	listTTL := s.cacheTTL("vehicle_list")
+
+	byStatus := make(map[string][]*models.Vehicle)
+	drivers := make(map[string]bool)
+	for _, vehicle := range vehicles {
+		count(s.cacheManager.SetVehicle(vehicle.ID.Hex(), vehicle, vehicleTTL))
+		byStatus[vehicle.Status] = append(byStatus[vehicle.Status], vehicle)
+		if vehicle.Driver != "" {
+			drivers[vehicle.Driver] = true
+		}
+	}
+	count(s.cacheManager.SetVehicleList("all_vehicles", vehicles, listTTL))
+
+	// FindAll and FindByStatus both leave out archived vehicles, so the status
+	// lists can be built from the full list
+	for status := range vehicleStatuses {
+		list := byStatus[status]
+		if list == nil {
+			list = []*models.Vehicle{}
+		}
+		count(s.cacheManager.SetVehicleList(fmt.Sprintf("vehicles_by_status_%s", status), list, listTTL))
+	}
+
+	// Driver lists include archived vehicles, so they are loaded as GetVehiclesByDriver would
+	for driver := range drivers {
+		list, err := source.FindByDriver(ctx, driver)
+		if err != nil {
+			failed++
+			continue
+		}
+		count(s.cacheManager.SetVehicleList(fmt.Sprintf("vehicles_by_driver_%s", driver), list, listTTL))
+	}
+
+	if failed > 0 {
+		logger.FromContext(ctx).Warn("Cache warming failed to populate entries", "failed", failed)
+	}
+	return populated, nil
+}
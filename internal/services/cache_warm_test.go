@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeWarmSource serves a fixed fleet; archived vehicles only appear in driver lists
+type fakeWarmSource struct {
+	vehicles []*models.Vehicle
+	archived []*models.Vehicle
+}
+
+func (f *fakeWarmSource) FindAll(_ context.Context) ([]*models.Vehicle, error) {
+	return f.vehicles, nil
+}
+
+func (f *fakeWarmSource) FindByDriver(_ context.Context, driver string) ([]*models.Vehicle, error) {
+	var found []*models.Vehicle
+	for _, vehicle := range append(append([]*models.Vehicle{}, f.vehicles...), f.archived...) {
+		if vehicle.Driver == driver {
+			found = append(found, vehicle)
+		}
+	}
+	return found, nil
+}
+
+func TestWarmCache_LaterReadsAreCacheHits(t *testing.T) {
+	source := &fakeWarmSource{
+		vehicles: []*models.Vehicle{
+			{ID: primitive.NewObjectID(), Name: "Truck 1", Status: "active", Driver: "alice"},
+			{ID: primitive.NewObjectID(), Name: "Truck 2", Status: "idle", Driver: "bob"},
+		},
+		archived: []*models.Vehicle{
+			{ID: primitive.NewObjectID(), Name: "Old Truck", Status: "offline", Driver: "alice"},
+		},
+	}
+	memoryCache := cache.NewMemoryCacheManager(cache.DefaultCacheConfig(), 100)
+	// No repository: any cache miss would fall through and panic
+	service := &VehicleService{cacheManager: memoryCache, cacheConfig: cache.DefaultCacheConfig()}
+
+	populated, err := service.warmCache(context.Background(), source)
+	require.NoError(t, err)
+	// 2 vehicles, the full list, 4 status lists and 2 driver lists
+	assert.Equal(t, 9, populated)
+
+	hitsBefore := memoryCache.GetCacheStats().TotalHits
+	vehicle, err := service.GetVehicleByID(context.Background(), source.vehicles[0].ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "Truck 1", vehicle.Name)
+	assert.Equal(t, hitsBefore+1, memoryCache.GetCacheStats().TotalHits)
+
+	all, err := service.GetAllVehicles(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	idle, err := service.GetVehiclesByStatus(context.Background(), "idle")
+	require.NoError(t, err)
+	require.Len(t, idle, 1)
+	assert.Equal(t, "Truck 2", idle[0].Name)
+
+	maintenance, err := service.GetVehiclesByStatus(context.Background(), "maintenance")
+	require.NoError(t, err)
+	assert.Empty(t, maintenance)
+
+	alice, err := service.GetVehiclesByDriver(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Len(t, alice, 2, "driver lists match GetVehiclesByDriver, archived vehicles included")
+
+	assert.Equal(t, hitsBefore+5, memoryCache.GetCacheStats().TotalHits)
+}
+
+func TestWarmCache_RequiresCache(t *testing.T) {
+	service := &VehicleService{}
+	_, err := service.warmCache(context.Background(), &fakeWarmSource{})
+	assert.ErrorIs(t, err, ErrCacheDisabled)
+}
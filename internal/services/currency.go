@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrExchangeRateNotFound is returned when no rate between two currencies
+	// was in effect at the requested time
+	ErrExchangeRateNotFound = newDomainError(ErrNotFound, "exchange rate not found")
+	// ErrInvalidExchangeRate is returned for a rate that isn't positive or
+	// doesn't name two different currencies
+	ErrInvalidExchangeRate = newDomainError(ErrValidation, "exchange rate must be positive and convert between two different currencies")
+)
+
+// DefaultReportingCurrency is the currency costs are reported in when none is configured
+const DefaultReportingCurrency = "USD"
+
+// exchangeRateStore is the subset of the exchange rate repository used by the converter
+type exchangeRateStore interface {
+	Create(ctx context.Context, rate *models.ExchangeRate) error
+	FindEffective(ctx context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error)
+}
+
+// CreateExchangeRateRequest adds the rate from one currency to another
+type CreateExchangeRateRequest struct {
+	BaseCurrency  string  `json:"baseCurrency" validate:"required,len=3"`
+	QuoteCurrency string  `json:"quoteCurrency" validate:"required,len=3"`
+	Rate          float64 `json:"rate" validate:"gt=0"`
+	// EffectiveAt defaults to now
+	EffectiveAt *time.Time `json:"effectiveAt,omitempty"`
+}
+
+// CurrencyConverter converts maintenance costs into the reporting currency
+// using stored exchange rates. The rate in effect when a service was
+// performed is recorded on its maintenance record, so historical costs don't
+// move when rates change.
+type CurrencyConverter struct {
+	rates             exchangeRateStore
+	reportingCurrency string
+	now               func() time.Time
+}
+
+// NewCurrencyConverter creates a converter into reportingCurrency, or
+// DefaultReportingCurrency if it's empty
+func NewCurrencyConverter(repo *repository.ExchangeRateRepository, reportingCurrency string) *CurrencyConverter {
+	return newCurrencyConverter(repo, reportingCurrency)
+}
+
+func newCurrencyConverter(rates exchangeRateStore, reportingCurrency string) *CurrencyConverter {
+	reportingCurrency = normalizeCurrency(reportingCurrency)
+	if reportingCurrency == "" {
+		reportingCurrency = DefaultReportingCurrency
+	}
+	return &CurrencyConverter{
+		rates:             rates,
+		reportingCurrency: reportingCurrency,
+		now:               time.Now,
+	}
+}
+
+// ReportingCurrency returns the currency costs are converted into
+func (c *CurrencyConverter) ReportingCurrency() string {
+	return c.reportingCurrency
+}
+
+// AddRate stores a rate, effective now unless the request says otherwise
+func (c *CurrencyConverter) AddRate(ctx context.Context, req *CreateExchangeRateRequest) (*models.ExchangeRate, error) {
+	rate := &models.ExchangeRate{
+		BaseCurrency:  normalizeCurrency(req.BaseCurrency),
+		QuoteCurrency: normalizeCurrency(req.QuoteCurrency),
+		Rate:          req.Rate,
+		EffectiveAt:   c.now(),
+	}
+	if req.EffectiveAt != nil {
+		rate.EffectiveAt = *req.EffectiveAt
+	}
+	if rate.Rate <= 0 || rate.BaseCurrency == "" || rate.QuoteCurrency == "" || rate.BaseCurrency == rate.QuoteCurrency {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	if err := c.rates.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// RateAt returns the rate from one currency to another in effect at at. When
+// only the opposite pair was stored, its inverse is used.
+func (c *CurrencyConverter) RateAt(ctx context.Context, from, to string, at time.Time) (*models.RecordedExchangeRate, error) {
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == to {
+		return &models.RecordedExchangeRate{Currency: to, Rate: 1, EffectiveAt: at}, nil
+	}
+
+	rate, err := c.rates.FindEffective(ctx, from, to, at)
+	if err == nil {
+		return &models.RecordedExchangeRate{Currency: to, Rate: rate.Rate, EffectiveAt: rate.EffectiveAt}, nil
+	}
+	if !errors.Is(err, repository.ErrExchangeRateNotFound) {
+		return nil, err
+	}
+
+	inverse, err := c.rates.FindEffective(ctx, to, from, at)
+	if err != nil {
+		if errors.Is(err, repository.ErrExchangeRateNotFound) {
+			return nil, fmt.Errorf("%w: no rate from %s to %s at %s", ErrExchangeRateNotFound, from, to, at.Format(time.RFC3339))
+		}
+		return nil, err
+	}
+	return &models.RecordedExchangeRate{Currency: to, Rate: 1 / inverse.Rate, EffectiveAt: inverse.EffectiveAt}, nil
+}
+
+// RecordRate sets the record's exchange rate to the one into the reporting
+// currency in effect when the service was performed. Records already in the
+// reporting currency need none.
+func (c *CurrencyConverter) RecordRate(ctx context.Context, record *models.MaintenanceRecord) error {
+	record.ExchangeRate = nil
+	if normalizeCurrency(record.Currency) == c.reportingCurrency {
+		return nil
+	}
+
+	rate, err := c.RateAt(ctx, record.Currency, c.reportingCurrency, record.PerformedAt)
+	if err != nil {
+		return err
+	}
+	record.ExchangeRate = rate
+	return nil
+}
+
+// normalizeCurrency turns a currency code into its upper-case ISO 4217 form
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// SetCurrencyConverter sets the converter that records the exchange rate of
+// each maintenance record; without one records keep only their own currency
+func (s *MaintenanceService) SetCurrencyConverter(converter *CurrencyConverter) {
+	s.converter = converter
+}
+
+// recordExchangeRate records the rate of the record's currency on it. A
+// missing rate doesn't stop the record being stored: it's kept without one,
+// in its own currency, until a rate is added and the record updated.
+func (s *MaintenanceService) recordExchangeRate(ctx context.Context, record *models.MaintenanceRecord) error {
+	if s.converter == nil {
+		return nil
+	}
+	err := s.converter.RecordRate(ctx, record)
+	if errors.Is(err, ErrExchangeRateNotFound) {
+		logger.FromContext(ctx).Warn("Storing maintenance record without exchange rate", "error", err)
+		return nil
+	}
+	return err
+}
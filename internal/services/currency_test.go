@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRateStore keeps exchange rates in memory
+type memoryRateStore struct {
+	rates []*models.ExchangeRate
+}
+
+func (m *memoryRateStore) Create(_ context.Context, rate *models.ExchangeRate) error {
+	m.rates = append(m.rates, rate)
+	return nil
+}
+
+func (m *memoryRateStore) FindEffective(_ context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error) {
+	var effective *models.ExchangeRate
+	for _, rate := range m.rates {
+		if rate.BaseCurrency != base || rate.QuoteCurrency != quote || rate.EffectiveAt.After(at) {
+			continue
+		}
+		if effective == nil || rate.EffectiveAt.After(effective.EffectiveAt) {
+			effective = rate
+		}
+	}
+	if effective == nil {
+		return nil, repository.ErrExchangeRateNotFound
+	}
+	return effective, nil
+}
+
+func addRate(t *testing.T, converter *CurrencyConverter, base, quote string, rate float64, effectiveAt time.Time) {
+	t.Helper()
+	_, err := converter.AddRate(context.Background(), &CreateExchangeRateRequest{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate,
+		EffectiveAt:   &effectiveAt,
+	})
+	require.NoError(t, err)
+}
+
+func TestCurrencyConverter_ConvertsWithHistoricalRate(t *testing.T) {
+	converter := newCurrencyConverter(&memoryRateStore{}, "usd")
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addRate(t, converter, "EUR", "USD", 1.10, january)
+
+	record := &models.MaintenanceRecord{Cost: 200, Currency: "eur", PerformedAt: january.AddDate(0, 2, 0)}
+	require.NoError(t, converter.RecordRate(context.Background(), record))
+	require.NotNil(t, record.ExchangeRate)
+	assert.Equal(t, "USD", record.ExchangeRate.Currency)
+	assert.Equal(t, january, record.ExchangeRate.EffectiveAt)
+
+	// A later rate doesn't change what the service cost
+	addRate(t, converter, "EUR", "USD", 1.30, january.AddDate(0, 6, 0))
+	assert.InDelta(t, 220.0, record.Cost*record.ExchangeRate.Rate, 1e-9)
+
+	current, err := converter.RateAt(context.Background(), "EUR", "USD", january.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 1.30, current.Rate)
+}
+
+func TestCurrencyConverter_UsesInverseOfOppositePair(t *testing.T) {
+	converter := newCurrencyConverter(&memoryRateStore{}, "USD")
+	addRate(t, converter, "USD", "KES", 125, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	record := &models.MaintenanceRecord{Cost: 25000, Currency: "KES", PerformedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, converter.RecordRate(context.Background(), record))
+
+	assert.InDelta(t, 200.0, record.Cost*record.ExchangeRate.Rate, 1e-9)
+}
+
+func TestCurrencyConverter_RequiresRateInEffectAtService(t *testing.T) {
+	converter := newCurrencyConverter(&memoryRateStore{}, "")
+	addRate(t, converter, "EUR", "USD", 1.10, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	// The only rate took effect after the service
+	record := &models.MaintenanceRecord{Cost: 100, Currency: "EUR", PerformedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	err := converter.RecordRate(context.Background(), record)
+	assert.ErrorIs(t, err, ErrExchangeRateNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Records in the reporting currency need no rate
+	record = &models.MaintenanceRecord{Cost: 100, Currency: "USD"}
+	require.NoError(t, converter.RecordRate(context.Background(), record))
+	assert.Nil(t, record.ExchangeRate)
+}
+
+func TestMaintenanceService_StoresRecordWithoutMissingRate(t *testing.T) {
+	service := &MaintenanceService{}
+	service.SetCurrencyConverter(newCurrencyConverter(&memoryRateStore{}, "USD"))
+
+	record := &models.MaintenanceRecord{Cost: 100, Currency: "EUR", PerformedAt: time.Now()}
+	require.NoError(t, service.recordExchangeRate(context.Background(), record))
+	assert.Nil(t, record.ExchangeRate)
+}
+
+func TestCurrencyConverter_RejectsInvalidRates(t *testing.T) {
+	converter := newCurrencyConverter(&memoryRateStore{}, "USD")
+
+	for _, req := range []*CreateExchangeRateRequest{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 0},
+		{BaseCurrency: "EUR", QuoteCurrency: "eur", Rate: 1},
+	} {
+		_, err := converter.AddRate(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidExchangeRate)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"sort"
+)
+
+// ErrDuplicatePlates is returned when vehicles share a plate number, so the
+// unique plate number index can't be created
+var ErrDuplicatePlates = errors.New("vehicles share plate numbers")
+
+// duplicatePlateSource is the subset of the vehicle repository used to find duplicate plates
+type duplicatePlateSource interface {
+	FindAllIncludingArchived(ctx context.Context) ([]*models.Vehicle, error)
+}
+
+// vehicleIndexStore is the subset of the vehicle repository used to create indexes
+type vehicleIndexStore interface {
+	duplicatePlateSource
+	CreateIndexes(ctx context.Context) error
+	CreatePlateIndex(ctx context.Context) error
+	CreateVINIndex(ctx context.Context) error
+}
+
+// FindDuplicatePlates returns the plate numbers shared by more than one
+// vehicle, archived vehicles included, sorted by plate number
+func (s *VehicleService) FindDuplicatePlates(ctx context.Context) ([]models.DuplicatePlate, error) {
+	return findDuplicatePlates(ctx, s.vehicleRepo)
+}
+
+// EnsureIndexes creates the vehicle indexes. The unique plate number index is
+// only created when no vehicles share a plate number; otherwise the duplicates
+// are returned with ErrDuplicatePlates so they can be cleaned up first. A
+// unique VIN index that can't be created over existing data is only logged.
+func (s *VehicleService) EnsureIndexes(ctx context.Context) ([]models.DuplicatePlate, error) {
+	return ensureVehicleIndexes(ctx, s.vehicleRepo)
+}
+
+func ensureVehicleIndexes(ctx context.Context, store vehicleIndexStore) ([]models.DuplicatePlate, error) {
+	if err := store.CreateIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	// VINs stored before validation may repeat, which mustn't hold up the plate index
+	if err := store.CreateVINIndex(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Unique VIN index not created", "error", err)
+	}
+
+	// Check first, since creating a unique index over duplicates fails without
+	// saying which documents are in the way
+	duplicates, err := findDuplicatePlates(ctx, store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate plate numbers: %w", err)
+	}
+	if len(duplicates) > 0 {
+		return duplicates, fmt.Errorf("%w: %d plate numbers are used more than once", ErrDuplicatePlates, len(duplicates))
+	}
+
+	return nil, store.CreatePlateIndex(ctx)
+}
+
+func findDuplicatePlates(ctx context.Context, source duplicatePlateSource) ([]models.DuplicatePlate, error) {
+	vehicles, err := source.FindAllIncludingArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byPlate := make(map[string][]*models.Vehicle)
+	for _, vehicle := range vehicles {
+		byPlate[vehicle.PlateNumber] = append(byPlate[vehicle.PlateNumber], vehicle)
+	}
+
+	duplicates := []models.DuplicatePlate{}
+	for plate, sharing := range byPlate {
+		if len(sharing) > 1 {
+			duplicates = append(duplicates, models.DuplicatePlate{PlateNumber: plate, Vehicles: sharing})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].PlateNumber < duplicates[j].PlateNumber })
+
+	return duplicates, nil
+}
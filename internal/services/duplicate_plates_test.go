@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryVehicleIndexStore fails to create the plate index over duplicates, as Mongo does
+type memoryVehicleIndexStore struct {
+	vehicles       []*models.Vehicle
+	indexesCreated bool
+	plateIndexed   bool
+	vinIndexed     bool
+}
+
+func (m *memoryVehicleIndexStore) FindAllIncludingArchived(_ context.Context) ([]*models.Vehicle, error) {
+	return m.vehicles, nil
+}
+
+func (m *memoryVehicleIndexStore) CreateIndexes(_ context.Context) error {
+	m.indexesCreated = true
+	return nil
+}
+
+func (m *memoryVehicleIndexStore) CreatePlateIndex(_ context.Context) error {
+	seen := make(map[string]bool)
+	for _, vehicle := range m.vehicles {
+		if seen[vehicle.PlateNumber] {
+			return errors.New("E11000 duplicate key error")
+		}
+		seen[vehicle.PlateNumber] = true
+	}
+	m.plateIndexed = true
+	return nil
+}
+
+func (m *memoryVehicleIndexStore) CreateVINIndex(_ context.Context) error {
+	m.vinIndexed = true
+	return nil
+}
+
+func plateVehicle(name, plate string) *models.Vehicle {
+	return &models.Vehicle{ID: primitive.NewObjectID(), Name: name, PlateNumber: plate}
+}
+
+func TestFindDuplicatePlates_DetectsSharedPlates(t *testing.T) {
+	archivedAt := time.Now()
+	archived := plateVehicle("Old Truck", "KAA 001A")
+	archived.DeletedAt = &archivedAt
+	store := &memoryVehicleIndexStore{vehicles: []*models.Vehicle{
+		plateVehicle("Truck 1", "KAA 001A"),
+		plateVehicle("Truck 2", "KBB 002B"),
+		plateVehicle("Van 1", "KCC 003C"),
+		plateVehicle("Van 2", "KCC 003C"),
+		archived,
+	}}
+
+	duplicates, err := findDuplicatePlates(context.Background(), store)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 2)
+
+	// Archived vehicles count, since the index covers them too
+	assert.Equal(t, "KAA 001A", duplicates[0].PlateNumber)
+	assert.Len(t, duplicates[0].Vehicles, 2)
+	assert.Contains(t, duplicates[0].Vehicles, archived)
+	assert.Equal(t, "KCC 003C", duplicates[1].PlateNumber)
+	assert.Len(t, duplicates[1].Vehicles, 2)
+}
+
+func TestFindDuplicatePlates_NoneReturnsEmpty(t *testing.T) {
+	store := &memoryVehicleIndexStore{vehicles: []*models.Vehicle{
+		plateVehicle("Truck 1", "KAA 001A"),
+		plateVehicle("Truck 2", "KBB 002B"),
+	}}
+
+	duplicates, err := findDuplicatePlates(context.Background(), store)
+	require.NoError(t, err)
+	assert.NotNil(t, duplicates)
+	assert.Empty(t, duplicates)
+}
+
+func TestEnsureVehicleIndexes_SkipsPlateIndexOverDuplicates(t *testing.T) {
+	store := &memoryVehicleIndexStore{vehicles: []*models.Vehicle{
+		plateVehicle("Van 1", "KCC 003C"),
+		plateVehicle("Van 2", "KCC 003C"),
+	}}
+
+	duplicates, err := ensureVehicleIndexes(context.Background(), store)
+	assert.ErrorIs(t, err, ErrDuplicatePlates)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "KCC 003C", duplicates[0].PlateNumber)
+	assert.True(t, store.indexesCreated, "the other indexes are still created")
+	assert.True(t, store.vinIndexed)
+	assert.False(t, store.plateIndexed)
+
+	// Once the data is cleaned up the unique index is created
+	store.vehicles[1].PlateNumber = "KCC 004C"
+	duplicates, err = ensureVehicleIndexes(context.Background(), store)
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+	assert.True(t, store.plateIndexed)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"fleet-backend/internal/repository"
+)
+
+// Kinds of domain errors. Specific errors belong to one of them, so callers
+// can tell a missing resource or a rejected request from a failure without
+// knowing every error a service returns.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+)
+
+var (
+	// ErrVehicleNotFound is returned when no vehicle has the given ID
+	ErrVehicleNotFound = newDomainError(ErrNotFound, "vehicle not found")
+	// ErrDuplicatePlate is returned when another vehicle already has the plate number
+	ErrDuplicatePlate = newDomainError(ErrConflict, "plate number already exists")
+	// ErrVehicleArchived is returned when archiving a vehicle that already is
+	ErrVehicleArchived = newDomainError(ErrConflict, "vehicle is already archived")
+	// ErrVehicleNotArchived is returned when restoring a vehicle that isn't archived
+	ErrVehicleNotArchived = newDomainError(ErrConflict, "vehicle is not archived")
+	// ErrMaintenanceRecordNotFound is returned when no maintenance record has the given ID
+	ErrMaintenanceRecordNotFound = newDomainError(ErrNotFound, "maintenance record not found")
+)
+
+// domainError is a specific error that also matches its kind with errors.Is
+type domainError struct {
+	kind    error
+	message string
+}
+
+func newDomainError(kind error, message string) error {
+	return &domainError{kind: kind, message: message}
+}
+
+func (e *domainError) Error() string { return e.message }
+
+func (e *domainError) Unwrap() error { return e.kind }
+
+// vehicleLookupError turns a vehicle the repository couldn't find, or an ID
+// that can't name one, into ErrVehicleNotFound. Other failures pass through.
+func vehicleLookupError(err error) error {
+	if errors.Is(err, repository.ErrVehicleNotFound) || errors.Is(err, repository.ErrInvalidVehicleID) {
+		return ErrVehicleNotFound
+	}
+	return err
+}
+
+// maintenanceRecordLookupError turns a maintenance record the repository
+// couldn't find, or an ID that can't name one, into
+// ErrMaintenanceRecordNotFound. Other failures pass through.
+func maintenanceRecordLookupError(err error) error {
+	if errors.Is(err, repository.ErrMaintenanceRecordNotFound) || errors.Is(err, repository.ErrInvalidMaintenanceRecordID) {
+		return ErrMaintenanceRecordNotFound
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/geo"
+	"fleet-backend/pkg/routing"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidDestination is returned when an ETA is requested for coordinates outside WGS84 bounds
+var ErrInvalidDestination = errors.New("destination coordinates are out of range")
+
+const (
+	// etaSpeedWindow is how far back speed readings are averaged for an ETA
+	etaSpeedWindow = 30 * time.Minute
+	// etaMinMovingSpeedKmh is the average speed below which a vehicle counts
+	// as stationary and gets no ETA
+	etaMinMovingSpeedKmh = 5.0
+)
+
+// SetRoutingProvider has ETAs use road distance and driving time from provider.
+// Without one, or when it fails, ETAs fall back to the straight-line estimate.
+func (s *VehicleService) SetRoutingProvider(provider routing.Provider) {
+	s.routes = provider
+}
+
+// EstimateArrival estimates when a vehicle reaches destination. With a
+// routing provider the road route's driving time is used; otherwise the
+// straight-line distance and the vehicle's recent average speed give an
+// estimate labeled approximate.
+func (s *VehicleService) EstimateArrival(ctx context.Context, id string, destination models.Location) (*models.ETAEstimate, error) {
+	if destination.Lat < -90 || destination.Lat > 90 || destination.Lng < -180 || destination.Lng > 180 {
+		return nil, ErrInvalidDestination
+	}
+
+	vehicle, err := s.GetVehicleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.estimateArrival(vehicle, destination, time.Now()), nil
+}
+
+func (s *VehicleService) estimateArrival(vehicle *models.Vehicle, destination models.Location, now time.Time) *models.ETAEstimate {
+	origin := vehicle.Location
+	from := geo.Point{Lat: origin.Lat, Lng: origin.Lng}
+	to := geo.Point{Lat: destination.Lat, Lng: destination.Lng}
+
+	estimate := &models.ETAEstimate{
+		VehicleID:       vehicle.ID.Hex(),
+		Origin:          origin,
+		Destination:     destination,
+		DistanceKm:      geo.DistanceMeters(from, to) / 1000,
+		AverageSpeedKmh: s.recentAverageSpeed(vehicle, now),
+		Method:          models.ETAMethodStraightLine,
+		Approximate:     true,
+		ComputedAt:      now,
+	}
+
+	var route *routing.Route
+	routingFailed := false
+	if s.routes != nil {
+		var err error
+		route, err = s.routes.Route(from, to)
+		if err != nil {
+			fmt.Printf("Routing provider %s failed for vehicle %s, using straight-line ETA: %v\n", s.routes.Name(), estimate.VehicleID, err)
+			routingFailed = true
+		} else {
+			estimate.DistanceKm = route.DistanceMeters / 1000
+			estimate.Method = models.ETAMethodRoad
+			estimate.Provider = s.routes.Name()
+			estimate.Approximate = false
+		}
+	}
+
+	// A parked vehicle's recent readings may still average above the threshold
+	parked := vehicle.Status == "idle" || vehicle.Status == "maintenance" || vehicle.Status == "offline"
+	if parked || estimate.AverageSpeedKmh < etaMinMovingSpeedKmh {
+		estimate.Note = "Vehicle is stationary, so no arrival time can be estimated"
+		return estimate
+	}
+
+	estimate.Moving = true
+	var seconds float64
+	if route != nil {
+		seconds = route.DurationSeconds
+		estimate.Note = "Driving time along the road route, without live traffic"
+	} else {
+		seconds = estimate.DistanceKm / estimate.AverageSpeedKmh * 3600
+		estimate.Note = "Approximation from straight-line distance and recent average speed; actual road distance is longer"
+		if routingFailed {
+			estimate.Note = "Routing provider unavailable. " + estimate.Note
+		}
+	}
+	arrival := now.Add(time.Duration(seconds * float64(time.Second)))
+	estimate.DurationSeconds = &seconds
+	estimate.EstimatedArrival = &arrival
+	return estimate
+}
+
+// recentAverageSpeed averages the vehicle's speed readings over the ETA
+// window, falling back to its last reported speed without any. The readings
+// held in memory are used when there are any, sparing a query per ETA.
+func (s *VehicleService) recentAverageSpeed(vehicle *models.Vehicle, now time.Time) float64 {
+	if s.speedHistory != nil {
+		from := now.Add(-etaSpeedWindow)
+		samples := s.speedHistory.RecentSamples(vehicle.ID.Hex(), from, now)
+		var err error
+		if len(samples) == 0 {
+			samples, err = s.speedHistory.Samples(vehicle.ID.Hex(), from, now)
+		}
+		if err == nil && len(samples) > 0 {
+			noLimit := func(*models.SpeedSample) int { return 0 }
+			return aggregateSpeedStats(samples, noLimit, speedSampleMaxGap).AverageKmh
+		}
+	}
+	return float64(vehicle.Speed)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/geo"
+	"fleet-backend/pkg/routing"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEstimateArrival_MovingVehicle(t *testing.T) {
+	history := newSpeedHistory(&memorySpeedStore{})
+	service := &VehicleService{speedHistory: history}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Speed: 90, Location: models.Location{Lat: 0, Lng: 0}}
+
+	now := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	for minute := 20; minute >= 0; minute-- {
+		history.RecordSpeed(vehicle.ID.Hex(), 60, nil, now.Add(-time.Duration(minute)*time.Minute))
+	}
+
+	// One degree of longitude along the equator is about 111.19 km
+	estimate := service.estimateArrival(vehicle, models.Location{Lat: 0, Lng: 1}, now)
+
+	assert.True(t, estimate.Moving)
+	assert.True(t, estimate.Approximate)
+	assert.Equal(t, models.ETAMethodStraightLine, estimate.Method)
+	assert.InDelta(t, 111.19, estimate.DistanceKm, 0.01)
+	assert.InDelta(t, 60, estimate.AverageSpeedKmh, 0.001, "recent readings are used, not the last reported speed")
+	require.NotNil(t, estimate.DurationSeconds)
+	assert.InDelta(t, 111.19/60*3600, *estimate.DurationSeconds, 1)
+	require.NotNil(t, estimate.EstimatedArrival)
+	assert.WithinDuration(t, now.Add(111*time.Minute+11*time.Second), *estimate.EstimatedArrival, 2*time.Second)
+}
+
+func TestEstimateArrival_StationaryVehicle(t *testing.T) {
+	history := newSpeedHistory(&memorySpeedStore{})
+	service := &VehicleService{speedHistory: history}
+	now := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	destination := models.Location{Lat: -1.3, Lng: 36.9}
+
+	stopped := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Location: models.Location{Lat: -1.29, Lng: 36.82}}
+	for minute := 10; minute >= 0; minute-- {
+		history.RecordSpeed(stopped.ID.Hex(), 0, nil, now.Add(-time.Duration(minute)*time.Minute))
+	}
+	estimate := service.estimateArrival(stopped, destination, now)
+	assert.False(t, estimate.Moving)
+	assert.Nil(t, estimate.DurationSeconds)
+	assert.Nil(t, estimate.EstimatedArrival)
+	assert.Greater(t, estimate.DistanceKm, 0.0)
+	assert.NotEmpty(t, estimate.Note)
+
+	// A vehicle that just parked has no ETA even though it was moving recently
+	parked := &models.Vehicle{ID: primitive.NewObjectID(), Status: "idle", Location: models.Location{Lat: -1.29, Lng: 36.82}}
+	for minute := 10; minute >= 1; minute-- {
+		history.RecordSpeed(parked.ID.Hex(), 50, nil, now.Add(-time.Duration(minute)*time.Minute))
+	}
+	estimate = service.estimateArrival(parked, destination, now)
+	assert.False(t, estimate.Moving)
+	assert.Nil(t, estimate.EstimatedArrival)
+}
+
+func TestEstimateArrival_FallsBackToReportedSpeed(t *testing.T) {
+	service := &VehicleService{}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Speed: 40}
+
+	estimate := service.estimateArrival(vehicle, models.Location{Lat: 0, Lng: 0.5}, time.Now())
+
+	assert.True(t, estimate.Moving)
+	assert.Equal(t, 40.0, estimate.AverageSpeedKmh)
+}
+
+func TestEstimateArrival_InvalidDestination(t *testing.T) {
+	service := &VehicleService{}
+
+	_, err := service.EstimateArrival(context.Background(), primitive.NewObjectID().Hex(), models.Location{Lat: 91, Lng: 0})
+	assert.ErrorIs(t, err, ErrInvalidDestination)
+}
+
+type stubRoutingProvider struct {
+	route *routing.Route
+	err   error
+}
+
+func (p *stubRoutingProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubRoutingProvider) Route(origin, destination geo.Point) (*routing.Route, error) {
+	return p.route, p.err
+}
+
+func TestEstimateArrival_UsesRoutingProvider(t *testing.T) {
+	service := &VehicleService{}
+	service.SetRoutingProvider(&stubRoutingProvider{route: &routing.Route{DistanceMeters: 150000, DurationSeconds: 7200}})
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Speed: 60}
+	now := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	estimate := service.estimateArrival(vehicle, models.Location{Lat: 0, Lng: 1}, now)
+
+	assert.Equal(t, models.ETAMethodRoad, estimate.Method)
+	assert.Equal(t, "stub", estimate.Provider)
+	assert.False(t, estimate.Approximate)
+	assert.Equal(t, 150.0, estimate.DistanceKm)
+	require.NotNil(t, estimate.DurationSeconds)
+	assert.Equal(t, 7200.0, *estimate.DurationSeconds, "the route's driving time is used, not the average speed")
+	require.NotNil(t, estimate.EstimatedArrival)
+	assert.Equal(t, now.Add(2*time.Hour), *estimate.EstimatedArrival)
+}
+
+func TestEstimateArrival_FallsBackWhenRoutingFails(t *testing.T) {
+	service := &VehicleService{}
+	service.SetRoutingProvider(&stubRoutingProvider{err: errors.New("provider timeout")})
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Speed: 60}
+
+	estimate := service.estimateArrival(vehicle, models.Location{Lat: 0, Lng: 1}, time.Now())
+
+	assert.Equal(t, models.ETAMethodStraightLine, estimate.Method)
+	assert.Empty(t, estimate.Provider)
+	assert.True(t, estimate.Approximate)
+	assert.InDelta(t, 111.19, estimate.DistanceKm, 0.01)
+	require.NotNil(t, estimate.DurationSeconds)
+	assert.InDelta(t, 111.19/60*3600, *estimate.DurationSeconds, 1)
+	assert.Contains(t, estimate.Note, "Routing provider unavailable")
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Supported export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// exportFlushInterval is the number of rows written between flushes to the client
+const exportFlushInterval = 100
+
+// ErrUnsupportedExportFormat is returned for formats other than csv and json
+var ErrUnsupportedExportFormat = errors.New("unsupported export format, expected csv or json")
+
+// ValidateExportFormat checks that an export format is supported
+func ValidateExportFormat(format string) error {
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return ErrUnsupportedExportFormat
+	}
+	return nil
+}
+
+// ExportContentType returns the Content-Type for an export format
+func ExportContentType(format string) string {
+	if format == ExportFormatJSON {
+		return "application/json"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+var vehicleExportHeader = []string{
+	"id", "name", "plateNumber", "driver", "status", "fuelLevel", "maxFuelCapacity",
+	"fuelConsumption", "speed", "odometer", "lat", "lng", "address",
+	"make", "model", "year", "vin", "lastUpdate",
+}
+
+var alertExportHeader = []string{
+	"id", "vehicleId", "type", "severity", "message", "timestamp", "resolved", "resolvedAt",
+}
+
+// vehicleStreamer is the subset of the vehicle repository used by exports
+type vehicleStreamer interface {
+	StreamAll(ctx context.Context, fn func(*models.Vehicle) error) error
+}
+
+// alertStreamer is the subset of the alert repository used by exports
+type alertStreamer interface {
+	StreamFiltered(ctx context.Context, filter repository.AlertFilter, fn func(*models.Alert) error) error
+}
+
+// ExportVehicles streams every vehicle to w as CSV or a JSON array
+func (s *VehicleService) ExportVehicles(ctx context.Context, w io.Writer, format string) error {
+	return exportVehicles(ctx, s.vehicleRepo, w, format)
+}
+
+// ExportAlerts streams the alerts matching the request filters to w as CSV or a JSON array
+func (s *AlertService) ExportAlerts(ctx context.Context, w io.Writer, format string, req *AlertExportRequest) error {
+	return exportAlerts(ctx, s.alertRepo, w, format, req.Filter())
+}
+
+func exportVehicles(ctx context.Context, source vehicleStreamer, w io.Writer, format string) error {
+	writer, err := newExportWriter(w, format, vehicleExportHeader)
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(vehicleExportHeader))
+	err = source.StreamAll(ctx, func(vehicle *models.Vehicle) error {
+		if format == ExportFormatJSON {
+			return writer.writeJSON(vehicle)
+		}
+
+		row[0] = vehicle.ID.Hex()
+		row[1] = vehicle.Name
+		row[2] = vehicle.PlateNumber
+		row[3] = vehicle.Driver
+		row[4] = vehicle.Status
+		row[5] = strconv.FormatFloat(vehicle.FuelLevel, 'f', -1, 64)
+		row[6] = strconv.FormatFloat(vehicle.MaxFuelCapacity, 'f', -1, 64)
+		row[7] = strconv.FormatFloat(vehicle.FuelConsumption, 'f', -1, 64)
+		row[8] = strconv.Itoa(vehicle.Speed)
+		row[9] = strconv.Itoa(vehicle.Odometer)
+		row[10] = strconv.FormatFloat(vehicle.Location.Lat, 'f', -1, 64)
+		row[11] = strconv.FormatFloat(vehicle.Location.Lng, 'f', -1, 64)
+		row[12] = vehicle.Location.Address
+		row[13] = vehicle.Make
+		row[14] = vehicle.Model
+		row[15] = strconv.Itoa(vehicle.Year)
+		row[16] = vehicle.VIN
+		row[17] = formatExportTime(vehicle.LastUpdate)
+		return writer.writeCSV(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.close()
+}
+
+func exportAlerts(ctx context.Context, source alertStreamer, w io.Writer, format string, filter repository.AlertFilter) error {
+	writer, err := newExportWriter(w, format, alertExportHeader)
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(alertExportHeader))
+	err = source.StreamFiltered(ctx, filter, func(alert *models.Alert) error {
+		if format == ExportFormatJSON {
+			return writer.writeJSON(alert)
+		}
+
+		row[0] = alert.ID.Hex()
+		row[1] = alert.VehicleID
+		row[2] = alert.Type
+		row[3] = alert.Severity
+		row[4] = alert.Message
+		row[5] = formatExportTime(alert.Timestamp)
+		row[6] = strconv.FormatBool(alert.Resolved)
+		row[7] = ""
+		if alert.ResolvedAt != nil {
+			row[7] = formatExportTime(*alert.ResolvedAt)
+		}
+		return writer.writeCSV(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.close()
+}
+
+// exportWriter writes rows as CSV or as elements of a JSON array, flushing to
+// the underlying writer periodically so the response streams to the client
+type exportWriter struct {
+	out    io.Writer
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	json   *json.Encoder
+	format string
+	rows   int
+}
+
+func newExportWriter(w io.Writer, format string, header []string) (*exportWriter, error) {
+	if err := ValidateExportFormat(format); err != nil {
+		return nil, err
+	}
+
+	buf := bufio.NewWriter(w)
+	writer := &exportWriter{out: w, buf: buf, format: format}
+
+	if format == ExportFormatJSON {
+		writer.json = json.NewEncoder(buf)
+		if _, err := buf.WriteString("["); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	}
+
+	writer.csv = csv.NewWriter(buf)
+	if err := writer.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (e *exportWriter) writeCSV(row []string) error {
+	if err := e.csv.Write(row); err != nil {
+		return err
+	}
+	return e.rowWritten()
+}
+
+func (e *exportWriter) writeJSON(value interface{}) error {
+	if e.rows > 0 {
+		if err := e.buf.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	if err := e.json.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode export row: %w", err)
+	}
+	return e.rowWritten()
+}
+
+func (e *exportWriter) rowWritten() error {
+	e.rows++
+	if e.rows%exportFlushInterval == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) close() error {
+	if e.format == ExportFormatJSON {
+		if _, err := e.buf.WriteString("]\n"); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// flush pushes buffered rows to the client
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := e.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeVehicleStreamer yields a fixed number of generated vehicles
+type fakeVehicleStreamer struct {
+	count   int
+	onRow   func(i int)
+	vehicle models.Vehicle
+}
+
+func (f *fakeVehicleStreamer) StreamAll(_ context.Context, fn func(*models.Vehicle) error) error {
+	for i := 0; i < f.count; i++ {
+		if f.onRow != nil {
+			f.onRow(i)
+		}
+		// Reuse one vehicle so the fake itself adds no per-row allocations
+		f.vehicle.Odometer = i
+		if err := fn(&f.vehicle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeAlertStreamer applies the filter the way the repository's query would
+type fakeAlertStreamer struct {
+	alerts []*models.Alert
+}
+
+func (f *fakeAlertStreamer) StreamFiltered(_ context.Context, filter repository.AlertFilter, fn func(*models.Alert) error) error {
+	for _, alert := range f.alerts {
+		if filter.Severity != "" && alert.Severity != filter.Severity ||
+			filter.Type != "" && alert.Type != filter.Type ||
+			filter.Resolved != nil && alert.Resolved != *filter.Resolved ||
+			filter.From != nil && alert.Timestamp.Before(*filter.From) ||
+			filter.To != nil && alert.Timestamp.After(*filter.To) {
+			continue
+		}
+		if err := fn(alert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newExportTestVehicle() models.Vehicle {
+	return models.Vehicle{
+		ID:              primitive.NewObjectID(),
+		Name:            "Truck, Heavy",
+		PlateNumber:     "KAA-001",
+		Driver:          "Alice",
+		Status:          "active",
+		FuelLevel:       42.5,
+		MaxFuelCapacity: 400,
+		Location:        models.Location{Lat: -1.2921, Lng: 36.8219, Address: "Nairobi"},
+		Year:            2020,
+		LastUpdate:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestExportVehicles_CSV(t *testing.T) {
+	source := &fakeVehicleStreamer{count: 2, vehicle: newExportTestVehicle()}
+	var out bytes.Buffer
+
+	require.NoError(t, exportVehicles(context.Background(), source, &out, ExportFormatCSV))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, vehicleExportHeader, records[0])
+	assert.Equal(t, "id,name,plateNumber,driver,status,fuelLevel,maxFuelCapacity,fuelConsumption,speed,odometer,lat,lng,address,make,model,year,vin,lastUpdate",
+		strings.Join(records[0], ","))
+
+	// Fields containing commas survive quoting
+	assert.Equal(t, "Truck, Heavy", records[1][1])
+	assert.Equal(t, "42.5", records[1][5])
+	assert.Equal(t, "2026-01-02T03:04:05Z", records[1][17])
+	assert.Equal(t, "1", records[2][9])
+}
+
+func TestExportVehicles_JSON(t *testing.T) {
+	source := &fakeVehicleStreamer{count: 3, vehicle: newExportTestVehicle()}
+	var out bytes.Buffer
+
+	require.NoError(t, exportVehicles(context.Background(), source, &out, ExportFormatJSON))
+
+	var vehicles []models.Vehicle
+	require.NoError(t, json.Unmarshal(out.Bytes(), &vehicles))
+	require.Len(t, vehicles, 3)
+	assert.Equal(t, 2, vehicles[2].Odometer)
+}
+
+func TestExportVehicles_EmptyJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, exportVehicles(context.Background(), &fakeVehicleStreamer{}, &out, ExportFormatJSON))
+
+	var vehicles []models.Vehicle
+	require.NoError(t, json.Unmarshal(out.Bytes(), &vehicles))
+	assert.Empty(t, vehicles)
+}
+
+func TestExportVehicles_UnsupportedFormat(t *testing.T) {
+	err := exportVehicles(context.Background(), &fakeVehicleStreamer{}, io.Discard, "xml")
+	assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+}
+
+// countingWriter records how much data has reached the client
+type countingWriter struct {
+	written int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.written += len(p)
+	return len(p), nil
+}
+
+func TestExportVehicles_Streams(t *testing.T) {
+	out := &countingWriter{}
+	writtenAtMidpoint := 0
+	source := &fakeVehicleStreamer{
+		count:   1000,
+		vehicle: newExportTestVehicle(),
+		onRow: func(i int) {
+			if i == 500 {
+				writtenAtMidpoint = out.written
+			}
+		},
+	}
+
+	require.NoError(t, exportVehicles(context.Background(), source, out, ExportFormatCSV))
+
+	// Rows are flushed while the source is still producing, not buffered until the end
+	assert.Greater(t, writtenAtMidpoint, 0)
+	assert.Less(t, writtenAtMidpoint, out.written)
+}
+
+func TestExportVehicles_BoundedAllocations(t *testing.T) {
+	source := &fakeVehicleStreamer{count: 1000, vehicle: newExportTestVehicle()}
+
+	allocs := testing.AllocsPerRun(5, func() {
+		if err := exportVehicles(context.Background(), source, io.Discard, ExportFormatCSV); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Only per-field number formatting allocates; nothing accumulates across rows
+	assert.Less(t, allocs/1000, 15.0, fmt.Sprintf("%.0f allocations for 1000 rows", allocs))
+}
+
+func TestExportAlerts_CSV(t *testing.T) {
+	resolvedAt := time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)
+	source := &fakeAlertStreamer{alerts: []*models.Alert{
+		{ID: primitive.NewObjectID(), VehicleID: "v1", Type: "speeding", Severity: "high", Message: "Too fast",
+			Timestamp: time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC), Resolved: true, ResolvedAt: &resolvedAt},
+		{ID: primitive.NewObjectID(), VehicleID: "v2", Type: "low_fuel", Severity: "medium", Message: "Low fuel",
+			Timestamp: time.Date(2026, 1, 2, 3, 30, 0, 0, time.UTC)},
+	}}
+	var out bytes.Buffer
+
+	require.NoError(t, exportAlerts(context.Background(), source, &out, ExportFormatCSV, repository.AlertFilter{}))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"id", "vehicleId", "type", "severity", "message", "timestamp", "resolved", "resolvedAt"}, records[0])
+	assert.Equal(t, "true", records[1][6])
+	assert.Equal(t, "2026-01-02T04:00:00Z", records[1][7])
+	assert.Equal(t, "", records[2][7])
+}
+
+func TestExportAlerts_CSVWithFilters(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeAlertStreamer{alerts: []*models.Alert{
+		{ID: primitive.NewObjectID(), VehicleID: "v1", Type: "speeding", Severity: "high", Timestamp: base},
+		{ID: primitive.NewObjectID(), VehicleID: "v2", Type: "speeding", Severity: "high", Timestamp: base.Add(time.Hour), Resolved: true},
+		{ID: primitive.NewObjectID(), VehicleID: "v3", Type: "speeding", Severity: "low", Timestamp: base.Add(2 * time.Hour)},
+		{ID: primitive.NewObjectID(), VehicleID: "v4", Type: "low_fuel", Severity: "high", Timestamp: base.Add(3 * time.Hour)},
+		{ID: primitive.NewObjectID(), VehicleID: "v5", Type: "speeding", Severity: "high", Timestamp: base.Add(48 * time.Hour)},
+	}}
+
+	resolved := false
+	from := base.Add(-time.Minute)
+	to := base.Add(24 * time.Hour)
+	req := &AlertExportRequest{Severity: "high", Type: "speeding", Resolved: &resolved, From: &from, To: &to}
+
+	var out bytes.Buffer
+	require.NoError(t, exportAlerts(context.Background(), source, &out, ExportFormatCSV, req.Filter()))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, alertExportHeader, records[0])
+	assert.Equal(t, "v1", records[1][1])
+}
+
+func TestBuildAlertFilter(t *testing.T) {
+	assert.Empty(t, repository.BuildAlertFilter(repository.AlertFilter{}))
+
+	resolved := true
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	filter := repository.BuildAlertFilter(repository.AlertFilter{
+		Severity: "critical",
+		Type:     "fuel_theft",
+		Resolved: &resolved,
+		From:     &from,
+		To:       &to,
+	})
+
+	assert.Equal(t, bson.M{
+		"severity":  "critical",
+		"type":      "fuel_theft",
+		"resolved":  true,
+		"timestamp": bson.M{"$gte": from, "$lte": to},
+	}, filter)
+
+	fromOnly := repository.BuildAlertFilter(repository.AlertFilter{From: &from})
+	assert.Equal(t, bson.M{"timestamp": bson.M{"$gte": from}}, fromOnly)
+}
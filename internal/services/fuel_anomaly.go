@@ -0,0 +1,201 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// minFuelWindowElapsed keeps near-simultaneous readings from producing huge rates
+	minFuelWindowElapsed = time.Minute
+	// maxFuelWindowReadings bounds the readings kept per vehicle within the window
+	maxFuelWindowReadings = 120
+	// minFuelStdDevFraction floors the standard deviation at a fraction of the
+	// mean rate, so a perfectly steady baseline doesn't flag ordinary jitter
+	minFuelStdDevFraction = 0.1
+	// fallbackFuelCapacity stands in for vehicles without a known tank capacity
+	fallbackFuelCapacity = 100.0
+)
+
+// FuelAnomalyPolicy configures how fuel drops are judged against a vehicle's
+// normal consumption
+type FuelAnomalyPolicy struct {
+	// Sensitivity is how many standard deviations above the mean consumption
+	// rate a drop must be to count as theft
+	Sensitivity float64
+	// Window is how far back a drop is measured from; older readings don't count
+	Window time.Duration
+	// BaselineSize is how many recent consumption rates form the baseline
+	BaselineSize int
+	// MinBaselineSamples is how many rates are needed before the baseline is used
+	MinBaselineSamples int
+	// NoiseFraction of the tank capacity is the sensor noise; smaller changes
+	// are never theft or refuels. Fuel senders report a share of the tank, so
+	// their noise grows with it.
+	NoiseFraction float64
+	// ColdStartFraction of the tank capacity is the drop flagged as theft while
+	// a vehicle has no baseline yet
+	ColdStartFraction float64
+}
+
+// DefaultFuelAnomalyPolicy returns the policy used unless configured otherwise
+func DefaultFuelAnomalyPolicy() FuelAnomalyPolicy {
+	return FuelAnomalyPolicy{
+		Sensitivity:        4,
+		Window:             30 * time.Minute,
+		BaselineSize:       50,
+		MinBaselineSamples: 10,
+		NoiseFraction:      0.01,
+		ColdStartFraction:  0.15,
+	}
+}
+
+// FuelCheck is the verdict on a fuel reading
+type FuelCheck struct {
+	Theft  bool
+	Refuel bool
+	// Drop is the liters lost over the window, or since the previous level
+	// when there are no recent readings
+	Drop float64
+	// Rate is the consumption over the window in liters per hour, BaselineRate
+	// the vehicle's normal rate and MaxDrop the largest drop its baseline
+	// explains over the window. All three are zero when the drop was judged
+	// against the tank capacity instead.
+	Rate         float64
+	BaselineRate float64
+	MaxDrop      float64
+}
+
+type fuelReading struct {
+	level float64
+	at    time.Time
+}
+
+type fuelHistory struct {
+	// readings within the window since the last refuel or theft, oldest first
+	readings []fuelReading
+	// rates are recent normal consumption rates in liters per hour, oldest first
+	rates []float64
+}
+
+// FuelAnomalyDetector learns each vehicle's normal fuel consumption rate and
+// flags drops far above it as theft. A fixed threshold misfires on large tanks
+// and misses slow siphoning on small ones; a per-vehicle baseline does neither.
+type FuelAnomalyDetector struct {
+	policy  FuelAnomalyPolicy
+	history map[string]*fuelHistory
+	mu      sync.Mutex
+}
+
+// NewFuelAnomalyDetector creates a detector with no history
+func NewFuelAnomalyDetector(policy FuelAnomalyPolicy) *FuelAnomalyDetector {
+	return &FuelAnomalyDetector{
+		policy:  policy,
+		history: make(map[string]*fuelHistory),
+	}
+}
+
+// Check records a fuel reading and judges the change from previousLevel.
+// A rise beyond the sensor noise is a refuel and restarts the window. A drop
+// is measured from the oldest reading within the window and flagged when it
+// exceeds the consumption the vehicle's baseline expects over that time by
+// more than Sensitivity standard deviations plus the sensor noise. Windows
+// spanning at least half of Window and not flagged as theft extend the baseline.
+func (d *FuelAnomalyDetector) Check(vehicleID string, previousLevel, level, capacity float64, at time.Time) FuelCheck {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	history := d.history[vehicleID]
+	if history == nil {
+		history = &fuelHistory{}
+		d.history[vehicleID] = history
+	}
+	current := fuelReading{level: level, at: at}
+	if capacity <= 0 {
+		capacity = fallbackFuelCapacity
+	}
+	noise := d.policy.NoiseFraction * capacity
+
+	if level-previousLevel > noise {
+		history.readings = []fuelReading{current}
+		return FuelCheck{Refuel: true}
+	}
+
+	// Drop readings that fell out of the window
+	cutoff := at.Add(-d.policy.Window)
+	start := 0
+	for start < len(history.readings) && history.readings[start].at.Before(cutoff) {
+		start++
+	}
+	history.readings = history.readings[start:]
+
+	// Without recent readings the elapsed time is unknown, so only the size
+	// of the drop relative to the tank can be judged
+	if len(history.readings) == 0 {
+		drop := previousLevel - level
+		history.readings = append(history.readings, current)
+		return FuelCheck{Theft: drop > d.policy.ColdStartFraction*capacity, Drop: drop}
+	}
+
+	oldest := history.readings[0]
+	elapsed := at.Sub(oldest.at)
+	if elapsed < minFuelWindowElapsed {
+		elapsed = minFuelWindowElapsed
+	}
+	hours := elapsed.Hours()
+	check := FuelCheck{Drop: oldest.level - level}
+	rate := math.Max(check.Drop, 0) / hours
+
+	switch {
+	case check.Drop <= noise:
+		// Within sensor noise, nothing to judge
+	case len(history.rates) >= d.policy.MinBaselineSamples:
+		mean, stdDev := meanStdDev(history.rates)
+		stdDev = math.Max(stdDev, minFuelStdDevFraction*mean)
+		check.Rate = rate
+		check.BaselineRate = mean
+		check.MaxDrop = (mean+d.policy.Sensitivity*stdDev)*hours + noise
+		check.Theft = check.Drop > check.MaxDrop
+	default:
+		check.Theft = check.Drop > d.policy.ColdStartFraction*capacity
+	}
+
+	if check.Theft {
+		// Start over so the same drop isn't reported again on the next reading
+		history.readings = []fuelReading{current}
+		return check
+	}
+
+	// Rates over short spans are dominated by sensor noise
+	if elapsed >= d.policy.Window/2 {
+		d.recordRate(history, rate)
+	}
+	history.readings = append(history.readings, current)
+	if len(history.readings) > maxFuelWindowReadings {
+		history.readings = history.readings[len(history.readings)-maxFuelWindowReadings:]
+	}
+	return check
+}
+
+// recordRate adds a normal consumption rate to the vehicle's baseline
+func (d *FuelAnomalyDetector) recordRate(history *fuelHistory, rate float64) {
+	history.rates = append(history.rates, rate)
+	if len(history.rates) > d.policy.BaselineSize {
+		history.rates = history.rates[len(history.rates)-d.policy.BaselineSize:]
+	}
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
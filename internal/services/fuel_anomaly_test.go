@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fuelSeries feeds readings to a detector the way the update path does,
+// passing the previously reported level along with each new one
+type fuelSeries struct {
+	detector *FuelAnomalyDetector
+	capacity float64
+	level    float64
+	at       time.Time
+	thefts   int
+}
+
+func newFuelSeries(capacity, level float64) *fuelSeries {
+	return &fuelSeries{
+		detector: NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy()),
+		capacity: capacity,
+		level:    level,
+		at:       time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func (f *fuelSeries) report(level float64, after time.Duration) FuelCheck {
+	f.at = f.at.Add(after)
+	check := f.detector.Check("v1", f.level, level, f.capacity, f.at)
+	f.level = level
+	if check.Theft {
+		f.thefts++
+	}
+	return check
+}
+
+// drive reports consumption at litersPerHour every interval for the given
+// duration, with sensor noise of up to ±noise liters on each reading
+func (f *fuelSeries) drive(rng *rand.Rand, litersPerHour, noise float64, interval, duration time.Duration) {
+	trueLevel := f.level
+	for elapsed := interval; elapsed <= duration; elapsed += interval {
+		trueLevel -= litersPerHour * interval.Hours()
+		f.report(trueLevel+(rng.Float64()*2-1)*noise, interval)
+	}
+}
+
+func TestFuelAnomalyDetector_NoisyNormalConsumptionDoesNotAlert(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	series := newFuelSeries(400, 380)
+
+	series.drive(rng, 12, 0.4, 5*time.Minute, 8*time.Hour)
+
+	assert.Zero(t, series.thefts)
+}
+
+func TestFuelAnomalyDetector_SuddenSiphonAlerts(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	series := newFuelSeries(400, 380)
+	series.drive(rng, 12, 0.4, 5*time.Minute, 3*time.Hour)
+	require.Zero(t, series.thefts)
+
+	// 8L in five minutes is well under the old fixed 15L threshold
+	check := series.report(series.level-1-8, 5*time.Minute)
+
+	assert.True(t, check.Theft)
+	assert.InDelta(t, 12, check.BaselineRate, 2)
+	assert.Greater(t, check.Drop, check.MaxDrop)
+
+	// Consumption returns to normal afterwards without repeat alerts
+	series.drive(rng, 12, 0.4, 5*time.Minute, time.Hour)
+	assert.Equal(t, 1, series.thefts)
+}
+
+func TestFuelAnomalyDetector_SlowSiphonOnParkedVehicleAlerts(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	series := newFuelSeries(40, 30)
+	series.drive(rng, 0, 0.2, 5*time.Minute, 3*time.Hour)
+	require.Zero(t, series.thefts)
+
+	// Under a liter per reading, far below the old fixed 15L threshold
+	series.drive(rng, 9, 0.2, 5*time.Minute, 30*time.Minute)
+
+	assert.NotZero(t, series.thefts)
+}
+
+func TestFuelAnomalyDetector_RefuelIsNotTheft(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	series := newFuelSeries(400, 200)
+	series.drive(rng, 12, 0.4, 5*time.Minute, 3*time.Hour)
+
+	check := series.report(series.level+150, 10*time.Minute)
+	assert.True(t, check.Refuel)
+	assert.False(t, check.Theft)
+
+	// Fuel settling right after refueling stays within sensor noise
+	series.report(series.level-3, time.Minute)
+	series.drive(rng, 12, 0.4, 5*time.Minute, time.Hour)
+	assert.Zero(t, series.thefts)
+}
+
+func TestFuelAnomalyDetector_ColdStartScalesWithTank(t *testing.T) {
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	largeTank := NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy())
+	assert.False(t, largeTank.Check("truck", 380, 360, 400, at).Theft, "20L is 5% of a 400L tank")
+
+	smallTank := NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy())
+	assert.True(t, smallTank.Check("car", 35, 25, 40, at).Theft, "10L is 25% of a 40L tank")
+}
+
+func TestFuelAnomalyDetector_TracksVehiclesSeparately(t *testing.T) {
+	detector := NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy())
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 30; i++ {
+		at = at.Add(5 * time.Minute)
+		detector.Check("busy", 100-float64(i), 100-float64(i+1), 400, at)
+	}
+
+	check := detector.Check("quiet", 50, 40, 400, at)
+	assert.Zero(t, check.BaselineRate, "a vehicle without history is judged against its tank")
+}
+
+func TestFuelAnomalyDetector_UnknownCapacityKeepsFixedThreshold(t *testing.T) {
+	detector := NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy())
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.False(t, detector.Check("v1", 60, 50, 0, at).Theft)
+	assert.True(t, detector.Check("v2", 60, 40, 0, at).Theft)
+}
+
+func TestVehicleService_CheckFuelTheft_UsesDetectorBaseline(t *testing.T) {
+	service, store, _, _ := newDispatchTestService()
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), FuelLevel: 380, MaxFuelCapacity: 400}
+
+	// A 20L drop on a 400L tank is a normal dip, not theft
+	vehicle.FuelLevel = 360
+	service.checkFuelTheft(context.Background(), vehicle, 380)
+	assert.Empty(t, store.created)
+
+	vehicle.FuelLevel = 200
+	service.checkFuelTheft(context.Background(), vehicle, 360)
+	require.Len(t, store.created, 1)
+	assert.Equal(t, "fuel_theft", store.created[0].Type)
+}
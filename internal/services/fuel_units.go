@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+
+	"fleet-backend/internal/models"
+)
+
+// ErrInvalidFuelUnit is returned for fuel units other than liters and gallons
+var ErrInvalidFuelUnit = newDomainError(ErrValidation, "invalid fuel unit")
+
+// SetFuelUnit sets the unit fuel is presented in for vehicles without their own
+func (s *VehicleService) SetFuelUnit(unit string) error {
+	if !models.IsValidFuelUnit(unit) {
+		return fmt.Errorf("%w: %q", ErrInvalidFuelUnit, unit)
+	}
+	s.fuelUnit = unit
+	return nil
+}
+
+// fuelUnitFor returns the unit the vehicle's fuel is presented in
+func (s *VehicleService) fuelUnitFor(vehicle *models.Vehicle) string {
+	if vehicle.FuelUnit != "" {
+		return vehicle.FuelUnit
+	}
+	if s.fuelUnit != "" {
+		return s.fuelUnit
+	}
+	return models.FuelUnitLiters
+}
+
+// PresentVehicle returns a copy of the vehicle with its fuel level and capacity
+// in the vehicle's fuel unit, for API responses. The stored vehicle is left in
+// liters.
+func (s *VehicleService) PresentVehicle(vehicle *models.Vehicle) *models.Vehicle {
+	if vehicle == nil {
+		return nil
+	}
+	presented := *vehicle
+	presented.FuelUnit = s.fuelUnitFor(vehicle)
+	presented.FuelLevel = models.FuelFromLiters(vehicle.FuelLevel, presented.FuelUnit)
+	presented.MaxFuelCapacity = models.FuelFromLiters(vehicle.MaxFuelCapacity, presented.FuelUnit)
+	return &presented
+}
+
+// PresentVehicles presents each vehicle as PresentVehicle does
+func (s *VehicleService) PresentVehicles(vehicles []*models.Vehicle) []*models.Vehicle {
+	if vehicles == nil {
+		return nil
+	}
+	presented := make([]*models.Vehicle, len(vehicles))
+	for i, vehicle := range vehicles {
+		presented[i] = s.PresentVehicle(vehicle)
+	}
+	return presented
+}
+
+// fuelPercentage is how full the vehicle's tank is. Level and capacity are
+// both stored in liters, so the fuel unit doesn't change it.
+func fuelPercentage(vehicle *models.Vehicle) float64 {
+	return (vehicle.FuelLevel / vehicle.MaxFuelCapacity) * 100
+}
+
+// fuelAmount formats an amount of fuel in liters for alert messages, e.g.
+// "12.5L" or "3.3 gal"
+func fuelAmount(liters float64, unit string) string {
+	if unit == models.FuelUnitGallons {
+		return fmt.Sprintf("%.1f gal", models.FuelFromLiters(liters, unit))
+	}
+	return fmt.Sprintf("%.1fL", liters)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVehicleInFuelUnit_StoresLiters(t *testing.T) {
+	service := &VehicleService{}
+	require.NoError(t, service.SetFuelUnit(models.FuelUnitGallons))
+
+	vehicle := service.newVehicleInFuelUnit(&CreateVehicleRequest{Name: "Truck 1", MaxFuelCapacity: 10})
+	assert.InDelta(t, 37.85, vehicle.MaxFuelCapacity, 0.01)
+	assert.Empty(t, vehicle.FuelUnit, "vehicles without their own unit follow the fleet's")
+
+	// A vehicle's own unit wins over the fleet's
+	vehicle = service.newVehicleInFuelUnit(&CreateVehicleRequest{Name: "Truck 2", MaxFuelCapacity: 80, FuelUnit: models.FuelUnitLiters})
+	assert.Equal(t, 80.0, vehicle.MaxFuelCapacity)
+	assert.Equal(t, models.FuelUnitLiters, vehicle.FuelUnit)
+}
+
+func TestPresentVehicle_ConvertsCopy(t *testing.T) {
+	service := &VehicleService{}
+	stored := &models.Vehicle{FuelLevel: 18.927, MaxFuelCapacity: 37.85411784, FuelUnit: models.FuelUnitGallons}
+
+	presented := service.PresentVehicle(stored)
+
+	assert.InDelta(t, 10, presented.MaxFuelCapacity, 1e-9)
+	assert.InDelta(t, 5, presented.FuelLevel, 0.001)
+	assert.Equal(t, 37.85411784, stored.MaxFuelCapacity, "the stored vehicle stays in liters")
+
+	// Vehicles without a unit are presented in the fleet's, defaulting to liters
+	presented = service.PresentVehicle(&models.Vehicle{FuelLevel: 40, MaxFuelCapacity: 80})
+	assert.Equal(t, 80.0, presented.MaxFuelCapacity)
+	assert.Equal(t, models.FuelUnitLiters, presented.FuelUnit)
+}
+
+func TestFuelPercentage_IndependentOfUnit(t *testing.T) {
+	gallons := &VehicleService{}
+	require.NoError(t, gallons.SetFuelUnit(models.FuelUnitGallons))
+	vehicle := gallons.newVehicleInFuelUnit(&CreateVehicleRequest{MaxFuelCapacity: 10})
+	vehicle.FuelLevel = models.FuelToLiters(1.5, models.FuelUnitGallons)
+
+	assert.InDelta(t, 15, fuelPercentage(vehicle), 1e-9)
+	assert.InDelta(t, fuelPercentage(&models.Vehicle{FuelLevel: 1.5, MaxFuelCapacity: 10}), fuelPercentage(vehicle), 1e-9)
+	assert.Less(t, fuelPercentage(vehicle), lowFuelThresholdPercent)
+}
+
+func TestFuelTheftAlertData_InVehicleUnit(t *testing.T) {
+	check := FuelCheck{Theft: true, Drop: models.LitersPerGallon * 4, Rate: models.LitersPerGallon, BaselineRate: models.LitersPerGallon / 2, MaxDrop: models.LitersPerGallon}
+
+	data := fuelTheftAlertData(check, models.LitersPerGallon*10, models.LitersPerGallon*6, models.FuelUnitGallons)
+
+	assert.Equal(t, models.FuelUnitGallons, data["fuelUnit"])
+	assert.InDelta(t, 4, data["fuelDrop"], 1e-9)
+	assert.InDelta(t, 6, data["fuelLevel"], 1e-9)
+	assert.InDelta(t, 1, data["ratePerHour"], 1e-9)
+	assert.Equal(t, "4.0 gal", fuelAmount(check.Drop, models.FuelUnitGallons))
+	assert.Equal(t, "15.1L", fuelAmount(check.Drop, models.FuelUnitLiters))
+}
+
+func TestSetFuelUnit_RejectsUnknownUnit(t *testing.T) {
+	service := &VehicleService{}
+
+	assert.ErrorIs(t, service.SetFuelUnit("imperial_gallons"), ErrInvalidFuelUnit)
+	assert.Equal(t, models.FuelUnitLiters, service.fuelUnitFor(&models.Vehicle{}))
+}
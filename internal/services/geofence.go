@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/pkg/geo"
+	"time"
+)
+
+type GeofenceService struct {
+	geofenceRepo *repository.GeofenceRepository
+	vehicleRepo  *repository.VehicleRepository
+}
+
+func NewGeofenceService(geofenceRepo *repository.GeofenceRepository, vehicleRepo *repository.VehicleRepository) *GeofenceService {
+	return &GeofenceService{
+		geofenceRepo: geofenceRepo,
+		vehicleRepo:  vehicleRepo,
+	}
+}
+
+type CreateGeofenceRequest struct {
+	Name         string            `json:"name" validate:"required,min=1,max=100"`
+	Description  string            `json:"description,omitempty" validate:"max=500"`
+	Type         string            `json:"type" validate:"required,oneof=circle polygon"`
+	Center       *models.GeoPoint  `json:"center,omitempty"`
+	RadiusMeters float64           `json:"radiusMeters,omitempty" validate:"min=0"`
+	Polygon      []models.GeoPoint `json:"polygon,omitempty" validate:"dive"`
+	// SpeedLimitKmh makes the geofence a speed zone for speeding alerts
+	SpeedLimitKmh int `json:"speedLimitKmh,omitempty" validate:"omitempty,min=1,max=250"`
+}
+
+// VehicleGeofencesResponse lists the geofences currently containing a vehicle
+type VehicleGeofencesResponse struct {
+	VehicleID string             `json:"vehicleId"`
+	Location  models.Location    `json:"location"`
+	Geofences []*models.Geofence `json:"geofences"`
+}
+
+// geofenceLister is the subset of the geofence repository used for containment checks
+type geofenceLister interface {
+	FindAll() ([]*models.Geofence, error)
+}
+
+// vehicleFinder is the subset of the vehicle repository used to look up a vehicle
+type vehicleFinder interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+}
+
+func (s *GeofenceService) CreateGeofence(req *CreateGeofenceRequest) (*models.Geofence, error) {
+	geofence := &models.Geofence{
+		Name:          req.Name,
+		Description:   req.Description,
+		Type:          req.Type,
+		SpeedLimitKmh: req.SpeedLimitKmh,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	switch req.Type {
+	case models.GeofenceTypeCircle:
+		if req.Center == nil || req.RadiusMeters <= 0 {
+			return nil, errors.New("circle geofences require a center and a positive radius")
+		}
+		geofence.Center = req.Center
+		geofence.RadiusMeters = req.RadiusMeters
+	case models.GeofenceTypePolygon:
+		if len(req.Polygon) < 3 {
+			return nil, errors.New("polygon geofences require at least 3 vertices")
+		}
+		geofence.Polygon = req.Polygon
+	}
+
+	return s.geofenceRepo.Create(geofence)
+}
+
+func (s *GeofenceService) GetAllGeofences() ([]*models.Geofence, error) {
+	return s.geofenceRepo.FindAll()
+}
+
+func (s *GeofenceService) GetGeofence(id string) (*models.Geofence, error) {
+	return s.geofenceRepo.FindByID(id)
+}
+
+func (s *GeofenceService) DeleteGeofence(id string) error {
+	return s.geofenceRepo.Delete(id)
+}
+
+// GetVehicleGeofences returns the geofences whose boundary currently contains
+// the vehicle's latest location
+func (s *GeofenceService) GetVehicleGeofences(ctx context.Context, vehicleID string) (*VehicleGeofencesResponse, error) {
+	return vehicleGeofences(ctx, s.vehicleRepo, s.geofenceRepo, vehicleID)
+}
+
+func vehicleGeofences(ctx context.Context, vehicles vehicleFinder, geofences geofenceLister, vehicleID string) (*VehicleGeofencesResponse, error) {
+	vehicle, err := vehicles.FindByID(ctx, vehicleID)
+	if err != nil {
+		return nil, errors.New("vehicle not found")
+	}
+
+	all, err := geofences.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VehicleGeofencesResponse{
+		VehicleID: vehicleID,
+		Location:  vehicle.Location,
+		Geofences: containingGeofences(all, vehicle.Location),
+	}, nil
+}
+
+// containingGeofences filters geofences down to those containing the location
+func containingGeofences(geofences []*models.Geofence, location models.Location) []*models.Geofence {
+	containing := []*models.Geofence{}
+	for _, geofence := range geofences {
+		if GeofenceContains(geofence, location) {
+			containing = append(containing, geofence)
+		}
+	}
+	return containing
+}
+
+// GeofenceContains reports whether a location lies inside a geofence's boundary
+func GeofenceContains(geofence *models.Geofence, location models.Location) bool {
+	point := geo.Point{Lat: location.Lat, Lng: location.Lng}
+
+	switch geofence.Type {
+	case models.GeofenceTypeCircle:
+		if geofence.Center == nil {
+			return false
+		}
+		center := geo.Point{Lat: geofence.Center.Lat, Lng: geofence.Center.Lng}
+		return geo.InCircle(point, center, geofence.RadiusMeters)
+	case models.GeofenceTypePolygon:
+		polygon := make([]geo.Point, len(geofence.Polygon))
+		for i, vertex := range geofence.Polygon {
+			polygon[i] = geo.Point{Lat: vertex.Lat, Lng: vertex.Lng}
+		}
+		return geo.InPolygon(point, polygon)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeVehicleFinder struct {
+	vehicles map[string]*models.Vehicle
+}
+
+func (f *fakeVehicleFinder) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	if vehicle, exists := f.vehicles[id]; exists {
+		return vehicle, nil
+	}
+	return nil, errors.New("vehicle not found")
+}
+
+type fakeGeofenceLister struct {
+	geofences []*models.Geofence
+}
+
+func (f *fakeGeofenceLister) FindAll() ([]*models.Geofence, error) {
+	return f.geofences, nil
+}
+
+func newTestGeofences() []*models.Geofence {
+	return []*models.Geofence{
+		{
+			// Depot: 200m circle around the truck's position
+			ID:           primitive.NewObjectID(),
+			Name:         "Depot",
+			Type:         models.GeofenceTypeCircle,
+			Center:       &models.GeoPoint{Lat: -1.2921, Lng: 36.8219},
+			RadiusMeters: 200,
+		},
+		{
+			// Airport: circle several kilometres away
+			ID:           primitive.NewObjectID(),
+			Name:         "Airport",
+			Type:         models.GeofenceTypeCircle,
+			Center:       &models.GeoPoint{Lat: -1.3192, Lng: 36.9278},
+			RadiusMeters: 1500,
+		},
+		{
+			// City centre polygon enclosing the truck
+			ID:   primitive.NewObjectID(),
+			Name: "CBD",
+			Type: models.GeofenceTypePolygon,
+			Polygon: []models.GeoPoint{
+				{Lat: -1.280, Lng: 36.810},
+				{Lat: -1.280, Lng: 36.835},
+				{Lat: -1.300, Lng: 36.835},
+				{Lat: -1.300, Lng: 36.810},
+			},
+		},
+		{
+			// Industrial area polygon to the south-east
+			ID:   primitive.NewObjectID(),
+			Name: "Industrial Area",
+			Type: models.GeofenceTypePolygon,
+			Polygon: []models.GeoPoint{
+				{Lat: -1.305, Lng: 36.840},
+				{Lat: -1.305, Lng: 36.870},
+				{Lat: -1.325, Lng: 36.870},
+				{Lat: -1.325, Lng: 36.840},
+			},
+		},
+	}
+}
+
+func TestVehicleGeofences_ReturnsOnlyContainingGeofences(t *testing.T) {
+	vehicleID := primitive.NewObjectID().Hex()
+	vehicles := &fakeVehicleFinder{vehicles: map[string]*models.Vehicle{
+		vehicleID: {Location: models.Location{Lat: -1.2925, Lng: 36.8222}},
+	}}
+	geofences := &fakeGeofenceLister{geofences: newTestGeofences()}
+
+	result, err := vehicleGeofences(context.Background(), vehicles, geofences, vehicleID)
+	require.NoError(t, err)
+
+	var names []string
+	for _, geofence := range result.Geofences {
+		names = append(names, geofence.Name)
+	}
+	assert.ElementsMatch(t, []string{"Depot", "CBD"}, names)
+	assert.Equal(t, vehicleID, result.VehicleID)
+}
+
+func TestVehicleGeofences_OutsideAll(t *testing.T) {
+	vehicleID := primitive.NewObjectID().Hex()
+	vehicles := &fakeVehicleFinder{vehicles: map[string]*models.Vehicle{
+		vehicleID: {Location: models.Location{Lat: -4.0435, Lng: 39.6682}},
+	}}
+	geofences := &fakeGeofenceLister{geofences: newTestGeofences()}
+
+	result, err := vehicleGeofences(context.Background(), vehicles, geofences, vehicleID)
+	require.NoError(t, err)
+	assert.NotNil(t, result.Geofences)
+	assert.Empty(t, result.Geofences)
+}
+
+func TestVehicleGeofences_UnknownVehicle(t *testing.T) {
+	_, err := vehicleGeofences(context.Background(), &fakeVehicleFinder{}, &fakeGeofenceLister{}, "missing")
+	assert.EqualError(t, err, "vehicle not found")
+}
+
+func TestGeofenceContains_MalformedGeofences(t *testing.T) {
+	location := models.Location{Lat: 0, Lng: 0}
+
+	assert.False(t, GeofenceContains(&models.Geofence{Type: models.GeofenceTypeCircle, RadiusMeters: 100}, location))
+	assert.False(t, GeofenceContains(&models.Geofence{Type: models.GeofenceTypePolygon}, location))
+	assert.False(t, GeofenceContains(&models.Geofence{Type: "hexagon"}, location))
+}
+
+func TestCreateGeofence_ShapeValidation(t *testing.T) {
+	service := &GeofenceService{}
+
+	_, err := service.CreateGeofence(&CreateGeofenceRequest{Name: "Depot", Type: models.GeofenceTypeCircle})
+	assert.Error(t, err)
+
+	_, err = service.CreateGeofence(&CreateGeofenceRequest{
+		Name:    "Yard",
+		Type:    models.GeofenceTypePolygon,
+		Polygon: []models.GeoPoint{{Lat: 0, Lng: 0}, {Lat: 1, Lng: 1}},
+	})
+	assert.Error(t, err)
+}
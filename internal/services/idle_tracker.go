@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrIdleTrackingDisabled is returned when idle stats are requested without an idle tracker
+	ErrIdleTrackingDisabled = errors.New("idle tracking is not enabled")
+	// ErrInvalidIdleRange is returned when an idle stats range doesn't end after it starts
+	ErrInvalidIdleRange = errors.New("idle stats range must end after it starts")
+)
+
+// idleSegmentStore is the subset of the idle segment repository used by the tracker
+type idleSegmentStore interface {
+	Create(segment *models.IdleSegment) error
+	FindOverlapping(vehicleID string, from, to time.Time) ([]*models.IdleSegment, error)
+}
+
+// IdleTracker accumulates the time vehicles spend idling. Each idle period is
+// persisted as a segment when the vehicle leaves the idle state, so idle time
+// can be totalled over any range; the period in progress is kept in memory.
+type IdleTracker struct {
+	store idleSegmentStore
+	now   func() time.Time
+
+	mu        sync.Mutex
+	idleSince map[string]time.Time // vehicle ID -> start of the open idle segment
+}
+
+func NewIdleTracker(repo *repository.IdleSegmentRepository) *IdleTracker {
+	return newIdleTracker(repo)
+}
+
+func newIdleTracker(store idleSegmentStore) *IdleTracker {
+	return &IdleTracker{
+		store:     store,
+		now:       time.Now,
+		idleSince: make(map[string]time.Time),
+	}
+}
+
+// RecordState notes whether a vehicle is idling as of at. Entering idle opens
+// a segment; leaving it closes and persists the segment.
+func (t *IdleTracker) RecordState(vehicleID string, idle bool, at time.Time) {
+	t.mu.Lock()
+	start, open := t.idleSince[vehicleID]
+	switch {
+	case idle && !open:
+		t.idleSince[vehicleID] = at
+		t.mu.Unlock()
+		return
+	case !idle && open:
+		delete(t.idleSince, vehicleID)
+	default:
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+
+	t.persist(vehicleID, start, at)
+}
+
+// Flush closes every open segment at at, e.g. before shutdown
+func (t *IdleTracker) Flush(at time.Time) {
+	t.mu.Lock()
+	open := t.idleSince
+	t.idleSince = make(map[string]time.Time)
+	t.mu.Unlock()
+
+	for vehicleID, start := range open {
+		t.persist(vehicleID, start, at)
+	}
+}
+
+func (t *IdleTracker) persist(vehicleID string, start, end time.Time) {
+	if !end.After(start) {
+		return
+	}
+
+	segment := &models.IdleSegment{
+		VehicleID:       vehicleID,
+		StartedAt:       start,
+		EndedAt:         end,
+		DurationSeconds: end.Sub(start).Seconds(),
+	}
+	if err := t.store.Create(segment); err != nil {
+		fmt.Printf("Failed to persist idle segment for vehicle %s: %v\n", vehicleID, err)
+	}
+}
+
+// GetIdleStats totals a vehicle's idle time within [from, to), including the
+// segment in progress if the vehicle is idling now
+func (t *IdleTracker) GetIdleStats(vehicleID string, from, to time.Time) (*models.IdleStats, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidIdleRange
+	}
+
+	segments, err := t.store.FindOverlapping(vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	start, idling := t.idleSince[vehicleID]
+	t.mu.Unlock()
+
+	stats := &models.IdleStats{
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		Segments:  []*models.IdleSegment{},
+	}
+
+	if idling {
+		stats.IdleSince = &start
+		if end := t.now(); start.Before(to) && end.After(from) {
+			segments = append(segments, &models.IdleSegment{VehicleID: vehicleID, StartedAt: start, EndedAt: end})
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].StartedAt.Before(segments[j].StartedAt) })
+
+	for _, segment := range segments {
+		clipped := clipIdleSegment(segment, from, to)
+		if clipped == nil {
+			continue
+		}
+		stats.Segments = append(stats.Segments, clipped)
+		stats.IdleSeconds += clipped.DurationSeconds
+	}
+	stats.SegmentCount = len(stats.Segments)
+
+	return stats, nil
+}
+
+// clipIdleSegment returns the part of a segment inside [from, to), or nil if none
+func clipIdleSegment(segment *models.IdleSegment, from, to time.Time) *models.IdleSegment {
+	clipped := *segment
+	if clipped.StartedAt.Before(from) {
+		clipped.StartedAt = from
+	}
+	if clipped.EndedAt.After(to) {
+		clipped.EndedAt = to
+	}
+	if !clipped.EndedAt.After(clipped.StartedAt) {
+		return nil
+	}
+	clipped.DurationSeconds = clipped.EndedAt.Sub(clipped.StartedAt).Seconds()
+	return &clipped
+}
+
+// GetIdleStats returns the idle time a vehicle accumulated within [from, to)
+func (s *VehicleService) GetIdleStats(ctx context.Context, id string, from, to time.Time) (*models.IdleStats, error) {
+	if s.idleTracker == nil {
+		return nil, ErrIdleTrackingDisabled
+	}
+	if _, err := s.GetVehicleByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.idleTracker.GetIdleStats(id, from, to)
+}
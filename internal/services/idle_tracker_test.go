@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdleStore keeps idle segments in memory
+type memoryIdleStore struct {
+	segments []*models.IdleSegment
+}
+
+func (m *memoryIdleStore) Create(segment *models.IdleSegment) error {
+	m.segments = append(m.segments, segment)
+	return nil
+}
+
+func (m *memoryIdleStore) FindOverlapping(vehicleID string, from, to time.Time) ([]*models.IdleSegment, error) {
+	var found []*models.IdleSegment
+	for _, segment := range m.segments {
+		if segment.VehicleID == vehicleID && segment.StartedAt.Before(to) && segment.EndedAt.After(from) {
+			found = append(found, segment)
+		}
+	}
+	return found, nil
+}
+
+func TestIdleTracker_AccumulatesIdleToActiveTransitions(t *testing.T) {
+	store := &memoryIdleStore{}
+	tracker := newIdleTracker(store)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start.Add(time.Hour) }
+
+	tracker.RecordState("v1", false, start)
+	tracker.RecordState("v1", true, start.Add(5*time.Minute))
+	// Repeated idle reports don't restart the segment
+	tracker.RecordState("v1", true, start.Add(7*time.Minute))
+	tracker.RecordState("v1", false, start.Add(10*time.Minute))
+	tracker.RecordState("v1", true, start.Add(20*time.Minute))
+	tracker.RecordState("v1", false, start.Add(20*time.Minute+30*time.Second))
+	// Another vehicle's idling is tracked separately
+	tracker.RecordState("v2", true, start)
+	tracker.RecordState("v2", false, start.Add(time.Minute))
+
+	require.Len(t, store.segments, 3)
+
+	stats, err := tracker.GetIdleStats("v1", start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 330.0, stats.IdleSeconds)
+	assert.Equal(t, 2, stats.SegmentCount)
+	assert.Nil(t, stats.IdleSince)
+	assert.Equal(t, start.Add(5*time.Minute), stats.Segments[0].StartedAt)
+}
+
+func TestIdleTracker_ClipsSegmentsToRange(t *testing.T) {
+	store := &memoryIdleStore{}
+	tracker := newIdleTracker(store)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	tracker.RecordState("v1", true, start)
+	tracker.RecordState("v1", false, start.Add(10*time.Minute))
+
+	stats, err := tracker.GetIdleStats("v1", start.Add(4*time.Minute), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 360.0, stats.IdleSeconds)
+
+	stats, err = tracker.GetIdleStats("v1", start.Add(time.Hour), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, stats.IdleSeconds)
+	assert.Empty(t, stats.Segments)
+}
+
+func TestIdleTracker_IncludesOpenSegmentAndFlushesIt(t *testing.T) {
+	store := &memoryIdleStore{}
+	tracker := newIdleTracker(store)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start.Add(15 * time.Minute) }
+
+	tracker.RecordState("v1", true, start)
+
+	stats, err := tracker.GetIdleStats("v1", start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 900.0, stats.IdleSeconds)
+	require.NotNil(t, stats.IdleSince)
+	assert.Equal(t, start, *stats.IdleSince)
+	assert.Empty(t, store.segments, "open segment is not persisted until the vehicle leaves idle")
+
+	tracker.Flush(start.Add(20 * time.Minute))
+	require.Len(t, store.segments, 1)
+	assert.Equal(t, 1200.0, store.segments[0].DurationSeconds)
+
+	stats, err = tracker.GetIdleStats("v1", start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, stats.IdleSince)
+	assert.Equal(t, 1200.0, stats.IdleSeconds)
+}
+
+func TestIdleTracker_RejectsEmptyRange(t *testing.T) {
+	tracker := newIdleTracker(&memoryIdleStore{})
+	now := time.Now()
+
+	_, err := tracker.GetIdleStats("v1", now, now)
+	assert.ErrorIs(t, err, ErrInvalidIdleRange)
+}
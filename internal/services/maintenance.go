@@ -1,9 +1,13 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fleet-backend/internal/models"
+	"fmt"
 	"fleet-backend/internal/repository"
+	"fleet-backend/internal/websocket"
+	"fleet-backend/pkg/blobstore"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,15 +16,51 @@ import (
 type MaintenanceService struct {
 	maintenanceRepo *repository.MaintenanceRepository
 	vehicleRepo     *repository.VehicleRepository
+	intervalBounds  ScheduleIntervalBounds
+	forecaster      *MileageForecaster
+	events          EventPublisher
+	recurring       map[string]RecurringScheduleRule
+	suggestedParts  map[string][]string
+	wsManager       websocket.WebSocketManager
+	converter       *CurrencyConverter
+
+	// Attachment contents; nil disables attachments
+	blobs             blobstore.BlobStore
+	maxAttachmentSize int64
 }
 
 func NewMaintenanceService(maintenanceRepo *repository.MaintenanceRepository, vehicleRepo *repository.VehicleRepository) *MaintenanceService {
 	return &MaintenanceService{
 		maintenanceRepo: maintenanceRepo,
 		vehicleRepo:     vehicleRepo,
+		intervalBounds:  DefaultScheduleIntervalBounds(),
 	}
 }
 
+// SetScheduleIntervalBounds sets the sane range for maintenance schedule intervals
+func (s *MaintenanceService) SetScheduleIntervalBounds(bounds ScheduleIntervalBounds) {
+	s.intervalBounds = bounds
+}
+
+// SetEventPublisher sets where maintenance events are published
+func (s *MaintenanceService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// publishRecordEvent publishes a maintenance record event
+func (s *MaintenanceService) publishRecordEvent(eventType string, record *models.MaintenanceRecord) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(newWebhookEvent(eventType, record.VehicleID.Hex(), map[string]interface{}{
+		"recordId":    record.ID.Hex(),
+		"types":       record.Types,
+		"status":      record.Status,
+		"odometer":    record.Odometer,
+		"performedAt": record.PerformedAt,
+	}))
+}
+
 // Maintenance Records
 type CreateMaintenanceRequest struct {
 	VehicleID       string    `json:"vehicleId" validate:"required"`
@@ -33,8 +73,12 @@ type CreateMaintenanceRequest struct {
 	Odometer        int       `json:"odometer" validate:"min=0"`
 	ServiceInterval *int      `json:"serviceInterval,omitempty"` // Optional custom interval
 	PartsReplaced   []string  `json:"partsReplaced"`
+	Replacements    []models.PartReplacement `json:"replacements,omitempty" validate:"omitempty,dive"`
 	Notes           string    `json:"notes,omitempty"`
 	Status          string    `json:"status" validate:"required"`
+	// SuggestParts fills in the parts usually replaced for the record's types
+	// when the request lists no parts of its own
+	SuggestParts bool `json:"suggestParts,omitempty"`
 }
 
 type UpdateMaintenanceRequest struct {
@@ -48,13 +92,14 @@ type UpdateMaintenanceRequest struct {
 	NextServiceOdometer *int       `json:"nextServiceOdometer,omitempty"`
 	NextServiceDate     *time.Time `json:"nextServiceDate,omitempty"`
 	PartsReplaced       []string   `json:"partsReplaced,omitempty"`
+	Replacements        []models.PartReplacement `json:"replacements,omitempty" validate:"omitempty,dive"`
 	Notes               string     `json:"notes,omitempty"`
 	Status              string     `json:"status,omitempty"`
 }
 
-func (s *MaintenanceService) CreateMaintenanceRecord(req *CreateMaintenanceRequest) (*models.MaintenanceRecord, error) {
+func (s *MaintenanceService) CreateMaintenanceRecord(ctx context.Context, req *CreateMaintenanceRequest) (*models.MaintenanceRecord, error) {
 	// Validate vehicle exists
-	vehicle, err := s.vehicleRepo.FindByID(req.VehicleID)
+	vehicle, err := s.vehicleRepo.FindByID(ctx, req.VehicleID)
 	if err != nil {
 		return nil, errors.New("vehicle not found")
 	}
@@ -71,7 +116,7 @@ func (s *MaintenanceService) CreateMaintenanceRecord(req *CreateMaintenanceReque
 	nextServiceOdometer := req.Odometer + serviceInterval
 
 	// Estimate next service date based on vehicle's average daily mileage
-	nextServiceDate := s.estimateNextServiceDate(vehicle, req.Odometer, nextServiceOdometer)
+	nextServiceDate := s.estimateNextServiceDate(ctx, vehicle, req.Odometer, nextServiceOdometer)
 
 	record := &models.MaintenanceRecord{
 		VehicleID:           vehicleObjectID,
@@ -85,45 +130,137 @@ func (s *MaintenanceService) CreateMaintenanceRecord(req *CreateMaintenanceReque
 		ServiceInterval:     serviceInterval,
 		NextServiceOdometer: nextServiceOdometer,
 		NextServiceDate:     nextServiceDate,
-		PartsReplaced:       req.PartsReplaced,
+		PartsReplaced:       withReplacedPartNames(s.partsForRecord(req), req.Replacements),
+		Replacements:        req.Replacements,
 		Notes:               req.Notes,
 		Status:              req.Status,
 	}
 
-	err = s.maintenanceRepo.Create(record)
+	if err := s.recordExchangeRate(ctx, record); err != nil {
+		return nil, err
+	}
+
+	err = s.logMaintenanceRecord(ctx, s.maintenanceRepo, record, func(dueOdometer int) *time.Time {
+		return s.estimateNextServiceDate(ctx, vehicle, req.Odometer, dueOdometer)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Create service reminder
-	s.createServiceReminder(req.VehicleID, req.Types, nextServiceDate, &nextServiceOdometer, req.Odometer)
+	s.createServiceReminder(ctx, req.VehicleID, req.Types, nextServiceDate, &nextServiceOdometer, req.Odometer)
+
+	s.publishRecordEvent(models.EventMaintenanceCreated, record)
+	if record.Status == "completed" {
+		s.publishRecordEvent(models.EventMaintenanceCompleted, record)
+	}
 
 	return record, nil
 }
 
-func (s *MaintenanceService) GetMaintenanceRecord(id string) (*models.MaintenanceRecord, error) {
-	return s.maintenanceRepo.FindByID(id)
+func (s *MaintenanceService) GetMaintenanceRecord(ctx context.Context, id string) (*models.MaintenanceRecord, error) {
+	record, err := s.maintenanceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, maintenanceRecordLookupError(err)
+	}
+	return record, nil
 }
 
-func (s *MaintenanceService) GetMaintenanceRecordsByVehicle(vehicleID string) ([]*models.MaintenanceRecord, error) {
+// Maintenance record listing page sizes
+const (
+	DefaultMaintenancePageLimit = 10
+	MaxMaintenancePageLimit     = 100
+)
+
+// ErrInvalidPagination is returned for a negative limit or offset
+var ErrInvalidPagination = newDomainError(ErrValidation, "limit and offset must not be negative")
+
+// MaintenanceRecordPage is one page of a maintenance record listing
+type MaintenanceRecordPage struct {
+	Records []*models.MaintenanceRecord `json:"records"`
+	Total   int64                       `json:"total"`
+	Limit   int                         `json:"limit"`
+	Offset  int                         `json:"offset"`
+	HasMore bool                        `json:"hasMore"`
+}
+
+// maintenanceRecordPager is the subset of the maintenance repository used by
+// record listings
+type maintenanceRecordPager interface {
+	FindAll(ctx context.Context, limit, offset int) ([]*models.MaintenanceRecord, error)
+	CountAll(ctx context.Context) (int64, error)
+	FindPageByVehicleID(ctx context.Context, vehicleID string, limit, offset int) ([]*models.MaintenanceRecord, error)
+	CountByVehicle(ctx context.Context, vehicleID string) (int64, error)
+}
+
+// GetMaintenanceRecordsByVehicle returns a page of the vehicle's maintenance
+// records, most recent first, with the vehicle's total record count
+func (s *MaintenanceService) GetMaintenanceRecordsByVehicle(ctx context.Context, vehicleID string, limit, offset int) (*MaintenanceRecordPage, error) {
 	// Validate vehicle exists
-	_, err := s.vehicleRepo.FindByID(vehicleID)
+	_, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return nil, errors.New("vehicle not found")
 	}
 
-	return s.maintenanceRepo.FindByVehicleID(vehicleID)
+	return listMaintenanceRecords(ctx, s.maintenanceRepo, vehicleID, limit, offset)
 }
 
-func (s *MaintenanceService) GetAllMaintenanceRecords(limit, offset int) ([]*models.MaintenanceRecord, error) {
-	return s.maintenanceRepo.FindAll(limit, offset)
+// GetAllMaintenanceRecords returns a page of all maintenance records, most
+// recent first, with the total record count
+func (s *MaintenanceService) GetAllMaintenanceRecords(ctx context.Context, limit, offset int) (*MaintenanceRecordPage, error) {
+	return listMaintenanceRecords(ctx, s.maintenanceRepo, "", limit, offset)
+}
+
+// listMaintenanceRecords pages through the records of a vehicle, or of all
+// vehicles when vehicleID is empty. A zero limit uses the default and larger
+// limits are clamped to the maximum.
+func listMaintenanceRecords(ctx context.Context, pager maintenanceRecordPager, vehicleID string, limit, offset int) (*MaintenanceRecordPage, error) {
+	if limit < 0 || offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+	if limit == 0 {
+		limit = DefaultMaintenancePageLimit
+	}
+	if limit > MaxMaintenancePageLimit {
+		limit = MaxMaintenancePageLimit
+	}
+
+	var records []*models.MaintenanceRecord
+	var total int64
+	var err error
+	if vehicleID != "" {
+		records, err = pager.FindPageByVehicleID(ctx, vehicleID, limit, offset)
+		if err == nil {
+			total, err = pager.CountByVehicle(ctx, vehicleID)
+		}
+	} else {
+		records, err = pager.FindAll(ctx, limit, offset)
+		if err == nil {
+			total, err = pager.CountAll(ctx)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*models.MaintenanceRecord{}
+	}
+
+	return &MaintenanceRecordPage{
+		Records: records,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: int64(offset+len(records)) < total,
+	}, nil
 }
 
-func (s *MaintenanceService) UpdateMaintenanceRecord(id string, req *UpdateMaintenanceRequest) (*models.MaintenanceRecord, error) {
-	record, err := s.maintenanceRepo.FindByID(id)
+func (s *MaintenanceService) UpdateMaintenanceRecord(ctx context.Context, id string, req *UpdateMaintenanceRequest) (*models.MaintenanceRecord, error) {
+	record, err := s.maintenanceRepo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("maintenance record not found")
+		return nil, maintenanceRecordLookupError(err)
 	}
+	previousStatus := record.Status
 
 	// Update fields if provided
 	if len(req.Types) > 0 {
@@ -156,6 +293,10 @@ func (s *MaintenanceService) UpdateMaintenanceRecord(id string, req *UpdateMaint
 	if req.PartsReplaced != nil {
 		record.PartsReplaced = req.PartsReplaced
 	}
+	if req.Replacements != nil {
+		record.Replacements = req.Replacements
+		record.PartsReplaced = withReplacedPartNames(record.PartsReplaced, req.Replacements)
+	}
 	if req.Notes != "" {
 		record.Notes = req.Notes
 	}
@@ -163,30 +304,85 @@ func (s *MaintenanceService) UpdateMaintenanceRecord(id string, req *UpdateMaint
 		record.Status = req.Status
 	}
 
-	err = s.maintenanceRepo.Update(id, record)
+	// The rate follows the currency and date of the service
+	if req.Currency != "" || req.PerformedAt != nil {
+		if err := s.recordExchangeRate(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.maintenanceRepo.Update(ctx, id, record)
 	if err != nil {
 		return nil, err
 	}
 
+	if record.Status == "completed" && previousStatus != "completed" {
+		s.publishRecordEvent(models.EventMaintenanceCompleted, record)
+
+		var estimate func(int) *time.Time
+		if vehicle, err := s.vehicleRepo.FindByID(ctx, record.VehicleID.Hex()); err == nil {
+			estimate = func(dueOdometer int) *time.Time {
+				return s.estimateNextServiceDate(ctx, vehicle, record.Odometer, dueOdometer)
+			}
+		}
+		s.maintainRecurringSchedules(ctx, s.maintenanceRepo, record, estimate)
+	}
+
 	return record, nil
 }
 
-func (s *MaintenanceService) DeleteMaintenanceRecord(id string) error {
-	_, err := s.maintenanceRepo.FindByID(id)
+func (s *MaintenanceService) DeleteMaintenanceRecord(ctx context.Context, id string) error {
+	record, err := s.maintenanceRepo.FindByID(ctx, id)
 	if err != nil {
-		return errors.New("maintenance record not found")
+		return maintenanceRecordLookupError(err)
 	}
 
-	return s.maintenanceRepo.Delete(id)
+	if err := s.maintenanceRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.deleteAttachmentBlobs(record)
+	return nil
 }
 
 // Maintenance Schedules
+
+// ErrInvalidScheduleInterval is returned for schedule intervals outside the configured bounds
+var ErrInvalidScheduleInterval = errors.New("invalid maintenance schedule interval")
+
+// ScheduleIntervalBounds is the sane range for schedule intervals. Intervals
+// that are too short generate constant reminders; intervals that are too long
+// never fire.
+type ScheduleIntervalBounds struct {
+	MinKm   int
+	MaxKm   int
+	MinDays int
+	MaxDays int
+}
+
+// DefaultScheduleIntervalBounds allows 500-200,000 km and 7 days to 5 years
+func DefaultScheduleIntervalBounds() ScheduleIntervalBounds {
+	return ScheduleIntervalBounds{MinKm: 500, MaxKm: 200000, MinDays: 7, MaxDays: 1825}
+}
+
+// Validate checks a schedule's intervals; intervalDays is optional
+func (b ScheduleIntervalBounds) Validate(intervalKm int, intervalDays *int) error {
+	if intervalKm < b.MinKm || intervalKm > b.MaxKm {
+		return fmt.Errorf("%w: intervalKm must be between %d and %d km, got %d",
+			ErrInvalidScheduleInterval, b.MinKm, b.MaxKm, intervalKm)
+	}
+	if intervalDays != nil && (*intervalDays < b.MinDays || *intervalDays > b.MaxDays) {
+		return fmt.Errorf("%w: intervalDays must be between %d and %d days, got %d",
+			ErrInvalidScheduleInterval, b.MinDays, b.MaxDays, *intervalDays)
+	}
+	return nil
+}
+
 type CreateScheduleRequest struct {
 	VehicleID           string    `json:"vehicleId" validate:"required"`
 	Types               []string  `json:"types" validate:"required,min=1"`
 	Description         string    `json:"description" validate:"required"`
 	IntervalKm          int       `json:"intervalKm" validate:"required,min=1"`
-	IntervalDays        *int      `json:"intervalDays,omitempty"`
+	IntervalDays        *int      `json:"intervalDays,omitempty" validate:"omitempty,min=1"`
 	LastServiceOdometer int       `json:"lastServiceOdometer" validate:"required,min=0"`
 	LastServiceDate     time.Time `json:"lastServiceDate" validate:"required"`
 	ServiceCenterName   string    `json:"serviceCenterName" validate:"required"`
@@ -194,17 +390,21 @@ type CreateScheduleRequest struct {
 
 type UpdateScheduleRequest struct {
 	Description         string     `json:"description,omitempty"`
-	IntervalKm          *int       `json:"intervalKm,omitempty"`
-	IntervalDays        *int       `json:"intervalDays,omitempty"`
+	IntervalKm          *int       `json:"intervalKm,omitempty" validate:"omitempty,min=1"`
+	IntervalDays        *int       `json:"intervalDays,omitempty" validate:"omitempty,min=1"`
 	LastServiceOdometer *int       `json:"lastServiceOdometer,omitempty"`
 	LastServiceDate     *time.Time `json:"lastServiceDate,omitempty"`
 	ServiceCenterName   string     `json:"serviceCenterName,omitempty"`
 	IsActive            *bool      `json:"isActive,omitempty"`
 }
 
-func (s *MaintenanceService) CreateSchedule(req *CreateScheduleRequest) (*models.MaintenanceSchedule, error) {
+func (s *MaintenanceService) CreateSchedule(ctx context.Context, req *CreateScheduleRequest) (*models.MaintenanceSchedule, error) {
+	if err := s.intervalBounds.Validate(req.IntervalKm, req.IntervalDays); err != nil {
+		return nil, err
+	}
+
 	// Validate vehicle exists
-	vehicle, err := s.vehicleRepo.FindByID(req.VehicleID)
+	vehicle, err := s.vehicleRepo.FindByID(ctx, req.VehicleID)
 	if err != nil {
 		return nil, errors.New("vehicle not found")
 	}
@@ -224,7 +424,7 @@ func (s *MaintenanceService) CreateSchedule(req *CreateScheduleRequest) (*models
 		nextServiceDate = &estimatedDate
 	} else {
 		// Estimate based on vehicle usage patterns
-		nextServiceDate = s.estimateNextServiceDate(vehicle, req.LastServiceOdometer, nextServiceOdometer)
+		nextServiceDate = s.estimateNextServiceDate(ctx, vehicle, req.LastServiceOdometer, nextServiceOdometer)
 	}
 
 	schedule := &models.MaintenanceSchedule{
@@ -241,38 +441,161 @@ func (s *MaintenanceService) CreateSchedule(req *CreateScheduleRequest) (*models
 		IsActive:            true,
 	}
 
-	err = s.maintenanceRepo.CreateSchedule(schedule)
+	err = s.maintenanceRepo.CreateSchedule(ctx, schedule)
 	if err != nil {
 		return nil, err
 	}
+	s.broadcastScheduleCreated(schedule)
 
 	return schedule, nil
 }
 
-func (s *MaintenanceService) GetSchedulesByVehicle(vehicleID string) ([]*models.MaintenanceSchedule, error) {
+func (s *MaintenanceService) GetSchedulesByVehicle(ctx context.Context, vehicleID string) ([]*models.MaintenanceSchedule, error) {
 	// Validate vehicle exists
-	_, err := s.vehicleRepo.FindByID(vehicleID)
+	_, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return nil, errors.New("vehicle not found")
 	}
 
-	return s.maintenanceRepo.FindSchedulesByVehicleID(vehicleID)
+	return s.maintenanceRepo.FindSchedulesByVehicleID(ctx, vehicleID)
+}
+
+func (s *MaintenanceService) GetUpcomingSchedules(ctx context.Context, days int) ([]*models.MaintenanceSchedule, error) {
+	return s.maintenanceRepo.FindUpcomingSchedules(ctx, days)
+}
+
+func (s *MaintenanceService) GetAllSchedules(ctx context.Context) ([]*models.MaintenanceSchedule, error) {
+	return s.maintenanceRepo.FindAllSchedules(ctx)
+}
+
+// GetUnservicedSchedules returns active schedules that have no completed
+// record of any of their maintenance types, or whose last service date
+// predates the vehicle's commissioning
+func (s *MaintenanceService) GetUnservicedSchedules(ctx context.Context) ([]*models.UnservicedSchedule, error) {
+	return unservicedSchedules(ctx, s.maintenanceRepo, s.vehicleRepo)
+}
+
+// scheduleHistoryFinder is the subset of the maintenance repository used to
+// join schedules to their service records
+type scheduleHistoryFinder interface {
+	FindActiveSchedules(ctx context.Context) ([]*models.MaintenanceSchedule, error)
+	FindCompletedByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) ([]*models.MaintenanceRecord, error)
 }
 
-func (s *MaintenanceService) GetUpcomingSchedules(days int) ([]*models.MaintenanceSchedule, error) {
-	return s.maintenanceRepo.FindUpcomingSchedules(days)
+func unservicedSchedules(ctx context.Context, history scheduleHistoryFinder, vehicles vehicleBatchFinder) ([]*models.UnservicedSchedule, error) {
+	schedules, err := history.FindActiveSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return []*models.UnservicedSchedule{}, nil
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	var vehicleIDs []primitive.ObjectID
+	var vehicleHexIDs []string
+	for _, schedule := range schedules {
+		if !seen[schedule.VehicleID] {
+			seen[schedule.VehicleID] = true
+			vehicleIDs = append(vehicleIDs, schedule.VehicleID)
+			vehicleHexIDs = append(vehicleHexIDs, schedule.VehicleID.Hex())
+		}
+	}
+
+	records, err := history.FindCompletedByVehicleIDs(ctx, vehicleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet, err := vehicles.FindByIDs(ctx, vehicleHexIDs)
+	if err != nil {
+		return nil, err
+	}
+	commissioned := make(map[primitive.ObjectID]time.Time, len(fleet))
+	for _, vehicle := range fleet {
+		commissioned[vehicle.ID] = vehicle.CreatedAt
+	}
+
+	return findUnservicedSchedules(schedules, records, commissioned), nil
 }
 
-func (s *MaintenanceService) GetAllSchedules() ([]*models.MaintenanceSchedule, error) {
-	return s.maintenanceRepo.FindAllSchedules()
+// findUnservicedSchedules joins schedules to completed records of the same
+// vehicle sharing at least one maintenance type. commissioned holds each
+// vehicle's commissioning time; vehicles missing from it are only checked for records.
+func findUnservicedSchedules(schedules []*models.MaintenanceSchedule, records []*models.MaintenanceRecord, commissioned map[primitive.ObjectID]time.Time) []*models.UnservicedSchedule {
+	servicedTypes := make(map[primitive.ObjectID]map[string]bool)
+	for _, record := range records {
+		if record.Status != models.MaintenanceStatusCompleted {
+			continue
+		}
+		types := servicedTypes[record.VehicleID]
+		if types == nil {
+			types = make(map[string]bool)
+			servicedTypes[record.VehicleID] = types
+		}
+		for _, maintenanceType := range record.Types {
+			types[maintenanceType] = true
+		}
+	}
+
+	unserviced := []*models.UnservicedSchedule{}
+	for _, schedule := range schedules {
+		if !schedule.IsActive {
+			continue
+		}
+
+		var reasons []string
+		hasRecord := false
+		for _, maintenanceType := range schedule.Types {
+			if servicedTypes[schedule.VehicleID][maintenanceType] {
+				hasRecord = true
+				break
+			}
+		}
+		if !hasRecord {
+			reasons = append(reasons, models.UnservicedReasonNoRecords)
+		}
+
+		var commissionedAt *time.Time
+		if createdAt, ok := commissioned[schedule.VehicleID]; ok && !createdAt.IsZero() {
+			commissionedAt = &createdAt
+			if schedule.LastServiceDate.Before(createdAt) {
+				reasons = append(reasons, models.UnservicedReasonPredatesCommissioning)
+			}
+		}
+
+		if len(reasons) > 0 {
+			unserviced = append(unserviced, &models.UnservicedSchedule{
+				Schedule:              schedule,
+				Reasons:               reasons,
+				VehicleCommissionedAt: commissionedAt,
+			})
+		}
+	}
+
+	return unserviced
 }
 
-func (s *MaintenanceService) UpdateSchedule(id string, req *UpdateScheduleRequest) (*models.MaintenanceSchedule, error) {
-	schedule, err := s.maintenanceRepo.FindScheduleByID(id)
+func (s *MaintenanceService) UpdateSchedule(ctx context.Context, id string, req *UpdateScheduleRequest) (*models.MaintenanceSchedule, error) {
+	schedule, err := s.maintenanceRepo.FindScheduleByID(ctx, id)
 	if err != nil {
 		return nil, errors.New("maintenance schedule not found")
 	}
 
+	// Validate changed intervals; legacy schedules stay editable otherwise
+	if req.IntervalKm != nil || req.IntervalDays != nil {
+		intervalKm, intervalDays := schedule.IntervalKm, schedule.IntervalDays
+		if req.IntervalKm != nil {
+			intervalKm = *req.IntervalKm
+		}
+		if req.IntervalDays != nil {
+			intervalDays = req.IntervalDays
+		}
+		if err := s.intervalBounds.Validate(intervalKm, intervalDays); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields if provided
 	if req.Description != "" {
 		schedule.Description = req.Description
@@ -305,7 +628,7 @@ func (s *MaintenanceService) UpdateSchedule(id string, req *UpdateScheduleReques
 		schedule.IsActive = *req.IsActive
 	}
 
-	err = s.maintenanceRepo.UpdateSchedule(id, schedule)
+	err = s.maintenanceRepo.UpdateSchedule(ctx, id, schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -313,46 +636,46 @@ func (s *MaintenanceService) UpdateSchedule(id string, req *UpdateScheduleReques
 	return schedule, nil
 }
 
-func (s *MaintenanceService) DeleteSchedule(id string) error {
-	_, err := s.maintenanceRepo.FindScheduleByID(id)
+func (s *MaintenanceService) DeleteSchedule(ctx context.Context, id string) error {
+	_, err := s.maintenanceRepo.FindScheduleByID(ctx, id)
 	if err != nil {
 		return errors.New("maintenance schedule not found")
 	}
 
-	return s.maintenanceRepo.DeleteSchedule(id)
+	return s.maintenanceRepo.DeleteSchedule(ctx, id)
 }
 
-func (s *MaintenanceService) GetSchedule(id string) (*models.MaintenanceSchedule, error) {
-	return s.maintenanceRepo.FindScheduleByID(id)
+func (s *MaintenanceService) GetSchedule(ctx context.Context, id string) (*models.MaintenanceSchedule, error) {
+	return s.maintenanceRepo.FindScheduleByID(ctx, id)
 }
 
 // Service Reminders
-func (s *MaintenanceService) GetServiceReminders(vehicleID string) ([]*models.ServiceReminder, error) {
+func (s *MaintenanceService) GetServiceReminders(ctx context.Context, vehicleID string) ([]*models.ServiceReminder, error) {
 	// Validate vehicle exists
-	_, err := s.vehicleRepo.FindByID(vehicleID)
+	_, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
 		return nil, errors.New("vehicle not found")
 	}
 
-	reminders, err := s.maintenanceRepo.FindRemindersByVehicleID(vehicleID)
+	reminders, err := s.maintenanceRepo.FindRemindersByVehicleID(ctx, vehicleID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update reminder status
 	for _, reminder := range reminders {
-		s.updateReminderStatus(reminder)
+		s.refreshReminderStatus(ctx, s.maintenanceRepo, reminder)
 	}
 
 	return reminders, nil
 }
 
-func (s *MaintenanceService) GetOverdueReminders() ([]*models.ServiceReminder, error) {
-	return s.maintenanceRepo.FindOverdueReminders()
+func (s *MaintenanceService) GetOverdueReminders(ctx context.Context) ([]*models.ServiceReminder, error) {
+	return s.maintenanceRepo.FindOverdueReminders(ctx)
 }
 
 // Helper functions
-func (s *MaintenanceService) createServiceReminder(vehicleID string, maintenanceTypes []string, nextServiceDate *time.Time, nextServiceOdometer *int, currentOdometer int) error {
+func (s *MaintenanceService) createServiceReminder(ctx context.Context, vehicleID string, maintenanceTypes []string, nextServiceDate *time.Time, nextServiceOdometer *int, currentOdometer int) error {
 	vehicleObjectID, err := primitive.ObjectIDFromHex(vehicleID)
 	if err != nil {
 		return err
@@ -369,7 +692,13 @@ func (s *MaintenanceService) createServiceReminder(vehicleID string, maintenance
 	}
 
 	s.updateReminderStatus(reminder)
-	return s.maintenanceRepo.CreateReminder(reminder)
+	if err := s.maintenanceRepo.CreateReminder(ctx, reminder); err != nil {
+		return err
+	}
+	if reminder.IsOverdue {
+		s.broadcastReminderOverdue(reminder)
+	}
+	return nil
 }
 
 func (s *MaintenanceService) updateReminderStatus(reminder *models.ServiceReminder) {
@@ -454,16 +783,19 @@ func (s *MaintenanceService) getServiceIntervalForTypes(maintenanceTypes []strin
 }
 
 // estimateNextServiceDate estimates when the next service will be due based on vehicle usage patterns
-func (s *MaintenanceService) estimateNextServiceDate(vehicle *models.Vehicle, currentOdometer, nextServiceOdometer int) *time.Time {
+func (s *MaintenanceService) estimateNextServiceDate(ctx context.Context, vehicle *models.Vehicle, currentOdometer, nextServiceOdometer int) *time.Time {
 	// Calculate kilometers until next service
 	kmUntilService := nextServiceOdometer - currentOdometer
 	
 	// Get vehicle's maintenance history to calculate average daily mileage
-	avgDailyMileage := s.calculateAverageDailyMileage(vehicle.ID.Hex())
+	avgDailyMileage := s.calculateAverageDailyMileage(ctx, vehicle.ID.Hex())
+
+	// Blend in live odometer growth from telemetry when available
+	avgDailyMileage = s.forecaster.BlendDailyMileage(vehicle.ID.Hex(), avgDailyMileage, time.Now())
 	
 	// If we can't calculate average, use a default (50 km/day for fleet vehicles)
 	if avgDailyMileage <= 0 {
-		avgDailyMileage = 50
+		avgDailyMileage = defaultDailyMileageKm
 	}
 	
 	// Calculate estimated days until next service
@@ -477,10 +809,19 @@ func (s *MaintenanceService) estimateNextServiceDate(vehicle *models.Vehicle, cu
 }
 
 // calculateAverageDailyMileage calculates the vehicle's average daily mileage based on maintenance history
-func (s *MaintenanceService) calculateAverageDailyMileage(vehicleID string) float64 {
+func (s *MaintenanceService) calculateAverageDailyMileage(ctx context.Context, vehicleID string) float64 {
 	// Get maintenance records for this vehicle
-	records, err := s.maintenanceRepo.FindByVehicleID(vehicleID)
-	if err != nil || len(records) < 2 {
+	records, err := s.maintenanceRepo.FindByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return 0
+	}
+	return averageDailyMileage(records)
+}
+
+// averageDailyMileage calculates the average daily mileage between maintenance
+// records ordered most recent first
+func averageDailyMileage(records []*models.MaintenanceRecord) float64 {
+	if len(records) < 2 {
 		return 0 // Not enough data
 	}
 
@@ -510,9 +851,36 @@ func (s *MaintenanceService) calculateAverageDailyMileage(vehicleID string) floa
 }
 
 // GetNextServiceDue returns vehicles that are due or approaching their next service
-func (s *MaintenanceService) GetNextServiceDue(thresholdKm int) ([]*models.ServiceReminder, error) {
+func (s *MaintenanceService) GetNextServiceDue(ctx context.Context, thresholdKm int) ([]*models.ServiceReminder, error) {
+	return s.nextServiceDue(ctx, s.vehicleRepo, s.maintenanceRepo, thresholdKm)
+}
+
+// fleetLister is the subset of the vehicle repository used to scan the fleet
+type fleetLister interface {
+	FindAll(ctx context.Context) ([]*models.Vehicle, error)
+}
+
+// latestRecordFinder is the subset of the maintenance repository that fetches
+// the latest maintenance record of many vehicles at once
+type latestRecordFinder interface {
+	FindLatestByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) (map[string]*models.MaintenanceRecord, error)
+}
+
+// nextServiceDue fetches the latest record of every vehicle in one query rather
+// than one query per vehicle
+func (s *MaintenanceService) nextServiceDue(ctx context.Context, fleet fleetLister, records latestRecordFinder, thresholdKm int) ([]*models.ServiceReminder, error) {
 	// Get all vehicles
-	vehicles, err := s.vehicleRepo.FindAll()
+	vehicles, err := fleet.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleIDs := make([]primitive.ObjectID, len(vehicles))
+	for i, vehicle := range vehicles {
+		vehicleIDs[i] = vehicle.ID
+	}
+
+	latestRecords, err := records.FindLatestByVehicleIDs(ctx, vehicleIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -520,13 +888,8 @@ func (s *MaintenanceService) GetNextServiceDue(thresholdKm int) ([]*models.Servi
 	var dueReminders []*models.ServiceReminder
 	
 	for _, vehicle := range vehicles {
-		// Get latest maintenance record for each vehicle
-		records, err := s.maintenanceRepo.FindByVehicleID(vehicle.ID.Hex())
-		if err != nil {
-			continue
-		}
-		
-		if len(records) == 0 {
+		latestRecord, ok := latestRecords[vehicle.ID.Hex()]
+		if !ok {
 			// No maintenance history - vehicle might need initial service
 			reminder := &models.ServiceReminder{
 				VehicleID:       vehicle.ID,
@@ -539,9 +902,6 @@ func (s *MaintenanceService) GetNextServiceDue(thresholdKm int) ([]*models.Servi
 			continue
 		}
 		
-		// Check each maintenance type for this vehicle
-		latestRecord := records[0] // Assuming sorted by date desc
-		
 		// Calculate if service is due based on current odometer vs next service odometer
 		if vehicle.Odometer >= latestRecord.NextServiceOdometer-thresholdKm {
 			kmUntilService := latestRecord.NextServiceOdometer - vehicle.Odometer
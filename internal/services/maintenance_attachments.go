@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/blobstore"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultMaxAttachmentSize is the largest attachment accepted when no limit is configured
+const DefaultMaxAttachmentSize int64 = 10 << 20 // 10 MB
+
+// Errors returned for maintenance record attachments
+var (
+	ErrAttachmentsDisabled = errors.New("maintenance attachments are not enabled")
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrAttachmentTooLarge  = errors.New("attachment is too large")
+)
+
+// attachmentRecordStore is the subset of the maintenance repository used for attachments
+type attachmentRecordStore interface {
+	FindByID(ctx context.Context, id string) (*models.MaintenanceRecord, error)
+	AddAttachment(ctx context.Context, recordID string, attachment *models.Attachment) error
+}
+
+// SetBlobStore enables attachments on maintenance records, storing their
+// contents in store. Attachments larger than maxSize bytes are rejected;
+// maxSize <= 0 uses DefaultMaxAttachmentSize.
+func (s *MaintenanceService) SetBlobStore(store blobstore.BlobStore, maxSize int64) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxAttachmentSize
+	}
+	s.blobs = store
+	s.maxAttachmentSize = maxSize
+}
+
+// MaxAttachmentSize returns the largest attachment accepted, in bytes
+func (s *MaintenanceService) MaxAttachmentSize() int64 {
+	return s.maxAttachmentSize
+}
+
+// AddAttachment stores a supporting document for a maintenance record
+func (s *MaintenanceService) AddAttachment(ctx context.Context, recordID, filename, contentType string, content io.Reader) (*models.Attachment, error) {
+	return s.addAttachment(ctx, s.maintenanceRepo, recordID, filename, contentType, content)
+}
+
+func (s *MaintenanceService) addAttachment(ctx context.Context, records attachmentRecordStore, recordID, filename, contentType string, content io.Reader) (*models.Attachment, error) {
+	if s.blobs == nil {
+		return nil, ErrAttachmentsDisabled
+	}
+	if _, err := records.FindByID(ctx, recordID); err != nil {
+		return nil, maintenanceRecordLookupError(err)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment := &models.Attachment{
+		ID:          primitive.NewObjectID(),
+		Filename:    filename,
+		ContentType: contentType,
+		UploadedAt:  time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("maintenance/%s/%s", recordID, attachment.ID.Hex())
+
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	counted := &countingReader{reader: io.LimitReader(content, s.maxAttachmentSize+1)}
+	if err := s.blobs.Put(attachment.StorageKey, counted, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if counted.n > s.maxAttachmentSize {
+		s.deleteBlob(attachment.StorageKey)
+		return nil, ErrAttachmentTooLarge
+	}
+	attachment.Size = counted.n
+
+	if err := records.AddAttachment(ctx, recordID, attachment); err != nil {
+		s.deleteBlob(attachment.StorageKey)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// GetAttachments lists the attachments of a maintenance record
+func (s *MaintenanceService) GetAttachments(ctx context.Context, recordID string) ([]models.Attachment, error) {
+	record, err := s.maintenanceRepo.FindByID(ctx, recordID)
+	if err != nil {
+		return nil, maintenanceRecordLookupError(err)
+	}
+	if record.Attachments == nil {
+		return []models.Attachment{}, nil
+	}
+	return record.Attachments, nil
+}
+
+// OpenAttachment returns an attachment's metadata and its contents, which the caller closes
+func (s *MaintenanceService) OpenAttachment(ctx context.Context, recordID, attachmentID string) (*models.Attachment, io.ReadCloser, error) {
+	return s.openAttachment(ctx, s.maintenanceRepo, recordID, attachmentID)
+}
+
+func (s *MaintenanceService) openAttachment(ctx context.Context, records attachmentRecordStore, recordID, attachmentID string) (*models.Attachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return nil, nil, ErrAttachmentsDisabled
+	}
+	record, err := records.FindByID(ctx, recordID)
+	if err != nil {
+		return nil, nil, maintenanceRecordLookupError(err)
+	}
+
+	for i := range record.Attachments {
+		attachment := &record.Attachments[i]
+		if attachment.ID.Hex() != attachmentID {
+			continue
+		}
+
+		content, err := s.blobs.Get(attachment.StorageKey)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		return attachment, content, nil
+	}
+	return nil, nil, ErrAttachmentNotFound
+}
+
+// deleteAttachmentBlobs removes the stored contents of a deleted record's attachments
+func (s *MaintenanceService) deleteAttachmentBlobs(record *models.MaintenanceRecord) {
+	if s.blobs == nil {
+		return
+	}
+	for _, attachment := range record.Attachments {
+		s.deleteBlob(attachment.StorageKey)
+	}
+}
+
+// deleteBlob removes a blob, logging failures since the caller has already failed or finished
+func (s *MaintenanceService) deleteBlob(key string) {
+	if err := s.blobs.Delete(key); err != nil {
+		log.Printf("Failed to delete attachment blob %s: %v", key, err)
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/pkg/blobstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeAttachmentRecords keeps maintenance records in memory
+type fakeAttachmentRecords struct {
+	records map[string]*models.MaintenanceRecord
+	failAdd bool
+}
+
+func newFakeAttachmentRecords(ids ...string) *fakeAttachmentRecords {
+	records := make(map[string]*models.MaintenanceRecord)
+	for _, id := range ids {
+		records[id] = &models.MaintenanceRecord{Types: []string{"oil_change"}}
+	}
+	return &fakeAttachmentRecords{records: records}
+}
+
+func (f *fakeAttachmentRecords) FindByID(_ context.Context, id string) (*models.MaintenanceRecord, error) {
+	record, ok := f.records[id]
+	if !ok {
+		return nil, repository.ErrMaintenanceRecordNotFound
+	}
+	return record, nil
+}
+
+func (f *fakeAttachmentRecords) AddAttachment(_ context.Context, recordID string, attachment *models.Attachment) error {
+	if f.failAdd {
+		return errors.New("database unavailable")
+	}
+	record := f.records[recordID]
+	record.Attachments = append(record.Attachments, *attachment)
+	return nil
+}
+
+func newAttachmentService(t *testing.T, maxSize int64) (*MaintenanceService, *blobstore.LocalStore) {
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	service := &MaintenanceService{}
+	service.SetBlobStore(store, maxSize)
+	return service, store
+}
+
+func TestAddAttachment_UploadThenDownloadReturnsSameBytes(t *testing.T) {
+	service, _ := newAttachmentService(t, 0)
+	recordID := primitive.NewObjectID().Hex()
+	records := newFakeAttachmentRecords(recordID)
+
+	content := []byte("%PDF-1.4 receipt for oil change\x00\x01\x02")
+	attachment, err := service.addAttachment(context.Background(), records, recordID, "receipt.pdf", "application/pdf", bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, "receipt.pdf", attachment.Filename)
+	assert.Equal(t, "application/pdf", attachment.ContentType)
+	assert.Equal(t, int64(len(content)), attachment.Size)
+	assert.Len(t, records.records[recordID].Attachments, 1)
+
+	opened, reader, err := service.openAttachment(context.Background(), records, recordID, attachment.ID.Hex())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	downloaded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	assert.Equal(t, attachment.StorageKey, opened.StorageKey)
+}
+
+func TestAddAttachment_RejectsOversizedFile(t *testing.T) {
+	service, store := newAttachmentService(t, 8)
+	recordID := primitive.NewObjectID().Hex()
+	records := newFakeAttachmentRecords(recordID)
+
+	_, err := service.addAttachment(context.Background(), records, recordID, "photo.jpg", "image/jpeg", bytes.NewReader(make([]byte, 9)))
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	assert.Empty(t, records.records[recordID].Attachments)
+
+	// A file of exactly the limit is accepted
+	attachment, err := service.addAttachment(context.Background(), records, recordID, "photo.jpg", "image/jpeg", bytes.NewReader(make([]byte, 8)))
+	require.NoError(t, err)
+	_, err = store.Get(attachment.StorageKey)
+	assert.NoError(t, err)
+}
+
+func TestAddAttachment_RemovesBlobWhenMetadataFails(t *testing.T) {
+	dir := t.TempDir()
+	store, err := blobstore.NewLocalStore(dir)
+	require.NoError(t, err)
+	service := &MaintenanceService{}
+	service.SetBlobStore(store, 0)
+
+	recordID := primitive.NewObjectID().Hex()
+	records := newFakeAttachmentRecords(recordID)
+	records.failAdd = true
+
+	_, err = service.addAttachment(context.Background(), records, recordID, "receipt.pdf", "", bytes.NewReader([]byte("receipt")))
+	require.Error(t, err)
+
+	var files []string
+	require.NoError(t, filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	}))
+	assert.Empty(t, files)
+}
+
+func TestAttachments_UnknownRecordAndAttachment(t *testing.T) {
+	service, _ := newAttachmentService(t, 0)
+	recordID := primitive.NewObjectID().Hex()
+	records := newFakeAttachmentRecords(recordID)
+
+	_, err := service.addAttachment(context.Background(), records, "missing", "receipt.pdf", "", bytes.NewReader([]byte("receipt")))
+	assert.ErrorIs(t, err, ErrMaintenanceRecordNotFound)
+
+	_, _, err = service.openAttachment(context.Background(), records, recordID, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+}
+
+func TestAttachments_DisabledWithoutBlobStore(t *testing.T) {
+	service := &MaintenanceService{}
+	records := newFakeAttachmentRecords("record")
+
+	_, err := service.addAttachment(context.Background(), records, "record", "receipt.pdf", "", bytes.NewReader([]byte("receipt")))
+	assert.ErrorIs(t, err, ErrAttachmentsDisabled)
+}
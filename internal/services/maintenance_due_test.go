@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingFleet returns a fixed fleet and counts queries
+type countingFleet struct {
+	vehicles []*models.Vehicle
+	calls    int
+}
+
+func (f *countingFleet) FindAll(_ context.Context) ([]*models.Vehicle, error) {
+	f.calls++
+	return f.vehicles, nil
+}
+
+// countingRecords returns the latest record per vehicle and counts queries
+type countingRecords struct {
+	latest map[string]*models.MaintenanceRecord
+	calls  int
+}
+
+func (f *countingRecords) FindLatestByVehicleIDs(_ context.Context, vehicleIDs []primitive.ObjectID) (map[string]*models.MaintenanceRecord, error) {
+	f.calls++
+	result := make(map[string]*models.MaintenanceRecord)
+	for _, id := range vehicleIDs {
+		if record, ok := f.latest[id.Hex()]; ok {
+			result[id.Hex()] = record
+		}
+	}
+	return result, nil
+}
+
+// newServiceDueFleet builds vehicles cycling through four cases: no history,
+// overdue, within the threshold and well clear of the next service
+func newServiceDueFleet(size int) (*countingFleet, *countingRecords) {
+	fleet := &countingFleet{}
+	records := &countingRecords{latest: make(map[string]*models.MaintenanceRecord)}
+
+	for i := 0; i < size; i++ {
+		vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Odometer: 50000}
+		fleet.vehicles = append(fleet.vehicles, vehicle)
+
+		var nextService int
+		switch i % 4 {
+		case 0:
+			continue // no maintenance history
+		case 1:
+			nextService = 49000 // overdue
+		case 2:
+			nextService = 50500 // within threshold
+		case 3:
+			nextService = 60000 // not due
+		}
+		records.latest[vehicle.ID.Hex()] = &models.MaintenanceRecord{
+			VehicleID:           vehicle.ID,
+			Types:               []string{"oil_change"},
+			NextServiceOdometer: nextService,
+		}
+	}
+
+	return fleet, records
+}
+
+func TestMaintenanceService_NextServiceDue(t *testing.T) {
+	service := &MaintenanceService{}
+	fleet, records := newServiceDueFleet(50)
+
+	reminders, err := service.nextServiceDue(context.Background(), fleet, records, 1000)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fleet.calls)
+	assert.Equal(t, 1, records.calls, "latest records should be fetched in one query, not one per vehicle")
+
+	// 13 vehicles without history, 13 overdue and 12 within the threshold
+	require.Len(t, reminders, 38)
+
+	byVehicle := make(map[primitive.ObjectID]*models.ServiceReminder)
+	for _, reminder := range reminders {
+		byVehicle[reminder.VehicleID] = reminder
+	}
+
+	for i, vehicle := range fleet.vehicles {
+		reminder, ok := byVehicle[vehicle.ID]
+		switch i % 4 {
+		case 0:
+			require.True(t, ok)
+			assert.Equal(t, []string{"inspection"}, reminder.Types)
+			assert.Equal(t, models.PriorityMedium, reminder.Priority)
+			assert.Nil(t, reminder.DueOdometer)
+		case 1:
+			require.True(t, ok)
+			assert.True(t, reminder.IsOverdue)
+			assert.Equal(t, -1000, *reminder.OdometerUntilDue)
+			assert.Equal(t, models.PriorityUrgent, reminder.Priority)
+		case 2:
+			require.True(t, ok)
+			assert.False(t, reminder.IsOverdue)
+			assert.Equal(t, 500, *reminder.OdometerUntilDue)
+			assert.Equal(t, models.PriorityHigh, reminder.Priority)
+			assert.Equal(t, []string{"oil_change"}, reminder.Types)
+		case 3:
+			assert.False(t, ok, "vehicle outside the threshold should not be reminded")
+		}
+	}
+}
+
+func BenchmarkMaintenanceService_NextServiceDue(b *testing.B) {
+	service := &MaintenanceService{}
+	fleet, records := newServiceDueFleet(1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.nextServiceDue(context.Background(), fleet, records, 1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
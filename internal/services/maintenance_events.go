@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
+	"fleet-backend/pkg/logger"
+)
+
+// SetWebSocketManager broadcasts new schedules and reminders turning overdue
+// to dashboards subscribed to maintenance events
+func (s *MaintenanceService) SetWebSocketManager(wsManager websocket.WebSocketManager) {
+	s.wsManager = wsManager
+}
+
+// reminderUpdater is the subset of the maintenance repository used to store
+// reminder status changes
+type reminderUpdater interface {
+	UpdateReminder(ctx context.Context, id string, reminder *models.ServiceReminder) error
+}
+
+// refreshReminderStatus recomputes a stored reminder's status. A reminder
+// that has just become overdue is saved as overdue, so the change is
+// broadcast once and the overdue digest picks it up.
+func (s *MaintenanceService) refreshReminderStatus(ctx context.Context, store reminderUpdater, reminder *models.ServiceReminder) {
+	wasOverdue := reminder.IsOverdue
+	s.updateReminderStatus(reminder)
+	if wasOverdue || !reminder.IsOverdue {
+		return
+	}
+
+	if err := store.UpdateReminder(ctx, reminder.ID.Hex(), reminder); err != nil {
+		logger.FromContext(ctx).Error("Failed to mark service reminder overdue", "reminder_id", reminder.ID.Hex(), "error", err)
+		return
+	}
+	s.broadcastReminderOverdue(reminder)
+}
+
+// broadcastScheduleCreated tells subscribed dashboards when a vehicle's next
+// service falls due under a new schedule
+func (s *MaintenanceService) broadcastScheduleCreated(schedule *models.MaintenanceSchedule) {
+	if s.wsManager == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"scheduleId":          schedule.ID.Hex(),
+		"types":               schedule.Types,
+		"description":         schedule.Description,
+		"nextServiceOdometer": schedule.NextServiceOdometer,
+	}
+	if schedule.NextServiceDate != nil {
+		data["nextServiceDate"] = *schedule.NextServiceDate
+	}
+
+	s.broadcastMaintenanceEvent(schedule.VehicleID.Hex(), websocket.VehicleUpdate{
+		UpdateType: websocket.UpdateTypeMaintenanceDue,
+		Data:       data,
+		Priority:   websocket.PriorityMedium,
+	})
+}
+
+// broadcastReminderOverdue tells subscribed dashboards a service is overdue
+func (s *MaintenanceService) broadcastReminderOverdue(reminder *models.ServiceReminder) {
+	if s.wsManager == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"reminderId":       reminder.ID.Hex(),
+		"types":            reminder.Types,
+		"currentOdometer":  reminder.CurrentOdometer,
+		"reminderPriority": reminder.Priority,
+	}
+	if reminder.DueDate != nil {
+		data["dueDate"] = *reminder.DueDate
+	}
+	if reminder.DueOdometer != nil {
+		data["dueOdometer"] = *reminder.DueOdometer
+	}
+
+	s.broadcastMaintenanceEvent(reminder.VehicleID.Hex(), websocket.VehicleUpdate{
+		UpdateType: websocket.UpdateTypeReminderOverdue,
+		Data:       data,
+		Priority:   websocket.PriorityHigh,
+	})
+}
+
+func (s *MaintenanceService) broadcastMaintenanceEvent(vehicleID string, update websocket.VehicleUpdate) {
+	update.VehicleID = vehicleID
+	update.Timestamp = time.Now()
+	if err := s.wsManager.BroadcastVehicleUpdate(vehicleID, update); err != nil {
+		fmt.Printf("Failed to broadcast %s event for vehicle %s: %v\n", update.UpdateType, vehicleID, err)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingReminderStore records the reminders stored by status refreshes
+type recordingReminderStore struct {
+	updated []string
+}
+
+func (r *recordingReminderStore) UpdateReminder(_ context.Context, id string, _ *models.ServiceReminder) error {
+	r.updated = append(r.updated, id)
+	return nil
+}
+
+func TestRefreshReminderStatus_BroadcastsReminderTurningOverdue(t *testing.T) {
+	broadcaster := &recordingBroadcaster{}
+	service := &MaintenanceService{}
+	service.SetWebSocketManager(broadcaster)
+	store := &recordingReminderStore{}
+
+	dueDate := time.Now().AddDate(0, 0, -3)
+	reminder := &models.ServiceReminder{
+		ID:        primitive.NewObjectID(),
+		VehicleID: primitive.NewObjectID(),
+		Types:     []string{models.MaintenanceTypeOilChange},
+		DueDate:   &dueDate,
+	}
+
+	service.refreshReminderStatus(context.Background(), store, reminder)
+	assert.True(t, reminder.IsOverdue)
+	assert.Equal(t, []string{reminder.ID.Hex()}, store.updated)
+
+	require.Len(t, broadcaster.updates, 1)
+	update := broadcaster.updates[0]
+	assert.Equal(t, websocket.UpdateTypeReminderOverdue, update.UpdateType)
+	assert.Equal(t, reminder.VehicleID.Hex(), update.VehicleID)
+	assert.Equal(t, reminder.ID.Hex(), update.Data["reminderId"])
+	assert.Equal(t, dueDate, update.Data["dueDate"])
+
+	// Already stored as overdue, so later reads don't broadcast it again
+	service.refreshReminderStatus(context.Background(), store, reminder)
+	assert.Len(t, store.updated, 1)
+	assert.Len(t, broadcaster.updates, 1)
+}
+
+func TestRefreshReminderStatus_IgnoresRemindersNotYetDue(t *testing.T) {
+	broadcaster := &recordingBroadcaster{}
+	service := &MaintenanceService{}
+	service.SetWebSocketManager(broadcaster)
+	store := &recordingReminderStore{}
+
+	dueOdometer := 60000
+	reminder := &models.ServiceReminder{
+		ID:              primitive.NewObjectID(),
+		VehicleID:       primitive.NewObjectID(),
+		DueOdometer:     &dueOdometer,
+		CurrentOdometer: 55000,
+	}
+
+	service.refreshReminderStatus(context.Background(), store, reminder)
+	assert.False(t, reminder.IsOverdue)
+	assert.Empty(t, store.updated)
+	assert.Empty(t, broadcaster.updates)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleIntervalBounds_Validate(t *testing.T) {
+	bounds := DefaultScheduleIntervalBounds()
+	days := func(d int) *int { return &d }
+
+	tests := []struct {
+		name         string
+		intervalKm   int
+		intervalDays *int
+		valid        bool
+	}{
+		{name: "km too small", intervalKm: 1, valid: false},
+		{name: "km too large", intervalKm: 10000000, valid: false},
+		{name: "km at lower bound", intervalKm: bounds.MinKm, valid: true},
+		{name: "km at upper bound", intervalKm: bounds.MaxKm, valid: true},
+		{name: "typical oil change", intervalKm: 10000, intervalDays: days(180), valid: true},
+		{name: "days too small", intervalKm: 10000, intervalDays: days(1), valid: false},
+		{name: "days too large", intervalKm: 10000, intervalDays: days(36500), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bounds.Validate(tt.intervalKm, tt.intervalDays)
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidScheduleInterval)
+		})
+	}
+}
+
+func TestScheduleIntervalBounds_ErrorNamesTheRange(t *testing.T) {
+	err := ScheduleIntervalBounds{MinKm: 1000, MaxKm: 50000, MinDays: 30, MaxDays: 365}.Validate(60000, nil)
+	assert.EqualError(t, err, "invalid maintenance schedule interval: intervalKm must be between 1000 and 50000 km, got 60000")
+}
+
+func TestCreateSchedule_RejectsIntervalBeforeLookup(t *testing.T) {
+	// No repositories: an out-of-range interval must be rejected before any lookup
+	service := &MaintenanceService{intervalBounds: DefaultScheduleIntervalBounds()}
+
+	_, err := service.CreateSchedule(context.Background(), &CreateScheduleRequest{VehicleID: "v1", IntervalKm: 1})
+	assert.ErrorIs(t, err, ErrInvalidScheduleInterval)
+}
+
+func TestCreateScheduleRequest_IntervalDaysMustBePositive(t *testing.T) {
+	negative := -30
+	req := CreateScheduleRequest{
+		VehicleID:           "v1",
+		Types:               []string{"oil_change"},
+		Description:         "Oil change",
+		IntervalKm:          10000,
+		IntervalDays:        &negative,
+		LastServiceOdometer: 1000,
+		LastServiceDate:     time.Now(),
+		ServiceCenterName:   "Main depot",
+	}
+
+	assert.Error(t, validator.New().Struct(&req))
+
+	req.IntervalDays = nil
+	assert.NoError(t, validator.New().Struct(&req))
+}
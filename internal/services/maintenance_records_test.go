@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRecordPager pages through maintenance records in memory
+type memoryRecordPager struct {
+	records []*models.MaintenanceRecord
+}
+
+func (m *memoryRecordPager) page(records []*models.MaintenanceRecord, limit, offset int) []*models.MaintenanceRecord {
+	if offset >= len(records) {
+		return nil
+	}
+	records = records[offset:]
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
+
+func (m *memoryRecordPager) forVehicle(vehicleID string) []*models.MaintenanceRecord {
+	var matched []*models.MaintenanceRecord
+	for _, record := range m.records {
+		if record.VehicleID.Hex() == vehicleID {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+func (m *memoryRecordPager) FindAll(_ context.Context, limit, offset int) ([]*models.MaintenanceRecord, error) {
+	return m.page(m.records, limit, offset), nil
+}
+
+func (m *memoryRecordPager) CountAll(_ context.Context) (int64, error) {
+	return int64(len(m.records)), nil
+}
+
+func (m *memoryRecordPager) FindPageByVehicleID(_ context.Context, vehicleID string, limit, offset int) ([]*models.MaintenanceRecord, error) {
+	return m.page(m.forVehicle(vehicleID), limit, offset), nil
+}
+
+func (m *memoryRecordPager) CountByVehicle(_ context.Context, vehicleID string) (int64, error) {
+	return int64(len(m.forVehicle(vehicleID))), nil
+}
+
+func newMemoryRecordPager(counts map[primitive.ObjectID]int) *memoryRecordPager {
+	pager := &memoryRecordPager{}
+	for vehicleID, count := range counts {
+		for i := 0; i < count; i++ {
+			pager.records = append(pager.records, &models.MaintenanceRecord{ID: primitive.NewObjectID(), VehicleID: vehicleID})
+		}
+	}
+	return pager
+}
+
+func TestListMaintenanceRecords_TotalIsIndependentOfPage(t *testing.T) {
+	pager := newMemoryRecordPager(map[primitive.ObjectID]int{primitive.NewObjectID(): 25})
+
+	for _, window := range []struct{ limit, offset, size int }{
+		{10, 0, 10},
+		{10, 20, 5},
+		{3, 7, 3},
+		{10, 30, 0},
+	} {
+		page, err := listMaintenanceRecords(context.Background(), pager, "", window.limit, window.offset)
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), page.Total)
+		assert.Len(t, page.Records, window.size)
+		assert.Equal(t, window.offset+window.size < 25, page.HasMore)
+	}
+}
+
+func TestListMaintenanceRecords_CountsOnlyTheVehicle(t *testing.T) {
+	truck, van := primitive.NewObjectID(), primitive.NewObjectID()
+	pager := newMemoryRecordPager(map[primitive.ObjectID]int{truck: 7, van: 4})
+
+	page, err := listMaintenanceRecords(context.Background(), pager, truck.Hex(), 5, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), page.Total)
+	assert.Len(t, page.Records, 2)
+	assert.False(t, page.HasMore)
+	for _, record := range page.Records {
+		assert.Equal(t, truck, record.VehicleID)
+	}
+}
+
+func TestListMaintenanceRecords_ClampsLimit(t *testing.T) {
+	pager := newMemoryRecordPager(map[primitive.ObjectID]int{primitive.NewObjectID(): 3})
+
+	page, err := listMaintenanceRecords(context.Background(), pager, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaintenancePageLimit, page.Limit)
+
+	page, err = listMaintenanceRecords(context.Background(), pager, "", MaxMaintenancePageLimit+1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, MaxMaintenancePageLimit, page.Limit)
+	assert.NotNil(t, page.Records)
+}
+
+func TestListMaintenanceRecords_RejectsNegativeWindow(t *testing.T) {
+	pager := newMemoryRecordPager(nil)
+
+	_, err := listMaintenanceRecords(context.Background(), pager, "", 10, -1)
+	assert.ErrorIs(t, err, ErrInvalidPagination)
+
+	_, err = listMaintenanceRecords(context.Background(), pager, "", -1, 0)
+	assert.ErrorIs(t, err, ErrInvalidPagination)
+}
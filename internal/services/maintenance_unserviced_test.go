@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeScheduleHistory serves schedules and records from memory
+type fakeScheduleHistory struct {
+	schedules []*models.MaintenanceSchedule
+	records   []*models.MaintenanceRecord
+	queried   []primitive.ObjectID
+}
+
+func (f *fakeScheduleHistory) FindActiveSchedules(_ context.Context) ([]*models.MaintenanceSchedule, error) {
+	var active []*models.MaintenanceSchedule
+	for _, schedule := range f.schedules {
+		if schedule.IsActive {
+			active = append(active, schedule)
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeScheduleHistory) FindCompletedByVehicleIDs(_ context.Context, vehicleIDs []primitive.ObjectID) ([]*models.MaintenanceRecord, error) {
+	f.queried = vehicleIDs
+	wanted := make(map[primitive.ObjectID]bool)
+	for _, id := range vehicleIDs {
+		wanted[id] = true
+	}
+
+	var records []*models.MaintenanceRecord
+	for _, record := range f.records {
+		if wanted[record.VehicleID] && record.Status == models.MaintenanceStatusCompleted {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func TestUnservicedSchedules_OnlyScheduleWithoutRecordsIsReturned(t *testing.T) {
+	commissioned := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), CreatedAt: commissioned}
+
+	serviced := &models.MaintenanceSchedule{
+		ID:              primitive.NewObjectID(),
+		VehicleID:       vehicle.ID,
+		Types:           []string{models.MaintenanceTypeOilChange},
+		LastServiceDate: commissioned.AddDate(0, 3, 0),
+		IsActive:        true,
+	}
+	neglected := &models.MaintenanceSchedule{
+		ID:              primitive.NewObjectID(),
+		VehicleID:       vehicle.ID,
+		Types:           []string{models.MaintenanceTypeBrakeService},
+		LastServiceDate: commissioned.AddDate(0, 3, 0),
+		IsActive:        true,
+	}
+	inactive := &models.MaintenanceSchedule{
+		ID:        primitive.NewObjectID(),
+		VehicleID: vehicle.ID,
+		Types:     []string{models.MaintenanceTypeCoolantFlush},
+		IsActive:  false,
+	}
+
+	history := &fakeScheduleHistory{
+		schedules: []*models.MaintenanceSchedule{serviced, neglected, inactive},
+		records: []*models.MaintenanceRecord{
+			{VehicleID: vehicle.ID, Types: []string{models.MaintenanceTypeOilChange, models.MaintenanceTypeAirFilter}, Status: models.MaintenanceStatusCompleted},
+			// Brake work that never happened doesn't count as a service
+			{VehicleID: vehicle.ID, Types: []string{models.MaintenanceTypeBrakeService}, Status: models.MaintenanceStatusCancelled},
+		},
+	}
+	vehicles := &fakeBatchFinder{vehicles: map[string]*models.Vehicle{vehicle.ID.Hex(): vehicle}}
+
+	unserviced, err := unservicedSchedules(context.Background(), history, vehicles)
+	require.NoError(t, err)
+
+	require.Len(t, unserviced, 1)
+	assert.Equal(t, neglected.ID, unserviced[0].Schedule.ID)
+	assert.Equal(t, []string{models.UnservicedReasonNoRecords}, unserviced[0].Reasons)
+	require.NotNil(t, unserviced[0].VehicleCommissionedAt)
+	assert.Equal(t, commissioned, *unserviced[0].VehicleCommissionedAt)
+
+	// Records for the vehicle are fetched once for both of its schedules
+	assert.Equal(t, []primitive.ObjectID{vehicle.ID}, history.queried)
+}
+
+func TestFindUnservicedSchedules_LastServicePredatesCommissioning(t *testing.T) {
+	commissioned := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	vehicleID := primitive.NewObjectID()
+
+	placeholder := &models.MaintenanceSchedule{
+		ID:        primitive.NewObjectID(),
+		VehicleID: vehicleID,
+		Types:     []string{models.MaintenanceTypeInspection},
+		IsActive:  true,
+		// LastServiceDate left unset
+	}
+	records := []*models.MaintenanceRecord{
+		{VehicleID: vehicleID, Types: []string{models.MaintenanceTypeInspection}, Status: models.MaintenanceStatusCompleted},
+	}
+
+	unserviced := findUnservicedSchedules([]*models.MaintenanceSchedule{placeholder}, records, map[primitive.ObjectID]time.Time{vehicleID: commissioned})
+	require.Len(t, unserviced, 1)
+	assert.Equal(t, []string{models.UnservicedReasonPredatesCommissioning}, unserviced[0].Reasons)
+
+	// Without a known commissioning date only the records are checked
+	assert.Empty(t, findUnservicedSchedules([]*models.MaintenanceSchedule{placeholder}, records, nil))
+}
+
+func TestFindUnservicedSchedules_BothReasons(t *testing.T) {
+	commissioned := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	vehicleID := primitive.NewObjectID()
+	schedule := &models.MaintenanceSchedule{
+		ID:              primitive.NewObjectID(),
+		VehicleID:       vehicleID,
+		Types:           []string{models.MaintenanceTypeTireRotation},
+		LastServiceDate: commissioned.AddDate(0, -1, 0),
+		IsActive:        true,
+	}
+
+	unserviced := findUnservicedSchedules([]*models.MaintenanceSchedule{schedule}, nil, map[primitive.ObjectID]time.Time{vehicleID: commissioned})
+	require.Len(t, unserviced, 1)
+	assert.Equal(t, []string{models.UnservicedReasonNoRecords, models.UnservicedReasonPredatesCommissioning}, unserviced[0].Reasons)
+}
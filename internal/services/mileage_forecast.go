@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultDailyMileageKm is assumed for fleet vehicles without usable history
+	defaultDailyMileageKm = 50
+
+	// odometerSampleInterval is the minimum spacing between stored samples, so
+	// high-frequency telemetry doesn't crowd out older readings
+	odometerSampleInterval = time.Hour
+	// odometerSampleWindow is how far back samples count towards the estimate
+	odometerSampleWindow = 30 * 24 * time.Hour
+	// minTelemetrySpan is the shortest sample span trusted for an estimate
+	minTelemetrySpan = 12 * time.Hour
+	// telemetryFullWeightSpan is the sample span at which telemetry fully
+	// replaces the maintenance history estimate
+	telemetryFullWeightSpan = 7 * 24 * time.Hour
+)
+
+// ErrInvalidForecastDays is returned when the forecast horizon is not positive
+var ErrInvalidForecastDays = errors.New("forecast days must be positive")
+
+type odometerSample struct {
+	odometer int
+	at       time.Time
+}
+
+// MileageForecaster keeps recent odometer readings from telemetry per vehicle
+// and blends the mileage they show into daily-mileage estimates
+type MileageForecaster struct {
+	samples map[string][]odometerSample
+	mu      sync.RWMutex
+}
+
+// NewMileageForecaster creates an empty forecaster
+func NewMileageForecaster() *MileageForecaster {
+	return &MileageForecaster{samples: make(map[string][]odometerSample)}
+}
+
+// RecordOdometer stores an odometer reading for a vehicle. Readings closer
+// than odometerSampleInterval to the previous one are ignored, and a reading
+// below the previous one restarts the vehicle's history.
+func (f *MileageForecaster) RecordOdometer(vehicleID string, odometer int, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	samples := f.samples[vehicleID]
+	if n := len(samples); n > 0 {
+		last := samples[n-1]
+		if odometer < last.odometer {
+			samples = samples[:0]
+		} else if at.Sub(last.at) < odometerSampleInterval {
+			return
+		}
+	}
+
+	// Drop samples that fell out of the window
+	cutoff := at.Add(-odometerSampleWindow)
+	start := 0
+	for start < len(samples) && samples[start].at.Before(cutoff) {
+		start++
+	}
+	f.samples[vehicleID] = append(samples[start:], odometerSample{odometer: odometer, at: at})
+}
+
+// TelemetryDailyMileage returns the km per day shown by the vehicle's samples
+// within the window ending at now, and the time span they cover. ok is false
+// when the samples span less than minTelemetrySpan or the forecaster is nil.
+func (f *MileageForecaster) TelemetryDailyMileage(vehicleID string, now time.Time) (kmPerDay float64, span time.Duration, ok bool) {
+	if f == nil {
+		return 0, 0, false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	cutoff := now.Add(-odometerSampleWindow)
+	var first, last *odometerSample
+	for i := range f.samples[vehicleID] {
+		sample := &f.samples[vehicleID][i]
+		if sample.at.Before(cutoff) || sample.at.After(now) {
+			continue
+		}
+		if first == nil {
+			first = sample
+		}
+		last = sample
+	}
+	if first == nil {
+		return 0, 0, false
+	}
+
+	span = last.at.Sub(first.at)
+	if span < minTelemetrySpan {
+		return 0, span, false
+	}
+	return float64(last.odometer-first.odometer) / (span.Hours() / 24), span, true
+}
+
+// BlendDailyMileage combines a daily mileage derived from maintenance records
+// with the vehicle's telemetry. Telemetry is weighted by how much time its
+// samples cover, reaching full weight at telemetryFullWeightSpan. A nil
+// forecaster returns recordKmPerDay unchanged.
+func (f *MileageForecaster) BlendDailyMileage(vehicleID string, recordKmPerDay float64, now time.Time) float64 {
+	if f == nil {
+		return recordKmPerDay
+	}
+
+	telemetryKmPerDay, span, ok := f.TelemetryDailyMileage(vehicleID, now)
+	if !ok {
+		return recordKmPerDay
+	}
+	if recordKmPerDay <= 0 {
+		return telemetryKmPerDay
+	}
+
+	weight := math.Min(span.Hours()/telemetryFullWeightSpan.Hours(), 1)
+	return weight*telemetryKmPerDay + (1-weight)*recordKmPerDay
+}
+
+// SetMileageForecaster enables blending telemetry mileage into service date estimates
+func (s *MaintenanceService) SetMileageForecaster(forecaster *MileageForecaster) {
+	s.forecaster = forecaster
+}
+
+// vehicleRecordsFinder is the subset of the maintenance repository that
+// fetches the maintenance history of many vehicles at once, keyed by vehicle
+// ID hex with each history most recent first
+type vehicleRecordsFinder interface {
+	FindByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) (map[string][]*models.MaintenanceRecord, error)
+}
+
+// GetMaintenanceForecast returns the vehicles predicted to reach their next
+// service odometer within the given number of days, soonest first
+func (s *MaintenanceService) GetMaintenanceForecast(ctx context.Context, days int) ([]*models.MaintenanceForecast, error) {
+	if days <= 0 {
+		return nil, ErrInvalidForecastDays
+	}
+	return s.maintenanceForecast(ctx, s.vehicleRepo, s.maintenanceRepo, days, time.Now())
+}
+
+func (s *MaintenanceService) maintenanceForecast(ctx context.Context, fleet fleetLister, history vehicleRecordsFinder, days int, now time.Time) ([]*models.MaintenanceForecast, error) {
+	vehicles, err := fleet.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleIDs := make([]primitive.ObjectID, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		vehicleIDs = append(vehicleIDs, vehicle.ID)
+	}
+	histories, err := history.FindByVehicleIDs(ctx, vehicleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := []*models.MaintenanceForecast{}
+	for _, vehicle := range vehicles {
+		records := histories[vehicle.ID.Hex()]
+		if len(records) == 0 || records[0].NextServiceOdometer <= 0 {
+			continue // nothing to forecast against
+		}
+
+		forecast := s.forecastVehicle(vehicle, records, now)
+		if forecast.DaysUntilDue <= days {
+			forecasts = append(forecasts, forecast)
+		}
+	}
+
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		return forecasts[i].PredictedDueDate.Before(forecasts[j].PredictedDueDate)
+	})
+	return forecasts, nil
+}
+
+// forecastVehicle predicts when a vehicle reaches the next service odometer of
+// its latest record, records being ordered most recent first
+func (s *MaintenanceService) forecastVehicle(vehicle *models.Vehicle, records []*models.MaintenanceRecord, now time.Time) *models.MaintenanceForecast {
+	latest := records[0]
+	recordKmPerDay := averageDailyMileage(records)
+
+	forecast := &models.MaintenanceForecast{
+		VehicleID:           vehicle.ID,
+		VehicleName:         vehicle.Name,
+		CurrentOdometer:     vehicle.Odometer,
+		NextServiceOdometer: latest.NextServiceOdometer,
+		KmRemaining:         latest.NextServiceOdometer - vehicle.Odometer,
+		RecordDailyKm:       recordKmPerDay,
+		StaticDueDate:       latest.NextServiceDate,
+	}
+	if telemetryKmPerDay, _, ok := s.forecaster.TelemetryDailyMileage(vehicle.ID.Hex(), now); ok {
+		forecast.TelemetryDailyKm = telemetryKmPerDay
+	}
+
+	forecast.DailyKm = s.forecaster.BlendDailyMileage(vehicle.ID.Hex(), recordKmPerDay, now)
+	if forecast.DailyKm <= 0 {
+		forecast.DailyKm = defaultDailyMileageKm
+	}
+
+	if forecast.KmRemaining > 0 {
+		forecast.DaysUntilDue = int(math.Ceil(float64(forecast.KmRemaining) / forecast.DailyKm))
+	}
+	forecast.PredictedDueDate = now.AddDate(0, 0, forecast.DaysUntilDue)
+	return forecast
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// staticHistory returns fixed maintenance records per vehicle
+type staticHistory map[string][]*models.MaintenanceRecord
+
+func (h staticHistory) FindByVehicleIDs(_ context.Context, vehicleIDs []primitive.ObjectID) (map[string][]*models.MaintenanceRecord, error) {
+	histories := make(map[string][]*models.MaintenanceRecord)
+	for _, id := range vehicleIDs {
+		if records, ok := h[id.Hex()]; ok {
+			histories[id.Hex()] = records
+		}
+	}
+	return histories, nil
+}
+
+// countingHistory counts how many history queries a forecast makes
+type countingHistory struct {
+	staticHistory
+	queries int
+}
+
+func (h *countingHistory) FindByVehicleIDs(ctx context.Context, vehicleIDs []primitive.ObjectID) (map[string][]*models.MaintenanceRecord, error) {
+	h.queries++
+	return h.staticHistory.FindByVehicleIDs(ctx, vehicleIDs)
+}
+
+// newForecastVehicle builds a vehicle whose service history averages 20 km/day
+// and that is 3000 km from its next service, statically due in 150 days
+func newForecastVehicle(now time.Time) (*models.Vehicle, staticHistory) {
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck", Odometer: 20000}
+	staticDue := now.AddDate(0, 0, 150)
+
+	history := staticHistory{vehicle.ID.Hex(): {
+		{Odometer: 20000, PerformedAt: now, NextServiceOdometer: 23000, NextServiceDate: &staticDue},
+		{Odometer: 18000, PerformedAt: now.AddDate(0, 0, -100)},
+	}}
+	return vehicle, history
+}
+
+func TestMaintenanceForecast_TelemetryMileageBringsServiceForward(t *testing.T) {
+	now := time.Now()
+	vehicle, history := newForecastVehicle(now)
+	fleet := &countingFleet{vehicles: []*models.Vehicle{vehicle}}
+
+	// Without telemetry the vehicle isn't due within 60 days
+	service := &MaintenanceService{}
+	forecasts, err := service.maintenanceForecast(context.Background(), fleet, history, 60, now)
+	require.NoError(t, err)
+	assert.Empty(t, forecasts)
+
+	// Telemetry shows the vehicle now covers 100 km/day
+	forecaster := NewMileageForecaster()
+	for day := 10; day >= 0; day-- {
+		forecaster.RecordOdometer(vehicle.ID.Hex(), 20000-day*100, now.AddDate(0, 0, -day))
+	}
+	service.SetMileageForecaster(forecaster)
+
+	forecasts, err = service.maintenanceForecast(context.Background(), fleet, history, 60, now)
+	require.NoError(t, err)
+	require.Len(t, forecasts, 1)
+
+	forecast := forecasts[0]
+	assert.InDelta(t, 20, forecast.RecordDailyKm, 0.01)
+	assert.InDelta(t, 100, forecast.TelemetryDailyKm, 0.01)
+	assert.InDelta(t, 100, forecast.DailyKm, 0.01)
+	assert.Equal(t, 3000, forecast.KmRemaining)
+	assert.Equal(t, 30, forecast.DaysUntilDue)
+	assert.True(t, forecast.PredictedDueDate.Before(*forecast.StaticDueDate))
+}
+
+func TestMaintenanceForecast_SkipsVehiclesWithoutHistory(t *testing.T) {
+	now := time.Now()
+	vehicle, history := newForecastVehicle(now)
+	unserviced := &models.Vehicle{ID: primitive.NewObjectID(), Odometer: 5000}
+	fleet := &countingFleet{vehicles: []*models.Vehicle{unserviced, vehicle}}
+
+	forecasts, err := (&MaintenanceService{}).maintenanceForecast(context.Background(), fleet, history, 365, now)
+
+	require.NoError(t, err)
+	require.Len(t, forecasts, 1)
+	assert.Equal(t, vehicle.ID, forecasts[0].VehicleID)
+	assert.Equal(t, 150, forecasts[0].DaysUntilDue)
+}
+
+func TestMaintenanceForecast_LoadsFleetHistoryInOneQuery(t *testing.T) {
+	now := time.Now()
+	first, history := newForecastVehicle(now)
+	second, secondHistory := newForecastVehicle(now)
+	history[second.ID.Hex()] = secondHistory[second.ID.Hex()]
+	fleet := &countingFleet{vehicles: []*models.Vehicle{first, second, {ID: primitive.NewObjectID()}}}
+	counting := &countingHistory{staticHistory: history}
+
+	forecasts, err := (&MaintenanceService{}).maintenanceForecast(context.Background(), fleet, counting, 365, now)
+
+	require.NoError(t, err)
+	assert.Len(t, forecasts, 2)
+	assert.Equal(t, 1, counting.queries)
+}
+
+func TestMileageForecaster_BlendsByTelemetrySpan(t *testing.T) {
+	now := time.Now()
+	forecaster := NewMileageForecaster()
+
+	// Too little telemetry to trust
+	forecaster.RecordOdometer("v1", 1000, now.Add(-2*time.Hour))
+	forecaster.RecordOdometer("v1", 1010, now)
+	assert.Equal(t, 20.0, forecaster.BlendDailyMileage("v1", 20, now))
+
+	// Samples closer than the sample interval are ignored
+	forecaster.RecordOdometer("v1", 1015, now.Add(10*time.Minute))
+	_, span, _ := forecaster.TelemetryDailyMileage("v1", now.Add(time.Hour))
+	assert.Equal(t, 2*time.Hour, span)
+
+	// Half a week of 100 km/day gets half the weight
+	forecaster = NewMileageForecaster()
+	forecaster.RecordOdometer("v2", 1000, now.Add(-84*time.Hour))
+	forecaster.RecordOdometer("v2", 1350, now)
+	assert.InDelta(t, 60, forecaster.BlendDailyMileage("v2", 20, now), 0.01)
+
+	// A rollback restarts the history
+	forecaster.RecordOdometer("v2", 500, now.Add(2*time.Hour))
+	_, _, ok := forecaster.TelemetryDailyMileage("v2", now.Add(2*time.Hour))
+	assert.False(t, ok)
+
+	var nilForecaster *MileageForecaster
+	assert.Equal(t, 20.0, nilForecaster.BlendDailyMileage("v1", 20, now))
+}
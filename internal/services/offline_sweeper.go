@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/lock"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// offlineSweepLockKey is the lock that keeps replicas from sweeping at the same time
+const offlineSweepLockKey = "offline-sweep"
+
+// OfflinePolicy decides when a vehicle's telemetry link counts as lost
+type OfflinePolicy struct {
+	// GracePeriod after creation during which a vehicle that hasn't reported
+	// yet is left alone
+	GracePeriod time.Duration
+	// OfflineAfter is how long a vehicle may go without reporting before it
+	// is marked offline
+	OfflineAfter time.Duration
+}
+
+// DefaultOfflinePolicy returns the default offline thresholds
+func DefaultOfflinePolicy() OfflinePolicy {
+	return OfflinePolicy{
+		GracePeriod:  30 * time.Minute,
+		OfflineAfter: 15 * time.Minute,
+	}
+}
+
+// Classify returns the connectivity state of a vehicle at now. Vehicles that
+// never reported are pending until their grace period ends, then never_reported,
+// which is kept distinct from vehicles that reported and went offline.
+func (p OfflinePolicy) Classify(vehicle *models.Vehicle, now time.Time) string {
+	if vehicle.LastReportedAt == nil {
+		if !vehicle.CreatedAt.IsZero() && now.Sub(vehicle.CreatedAt) < p.GracePeriod {
+			return models.ConnectivityPending
+		}
+		return models.ConnectivityNeverReported
+	}
+
+	if now.Sub(*vehicle.LastReportedAt) > p.OfflineAfter {
+		return models.ConnectivityOffline
+	}
+	return models.ConnectivityOnline
+}
+
+// markReported records that telemetry arrived for a vehicle at the given time.
+// Connectivity itself is only changed by the sweeper.
+func markReported(vehicle *models.Vehicle, at time.Time) {
+	reportedAt := at
+	vehicle.LastReportedAt = &reportedAt
+}
+
+// connectivityStore is the subset of the vehicle repository used by the sweeper
+type connectivityStore interface {
+	FindAll(ctx context.Context) ([]*models.Vehicle, error)
+	UpdateConnectivity(ctx context.Context, id string, connectivity string, status string) error
+}
+
+// OfflineSweeper periodically classifies every vehicle's connectivity and marks
+// vehicles that stopped reporting as offline
+type OfflineSweeper struct {
+	vehicles connectivityStore
+	policy   OfflinePolicy
+	interval time.Duration
+	statuses StatusRecorder
+	service  *VehicleService
+	locker   lock.Locker
+	lockTTL  time.Duration
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewOfflineSweeper creates a sweeper that runs every interval
+func NewOfflineSweeper(vehicles connectivityStore, policy OfflinePolicy, interval time.Duration) *OfflineSweeper {
+	return &OfflineSweeper{
+		vehicles: vehicles,
+		policy:   policy,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetStatusRecorder forwards the statuses the sweeper sets to recorder
+func (s *OfflineSweeper) SetStatusRecorder(recorder StatusRecorder) {
+	s.statuses = recorder
+}
+
+// SetVehicleService makes connectivity and status changes invalidate the
+// cached vehicle lists they affect, as vehicle updates do
+func (s *OfflineSweeper) SetVehicleService(service *VehicleService) {
+	s.service = service
+}
+
+// SetLocker makes only one replica at a time sweep. The lock is taken for
+// ttl, which should cover a whole sweep, and released when it ends.
+func (s *OfflineSweeper) SetLocker(locker lock.Locker, ttl time.Duration) {
+	s.locker = locker
+	s.lockTTL = ttl
+}
+
+// Start sweeps immediately and then every interval until Stop is called
+func (s *OfflineSweeper) Start() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sweepAndLog()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweepAndLog()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Stop stops the sweeper
+func (s *OfflineSweeper) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
+
+func (s *OfflineSweeper) sweepAndLog() {
+	changed, err := s.Sweep(context.Background(), time.Now())
+	if err != nil {
+		fmt.Printf("Offline sweep failed: %v\n", err)
+		return
+	}
+	if changed > 0 {
+		fmt.Printf("Offline sweep updated connectivity of %d vehicles\n", changed)
+	}
+}
+
+// Sweep classifies every vehicle at now and stores the states that changed.
+// Vehicles that went offline get the offline status, unless in maintenance,
+// and get it cleared again once they report. Vehicles still in their grace
+// period are skipped. It returns the number of vehicles updated. When
+// another replica holds the sweep lock, Sweep leaves the sweep to it and
+// returns zero.
+func (s *OfflineSweeper) Sweep(ctx context.Context, now time.Time) (int, error) {
+	if s.locker != nil {
+		held, err := s.locker.TryAcquire(offlineSweepLockKey, s.lockTTL)
+		if err != nil {
+			return 0, fmt.Errorf("failed to take the offline sweep lock: %w", err)
+		}
+		if !held {
+			return 0, nil
+		}
+		defer func() {
+			if err := s.locker.Release(offlineSweepLockKey); err != nil {
+				logger.FromContext(ctx).Warn("Failed to release the offline sweep lock", "error", err)
+			}
+		}()
+	}
+
+	vehicles, err := s.vehicles.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, vehicle := range vehicles {
+		connectivity := s.policy.Classify(vehicle, now)
+		if connectivity == models.ConnectivityPending || connectivity == vehicle.Connectivity {
+			continue
+		}
+
+		status := ""
+		switch {
+		case connectivity == models.ConnectivityOffline && vehicle.Status != "maintenance":
+			status = "offline"
+		case connectivity == models.ConnectivityOnline && vehicle.Status == "offline" && vehicle.Connectivity == models.ConnectivityOffline:
+			status = "idle" // the sweeper took it offline, telemetry brought it back
+		}
+
+		if err := s.vehicles.UpdateConnectivity(ctx, vehicle.ID.Hex(), connectivity, status); err != nil {
+			logger.FromContext(ctx).Error("Failed to update vehicle connectivity", "vehicle_id", vehicle.ID.Hex(), "error", err)
+			continue
+		}
+		if status != "" && s.statuses != nil {
+			s.statuses.RecordStatus(vehicle.ID.Hex(), status, now)
+		}
+		s.invalidateCache(ctx, vehicle, connectivity, status)
+		changed++
+	}
+
+	return changed, nil
+}
+
+// invalidateCache drops the cached lists a vehicle's connectivity change
+// affects, including those of its previous status
+func (s *OfflineSweeper) invalidateCache(ctx context.Context, vehicle *models.Vehicle, connectivity, status string) {
+	if s.service == nil || s.service.cacheManager == nil {
+		return
+	}
+
+	updated := *vehicle
+	updated.Connectivity = connectivity
+	if status != "" {
+		updated.Status = status
+	}
+	s.service.invalidateCacheOnUpdate(ctx, &updated, vehicle.Driver, vehicle.Status)
+}
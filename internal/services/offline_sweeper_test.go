@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// connectivityUpdate is a stored connectivity change
+type connectivityUpdate struct {
+	connectivity string
+	status       string
+}
+
+// fakeConnectivityStore serves a fixed fleet and records connectivity updates
+type fakeConnectivityStore struct {
+	vehicles []*models.Vehicle
+	updates  map[string]connectivityUpdate
+}
+
+func (f *fakeConnectivityStore) FindAll(_ context.Context) ([]*models.Vehicle, error) {
+	return f.vehicles, nil
+}
+
+func (f *fakeConnectivityStore) UpdateConnectivity(_ context.Context, id string, connectivity string, status string) error {
+	f.updates[id] = connectivityUpdate{connectivity: connectivity, status: status}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestOfflinePolicy_GracePeriodBoundary(t *testing.T) {
+	policy := OfflinePolicy{GracePeriod: 30 * time.Minute, OfflineAfter: 15 * time.Minute}
+	created := time.Now()
+	vehicle := &models.Vehicle{CreatedAt: created, LastUpdate: created}
+
+	assert.Equal(t, models.ConnectivityPending, policy.Classify(vehicle, created))
+	assert.Equal(t, models.ConnectivityPending, policy.Classify(vehicle, created.Add(30*time.Minute-time.Nanosecond)))
+	assert.Equal(t, models.ConnectivityNeverReported, policy.Classify(vehicle, created.Add(30*time.Minute)))
+}
+
+func TestOfflinePolicy_NeverReportedIsDistinctFromOffline(t *testing.T) {
+	policy := OfflinePolicy{GracePeriod: 30 * time.Minute, OfflineAfter: 15 * time.Minute}
+	now := time.Now()
+	created := now.Add(-2 * time.Hour)
+
+	neverReported := &models.Vehicle{CreatedAt: created}
+	wentOffline := &models.Vehicle{CreatedAt: created, LastReportedAt: timePtr(now.Add(-time.Hour))}
+	online := &models.Vehicle{CreatedAt: created, LastReportedAt: timePtr(now.Add(-time.Minute))}
+
+	assert.Equal(t, models.ConnectivityNeverReported, policy.Classify(neverReported, now))
+	assert.Equal(t, models.ConnectivityOffline, policy.Classify(wentOffline, now))
+	assert.Equal(t, models.ConnectivityOnline, policy.Classify(online, now))
+
+	// A report during the grace period counts as online straight away
+	fresh := &models.Vehicle{CreatedAt: now.Add(-time.Minute), LastReportedAt: timePtr(now)}
+	assert.Equal(t, models.ConnectivityOnline, policy.Classify(fresh, now))
+}
+
+func TestOfflineSweeper_Sweep(t *testing.T) {
+	now := time.Now()
+	policy := OfflinePolicy{GracePeriod: 30 * time.Minute, OfflineAfter: 15 * time.Minute}
+
+	newVehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "idle", CreatedAt: now.Add(-time.Minute)}
+	neverReported := &models.Vehicle{ID: primitive.NewObjectID(), Status: "idle", CreatedAt: now.Add(-time.Hour)}
+	wentOffline := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Hour)), Connectivity: models.ConnectivityOnline}
+	inMaintenance := &models.Vehicle{ID: primitive.NewObjectID(), Status: "maintenance", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Hour)), Connectivity: models.ConnectivityOnline}
+	backOnline := &models.Vehicle{ID: primitive.NewObjectID(), Status: "offline", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Minute)), Connectivity: models.ConnectivityOffline}
+	unchanged := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Minute)), Connectivity: models.ConnectivityOnline}
+
+	store := &fakeConnectivityStore{
+		vehicles: []*models.Vehicle{newVehicle, neverReported, wentOffline, inMaintenance, backOnline, unchanged},
+		updates:  make(map[string]connectivityUpdate),
+	}
+	sweeper := NewOfflineSweeper(store, policy, time.Minute)
+
+	changed, err := sweeper.Sweep(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, changed)
+	assert.NotContains(t, store.updates, newVehicle.ID.Hex())
+	assert.NotContains(t, store.updates, unchanged.ID.Hex())
+	assert.Equal(t, connectivityUpdate{connectivity: models.ConnectivityNeverReported}, store.updates[neverReported.ID.Hex()])
+	assert.Equal(t, connectivityUpdate{connectivity: models.ConnectivityOffline, status: "offline"}, store.updates[wentOffline.ID.Hex()])
+	assert.Equal(t, connectivityUpdate{connectivity: models.ConnectivityOffline}, store.updates[inMaintenance.ID.Hex()])
+	assert.Equal(t, connectivityUpdate{connectivity: models.ConnectivityOnline, status: "idle"}, store.updates[backOnline.ID.Hex()])
+}
+
+func TestOfflineSweeper_InvalidatesAffectedVehicleLists(t *testing.T) {
+	now := time.Now()
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", Driver: "alice", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Hour)), Connectivity: models.ConnectivityOnline}
+	store := &fakeConnectivityStore{vehicles: []*models.Vehicle{vehicle}, updates: make(map[string]connectivityUpdate)}
+
+	memoryCache := cache.NewMemoryCacheManager(cache.DefaultCacheConfig(), 100)
+	lists := []string{
+		"all_vehicles",
+		"vehicles_by_status_active",
+		"vehicles_by_status_offline",
+		"vehicles_by_driver_alice",
+	}
+	for _, key := range lists {
+		require.NoError(t, memoryCache.SetVehicleList(key, []*models.Vehicle{vehicle}, time.Minute))
+	}
+
+	sweeper := NewOfflineSweeper(store, DefaultOfflinePolicy(), time.Minute)
+	sweeper.SetVehicleService(&VehicleService{cacheManager: memoryCache, cacheConfig: cache.DefaultCacheConfig()})
+
+	changed, err := sweeper.Sweep(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	for _, key := range lists {
+		cached, err := memoryCache.GetVehicleList(key)
+		require.NoError(t, err)
+		assert.Nil(t, cached, key)
+	}
+	cached, err := memoryCache.GetVehicle(vehicle.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, "offline", cached.Status)
+}
+
+func TestOfflineSweeper_SkipsSweepWhileAnotherReplicaHoldsTheLock(t *testing.T) {
+	now := time.Now()
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Status: "active", CreatedAt: now.Add(-48 * time.Hour),
+		LastReportedAt: timePtr(now.Add(-time.Hour)), Connectivity: models.ConnectivityOnline}
+	store := &fakeConnectivityStore{vehicles: []*models.Vehicle{vehicle}, updates: make(map[string]connectivityUpdate)}
+
+	backend := newFakeLockBackend(&fakeClock{now: now})
+	otherReplica := &fakeLocker{backend: backend, owner: "replica-2"}
+	held, err := otherReplica.TryAcquire(offlineSweepLockKey, time.Minute)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	sweeper := NewOfflineSweeper(store, DefaultOfflinePolicy(), time.Minute)
+	sweeper.SetLocker(&fakeLocker{backend: backend, owner: "replica-1"}, time.Minute)
+
+	changed, err := sweeper.Sweep(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, changed)
+	assert.Empty(t, store.updates)
+
+	// Once released, this replica sweeps and releases the lock after
+	require.NoError(t, otherReplica.Release(offlineSweepLockKey))
+	changed, err = sweeper.Sweep(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Empty(t, backend.owners)
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/email"
+	"fleet-backend/pkg/lock"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// overdueReminderLockKey is the lock that keeps replicas from scanning at the same time
+const overdueReminderLockKey = "overdue-reminder-digest"
+
+// errReminderLockLost is returned when the scan lock expired mid-scan, so
+// another replica may have started its own
+var errReminderLockLost = errors.New("overdue reminder scan lock lost")
+
+// ReminderScanLimits bounds the database load of the overdue reminder scan
+type ReminderScanLimits struct {
+	// PageSize is how many reminders each query reads
+	PageSize int
+	// Concurrency is how many vehicle lookups run at once
+	Concurrency int
+}
+
+// DefaultReminderScanLimits returns the limits used unless SetScanLimits is called
+func DefaultReminderScanLimits() ReminderScanLimits {
+	return ReminderScanLimits{PageSize: 500, Concurrency: 4}
+}
+
+// Mailer sends the overdue service reminder digest to a recipient
+type Mailer interface {
+	SendOverdueReminderDigest(to string, digest email.OverdueReminderDigest) error
+}
+
+// overdueReminderStore is the subset of the maintenance repository used by the notifier
+type overdueReminderStore interface {
+	FindOverdueRemindersPage(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.ServiceReminder, error)
+	MarkRemindersNotified(ctx context.Context, ids []primitive.ObjectID, at time.Time) error
+}
+
+// reminderVehicleLookup resolves the vehicles named in a digest
+type reminderVehicleLookup interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+}
+
+// OverdueReminderNotifier emails a daily digest of overdue service reminders,
+// grouped by vehicle. A reminder is included at most once per day, so a
+// restart or a second run on the same day doesn't send it again.
+type OverdueReminderNotifier struct {
+	reminders  overdueReminderStore
+	vehicles   reminderVehicleLookup
+	mailer     Mailer
+	recipients []string
+	// sendAt is the time of day, as an offset from midnight, the digest is sent
+	sendAt   time.Duration
+	limits   ReminderScanLimits
+	locker   lock.Locker
+	lockTTL  time.Duration
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewOverdueReminderNotifier creates a notifier that emails recipients every
+// day at sendAt past local midnight
+func NewOverdueReminderNotifier(reminders overdueReminderStore, vehicles reminderVehicleLookup, mailer Mailer, recipients []string, sendAt time.Duration) *OverdueReminderNotifier {
+	return &OverdueReminderNotifier{
+		reminders:  reminders,
+		vehicles:   vehicles,
+		mailer:     mailer,
+		recipients: recipients,
+		sendAt:     sendAt,
+		limits:     DefaultReminderScanLimits(),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// SetScanLimits sets the page size and lookup concurrency of the scan; unset
+// limits keep their defaults
+func (n *OverdueReminderNotifier) SetScanLimits(limits ReminderScanLimits) {
+	defaults := DefaultReminderScanLimits()
+	if limits.PageSize <= 0 {
+		limits.PageSize = defaults.PageSize
+	}
+	if limits.Concurrency <= 0 {
+		limits.Concurrency = defaults.Concurrency
+	}
+	n.limits = limits
+}
+
+// SetLocker makes only one replica at a time run the scan. The lock is taken
+// for ttl and renewed after every page, so it covers the whole scan however
+// long it takes.
+func (n *OverdueReminderNotifier) SetLocker(locker lock.Locker, ttl time.Duration) {
+	n.locker = locker
+	n.lockTTL = ttl
+}
+
+// Start sends the digest every day at the configured time until Stop is called
+func (n *OverdueReminderNotifier) Start() {
+	for {
+		timer := time.NewTimer(time.Until(n.nextRun(time.Now())))
+		select {
+		case now := <-timer.C:
+			n.notifyAndLog(now)
+		case <-n.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Stop stops the notifier
+func (n *OverdueReminderNotifier) Stop() {
+	n.stopOnce.Do(func() { close(n.stopChan) })
+}
+
+// nextRun returns the first scheduled send time after now
+func (n *OverdueReminderNotifier) nextRun(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(n.sendAt)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(n.sendAt)
+	}
+	return next
+}
+
+func (n *OverdueReminderNotifier) notifyAndLog(now time.Time) {
+	sent, err := n.Notify(context.Background(), now)
+	if err != nil {
+		fmt.Printf("Overdue reminder digest failed: %v\n", err)
+		return
+	}
+	if sent > 0 {
+		fmt.Printf("Overdue reminder digest sent with %d reminders\n", sent)
+	}
+}
+
+// Notify emails the overdue reminders not yet notified on now's day to every
+// recipient and records them as notified. Reminders stay unnotified, to be
+// retried on the next run, only when no recipient could be reached. It
+// returns the number of reminders sent. When another replica holds the scan
+// lock, Notify leaves the digest to it and returns zero.
+func (n *OverdueReminderNotifier) Notify(ctx context.Context, now time.Time) (int, error) {
+	if n.locker != nil {
+		held, err := n.locker.TryAcquire(overdueReminderLockKey, n.lockTTL)
+		if err != nil {
+			return 0, fmt.Errorf("failed to take the overdue reminder scan lock: %w", err)
+		}
+		if !held {
+			return 0, nil
+		}
+		defer func() {
+			if err := n.locker.Release(overdueReminderLockKey); err != nil {
+				logger.FromContext(ctx).Warn("Failed to release the overdue reminder scan lock", "error", err)
+			}
+		}()
+	}
+
+	pending, vehicles, err := n.scanPending(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	digest := buildReminderDigest(pending, vehicles, now)
+
+	delivered := 0
+	for _, recipient := range n.recipients {
+		if err := n.mailer.SendOverdueReminderDigest(recipient, digest); err != nil {
+			logger.FromContext(ctx).Error("Failed to send overdue reminder digest", "recipient", recipient, "error", err)
+			continue
+		}
+		delivered++
+	}
+	if delivered == 0 {
+		return 0, fmt.Errorf("digest could not be delivered to any of %d recipients", len(n.recipients))
+	}
+
+	// Mark in page-sized batches so no single write grows with the fleet
+	for start := 0; start < len(pending); start += n.limits.PageSize {
+		end := min(start+n.limits.PageSize, len(pending))
+		ids := make([]primitive.ObjectID, 0, end-start)
+		for _, reminder := range pending[start:end] {
+			ids = append(ids, reminder.ID)
+		}
+		if err := n.renewLock(); err != nil {
+			return len(pending), fmt.Errorf("digest sent but reminders were not marked notified: %w", err)
+		}
+		if err := n.reminders.MarkRemindersNotified(ctx, ids, now); err != nil {
+			return len(pending), fmt.Errorf("digest sent but reminders were not marked notified: %w", err)
+		}
+	}
+
+	return len(pending), nil
+}
+
+// scanPending pages through the overdue reminders and returns the ones not
+// yet notified on now's day, along with their vehicles. Vehicles are looked up
+// page by page with at most limits.Concurrency lookups in flight.
+func (n *OverdueReminderNotifier) scanPending(ctx context.Context, now time.Time) ([]*models.ServiceReminder, map[primitive.ObjectID]*models.Vehicle, error) {
+	var pending []*models.ServiceReminder
+	vehicles := make(map[primitive.ObjectID]*models.Vehicle)
+	seen := make(map[primitive.ObjectID]bool)
+
+	var afterID primitive.ObjectID
+	for {
+		page, err := n.reminders.FindOverdueRemindersPage(ctx, afterID, n.limits.PageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var vehicleIDs []primitive.ObjectID
+		for _, reminder := range page {
+			if reminder.LastNotifiedAt != nil && sameDay(*reminder.LastNotifiedAt, now) {
+				continue
+			}
+			pending = append(pending, reminder)
+			if !seen[reminder.VehicleID] {
+				seen[reminder.VehicleID] = true
+				vehicleIDs = append(vehicleIDs, reminder.VehicleID)
+			}
+		}
+		n.lookupVehicles(ctx, vehicleIDs, vehicles)
+
+		if len(page) < n.limits.PageSize {
+			return pending, vehicles, nil
+		}
+		afterID = page[len(page)-1].ID
+
+		if err := n.renewLock(); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// lookupVehicles resolves vehicles into found using a bounded pool of workers.
+// Vehicles that can't be found are left out; their reminders are listed by ID.
+func (n *OverdueReminderNotifier) lookupVehicles(ctx context.Context, ids []primitive.ObjectID, found map[primitive.ObjectID]*models.Vehicle) {
+	jobs := make(chan primitive.ObjectID)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(n.limits.Concurrency, len(ids)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				vehicle, err := n.vehicles.FindByID(ctx, id.Hex())
+				if err != nil || vehicle == nil {
+					continue
+				}
+				mu.Lock()
+				found[id] = vehicle
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// renewLock extends the scan lock, failing when it was lost
+func (n *OverdueReminderNotifier) renewLock() error {
+	if n.locker == nil {
+		return nil
+	}
+	held, err := n.locker.Renew(overdueReminderLockKey, n.lockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to renew the overdue reminder scan lock: %w", err)
+	}
+	if !held {
+		return errReminderLockLost
+	}
+	return nil
+}
+
+// buildReminderDigest groups reminders by vehicle, ordered by vehicle name
+func buildReminderDigest(reminders []*models.ServiceReminder, vehicles map[primitive.ObjectID]*models.Vehicle, now time.Time) email.OverdueReminderDigest {
+	byVehicle := make(map[primitive.ObjectID]*email.VehicleReminders)
+	var order []primitive.ObjectID
+
+	for _, reminder := range reminders {
+		group, ok := byVehicle[reminder.VehicleID]
+		if !ok {
+			group = &email.VehicleReminders{VehicleID: reminder.VehicleID.Hex()}
+			if vehicle := vehicles[reminder.VehicleID]; vehicle != nil {
+				group.VehicleName = vehicle.Name
+				group.PlateNumber = vehicle.PlateNumber
+			}
+			byVehicle[reminder.VehicleID] = group
+			order = append(order, reminder.VehicleID)
+		}
+		group.Reminders = append(group.Reminders, reminder)
+	}
+
+	digest := email.OverdueReminderDigest{GeneratedAt: now}
+	for _, vehicleID := range order {
+		digest.Vehicles = append(digest.Vehicles, *byVehicle[vehicleID])
+	}
+	sort.SliceStable(digest.Vehicles, func(i, j int) bool {
+		return digest.Vehicles[i].VehicleName < digest.Vehicles[j].VehicleName
+	})
+
+	return digest
+}
+
+// sameDay reports whether a falls on the same calendar day as b, in b's location
+func sameDay(a, b time.Time) bool {
+	a = a.In(b.Location())
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
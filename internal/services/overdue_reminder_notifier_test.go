@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/email"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeReminderStore serves reminders from memory and records notifications on them
+type fakeReminderStore struct {
+	reminders []*models.ServiceReminder
+	pages     int
+	// onPage runs after every page is read
+	onPage func()
+}
+
+func (f *fakeReminderStore) FindOverdueRemindersPage(_ context.Context, afterID primitive.ObjectID, limit int) ([]*models.ServiceReminder, error) {
+	sorted := append([]*models.ServiceReminder(nil), f.reminders...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID.Hex() < sorted[j].ID.Hex() })
+
+	var page []*models.ServiceReminder
+	for _, reminder := range sorted {
+		if !reminder.IsOverdue || (!afterID.IsZero() && reminder.ID.Hex() <= afterID.Hex()) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		copied := *reminder
+		page = append(page, &copied)
+	}
+	f.pages++
+	if f.onPage != nil {
+		f.onPage()
+	}
+	return page, nil
+}
+
+func (f *fakeReminderStore) MarkRemindersNotified(_ context.Context, ids []primitive.ObjectID, at time.Time) error {
+	for _, id := range ids {
+		for _, reminder := range f.reminders {
+			if reminder.ID == id {
+				notifiedAt := at
+				reminder.LastNotifiedAt = &notifiedAt
+			}
+		}
+	}
+	return nil
+}
+
+type fakeReminderVehicles map[string]*models.Vehicle
+
+func (f fakeReminderVehicles) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	if vehicle, ok := f[id]; ok {
+		return vehicle, nil
+	}
+	return nil, errors.New("vehicle not found")
+}
+
+// fakeMailer records the digests sent to each recipient
+type fakeMailer struct {
+	sent []email.OverdueReminderDigest
+	to   []string
+	fail bool
+}
+
+func (f *fakeMailer) SendOverdueReminderDigest(to string, digest email.OverdueReminderDigest) error {
+	if f.fail {
+		return errors.New("smtp unavailable")
+	}
+	f.to = append(f.to, to)
+	f.sent = append(f.sent, digest)
+	return nil
+}
+
+func overdueReminder(vehicleID primitive.ObjectID, maintenanceType string) *models.ServiceReminder {
+	dueDate := time.Now().AddDate(0, 0, -3)
+	return &models.ServiceReminder{
+		ID:        primitive.NewObjectID(),
+		VehicleID: vehicleID,
+		Types:     []string{maintenanceType},
+		DueDate:   &dueDate,
+		Priority:  models.PriorityUrgent,
+		IsOverdue: true,
+	}
+}
+
+func TestOverdueReminderNotifier_SendsDigestOncePerDay(t *testing.T) {
+	truck, van := primitive.NewObjectID(), primitive.NewObjectID()
+	store := &fakeReminderStore{reminders: []*models.ServiceReminder{
+		overdueReminder(truck, models.MaintenanceTypeOilChange),
+		overdueReminder(van, models.MaintenanceTypeBrakeService),
+		{ID: primitive.NewObjectID(), VehicleID: van, Types: []string{models.MaintenanceTypeAirFilter}},
+	}}
+	vehicles := fakeReminderVehicles{
+		truck.Hex(): {Name: "Truck 1", PlateNumber: "KAA 001A"},
+		van.Hex():   {Name: "Van 2", PlateNumber: "KAA 002B"},
+	}
+	mailer := &fakeMailer{}
+	notifier := NewOverdueReminderNotifier(store, vehicles, mailer, []string{"fleet@example.com"}, 8*time.Hour)
+
+	morning := time.Date(2024, 5, 6, 8, 0, 0, 0, time.Local)
+	sent, err := notifier.Notify(context.Background(), morning)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, []string{"fleet@example.com"}, mailer.to)
+	digest := mailer.sent[0]
+	assert.Equal(t, 2, digest.ReminderCount())
+	require.Len(t, digest.Vehicles, 2)
+	assert.Equal(t, "Truck 1", digest.Vehicles[0].VehicleName)
+	assert.Equal(t, []string{models.MaintenanceTypeOilChange}, digest.Vehicles[0].Reminders[0].Types)
+	assert.Equal(t, "Van 2", digest.Vehicles[1].VehicleName)
+	assert.Len(t, digest.Vehicles[1].Reminders, 1)
+
+	// A second run the same day finds nothing new to send
+	sent, err = notifier.Notify(context.Background(), morning.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, mailer.sent, 1)
+
+	// The reminders are still overdue the next day, so they go out again
+	sent, err = notifier.Notify(context.Background(), morning.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Len(t, mailer.sent, 2)
+}
+
+func TestOverdueReminderNotifier_RetriesWhenNoRecipientReached(t *testing.T) {
+	store := &fakeReminderStore{reminders: []*models.ServiceReminder{
+		overdueReminder(primitive.NewObjectID(), models.MaintenanceTypeOilChange),
+	}}
+	mailer := &fakeMailer{fail: true}
+	notifier := NewOverdueReminderNotifier(store, fakeReminderVehicles{}, mailer, []string{"fleet@example.com"}, 8*time.Hour)
+
+	now := time.Date(2024, 5, 6, 8, 0, 0, 0, time.Local)
+	_, err := notifier.Notify(context.Background(), now)
+	assert.Error(t, err)
+	assert.Nil(t, store.reminders[0].LastNotifiedAt)
+
+	mailer.fail = false
+	sent, err := notifier.Notify(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestOverdueReminderNotifier_NextRun(t *testing.T) {
+	notifier := NewOverdueReminderNotifier(&fakeReminderStore{}, fakeReminderVehicles{}, &fakeMailer{}, nil, 8*time.Hour+30*time.Minute)
+
+	early := time.Date(2024, 5, 6, 7, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 5, 6, 8, 30, 0, 0, time.Local), notifier.nextRun(early))
+
+	late := time.Date(2024, 5, 6, 8, 30, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 5, 7, 8, 30, 0, 0, time.Local), notifier.nextRun(late))
+}
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// fakeLockBackend holds expiring locks on a fake clock, shared by the lockers
+// of several replicas
+type fakeLockBackend struct {
+	clock   *fakeClock
+	owners  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeLockBackend(clock *fakeClock) *fakeLockBackend {
+	return &fakeLockBackend{clock: clock, owners: make(map[string]string), expires: make(map[string]time.Time)}
+}
+
+type fakeLocker struct {
+	backend *fakeLockBackend
+	owner   string
+}
+
+func (l *fakeLocker) held(key string) bool {
+	return l.backend.owners[key] == l.owner && l.backend.clock.now.Before(l.backend.expires[key])
+}
+
+func (l *fakeLocker) TryAcquire(key string, ttl time.Duration) (bool, error) {
+	if owner := l.backend.owners[key]; owner != "" && owner != l.owner && l.backend.clock.now.Before(l.backend.expires[key]) {
+		return false, nil
+	}
+	l.backend.owners[key] = l.owner
+	l.backend.expires[key] = l.backend.clock.now.Add(ttl)
+	return true, nil
+}
+
+func (l *fakeLocker) Renew(key string, ttl time.Duration) (bool, error) {
+	if !l.held(key) {
+		return false, nil
+	}
+	l.backend.expires[key] = l.backend.clock.now.Add(ttl)
+	return true, nil
+}
+
+func (l *fakeLocker) Release(key string) error {
+	if l.backend.owners[key] == l.owner {
+		delete(l.backend.owners, key)
+	}
+	return nil
+}
+
+// concurrencyTrackingVehicles records the most lookups in flight at once
+type concurrencyTrackingVehicles struct {
+	vehicles fakeReminderVehicles
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	lookups  int
+}
+
+func (c *concurrencyTrackingVehicles) FindByID(ctx context.Context, id string) (*models.Vehicle, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.lookups++
+	c.maxSeen = max(c.maxSeen, c.inFlight)
+	c.mu.Unlock()
+
+	// Give the other workers a chance to overlap
+	time.Sleep(time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return c.vehicles.FindByID(ctx, id)
+}
+
+func TestOverdueReminderNotifier_ScansAllPagesWithBoundedConcurrency(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 6, 8, 0, 0, 0, time.Local)}
+	backend := newFakeLockBackend(clock)
+	otherReplica := &fakeLocker{backend: backend, owner: "replica-2"}
+
+	store := &fakeReminderStore{}
+	vehicles := &concurrencyTrackingVehicles{vehicles: fakeReminderVehicles{}}
+	for i := 0; i < 40; i++ {
+		vehicleID := primitive.NewObjectID()
+		vehicles.vehicles[vehicleID.Hex()] = &models.Vehicle{Name: fmt.Sprintf("Vehicle %02d", i)}
+		store.reminders = append(store.reminders,
+			overdueReminder(vehicleID, models.MaintenanceTypeOilChange),
+			overdueReminder(vehicleID, models.MaintenanceTypeBrakeService))
+	}
+	// Not overdue, so never part of the scan
+	store.reminders = append(store.reminders, &models.ServiceReminder{ID: primitive.NewObjectID(), VehicleID: primitive.NewObjectID()})
+
+	// Each page takes longer than half the lock TTL, so the scan outlasts the
+	// TTL and only holds the lock by renewing it
+	replicaBlocked := true
+	store.onPage = func() {
+		clock.Advance(40 * time.Second)
+		if acquired, _ := otherReplica.TryAcquire(overdueReminderLockKey, time.Minute); acquired {
+			replicaBlocked = false
+		}
+	}
+
+	mailer := &fakeMailer{}
+	notifier := NewOverdueReminderNotifier(store, vehicles, mailer, []string{"fleet@example.com"}, 8*time.Hour)
+	notifier.SetScanLimits(ReminderScanLimits{PageSize: 7, Concurrency: 3})
+	notifier.SetLocker(&fakeLocker{backend: backend, owner: "replica-1"}, time.Minute)
+
+	sent, err := notifier.Notify(context.Background(), clock.now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 80, sent)
+	assert.Equal(t, 12, store.pages, "80 reminders in pages of 7 take 12 queries")
+	assert.Equal(t, 40, vehicles.lookups, "each vehicle is looked up once")
+	assert.LessOrEqual(t, vehicles.maxSeen, 3)
+	assert.True(t, replicaBlocked, "another replica can't start a scan while one is running")
+
+	require.Len(t, mailer.sent, 1)
+	digest := mailer.sent[0]
+	assert.Equal(t, 80, digest.ReminderCount())
+	require.Len(t, digest.Vehicles, 40)
+	assert.Equal(t, "Vehicle 00", digest.Vehicles[0].VehicleName)
+	for _, reminder := range store.reminders[:80] {
+		assert.NotNil(t, reminder.LastNotifiedAt)
+	}
+
+	// The lock is released once the scan is done
+	acquired, err := otherReplica.TryAcquire(overdueReminderLockKey, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestOverdueReminderNotifier_SkipsScanWhileAnotherReplicaHoldsTheLock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 6, 8, 0, 0, 0, time.Local)}
+	backend := newFakeLockBackend(clock)
+	otherReplica := &fakeLocker{backend: backend, owner: "replica-2"}
+	_, err := otherReplica.TryAcquire(overdueReminderLockKey, time.Minute)
+	require.NoError(t, err)
+
+	store := &fakeReminderStore{reminders: []*models.ServiceReminder{
+		overdueReminder(primitive.NewObjectID(), models.MaintenanceTypeOilChange),
+	}}
+	mailer := &fakeMailer{}
+	notifier := NewOverdueReminderNotifier(store, fakeReminderVehicles{}, mailer, []string{"fleet@example.com"}, 8*time.Hour)
+	notifier.SetLocker(&fakeLocker{backend: backend, owner: "replica-1"}, time.Minute)
+
+	sent, err := notifier.Notify(context.Background(), clock.now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Zero(t, store.pages)
+	assert.Empty(t, mailer.sent)
+}
+
+func TestOverdueReminderNotifier_AbortsWhenLockIsLostMidScan(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 6, 8, 0, 0, 0, time.Local)}
+	backend := newFakeLockBackend(clock)
+
+	store := &fakeReminderStore{}
+	for i := 0; i < 10; i++ {
+		store.reminders = append(store.reminders, overdueReminder(primitive.NewObjectID(), models.MaintenanceTypeOilChange))
+	}
+	// A page slower than the whole TTL lets the lock expire before it is renewed
+	store.onPage = func() { clock.Advance(2 * time.Minute) }
+
+	mailer := &fakeMailer{}
+	notifier := NewOverdueReminderNotifier(store, fakeReminderVehicles{}, mailer, []string{"fleet@example.com"}, 8*time.Hour)
+	notifier.SetScanLimits(ReminderScanLimits{PageSize: 4, Concurrency: 2})
+	notifier.SetLocker(&fakeLocker{backend: backend, owner: "replica-1"}, time.Minute)
+
+	_, err := notifier.Notify(context.Background(), clock.now)
+	assert.ErrorIs(t, err, errReminderLockLost)
+	assert.Equal(t, 1, store.pages)
+	assert.Empty(t, mailer.sent)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecurringScheduleRule sets the intervals of the schedule kept for a
+// maintenance type. A zero IntervalKm uses the type's default interval.
+type RecurringScheduleRule struct {
+	IntervalKm   int
+	IntervalDays *int
+}
+
+// recurringScheduleStore is the subset of the maintenance repository used to keep schedules
+type recurringScheduleStore interface {
+	FindSchedulesByVehicleID(ctx context.Context, vehicleID string) ([]*models.MaintenanceSchedule, error)
+	CreateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule) error
+	UpdateSchedule(ctx context.Context, id string, schedule *models.MaintenanceSchedule) error
+}
+
+// maintenanceLog is the subset of the maintenance repository used to log records
+type maintenanceLog interface {
+	Create(ctx context.Context, record *models.MaintenanceRecord) error
+	recurringScheduleStore
+}
+
+// SetRecurringScheduleRules sets the maintenance types whose completed records
+// keep a recurring schedule going. Each rule's intervals must be within the
+// schedule interval bounds.
+func (s *MaintenanceService) SetRecurringScheduleRules(rules map[string]RecurringScheduleRule) error {
+	resolved := make(map[string]RecurringScheduleRule, len(rules))
+	for maintenanceType, rule := range rules {
+		if rule.IntervalKm == 0 {
+			interval, ok := models.DefaultServiceIntervals[maintenanceType]
+			if !ok {
+				return fmt.Errorf("%w: %s has no default interval, set intervalKm", ErrInvalidScheduleInterval, maintenanceType)
+			}
+			rule.IntervalKm = interval
+		}
+		if err := s.intervalBounds.Validate(rule.IntervalKm, rule.IntervalDays); err != nil {
+			return fmt.Errorf("recurring %s schedule: %w", maintenanceType, err)
+		}
+		resolved[maintenanceType] = rule
+	}
+
+	s.recurring = resolved
+	return nil
+}
+
+// logMaintenanceRecord stores a record and, if it is completed, keeps the
+// recurring schedules of its types going. estimate returns the expected date
+// of the service due at an odometer reading.
+func (s *MaintenanceService) logMaintenanceRecord(ctx context.Context, store maintenanceLog, record *models.MaintenanceRecord, estimate func(dueOdometer int) *time.Time) error {
+	if err := store.Create(ctx, record); err != nil {
+		return err
+	}
+	if record.Status == models.MaintenanceStatusCompleted {
+		s.maintainRecurringSchedules(ctx, store, record, estimate)
+	}
+	return nil
+}
+
+// maintainRecurringSchedules restarts the schedules a completed record serviced
+// and creates a schedule for each recurring type that has none. A schedule is
+// only restarted when the record serviced all of its types, and never moved
+// back by a record older than its last service. Failures are logged because
+// the record itself has already been stored.
+func (s *MaintenanceService) maintainRecurringSchedules(ctx context.Context, store recurringScheduleStore, record *models.MaintenanceRecord, estimate func(dueOdometer int) *time.Time) []*models.MaintenanceSchedule {
+	var recurringTypes []string
+	serviced := make(map[string]bool, len(record.Types))
+	for _, maintenanceType := range record.Types {
+		serviced[maintenanceType] = true
+		if _, ok := s.recurring[maintenanceType]; ok {
+			recurringTypes = append(recurringTypes, maintenanceType)
+		}
+	}
+	if len(recurringTypes) == 0 {
+		return nil
+	}
+
+	schedules, err := store.FindSchedulesByVehicleID(ctx, record.VehicleID.Hex())
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load maintenance schedules", "vehicle_id", record.VehicleID.Hex(), "error", err)
+		return nil
+	}
+
+	var maintained []*models.MaintenanceSchedule
+	covered := make(map[string]bool)
+	for _, schedule := range schedules {
+		if !schedule.IsActive {
+			continue
+		}
+		allServiced := true
+		for _, maintenanceType := range schedule.Types {
+			covered[maintenanceType] = true
+			allServiced = allServiced && serviced[maintenanceType]
+		}
+		if !allServiced || record.Odometer < schedule.LastServiceOdometer {
+			continue
+		}
+
+		schedule.LastServiceOdometer = record.Odometer
+		schedule.LastServiceDate = record.PerformedAt
+		schedule.NextServiceOdometer = record.Odometer + schedule.IntervalKm
+		schedule.NextServiceDate = nextScheduleDate(schedule, estimate)
+		if err := store.UpdateSchedule(ctx, schedule.ID.Hex(), schedule); err != nil {
+			logger.FromContext(ctx).Error("Failed to restart maintenance schedule", "schedule_id", schedule.ID.Hex(), "error", err)
+			continue
+		}
+		maintained = append(maintained, schedule)
+	}
+
+	for _, maintenanceType := range recurringTypes {
+		if covered[maintenanceType] {
+			continue
+		}
+
+		rule := s.recurring[maintenanceType]
+		schedule := &models.MaintenanceSchedule{
+			VehicleID:           record.VehicleID,
+			Types:               []string{maintenanceType},
+			Description:         "Recurring " + strings.ReplaceAll(maintenanceType, "_", " "),
+			IntervalKm:          rule.IntervalKm,
+			IntervalDays:        rule.IntervalDays,
+			LastServiceOdometer: record.Odometer,
+			LastServiceDate:     record.PerformedAt,
+			NextServiceOdometer: record.Odometer + rule.IntervalKm,
+			ServiceCenterName:   record.ServiceCenter,
+			IsActive:            true,
+		}
+		schedule.NextServiceDate = nextScheduleDate(schedule, estimate)
+		if err := store.CreateSchedule(ctx, schedule); err != nil {
+			logger.FromContext(ctx).Error("Failed to create recurring schedule", "maintenance_type", maintenanceType, "vehicle_id", record.VehicleID.Hex(), "error", err)
+			continue
+		}
+		s.broadcastScheduleCreated(schedule)
+		maintained = append(maintained, schedule)
+	}
+
+	return maintained
+}
+
+// nextScheduleDate is the schedule's time interval after its last service,
+// or the estimated date its odometer interval is reached
+func nextScheduleDate(schedule *models.MaintenanceSchedule, estimate func(dueOdometer int) *time.Time) *time.Time {
+	if schedule.IntervalDays != nil {
+		next := schedule.LastServiceDate.AddDate(0, 0, *schedule.IntervalDays)
+		return &next
+	}
+	if estimate == nil {
+		return nil
+	}
+	return estimate(schedule.NextServiceOdometer)
+}
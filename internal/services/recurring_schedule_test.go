@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryMaintenanceLog keeps maintenance records and schedules in memory
+type memoryMaintenanceLog struct {
+	records   []*models.MaintenanceRecord
+	schedules []*models.MaintenanceSchedule
+}
+
+func (m *memoryMaintenanceLog) Create(_ context.Context, record *models.MaintenanceRecord) error {
+	record.ID = primitive.NewObjectID()
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *memoryMaintenanceLog) FindSchedulesByVehicleID(_ context.Context, vehicleID string) ([]*models.MaintenanceSchedule, error) {
+	var found []*models.MaintenanceSchedule
+	for _, schedule := range m.schedules {
+		if schedule.VehicleID.Hex() == vehicleID {
+			copied := *schedule
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryMaintenanceLog) CreateSchedule(_ context.Context, schedule *models.MaintenanceSchedule) error {
+	schedule.ID = primitive.NewObjectID()
+	copied := *schedule
+	m.schedules = append(m.schedules, &copied)
+	return nil
+}
+
+func (m *memoryMaintenanceLog) UpdateSchedule(_ context.Context, id string, schedule *models.MaintenanceSchedule) error {
+	for i, existing := range m.schedules {
+		if existing.ID.Hex() == id {
+			copied := *schedule
+			m.schedules[i] = &copied
+			return nil
+		}
+	}
+	return nil
+}
+
+func newRecurringMaintenanceService(t *testing.T, rules map[string]RecurringScheduleRule) *MaintenanceService {
+	service := &MaintenanceService{intervalBounds: DefaultScheduleIntervalBounds()}
+	require.NoError(t, service.SetRecurringScheduleRules(rules))
+	return service
+}
+
+func completedService(vehicleID primitive.ObjectID, odometer int, performedAt time.Time, types ...string) *models.MaintenanceRecord {
+	return &models.MaintenanceRecord{
+		VehicleID:     vehicleID,
+		Types:         types,
+		PerformedAt:   performedAt,
+		Odometer:      odometer,
+		ServiceCenter: "Main Depot",
+		Status:        models.MaintenanceStatusCompleted,
+	}
+}
+
+func TestLogMaintenanceRecord_KeepsRecurringScheduleGoing(t *testing.T) {
+	days := 180
+	service := newRecurringMaintenanceService(t, map[string]RecurringScheduleRule{
+		models.MaintenanceTypeOilChange: {IntervalDays: &days},
+	})
+	store := &memoryMaintenanceLog{}
+	vehicleID := primitive.NewObjectID()
+	first := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	// The first oil change is logged and starts a schedule at the type's default interval
+	require.NoError(t, service.logMaintenanceRecord(context.Background(), store, completedService(vehicleID, 42000, first, models.MaintenanceTypeOilChange), nil))
+	require.Len(t, store.records, 1)
+	require.Len(t, store.schedules, 1)
+	schedule := store.schedules[0]
+	assert.Equal(t, []string{models.MaintenanceTypeOilChange}, schedule.Types)
+	assert.Equal(t, 10000, schedule.IntervalKm)
+	assert.Equal(t, 52000, schedule.NextServiceOdometer)
+	require.NotNil(t, schedule.NextServiceDate)
+	assert.Equal(t, first.AddDate(0, 0, 180), *schedule.NextServiceDate)
+	assert.Equal(t, "Main Depot", schedule.ServiceCenterName)
+	assert.True(t, schedule.IsActive)
+
+	// The next oil change restarts the same schedule
+	second := first.AddDate(0, 5, 0)
+	require.NoError(t, service.logMaintenanceRecord(context.Background(), store, completedService(vehicleID, 51500, second, models.MaintenanceTypeOilChange), nil))
+	require.Len(t, store.records, 2)
+	require.Len(t, store.schedules, 1)
+	assert.Equal(t, schedule.ID, store.schedules[0].ID)
+	assert.Equal(t, 51500, store.schedules[0].LastServiceOdometer)
+	assert.Equal(t, second, store.schedules[0].LastServiceDate)
+	assert.Equal(t, 61500, store.schedules[0].NextServiceOdometer)
+
+	// A backdated record doesn't move the schedule back
+	require.NoError(t, service.logMaintenanceRecord(context.Background(), store, completedService(vehicleID, 47000, first.AddDate(0, 2, 0), models.MaintenanceTypeOilChange), nil))
+	assert.Equal(t, 51500, store.schedules[0].LastServiceOdometer)
+}
+
+func TestLogMaintenanceRecord_OnlyConfiguredTypesAndCompletedRecords(t *testing.T) {
+	service := newRecurringMaintenanceService(t, map[string]RecurringScheduleRule{
+		models.MaintenanceTypeInspection: {IntervalKm: 15000},
+	})
+	store := &memoryMaintenanceLog{}
+	vehicleID := primitive.NewObjectID()
+	now := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	planned := completedService(vehicleID, 30000, now, models.MaintenanceTypeInspection)
+	planned.Status = models.MaintenanceStatusScheduled
+	require.NoError(t, service.logMaintenanceRecord(context.Background(), store, planned, nil))
+	assert.Empty(t, store.schedules, "only completed services start schedules")
+
+	estimated := now.AddDate(0, 3, 0)
+	estimate := func(dueOdometer int) *time.Time {
+		assert.Equal(t, 45000, dueOdometer)
+		return &estimated
+	}
+	require.NoError(t, service.logMaintenanceRecord(context.Background(), store, completedService(vehicleID, 30000, now,
+		models.MaintenanceTypeInspection, models.MaintenanceTypeRepair), estimate))
+	require.Len(t, store.schedules, 1, "repairs have no recurring rule")
+	assert.Equal(t, []string{models.MaintenanceTypeInspection}, store.schedules[0].Types)
+	assert.Equal(t, 15000, store.schedules[0].IntervalKm)
+	assert.Equal(t, &estimated, store.schedules[0].NextServiceDate)
+}
+
+func TestMaintainRecurringSchedules_RespectsSharedSchedules(t *testing.T) {
+	service := newRecurringMaintenanceService(t, map[string]RecurringScheduleRule{
+		models.MaintenanceTypeOilChange: {},
+	})
+	vehicleID := primitive.NewObjectID()
+	lastService := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	store := &memoryMaintenanceLog{schedules: []*models.MaintenanceSchedule{{
+		ID:                  primitive.NewObjectID(),
+		VehicleID:           vehicleID,
+		Types:               []string{models.MaintenanceTypeOilChange, models.MaintenanceTypeAirFilter},
+		IntervalKm:          10000,
+		LastServiceOdometer: 40000,
+		LastServiceDate:     lastService,
+		NextServiceOdometer: 50000,
+		IsActive:            true,
+	}}}
+
+	// An oil change alone doesn't complete the shared schedule, and doesn't get a second one
+	maintained := service.maintainRecurringSchedules(context.Background(), store, completedService(vehicleID, 49000, lastService.AddDate(0, 2, 0), models.MaintenanceTypeOilChange), nil)
+	assert.Empty(t, maintained)
+	require.Len(t, store.schedules, 1)
+	assert.Equal(t, 40000, store.schedules[0].LastServiceOdometer)
+
+	// Servicing both restarts it
+	maintained = service.maintainRecurringSchedules(context.Background(), store, completedService(vehicleID, 49500, lastService.AddDate(0, 2, 0),
+		models.MaintenanceTypeOilChange, models.MaintenanceTypeAirFilter), nil)
+	require.Len(t, maintained, 1)
+	assert.Equal(t, 59500, store.schedules[0].NextServiceOdometer)
+}
+
+func TestSetRecurringScheduleRules_Validation(t *testing.T) {
+	service := &MaintenanceService{intervalBounds: DefaultScheduleIntervalBounds()}
+
+	err := service.SetRecurringScheduleRules(map[string]RecurringScheduleRule{models.MaintenanceTypeRepair: {}})
+	assert.ErrorIs(t, err, ErrInvalidScheduleInterval, "repairs have no default interval")
+
+	err = service.SetRecurringScheduleRules(map[string]RecurringScheduleRule{models.MaintenanceTypeOilChange: {IntervalKm: 100}})
+	assert.ErrorIs(t, err, ErrInvalidScheduleInterval)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"math"
+	"time"
+)
+
+var (
+	// ErrDriverNotFound is returned when no vehicle is assigned to the driver
+	ErrDriverNotFound = newDomainError(ErrNotFound, "no vehicles are assigned to the driver")
+	// ErrInvalidScorecardRange is returned when a scorecard range doesn't end after it starts
+	ErrInvalidScorecardRange = newDomainError(ErrValidation, "scorecard range must end after it starts")
+)
+
+const (
+	// harshEventKmhPerSecond is the change in speed, about 0.3 g, from which
+	// an acceleration or braking is harsh
+	harshEventKmhPerSecond = 11.0
+	// harshEventMaxInterval is the longest time between readings a harsh event
+	// is detected across; over longer intervals a sharp change can't be told
+	// from a gradual one
+	harshEventMaxInterval = 10 * time.Second
+	// scorecardMinDistanceKm is the least distance events are normalized by,
+	// so a driver who barely drove isn't scored by a single event
+	scorecardMinDistanceKm = 10.0
+)
+
+// Points each component loses per event, or idle minute, per 100 km, and the
+// weight of each component in the overall score
+const (
+	speedingPenalty   = 10.0
+	harshEventPenalty = 5.0
+	idleMinutePenalty = 0.5
+
+	speedingWeight   = 0.4
+	harshEventWeight = 0.35
+	idleWeight       = 0.25
+)
+
+// scorecardVehicleFinder is the subset of the vehicle repository used by scorecards
+type scorecardVehicleFinder interface {
+	FindByDriver(ctx context.Context, driver string) ([]*models.Vehicle, error)
+}
+
+// scorecardAlertFinder is the subset of the alert repository used by scorecards
+type scorecardAlertFinder interface {
+	FindWithFilters(ctx context.Context, filter repository.AlertFilter, limit, offset int) ([]*models.Alert, int64, error)
+}
+
+// scorecardSpeedSource provides the speed readings harsh events and distance
+// are derived from
+type scorecardSpeedSource interface {
+	Samples(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error)
+}
+
+// scorecardIdleSource provides vehicles' idle time
+type scorecardIdleSource interface {
+	GetIdleStats(vehicleID string, from, to time.Time) (*models.IdleStats, error)
+}
+
+// ScorecardService scores drivers on speeding, harsh driving and idling
+// across the vehicles assigned to them
+type ScorecardService struct {
+	vehicles scorecardVehicleFinder
+	alerts   scorecardAlertFinder
+	speeds   scorecardSpeedSource
+	idle     scorecardIdleSource
+}
+
+func NewScorecardService(vehicleRepo *repository.VehicleRepository, alertRepo *repository.AlertRepository, speedHistory *SpeedHistory, idleTracker *IdleTracker) *ScorecardService {
+	return newScorecardService(vehicleRepo, alertRepo, speedHistory, idleTracker)
+}
+
+func newScorecardService(vehicles scorecardVehicleFinder, alerts scorecardAlertFinder, speeds scorecardSpeedSource, idle scorecardIdleSource) *ScorecardService {
+	return &ScorecardService{
+		vehicles: vehicles,
+		alerts:   alerts,
+		speeds:   speeds,
+		idle:     idle,
+	}
+}
+
+// Compute scores the driver within [from, to] over the vehicles assigned to
+// them now. Distance is the distance covered between speed readings, gaps
+// excluded.
+func (s *ScorecardService) Compute(ctx context.Context, driverID string, from, to time.Time) (*models.DriverScorecard, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidScorecardRange
+	}
+
+	vehicles, err := s.vehicles.FindByDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(vehicles) == 0 {
+		return nil, ErrDriverNotFound
+	}
+
+	scorecard := &models.DriverScorecard{
+		DriverID:   driverID,
+		From:       from,
+		To:         to,
+		VehicleIDs: make([]string, 0, len(vehicles)),
+	}
+	idleMinutes := 0.0
+	for _, vehicle := range vehicles {
+		vehicleID := vehicle.ID.Hex()
+		scorecard.VehicleIDs = append(scorecard.VehicleIDs, vehicleID)
+
+		_, speeding, err := s.alerts.FindWithFilters(ctx, repository.AlertFilter{
+			VehicleID: vehicleID,
+			Type:      "speeding",
+			From:      &from,
+			To:        &to,
+		}, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		scorecard.Speeding.Total += float64(speeding)
+
+		samples, err := s.speeds.Samples(vehicleID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		distance, harsh := analyzeDriving(samples)
+		scorecard.DistanceKm += distance
+		scorecard.HarshEvents.Total += float64(harsh)
+
+		idle, err := s.idle.GetIdleStats(vehicleID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		idleMinutes += idle.IdleSeconds / 60
+	}
+	scorecard.Idle.Total = idleMinutes
+
+	per100Km := 100 / math.Max(scorecard.DistanceKm, scorecardMinDistanceKm)
+	scoreComponent(&scorecard.Speeding, per100Km, speedingPenalty)
+	scoreComponent(&scorecard.HarshEvents, per100Km, harshEventPenalty)
+	scoreComponent(&scorecard.Idle, per100Km, idleMinutePenalty)
+	scorecard.DistanceKm = roundTo(scorecard.DistanceKm, 2)
+	scorecard.Idle.Total = roundTo(scorecard.Idle.Total, 2)
+	scorecard.Score = roundTo(speedingWeight*scorecard.Speeding.Score+
+		harshEventWeight*scorecard.HarshEvents.Score+
+		idleWeight*scorecard.Idle.Score, 1)
+
+	return scorecard, nil
+}
+
+// analyzeDriving returns the distance covered between readings sorted oldest
+// first, skipping gaps, and the number of harsh accelerations and brakings.
+// Consecutive harsh changes in the same direction are one event.
+func analyzeDriving(samples []*models.SpeedSample) (float64, int) {
+	distance := 0.0
+	harsh := 0
+	direction := 0.0 // of the harsh event in progress, 0 if none
+	for i := 1; i < len(samples); i++ {
+		previous, current := samples[i-1], samples[i]
+		held := current.Timestamp.Sub(previous.Timestamp)
+		if held <= 0 || held > speedSampleMaxGap {
+			direction = 0
+			continue
+		}
+		distance += float64(previous.SpeedKmh) * held.Hours()
+
+		change := float64(current.SpeedKmh - previous.SpeedKmh)
+		if held > harshEventMaxInterval || math.Abs(change)/held.Seconds() < harshEventKmhPerSecond {
+			direction = 0
+			continue
+		}
+		if direction == 0 || math.Signbit(change) != math.Signbit(direction) {
+			harsh++
+		}
+		direction = change
+	}
+	return distance, harsh
+}
+
+// scoreComponent normalizes a component's total per 100 km and takes penalty
+// points off a perfect score for each unit of it
+func scoreComponent(component *models.ScorecardComponent, per100Km, penalty float64) {
+	component.Per100Km = roundTo(component.Total*per100Km, 2)
+	component.Score = roundTo(math.Max(0, 100-component.Total*per100Km*penalty), 1)
+}
+
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scorecardFleet serves the vehicles, alerts, speed readings and idle time
+// scorecards are computed from
+type scorecardFleet struct {
+	vehicles []*models.Vehicle
+	alerts   []*models.Alert
+	samples  map[string][]*models.SpeedSample
+	idle     map[string]float64
+}
+
+func (f *scorecardFleet) FindByDriver(_ context.Context, driver string) ([]*models.Vehicle, error) {
+	var found []*models.Vehicle
+	for _, vehicle := range f.vehicles {
+		if vehicle.Driver == driver {
+			found = append(found, vehicle)
+		}
+	}
+	return found, nil
+}
+
+func (f *scorecardFleet) FindWithFilters(_ context.Context, filter repository.AlertFilter, _, _ int) ([]*models.Alert, int64, error) {
+	var found []*models.Alert
+	for _, alert := range f.alerts {
+		if alert.VehicleID == filter.VehicleID && alert.Type == filter.Type &&
+			!alert.Timestamp.Before(*filter.From) && !alert.Timestamp.After(*filter.To) {
+			found = append(found, alert)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+func (f *scorecardFleet) Samples(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error) {
+	return f.samples[vehicleID], nil
+}
+
+func (f *scorecardFleet) GetIdleStats(vehicleID string, from, to time.Time) (*models.IdleStats, error) {
+	return &models.IdleStats{VehicleID: vehicleID, IdleSeconds: f.idle[vehicleID]}, nil
+}
+
+// addDriver assigns a vehicle to the driver that drove steadily at 60 km/h
+// for two hours from start, reporting every minute
+func (f *scorecardFleet) addDriver(driver string, start time.Time) string {
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Driver: driver}
+	f.vehicles = append(f.vehicles, vehicle)
+	vehicleID := vehicle.ID.Hex()
+	for at := start; !at.After(start.Add(2 * time.Hour)); at = at.Add(time.Minute) {
+		f.samples[vehicleID] = append(f.samples[vehicleID], &models.SpeedSample{VehicleID: vehicleID, Timestamp: at, SpeedKmh: 60})
+	}
+	f.idle[vehicleID] = 600
+	return vehicleID
+}
+
+func TestScorecard_FrequentSpeedingScoresLowerThanCleanDriving(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	fleet := &scorecardFleet{samples: map[string][]*models.SpeedSample{}, idle: map[string]float64{}}
+	fleet.addDriver("clean", start)
+	speederVehicle := fleet.addDriver("speeder", start)
+	for i := 0; i < 6; i++ {
+		fleet.alerts = append(fleet.alerts, &models.Alert{VehicleID: speederVehicle, Type: "speeding", Timestamp: start.Add(time.Duration(i) * 15 * time.Minute)})
+	}
+	// Outside the period, so it doesn't count
+	fleet.alerts = append(fleet.alerts, &models.Alert{VehicleID: speederVehicle, Type: "speeding", Timestamp: start.Add(-time.Hour)})
+
+	service := newScorecardService(fleet, fleet, fleet, fleet)
+	clean, err := service.Compute(context.Background(), "clean", start, start.Add(2*time.Hour))
+	require.NoError(t, err)
+	speeder, err := service.Compute(context.Background(), "speeder", start, start.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	assert.Equal(t, 120.0, clean.DistanceKm)
+	assert.Equal(t, clean.DistanceKm, speeder.DistanceKm)
+
+	assert.Equal(t, 0.0, clean.Speeding.Total)
+	assert.Equal(t, 100.0, clean.Speeding.Score)
+	assert.Equal(t, 6.0, speeder.Speeding.Total)
+	assert.Equal(t, 5.0, speeder.Speeding.Per100Km)
+	assert.Equal(t, 50.0, speeder.Speeding.Score)
+
+	// Idling is the same for both, so only speeding sets them apart
+	assert.Equal(t, 10.0, clean.Idle.Total)
+	assert.Equal(t, clean.Idle.Score, speeder.Idle.Score)
+	assert.Less(t, speeder.Score, clean.Score)
+	assert.InDelta(t, clean.Score-speedingWeight*50, speeder.Score, 0.05)
+}
+
+func TestScorecard_UnknownDriverAndInvalidRange(t *testing.T) {
+	fleet := &scorecardFleet{samples: map[string][]*models.SpeedSample{}, idle: map[string]float64{}}
+	service := newScorecardService(fleet, fleet, fleet, fleet)
+	now := time.Now()
+
+	_, err := service.Compute(context.Background(), "nobody", now.Add(-time.Hour), now)
+	assert.ErrorIs(t, err, ErrDriverNotFound)
+
+	_, err = service.Compute(context.Background(), "nobody", now, now)
+	assert.ErrorIs(t, err, ErrInvalidScorecardRange)
+}
+
+func TestAnalyzeDriving_CountsHarshEventsAndSkipsGaps(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	speeds := []struct {
+		offset time.Duration
+		kmh    int
+	}{
+		{0, 0},
+		{time.Second, 15}, // harsh acceleration lasting two readings
+		{2 * time.Second, 30},
+		{3 * time.Second, 35},
+		{4 * time.Second, 20}, // harsh braking
+		{5 * time.Second, 30},
+		{time.Hour, 0}, // after a gap, not a harsh braking
+		{time.Hour + time.Minute, 60},
+	}
+	var samples []*models.SpeedSample
+	for _, speed := range speeds {
+		samples = append(samples, &models.SpeedSample{Timestamp: start.Add(speed.offset), SpeedKmh: speed.kmh})
+	}
+
+	distance, harsh := analyzeDriving(samples)
+	assert.Equal(t, 2, harsh)
+	assert.InDelta(t, (15+30+35+20)/3600.0, distance, 1e-9)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"time"
+)
+
+// DefaultSilentWindow is how long a vehicle may go without updates before it
+// is listed as silent, unless the caller picks a window
+const DefaultSilentWindow = 15 * time.Minute
+
+// ErrInvalidSilentWindow is returned for a window that isn't positive
+var ErrInvalidSilentWindow = newDomainError(ErrValidation, "silent window must be positive")
+
+// silentVehicleFinder is the subset of the vehicle repository used to find
+// vehicles that stopped reporting
+type silentVehicleFinder interface {
+	FindSilentSince(ctx context.Context, cutoff time.Time) ([]*models.Vehicle, error)
+}
+
+// GetSilentVehicles returns the vehicles without an update for at least window,
+// the longest silent first. Unlike connectivity, which the offline sweeper
+// updates periodically, this reads LastUpdate directly, so it also lists
+// trackers that went dark since the last sweep.
+func (s *VehicleService) GetSilentVehicles(ctx context.Context, window time.Duration) ([]*models.Vehicle, error) {
+	return getSilentVehicles(ctx, s.vehicleRepo, window, time.Now())
+}
+
+func getSilentVehicles(ctx context.Context, finder silentVehicleFinder, window time.Duration, now time.Time) ([]*models.Vehicle, error) {
+	if window <= 0 {
+		return nil, ErrInvalidSilentWindow
+	}
+
+	vehicles, err := finder.FindSilentSince(ctx, now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	if vehicles == nil {
+		vehicles = []*models.Vehicle{}
+	}
+	return vehicles, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memorySilentFinder matches vehicles the way the repository's silent vehicle
+// query does
+type memorySilentFinder struct {
+	vehicles []*models.Vehicle
+	cutoff   time.Time
+}
+
+func (m *memorySilentFinder) FindSilentSince(_ context.Context, cutoff time.Time) ([]*models.Vehicle, error) {
+	m.cutoff = cutoff
+	var silent []*models.Vehicle
+	for _, vehicle := range m.vehicles {
+		if vehicle.LastUpdate.Before(cutoff) {
+			silent = append(silent, vehicle)
+		}
+	}
+	sort.SliceStable(silent, func(i, j int) bool {
+		return silent[i].LastUpdate.Before(silent[j].LastUpdate)
+	})
+	return silent, nil
+}
+
+func TestGetSilentVehicles_ListsVehiclesPastTheWindowByStaleness(t *testing.T) {
+	now := time.Now()
+	finder := &memorySilentFinder{vehicles: []*models.Vehicle{
+		{Name: "fresh", Status: "active", LastUpdate: now.Add(-2 * time.Minute)},
+		{Name: "quiet", Status: "active", LastUpdate: now.Add(-45 * time.Minute)},
+		{Name: "edge", Status: "idle", LastUpdate: now.Add(-29 * time.Minute)},
+		// Stored status lags behind; the vehicle still reads as active
+		{Name: "dark", Status: "active", LastUpdate: now.Add(-6 * time.Hour)},
+		{Name: "never", Status: "offline"},
+	}}
+
+	vehicles, err := getSilentVehicles(context.Background(), finder, 30*time.Minute, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-30*time.Minute), finder.cutoff)
+
+	var names []string
+	for _, vehicle := range vehicles {
+		names = append(names, vehicle.Name)
+	}
+	assert.Equal(t, []string{"never", "dark", "quiet"}, names)
+}
+
+func TestGetSilentVehicles_EmptyAndInvalidWindows(t *testing.T) {
+	now := time.Now()
+	finder := &memorySilentFinder{vehicles: []*models.Vehicle{
+		{Name: "fresh", LastUpdate: now.Add(-time.Minute)},
+	}}
+
+	vehicles, err := getSilentVehicles(context.Background(), finder, DefaultSilentWindow, now)
+	require.NoError(t, err)
+	assert.NotNil(t, vehicles)
+	assert.Empty(t, vehicles)
+
+	_, err = getSilentVehicles(context.Background(), finder, 0, now)
+	assert.ErrorIs(t, err, ErrInvalidSilentWindow)
+}
+
+func TestBuildSilentVehicleFilter_MatchesStaleAndMissingUpdates(t *testing.T) {
+	cutoff := time.Now()
+	assert.Equal(t, bson.M{
+		"$or": bson.A{
+			bson.M{"last_update": bson.M{"$lt": cutoff}},
+			bson.M{"last_update": nil},
+		},
+	}, repository.BuildSilentVehicleFilter(cutoff))
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSpeedHistoryDisabled is returned when speed stats are requested without a speed history
+	ErrSpeedHistoryDisabled = errors.New("speed history is not enabled")
+	// ErrInvalidSpeedRange is returned when a speed stats range doesn't end after it starts
+	ErrInvalidSpeedRange = errors.New("speed stats range must end after it starts")
+	// ErrSpeedRangeTooLong is returned when a speed stats range spans more than MaxSpeedStatsRange
+	ErrSpeedRangeTooLong = errors.New("speed stats range must not exceed 7 days")
+)
+
+// MaxSpeedStatsRange is the longest range speed stats cover, as every reading
+// in the range is loaded to compute them
+const MaxSpeedStatsRange = 7 * 24 * time.Hour
+
+const (
+	// speedSampleBatchSize is how many readings are buffered before they are written
+	speedSampleBatchSize = 100
+	// speedSampleFlushInterval is how often buffered readings are written regardless
+	speedSampleFlushInterval = 5 * time.Second
+	// speedSampleMaxGap is the longest time between readings that still counts
+	// as continuous data; anything longer is a gap
+	speedSampleMaxGap = 5 * time.Minute
+
+	// DefaultSpeedWindowSize is how many recent readings are kept in memory per
+	// vehicle unless configured otherwise; at a 30 second reporting interval
+	// it covers the ETA window
+	DefaultSpeedWindowSize = 60
+)
+
+// speedSampleStore is the subset of the speed sample repository used by the history
+type speedSampleStore interface {
+	InsertMany(samples []*models.SpeedSample) error
+	FindInRange(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error)
+}
+
+// SpeedHistory keeps vehicles' speed readings for speed statistics. Readings
+// are buffered and written in batches; buffered readings are included in queries.
+//
+// The most recent readings of each vehicle are also kept in memory, in a
+// window of a fixed number of readings, for features that need them on every
+// update, such as ETAs. The window size trades memory for accuracy: each
+// reading costs about 100 bytes, so a 60-reading window is roughly 6 KB per
+// vehicle, but a window holding fewer readings than arrive in the period a
+// feature looks back over (30 minutes for ETAs) only covers the most recent
+// part of it, making averages noisier and quicker to swing.
+type SpeedHistory struct {
+	store      speedSampleStore
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+	windowSize int
+
+	mu      sync.Mutex
+	pending []*models.SpeedSample
+	windows map[string]*speedWindow
+	// flushing is set while a flush started by a full buffer is running, so
+	// readings arriving meanwhile wait for it instead of starting another
+	flushing bool
+}
+
+func NewSpeedHistory(repo *repository.SpeedSampleRepository) *SpeedHistory {
+	return newSpeedHistory(repo)
+}
+
+func newSpeedHistory(store speedSampleStore) *SpeedHistory {
+	return &SpeedHistory{
+		store:      store,
+		stopChan:   make(chan struct{}),
+		windowSize: DefaultSpeedWindowSize,
+		windows:    make(map[string]*speedWindow),
+	}
+}
+
+// SetWindowSize sets how many recent readings are kept in memory per vehicle.
+// Windows already holding more readings drop their oldest ones.
+func (h *SpeedHistory) SetWindowSize(size int) {
+	if size <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.windowSize = size
+	for _, window := range h.windows {
+		window.resize(size)
+	}
+}
+
+// RecordSpeed buffers a speed reading taken at at. The location, when known,
+// is used to look up the speed limit that applied.
+func (h *SpeedHistory) RecordSpeed(vehicleID string, speedKmh int, location *models.Location, at time.Time) {
+	sample := &models.SpeedSample{VehicleID: vehicleID, Timestamp: at, SpeedKmh: speedKmh}
+	if location != nil {
+		loc := *location
+		sample.Location = &loc
+	}
+
+	h.mu.Lock()
+	h.pending = append(h.pending, sample)
+	flush := len(h.pending) >= speedSampleBatchSize && !h.flushing
+	if flush {
+		h.flushing = true
+	}
+	window := h.windows[vehicleID]
+	if window == nil {
+		window = newSpeedWindow(h.windowSize)
+		h.windows[vehicleID] = window
+	}
+	window.add(sample)
+	h.mu.Unlock()
+
+	if flush {
+		go func() {
+			h.Flush()
+			h.mu.Lock()
+			h.flushing = false
+			h.mu.Unlock()
+		}()
+	}
+}
+
+// Start writes buffered readings every flush interval until Stop is called
+func (h *SpeedHistory) Start() {
+	ticker := time.NewTicker(speedSampleFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.Flush()
+		case <-h.stopChan:
+			return
+		}
+	}
+}
+
+// Stop stops periodic writes and writes the readings still buffered
+func (h *SpeedHistory) Stop() {
+	h.stopOnce.Do(func() { close(h.stopChan) })
+	h.Flush()
+}
+
+// Flush writes the buffered readings
+func (h *SpeedHistory) Flush() {
+	h.mu.Lock()
+	samples := h.pending
+	h.pending = nil
+	h.mu.Unlock()
+
+	if len(samples) == 0 {
+		return
+	}
+	if err := h.store.InsertMany(samples); err != nil {
+		fmt.Printf("Failed to persist %d speed samples: %v\n", len(samples), err)
+	}
+}
+
+// Samples returns a vehicle's readings within [from, to], oldest first
+func (h *SpeedHistory) Samples(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error) {
+	samples, err := h.store.FindInRange(vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	for _, sample := range h.pending {
+		if sample.VehicleID == vehicleID && !sample.Timestamp.Before(from) && !sample.Timestamp.After(to) {
+			samples = append(samples, sample)
+		}
+	}
+	h.mu.Unlock()
+
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Timestamp.Before(samples[j].Timestamp) })
+	return samples, nil
+}
+
+// RecentSamples returns the vehicle's readings within [from, to] that are held
+// in memory, oldest first. Only readings this instance received are included, and
+// at most the window size of them.
+func (h *SpeedHistory) RecentSamples(vehicleID string, from, to time.Time) []*models.SpeedSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	window := h.windows[vehicleID]
+	if window == nil {
+		return nil
+	}
+	return window.between(from, to)
+}
+
+// Forget frees the vehicle's in-memory readings. Stored and buffered readings
+// are kept.
+func (h *SpeedHistory) Forget(vehicleID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.windows, vehicleID)
+}
+
+// speedWindow is a ring buffer of a vehicle's most recent readings, oldest
+// first; when full, a new reading replaces the oldest
+type speedWindow struct {
+	samples []*models.SpeedSample
+	start   int
+	count   int
+}
+
+func newSpeedWindow(size int) *speedWindow {
+	return &speedWindow{samples: make([]*models.SpeedSample, size)}
+}
+
+// at returns the i-th oldest reading
+func (w *speedWindow) at(i int) *models.SpeedSample {
+	return w.samples[(w.start+i)%len(w.samples)]
+}
+
+func (w *speedWindow) set(i int, sample *models.SpeedSample) {
+	w.samples[(w.start+i)%len(w.samples)] = sample
+}
+
+// add inserts a reading in time order. Readings usually arrive in order, so
+// out-of-order ones are moved back from the newest end.
+func (w *speedWindow) add(sample *models.SpeedSample) {
+	if w.count == len(w.samples) {
+		if sample.Timestamp.Before(w.at(0).Timestamp) {
+			// Older than everything in a full window
+			return
+		}
+		w.samples[w.start] = nil
+		w.start = (w.start + 1) % len(w.samples)
+		w.count--
+	}
+
+	i := w.count
+	w.count++
+	for ; i > 0 && sample.Timestamp.Before(w.at(i-1).Timestamp); i-- {
+		w.set(i, w.at(i-1))
+	}
+	w.set(i, sample)
+}
+
+// between returns the readings within [from, to], oldest first
+func (w *speedWindow) between(from, to time.Time) []*models.SpeedSample {
+	var samples []*models.SpeedSample
+	for i := 0; i < w.count; i++ {
+		if sample := w.at(i); !sample.Timestamp.Before(from) && !sample.Timestamp.After(to) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// resize keeps the newest readings that fit in size
+func (w *speedWindow) resize(size int) {
+	resized := make([]*models.SpeedSample, size)
+	keep := w.count
+	if keep > size {
+		keep = size
+	}
+	for i := 0; i < keep; i++ {
+		resized[i] = w.at(w.count - keep + i)
+	}
+	w.samples = resized
+	w.start = 0
+	w.count = keep
+}
+
+// aggregateSpeedStats summarizes readings sorted oldest first. Each reading
+// holds until the next one, unless they are more than maxGap apart; limitFor
+// returns the speed limit that applied to a reading.
+func aggregateSpeedStats(samples []*models.SpeedSample, limitFor func(*models.SpeedSample) int, maxGap time.Duration) models.SpeedStats {
+	stats := models.SpeedStats{SampleCount: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	speeds := make([]int, len(samples))
+	total := 0
+	weighted := 0.0
+	for i, sample := range samples {
+		speeds[i] = sample.SpeedKmh
+		total += sample.SpeedKmh
+		if sample.SpeedKmh > stats.MaxKmh {
+			stats.MaxKmh = sample.SpeedKmh
+		}
+
+		if i == len(samples)-1 {
+			break
+		}
+		held := samples[i+1].Timestamp.Sub(sample.Timestamp).Seconds()
+		if held > maxGap.Seconds() {
+			stats.GapCount++
+			stats.GapSeconds += held
+			continue
+		}
+		stats.CoveredSeconds += held
+		weighted += float64(sample.SpeedKmh) * held
+		if sample.SpeedKmh > limitFor(sample) {
+			stats.OverLimitSeconds += held
+		}
+	}
+
+	if stats.CoveredSeconds > 0 {
+		stats.AverageKmh = weighted / stats.CoveredSeconds
+	} else {
+		// Isolated readings carry no duration, so they count equally
+		stats.AverageKmh = float64(total) / float64(len(samples))
+	}
+
+	// Nearest-rank percentile
+	sort.Ints(speeds)
+	rank := int(math.Ceil(0.95 * float64(len(speeds))))
+	stats.P95Kmh = speeds[rank-1]
+
+	return stats
+}
+
+// SetSpeedHistory enables speed statistics and records the speed of vehicle updates
+func (s *VehicleService) SetSpeedHistory(history *SpeedHistory) {
+	s.speedHistory = history
+}
+
+// GetSpeedStats summarizes a vehicle's speed readings within [from, to]. Time
+// over the limit uses the limits in effect now, including speed zones.
+func (s *VehicleService) GetSpeedStats(ctx context.Context, id string, from, to time.Time) (*models.SpeedStats, error) {
+	if s.speedHistory == nil {
+		return nil, ErrSpeedHistoryDisabled
+	}
+	if !to.After(from) {
+		return nil, ErrInvalidSpeedRange
+	}
+	if to.Sub(from) > MaxSpeedStatsRange {
+		return nil, ErrSpeedRangeTooLong
+	}
+	vehicle, err := s.GetVehicleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := s.speedHistory.Samples(id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := aggregateSpeedStats(samples, func(sample *models.SpeedSample) int {
+		location := vehicle.Location
+		if sample.Location != nil {
+			location = *sample.Location
+		}
+		return s.speedLimitAt(vehicle, location).Kmh
+	}, speedSampleMaxGap)
+	stats.VehicleID = id
+	stats.From = from
+	stats.To = to
+	return &stats, nil
+}
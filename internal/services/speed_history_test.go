@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySpeedStore keeps speed readings in memory
+type memorySpeedStore struct {
+	mu      sync.Mutex
+	samples []*models.SpeedSample
+}
+
+func (m *memorySpeedStore) InsertMany(samples []*models.SpeedSample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, samples...)
+	return nil
+}
+
+func (m *memorySpeedStore) FindInRange(vehicleID string, from, to time.Time) ([]*models.SpeedSample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []*models.SpeedSample
+	for _, sample := range m.samples {
+		if sample.VehicleID == vehicleID && !sample.Timestamp.Before(from) && !sample.Timestamp.After(to) {
+			found = append(found, sample)
+		}
+	}
+	return found, nil
+}
+
+// blockingSpeedStore holds every write until released and counts the writes
+// started
+type blockingSpeedStore struct {
+	memorySpeedStore
+	release chan struct{}
+
+	writesMu sync.Mutex
+	writes   int
+}
+
+func (b *blockingSpeedStore) InsertMany(samples []*models.SpeedSample) error {
+	b.writesMu.Lock()
+	b.writes++
+	b.writesMu.Unlock()
+
+	<-b.release
+	return b.memorySpeedStore.InsertMany(samples)
+}
+
+func fixedLimit(kmh int) func(*models.SpeedSample) int {
+	return func(*models.SpeedSample) int { return kmh }
+}
+
+func TestAggregateSpeedStats_SeededSeries(t *testing.T) {
+	// 5, 10, ..., 100 km/h, one reading every 10 seconds
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	var samples []*models.SpeedSample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, &models.SpeedSample{
+			VehicleID: "v1",
+			Timestamp: start.Add(time.Duration(i-1) * 10 * time.Second),
+			SpeedKmh:  i * 5,
+		})
+	}
+
+	stats := aggregateSpeedStats(samples, fixedLimit(80), speedSampleMaxGap)
+
+	assert.Equal(t, 20, stats.SampleCount)
+	assert.Equal(t, 100, stats.MaxKmh)
+	// Nearest rank: the 19th of 20 sorted readings
+	assert.Equal(t, 95, stats.P95Kmh)
+	// The last reading holds for no time, so the average covers 5..95 km/h
+	assert.InDelta(t, 50, stats.AverageKmh, 0.001)
+	assert.InDelta(t, 190, stats.CoveredSeconds, 0.001)
+	// 85, 90 and 95 km/h each held for 10 seconds
+	assert.InDelta(t, 30, stats.OverLimitSeconds, 0.001)
+	assert.Zero(t, stats.GapCount)
+}
+
+func TestAggregateSpeedStats_GapsCountTowardsNeitherAverageNorOverLimit(t *testing.T) {
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	samples := []*models.SpeedSample{
+		{Timestamp: start, SpeedKmh: 90},
+		{Timestamp: start.Add(time.Minute), SpeedKmh: 90},
+		// The tracker went quiet for ten minutes
+		{Timestamp: start.Add(11 * time.Minute), SpeedKmh: 90},
+		{Timestamp: start.Add(12 * time.Minute), SpeedKmh: 30},
+	}
+
+	stats := aggregateSpeedStats(samples, fixedLimit(80), speedSampleMaxGap)
+
+	assert.Equal(t, 1, stats.GapCount)
+	assert.InDelta(t, 600, stats.GapSeconds, 0.001)
+	assert.InDelta(t, 120, stats.CoveredSeconds, 0.001)
+	assert.InDelta(t, 120, stats.OverLimitSeconds, 0.001)
+	assert.InDelta(t, 90, stats.AverageKmh, 0.001)
+}
+
+func TestAggregateSpeedStats_UsesLimitPerReading(t *testing.T) {
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	samples := []*models.SpeedSample{
+		{Timestamp: start, SpeedKmh: 70, Location: &depotLocation},
+		{Timestamp: start.Add(30 * time.Second), SpeedKmh: 70, Location: &highwayLocation},
+		{Timestamp: start.Add(60 * time.Second), SpeedKmh: 70, Location: &highwayLocation},
+	}
+	resolver := newZoneSpeedLimitResolver(&fakeGeofenceLister{geofences: newSpeedZones()})
+	limitFor := func(sample *models.SpeedSample) int {
+		return resolver.ResolveSpeedLimit(&models.Vehicle{}, *sample.Location).Kmh
+	}
+
+	stats := aggregateSpeedStats(samples, limitFor, speedSampleMaxGap)
+
+	// Only the reading inside the 60 km/h depot zone is over its limit
+	assert.InDelta(t, 30, stats.OverLimitSeconds, 0.001)
+}
+
+func TestAggregateSpeedStats_IsolatedReadings(t *testing.T) {
+	stats := aggregateSpeedStats(nil, fixedLimit(80), speedSampleMaxGap)
+	assert.Zero(t, stats.SampleCount)
+
+	stats = aggregateSpeedStats([]*models.SpeedSample{{SpeedKmh: 40}}, fixedLimit(80), speedSampleMaxGap)
+	assert.Equal(t, 40, stats.P95Kmh)
+	assert.InDelta(t, 40, stats.AverageKmh, 0.001)
+	assert.Zero(t, stats.OverLimitSeconds)
+}
+
+func TestSpeedHistory_SamplesIncludeBufferedReadings(t *testing.T) {
+	store := &memorySpeedStore{}
+	history := newSpeedHistory(store)
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	history.RecordSpeed("v1", 40, nil, start.Add(20*time.Second))
+	history.RecordSpeed("v2", 70, nil, start.Add(10*time.Second))
+	history.Flush()
+	history.RecordSpeed("v1", 50, &depotLocation, start.Add(10*time.Second))
+	history.RecordSpeed("v1", 60, nil, start.Add(time.Hour))
+
+	samples, err := history.Samples("v1", start, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 50, samples[0].SpeedKmh, "buffered and stored readings are merged in time order")
+	assert.Equal(t, depotLocation, *samples[0].Location)
+	assert.Equal(t, 40, samples[1].SpeedKmh)
+
+	history.Stop()
+	assert.Len(t, store.samples, 4)
+}
+
+func TestSpeedHistory_WindowNeverExceedsSize(t *testing.T) {
+	history := newSpeedHistory(&memorySpeedStore{})
+	history.SetWindowSize(5)
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	all := start.Add(-time.Hour)
+
+	for i := 0; i < 12; i++ {
+		history.RecordSpeed("v1", i, nil, start.Add(time.Duration(i)*time.Second))
+		assert.LessOrEqual(t, len(history.RecentSamples("v1", all, start.Add(time.Hour))), 5)
+	}
+
+	speeds := func() []int {
+		var speeds []int
+		for _, sample := range history.RecentSamples("v1", all, start.Add(time.Hour)) {
+			speeds = append(speeds, sample.SpeedKmh)
+		}
+		return speeds
+	}
+	assert.Equal(t, []int{7, 8, 9, 10, 11}, speeds(), "the newest readings are kept")
+
+	// A late reading is placed in time order, and one older than the whole window is dropped
+	history.RecordSpeed("v1", 99, nil, start.Add(8500*time.Millisecond))
+	history.RecordSpeed("v1", 98, nil, start)
+	assert.Equal(t, []int{8, 99, 9, 10, 11}, speeds())
+
+	window := history.RecentSamples("v1", start.Add(9*time.Second), start.Add(10*time.Second))
+	require.Len(t, window, 2)
+	assert.Equal(t, 9, window[0].SpeedKmh)
+
+	// Shrinking keeps the newest readings
+	history.SetWindowSize(2)
+	assert.Equal(t, []int{10, 11}, speeds())
+}
+
+func TestSpeedHistory_DeletingVehicleFreesWindow(t *testing.T) {
+	store := &memorySpeedStore{}
+	history := newSpeedHistory(store)
+	service := &VehicleService{speedHistory: history}
+	archived := newArchiveTestVehicle()
+	deleted := newArchiveTestVehicle()
+	vehicleStore := newFakeArchiveStore(archived, deleted)
+	now := time.Now()
+
+	for _, vehicle := range []*models.Vehicle{archived, deleted} {
+		history.RecordSpeed(vehicle.ID.Hex(), 50, nil, now)
+	}
+	history.RecordSpeed("other", 50, nil, now)
+	require.Len(t, history.windows, 3)
+
+	require.NoError(t, service.archiveVehicle(context.Background(), vehicleStore, archived.ID.Hex()))
+	require.NoError(t, service.hardDeleteVehicle(context.Background(), vehicleStore, deleted.ID.Hex()))
+
+	assert.Len(t, history.windows, 1)
+	assert.Empty(t, history.RecentSamples(deleted.ID.Hex(), now.Add(-time.Minute), now))
+	assert.NotEmpty(t, history.RecentSamples("other", now.Add(-time.Minute), now))
+
+	// The readings themselves are still written
+	history.Stop()
+	assert.Len(t, store.samples, 3)
+}
+
+func TestSpeedHistory_FullBufferStartsOneFlushAtATime(t *testing.T) {
+	store := &blockingSpeedStore{release: make(chan struct{})}
+	history := newSpeedHistory(store)
+	start := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	writes := func() int {
+		store.writesMu.Lock()
+		defer store.writesMu.Unlock()
+		return store.writes
+	}
+	record := func(from, count int) {
+		for i := from; i < from+count; i++ {
+			history.RecordSpeed("v1", 50, nil, start.Add(time.Duration(i)*time.Second))
+		}
+	}
+
+	record(0, speedSampleBatchSize)
+	require.Eventually(t, func() bool { return writes() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Fill the buffer several times over while the first write is stuck
+	record(speedSampleBatchSize, 4*speedSampleBatchSize)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, writes(), "no other write starts while one is running")
+
+	close(store.release)
+	history.Stop()
+	assert.Eventually(t, func() bool {
+		samples, _ := store.FindInRange("v1", start, start.Add(time.Hour))
+		return len(samples) == 5*speedSampleBatchSize
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGetSpeedStats_RejectsRangeOverMaximum(t *testing.T) {
+	service := &VehicleService{speedHistory: newSpeedHistory(&memorySpeedStore{})}
+	to := time.Now()
+
+	_, err := service.GetSpeedStats(context.Background(), "v1", to.Add(-MaxSpeedStatsRange-time.Hour), to)
+
+	assert.ErrorIs(t, err, ErrSpeedRangeTooLong)
+}
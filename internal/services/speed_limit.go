@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Where an applicable speed limit comes from
+const (
+	SpeedLimitSourceDefault = "default"
+	SpeedLimitSourceVehicle = "vehicle"
+	SpeedLimitSourceZone    = "zone"
+)
+
+// speedZoneRefreshInterval is how long the zone resolver reuses its loaded zones
+const speedZoneRefreshInterval = 30 * time.Second
+
+// SpeedLimit is the limit a speed reading is checked against
+type SpeedLimit struct {
+	Kmh    int
+	Source string
+	// ZoneID and ZoneName identify the geofence for zone limits
+	ZoneID   string
+	ZoneName string
+}
+
+// alertData describes the limit in a speeding alert's payload
+func (l SpeedLimit) alertData(speed int) map[string]interface{} {
+	data := map[string]interface{}{
+		"speed":            speed,
+		"speedLimit":       l.Kmh,
+		"speedLimitSource": l.Source,
+	}
+	if l.Source == SpeedLimitSourceZone {
+		data["zoneId"] = l.ZoneID
+		data["zoneName"] = l.ZoneName
+	}
+	return data
+}
+
+// SpeedLimitResolver returns the speed limit that applies to a vehicle at a location
+type SpeedLimitResolver interface {
+	ResolveSpeedLimit(vehicle *models.Vehicle, location models.Location) SpeedLimit
+}
+
+// vehicleSpeedLimit is the vehicle's configured limit, or the global default
+func vehicleSpeedLimit(vehicle *models.Vehicle) SpeedLimit {
+	if vehicle.SpeedLimitKmh > 0 {
+		return SpeedLimit{Kmh: vehicle.SpeedLimitKmh, Source: SpeedLimitSourceVehicle}
+	}
+	return SpeedLimit{Kmh: speedLimitKmh, Source: SpeedLimitSourceDefault}
+}
+
+// ZoneSpeedLimitResolver applies the limits of geofences with a speed limit.
+// Inside overlapping zones the lowest limit applies, and a vehicle's own limit
+// applies when it is lower still. Outside every zone the vehicle's limit or
+// the global default applies.
+type ZoneSpeedLimitResolver struct {
+	geofences geofenceLister
+	now       func() time.Time
+
+	mu       sync.Mutex
+	zones    []*models.Geofence
+	loadedAt time.Time
+	loading  bool
+}
+
+func NewZoneSpeedLimitResolver(geofenceRepo *repository.GeofenceRepository) *ZoneSpeedLimitResolver {
+	return newZoneSpeedLimitResolver(geofenceRepo)
+}
+
+func newZoneSpeedLimitResolver(geofences geofenceLister) *ZoneSpeedLimitResolver {
+	return &ZoneSpeedLimitResolver{
+		geofences: geofences,
+		now:       time.Now,
+	}
+}
+
+func (r *ZoneSpeedLimitResolver) ResolveSpeedLimit(vehicle *models.Vehicle, location models.Location) SpeedLimit {
+	limit := vehicleSpeedLimit(vehicle)
+
+	var zone *models.Geofence
+	for _, candidate := range r.speedZones() {
+		if GeofenceContains(candidate, location) && (zone == nil || candidate.SpeedLimitKmh < zone.SpeedLimitKmh) {
+			zone = candidate
+		}
+	}
+	if zone == nil {
+		return limit
+	}
+
+	if limit.Source == SpeedLimitSourceVehicle && limit.Kmh < zone.SpeedLimitKmh {
+		return limit
+	}
+	return SpeedLimit{
+		Kmh:      zone.SpeedLimitKmh,
+		Source:   SpeedLimitSourceZone,
+		ZoneID:   zone.ID.Hex(),
+		ZoneName: zone.Name,
+	}
+}
+
+// speedZones returns the geofences that carry a speed limit, reloading them
+// once the refresh interval has passed. Zones are loaded without holding the
+// lock, and callers arriving while a reload runs keep using the previously
+// loaded zones, as they do if reloading fails.
+func (r *ZoneSpeedLimitResolver) speedZones() []*models.Geofence {
+	r.mu.Lock()
+	now := r.now()
+	fresh := !r.loadedAt.IsZero() && now.Sub(r.loadedAt) < speedZoneRefreshInterval
+	if fresh || r.loading {
+		zones := r.zones
+		r.mu.Unlock()
+		return zones
+	}
+	r.loading = true
+	r.mu.Unlock()
+
+	geofences, err := r.geofences.FindAll()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loading = false
+	if err != nil {
+		fmt.Printf("Failed to load speed zones: %v\n", err)
+		return r.zones
+	}
+
+	zones := make([]*models.Geofence, 0, len(geofences))
+	for _, geofence := range geofences {
+		if geofence.SpeedLimitKmh > 0 {
+			zones = append(zones, geofence)
+		}
+	}
+	r.zones = zones
+	r.loadedAt = now
+	return zones
+}
+
+// SetSpeedLimitResolver sets how the speed limit for speeding alerts is
+// chosen. Without a resolver a vehicle's own limit or the global default applies.
+func (s *VehicleService) SetSpeedLimitResolver(resolver SpeedLimitResolver) {
+	s.speedLimits = resolver
+
+	// Speeding alerts resolve against the same limit that raised them
+	if s.autoResolve != nil && s.autoResolve.HasRule("speeding") {
+		s.autoResolve.Register("speeding", func(vehicle *models.Vehicle, alert *models.Alert) bool {
+			return vehicle.Speed <= s.speedLimitAt(vehicle, vehicle.Location).Kmh
+		})
+	}
+}
+
+// speedLimitAt returns the limit that applies to the vehicle at location
+func (s *VehicleService) speedLimitAt(vehicle *models.Vehicle, location models.Location) SpeedLimit {
+	if s.speedLimits == nil {
+		return vehicleSpeedLimit(vehicle)
+	}
+	return s.speedLimits.ResolveSpeedLimit(vehicle, location)
+}
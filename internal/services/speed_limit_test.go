@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// Inside the 60 km/h urban depot zone
+	depotLocation = models.Location{Lat: -1.2921, Lng: 36.8219}
+	// On the 110 km/h highway
+	highwayLocation = models.Location{Lat: -1.3500, Lng: 36.9000}
+	// Outside every speed zone
+	openRoadLocation = models.Location{Lat: -1.5000, Lng: 37.2000}
+)
+
+func newSpeedZones() []*models.Geofence {
+	return []*models.Geofence{
+		{
+			ID:            primitive.NewObjectID(),
+			Name:          "Urban Depot",
+			Type:          models.GeofenceTypeCircle,
+			Center:        &models.GeoPoint{Lat: -1.2921, Lng: 36.8219},
+			RadiusMeters:  500,
+			SpeedLimitKmh: 60,
+		},
+		{
+			ID:   primitive.NewObjectID(),
+			Name: "Highway",
+			Type: models.GeofenceTypePolygon,
+			Polygon: []models.GeoPoint{
+				{Lat: -1.340, Lng: 36.880},
+				{Lat: -1.340, Lng: 36.920},
+				{Lat: -1.360, Lng: 36.920},
+				{Lat: -1.360, Lng: 36.880},
+			},
+			SpeedLimitKmh: 110,
+		},
+		{
+			// Geofences without a limit don't affect speeding
+			ID:           primitive.NewObjectID(),
+			Name:         "Customer Site",
+			Type:         models.GeofenceTypeCircle,
+			Center:       &models.GeoPoint{Lat: -1.3500, Lng: 36.9000},
+			RadiusMeters: 300,
+		},
+	}
+}
+
+func newSpeedZoneService() *VehicleService {
+	service := &VehicleService{
+		severityPolicy: DefaultAlertSeverityPolicy(),
+		autoResolve:    DefaultAutoResolveRegistry(),
+	}
+	service.SetSpeedLimitResolver(newZoneSpeedLimitResolver(&fakeGeofenceLister{geofences: newSpeedZones()}))
+	return service
+}
+
+func TestCheckSpeeding_UsesZoneLimitAtLocation(t *testing.T) {
+	service := newSpeedZoneService()
+
+	inDepot := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 90, Location: depotLocation}
+	service.checkSpeeding(context.Background(), inDepot)
+	require.Len(t, inDepot.Alerts, 1)
+	assert.Equal(t, "speeding", inDepot.Alerts[0].Type)
+
+	onHighway := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 90, Location: highwayLocation}
+	service.checkSpeeding(context.Background(), onHighway)
+	assert.Empty(t, onHighway.Alerts, "90 km/h is within the 110 km/h highway limit")
+
+	onOpenRoad := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 90, Location: openRoadLocation}
+	service.checkSpeeding(context.Background(), onOpenRoad)
+	assert.Len(t, onOpenRoad.Alerts, 1, "the default limit applies outside speed zones")
+}
+
+func TestZoneSpeedLimitResolver_Precedence(t *testing.T) {
+	resolver := newZoneSpeedLimitResolver(&fakeGeofenceLister{geofences: newSpeedZones()})
+
+	tests := []struct {
+		name       string
+		vehicleKmh int
+		location   models.Location
+		wantKmh    int
+		wantSource string
+	}{
+		{name: "zone", location: depotLocation, wantKmh: 60, wantSource: SpeedLimitSourceZone},
+		{name: "zone above default", location: highwayLocation, wantKmh: 110, wantSource: SpeedLimitSourceZone},
+		{name: "default outside zones", location: openRoadLocation, wantKmh: speedLimitKmh, wantSource: SpeedLimitSourceDefault},
+		{name: "vehicle outside zones", vehicleKmh: 90, location: openRoadLocation, wantKmh: 90, wantSource: SpeedLimitSourceVehicle},
+		{name: "stricter vehicle limit wins in zone", vehicleKmh: 90, location: highwayLocation, wantKmh: 90, wantSource: SpeedLimitSourceVehicle},
+		{name: "stricter zone limit wins", vehicleKmh: 90, location: depotLocation, wantKmh: 60, wantSource: SpeedLimitSourceZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vehicle := &models.Vehicle{SpeedLimitKmh: tt.vehicleKmh}
+			limit := resolver.ResolveSpeedLimit(vehicle, tt.location)
+			assert.Equal(t, tt.wantKmh, limit.Kmh)
+			assert.Equal(t, tt.wantSource, limit.Source)
+		})
+	}
+}
+
+func TestZoneSpeedLimitResolver_ReloadsZonesAfterInterval(t *testing.T) {
+	lister := &fakeGeofenceLister{}
+	resolver := newZoneSpeedLimitResolver(lister)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	vehicle := &models.Vehicle{}
+	assert.Equal(t, speedLimitKmh, resolver.ResolveSpeedLimit(vehicle, depotLocation).Kmh)
+
+	lister.geofences = newSpeedZones()
+	assert.Equal(t, speedLimitKmh, resolver.ResolveSpeedLimit(vehicle, depotLocation).Kmh, "zones are cached")
+
+	now = now.Add(speedZoneRefreshInterval)
+	assert.Equal(t, 60, resolver.ResolveSpeedLimit(vehicle, depotLocation).Kmh)
+}
+
+// blockingGeofenceLister holds FindAll until released
+type blockingGeofenceLister struct {
+	fakeGeofenceLister
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingGeofenceLister) FindAll() ([]*models.Geofence, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.fakeGeofenceLister.FindAll()
+}
+
+func TestZoneSpeedLimitResolver_ResolvesWithPreviousZonesWhileReloading(t *testing.T) {
+	lister := &blockingGeofenceLister{
+		fakeGeofenceLister: fakeGeofenceLister{geofences: newSpeedZones()[:1]},
+		started:            make(chan struct{}, 1),
+		release:            make(chan struct{}),
+	}
+	resolver := newZoneSpeedLimitResolver(lister)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+	vehicle := &models.Vehicle{}
+
+	close(lister.release)
+	assert.Equal(t, 60, resolver.ResolveSpeedLimit(vehicle, depotLocation).Kmh)
+	<-lister.started
+
+	// The next reload is stuck in the database
+	lister.release = make(chan struct{})
+	lister.geofences = newSpeedZones()
+	now = now.Add(speedZoneRefreshInterval)
+	reloaded := make(chan SpeedLimit)
+	go func() { reloaded <- resolver.ResolveSpeedLimit(vehicle, highwayLocation) }()
+	<-lister.started
+
+	// Other lookups don't wait for it
+	assert.Equal(t, 60, resolver.ResolveSpeedLimit(vehicle, depotLocation).Kmh)
+	assert.Equal(t, speedLimitKmh, resolver.ResolveSpeedLimit(vehicle, highwayLocation).Kmh)
+
+	close(lister.release)
+	assert.Equal(t, 110, (<-reloaded).Kmh)
+	assert.Equal(t, 110, resolver.ResolveSpeedLimit(vehicle, highwayLocation).Kmh)
+}
+
+func TestSpeedingAutoResolvesAgainstZoneLimit(t *testing.T) {
+	service := newSpeedZoneService()
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 90, Location: depotLocation}
+	service.checkSpeeding(context.Background(), vehicle)
+	require.Len(t, vehicle.Alerts, 1)
+
+	// 70 km/h is under the default limit but still over the depot's
+	vehicle.Speed = 70
+	assert.Empty(t, service.autoResolve.Apply(vehicle, time.Now()))
+
+	vehicle.Speed = 50
+	assert.Len(t, service.autoResolve.Apply(vehicle, time.Now()), 1)
+}
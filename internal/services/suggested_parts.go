@@ -0,0 +1,39 @@
+package services
+
+import "fleet-backend/internal/models"
+
+// SetSuggestedParts overrides the parts suggested for maintenance types.
+// Types without an override keep models.CommonPartsForService.
+func (s *MaintenanceService) SetSuggestedParts(overrides map[string][]string) {
+	s.suggestedParts = overrides
+}
+
+// partsForRecord returns the parts a new record lists. Parts the request names,
+// directly or through replacements, are kept as given; suggestions are only
+// filled in when the request asks for them and names none.
+func (s *MaintenanceService) partsForRecord(req *CreateMaintenanceRequest) []string {
+	if !req.SuggestParts || len(req.PartsReplaced) > 0 || len(req.Replacements) > 0 {
+		return req.PartsReplaced
+	}
+	return suggestedPartsFor(req.Types, s.suggestedParts)
+}
+
+// suggestedPartsFor lists the parts usually replaced for the maintenance
+// types, in type order without duplicates
+func suggestedPartsFor(types []string, overrides map[string][]string) []string {
+	var parts []string
+	seen := make(map[string]bool)
+	for _, maintenanceType := range types {
+		suggested, ok := overrides[maintenanceType]
+		if !ok {
+			suggested = models.CommonPartsForService[maintenanceType]
+		}
+		for _, part := range suggested {
+			if !seen[part] {
+				seen[part] = true
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts
+}
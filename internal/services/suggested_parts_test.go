@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartsForRecord_SuggestsPartsForKnownTypeWhenRequested(t *testing.T) {
+	service := &MaintenanceService{}
+	req := &CreateMaintenanceRequest{
+		Types:        []string{models.MaintenanceTypeOilChange, models.MaintenanceTypeEngineTuneUp, models.MaintenanceTypeAirFilter},
+		SuggestParts: true,
+	}
+
+	assert.Equal(t, []string{
+		models.PartEngineOil,
+		models.PartOilFilter,
+		models.PartSparkPlugs,
+		models.PartAirFilter,
+		models.PartFuelFilter,
+	}, service.partsForRecord(req))
+
+	// Without the flag nothing is suggested
+	req.SuggestParts = false
+	assert.Empty(t, service.partsForRecord(req))
+}
+
+func TestPartsForRecord_KeepsProvidedParts(t *testing.T) {
+	service := &MaintenanceService{}
+
+	req := &CreateMaintenanceRequest{
+		Types:         []string{models.MaintenanceTypeOilChange},
+		PartsReplaced: []string{models.PartOilFilter},
+		SuggestParts:  true,
+	}
+	assert.Equal(t, []string{models.PartOilFilter}, service.partsForRecord(req))
+
+	// Parts named through replacements count as provided too
+	req = &CreateMaintenanceRequest{
+		Types:        []string{models.MaintenanceTypeBrakeService},
+		Replacements: []models.PartReplacement{{Name: models.PartBrakePads}},
+		SuggestParts: true,
+	}
+	assert.Empty(t, service.partsForRecord(req))
+	assert.Equal(t, []string{models.PartBrakePads}, withReplacedPartNames(service.partsForRecord(req), req.Replacements))
+}
+
+func TestPartsForRecord_UsesConfiguredOverrides(t *testing.T) {
+	service := &MaintenanceService{}
+	service.SetSuggestedParts(map[string][]string{
+		models.MaintenanceTypeOilChange: {models.PartEngineOil},
+	})
+
+	req := &CreateMaintenanceRequest{
+		Types:        []string{models.MaintenanceTypeOilChange, models.MaintenanceTypeBatteryReplacement},
+		SuggestParts: true,
+	}
+	assert.Equal(t, []string{models.PartEngineOil, models.PartBattery}, service.partsForRecord(req))
+}
@@ -27,6 +27,7 @@ type CreateUserRequest struct {
 	LastName  string `json:"lastName" validate:"required,min=1,max=50"`
 	Password  string `json:"password" validate:"required,min=6"`
 	Role      string `json:"role" validate:"required,oneof=admin manager operator viewer"`
+	TenantID  string `json:"tenantId,omitempty" validate:"omitempty,max=100"`
 }
 
 type UpdateUserRequest struct {
@@ -35,6 +36,7 @@ type UpdateUserRequest struct {
 	LastName  string `json:"lastName,omitempty" validate:"omitempty,min=1,max=50"`
 	Role      string `json:"role,omitempty" validate:"omitempty,oneof=admin manager operator viewer"`
 	Status    string `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
+	TenantID  string `json:"tenantId,omitempty" validate:"omitempty,max=100"`
 }
 
 func (s *UserService) GetAllUsers() ([]*models.User, error) {
@@ -75,6 +77,7 @@ func (s *UserService) CreateUser(req *CreateUserRequest) (*models.User, error) {
 		Role:        req.Role,
 		Status:      "active",
 		Permissions: s.getRolePermissions(req.Role),
+		TenantID:    req.TenantID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -116,6 +119,10 @@ func (s *UserService) UpdateUser(id string, req *UpdateUserRequest) (*models.Use
 		user.Status = req.Status
 	}
 
+	if req.TenantID != "" {
+		user.TenantID = req.TenantID
+	}
+
 	user.UpdatedAt = time.Now()
 
 	return s.userRepo.Update(id, user)
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/internal/status"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUtilizationTrackingDisabled is returned when a utilization report is requested without a tracker
+	ErrUtilizationTrackingDisabled = errors.New("utilization tracking is not enabled")
+	// ErrInvalidUtilizationRange is returned when a report range doesn't end after it starts
+	ErrInvalidUtilizationRange = newDomainError(ErrValidation, "utilization range must end after it starts")
+	// ErrUtilizationRangeTooLong is returned when a report range spans more than MaxUtilizationReportRange
+	ErrUtilizationRangeTooLong = newDomainError(ErrValidation, "utilization range must not exceed 31 days")
+)
+
+// MaxUtilizationReportRange is the longest range a utilization report covers,
+// as every segment overlapping the range is loaded to build it
+const MaxUtilizationReportRange = 31 * 24 * time.Hour
+
+// DefaultUtilizationReportGap is how long a report of an active or idle
+// vehicle keeps vouching for its status when none is configured
+const DefaultUtilizationReportGap = 15 * time.Minute
+
+// utilizationStates are the states report time is split into
+var utilizationStates = []string{status.Active, status.Idle, status.Maintenance, status.Offline, models.UtilizationUnknown}
+
+// StatusRecorder receives vehicle statuses as they are reported
+type StatusRecorder interface {
+	RecordStatus(vehicleID, vehicleStatus string, at time.Time)
+}
+
+// statusSegmentStore is the subset of the status segment repository used by the tracker
+type statusSegmentStore interface {
+	Create(segment *models.StatusSegment) error
+	FindOverlapping(from, to time.Time) ([]*models.StatusSegment, error)
+}
+
+// openStatus is the status a vehicle is in now, as far as reports tell
+type openStatus struct {
+	status   string
+	since    time.Time
+	lastSeen time.Time
+}
+
+// knownUntil returns when the status stops being known, at the latest at
+// now. Active and idle vehicles report continuously, so each report only
+// vouches for maxGap; maintenance and offline hold until the status changes.
+func (o *openStatus) knownUntil(maxGap time.Duration, now time.Time) time.Time {
+	if o.status == status.Active || o.status == status.Idle {
+		if until := o.lastSeen.Add(maxGap); until.Before(now) {
+			return until
+		}
+	}
+	return now
+}
+
+// UtilizationTracker records the periods vehicles spend in each status, so
+// the share of time each vehicle was active, idle, in maintenance or offline
+// can be reported over any range. Each period is persisted as a segment when
+// the status changes; the period in progress is kept in memory. Time no
+// report covers, such as a gap in an active vehicle's telemetry or the time
+// before its first report, is reported as unknown rather than as a status.
+type UtilizationTracker struct {
+	store  statusSegmentStore
+	maxGap time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	open map[string]*openStatus // vehicle ID -> status in progress
+}
+
+// NewUtilizationTracker creates a tracker. Reports of active and idle
+// vehicles vouch for their status for maxGap, or DefaultUtilizationReportGap
+// if it isn't positive.
+func NewUtilizationTracker(repo *repository.StatusSegmentRepository, maxGap time.Duration) *UtilizationTracker {
+	return newUtilizationTracker(repo, maxGap)
+}
+
+func newUtilizationTracker(store statusSegmentStore, maxGap time.Duration) *UtilizationTracker {
+	if maxGap <= 0 {
+		maxGap = DefaultUtilizationReportGap
+	}
+	return &UtilizationTracker{
+		store:  store,
+		maxGap: maxGap,
+		now:    time.Now,
+		open:   make(map[string]*openStatus),
+	}
+}
+
+// RecordStatus notes a vehicle's status as of at. A change of status closes
+// and persists the segment in progress, as does a report arriving after the
+// previous one stopped vouching for its status. Unknown statuses and reports
+// older than the last one are ignored.
+func (t *UtilizationTracker) RecordStatus(vehicleID, vehicleStatus string, at time.Time) {
+	if !isUtilizationStatus(vehicleStatus) {
+		return
+	}
+
+	t.mu.Lock()
+	var closed *models.StatusSegment
+	current, tracked := t.open[vehicleID]
+	switch {
+	case !tracked:
+		t.open[vehicleID] = &openStatus{status: vehicleStatus, since: at, lastSeen: at}
+	case at.Before(current.lastSeen):
+		// A late report; newer ones already described the vehicle
+	case current.knownUntil(t.maxGap, at).Before(at):
+		closed = current.segment(vehicleID, current.knownUntil(t.maxGap, at))
+		t.open[vehicleID] = &openStatus{status: vehicleStatus, since: at, lastSeen: at}
+	case vehicleStatus == current.status:
+		current.lastSeen = at
+	default:
+		closed = current.segment(vehicleID, at)
+		t.open[vehicleID] = &openStatus{status: vehicleStatus, since: at, lastSeen: at}
+	}
+	t.mu.Unlock()
+
+	if closed != nil {
+		t.persist(closed)
+	}
+}
+
+// Flush closes every segment in progress at at, e.g. before shutdown. Until
+// vehicles report again, their status is unknown.
+func (t *UtilizationTracker) Flush(at time.Time) {
+	t.mu.Lock()
+	open := t.open
+	t.open = make(map[string]*openStatus)
+	t.mu.Unlock()
+
+	for vehicleID, current := range open {
+		t.persist(current.segment(vehicleID, current.knownUntil(t.maxGap, at)))
+	}
+}
+
+func (o *openStatus) segment(vehicleID string, end time.Time) *models.StatusSegment {
+	return &models.StatusSegment{
+		VehicleID: vehicleID,
+		Status:    o.status,
+		StartedAt: o.since,
+		EndedAt:   end,
+	}
+}
+
+func (t *UtilizationTracker) persist(segment *models.StatusSegment) {
+	if !segment.EndedAt.After(segment.StartedAt) {
+		return
+	}
+	if err := t.store.Create(segment); err != nil {
+		fmt.Printf("Failed to persist status segment for vehicle %s: %v\n", segment.VehicleID, err)
+	}
+}
+
+// Report returns the utilization of vehicles within [from, to), including
+// the segments in progress
+func (t *UtilizationTracker) Report(vehicles []*models.Vehicle, from, to time.Time) (*models.UtilizationReport, error) {
+	if err := validateUtilizationRange(from, to); err != nil {
+		return nil, err
+	}
+
+	segments, err := t.store.FindOverlapping(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	for vehicleID, current := range t.open {
+		segments = append(segments, current.segment(vehicleID, current.knownUntil(t.maxGap, now)))
+	}
+	t.mu.Unlock()
+
+	return buildUtilizationReport(vehicles, segments, from, to), nil
+}
+
+// validateUtilizationRange checks a report range is neither empty nor longer
+// than MaxUtilizationReportRange
+func validateUtilizationRange(from, to time.Time) error {
+	if !to.After(from) {
+		return ErrInvalidUtilizationRange
+	}
+	if to.Sub(from) > MaxUtilizationReportRange {
+		return ErrUtilizationRangeTooLong
+	}
+	return nil
+}
+
+// buildUtilizationReport splits each vehicle's time within [from, to) by the
+// status segments covering it. Where segments overlap, the earlier one wins;
+// time no segment covers is unknown.
+func buildUtilizationReport(vehicles []*models.Vehicle, segments []*models.StatusSegment, from, to time.Time) *models.UtilizationReport {
+	byVehicle := make(map[string][]*models.StatusSegment)
+	for _, segment := range segments {
+		byVehicle[segment.VehicleID] = append(byVehicle[segment.VehicleID], segment)
+	}
+
+	rangeSeconds := to.Sub(from).Seconds()
+	fleetSeconds := make(map[string]float64)
+	report := &models.UtilizationReport{
+		From:     from,
+		To:       to,
+		Vehicles: make([]*models.VehicleUtilization, 0, len(vehicles)),
+	}
+
+	for _, vehicle := range vehicles {
+		vehicleID := vehicle.ID.Hex()
+		vehicleSegments := byVehicle[vehicleID]
+		sort.Slice(vehicleSegments, func(i, j int) bool {
+			return vehicleSegments[i].StartedAt.Before(vehicleSegments[j].StartedAt)
+		})
+
+		seconds := make(map[string]float64)
+		known := 0.0
+		covered := from
+		for _, segment := range vehicleSegments {
+			start := segment.StartedAt
+			if start.Before(covered) {
+				start = covered
+			}
+			end := segment.EndedAt
+			if end.After(to) {
+				end = to
+			}
+			if !end.After(start) {
+				continue
+			}
+			seconds[segment.Status] += end.Sub(start).Seconds()
+			known += end.Sub(start).Seconds()
+			covered = end
+		}
+		seconds[models.UtilizationUnknown] = rangeSeconds - known
+
+		for state, value := range seconds {
+			fleetSeconds[state] += value
+		}
+		report.Vehicles = append(report.Vehicles, &models.VehicleUtilization{
+			VehicleID:            vehicleID,
+			VehicleName:          vehicle.Name,
+			UtilizationBreakdown: newUtilizationBreakdown(seconds, rangeSeconds),
+		})
+	}
+
+	report.Fleet = newUtilizationBreakdown(fleetSeconds, rangeSeconds*float64(len(vehicles)))
+	return report
+}
+
+// newUtilizationBreakdown lists the seconds in every state with their share
+// of total, rounded to two decimals
+func newUtilizationBreakdown(seconds map[string]float64, total float64) models.UtilizationBreakdown {
+	breakdown := models.UtilizationBreakdown{
+		Seconds: make(map[string]float64, len(utilizationStates)),
+		Percent: make(map[string]float64, len(utilizationStates)),
+	}
+	for _, state := range utilizationStates {
+		breakdown.Seconds[state] = seconds[state]
+		breakdown.Percent[state] = 0
+		if total > 0 {
+			breakdown.Percent[state] = math.Round(seconds[state]/total*10000) / 100
+		}
+	}
+	return breakdown
+}
+
+func isUtilizationStatus(vehicleStatus string) bool {
+	switch vehicleStatus {
+	case status.Active, status.Idle, status.Maintenance, status.Offline:
+		return true
+	}
+	return false
+}
+
+// GetUtilizationReport returns the utilization of every vehicle within [from, to)
+func (s *VehicleService) GetUtilizationReport(ctx context.Context, from, to time.Time) (*models.UtilizationReport, error) {
+	if s.utilizationTracker == nil {
+		return nil, ErrUtilizationTrackingDisabled
+	}
+	if err := validateUtilizationRange(from, to); err != nil {
+		return nil, err
+	}
+
+	vehicles, err := s.vehicleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.utilizationTracker.Report(vehicles, from, to)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStatusStore keeps status segments in memory
+type memoryStatusStore struct {
+	segments []*models.StatusSegment
+}
+
+func (m *memoryStatusStore) Create(segment *models.StatusSegment) error {
+	m.segments = append(m.segments, segment)
+	return nil
+}
+
+func (m *memoryStatusStore) FindOverlapping(from, to time.Time) ([]*models.StatusSegment, error) {
+	var found []*models.StatusSegment
+	for _, segment := range m.segments {
+		if segment.StartedAt.Before(to) && segment.EndedAt.After(from) {
+			found = append(found, segment)
+		}
+	}
+	return found, nil
+}
+
+// reportEvery records a status every interval within [from, to)
+func reportEvery(tracker *UtilizationTracker, vehicleID, vehicleStatus string, from, to time.Time, interval time.Duration) {
+	for at := from; at.Before(to); at = at.Add(interval) {
+		tracker.RecordStatus(vehicleID, vehicleStatus, at)
+	}
+}
+
+func TestUtilizationReport_KnownTimeline(t *testing.T) {
+	store := &memoryStatusStore{}
+	tracker := newUtilizationTracker(store, 15*time.Minute)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start.Add(4 * time.Hour) }
+
+	truck := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck"}
+	silent := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Silent"}
+	van := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Van"}
+
+	// Truck: active 08:00-10:00, idle from 10:00 with its last report at
+	// 10:55, which vouches until 11:10, then in maintenance from 11:30
+	reportEvery(tracker, truck.ID.Hex(), "active", start, start.Add(2*time.Hour), 5*time.Minute)
+	reportEvery(tracker, truck.ID.Hex(), "idle", start.Add(2*time.Hour), start.Add(3*time.Hour), 5*time.Minute)
+	tracker.RecordStatus(truck.ID.Hex(), "maintenance", start.Add(3*time.Hour+30*time.Minute))
+	// Van: nothing known before it was taken offline at 09:00
+	tracker.RecordStatus(van.ID.Hex(), "offline", start.Add(time.Hour))
+
+	report, err := tracker.Report([]*models.Vehicle{truck, silent, van}, start, start.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, report.Vehicles, 3)
+
+	assert.Equal(t, truck.ID.Hex(), report.Vehicles[0].VehicleID)
+	assert.Equal(t, "Truck", report.Vehicles[0].VehicleName)
+	assert.Equal(t, map[string]float64{
+		"active": 7200, "idle": 4200, "maintenance": 1800, "offline": 0, "unknown": 1200,
+	}, report.Vehicles[0].Seconds)
+	assert.Equal(t, map[string]float64{
+		"active": 50, "idle": 29.17, "maintenance": 12.5, "offline": 0, "unknown": 8.33,
+	}, report.Vehicles[0].Percent)
+
+	assert.Equal(t, map[string]float64{
+		"active": 0, "idle": 0, "maintenance": 0, "offline": 0, "unknown": 100,
+	}, report.Vehicles[1].Percent)
+
+	assert.Equal(t, map[string]float64{
+		"active": 0, "idle": 0, "maintenance": 0, "offline": 75, "unknown": 25,
+	}, report.Vehicles[2].Percent)
+
+	assert.Equal(t, map[string]float64{
+		"active": 16.67, "idle": 9.72, "maintenance": 4.17, "offline": 25, "unknown": 44.44,
+	}, report.Fleet.Percent)
+}
+
+func TestUtilizationReport_ClipsToRangeAndIgnoresLateReports(t *testing.T) {
+	store := &memoryStatusStore{}
+	tracker := newUtilizationTracker(store, 15*time.Minute)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start.Add(2 * time.Hour) }
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+	id := vehicle.ID.Hex()
+
+	tracker.RecordStatus(id, "maintenance", start)
+	tracker.RecordStatus(id, "idle", start.Add(time.Hour))
+	// A late report and an unknown status change nothing
+	tracker.RecordStatus(id, "active", start.Add(30*time.Minute))
+	tracker.RecordStatus(id, "parked", start.Add(time.Hour+5*time.Minute))
+	tracker.RecordStatus(id, "active", start.Add(time.Hour+10*time.Minute))
+
+	require.Len(t, store.segments, 2)
+	assert.Equal(t, "maintenance", store.segments[0].Status)
+	assert.Equal(t, start.Add(time.Hour+10*time.Minute), store.segments[1].EndedAt)
+
+	// The single active report at 09:10 vouches until 09:25
+	report, err := tracker.Report([]*models.Vehicle{vehicle}, start.Add(30*time.Minute), start.Add(90*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"active": 900, "idle": 600, "maintenance": 1800, "offline": 0, "unknown": 300,
+	}, report.Vehicles[0].Seconds)
+}
+
+func TestUtilizationTracker_FlushEndsOpenStatusesWhereTheyStopBeingKnown(t *testing.T) {
+	store := &memoryStatusStore{}
+	tracker := newUtilizationTracker(store, 15*time.Minute)
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	moving := &models.Vehicle{ID: primitive.NewObjectID()}
+	parked := &models.Vehicle{ID: primitive.NewObjectID()}
+
+	tracker.RecordStatus(moving.ID.Hex(), "active", start)
+	tracker.RecordStatus(moving.ID.Hex(), "active", start.Add(10*time.Minute))
+	tracker.RecordStatus(parked.ID.Hex(), "offline", start)
+
+	tracker.Flush(start.Add(time.Hour))
+	require.Len(t, store.segments, 2)
+
+	// Nothing is known after the flush until the vehicles report again
+	tracker.now = func() time.Time { return start.Add(2 * time.Hour) }
+	report, err := tracker.Report([]*models.Vehicle{moving, parked}, start, start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1500.0, report.Vehicles[0].Seconds["active"])
+	assert.Equal(t, 5700.0, report.Vehicles[0].Seconds["unknown"])
+	assert.Equal(t, 3600.0, report.Vehicles[1].Seconds["offline"])
+	assert.Equal(t, 3600.0, report.Vehicles[1].Seconds["unknown"])
+}
+
+func TestUtilizationReport_RejectsEmptyRange(t *testing.T) {
+	tracker := newUtilizationTracker(&memoryStatusStore{}, 0)
+	start := time.Now()
+
+	_, err := tracker.Report(nil, start, start)
+	assert.ErrorIs(t, err, ErrInvalidUtilizationRange)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestUtilizationReport_RejectsRangeOverMaximum(t *testing.T) {
+	store := &memoryStatusStore{}
+	tracker := newUtilizationTracker(store, 0)
+	to := time.Now()
+
+	_, err := tracker.Report(nil, to.Add(-MaxUtilizationReportRange-time.Second), to)
+	assert.ErrorIs(t, err, ErrUtilizationRangeTooLong)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = tracker.Report(nil, to.Add(-MaxUtilizationReportRange), to)
+	assert.NoError(t, err)
+}
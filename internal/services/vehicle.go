@@ -1,50 +1,109 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fleet-backend/internal/models"
 	"fleet-backend/internal/repository"
+	"fleet-backend/internal/status"
 	"fleet-backend/internal/websocket"
 	"fleet-backend/pkg/batch"
 	"fleet-backend/pkg/cache"
+	"fleet-backend/pkg/logger"
+	"fleet-backend/pkg/routing"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 type VehicleService struct {
-	vehicleRepo     *repository.VehicleRepository
-	alertRepo       *repository.AlertRepository
-	cacheManager    cache.CacheManager
-	cacheConfig     cache.CacheConfig
-	batchProcessor  batch.BatchProcessor
-	wsManager       websocket.WebSocketManager
+	vehicleRepo        *repository.VehicleRepository
+	alertRepo          vehicleAlertStore
+	alertNotifier      AlertNotifier
+	events             EventPublisher
+	cacheManager       cache.CacheManager
+	cacheConfig        cache.CacheConfig
+	listRevalidator    *cache.StaleWhileRevalidate
+	batchProcessor     batch.BatchProcessor
+	wsManager          websocket.WebSocketManager
+	autoResolve        *AutoResolveRegistry
+	speedBounds        SpeedPlausibility
+	severityPolicy     *AlertSeverityPolicy
+	forecaster         *MileageForecaster
+	idleTracker        *IdleTracker
+	utilizationTracker *UtilizationTracker
+	speedLimits        SpeedLimitResolver
+	alertTargets       AlertTargetValidator
+	speedHistory       *SpeedHistory
+	routes             routing.Provider
+	fuelAnomalies      *FuelAnomalyDetector
+	tagIndex           VehicleTagIndex
+	fuelUnit           string // fleet-wide fuel unit; vehicles may override it
+
+	// settingsMu guards the settings that can be changed at runtime
+	settingsMu sync.RWMutex
+}
+
+// vehicleAlertStore is the subset of the alert repository used to persist and
+// resolve alerts raised from vehicle updates
+type vehicleAlertStore interface {
+	Create(ctx context.Context, alert *models.Alert) (*models.Alert, error)
+	MarkAsResolved(ctx context.Context, id string) error
 }
 
 func NewVehicleService(vehicleRepo *repository.VehicleRepository) *VehicleService {
 	return &VehicleService{
-		vehicleRepo: vehicleRepo,
-		cacheConfig: cache.DefaultCacheConfig(),
+		vehicleRepo:    vehicleRepo,
+		cacheConfig:    cache.DefaultCacheConfig(),
+		autoResolve:    DefaultAutoResolveRegistry(),
+		speedBounds:    DefaultSpeedPlausibility(),
+		severityPolicy: DefaultAlertSeverityPolicy(),
+		fuelAnomalies:  NewFuelAnomalyDetector(DefaultFuelAnomalyPolicy()),
 	}
 }
 
 // SetAlertRepository allows setting the alert repository for alert generation
 func (s *VehicleService) SetAlertRepository(alertRepo *repository.AlertRepository) {
+	if alertRepo == nil {
+		s.alertRepo = nil // keep the nil checks working for a typed nil
+		return
+	}
 	s.alertRepo = alertRepo
 }
 
 // SetCacheManager allows setting the cache manager for caching operations
 func (s *VehicleService) SetCacheManager(cacheManager cache.CacheManager) {
 	s.cacheManager = cacheManager
+	s.listRevalidator = nil
+	if cacheManager != nil {
+		s.listRevalidator = cache.NewStaleWhileRevalidate(cacheManager)
+	}
 }
 
 // SetCacheConfig allows setting custom cache configuration
 func (s *VehicleService) SetCacheConfig(config cache.CacheConfig) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
 	s.cacheConfig = config
 }
 
+// GetCacheConfig returns the cache configuration currently in use
+func (s *VehicleService) GetCacheConfig() cache.CacheConfig {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.cacheConfig
+}
+
+// cacheTTL returns the TTL for a cached data type
+func (s *VehicleService) cacheTTL(dataType string) time.Duration {
+	return s.GetCacheConfig().GetTTLForDataType(dataType)
+}
+
 // SetBatchProcessor allows setting the batch processor for optimized updates
 func (s *VehicleService) SetBatchProcessor(batchProcessor batch.BatchProcessor) {
 	s.batchProcessor = batchProcessor
@@ -55,16 +114,64 @@ func (s *VehicleService) SetWebSocketManager(wsManager websocket.WebSocketManage
 	s.wsManager = wsManager
 }
 
+// SetSpeedPlausibility sets the range of speeds accepted from updates
+func (s *VehicleService) SetSpeedPlausibility(bounds SpeedPlausibility) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+	s.speedBounds = bounds
+}
+
+// SetAlertSeverityPolicy sets the severity assigned to each generated alert type
+func (s *VehicleService) SetAlertSeverityPolicy(policy *AlertSeverityPolicy) {
+	s.severityPolicy = policy
+}
+
+// SetFuelAnomalyPolicy replaces the fuel theft detector, discarding the
+// consumption history it has learned
+func (s *VehicleService) SetFuelAnomalyPolicy(policy FuelAnomalyPolicy) {
+	s.fuelAnomalies = NewFuelAnomalyDetector(policy)
+}
+
+// SetMileageForecaster records accepted odometer readings for mileage forecasting
+func (s *VehicleService) SetMileageForecaster(forecaster *MileageForecaster) {
+	s.forecaster = forecaster
+}
+
+// SetIdleTracker enables idle time stats for vehicles
+func (s *VehicleService) SetIdleTracker(tracker *IdleTracker) {
+	s.idleTracker = tracker
+}
+
+// SetUtilizationTracker enables utilization reports and records the status
+// of created and updated vehicles on tracker
+func (s *VehicleService) SetUtilizationTracker(tracker *UtilizationTracker) {
+	s.utilizationTracker = tracker
+}
+
+// SetEventPublisher sets where vehicle events such as status changes are published
+func (s *VehicleService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetAutoResolveRegistry allows replacing the rules used to auto-resolve alerts
+func (s *VehicleService) SetAutoResolveRegistry(registry *AutoResolveRegistry) {
+	s.autoResolve = registry
+}
+
 type CreateVehicleRequest struct {
-	Name             string  `json:"name" validate:"required,min=1,max=100"`
-	PlateNumber      string  `json:"plateNumber" validate:"required,min=1,max=20"`
-	Driver           string  `json:"driver" validate:"required,min=1,max=100"`
-	Make             string  `json:"make,omitempty"`
-	Model            string  `json:"model,omitempty"`
-	Year             int     `json:"year,omitempty" validate:"omitempty,min=1900,max=2030"`
-	VIN              string  `json:"vin,omitempty"`
-	MaxFuelCapacity  float64 `json:"maxFuelCapacity" validate:"required,min=1"`
-	FuelConsumption  float64 `json:"fuelConsumption" validate:"required,min=0.1"`
+	Name             string            `json:"name" validate:"required,min=1,max=100"`
+	PlateNumber      string            `json:"plateNumber" validate:"required,min=1,max=20"`
+	Driver           string            `json:"driver" validate:"required,min=1,max=100"`
+	Make             string            `json:"make,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	Year             int               `json:"year,omitempty" validate:"omitempty,min=1900,max=2030"`
+	VIN              string            `json:"vin,omitempty"`
+	MaxFuelCapacity  float64           `json:"maxFuelCapacity" validate:"required,min=1"` // in FuelUnit, or the fleet's unit
+	FuelConsumption  float64           `json:"fuelConsumption" validate:"required,min=0.1"`
+	SpeedLimitKmh    int               `json:"speedLimitKmh,omitempty" validate:"omitempty,min=1,max=250"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	FuelUnit         string            `json:"fuelUnit,omitempty" validate:"omitempty,oneof=liters gallons"`
 }
 
 type UpdateVehicleRequest struct {
@@ -73,7 +180,8 @@ type UpdateVehicleRequest struct {
 	Driver           string             `json:"driver,omitempty"`
 	FuelLevel        float64            `json:"fuelLevel,omitempty"`
 	Location         *models.Location   `json:"location,omitempty"`
-	Speed            int                `json:"speed,omitempty"`
+	// Speed is nil when the update doesn't report one; zero is a stopped vehicle
+	Speed            *int               `json:"speed,omitempty"`
 	Status           string             `json:"status,omitempty" validate:"omitempty,oneof=active idle maintenance offline"`
 	Odometer         int                `json:"odometer,omitempty"`
 	Make             string             `json:"make,omitempty"`
@@ -82,9 +190,37 @@ type UpdateVehicleRequest struct {
 	VIN              string             `json:"vin,omitempty"`
 	MaxFuelCapacity  float64            `json:"maxFuelCapacity,omitempty"`
 	FuelConsumption  float64            `json:"fuelConsumption,omitempty"`
+	SpeedLimitKmh    int                `json:"speedLimitKmh,omitempty" validate:"omitempty,min=1,max=250"`
+	// FuelUnit changes the unit the vehicle's fuel is presented in
+	FuelUnit         string             `json:"fuelUnit,omitempty" validate:"omitempty,oneof=liters gallons"`
+	// FuelInLiters marks FuelLevel and MaxFuelCapacity as already in liters,
+	// as telemetry reports them. API requests give them in the vehicle's unit.
+	FuelInLiters     bool               `json:"-"`
+	// ForceStatus applies Status even where the status state machine doesn't
+	// allow the change. Only admins may set it.
+	ForceStatus      bool               `json:"forceStatus,omitempty"`
+}
+
+func (s *VehicleService) GetAllVehicles(ctx context.Context) ([]*models.Vehicle, error) {
+	return s.getAllVehicles(ctx, s.vehicleRepo)
+}
+
+// allVehiclesSource is the subset of the vehicle repository listing the fleet
+type allVehiclesSource interface {
+	FindAll(ctx context.Context) ([]*models.Vehicle, error)
 }
 
-func (s *VehicleService) GetAllVehicles() ([]*models.Vehicle, error) {
+func (s *VehicleService) getAllVehicles(ctx context.Context, source allVehiclesSource) ([]*models.Vehicle, error) {
+	// Serve the cached list past its soft TTL while it is refreshed in the background
+	config := s.GetCacheConfig()
+	if s.listRevalidator != nil && config.VehicleListSoftTTL > 0 && config.VehicleListSoftTTL < config.VehicleListTTL {
+		// The loader may refresh the list after the request has finished, so it
+		// must not be cancelled along with the request
+		loadCtx := context.WithoutCancel(ctx)
+		loader := func() ([]*models.Vehicle, error) { return source.FindAll(loadCtx) }
+		return s.listRevalidator.GetVehicleList("all_vehicles", config.VehicleListSoftTTL, config.VehicleListTTL, loader)
+	}
+
 	// Try cache first if cache manager is available
 	if s.cacheManager != nil {
 		cacheKey := "all_vehicles"
@@ -94,28 +230,28 @@ func (s *VehicleService) GetAllVehicles() ([]*models.Vehicle, error) {
 		}
 		// Log cache miss but continue to database
 		if err != nil {
-			fmt.Printf("Cache error for GetAllVehicles: %v\n", err)
+			logger.FromContext(ctx).Warn("Cache error for GetAllVehicles", "error", err)
 		}
 	}
 
 	// Fallback to database
-	vehicles, err := s.vehicleRepo.FindAll()
+	vehicles, err := source.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Cache the result if cache manager is available
 	if s.cacheManager != nil {
-		ttl := s.cacheConfig.GetTTLForDataType("vehicle_list")
+		ttl := s.cacheTTL("vehicle_list")
 		if cacheErr := s.cacheManager.SetVehicleList("all_vehicles", vehicles, ttl); cacheErr != nil {
-			fmt.Printf("Failed to cache all vehicles: %v\n", cacheErr)
+			logger.FromContext(ctx).Warn("Failed to cache all vehicles", "error", cacheErr)
 		}
 	}
 
 	return vehicles, nil
 }
 
-func (s *VehicleService) GetVehicleByID(id string) (*models.Vehicle, error) {
+func (s *VehicleService) GetVehicleByID(ctx context.Context, id string) (*models.Vehicle, error) {
 	// Try cache first if cache manager is available
 	if s.cacheManager != nil {
 		cachedVehicle, err := s.cacheManager.GetVehicle(id)
@@ -124,36 +260,246 @@ func (s *VehicleService) GetVehicleByID(id string) (*models.Vehicle, error) {
 		}
 		// Log cache miss but continue to database
 		if err != nil {
-			fmt.Printf("Cache error for GetVehicleByID(%s): %v\n", id, err)
+			logger.FromContext(ctx).Warn("Cache error for GetVehicleByID", "vehicle_id", id, "error", err)
 		}
 	}
 
 	// Fallback to database
-	vehicle, err := s.vehicleRepo.FindByID(id)
+	vehicle, err := s.vehicleRepo.FindByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, vehicleLookupError(err)
 	}
 
 	// Cache the result if cache manager is available
 	if s.cacheManager != nil {
-		ttl := s.cacheConfig.GetTTLForDataType("vehicle")
+		ttl := s.cacheTTL("vehicle")
 		if cacheErr := s.cacheManager.SetVehicle(id, vehicle, ttl); cacheErr != nil {
-			fmt.Printf("Failed to cache vehicle %s: %v\n", id, cacheErr)
+			logger.FromContext(ctx).Warn("Failed to cache vehicle", "vehicle_id", id, "error", cacheErr)
 		}
 	}
 
 	return vehicle, nil
 }
 
-func (s *VehicleService) CreateVehicle(req *CreateVehicleRequest) (*models.Vehicle, error) {
+// MaxBatchGetVehicles caps the number of IDs accepted by GetVehiclesByIDs
+const MaxBatchGetVehicles = 500
+
+// BatchGetVehiclesRequest lists the vehicles whose current state is wanted
+type BatchGetVehiclesRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=500,dive,required"`
+}
+
+// BatchGetVehiclesResult holds the vehicles found, in request order, and the IDs
+// that did not match any vehicle
+type BatchGetVehiclesResult struct {
+	Vehicles []*models.Vehicle `json:"vehicles"`
+	Missing  []string          `json:"missing"`
+}
+
+// vehicleBatchFinder is the subset of the vehicle repository used by batch lookups
+type vehicleBatchFinder interface {
+	FindByIDs(ctx context.Context, ids []string) ([]*models.Vehicle, error)
+}
+
+// GetVehiclesByIDs returns the current state of several vehicles. Cached vehicles
+// are fetched from cache in one call and the rest in one query.
+func (s *VehicleService) GetVehiclesByIDs(ctx context.Context, ids []string) (*BatchGetVehiclesResult, error) {
+	return s.getVehiclesByIDs(ctx, s.vehicleRepo, ids)
+}
+
+func (s *VehicleService) getVehiclesByIDs(ctx context.Context, finder vehicleBatchFinder, ids []string) (*BatchGetVehiclesResult, error) {
+	// Drop duplicates but keep the caller's order
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchGetVehicles {
+		return nil, fmt.Errorf("at most %d vehicle IDs can be requested at once", MaxBatchGetVehicles)
+	}
+
+	found := make(map[string]*models.Vehicle, len(unique))
+	var misses []string
+
+	// One round trip for every cached vehicle; a cache error only means misses
+	if s.cacheManager != nil {
+		if cached, err := s.cacheManager.GetVehicles(unique); err == nil {
+			for id, cachedVehicle := range cached {
+				found[id] = cachedVehicle
+			}
+		}
+	}
+	for _, id := range unique {
+		if _, ok := found[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+
+	if len(misses) > 0 {
+		vehicles, err := finder.FindByIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl := s.cacheTTL("vehicle")
+		for _, vehicle := range vehicles {
+			id := vehicle.ID.Hex()
+			found[id] = vehicle
+
+			// Cache the result if cache manager is available
+			if s.cacheManager != nil {
+				if cacheErr := s.cacheManager.SetVehicle(id, vehicle, ttl); cacheErr != nil {
+					logger.FromContext(ctx).Warn("Failed to cache vehicle", "vehicle_id", id, "error", cacheErr)
+				}
+			}
+		}
+	}
+
+	result := &BatchGetVehiclesResult{
+		Vehicles: make([]*models.Vehicle, 0, len(found)),
+		Missing:  []string{},
+	}
+	for _, id := range unique {
+		if vehicle, ok := found[id]; ok {
+			result.Vehicles = append(result.Vehicles, vehicle)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return result, nil
+}
+
+// Vehicle search limits
+const (
+	DefaultVehicleSearchLimit = 20
+	MaxVehicleSearchLimit     = 100
+)
+
+// vehicleSearcher is the subset of the vehicle repository used by search
+type vehicleSearcher interface {
+	Search(ctx context.Context, query string, limit int) ([]*models.Vehicle, error)
+}
+
+// SearchVehicles finds vehicles whose name, plate, VIN or driver contains the query.
+// Results are cached briefly per query since search is typically typed incrementally.
+func (s *VehicleService) SearchVehicles(ctx context.Context, query string, limit int) ([]*models.Vehicle, error) {
+	return s.searchVehicles(ctx, s.vehicleRepo, query, limit)
+}
+
+func (s *VehicleService) searchVehicles(ctx context.Context, searcher vehicleSearcher, query string, limit int) ([]*models.Vehicle, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if limit <= 0 {
+		limit = DefaultVehicleSearchLimit
+	}
+	if limit > MaxVehicleSearchLimit {
+		limit = MaxVehicleSearchLimit
+	}
+
+	cacheKey := fmt.Sprintf("vehicle_search_%d_%s", limit, strings.ToLower(query))
+
+	// Try cache first if cache manager is available
+	if s.cacheManager != nil {
+		cachedVehicles, err := s.cacheManager.GetVehicleList(cacheKey)
+		if err == nil && cachedVehicles != nil {
+			return cachedVehicles, nil
+		}
+	}
+
+	vehicles, err := searcher.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if vehicles == nil {
+		vehicles = []*models.Vehicle{}
+	}
+
+	// Cache the result if cache manager is available
+	if s.cacheManager != nil {
+		ttl := s.cacheTTL("search")
+		if cacheErr := s.cacheManager.SetVehicleList(cacheKey, vehicles, ttl); cacheErr != nil {
+			logger.FromContext(ctx).Warn("Failed to cache vehicle search results", "error", cacheErr)
+		}
+	}
+
+	return vehicles, nil
+}
+
+func (s *VehicleService) CreateVehicle(ctx context.Context, req *CreateVehicleRequest) (*models.Vehicle, error) {
+	return s.createVehicle(ctx, s.vehicleRepo, req)
+}
+
+func (s *VehicleService) createVehicle(ctx context.Context, store vehicleCreateStore, req *CreateVehicleRequest) (*models.Vehicle, error) {
+	if err := validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	req.Tags = tags
+	if req.FuelUnit != "" && !models.IsValidFuelUnit(req.FuelUnit) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFuelUnit, req.FuelUnit)
+	}
+	if req.VIN != "" {
+		normalized, err := normalizeVIN(req.VIN)
+		if err != nil {
+			return nil, err
+		}
+		req.VIN = normalized
+	}
+
 	// Check if plate number already exists
-	existingVehicle, _ := s.vehicleRepo.FindByPlateNumber(req.PlateNumber)
+	existingVehicle, _ := store.FindByPlateNumber(ctx, req.PlateNumber)
 	if existingVehicle != nil {
-		return nil, errors.New("plate number already exists")
+		return nil, ErrDuplicatePlate
 	}
+	if req.VIN != "" {
+		if existingVehicle, _ := store.FindByVIN(ctx, req.VIN); existingVehicle != nil {
+			return nil, ErrDuplicateVIN
+		}
+	}
+
+	vehicle := s.newVehicleInFuelUnit(req)
+
+	createdVehicle, err := store.Create(ctx, vehicle)
+	if err != nil {
+		return nil, duplicateVehicleError(err)
+	}
+
+	// Invalidate relevant cache entries after successful creation
+	if s.cacheManager != nil {
+		s.invalidateCacheOnCreate(ctx, createdVehicle)
+	}
+	if s.tagIndex != nil && len(createdVehicle.Tags) > 0 {
+		s.tagIndex.SetVehicleTags(createdVehicle.ID.Hex(), createdVehicle.Tags)
+	}
+	if s.utilizationTracker != nil {
+		s.utilizationTracker.RecordStatus(createdVehicle.ID.Hex(), createdVehicle.Status, createdVehicle.CreatedAt)
+	}
+
+	return createdVehicle, nil
+}
 
-	// Create vehicle model
-	vehicle := &models.Vehicle{
+// newVehicleInFuelUnit builds a new vehicle whose capacity is given in the
+// request's fuel unit, or the fleet's, storing it in liters
+func (s *VehicleService) newVehicleInFuelUnit(req *CreateVehicleRequest) *models.Vehicle {
+	vehicle := newVehicleFromRequest(req)
+	vehicle.FuelUnit = req.FuelUnit
+	vehicle.MaxFuelCapacity = models.FuelToLiters(req.MaxFuelCapacity, s.fuelUnitFor(vehicle))
+	return vehicle
+}
+
+// newVehicleFromRequest builds a new vehicle with default telemetry values
+func newVehicleFromRequest(req *CreateVehicleRequest) *models.Vehicle {
+	now := time.Now()
+	return &models.Vehicle{
 		ID:               primitive.NewObjectID(),
 		Name:             req.Name,
 		PlateNumber:      req.PlateNumber,
@@ -167,7 +513,7 @@ func (s *VehicleService) CreateVehicle(req *CreateVehicleRequest) (*models.Vehic
 		},
 		Speed:           0,
 		Status:          "idle",
-		LastUpdate:      time.Now(),
+		LastUpdate:      now,
 		Odometer:        0,
 		FuelConsumption: req.FuelConsumption,
 		Alerts:          []models.Alert{},
@@ -175,28 +521,27 @@ func (s *VehicleService) CreateVehicle(req *CreateVehicleRequest) (*models.Vehic
 		Model:           req.Model,
 		Year:            req.Year,
 		VIN:             req.VIN,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-	}
-
-	createdVehicle, err := s.vehicleRepo.Create(vehicle)
-	if err != nil {
-		return nil, err
+		SpeedLimitKmh:   req.SpeedLimitKmh,
+		Metadata:        req.Metadata,
+		Tags:            req.Tags,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
+}
 
-	// Invalidate relevant cache entries after successful creation
-	if s.cacheManager != nil {
-		s.invalidateCacheOnCreate(createdVehicle)
+func (s *VehicleService) UpdateVehicle(ctx context.Context, id string, req *UpdateVehicleRequest) (*models.Vehicle, error) {
+	if req.VIN != "" {
+		normalized, err := normalizeVIN(req.VIN)
+		if err != nil {
+			return nil, err
+		}
+		req.VIN = normalized
 	}
 
-	return createdVehicle, nil
-}
-
-func (s *VehicleService) UpdateVehicle(id string, req *UpdateVehicleRequest) (*models.Vehicle, error) {
 	// Find existing vehicle
-	vehicle, err := s.vehicleRepo.FindByID(id)
+	vehicle, err := s.vehicleRepo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("vehicle not found")
+		return nil, vehicleLookupError(err)
 	}
 
 	// Store previous values for cache invalidation
@@ -204,35 +549,61 @@ func (s *VehicleService) UpdateVehicle(id string, req *UpdateVehicleRequest) (*m
 	previousDriver := vehicle.Driver
 	previousStatus := vehicle.Status
 
+	if req.Status != "" {
+		if err := checkStatusTransition(previousStatus, req.Status, req.ForceStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.FuelUnit != "" {
+		if !models.IsValidFuelUnit(req.FuelUnit) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFuelUnit, req.FuelUnit)
+		}
+		vehicle.FuelUnit = req.FuelUnit
+	}
+	fuelUnit := models.FuelUnitLiters
+	if !req.FuelInLiters {
+		fuelUnit = s.fuelUnitFor(vehicle)
+	}
+
 	// Update fields if provided
 	if req.Name != "" {
 		vehicle.Name = req.Name
 	}
 	if req.PlateNumber != "" {
 		// Check if new plate number is already taken
-		existingVehicle, _ := s.vehicleRepo.FindByPlateNumber(req.PlateNumber)
+		existingVehicle, _ := s.vehicleRepo.FindByPlateNumber(ctx, req.PlateNumber)
 		if existingVehicle != nil && existingVehicle.ID.Hex() != id {
-			return nil, errors.New("plate number already exists")
+			return nil, ErrDuplicatePlate
 		}
 		vehicle.PlateNumber = req.PlateNumber
 	}
+	if req.VIN != "" {
+		// Check if new VIN is already taken
+		existingVehicle, _ := s.vehicleRepo.FindByVIN(ctx, req.VIN)
+		if existingVehicle != nil && existingVehicle.ID.Hex() != id {
+			return nil, ErrDuplicateVIN
+		}
+		vehicle.VIN = req.VIN
+	}
 	if req.Driver != "" {
 		vehicle.Driver = req.Driver
 	}
 	if req.FuelLevel > 0 {
-		vehicle.FuelLevel = req.FuelLevel
+		vehicle.FuelLevel = models.FuelToLiters(req.FuelLevel, fuelUnit)
 	}
 	if req.Location != nil {
 		vehicle.Location = *req.Location
 	}
-	if req.Speed >= 0 {
-		vehicle.Speed = req.Speed
-	}
+	s.applyReportedSpeed(ctx, vehicle, req.Speed)
 	if req.Status != "" {
 		vehicle.Status = req.Status
 	}
-	if req.Odometer > 0 {
+	if req.Odometer > 0 && s.checkOdometerRollback(ctx, vehicle, req.Odometer) {
 		vehicle.Odometer = req.Odometer
+		if s.forecaster != nil {
+			s.forecaster.RecordOdometer(id, req.Odometer, time.Now())
+		}
 	}
 	if req.Make != "" {
 		vehicle.Make = req.Make
@@ -243,63 +614,172 @@ func (s *VehicleService) UpdateVehicle(id string, req *UpdateVehicleRequest) (*m
 	if req.Year > 0 {
 		vehicle.Year = req.Year
 	}
-	if req.VIN != "" {
-		vehicle.VIN = req.VIN
-	}
 	if req.MaxFuelCapacity > 0 {
-		vehicle.MaxFuelCapacity = req.MaxFuelCapacity
+		vehicle.MaxFuelCapacity = models.FuelToLiters(req.MaxFuelCapacity, fuelUnit)
 	}
 	if req.FuelConsumption > 0 {
 		vehicle.FuelConsumption = req.FuelConsumption
 	}
+	if req.SpeedLimitKmh > 0 {
+		vehicle.SpeedLimitKmh = req.SpeedLimitKmh
+	}
 
 	vehicle.LastUpdate = time.Now()
 	vehicle.UpdatedAt = time.Now()
+	reported := req.Location != nil || req.FuelLevel > 0 || (req.Speed != nil && *req.Speed > 0) || req.Odometer > 0
+	if reported {
+		markReported(vehicle, vehicle.LastUpdate)
+	}
+
+	// Resolve alerts whose condition has cleared before raising new ones
+	s.autoResolveAlerts(ctx, vehicle)
 
 	// Check for fuel theft if fuel level was updated
 	if req.FuelLevel > 0 && s.alertRepo != nil {
-		s.checkFuelTheft(vehicle, previousFuelLevel)
-		s.checkLowFuel(vehicle)
-		s.checkSpeeding(vehicle)
+		s.checkFuelTheft(ctx, vehicle, previousFuelLevel)
+		s.checkLowFuel(ctx, vehicle)
+		s.checkSpeeding(ctx, vehicle)
 	}
 
-	updatedVehicle, err := s.vehicleRepo.Update(id, vehicle)
+	updatedVehicle, err := s.vehicleRepo.Update(ctx, id, vehicle)
 	if err != nil {
 		return nil, err
 	}
 
 	// Invalidate relevant cache entries after successful update
 	if s.cacheManager != nil {
-		s.invalidateCacheOnUpdate(updatedVehicle, previousDriver, previousStatus)
+		s.invalidateCacheOnUpdate(ctx, updatedVehicle, previousDriver, previousStatus)
+	}
+
+	// A report confirms the vehicle is still in its status
+	if s.utilizationTracker != nil && (reported || req.Status != "") {
+		s.utilizationTracker.RecordStatus(id, updatedVehicle.Status, vehicle.LastUpdate)
+	}
+
+	if s.events != nil && updatedVehicle.Status != previousStatus {
+		s.events.Publish(newWebhookEvent(models.EventVehicleStatusChanged, id, map[string]interface{}{
+			"previousStatus": previousStatus,
+			"status":         updatedVehicle.Status,
+			"vehicleName":    updatedVehicle.Name,
+		}))
 	}
 
 	return updatedVehicle, nil
 }
 
-func (s *VehicleService) DeleteVehicle(id string) error {
+// vehicleArchiveStore is the subset of the vehicle repository used to archive,
+// restore and permanently delete vehicles
+type vehicleArchiveStore interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+	Archive(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// DeleteVehicle archives a vehicle. Archived vehicles are hidden from listings but
+// keep their history so maintenance records and alerts still resolve.
+func (s *VehicleService) DeleteVehicle(ctx context.Context, id string) error {
+	return s.archiveVehicle(ctx, s.vehicleRepo, id)
+}
+
+// RestoreVehicle brings an archived vehicle back into listings
+func (s *VehicleService) RestoreVehicle(ctx context.Context, id string) (*models.Vehicle, error) {
+	return s.restoreVehicle(ctx, s.vehicleRepo, id)
+}
+
+// HardDeleteVehicle permanently removes a vehicle and cannot be undone
+func (s *VehicleService) HardDeleteVehicle(ctx context.Context, id string) error {
+	return s.hardDeleteVehicle(ctx, s.vehicleRepo, id)
+}
+
+func (s *VehicleService) archiveVehicle(ctx context.Context, store vehicleArchiveStore, id string) error {
 	// Check if vehicle exists and get it for cache invalidation
-	vehicle, err := s.vehicleRepo.FindByID(id)
+	vehicle, err := store.FindByID(ctx, id)
 	if err != nil {
-		return errors.New("vehicle not found")
+		return vehicleLookupError(err)
+	}
+	if vehicle.IsArchived() {
+		return ErrVehicleArchived
 	}
 
-	err = s.vehicleRepo.Delete(id)
+	if err := store.Archive(ctx, id); err != nil {
+		return err
+	}
+
+	// Archived vehicles drop out of every list, so invalidate as for a delete
+	if s.cacheManager != nil {
+		s.invalidateCacheOnDelete(ctx, vehicle)
+	}
+	if s.tagIndex != nil {
+		s.tagIndex.SetVehicleTags(id, nil)
+	}
+	if s.speedHistory != nil {
+		s.speedHistory.Forget(id)
+	}
+
+	return nil
+}
+
+func (s *VehicleService) restoreVehicle(ctx context.Context, store vehicleArchiveStore, id string) (*models.Vehicle, error) {
+	vehicle, err := store.FindByID(ctx, id)
 	if err != nil {
+		return nil, vehicleLookupError(err)
+	}
+	if !vehicle.IsArchived() {
+		return nil, ErrVehicleNotArchived
+	}
+
+	if err := store.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	vehicle.DeletedAt = nil
+
+	// The vehicle reappears in the same lists an archive removed it from
+	if s.cacheManager != nil {
+		s.invalidateCacheOnDelete(ctx, vehicle)
+	}
+	if s.tagIndex != nil {
+		s.tagIndex.SetVehicleTags(id, vehicle.Tags)
+	}
+
+	return vehicle, nil
+}
+
+func (s *VehicleService) hardDeleteVehicle(ctx context.Context, store vehicleArchiveStore, id string) error {
+	// Check if vehicle exists and get it for cache invalidation
+	vehicle, err := store.FindByID(ctx, id)
+	if err != nil {
+		return vehicleLookupError(err)
+	}
+
+	if err := store.Delete(ctx, id); err != nil {
 		return err
 	}
 
 	// Invalidate relevant cache entries after successful deletion
 	if s.cacheManager != nil {
-		s.invalidateCacheOnDelete(vehicle)
+		s.invalidateCacheOnDelete(ctx, vehicle)
+	}
+	if s.tagIndex != nil {
+		s.tagIndex.SetVehicleTags(id, nil)
+	}
+	if s.speedHistory != nil {
+		s.speedHistory.Forget(id)
 	}
 
 	return nil
 }
 
-func (s *VehicleService) GetVehicleUpdates() ([]*models.Vehicle, error) {
+// GetAllVehiclesIncludingArchived returns every vehicle, including archived ones.
+// The result is not cached since it is only used for audits and restores.
+func (s *VehicleService) GetAllVehiclesIncludingArchived(ctx context.Context) ([]*models.Vehicle, error) {
+	return s.vehicleRepo.FindAllIncludingArchived(ctx)
+}
+
+func (s *VehicleService) GetVehicleUpdates(ctx context.Context) ([]*models.Vehicle, error) {
 	// Simply return all vehicles without simulation
 	// The optimized telemetry service handles updates separately
-	vehicles, err := s.vehicleRepo.FindAll()
+	vehicles, err := s.vehicleRepo.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +787,7 @@ func (s *VehicleService) GetVehicleUpdates() ([]*models.Vehicle, error) {
 	return vehicles, nil
 }
 
-func (s *VehicleService) GetVehiclesByStatus(status string) ([]*models.Vehicle, error) {
+func (s *VehicleService) GetVehiclesByStatus(ctx context.Context, status string) ([]*models.Vehicle, error) {
 	// Try cache first if cache manager is available
 	if s.cacheManager != nil {
 		cacheKey := fmt.Sprintf("vehicles_by_status_%s", status)
@@ -317,12 +797,12 @@ func (s *VehicleService) GetVehiclesByStatus(status string) ([]*models.Vehicle,
 		}
 		// Log cache miss but continue to database
 		if err != nil {
-			fmt.Printf("Cache error for GetVehiclesByStatus(%s): %v\n", status, err)
+			logger.FromContext(ctx).Warn("Cache error for GetVehiclesByStatus", "status", status, "error", err)
 		}
 	}
 
 	// Fallback to database
-	vehicles, err := s.vehicleRepo.FindByStatus(status)
+	vehicles, err := s.vehicleRepo.FindByStatus(ctx, status)
 	if err != nil {
 		return nil, err
 	}
@@ -330,16 +810,118 @@ func (s *VehicleService) GetVehiclesByStatus(status string) ([]*models.Vehicle,
 	// Cache the result if cache manager is available
 	if s.cacheManager != nil {
 		cacheKey := fmt.Sprintf("vehicles_by_status_%s", status)
-		ttl := s.cacheConfig.GetTTLForDataType("vehicle_list")
+		ttl := s.cacheTTL("vehicle_list")
 		if cacheErr := s.cacheManager.SetVehicleList(cacheKey, vehicles, ttl); cacheErr != nil {
-			fmt.Printf("Failed to cache vehicles by status %s: %v\n", status, cacheErr)
+			logger.FromContext(ctx).Warn("Failed to cache vehicles by status", "status", status, "error", cacheErr)
 		}
 	}
 
 	return vehicles, nil
 }
 
-func (s *VehicleService) GetVehiclesByDriver(driver string) ([]*models.Vehicle, error) {
+// ErrInvalidVehicleStatus is returned when a status filter names an unknown status
+var ErrInvalidVehicleStatus = newDomainError(ErrValidation, "invalid vehicle status")
+
+// vehicleStatuses are the statuses a vehicle can be in
+var vehicleStatuses = map[string]bool{"active": true, "idle": true, "maintenance": true, "offline": true}
+
+// ErrInvalidStatusTransition is returned when an update changes a vehicle's
+// status in a way the status state machine doesn't allow
+var ErrInvalidStatusTransition = newDomainError(ErrConflict, "invalid status transition")
+
+// checkStatusTransition rejects status changes the state machine doesn't
+// allow, unless they are forced
+func checkStatusTransition(from, to string, force bool) error {
+	if force || status.TransitionAllowed(from, to) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+}
+
+// vehicleStatusFinder is the subset of the vehicle repository used by status filters
+type vehicleStatusFinder interface {
+	FindByStatus(ctx context.Context, status string) ([]*models.Vehicle, error)
+	FindByStatuses(ctx context.Context, statuses []string) ([]*models.Vehicle, error)
+}
+
+// GetVehiclesByStatuses returns vehicles in any of the given statuses. A single
+// status shares the by-status list cache, which writes invalidate. Multi-status
+// lists are keyed by the sorted status set and are not invalidated on writes,
+// so they are cached with the short filtered-list TTL instead.
+func (s *VehicleService) GetVehiclesByStatuses(ctx context.Context, statuses []string) ([]*models.Vehicle, error) {
+	return s.getVehiclesByStatuses(ctx, s.vehicleRepo, statuses)
+}
+
+func (s *VehicleService) getVehiclesByStatuses(ctx context.Context, finder vehicleStatusFinder, statuses []string) ([]*models.Vehicle, error) {
+	normalized, err := normalizeStatuses(statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey, ttlType := statusListCacheKey(normalized)
+
+	// Try cache first if cache manager is available
+	if s.cacheManager != nil {
+		cachedVehicles, err := s.cacheManager.GetVehicleList(cacheKey)
+		if err == nil && cachedVehicles != nil {
+			return cachedVehicles, nil
+		}
+	}
+
+	var vehicles []*models.Vehicle
+	if len(normalized) == 1 {
+		vehicles, err = finder.FindByStatus(ctx, normalized[0])
+	} else {
+		vehicles, err = finder.FindByStatuses(ctx, normalized)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the result if cache manager is available
+	if s.cacheManager != nil {
+		ttl := s.cacheTTL(ttlType)
+		if cacheErr := s.cacheManager.SetVehicleList(cacheKey, vehicles, ttl); cacheErr != nil {
+			logger.FromContext(ctx).Warn("Failed to cache vehicles by statuses", "statuses", normalized, "error", cacheErr)
+		}
+	}
+
+	return vehicles, nil
+}
+
+// normalizeStatuses lowercases, dedupes and sorts a status set so equivalent
+// filters share one cache key
+func normalizeStatuses(statuses []string) ([]string, error) {
+	seen := make(map[string]bool, len(statuses))
+	normalized := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == "" || seen[status] {
+			continue
+		}
+		if !vehicleStatuses[status] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVehicleStatus, status)
+		}
+		seen[status] = true
+		normalized = append(normalized, status)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one status is required", ErrInvalidVehicleStatus)
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// statusListCacheKey returns the cache key and TTL data type for a normalized status set
+func statusListCacheKey(statuses []string) (string, string) {
+	if len(statuses) == 1 {
+		return fmt.Sprintf("vehicles_by_status_%s", statuses[0]), "vehicle_list"
+	}
+	return fmt.Sprintf("vehicles_by_statuses_%s", strings.Join(statuses, ",")), "filtered_list"
+}
+
+func (s *VehicleService) GetVehiclesByDriver(ctx context.Context, driver string) ([]*models.Vehicle, error) {
 	// Try cache first if cache manager is available
 	if s.cacheManager != nil {
 		cacheKey := fmt.Sprintf("vehicles_by_driver_%s", driver)
@@ -349,12 +931,12 @@ func (s *VehicleService) GetVehiclesByDriver(driver string) ([]*models.Vehicle,
 		}
 		// Log cache miss but continue to database
 		if err != nil {
-			fmt.Printf("Cache error for GetVehiclesByDriver(%s): %v\n", driver, err)
+			logger.FromContext(ctx).Warn("Cache error for GetVehiclesByDriver", "driver", driver, "error", err)
 		}
 	}
 
 	// Fallback to database
-	vehicles, err := s.vehicleRepo.FindByDriver(driver)
+	vehicles, err := s.vehicleRepo.FindByDriver(ctx, driver)
 	if err != nil {
 		return nil, err
 	}
@@ -362,9 +944,9 @@ func (s *VehicleService) GetVehiclesByDriver(driver string) ([]*models.Vehicle,
 	// Cache the result if cache manager is available
 	if s.cacheManager != nil {
 		cacheKey := fmt.Sprintf("vehicles_by_driver_%s", driver)
-		ttl := s.cacheConfig.GetTTLForDataType("vehicle_list")
+		ttl := s.cacheTTL("vehicle_list")
 		if cacheErr := s.cacheManager.SetVehicleList(cacheKey, vehicles, ttl); cacheErr != nil {
-			fmt.Printf("Failed to cache vehicles by driver %s: %v\n", driver, cacheErr)
+			logger.FromContext(ctx).Warn("Failed to cache vehicles by driver", "driver", driver, "error", cacheErr)
 		}
 	}
 
@@ -372,7 +954,7 @@ func (s *VehicleService) GetVehiclesByDriver(driver string) ([]*models.Vehicle,
 }
 
 // simulateVehicleUpdates simulates real-time vehicle data changes using batch processing
-func (s *VehicleService) simulateVehicleUpdates(vehicle *models.Vehicle) {
+func (s *VehicleService) simulateVehicleUpdates(ctx context.Context, vehicle *models.Vehicle) {
 	now := time.Now()
 	
 	// Only update if last update was more than 5 seconds ago
@@ -391,11 +973,6 @@ func (s *VehicleService) simulateVehicleUpdates(vehicle *models.Vehicle) {
 		newFuelLevel := math.Max(0, vehicle.FuelLevel-fuelDrop)
 		updateData.FuelLevel = &newFuelLevel
 		hasUpdates = true
-		
-		// Generate critical alert for fuel theft
-		if s.alertRepo != nil && s.wsManager != nil {
-			s.broadcastFuelTheftAlert(vehicle, previousFuelLevel, newFuelLevel)
-		}
 	} else if random < 0.7 { // 70% chance of normal consumption
 		consumption := rand.Float64() * 0.5
 		newFuelLevel := math.Max(0, vehicle.FuelLevel-consumption)
@@ -403,6 +980,11 @@ func (s *VehicleService) simulateVehicleUpdates(vehicle *models.Vehicle) {
 		hasUpdates = true
 	}
 
+	// Normal consumption feeds the theft detector's baseline too
+	if updateData.FuelLevel != nil && s.alertRepo != nil && s.wsManager != nil {
+		s.broadcastFuelTheftAlert(ctx, vehicle, previousFuelLevel, *updateData.FuelLevel)
+	}
+
 	// Simulate location changes for active vehicles
 	if vehicle.Status == "active" {
 		variation := 0.01
@@ -418,9 +1000,11 @@ func (s *VehicleService) simulateVehicleUpdates(vehicle *models.Vehicle) {
 		newSpeed := int(rand.Float64() * 80) // 0-80 km/h
 		updateData.Speed = &newSpeed
 		
-		// Check for speeding alerts
-		if newSpeed > 80 && s.alertRepo != nil && s.wsManager != nil {
-			s.broadcastSpeedingAlert(vehicle, newSpeed)
+		// Check for speeding alerts against the limit at the new location
+		if s.alertRepo != nil && s.wsManager != nil {
+			if limit := s.speedLimitAt(vehicle, newLocation); newSpeed > limit.Kmh {
+				s.broadcastSpeedingAlert(ctx, vehicle, newSpeed, limit)
+			}
 		}
 		
 		// Simulate odometer increase
@@ -440,17 +1024,17 @@ func (s *VehicleService) simulateVehicleUpdates(vehicle *models.Vehicle) {
 	if hasUpdates && s.batchProcessor != nil {
 		if err := s.batchProcessor.AddUpdate(vehicle.ID.Hex(), updateData); err != nil {
 			// Fallback to direct database update if batch processing fails
-			fmt.Printf("Batch processing failed for vehicle %s, falling back to direct update: %v\n", vehicle.ID.Hex(), err)
-			s.fallbackToDirectUpdate(vehicle, updateData)
+			logger.FromContext(ctx).Warn("Batch processing failed, falling back to direct update", "vehicle_id", vehicle.ID.Hex(), "error", err)
+			s.fallbackToDirectUpdate(ctx, vehicle, updateData)
 		}
 	} else if hasUpdates {
 		// No batch processor available, use direct update
-		s.fallbackToDirectUpdate(vehicle, updateData)
+		s.fallbackToDirectUpdate(ctx, vehicle, updateData)
 	}
 }
 
 // fallbackToDirectUpdate performs direct database update when batch processing is unavailable
-func (s *VehicleService) fallbackToDirectUpdate(vehicle *models.Vehicle, updateData batch.VehicleUpdateData) {
+func (s *VehicleService) fallbackToDirectUpdate(ctx context.Context, vehicle *models.Vehicle, updateData batch.VehicleUpdateData) {
 	// Apply updates to vehicle model
 	if updateData.FuelLevel != nil {
 		vehicle.FuelLevel = *updateData.FuelLevel
@@ -459,18 +1043,28 @@ func (s *VehicleService) fallbackToDirectUpdate(vehicle *models.Vehicle, updateD
 		vehicle.Location = *updateData.Location
 	}
 	if updateData.Speed != nil {
-		vehicle.Speed = *updateData.Speed
+		if s.checkSpeedPlausible(ctx, vehicle, *updateData.Speed) {
+			vehicle.Speed = *updateData.Speed
+		} else {
+			updateData.Speed = nil // don't broadcast the rejected reading
+		}
 	}
-	if updateData.Odometer != nil {
+	if updateData.Odometer != nil && s.checkOdometerRollback(ctx, vehicle, *updateData.Odometer) {
 		vehicle.Odometer = *updateData.Odometer
+		if s.forecaster != nil {
+			s.forecaster.RecordOdometer(vehicle.ID.Hex(), vehicle.Odometer, updateData.Timestamp)
+		}
 	}
 	
 	vehicle.LastUpdate = updateData.Timestamp
 	vehicle.UpdatedAt = updateData.Timestamp
+	markReported(vehicle, updateData.Timestamp)
+
+	s.autoResolveAlerts(ctx, vehicle)
 
 	// Update in database directly
-	if _, err := s.vehicleRepo.Update(vehicle.ID.Hex(), vehicle); err != nil {
-		fmt.Printf("Failed to update vehicle %s directly: %v\n", vehicle.ID.Hex(), err)
+	if _, err := s.vehicleRepo.Update(ctx, vehicle.ID.Hex(), vehicle); err != nil {
+		logger.FromContext(ctx).Error("Failed to update vehicle directly", "vehicle_id", vehicle.ID.Hex(), "error", err)
 		return
 	}
 
@@ -478,7 +1072,7 @@ func (s *VehicleService) fallbackToDirectUpdate(vehicle *models.Vehicle, updateD
 	if s.wsManager != nil {
 		wsUpdate := s.convertToWebSocketUpdate(vehicle.ID.Hex(), updateData)
 		if err := s.wsManager.BroadcastVehicleUpdate(vehicle.ID.Hex(), wsUpdate); err != nil {
-			fmt.Printf("Failed to broadcast vehicle update via WebSocket: %v\n", err)
+			logger.FromContext(ctx).Warn("Failed to broadcast vehicle update via WebSocket", "vehicle_id", vehicle.ID.Hex(), "error", err)
 		}
 	}
 }
@@ -537,109 +1131,130 @@ func (s *VehicleService) convertToWebSocketUpdate(vehicleID string, updateData b
 	}
 }
 
-// broadcastFuelTheftAlert broadcasts a critical fuel theft alert
-func (s *VehicleService) broadcastFuelTheftAlert(vehicle *models.Vehicle, previousLevel, newLevel float64) {
-	fuelDrop := previousLevel - newLevel
-	if fuelDrop > 15 { // Threshold for fuel theft detection
-		// Create alert in database
-		alert := &models.Alert{
-			ID:        primitive.NewObjectID(),
-			VehicleID: vehicle.ID.Hex(),
-			Type:      "fuel_theft",
-			Message:   fmt.Sprintf("Abnormal fuel drop detected: %.1fL lost - Possible theft", fuelDrop),
-			Severity:  "critical",
-			Timestamp: time.Now(),
-			Resolved:  false,
-		}
-		
-		if _, err := s.alertRepo.Create(alert); err != nil {
-			fmt.Printf("Failed to create fuel theft alert: %v\n", err)
-		}
-		
-		// Broadcast critical alert via WebSocket
-		wsUpdate := websocket.VehicleUpdate{
-			VehicleID:  vehicle.ID.Hex(),
-			UpdateType: "alert",
-			Data: map[string]interface{}{
-				"alertType":    "fuel_theft",
-				"alertId":      alert.ID.Hex(),
-				"message":      alert.Message,
-				"severity":     alert.Severity,
-				"fuelLevel":    newLevel,
-				"fuelDrop":     fuelDrop,
-				"previousLevel": previousLevel,
-			},
-			Timestamp: alert.Timestamp,
-			Priority:  websocket.PriorityCritical,
-		}
-		
-		if err := s.wsManager.BroadcastVehicleUpdate(vehicle.ID.Hex(), wsUpdate); err != nil {
-			fmt.Printf("Failed to broadcast fuel theft alert: %v\n", err)
-		}
+// broadcastFuelTheftAlert raises a fuel theft alert for a simulated fuel drop
+func (s *VehicleService) broadcastFuelTheftAlert(ctx context.Context, vehicle *models.Vehicle, previousLevel, newLevel float64) {
+	if check, ok := s.detectFuelTheft(vehicle, previousLevel, newLevel, time.Now()); ok {
+		s.createAndDispatchAlert(ctx, vehicle, "fuel_theft",
+			fmt.Sprintf("Abnormal fuel drop detected: %s lost - Possible theft", fuelAmount(check.Drop, s.fuelUnitFor(vehicle))), "",
+			fuelTheftAlertData(check, previousLevel, newLevel, s.fuelUnitFor(vehicle)))
 	}
 }
 
-// broadcastSpeedingAlert broadcasts a high priority speeding alert
-func (s *VehicleService) broadcastSpeedingAlert(vehicle *models.Vehicle, speed int) {
-	// Create alert in database
+// broadcastSpeedingAlert raises a speeding alert for a simulated speed reading
+func (s *VehicleService) broadcastSpeedingAlert(ctx context.Context, vehicle *models.Vehicle, speed int, limit SpeedLimit) {
+	s.createAndDispatchAlert(ctx, vehicle, "speeding",
+		fmt.Sprintf("Vehicle exceeding speed limit: %d km/h in a %d km/h limit", speed, limit.Kmh), "",
+		limit.alertData(speed))
+}
+
+// Alert generation methods
+
+// AlertNotifier is told about every alert the vehicle service raises, after it
+// has been persisted and broadcast
+type AlertNotifier interface {
+	NotifyAlert(vehicle *models.Vehicle, alert *models.Alert)
+}
+
+// SetAlertNotifier sets the notifier for generated alerts
+func (s *VehicleService) SetAlertNotifier(notifier AlertNotifier) {
+	s.alertNotifier = notifier
+}
+
+// createAndDispatchAlert raises an alert on a vehicle. Every alert goes through
+// the same side effects, each skipped when its dependency isn't configured:
+// it is persisted, added to the vehicle's embedded alerts, broadcast over
+// WebSocket with data merged into the payload, and handed to the notifier. An
+// empty severity uses the severity policy's default for the alert type.
+func (s *VehicleService) createAndDispatchAlert(ctx context.Context, vehicle *models.Vehicle, alertType, message, severity string, data map[string]interface{}) *models.Alert {
+	if severity == "" {
+		severity = s.severityPolicy.SeverityFor(alertType)
+	}
+
 	alert := &models.Alert{
 		ID:        primitive.NewObjectID(),
 		VehicleID: vehicle.ID.Hex(),
-		Type:      "speeding",
-		Message:   fmt.Sprintf("Vehicle exceeding speed limit: %d km/h", speed),
-		Severity:  "high",
+		Type:      alertType,
+		Message:   message,
+		Severity:  severity,
 		Timestamp: time.Now(),
 		Resolved:  false,
 	}
-	
-	if _, err := s.alertRepo.Create(alert); err != nil {
-		fmt.Printf("Failed to create speeding alert: %v\n", err)
+
+	if s.alertRepo != nil {
+		if _, err := s.alertRepo.Create(ctx, alert); err != nil {
+			logger.FromContext(ctx).Error("Failed to create alert", "alert_type", alertType, "vehicle_id", vehicle.ID.Hex(), "error", err)
+		}
 	}
-	
-	// Broadcast high priority alert via WebSocket
-	wsUpdate := websocket.VehicleUpdate{
-		VehicleID:  vehicle.ID.Hex(),
-		UpdateType: "alert",
-		Data: map[string]interface{}{
-			"alertType": "speeding",
+
+	syncEmbeddedAlert(vehicle, *alert)
+
+	if s.wsManager != nil {
+		payload := map[string]interface{}{
+			"alertType": alertType,
 			"alertId":   alert.ID.Hex(),
 			"message":   alert.Message,
 			"severity":  alert.Severity,
-			"speed":     speed,
-			"speedLimit": 80,
-		},
-		Timestamp: alert.Timestamp,
-		Priority:  websocket.PriorityHigh,
+		}
+		for key, value := range data {
+			payload[key] = value
+		}
+
+		wsUpdate := websocket.VehicleUpdate{
+			VehicleID:  vehicle.ID.Hex(),
+			UpdateType: "alert",
+			Data:       payload,
+			Timestamp:  alert.Timestamp,
+			Priority:   alert.Severity, // severities share the priority levels
+		}
+		if err := s.wsManager.BroadcastVehicleUpdate(vehicle.ID.Hex(), wsUpdate); err != nil {
+			logger.FromContext(ctx).Warn("Failed to broadcast alert", "alert_type", alertType, "vehicle_id", vehicle.ID.Hex(), "error", err)
+		}
 	}
-	
-	if err := s.wsManager.BroadcastVehicleUpdate(vehicle.ID.Hex(), wsUpdate); err != nil {
-		fmt.Printf("Failed to broadcast speeding alert: %v\n", err)
+
+	if s.alertNotifier != nil {
+		s.alertNotifier.NotifyAlert(vehicle, alert)
 	}
+
+	return alert
 }
 
-// Alert generation methods
-func (s *VehicleService) checkFuelTheft(vehicle *models.Vehicle, previousLevel float64) {
-	fuelDrop := previousLevel - vehicle.FuelLevel
-	if fuelDrop > 15 { // Threshold for fuel theft detection
-		alert := &models.Alert{
-			ID:        primitive.NewObjectID(),
-			VehicleID: vehicle.ID.Hex(),
-			Type:      "fuel_theft",
-			Message:   "Abnormal fuel drop detected - Possible theft",
-			Severity:  "critical",
-			Timestamp: time.Now(),
-			Resolved:  false,
-		}
-		s.alertRepo.Create(alert)
-		
-		// Add alert to vehicle
-		vehicle.Alerts = append(vehicle.Alerts, *alert)
+func (s *VehicleService) checkFuelTheft(ctx context.Context, vehicle *models.Vehicle, previousLevel float64) {
+	if check, ok := s.detectFuelTheft(vehicle, previousLevel, vehicle.FuelLevel, time.Now()); ok {
+		s.createAndDispatchAlert(ctx, vehicle, "fuel_theft", "Abnormal fuel drop detected - Possible theft", "",
+			fuelTheftAlertData(check, previousLevel, vehicle.FuelLevel, s.fuelUnitFor(vehicle)))
 	}
 }
 
-func (s *VehicleService) checkLowFuel(vehicle *models.Vehicle) {
-	fuelPercentage := (vehicle.FuelLevel / vehicle.MaxFuelCapacity) * 100
-	if fuelPercentage < 20 { // Low fuel threshold
+// detectFuelTheft runs a fuel reading through the anomaly detector and reports
+// whether it looks like theft
+func (s *VehicleService) detectFuelTheft(vehicle *models.Vehicle, previousLevel, level float64, at time.Time) (FuelCheck, bool) {
+	if s.fuelAnomalies == nil {
+		return FuelCheck{}, false
+	}
+	check := s.fuelAnomalies.Check(vehicle.ID.Hex(), previousLevel, level, vehicle.MaxFuelCapacity, at)
+	return check, check.Theft
+}
+
+// fuelTheftAlertData describes a flagged fuel drop in the vehicle's fuel unit,
+// including the consumption rates it was judged against when the vehicle had
+// a baseline
+func fuelTheftAlertData(check FuelCheck, previousLevel, level float64, unit string) map[string]interface{} {
+	data := map[string]interface{}{
+		"fuelLevel":     models.FuelFromLiters(level, unit),
+		"fuelDrop":      models.FuelFromLiters(check.Drop, unit),
+		"previousLevel": models.FuelFromLiters(previousLevel, unit),
+		"fuelUnit":      unit,
+	}
+	if check.Rate > 0 {
+		data["ratePerHour"] = models.FuelFromLiters(check.Rate, unit)
+		data["baselineRatePerHour"] = models.FuelFromLiters(check.BaselineRate, unit)
+		data["maxExpectedDrop"] = models.FuelFromLiters(check.MaxDrop, unit)
+	}
+	return data
+}
+
+func (s *VehicleService) checkLowFuel(ctx context.Context, vehicle *models.Vehicle) {
+	if fuelPercentage(vehicle) < lowFuelThresholdPercent {
 		// Check if alert already exists
 		hasLowFuelAlert := false
 		for _, alert := range vehicle.Alerts {
@@ -650,141 +1265,239 @@ func (s *VehicleService) checkLowFuel(vehicle *models.Vehicle) {
 		}
 		
 		if !hasLowFuelAlert {
-			alert := &models.Alert{
-				ID:        primitive.NewObjectID(),
-				VehicleID: vehicle.ID.Hex(),
-				Type:      "low_fuel",
-				Message:   "Low fuel level detected",
-				Severity:  "medium",
-				Timestamp: time.Now(),
-				Resolved:  false,
-			}
-			s.alertRepo.Create(alert)
-			
-			// Add alert to vehicle
-			vehicle.Alerts = append(vehicle.Alerts, *alert)
+			unit := s.fuelUnitFor(vehicle)
+			s.createAndDispatchAlert(ctx, vehicle, "low_fuel",
+				fmt.Sprintf("Low fuel level detected: %s left", fuelAmount(vehicle.FuelLevel, unit)), "",
+				map[string]interface{}{"fuelLevel": models.FuelFromLiters(vehicle.FuelLevel, unit), "fuelUnit": unit})
 		}
 	}
 }
 
-func (s *VehicleService) checkSpeeding(vehicle *models.Vehicle) {
-	if vehicle.Speed > 80 { // Speed limit threshold
-		alert := &models.Alert{
-			ID:        primitive.NewObjectID(),
-			VehicleID: vehicle.ID.Hex(),
-			Type:      "speeding",
-			Message:   "Vehicle exceeding speed limit",
-			Severity:  "high",
-			Timestamp: time.Now(),
-			Resolved:  false,
+// checkSpeeding raises a speeding alert when the vehicle is over the limit
+// that applies at its current location
+func (s *VehicleService) checkSpeeding(ctx context.Context, vehicle *models.Vehicle) {
+	if limit := s.speedLimitAt(vehicle, vehicle.Location); vehicle.Speed > limit.Kmh {
+		s.createAndDispatchAlert(ctx, vehicle, "speeding", "Vehicle exceeding speed limit", "",
+			limit.alertData(vehicle.Speed))
+	}
+}
+
+// checkOdometerRollback reports whether a new odometer reading can be stored.
+// Readings that drop more than odometerRollbackToleranceKm below the stored
+// value indicate tampering or a sensor fault: they raise an odometer_anomaly
+// alert and the last known good value is kept.
+func (s *VehicleService) checkOdometerRollback(ctx context.Context, vehicle *models.Vehicle, newOdometer int) bool {
+	if newOdometer >= vehicle.Odometer-odometerRollbackToleranceKm {
+		return true
+	}
+
+	s.createAndDispatchAlert(ctx, vehicle, "odometer_anomaly",
+		fmt.Sprintf("Odometer rollback detected: reading %d km is below last known %d km", newOdometer, vehicle.Odometer), "",
+		map[string]interface{}{
+			"odometer":         newOdometer,
+			"previousOdometer": vehicle.Odometer,
+		})
+	return false
+}
+
+// applyReportedSpeed stores an update's speed reading once it passes the
+// plausibility check. Updates that don't carry a speed leave it untouched and
+// are never checked, so a raised minimum can't flag unrelated edits.
+func (s *VehicleService) applyReportedSpeed(ctx context.Context, vehicle *models.Vehicle, speed *int) {
+	if speed == nil || !s.checkSpeedPlausible(ctx, vehicle, *speed) {
+		return
+	}
+	vehicle.Speed = *speed
+	if *speed > 0 && s.speedHistory != nil {
+		s.speedHistory.RecordSpeed(vehicle.ID.Hex(), *speed, &vehicle.Location, time.Now())
+	}
+}
+
+// checkSpeedPlausible reports whether a speed reading can be stored. Readings
+// outside the configured plausible range are sensor faults: they raise a
+// speed_anomaly alert instead of a speeding alert and the previous speed is kept.
+func (s *VehicleService) checkSpeedPlausible(ctx context.Context, vehicle *models.Vehicle, speed int) bool {
+	s.settingsMu.RLock()
+	bounds := s.speedBounds
+	s.settingsMu.RUnlock()
+
+	if bounds.IsPlausible(speed) {
+		return true
+	}
+
+	logger.FromContext(ctx).Warn("Rejected implausible speed", "vehicle_id", vehicle.ID.Hex(), "speed_kmh", speed)
+
+	s.createAndDispatchAlert(ctx, vehicle, "speed_anomaly",
+		fmt.Sprintf("Implausible speed reading of %d km/h ignored", speed), "",
+		map[string]interface{}{"speed": speed})
+	return false
+}
+
+// autoResolveAlerts resolves the vehicle's open alerts whose registered
+// resolution rule is satisfied by its current state, and drops resolved
+// alerts from the embedded ones
+func (s *VehicleService) autoResolveAlerts(ctx context.Context, vehicle *models.Vehicle) {
+	var resolved []models.Alert
+	if s.autoResolve != nil {
+		resolved = s.autoResolve.Apply(vehicle, time.Now())
+	}
+	vehicle.Alerts = boundEmbeddedAlerts(vehicle.Alerts)
+	if s.alertRepo == nil {
+		return
+	}
+
+	for _, alert := range resolved {
+		if err := s.alertRepo.MarkAsResolved(ctx, alert.ID.Hex()); err != nil {
+			logger.FromContext(ctx).Error("Failed to auto-resolve alert", "alert_type", alert.Type, "alert_id", alert.ID.Hex(), "error", err)
 		}
-		s.alertRepo.Create(alert)
-		
-		// Add alert to vehicle
-		vehicle.Alerts = append(vehicle.Alerts, *alert)
 	}
 }
 
 // Cache invalidation helper methods
 
 // invalidateCacheOnCreate invalidates relevant cache entries when a vehicle is created
-func (s *VehicleService) invalidateCacheOnCreate(vehicle *models.Vehicle) {
+func (s *VehicleService) invalidateCacheOnCreate(ctx context.Context, vehicle *models.Vehicle) {
 	// Invalidate all vehicles list
 	if err := s.cacheManager.Delete("fleet:vehicle_list:all_vehicles"); err != nil {
-		fmt.Printf("Failed to invalidate all vehicles cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate all vehicles cache", "error", err)
 	}
 
 	// Invalidate vehicles by status list
 	statusCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_status_%s", vehicle.Status)
 	if err := s.cacheManager.Delete(statusCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by status cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by status cache", "error", err)
 	}
 
 	// Invalidate vehicles by driver list
 	driverCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_driver_%s", vehicle.Driver)
 	if err := s.cacheManager.Delete(driverCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by driver cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by driver cache", "error", err)
 	}
 
+	// Invalidate the lists of the vehicle's tags
+	s.invalidateTagLists(ctx, vehicle.Tags)
+
 	// Cache the new vehicle
-	ttl := s.cacheConfig.GetTTLForDataType("vehicle")
+	ttl := s.cacheTTL("vehicle")
 	if err := s.cacheManager.SetVehicle(vehicle.ID.Hex(), vehicle, ttl); err != nil {
-		fmt.Printf("Failed to cache new vehicle %s: %v\n", vehicle.ID.Hex(), err)
+		logger.FromContext(ctx).Warn("Failed to cache new vehicle", "vehicle_id", vehicle.ID.Hex(), "error", err)
+	}
+}
+
+// invalidateCacheOnImport invalidates list caches once for a batch of imported vehicles
+func (s *VehicleService) invalidateCacheOnImport(ctx context.Context, vehicles []*models.Vehicle) {
+	// Invalidate all vehicles list
+	if err := s.cacheManager.Delete("fleet:vehicle_list:all_vehicles"); err != nil {
+		logger.FromContext(ctx).Warn("Failed to invalidate all vehicles cache", "error", err)
+	}
+
+	statuses := make(map[string]bool)
+	drivers := make(map[string]bool)
+	tags := make(map[string]bool)
+	for _, vehicle := range vehicles {
+		statuses[vehicle.Status] = true
+		drivers[vehicle.Driver] = true
+		for _, tag := range vehicle.Tags {
+			tags[tag] = true
+		}
+	}
+
+	// Invalidate each affected status and driver list once
+	for status := range statuses {
+		statusCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_status_%s", status)
+		if err := s.cacheManager.Delete(statusCacheKey); err != nil {
+			logger.FromContext(ctx).Warn("Failed to invalidate vehicles by status cache", "error", err)
+		}
+	}
+	for driver := range drivers {
+		driverCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_driver_%s", driver)
+		if err := s.cacheManager.Delete(driverCacheKey); err != nil {
+			logger.FromContext(ctx).Warn("Failed to invalidate vehicles by driver cache", "error", err)
+		}
+	}
+	for tag := range tags {
+		s.invalidateTagLists(ctx, []string{tag})
 	}
 }
 
 // invalidateCacheOnUpdate invalidates relevant cache entries when a vehicle is updated
-func (s *VehicleService) invalidateCacheOnUpdate(vehicle *models.Vehicle, previousDriver, previousStatus string) {
+func (s *VehicleService) invalidateCacheOnUpdate(ctx context.Context, vehicle *models.Vehicle, previousDriver, previousStatus string) {
 	vehicleID := vehicle.ID.Hex()
 
 	// Invalidate the specific vehicle cache
 	if err := s.cacheManager.InvalidateVehicle(vehicleID); err != nil {
-		fmt.Printf("Failed to invalidate vehicle cache for %s: %v\n", vehicleID, err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicle cache", "vehicle_id", vehicleID, "error", err)
 	}
 
 	// Invalidate all vehicles list
 	if err := s.cacheManager.Delete("fleet:vehicle_list:all_vehicles"); err != nil {
-		fmt.Printf("Failed to invalidate all vehicles cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate all vehicles cache", "error", err)
 	}
 
 	// Invalidate current status cache
 	statusCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_status_%s", vehicle.Status)
 	if err := s.cacheManager.Delete(statusCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by status cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by status cache", "error", err)
 	}
 
 	// Invalidate previous status cache if status changed
 	if previousStatus != vehicle.Status {
 		prevStatusCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_status_%s", previousStatus)
 		if err := s.cacheManager.Delete(prevStatusCacheKey); err != nil {
-			fmt.Printf("Failed to invalidate previous vehicles by status cache: %v\n", err)
+			logger.FromContext(ctx).Warn("Failed to invalidate previous vehicles by status cache", "status", previousStatus, "error", err)
 		}
 	}
 
 	// Invalidate current driver cache
 	driverCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_driver_%s", vehicle.Driver)
 	if err := s.cacheManager.Delete(driverCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by driver cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by driver cache", "error", err)
 	}
 
 	// Invalidate previous driver cache if driver changed
 	if previousDriver != vehicle.Driver {
 		prevDriverCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_driver_%s", previousDriver)
 		if err := s.cacheManager.Delete(prevDriverCacheKey); err != nil {
-			fmt.Printf("Failed to invalidate previous vehicles by driver cache: %v\n", err)
+			logger.FromContext(ctx).Warn("Failed to invalidate previous vehicles by driver cache", "driver", previousDriver, "error", err)
 		}
 	}
 
+	// Invalidate the lists of the vehicle's tags
+	s.invalidateTagLists(ctx, vehicle.Tags)
+
 	// Cache the updated vehicle
-	ttl := s.cacheConfig.GetTTLForDataType("vehicle")
+	ttl := s.cacheTTL("vehicle")
 	if err := s.cacheManager.SetVehicle(vehicleID, vehicle, ttl); err != nil {
-		fmt.Printf("Failed to cache updated vehicle %s: %v\n", vehicleID, err)
+		logger.FromContext(ctx).Warn("Failed to cache updated vehicle", "vehicle_id", vehicleID, "error", err)
 	}
 }
 
 // invalidateCacheOnDelete invalidates relevant cache entries when a vehicle is deleted
-func (s *VehicleService) invalidateCacheOnDelete(vehicle *models.Vehicle) {
+func (s *VehicleService) invalidateCacheOnDelete(ctx context.Context, vehicle *models.Vehicle) {
 	vehicleID := vehicle.ID.Hex()
 
 	// Invalidate the specific vehicle cache
 	if err := s.cacheManager.InvalidateVehicle(vehicleID); err != nil {
-		fmt.Printf("Failed to invalidate vehicle cache for %s: %v\n", vehicleID, err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicle cache", "vehicle_id", vehicleID, "error", err)
 	}
 
 	// Invalidate all vehicles list
 	if err := s.cacheManager.Delete("fleet:vehicle_list:all_vehicles"); err != nil {
-		fmt.Printf("Failed to invalidate all vehicles cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate all vehicles cache", "error", err)
 	}
 
 	// Invalidate vehicles by status list
 	statusCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_status_%s", vehicle.Status)
 	if err := s.cacheManager.Delete(statusCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by status cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by status cache", "error", err)
 	}
 
 	// Invalidate vehicles by driver list
 	driverCacheKey := fmt.Sprintf("fleet:vehicle_list:vehicles_by_driver_%s", vehicle.Driver)
 	if err := s.cacheManager.Delete(driverCacheKey); err != nil {
-		fmt.Printf("Failed to invalidate vehicles by driver cache: %v\n", err)
+		logger.FromContext(ctx).Warn("Failed to invalidate vehicles by driver cache", "error", err)
 	}
+
+	// Invalidate the lists of the vehicle's tags
+	s.invalidateTagLists(ctx, vehicle.Tags)
 }
\ No newline at end of file
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeArchiveStore keeps vehicles in memory with the repository's archive semantics
+type fakeArchiveStore struct {
+	vehicles map[string]*models.Vehicle
+}
+
+func newFakeArchiveStore(vehicles ...*models.Vehicle) *fakeArchiveStore {
+	store := &fakeArchiveStore{vehicles: make(map[string]*models.Vehicle)}
+	for _, vehicle := range vehicles {
+		store.vehicles[vehicle.ID.Hex()] = vehicle
+	}
+	return store
+}
+
+func (f *fakeArchiveStore) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	vehicle, ok := f.vehicles[id]
+	if !ok {
+		return nil, errors.New("vehicle not found")
+	}
+	copied := *vehicle
+	return &copied, nil
+}
+
+func (f *fakeArchiveStore) FindAll() []*models.Vehicle {
+	var vehicles []*models.Vehicle
+	for _, vehicle := range f.vehicles {
+		if !vehicle.IsArchived() {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	return vehicles
+}
+
+func (f *fakeArchiveStore) Archive(_ context.Context, id string) error {
+	vehicle, ok := f.vehicles[id]
+	if !ok || vehicle.IsArchived() {
+		return errors.New("vehicle not found")
+	}
+	now := time.Now()
+	vehicle.DeletedAt = &now
+	return nil
+}
+
+func (f *fakeArchiveStore) Restore(_ context.Context, id string) error {
+	vehicle, ok := f.vehicles[id]
+	if !ok || !vehicle.IsArchived() {
+		return errors.New("archived vehicle not found")
+	}
+	vehicle.DeletedAt = nil
+	return nil
+}
+
+func (f *fakeArchiveStore) Delete(_ context.Context, id string) error {
+	if _, ok := f.vehicles[id]; !ok {
+		return errors.New("vehicle not found")
+	}
+	delete(f.vehicles, id)
+	return nil
+}
+
+func newArchiveTestVehicle() *models.Vehicle {
+	return &models.Vehicle{
+		ID:          primitive.NewObjectID(),
+		Name:        "Truck 1",
+		PlateNumber: "ABC123",
+		Driver:      "John Doe",
+		Status:      "active",
+	}
+}
+
+func TestVehicleService_ArchiveAndRestore(t *testing.T) {
+	service := &VehicleService{}
+	vehicle := newArchiveTestVehicle()
+	other := newArchiveTestVehicle()
+	store := newFakeArchiveStore(vehicle, other)
+	id := vehicle.ID.Hex()
+
+	require.NoError(t, service.archiveVehicle(context.Background(), store, id))
+
+	assert.NotContains(t, store.FindAll(), vehicle, "archived vehicle should be excluded from FindAll")
+	assert.Len(t, store.FindAll(), 1)
+
+	archived, err := store.FindByID(context.Background(), id)
+	require.NoError(t, err, "archived vehicle should still resolve by ID")
+	assert.True(t, archived.IsArchived())
+
+	assert.Error(t, service.archiveVehicle(context.Background(), store, id), "archiving twice should fail")
+
+	restored, err := service.restoreVehicle(context.Background(), store, id)
+	require.NoError(t, err)
+	assert.False(t, restored.IsArchived())
+	assert.Contains(t, store.FindAll(), vehicle)
+
+	_, err = service.restoreVehicle(context.Background(), store, id)
+	assert.Error(t, err, "restoring an active vehicle should fail")
+}
+
+func TestVehicleService_HardDeleteVehicle(t *testing.T) {
+	service := &VehicleService{}
+	vehicle := newArchiveTestVehicle()
+	store := newFakeArchiveStore(vehicle)
+
+	require.NoError(t, service.hardDeleteVehicle(context.Background(), store, vehicle.ID.Hex()))
+
+	_, err := store.FindByID(context.Background(), vehicle.ID.Hex())
+	assert.Error(t, err)
+	_, err = service.restoreVehicle(context.Background(), store, vehicle.ID.Hex())
+	assert.Error(t, err, "hard-deleted vehicles cannot be restored")
+}
+
+func TestVehicleService_ArchiveInvalidatesCache(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: cache.DefaultCacheConfig()}
+	vehicle := newArchiveTestVehicle()
+	store := newFakeArchiveStore(vehicle)
+	id := vehicle.ID.Hex()
+
+	mockCache.On("InvalidateVehicle", id).Return(nil).Twice()
+	mockCache.On("Delete", "fleet:vehicle_list:all_vehicles").Return(nil).Twice()
+	mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_status_active").Return(nil).Twice()
+	mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_John Doe").Return(nil).Twice()
+
+	require.NoError(t, service.archiveVehicle(context.Background(), store, id))
+	_, err := service.restoreVehicle(context.Background(), store, id)
+	require.NoError(t, err)
+
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "SetVehicle", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeBatchFinder serves vehicles from memory and records each query
+type fakeBatchFinder struct {
+	vehicles map[string]*models.Vehicle
+	queries  [][]string
+}
+
+func (f *fakeBatchFinder) FindByIDs(_ context.Context, ids []string) ([]*models.Vehicle, error) {
+	f.queries = append(f.queries, ids)
+	var vehicles []*models.Vehicle
+	for _, id := range ids {
+		if vehicle, ok := f.vehicles[id]; ok {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	return vehicles, nil
+}
+
+func TestVehicleService_GetVehiclesByIDs_MixedSources(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	config := cache.DefaultCacheConfig()
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: config}
+
+	cached := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Cached"}
+	uncachedA := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Uncached A"}
+	uncachedB := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Uncached B"}
+	unknownID := primitive.NewObjectID().Hex()
+
+	finder := &fakeBatchFinder{vehicles: map[string]*models.Vehicle{
+		cached.ID.Hex():    cached,
+		uncachedA.ID.Hex(): uncachedA,
+		uncachedB.ID.Hex(): uncachedB,
+	}}
+
+	// Duplicates are dropped before the single bulk cache lookup
+	uniqueIDs := []string{uncachedB.ID.Hex(), cached.ID.Hex(), unknownID, uncachedA.ID.Hex(), "not-an-id"}
+	mockCache.On("GetVehicles", uniqueIDs).Return(map[string]*models.Vehicle{cached.ID.Hex(): cached}, nil).Once()
+	mockCache.On("SetVehicle", uncachedA.ID.Hex(), uncachedA, config.VehicleDataTTL).Return(nil).Once()
+	mockCache.On("SetVehicle", uncachedB.ID.Hex(), uncachedB, config.VehicleDataTTL).Return(nil).Once()
+
+	ids := []string{uncachedB.ID.Hex(), cached.ID.Hex(), unknownID, uncachedA.ID.Hex(), "not-an-id", cached.ID.Hex()}
+	result, err := service.getVehiclesByIDs(context.Background(), finder, ids)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.Vehicle{uncachedB, cached, uncachedA}, result.Vehicles, "vehicles should follow request order")
+	assert.Equal(t, []string{unknownID, "not-an-id"}, result.Missing)
+
+	require.Len(t, finder.queries, 1, "cache misses should be fetched in a single query")
+	assert.Equal(t, []string{uncachedB.ID.Hex(), unknownID, uncachedA.ID.Hex(), "not-an-id"}, finder.queries[0])
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestVehicleService_GetVehiclesByIDs_AllCached(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: cache.DefaultCacheConfig()}
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+	finder := &fakeBatchFinder{}
+	mockCache.On("GetVehicles", []string{vehicle.ID.Hex()}).Return(map[string]*models.Vehicle{vehicle.ID.Hex(): vehicle}, nil)
+
+	result, err := service.getVehiclesByIDs(context.Background(), finder, []string{vehicle.ID.Hex()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.Vehicle{vehicle}, result.Vehicles)
+	assert.Empty(t, result.Missing)
+	assert.Empty(t, finder.queries, "no database query when every vehicle is cached")
+}
+
+func TestVehicleService_GetVehiclesByIDs_CacheErrorFallsBackToDatabase(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	config := cache.DefaultCacheConfig()
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: config}
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+	finder := &fakeBatchFinder{vehicles: map[string]*models.Vehicle{vehicle.ID.Hex(): vehicle}}
+	mockCache.On("GetVehicles", []string{vehicle.ID.Hex()}).Return(nil, errors.New("redis unavailable"))
+	mockCache.On("SetVehicle", vehicle.ID.Hex(), vehicle, config.VehicleDataTTL).Return(nil)
+
+	result, err := service.getVehiclesByIDs(context.Background(), finder, []string{vehicle.ID.Hex()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.Vehicle{vehicle}, result.Vehicles)
+	require.Len(t, finder.queries, 1)
+}
+
+func TestVehicleService_GetVehiclesByIDs_TooMany(t *testing.T) {
+	service := &VehicleService{}
+	ids := make([]string, MaxBatchGetVehicles+1)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID().Hex()
+	}
+
+	_, err := service.getVehiclesByIDs(context.Background(), &fakeBatchFinder{}, ids)
+	assert.Error(t, err)
+}
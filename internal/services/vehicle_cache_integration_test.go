@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fleet-backend/internal/models"
 	"fleet-backend/pkg/cache"
 	"testing"
@@ -24,6 +25,14 @@ func (m *MockCacheManager) GetVehicle(vehicleID string) (*models.Vehicle, error)
 	return args.Get(0).(*models.Vehicle), args.Error(1)
 }
 
+func (m *MockCacheManager) GetVehicles(vehicleIDs []string) (map[string]*models.Vehicle, error) {
+	args := m.Called(vehicleIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*models.Vehicle), args.Error(1)
+}
+
 func (m *MockCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error {
 	args := m.Called(vehicleID, vehicle, ttl)
 	return args.Error(0)
@@ -113,7 +122,7 @@ func TestVehicleService_GetVehicleByID_CacheHit(t *testing.T) {
 	// Mock cache hit
 	mockCache.On("GetVehicle", vehicleID).Return(testVehicle, nil)
 
-	result, err := service.GetVehicleByID(vehicleID)
+	result, err := service.GetVehicleByID(context.Background(), vehicleID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, testVehicle, result)
@@ -148,7 +157,7 @@ func TestVehicleService_GetAllVehicles_CacheHit(t *testing.T) {
 	// Mock cache hit
 	mockCache.On("GetVehicleList", "all_vehicles").Return(testVehicles, nil)
 
-	result, err := service.GetAllVehicles()
+	result, err := service.GetAllVehicles(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, testVehicles, result)
@@ -178,7 +187,7 @@ func TestVehicleService_CacheInvalidationHelpers(t *testing.T) {
 		mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_John Doe").Return(nil)
 		mockCache.On("SetVehicle", testVehicle.ID.Hex(), testVehicle, service.cacheConfig.VehicleDataTTL).Return(nil)
 
-		service.invalidateCacheOnCreate(testVehicle)
+		service.invalidateCacheOnCreate(context.Background(), testVehicle)
 
 		mockCache.AssertExpectations(t)
 	})
@@ -197,7 +206,7 @@ func TestVehicleService_CacheInvalidationHelpers(t *testing.T) {
 		mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_Old Driver").Return(nil) // previous driver
 		mockCache.On("SetVehicle", vehicleID, testVehicle, service.cacheConfig.VehicleDataTTL).Return(nil)
 
-		service.invalidateCacheOnUpdate(testVehicle, previousDriver, previousStatus)
+		service.invalidateCacheOnUpdate(context.Background(), testVehicle, previousDriver, previousStatus)
 
 		mockCache.AssertExpectations(t)
 	})
@@ -211,7 +220,7 @@ func TestVehicleService_CacheInvalidationHelpers(t *testing.T) {
 		mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_status_active").Return(nil)
 		mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_John Doe").Return(nil)
 
-		service.invalidateCacheOnDelete(testVehicle)
+		service.invalidateCacheOnDelete(context.Background(), testVehicle)
 
 		mockCache.AssertExpectations(t)
 	})
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/utils"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// DefaultImportWorkers is the number of vehicles created concurrently during an import
+	DefaultImportWorkers = 8
+	// MaxImportRows caps the number of data rows accepted in a single import
+	MaxImportRows = 5000
+)
+
+// vehicleImportColumns maps accepted CSV header names to request fields
+var vehicleImportColumns = map[string]string{
+	"name":            "name",
+	"plate":           "plate",
+	"platenumber":     "plate",
+	"driver":          "driver",
+	"make":            "make",
+	"model":           "model",
+	"year":            "year",
+	"vin":             "vin",
+	"maxfuelcapacity": "maxFuelCapacity",
+	"fuelconsumption": "fuelConsumption",
+}
+
+// requiredImportColumns must be present in the CSV header
+var requiredImportColumns = []string{"name", "plate", "driver", "maxFuelCapacity", "fuelConsumption"}
+
+// VehicleImportRowResult describes the outcome of importing a single CSV row
+type VehicleImportRowResult struct {
+	Row         int      `json:"row"`
+	PlateNumber string   `json:"plateNumber,omitempty"`
+	Success     bool     `json:"success"`
+	VehicleID   string   `json:"vehicleId,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// VehicleImportReport summarizes a CSV vehicle import
+type VehicleImportReport struct {
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Results []VehicleImportRowResult `json:"results"`
+}
+
+// vehicleImportStore is the subset of the vehicle repository used by imports
+type vehicleImportStore interface {
+	FindByPlateNumber(ctx context.Context, plateNumber string) (*models.Vehicle, error)
+	Create(ctx context.Context, vehicle *models.Vehicle) (*models.Vehicle, error)
+}
+
+// importRow is a parsed CSV row awaiting creation
+type importRow struct {
+	index int // position in the report
+	req   *CreateVehicleRequest
+}
+
+// ImportVehiclesCSV creates vehicles from a CSV document. Each row is validated
+// independently; valid rows are created and invalid or duplicate rows are
+// reported without aborting the import. An error is only returned when the
+// document itself cannot be read.
+func (s *VehicleService) ImportVehiclesCSV(ctx context.Context, r io.Reader) (*VehicleImportReport, error) {
+	report, created, err := importVehicles(ctx, s.vehicleRepo, r, DefaultImportWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invalidate list caches once for the whole import
+	if s.cacheManager != nil && len(created) > 0 {
+		s.invalidateCacheOnImport(ctx, created)
+	}
+
+	return report, nil
+}
+
+// importVehicles parses, validates and creates the vehicles described by a CSV
+// document using a bounded pool of workers
+func importVehicles(ctx context.Context, store vehicleImportStore, r io.Reader, workers int) (*VehicleImportReport, []*models.Vehicle, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // short rows are reported per row instead of failing the import
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns, err := parseImportHeader(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validate := validator.New()
+	report := &VehicleImportReport{Results: []VehicleImportRowResult{}}
+	var pending []importRow
+	seenPlates := make(map[string]int)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if report.Total >= MaxImportRows {
+			return nil, nil, fmt.Errorf("CSV file exceeds the maximum of %d rows", MaxImportRows)
+		}
+		report.Total++
+
+		// Rows are reported by their line in the file so they can be found in a spreadsheet
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Results = append(report.Results, VehicleImportRowResult{
+				Row:    parseErr.StartLine,
+				Errors: []string{fmt.Sprintf("malformed row: %v", parseErr.Err)},
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		result := VehicleImportRowResult{Row: line}
+
+		req, rowErrors := parseImportRecord(record, columns)
+		result.PlateNumber = req.PlateNumber
+		if len(rowErrors) == 0 {
+			if err := validate.Struct(req); err != nil {
+				rowErrors = utils.ValidationErrorMessages(err)
+			}
+		}
+		if len(rowErrors) == 0 {
+			if firstRow, exists := seenPlates[req.PlateNumber]; exists {
+				rowErrors = []string{fmt.Sprintf("duplicate plate number in file (first seen on row %d)", firstRow)}
+			} else {
+				seenPlates[req.PlateNumber] = line
+			}
+		}
+
+		result.Errors = rowErrors
+		report.Results = append(report.Results, result)
+		if len(rowErrors) == 0 {
+			pending = append(pending, importRow{index: len(report.Results) - 1, req: req})
+		}
+	}
+
+	created := createImportedVehicles(ctx, store, pending, report, workers)
+
+	for _, result := range report.Results {
+		if result.Success {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+
+	return report, created, nil
+}
+
+// createImportedVehicles creates the validated rows concurrently and records
+// each outcome in the report
+func createImportedVehicles(ctx context.Context, store vehicleImportStore, rows []importRow, report *VehicleImportReport, workers int) []*models.Vehicle {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	jobs := make(chan importRow)
+	var created []*models.Vehicle
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				vehicle, err := createImportedVehicle(ctx, store, row.req)
+
+				// Each row owns its own slot in the report, only the created list is shared
+				result := &report.Results[row.index]
+				if err != nil {
+					result.Errors = []string{err.Error()}
+					continue
+				}
+				result.Success = true
+				result.VehicleID = vehicle.ID.Hex()
+
+				mu.Lock()
+				created = append(created, vehicle)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, row := range rows {
+		jobs <- row
+	}
+	close(jobs)
+	wg.Wait()
+
+	return created
+}
+
+// createImportedVehicle creates a single vehicle, rejecting plates that already exist
+func createImportedVehicle(ctx context.Context, store vehicleImportStore, req *CreateVehicleRequest) (*models.Vehicle, error) {
+	existingVehicle, _ := store.FindByPlateNumber(ctx, req.PlateNumber)
+	if existingVehicle != nil {
+		return nil, ErrDuplicatePlate
+	}
+
+	return store.Create(ctx, newVehicleFromRequest(req))
+}
+
+// parseImportHeader maps request fields to their column index
+func parseImportHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		key = strings.NewReplacer("_", "", " ", "", "-", "").Replace(key)
+		if field, ok := vehicleImportColumns[key]; ok {
+			columns[field] = i
+		}
+	}
+
+	var missing []string
+	for _, field := range requiredImportColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("CSV header is missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// parseImportRecord converts a CSV record into a create request
+func parseImportRecord(record []string, columns map[string]int) (*CreateVehicleRequest, []string) {
+	value := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rowErrors []string
+	parseFloat := func(field string) float64 {
+		raw := value(field)
+		if raw == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s must be a number", field))
+		}
+		return parsed
+	}
+
+	req := &CreateVehicleRequest{
+		Name:            value("name"),
+		PlateNumber:     value("plate"),
+		Driver:          value("driver"),
+		Make:            value("make"),
+		Model:           value("model"),
+		VIN:             value("vin"),
+		MaxFuelCapacity: parseFloat("maxFuelCapacity"),
+		FuelConsumption: parseFloat("fuelConsumption"),
+	}
+
+	if raw := value("year"); raw != "" {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			rowErrors = append(rowErrors, "year must be a whole number")
+		}
+		req.Year = year
+	}
+
+	return req, rowErrors
+}
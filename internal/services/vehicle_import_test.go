@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVehicleImportStore is an in-memory vehicle store keyed by plate number
+type fakeVehicleImportStore struct {
+	mu       sync.Mutex
+	vehicles map[string]*models.Vehicle
+	failOn   string
+}
+
+func newFakeVehicleImportStore(existingPlates ...string) *fakeVehicleImportStore {
+	store := &fakeVehicleImportStore{vehicles: make(map[string]*models.Vehicle)}
+	for _, plate := range existingPlates {
+		store.vehicles[plate] = &models.Vehicle{PlateNumber: plate}
+	}
+	return store
+}
+
+func (f *fakeVehicleImportStore) FindByPlateNumber(_ context.Context, plateNumber string) (*models.Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if vehicle, exists := f.vehicles[plateNumber]; exists {
+		return vehicle, nil
+	}
+	return nil, errors.New("vehicle not found")
+}
+
+func (f *fakeVehicleImportStore) Create(_ context.Context, vehicle *models.Vehicle) (*models.Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if vehicle.PlateNumber == f.failOn {
+		return nil, errors.New("database unavailable")
+	}
+	f.vehicles[vehicle.PlateNumber] = vehicle
+	return vehicle, nil
+}
+
+const importHeader = "name,plate,driver,make,model,year,vin,maxFuelCapacity,fuelConsumption\n"
+
+func TestImportVehicles_PartialSuccess(t *testing.T) {
+	store := newFakeVehicleImportStore("EXIST-1")
+	csvData := importHeader +
+		"Truck 1,KAA-001,Alice,Volvo,FH16,2020,VIN1,400,30\n" +
+		"Truck 2,EXIST-1,Bob,Volvo,FH16,2021,VIN2,400,30\n" + // already in the store
+		"Truck 3,KAA-003,Carol,Scania,R500,2019,VIN3,350,28\n" +
+		"Truck 4,KAA-001,Dave,MAN,TGX,2022,VIN4,380,29\n" + // duplicate within file
+		"Truck 5,KAA-005,,MAN,TGX,2022,VIN5,380,29\n" + // missing driver
+		"Truck 6,KAA-006,Eve,MAN,TGX,abc,VIN6,380,29\n" // invalid year
+
+	report, created, err := importVehicles(context.Background(), store, strings.NewReader(csvData), 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 4, report.Failed)
+	assert.Len(t, created, 2)
+	require.Len(t, report.Results, 6)
+
+	// Rows are reported in file order with their line numbers
+	for i, result := range report.Results {
+		assert.Equal(t, i+2, result.Row)
+	}
+
+	assert.True(t, report.Results[0].Success)
+	assert.NotEmpty(t, report.Results[0].VehicleID)
+
+	assert.False(t, report.Results[1].Success)
+	assert.Equal(t, []string{"plate number already exists"}, report.Results[1].Errors)
+
+	assert.True(t, report.Results[2].Success)
+
+	assert.False(t, report.Results[3].Success)
+	assert.Contains(t, report.Results[3].Errors[0], "duplicate plate number in file")
+
+	assert.False(t, report.Results[4].Success)
+	assert.Contains(t, report.Results[4].Errors, "Driver is required")
+
+	assert.False(t, report.Results[5].Success)
+	assert.Contains(t, report.Results[5].Errors, "year must be a whole number")
+
+	// Only valid rows reached the store
+	_, err = store.FindByPlateNumber(context.Background(), "KAA-003")
+	assert.NoError(t, err)
+	_, err = store.FindByPlateNumber(context.Background(), "KAA-005")
+	assert.Error(t, err)
+}
+
+func TestImportVehicles_CreateFailureReportedPerRow(t *testing.T) {
+	store := newFakeVehicleImportStore()
+	store.failOn = "KAA-002"
+	csvData := importHeader +
+		"Truck 1,KAA-001,Alice,,,,,400,30\n" +
+		"Truck 2,KAA-002,Bob,,,,,400,30\n"
+
+	report, created, err := importVehicles(context.Background(), store, strings.NewReader(csvData), DefaultImportWorkers)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, created, 1)
+	assert.Equal(t, []string{"database unavailable"}, report.Results[1].Errors)
+}
+
+func TestImportVehicles_InvalidHeader(t *testing.T) {
+	store := newFakeVehicleImportStore()
+
+	_, _, err := importVehicles(context.Background(), store, strings.NewReader("name,driver\nTruck,Alice\n"), 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plate")
+	assert.Contains(t, err.Error(), "maxFuelCapacity")
+
+	_, _, err = importVehicles(context.Background(), store, strings.NewReader(""), 1)
+	assert.EqualError(t, err, "CSV file is empty")
+}
+
+func TestImportVehicles_HeaderAliases(t *testing.T) {
+	store := newFakeVehicleImportStore()
+	csvData := "\ufeffName,Plate Number,Driver,max_fuel_capacity,fuel-consumption\n" +
+		"Van 1,KBB-001,Frank,80,9.5\n"
+
+	report, created, err := importVehicles(context.Background(), store, strings.NewReader(csvData), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Created)
+	require.Len(t, created, 1)
+	assert.Equal(t, "KBB-001", created[0].PlateNumber)
+	assert.Equal(t, 80.0, created[0].MaxFuelCapacity)
+	assert.Equal(t, 9.5, created[0].FuelConsumption)
+}
+
+func TestVehicleService_InvalidateCacheOnImport(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	service := &VehicleService{cacheManager: mockCache}
+
+	mockCache.On("Delete", "fleet:vehicle_list:all_vehicles").Return(nil).Once()
+	mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_status_idle").Return(nil).Once()
+	mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_Alice").Return(nil).Once()
+	mockCache.On("Delete", "fleet:vehicle_list:vehicles_by_driver_Bob").Return(nil).Once()
+
+	service.invalidateCacheOnImport(context.Background(), []*models.Vehicle{
+		{Status: "idle", Driver: "Alice"},
+		{Status: "idle", Driver: "Alice"},
+		{Status: "idle", Driver: "Bob"},
+	})
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestCreateImportedVehicle_DuplicatePlate(t *testing.T) {
+	store := newFakeVehicleImportStore("KAA-001")
+
+	_, err := createImportedVehicle(context.Background(), store, &CreateVehicleRequest{Name: "Truck", PlateNumber: "KAA-001"})
+
+	assert.ErrorIs(t, err, ErrDuplicatePlate)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fleetSource serves a fleet that the test can change between loads
+type fleetSource struct {
+	mu       sync.Mutex
+	vehicles []*models.Vehicle
+	loads    int
+}
+
+func (f *fleetSource) FindAll(_ context.Context) ([]*models.Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loads++
+	return f.vehicles, nil
+}
+
+func (f *fleetSource) add(vehicle *models.Vehicle) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vehicles = append(f.vehicles, vehicle)
+}
+
+func (f *fleetSource) loadCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.loads
+}
+
+func TestGetAllVehicles_StaleWhileRevalidate(t *testing.T) {
+	config := cache.DefaultCacheConfig()
+	config.VehicleListSoftTTL = 50 * time.Millisecond
+	service := &VehicleService{cacheConfig: config}
+	service.SetCacheManager(cache.NewMemoryCacheManager(config, 100))
+
+	source := &fleetSource{vehicles: []*models.Vehicle{{ID: primitive.NewObjectID(), Name: "Truck 1"}}}
+	vehicles, err := service.getAllVehicles(context.Background(), source)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+
+	source.add(&models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck 2"})
+	time.Sleep(60 * time.Millisecond)
+
+	// Just past the soft TTL the cached list comes back while it is refreshed
+	vehicles, err = service.getAllVehicles(context.Background(), source)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+
+	assert.Eventually(t, func() bool {
+		vehicles, err := service.getAllVehicles(context.Background(), source)
+		return err == nil && len(vehicles) == 2
+	}, 100*time.Millisecond, 5*time.Millisecond)
+	assert.Equal(t, 2, source.loadCount())
+}
+
+func TestGetAllVehicles_SoftTTLDisabledByDefault(t *testing.T) {
+	config := cache.DefaultCacheConfig()
+	service := &VehicleService{cacheConfig: config}
+	service.SetCacheManager(cache.NewMemoryCacheManager(config, 100))
+
+	source := &fleetSource{vehicles: []*models.Vehicle{{ID: primitive.NewObjectID(), Name: "Truck 1"}}}
+	_, err := service.getAllVehicles(context.Background(), source)
+	require.NoError(t, err)
+	_, err = service.getAllVehicles(context.Background(), source)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, source.loadCount())
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Limits on the custom metadata a vehicle can carry
+const (
+	MaxMetadataKeys        = 32
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 256
+)
+
+// ErrInvalidMetadata is returned when metadata or a metadata filter breaks the limits
+var ErrInvalidMetadata = newDomainError(ErrValidation, "invalid metadata")
+
+// metadataKeyPattern restricts keys to characters that are safe in a document
+// field path and a query parameter name
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ReplaceMetadataRequest replaces all of a vehicle's metadata; an empty object clears it
+type ReplaceMetadataRequest struct {
+	Metadata map[string]string `json:"metadata" validate:"required"`
+}
+
+// PatchMetadataRequest sets the given metadata keys; a null value removes the key
+type PatchMetadataRequest struct {
+	Metadata map[string]*string `json:"metadata" validate:"required"`
+}
+
+// metadataStore is the subset of the vehicle repository used to store metadata
+type metadataStore interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+	ReplaceMetadata(ctx context.Context, id string, metadata map[string]string) error
+	PatchMetadata(ctx context.Context, id string, set map[string]string, remove []string) error
+}
+
+// metadataFinder is the subset of the vehicle repository used by metadata filters
+type metadataFinder interface {
+	FindByMetadata(ctx context.Context, metadata map[string]string, statuses []string) ([]*models.Vehicle, error)
+}
+
+// ReplaceMetadata replaces all of the vehicle's metadata
+func (s *VehicleService) ReplaceMetadata(ctx context.Context, id string, req *ReplaceMetadataRequest) (*models.Vehicle, error) {
+	return s.replaceMetadata(ctx, s.vehicleRepo, id, req.Metadata)
+}
+
+// PatchMetadata sets and removes individual metadata keys of the vehicle
+func (s *VehicleService) PatchMetadata(ctx context.Context, id string, req *PatchMetadataRequest) (*models.Vehicle, error) {
+	return s.patchMetadata(ctx, s.vehicleRepo, id, req.Metadata)
+}
+
+// ClearMetadata removes all of the vehicle's metadata
+func (s *VehicleService) ClearMetadata(ctx context.Context, id string) (*models.Vehicle, error) {
+	return s.replaceMetadata(ctx, s.vehicleRepo, id, nil)
+}
+
+func (s *VehicleService) replaceMetadata(ctx context.Context, store metadataStore, id string, metadata map[string]string) (*models.Vehicle, error) {
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	vehicle, err := store.FindByID(ctx, id)
+	if err != nil {
+		return nil, vehicleLookupError(err)
+	}
+
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	if err := store.ReplaceMetadata(ctx, id, metadata); err != nil {
+		return nil, err
+	}
+	vehicle.Metadata = metadata
+
+	if s.cacheManager != nil {
+		s.invalidateCacheOnUpdate(ctx, vehicle, vehicle.Driver, vehicle.Status)
+	}
+	return vehicle, nil
+}
+
+func (s *VehicleService) patchMetadata(ctx context.Context, store metadataStore, id string, patch map[string]*string) (*models.Vehicle, error) {
+	set := make(map[string]string, len(patch))
+	var remove []string
+	for key, value := range patch {
+		if value == nil {
+			remove = append(remove, key)
+		} else {
+			set[key] = *value
+		}
+	}
+	if err := validateMetadata(set); err != nil {
+		return nil, err
+	}
+	for _, key := range remove {
+		if err := validateMetadataKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	vehicle, err := store.FindByID(ctx, id)
+	if err != nil {
+		return nil, vehicleLookupError(err)
+	}
+
+	// The key limit applies to the metadata the vehicle ends up with
+	merged := make(map[string]string, len(vehicle.Metadata)+len(set))
+	for key, value := range vehicle.Metadata {
+		merged[key] = value
+	}
+	for key, value := range set {
+		merged[key] = value
+	}
+	for _, key := range remove {
+		delete(merged, key)
+	}
+	if len(merged) > MaxMetadataKeys {
+		return nil, fmt.Errorf("%w: at most %d keys are allowed", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+
+	if err := store.PatchMetadata(ctx, id, set, remove); err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		merged = nil
+	}
+	vehicle.Metadata = merged
+
+	if s.cacheManager != nil {
+		s.invalidateCacheOnUpdate(ctx, vehicle, vehicle.Driver, vehicle.Status)
+	}
+	return vehicle, nil
+}
+
+// GetVehiclesByMetadata returns vehicles whose metadata matches every key and
+// value in the filter, optionally narrowed to one or more statuses. Results
+// aren't cached since metadata filters are arbitrary.
+func (s *VehicleService) GetVehiclesByMetadata(ctx context.Context, metadata map[string]string, statuses []string) ([]*models.Vehicle, error) {
+	return s.getVehiclesByMetadata(ctx, s.vehicleRepo, metadata, statuses)
+}
+
+func (s *VehicleService) getVehiclesByMetadata(ctx context.Context, finder metadataFinder, metadata map[string]string, statuses []string) ([]*models.Vehicle, error) {
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("%w: at least one metadata filter is required", ErrInvalidMetadata)
+	}
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	if len(statuses) > 0 {
+		normalized, err := normalizeStatuses(statuses)
+		if err != nil {
+			return nil, err
+		}
+		statuses = normalized
+	}
+
+	return finder.FindByMetadata(ctx, metadata, statuses)
+}
+
+// validateMetadata checks the number of keys and the length and characters of
+// each key and value
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys are allowed", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+
+	// Sorted so the reported key doesn't depend on map order
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := validateMetadataKey(key); err != nil {
+			return err
+		}
+		value := metadata[key]
+		if value == "" {
+			return fmt.Errorf("%w: value of %q is empty", ErrInvalidMetadata, key)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return fmt.Errorf("%w: value of %q is longer than %d characters", ErrInvalidMetadata, key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+func validateMetadataKey(key string) error {
+	if len(key) > MaxMetadataKeyLength {
+		return fmt.Errorf("%w: key %q is longer than %d characters", ErrInvalidMetadata, key, MaxMetadataKeyLength)
+	}
+	if !metadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key %q may only contain letters, digits, '_' and '-'", ErrInvalidMetadata, key)
+	}
+	return nil
+}
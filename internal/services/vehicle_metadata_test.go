@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMetadataStore applies metadata writes and filters to an in-memory fleet
+// the way the repository's queries would
+type memoryMetadataStore struct {
+	vehicles map[string]*models.Vehicle
+	writes   int
+}
+
+func newMemoryMetadataStore(vehicles ...*models.Vehicle) *memoryMetadataStore {
+	store := &memoryMetadataStore{vehicles: make(map[string]*models.Vehicle)}
+	for _, vehicle := range vehicles {
+		store.vehicles[vehicle.PlateNumber] = vehicle
+	}
+	return store
+}
+
+func (m *memoryMetadataStore) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	vehicle, ok := m.vehicles[id]
+	if !ok {
+		return nil, errors.New("vehicle not found")
+	}
+	copied := *vehicle
+	copied.Metadata = make(map[string]string, len(vehicle.Metadata))
+	for key, value := range vehicle.Metadata {
+		copied.Metadata[key] = value
+	}
+	return &copied, nil
+}
+
+func (m *memoryMetadataStore) ReplaceMetadata(_ context.Context, id string, metadata map[string]string) error {
+	m.writes++
+	m.vehicles[id].Metadata = metadata
+	return nil
+}
+
+func (m *memoryMetadataStore) PatchMetadata(_ context.Context, id string, set map[string]string, remove []string) error {
+	m.writes++
+	vehicle := m.vehicles[id]
+	if vehicle.Metadata == nil {
+		vehicle.Metadata = make(map[string]string)
+	}
+	for key, value := range set {
+		vehicle.Metadata[key] = value
+	}
+	for _, key := range remove {
+		delete(vehicle.Metadata, key)
+	}
+	return nil
+}
+
+func (m *memoryMetadataStore) FindByMetadata(_ context.Context, metadata map[string]string, statuses []string) ([]*models.Vehicle, error) {
+	var matches []*models.Vehicle
+	for _, vehicle := range m.vehicles {
+		matched := len(statuses) == 0
+		for _, status := range statuses {
+			if vehicle.Status == status {
+				matched = true
+			}
+		}
+		for key, value := range metadata {
+			if vehicle.Metadata[key] != value {
+				matched = false
+			}
+		}
+		if matched {
+			matches = append(matches, vehicle)
+		}
+	}
+	return matches, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestVehicleService_ReplaceMetadata_SetsMetadata(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1"})
+
+	vehicle, err := service.replaceMetadata(context.Background(), store, "V-1", map[string]string{
+		"assetTag":   "AT-0042",
+		"costCenter": "ops",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"assetTag": "AT-0042", "costCenter": "ops"}, vehicle.Metadata)
+	assert.Equal(t, vehicle.Metadata, store.vehicles["V-1"].Metadata)
+}
+
+func TestVehicleService_ReplaceMetadata_DropsKeysNotGiven(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1", Metadata: map[string]string{"assetTag": "AT-0042"}})
+
+	vehicle, err := service.replaceMetadata(context.Background(), store, "V-1", map[string]string{"costCenter": "ops"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"costCenter": "ops"}, vehicle.Metadata)
+}
+
+func TestVehicleService_PatchMetadata_UpdatesAndRemovesKeys(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1", Metadata: map[string]string{
+		"assetTag":        "AT-0042",
+		"costCenter":      "ops",
+		"insurancePolicy": "POL-1",
+	}})
+
+	vehicle, err := service.patchMetadata(context.Background(), store, "V-1", map[string]*string{
+		"costCenter":      stringPtr("logistics"),
+		"insurancePolicy": nil,
+		"region":          stringPtr("north"),
+	})
+
+	require.NoError(t, err)
+	expected := map[string]string{"assetTag": "AT-0042", "costCenter": "logistics", "region": "north"}
+	assert.Equal(t, expected, vehicle.Metadata)
+	assert.Equal(t, expected, store.vehicles["V-1"].Metadata)
+}
+
+func TestVehicleService_PatchMetadata_KeyLimitCountsExistingKeys(t *testing.T) {
+	existing := make(map[string]string, MaxMetadataKeys)
+	for i := 0; i < MaxMetadataKeys; i++ {
+		existing[fmt.Sprintf("key%d", i)] = "value"
+	}
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1", Metadata: existing})
+
+	_, err := service.patchMetadata(context.Background(), store, "V-1", map[string]*string{"oneTooMany": stringPtr("value")})
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	// Replacing a key at the limit is still allowed
+	_, err = service.patchMetadata(context.Background(), store, "V-1", map[string]*string{"key0": stringPtr("updated")})
+	require.NoError(t, err)
+	assert.Equal(t, "updated", store.vehicles["V-1"].Metadata["key0"])
+}
+
+func TestVehicleService_ClearMetadata(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1", Metadata: map[string]string{"assetTag": "AT-0042"}})
+
+	vehicle, err := service.replaceMetadata(context.Background(), store, "V-1", nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, vehicle.Metadata)
+	assert.Nil(t, store.vehicles["V-1"].Metadata)
+}
+
+func TestVehicleService_PatchMetadata_RemovingLastKeyClears(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1", Metadata: map[string]string{"assetTag": "AT-0042"}})
+
+	vehicle, err := service.patchMetadata(context.Background(), store, "V-1", map[string]*string{"assetTag": nil})
+
+	require.NoError(t, err)
+	assert.Nil(t, vehicle.Metadata)
+}
+
+func TestVehicleService_Metadata_UnknownVehicle(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore()
+
+	_, err := service.replaceMetadata(context.Background(), store, "missing", map[string]string{"assetTag": "AT-0042"})
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidMetadata)
+	assert.Zero(t, store.writes)
+}
+
+func TestVehicleService_Metadata_RejectsInvalidMetadata(t *testing.T) {
+	tooMany := make(map[string]string, MaxMetadataKeys+1)
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+	}{
+		{"too many keys", tooMany},
+		{"key too long", map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "value"}},
+		{"value too long", map[string]string{"assetTag": strings.Repeat("v", MaxMetadataValueLength+1)}},
+		{"empty value", map[string]string{"assetTag": ""}},
+		{"empty key", map[string]string{"": "value"}},
+		{"dotted key", map[string]string{"cost.center": "ops"}},
+		{"operator key", map[string]string{"$where": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &VehicleService{}
+			store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1"})
+
+			_, err := service.replaceMetadata(context.Background(), store, "V-1", tt.metadata)
+
+			assert.ErrorIs(t, err, ErrInvalidMetadata)
+			assert.Zero(t, store.writes)
+		})
+	}
+}
+
+func TestVehicleService_PatchMetadata_RejectsInvalidRemovedKey(t *testing.T) {
+	service := &VehicleService{}
+	store := newMemoryMetadataStore(&models.Vehicle{PlateNumber: "V-1"})
+
+	_, err := service.patchMetadata(context.Background(), store, "V-1", map[string]*string{"cost.center": nil})
+
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	assert.Zero(t, store.writes)
+}
+
+func TestVehicleService_CreateVehicle_RejectsInvalidMetadata(t *testing.T) {
+	service := &VehicleService{}
+
+	_, err := service.CreateVehicle(context.Background(), &CreateVehicleRequest{
+		Name:        "Truck",
+		PlateNumber: "V-1",
+		Metadata:    map[string]string{"cost.center": "ops"},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+}
+
+func TestVehicleService_GetVehiclesByMetadata(t *testing.T) {
+	store := newMemoryMetadataStore(
+		&models.Vehicle{PlateNumber: "V-1", Status: "active", Metadata: map[string]string{"costCenter": "ops", "region": "north"}},
+		&models.Vehicle{PlateNumber: "V-2", Status: "idle", Metadata: map[string]string{"costCenter": "ops", "region": "south"}},
+		&models.Vehicle{PlateNumber: "V-3", Status: "active", Metadata: map[string]string{"costCenter": "sales"}},
+		&models.Vehicle{PlateNumber: "V-4", Status: "active"},
+	)
+	service := &VehicleService{}
+
+	plates := func(vehicles []*models.Vehicle) []string {
+		var plates []string
+		for _, vehicle := range vehicles {
+			plates = append(plates, vehicle.PlateNumber)
+		}
+		return plates
+	}
+
+	vehicles, err := service.getVehiclesByMetadata(context.Background(), store, map[string]string{"costCenter": "ops"}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"V-1", "V-2"}, plates(vehicles))
+
+	vehicles, err = service.getVehiclesByMetadata(context.Background(), store, map[string]string{"costCenter": "ops", "region": "south"}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"V-2"}, plates(vehicles))
+
+	vehicles, err = service.getVehiclesByMetadata(context.Background(), store, map[string]string{"costCenter": "ops"}, []string{"Active"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"V-1"}, plates(vehicles))
+}
+
+func TestVehicleService_GetVehiclesByMetadata_RejectsInvalidFilters(t *testing.T) {
+	store := newMemoryMetadataStore()
+	service := &VehicleService{}
+
+	_, err := service.getVehiclesByMetadata(context.Background(), store, map[string]string{"cost.center": "ops"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	_, err = service.getVehiclesByMetadata(context.Background(), store, map[string]string{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	_, err = service.getVehiclesByMetadata(context.Background(), store, map[string]string{"costCenter": "ops"}, []string{"parked"})
+	assert.ErrorIs(t, err, ErrInvalidVehicleStatus)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckOdometerRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		newOdometer int
+		accepted    bool
+	}{
+		{name: "normal increase", newOdometer: 10050, accepted: true},
+		{name: "unchanged", newOdometer: 10000, accepted: true},
+		{name: "small decrease within tolerance", newOdometer: 10000 - odometerRollbackToleranceKm, accepted: true},
+		{name: "large drop", newOdometer: 4000, accepted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &VehicleService{}
+			vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Odometer: 10000}
+
+			accepted := service.checkOdometerRollback(context.Background(), vehicle, tt.newOdometer)
+			assert.Equal(t, tt.accepted, accepted)
+
+			if tt.accepted {
+				assert.Empty(t, vehicle.Alerts)
+				return
+			}
+
+			require.Len(t, vehicle.Alerts, 1)
+			alert := vehicle.Alerts[0]
+			assert.Equal(t, "odometer_anomaly", alert.Type)
+			assert.Equal(t, "high", alert.Severity)
+			assert.Equal(t, vehicle.ID.Hex(), alert.VehicleID)
+			assert.False(t, alert.Resolved)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UnprovisionedTag marks vehicles created from telemetry rather than by an
+// operator, so they can be found and completed
+const UnprovisionedTag = "unprovisioned"
+
+// ErrInvalidProvisionID is returned when provisioning a vehicle whose ID
+// isn't a valid vehicle ID
+var ErrInvalidProvisionID = newDomainError(ErrValidation, "invalid vehicle ID")
+
+// ProvisionVehicle creates a minimal vehicle record under the given ID, for
+// telemetry reported by a device no vehicle was set up for. The vehicle gets
+// a placeholder name and plate and the unprovisioned tag; the telemetry
+// fills in its state. A vehicle created concurrently under the same ID is
+// returned as is.
+func (s *VehicleService) ProvisionVehicle(ctx context.Context, id string) (*models.Vehicle, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProvisionID, id)
+	}
+
+	vehicle := newProvisionedVehicle(objectID, time.Now())
+	created, err := s.vehicleRepo.Create(ctx, vehicle)
+	if mongo.IsDuplicateKeyError(err) {
+		return s.GetVehicleByID(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cacheManager != nil {
+		s.invalidateCacheOnCreate(ctx, created)
+	}
+	if s.tagIndex != nil {
+		s.tagIndex.SetVehicleTags(created.ID.Hex(), created.Tags)
+	}
+	return created, nil
+}
+
+// newProvisionedVehicle builds the placeholder record ProvisionVehicle creates
+func newProvisionedVehicle(id primitive.ObjectID, now time.Time) *models.Vehicle {
+	placeholder := "UNPROVISIONED-" + id.Hex()
+	return &models.Vehicle{
+		ID:          id,
+		Name:        placeholder,
+		PlateNumber: placeholder,
+		Status:      "offline",
+		LastUpdate:  now,
+		Alerts:      []models.Alert{},
+		Tags:        []string{UnprovisionedTag},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
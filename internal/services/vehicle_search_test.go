@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeVehicleSearcher applies the repository search filter to an in-memory fleet
+type fakeVehicleSearcher struct {
+	vehicles []*models.Vehicle
+	calls    int
+}
+
+func (f *fakeVehicleSearcher) Search(_ context.Context, query string, limit int) ([]*models.Vehicle, error) {
+	f.calls++
+
+	filter := repository.BuildVehicleSearchFilter(query)
+	var matches []*models.Vehicle
+	for _, vehicle := range f.vehicles {
+		if matchesSearchFilter(filter, vehicle) {
+			matches = append(matches, vehicle)
+		}
+		if len(matches) == limit {
+			break
+		}
+	}
+	return matches, nil
+}
+
+func matchesSearchFilter(filter bson.M, vehicle *models.Vehicle) bool {
+	fields := map[string]string{
+		"name":         vehicle.Name,
+		"plate_number": vehicle.PlateNumber,
+		"vin":          vehicle.VIN,
+		"driver":       vehicle.Driver,
+	}
+	for _, clause := range filter["$or"].(bson.A) {
+		for field, value := range clause.(bson.M) {
+			pattern := value.(primitive.Regex)
+			expr := pattern.Pattern
+			if strings.Contains(pattern.Options, "i") {
+				expr = "(?i)" + expr
+			}
+			if regexp.MustCompile(expr).MatchString(fields[field]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func searchTestFleet() []*models.Vehicle {
+	return []*models.Vehicle{
+		{ID: primitive.NewObjectID(), Name: "Delivery Van", PlateNumber: "ABC123", Driver: "Mary Smith", VIN: "1HGCM82633A004352"},
+		{ID: primitive.NewObjectID(), Name: "Truck 7", PlateNumber: "XYZ789", Driver: "John Doe", VIN: "2FTRX18W1XCA01234"},
+		{ID: primitive.NewObjectID(), Name: "Pickup", PlateNumber: "KLM456", Driver: "Jane Roe", VIN: "3GNEK13T11G123456"},
+	}
+}
+
+func TestVehicleService_SearchVehicles(t *testing.T) {
+	service := &VehicleService{}
+	searcher := &fakeVehicleSearcher{vehicles: searchTestFleet()}
+
+	tests := []struct {
+		name   string
+		query  string
+		plates []string
+	}{
+		{name: "plate prefix", query: "ABC", plates: []string{"ABC123"}},
+		{name: "case insensitive plate", query: "abc", plates: []string{"ABC123"}},
+		{name: "driver", query: "John", plates: []string{"XYZ789"}},
+		{name: "driver substring", query: "roe", plates: []string{"KLM456"}},
+		{name: "vin substring", query: "A0043", plates: []string{"ABC123"}},
+		{name: "name", query: "truck", plates: []string{"XYZ789"}},
+		{name: "regex metacharacters are literal", query: ".*", plates: nil},
+		{name: "no match", query: "nothing", plates: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vehicles, err := service.searchVehicles(context.Background(), searcher, tt.query, 0)
+			require.NoError(t, err)
+
+			var plates []string
+			for _, vehicle := range vehicles {
+				plates = append(plates, vehicle.PlateNumber)
+			}
+			assert.Equal(t, tt.plates, plates)
+		})
+	}
+}
+
+func TestVehicleService_SearchVehicles_RequiresQuery(t *testing.T) {
+	service := &VehicleService{}
+	searcher := &fakeVehicleSearcher{}
+
+	_, err := service.searchVehicles(context.Background(), searcher, "   ", 10)
+	assert.Error(t, err)
+	assert.Zero(t, searcher.calls)
+}
+
+func TestVehicleService_SearchVehicles_CachesByQuery(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	config := cache.DefaultCacheConfig()
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: config}
+	searcher := &fakeVehicleSearcher{vehicles: searchTestFleet()}
+
+	mockCache.On("GetVehicleList", "vehicle_search_20_abc").Return(nil, errors.New("cache miss")).Once()
+	mockCache.On("SetVehicleList", "vehicle_search_20_abc", mock.Anything, config.SearchResultTTL).Return(nil).Once()
+
+	vehicles, err := service.searchVehicles(context.Background(), searcher, "ABC", 0)
+	require.NoError(t, err)
+	require.Len(t, vehicles, 1)
+	assert.Equal(t, 1, searcher.calls)
+
+	mockCache.On("GetVehicleList", "vehicle_search_20_abc").Return(vehicles, nil).Once()
+
+	cached, err := service.searchVehicles(context.Background(), searcher, "abc", 0)
+	require.NoError(t, err)
+	assert.Equal(t, vehicles, cached)
+	assert.Equal(t, 1, searcher.calls, "cached results should not hit the repository")
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestVehicleService_SearchVehicles_CapsLimit(t *testing.T) {
+	service := &VehicleService{}
+	fleet := make([]*models.Vehicle, 0, MaxVehicleSearchLimit+10)
+	for i := 0; i < MaxVehicleSearchLimit+10; i++ {
+		fleet = append(fleet, &models.Vehicle{Name: "Van", PlateNumber: "VAN"})
+	}
+	searcher := &fakeVehicleSearcher{vehicles: fleet}
+
+	vehicles, err := service.searchVehicles(context.Background(), searcher, "van", MaxVehicleSearchLimit*2)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, MaxVehicleSearchLimit)
+}
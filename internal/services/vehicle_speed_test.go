@@ -0,0 +1,94 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/logger"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckSpeedPlausible(t *testing.T) {
+	tests := []struct {
+		name     string
+		speed    int
+		accepted bool
+	}{
+		{name: "negative", speed: -5, accepted: false},
+		{name: "zero", speed: 0, accepted: true},
+		{name: "normal", speed: 85, accepted: true},
+		{name: "at upper bound", speed: defaultMaxPlausibleSpeedKmh, accepted: true},
+		{name: "absurdly high", speed: 5000, accepted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &VehicleService{speedBounds: DefaultSpeedPlausibility()}
+			vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 60}
+
+			accepted := service.checkSpeedPlausible(context.Background(), vehicle, tt.speed)
+			assert.Equal(t, tt.accepted, accepted)
+
+			if tt.accepted {
+				assert.Empty(t, vehicle.Alerts)
+				return
+			}
+
+			require.Len(t, vehicle.Alerts, 1)
+			alert := vehicle.Alerts[0]
+			assert.Equal(t, "speed_anomaly", alert.Type)
+			assert.Equal(t, vehicle.ID.Hex(), alert.VehicleID)
+			assert.Equal(t, 60, vehicle.Speed)
+		})
+	}
+}
+
+func TestCheckSpeedPlausible_LogsRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	service := &VehicleService{speedBounds: DefaultSpeedPlausibility()}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+	ctx := logger.WithRequestID(context.Background(), "req-42")
+
+	require.False(t, service.checkSpeedPlausible(ctx, vehicle, 5000))
+
+	assert.Contains(t, logs.String(), "Rejected implausible speed")
+	assert.Contains(t, logs.String(), "request_id=req-42")
+	assert.Contains(t, logs.String(), "vehicle_id="+vehicle.ID.Hex())
+}
+
+func TestApplyReportedSpeed_SkipsUpdatesWithoutSpeed(t *testing.T) {
+	service := &VehicleService{speedBounds: SpeedPlausibility{MinKmh: 5, MaxKmh: 200}}
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Speed: 60}
+
+	service.applyReportedSpeed(context.Background(), vehicle, nil)
+
+	assert.Equal(t, 60, vehicle.Speed)
+	assert.Empty(t, vehicle.Alerts)
+
+	stopped := 0
+	service.applyReportedSpeed(context.Background(), vehicle, &stopped)
+
+	assert.Equal(t, 60, vehicle.Speed)
+	require.Len(t, vehicle.Alerts, 1)
+	assert.Equal(t, "speed_anomaly", vehicle.Alerts[0].Type)
+}
+
+func TestSpeedPlausibility_CustomBounds(t *testing.T) {
+	bounds := SpeedPlausibility{MinKmh: 0, MaxKmh: 120}
+
+	assert.True(t, bounds.IsPlausible(120))
+	assert.False(t, bounds.IsPlausible(121))
+
+	// A zero value falls back to the default upper bound
+	assert.True(t, SpeedPlausibility{}.IsPlausible(200))
+	assert.False(t, SpeedPlausibility{}.IsPlausible(5000))
+}
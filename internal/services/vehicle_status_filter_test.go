@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatusFinder filters an in-memory fleet the way the repository's $in query would
+type fakeStatusFinder struct {
+	vehicles    []*models.Vehicle
+	singleCalls int
+	multiCalls  int
+}
+
+func (f *fakeStatusFinder) FindByStatus(_ context.Context, status string) ([]*models.Vehicle, error) {
+	f.singleCalls++
+	return f.matching([]string{status}), nil
+}
+
+func (f *fakeStatusFinder) FindByStatuses(_ context.Context, statuses []string) ([]*models.Vehicle, error) {
+	f.multiCalls++
+	return f.matching(statuses), nil
+}
+
+func (f *fakeStatusFinder) matching(statuses []string) []*models.Vehicle {
+	var matches []*models.Vehicle
+	for _, vehicle := range f.vehicles {
+		for _, status := range statuses {
+			if vehicle.Status == status {
+				matches = append(matches, vehicle)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func statusTestFleet() []*models.Vehicle {
+	return []*models.Vehicle{
+		{PlateNumber: "A-1", Status: "active"},
+		{PlateNumber: "I-1", Status: "idle"},
+		{PlateNumber: "M-1", Status: "maintenance"},
+		{PlateNumber: "A-2", Status: "active"},
+		{PlateNumber: "O-1", Status: "offline"},
+	}
+}
+
+func TestVehicleService_GetVehiclesByStatuses_Filters(t *testing.T) {
+	service := &VehicleService{}
+	finder := &fakeStatusFinder{vehicles: statusTestFleet()}
+
+	vehicles, err := service.getVehiclesByStatuses(context.Background(), finder, []string{"active", "idle"})
+	require.NoError(t, err)
+
+	plates := make([]string, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		plates = append(plates, vehicle.PlateNumber)
+	}
+	assert.Equal(t, []string{"A-1", "I-1", "A-2"}, plates)
+	assert.Equal(t, 1, finder.multiCalls)
+}
+
+func TestVehicleService_GetVehiclesByStatuses_SingleStatusUsesStatusQuery(t *testing.T) {
+	service := &VehicleService{}
+	finder := &fakeStatusFinder{vehicles: statusTestFleet()}
+
+	vehicles, err := service.getVehiclesByStatuses(context.Background(), finder, []string{"idle", " idle "})
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+	assert.Equal(t, 1, finder.singleCalls)
+	assert.Zero(t, finder.multiCalls)
+}
+
+func TestVehicleService_GetVehiclesByStatuses_RejectsUnknownStatus(t *testing.T) {
+	service := &VehicleService{}
+	finder := &fakeStatusFinder{}
+
+	_, err := service.getVehiclesByStatuses(context.Background(), finder, []string{"active", "parked"})
+	assert.ErrorIs(t, err, ErrInvalidVehicleStatus)
+
+	_, err = service.getVehiclesByStatuses(context.Background(), finder, []string{"", " "})
+	assert.ErrorIs(t, err, ErrInvalidVehicleStatus)
+	assert.Zero(t, finder.singleCalls+finder.multiCalls)
+}
+
+func TestStatusListCacheKey_Normalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		key      string
+		ttlType  string
+	}{
+		{name: "single status shares by-status key", statuses: []string{"active"}, key: "vehicles_by_status_active", ttlType: "vehicle_list"},
+		{name: "sorted", statuses: []string{"active", "idle"}, key: "vehicles_by_statuses_active,idle", ttlType: "filtered_list"},
+		{name: "order independent", statuses: []string{"idle", "active"}, key: "vehicles_by_statuses_active,idle", ttlType: "filtered_list"},
+		{name: "case and duplicates", statuses: []string{"IDLE", "active", " idle"}, key: "vehicles_by_statuses_active,idle", ttlType: "filtered_list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := normalizeStatuses(tt.statuses)
+			require.NoError(t, err)
+
+			key, ttlType := statusListCacheKey(normalized)
+			assert.Equal(t, tt.key, key)
+			assert.Equal(t, tt.ttlType, ttlType)
+		})
+	}
+}
+
+func TestVehicleService_GetVehiclesByStatuses_CachesWithShortTTL(t *testing.T) {
+	mockCache := new(MockCacheManager)
+	config := cache.DefaultCacheConfig()
+	service := &VehicleService{cacheManager: mockCache, cacheConfig: config}
+	finder := &fakeStatusFinder{vehicles: statusTestFleet()}
+
+	mockCache.On("GetVehicleList", "vehicles_by_statuses_active,idle").Return(nil, errors.New("cache miss")).Once()
+	mockCache.On("SetVehicleList", "vehicles_by_statuses_active,idle", mock.Anything, config.FilteredListTTL).Return(nil).Once()
+
+	vehicles, err := service.getVehiclesByStatuses(context.Background(), finder, []string{"idle", "active"})
+	require.NoError(t, err)
+
+	mockCache.On("GetVehicleList", "vehicles_by_statuses_active,idle").Return(vehicles, nil).Once()
+
+	cached, err := service.getVehiclesByStatuses(context.Background(), finder, []string{"active", "IDLE"})
+	require.NoError(t, err)
+	assert.Equal(t, vehicles, cached)
+	assert.Equal(t, 1, finder.multiCalls, "the reordered filter should hit the same cache entry")
+
+	mockCache.AssertExpectations(t)
+}
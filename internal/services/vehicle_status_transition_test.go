@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStatusTransition_AllowsIdleToActive(t *testing.T) {
+	assert.NoError(t, checkStatusTransition("idle", "active", false))
+}
+
+func TestCheckStatusTransition_BlocksMaintenanceToActive(t *testing.T) {
+	err := checkStatusTransition("maintenance", "active", false)
+
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestCheckStatusTransition_AdminOverride(t *testing.T) {
+	assert.NoError(t, checkStatusTransition("maintenance", "active", true))
+}
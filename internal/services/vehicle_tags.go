@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/logger"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits on the tags a vehicle can carry
+const (
+	MaxVehicleTags = 20
+	MaxTagLength   = 64
+)
+
+// ErrInvalidTags is returned when tags break the limits
+var ErrInvalidTags = newDomainError(ErrValidation, "invalid tags")
+
+// tagPattern is what a tag may look like once lowercased, e.g. "depot-north"
+// or "region:east"
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_:-]*$`)
+
+// SetTagsRequest replaces all of a vehicle's tags; an empty list removes them
+type SetTagsRequest struct {
+	Tags []string `json:"tags" validate:"required"`
+}
+
+// VehicleTagIndex keeps the tags of every vehicle for consumers that filter
+// vehicle updates by tag, such as WebSocket subscriptions
+type VehicleTagIndex interface {
+	SetVehicleTags(vehicleID string, tags []string)
+	LoadVehicleTags(tags map[string][]string)
+}
+
+// tagStore is the subset of the vehicle repository used to store tags
+type tagStore interface {
+	FindByID(ctx context.Context, id string) (*models.Vehicle, error)
+	SetTags(ctx context.Context, id string, tags []string) error
+}
+
+// tagFinder is the subset of the vehicle repository used to list vehicles by tag
+type tagFinder interface {
+	FindByTag(ctx context.Context, tag string) ([]*models.Vehicle, error)
+}
+
+// SetVehicleTagIndex sets the index kept up to date as vehicles are tagged
+func (s *VehicleService) SetVehicleTagIndex(index VehicleTagIndex) {
+	s.tagIndex = index
+}
+
+// LoadTagIndex fills the tag index with the tags of every vehicle
+func (s *VehicleService) LoadTagIndex(ctx context.Context) error {
+	if s.tagIndex == nil {
+		return nil
+	}
+	tags, err := s.vehicleRepo.FindTags(ctx)
+	if err != nil {
+		return err
+	}
+	s.tagIndex.LoadVehicleTags(tags)
+	return nil
+}
+
+// SetTags replaces the vehicle's tags
+func (s *VehicleService) SetTags(ctx context.Context, id string, req *SetTagsRequest) (*models.Vehicle, error) {
+	return s.setTags(ctx, s.vehicleRepo, id, req.Tags)
+}
+
+func (s *VehicleService) setTags(ctx context.Context, store tagStore, id string, tags []string) (*models.Vehicle, error) {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, err := store.FindByID(ctx, id)
+	if err != nil {
+		return nil, vehicleLookupError(err)
+	}
+
+	if err := store.SetTags(ctx, id, normalized); err != nil {
+		return nil, err
+	}
+	previousTags := vehicle.Tags
+	vehicle.Tags = normalized
+
+	if s.cacheManager != nil {
+		// Lists of tags the vehicle left no longer include it
+		s.invalidateTagLists(ctx, previousTags)
+		s.invalidateCacheOnUpdate(ctx, vehicle, vehicle.Driver, vehicle.Status)
+	}
+	if s.tagIndex != nil && !vehicle.IsArchived() {
+		s.tagIndex.SetVehicleTags(id, normalized)
+	}
+	return vehicle, nil
+}
+
+// GetVehiclesByTag returns the vehicles carrying the tag
+func (s *VehicleService) GetVehiclesByTag(ctx context.Context, tag string) ([]*models.Vehicle, error) {
+	return s.getVehiclesByTag(ctx, s.vehicleRepo, tag)
+}
+
+func (s *VehicleService) getVehiclesByTag(ctx context.Context, finder tagFinder, tag string) ([]*models.Vehicle, error) {
+	normalized, err := normalizeTags([]string{tag})
+	if err != nil {
+		return nil, err
+	}
+	tag = normalized[0]
+
+	// Try cache first if cache manager is available
+	if s.cacheManager != nil {
+		cachedVehicles, err := s.cacheManager.GetVehicleList(tagListCacheKey(tag))
+		if err == nil && cachedVehicles != nil {
+			return cachedVehicles, nil
+		}
+		if err != nil {
+			logger.FromContext(ctx).Warn("Cache error for GetVehiclesByTag", "tag", tag, "error", err)
+		}
+	}
+
+	vehicles, err := finder.FindByTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cacheManager != nil {
+		ttl := s.cacheTTL("vehicle_list")
+		if cacheErr := s.cacheManager.SetVehicleList(tagListCacheKey(tag), vehicles, ttl); cacheErr != nil {
+			logger.FromContext(ctx).Warn("Failed to cache vehicles by tag", "tag", tag, "error", cacheErr)
+		}
+	}
+
+	return vehicles, nil
+}
+
+// tagListCacheKey returns the cache key of the vehicle list for a tag
+func tagListCacheKey(tag string) string {
+	return "vehicles_by_tag_" + tag
+}
+
+// invalidateTagLists drops the cached vehicle lists of the given tags
+func (s *VehicleService) invalidateTagLists(ctx context.Context, tags []string) {
+	for _, tag := range tags {
+		if err := s.cacheManager.Delete("fleet:vehicle_list:" + tagListCacheKey(tag)); err != nil {
+			logger.FromContext(ctx).Warn("Failed to invalidate vehicles by tag cache", "tag", tag, "error", err)
+		}
+	}
+}
+
+// normalizeTags trims and lowercases tags, drops duplicates and sorts them,
+// rejecting tags that break the limits. No tags yields nil.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTags, tag, MaxTagLength)
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("%w: %q may only contain letters, digits, '_', ':' and '-'", ErrInvalidTags, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxVehicleTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTags, MaxVehicleTags)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryTagStore keeps vehicles in memory and counts tag lookups
+type memoryTagStore struct {
+	vehicles map[string]*models.Vehicle
+	finds    int
+}
+
+func (m *memoryTagStore) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	vehicle, ok := m.vehicles[id]
+	if !ok {
+		return nil, repository.ErrVehicleNotFound
+	}
+	copied := *vehicle
+	return &copied, nil
+}
+
+func (m *memoryTagStore) SetTags(_ context.Context, id string, tags []string) error {
+	m.vehicles[id].Tags = tags
+	return nil
+}
+
+func (m *memoryTagStore) FindByTag(_ context.Context, tag string) ([]*models.Vehicle, error) {
+	m.finds++
+	var found []*models.Vehicle
+	for _, vehicle := range m.vehicles {
+		for _, vehicleTag := range vehicle.Tags {
+			if vehicleTag == tag {
+				copied := *vehicle
+				found = append(found, &copied)
+			}
+		}
+	}
+	return found, nil
+}
+
+// recordingTagIndex remembers the last tags set for each vehicle
+type recordingTagIndex struct {
+	tags map[string][]string
+}
+
+func (r *recordingTagIndex) SetVehicleTags(vehicleID string, tags []string) {
+	r.tags[vehicleID] = tags
+}
+
+func (r *recordingTagIndex) LoadVehicleTags(tags map[string][]string) {
+	r.tags = tags
+}
+
+func newMemoryTagStore(vehicles ...*models.Vehicle) *memoryTagStore {
+	store := &memoryTagStore{vehicles: make(map[string]*models.Vehicle)}
+	for _, vehicle := range vehicles {
+		store.vehicles[vehicle.ID.Hex()] = vehicle
+	}
+	return store
+}
+
+func TestSetTags_NormalizesAndUpdatesIndex(t *testing.T) {
+	truck := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck 1", Status: "active", Driver: "alice"}
+	store := newMemoryTagStore(truck)
+	index := &recordingTagIndex{tags: make(map[string][]string)}
+	service := &VehicleService{tagIndex: index}
+
+	vehicle, err := service.setTags(context.Background(), store, truck.ID.Hex(), []string{" Depot-North ", "refrigerated", "depot-north"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"depot-north", "refrigerated"}, vehicle.Tags)
+	assert.Equal(t, []string{"depot-north", "refrigerated"}, store.vehicles[truck.ID.Hex()].Tags)
+	assert.Equal(t, []string{"depot-north", "refrigerated"}, index.tags[truck.ID.Hex()])
+
+	_, err = service.setTags(context.Background(), store, truck.ID.Hex(), []string{})
+	require.NoError(t, err)
+	assert.Nil(t, store.vehicles[truck.ID.Hex()].Tags)
+	assert.Nil(t, index.tags[truck.ID.Hex()])
+}
+
+func TestSetTags_RejectsInvalidTags(t *testing.T) {
+	truck := &models.Vehicle{ID: primitive.NewObjectID()}
+	service := &VehicleService{}
+
+	tooMany := make([]string, MaxVehicleTags+1)
+	for i := range tooMany {
+		tooMany[i] = primitive.NewObjectID().Hex()
+	}
+	for _, tags := range [][]string{
+		{"depot north"},
+		{""},
+		{"-depot"},
+		{string(make([]byte, MaxTagLength+1))},
+		tooMany,
+	} {
+		_, err := service.setTags(context.Background(), newMemoryTagStore(truck), truck.ID.Hex(), tags)
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.ErrorIs(t, err, ErrValidation)
+	}
+
+	_, err := service.setTags(context.Background(), newMemoryTagStore(), primitive.NewObjectID().Hex(), []string{"depot-north"})
+	assert.ErrorIs(t, err, ErrVehicleNotFound)
+}
+
+func TestSetTags_ArchivedVehicleStaysOutOfIndex(t *testing.T) {
+	archivedAt := time.Now()
+	archived := &models.Vehicle{ID: primitive.NewObjectID(), DeletedAt: &archivedAt}
+	index := &recordingTagIndex{tags: make(map[string][]string)}
+	service := &VehicleService{tagIndex: index}
+
+	_, err := service.setTags(context.Background(), newMemoryTagStore(archived), archived.ID.Hex(), []string{"depot-north"})
+
+	require.NoError(t, err)
+	assert.NotContains(t, index.tags, archived.ID.Hex())
+}
+
+func TestSetTags_InvalidatesTagLists(t *testing.T) {
+	truck := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck 1", Status: "active", Driver: "alice", Tags: []string{"depot-north"}}
+	van := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Van 1", Status: "idle", Driver: "bob", Tags: []string{"depot-south"}}
+	store := newMemoryTagStore(truck, van)
+	memoryCache := cache.NewMemoryCacheManager(cache.DefaultCacheConfig(), 100)
+	service := &VehicleService{cacheManager: memoryCache, cacheConfig: cache.DefaultCacheConfig()}
+	ctx := context.Background()
+
+	north, err := service.getVehiclesByTag(ctx, store, "Depot-North")
+	require.NoError(t, err)
+	require.Len(t, north, 1)
+	_, err = service.getVehiclesByTag(ctx, store, "depot-south")
+	require.NoError(t, err)
+	_, err = service.getVehiclesByTag(ctx, store, "depot-north")
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.finds, "repeated reads are served from the cache")
+
+	// Moving the truck changes both the tag it left and the tag it joined
+	_, err = service.setTags(ctx, store, truck.ID.Hex(), []string{"depot-south"})
+	require.NoError(t, err)
+
+	north, err = service.getVehiclesByTag(ctx, store, "depot-north")
+	require.NoError(t, err)
+	assert.Empty(t, north)
+	south, err := service.getVehiclesByTag(ctx, store, "depot-south")
+	require.NoError(t, err)
+	assert.Len(t, south, 2)
+	assert.Equal(t, 4, store.finds)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fleet-backend/internal/vin"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrInvalidVIN is returned for VINs that fail the ISO 3779 checks
+	ErrInvalidVIN = newDomainError(ErrValidation, "invalid VIN")
+	// ErrDuplicateVIN is returned when another vehicle already has the VIN
+	ErrDuplicateVIN = newDomainError(ErrConflict, "VIN already exists")
+)
+
+// vehicleCreateStore is the subset of the vehicle repository used to create vehicles
+type vehicleCreateStore interface {
+	FindByPlateNumber(ctx context.Context, plateNumber string) (*models.Vehicle, error)
+	FindByVIN(ctx context.Context, vin string) (*models.Vehicle, error)
+	Create(ctx context.Context, vehicle *models.Vehicle) (*models.Vehicle, error)
+}
+
+// normalizeVIN uppercases a VIN and validates it, so the same vehicle can't
+// be stored under differently cased or mistyped VINs
+func normalizeVIN(raw string) (string, error) {
+	normalized := vin.Normalize(raw)
+	if err := vin.Validate(normalized); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidVIN, err)
+	}
+	return normalized, nil
+}
+
+// duplicateVehicleError maps a unique index violation on create to the
+// conflict it stands for. The lookups before creating can't see a vehicle
+// created concurrently, so the unique indexes have the final say.
+func duplicateVehicleError(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	if strings.Contains(err.Error(), repository.VINIndexName) {
+		return ErrDuplicateVIN
+	}
+	return ErrDuplicatePlate
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/vin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryVehicleCreateStore keeps created vehicles in memory
+type memoryVehicleCreateStore struct {
+	vehicles []*models.Vehicle
+}
+
+func (m *memoryVehicleCreateStore) FindByPlateNumber(_ context.Context, plateNumber string) (*models.Vehicle, error) {
+	for _, vehicle := range m.vehicles {
+		if vehicle.PlateNumber == plateNumber {
+			return vehicle, nil
+		}
+	}
+	return nil, ErrVehicleNotFound
+}
+
+func (m *memoryVehicleCreateStore) FindByVIN(_ context.Context, vin string) (*models.Vehicle, error) {
+	for _, vehicle := range m.vehicles {
+		if vehicle.VIN == vin {
+			return vehicle, nil
+		}
+	}
+	return nil, ErrVehicleNotFound
+}
+
+func (m *memoryVehicleCreateStore) Create(_ context.Context, vehicle *models.Vehicle) (*models.Vehicle, error) {
+	m.vehicles = append(m.vehicles, vehicle)
+	return vehicle, nil
+}
+
+// racingVehicleCreateStore finds no existing vehicle, as if another request
+// created it after the lookups, and fails the insert on a unique index
+type racingVehicleCreateStore struct {
+	memoryVehicleCreateStore
+	index string
+}
+
+func (r *racingVehicleCreateStore) Create(context.Context, *models.Vehicle) (*models.Vehicle, error) {
+	return nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{
+		Code:    11000,
+		Message: "E11000 duplicate key error collection: fleet.vehicles index: " + r.index + " dup key",
+	}}}
+}
+
+func TestCreateVehicle_StoresValidVIN(t *testing.T) {
+	store := &memoryVehicleCreateStore{}
+
+	vehicle, err := (&VehicleService{}).createVehicle(context.Background(), store, &CreateVehicleRequest{
+		Name:        "Truck",
+		PlateNumber: "KAA 001A",
+		VIN:         "1HGCM82633A004352",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1HGCM82633A004352", vehicle.VIN)
+	assert.Len(t, store.vehicles, 1)
+}
+
+func TestCreateVehicle_RejectsBadCheckDigit(t *testing.T) {
+	store := &memoryVehicleCreateStore{}
+
+	_, err := (&VehicleService{}).createVehicle(context.Background(), store, &CreateVehicleRequest{
+		Name:        "Truck",
+		PlateNumber: "KAA 001A",
+		VIN:         "1HGCM82643A004352",
+	})
+	assert.ErrorIs(t, err, ErrInvalidVIN)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, err, vin.ErrInvalidCheckDigit)
+	assert.Empty(t, store.vehicles)
+}
+
+func TestCreateVehicle_NormalizesLowercaseVIN(t *testing.T) {
+	store := &memoryVehicleCreateStore{}
+	service := &VehicleService{}
+
+	_, err := service.createVehicle(context.Background(), store, &CreateVehicleRequest{
+		Name:        "Truck",
+		PlateNumber: "KAA 001A",
+		VIN:         " 1hgcm82633a004352",
+	})
+	require.NoError(t, err)
+	require.Len(t, store.vehicles, 1)
+	assert.Equal(t, "1HGCM82633A004352", store.vehicles[0].VIN)
+
+	// The same VIN in another case is the same vehicle
+	_, err = service.createVehicle(context.Background(), store, &CreateVehicleRequest{
+		Name:        "Truck copy",
+		PlateNumber: "KBB 002B",
+		VIN:         "1HGCM82633A004352",
+	})
+	assert.ErrorIs(t, err, ErrDuplicateVIN)
+	assert.Len(t, store.vehicles, 1)
+}
+
+func TestCreateVehicle_MapsConcurrentDuplicatesToConflicts(t *testing.T) {
+	req := func() *CreateVehicleRequest {
+		return &CreateVehicleRequest{Name: "Truck", PlateNumber: "KAA 001A", VIN: "1HGCM82633A004352"}
+	}
+
+	_, err := (&VehicleService{}).createVehicle(context.Background(), &racingVehicleCreateStore{index: "vin_unique"}, req())
+	assert.ErrorIs(t, err, ErrDuplicateVIN)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = (&VehicleService{}).createVehicle(context.Background(), &racingVehicleCreateStore{index: "plate_number_1"}, req())
+	assert.ErrorIs(t, err, ErrDuplicatePlate)
+}
+
+func TestCreateVehicle_VINIsOptional(t *testing.T) {
+	store := &memoryVehicleCreateStore{}
+	service := &VehicleService{}
+
+	for _, plate := range []string{"KAA 001A", "KBB 002B"} {
+		_, err := service.createVehicle(context.Background(), store, &CreateVehicleRequest{Name: "Van", PlateNumber: plate})
+		require.NoError(t, err)
+	}
+	assert.Len(t, store.vehicles, 2)
+}
+
+func TestUpdateVehicle_RejectsInvalidVIN(t *testing.T) {
+	service := &VehicleService{}
+
+	_, err := service.UpdateVehicle(context.Background(), "vehicle-1", &UpdateVehicleRequest{VIN: "1HGCM82633AO04352"})
+
+	assert.ErrorIs(t, err, ErrInvalidVIN)
+	assert.ErrorIs(t, err, vin.ErrInvalidCharacter)
+}
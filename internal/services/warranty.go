@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"sort"
+	"time"
+)
+
+// GetActiveWarranties returns the parts replaced on a vehicle that are still
+// under warranty, based on the time elapsed and distance driven since the
+// replacement. Only completed maintenance records count.
+func (s *MaintenanceService) GetActiveWarranties(ctx context.Context, vehicleID string) ([]*models.PartWarranty, error) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+	if err != nil {
+		return nil, errors.New("vehicle not found")
+	}
+
+	records, err := s.maintenanceRepo.FindByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return activeWarranties(records, vehicle.Odometer, time.Now()), nil
+}
+
+// activeWarranties computes the warranties still running at now for a vehicle
+// whose odometer reads currentOdometer, soonest to expire first
+func activeWarranties(records []*models.MaintenanceRecord, currentOdometer int, now time.Time) []*models.PartWarranty {
+	warranties := []*models.PartWarranty{}
+
+	for _, record := range records {
+		if record.Status != models.MaintenanceStatusCompleted {
+			continue
+		}
+
+		for _, part := range record.Replacements {
+			if part.WarrantyMonths <= 0 && part.WarrantyKm <= 0 {
+				continue // no warranty
+			}
+
+			warranty := &models.PartWarranty{
+				RecordID:         record.ID,
+				PartName:         part.Name,
+				ReplacedAt:       record.PerformedAt,
+				ReplacedOdometer: record.Odometer,
+				Cost:             part.Cost,
+			}
+
+			if part.WarrantyMonths > 0 {
+				expiresAt := record.PerformedAt.AddDate(0, part.WarrantyMonths, 0)
+				if !now.Before(expiresAt) {
+					continue
+				}
+				daysRemaining := int(expiresAt.Sub(now).Hours() / 24)
+				warranty.ExpiresAt = &expiresAt
+				warranty.DaysRemaining = &daysRemaining
+			}
+
+			if part.WarrantyKm > 0 {
+				expiresOdometer := record.Odometer + part.WarrantyKm
+				if currentOdometer >= expiresOdometer {
+					continue
+				}
+				kmRemaining := expiresOdometer - currentOdometer
+				warranty.ExpiresOdometer = &expiresOdometer
+				warranty.KmRemaining = &kmRemaining
+			}
+
+			warranties = append(warranties, warranty)
+		}
+	}
+
+	sort.SliceStable(warranties, func(i, j int) bool {
+		return warrantyExpiresBefore(warranties[i], warranties[j])
+	})
+	return warranties
+}
+
+// warrantyExpiresBefore orders time-limited warranties by expiry date and puts
+// distance-only warranties after them, by remaining distance
+func warrantyExpiresBefore(a, b *models.PartWarranty) bool {
+	switch {
+	case a.ExpiresAt != nil && b.ExpiresAt != nil:
+		return a.ExpiresAt.Before(*b.ExpiresAt)
+	case a.ExpiresAt != nil:
+		return true
+	case b.ExpiresAt != nil:
+		return false
+	default:
+		return *a.KmRemaining < *b.KmRemaining
+	}
+}
+
+// withReplacedPartNames adds the names of structured replacements to the plain
+// parts list so existing consumers of partsReplaced still see them
+func withReplacedPartNames(parts []string, replacements []models.PartReplacement) []string {
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		seen[part] = true
+	}
+	for _, replacement := range replacements {
+		if !seen[replacement.Name] {
+			seen[replacement.Name] = true
+			parts = append(parts, replacement.Name)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func warrantyTestRecord(performedAt time.Time, odometer int, status string, parts ...models.PartReplacement) *models.MaintenanceRecord {
+	return &models.MaintenanceRecord{
+		ID:           primitive.NewObjectID(),
+		PerformedAt:  performedAt,
+		Odometer:     odometer,
+		Status:       status,
+		Replacements: parts,
+	}
+}
+
+func TestActiveWarranties_TimeLimited(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	sixMonthsAgo := now.AddDate(0, -6, 0)
+
+	records := []*models.MaintenanceRecord{
+		warrantyTestRecord(sixMonthsAgo, 40000, models.MaintenanceStatusCompleted,
+			models.PartReplacement{Name: models.PartBattery, WarrantyMonths: 12, Cost: 180},
+			models.PartReplacement{Name: models.PartWiperBlades, WarrantyMonths: 3},
+			models.PartReplacement{Name: models.PartEngineOil},
+		),
+	}
+
+	warranties := activeWarranties(records, 45000, now)
+
+	require.Len(t, warranties, 1)
+	battery := warranties[0]
+	assert.Equal(t, models.PartBattery, battery.PartName)
+	assert.Equal(t, records[0].ID, battery.RecordID)
+	assert.Equal(t, sixMonthsAgo.AddDate(0, 12, 0), *battery.ExpiresAt)
+	assert.Equal(t, 184, *battery.DaysRemaining)
+	assert.Nil(t, battery.ExpiresOdometer)
+	assert.Equal(t, 180.0, battery.Cost)
+}
+
+func TestActiveWarranties_DistanceLimited(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	replacedAt := now.AddDate(0, -2, 0)
+
+	records := []*models.MaintenanceRecord{
+		warrantyTestRecord(replacedAt, 40000, models.MaintenanceStatusCompleted,
+			models.PartReplacement{Name: models.PartTires, WarrantyMonths: 24, WarrantyKm: 50000},
+			models.PartReplacement{Name: models.PartBrakePads, WarrantyMonths: 24, WarrantyKm: 5000},
+		),
+	}
+
+	// Brake pads are within their 24 months but past their 5,000 km
+	warranties := activeWarranties(records, 46000, now)
+
+	require.Len(t, warranties, 1)
+	assert.Equal(t, models.PartTires, warranties[0].PartName)
+	assert.Equal(t, 90000, *warranties[0].ExpiresOdometer)
+	assert.Equal(t, 44000, *warranties[0].KmRemaining)
+}
+
+func TestActiveWarranties_SkipsExpiredAndIncompleteRecords(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []*models.MaintenanceRecord{
+		warrantyTestRecord(now.AddDate(-2, 0, 0), 10000, models.MaintenanceStatusCompleted,
+			models.PartReplacement{Name: models.PartAlternator, WarrantyMonths: 12}),
+		warrantyTestRecord(now.AddDate(0, -1, 0), 40000, models.MaintenanceStatusCancelled,
+			models.PartReplacement{Name: models.PartStarter, WarrantyMonths: 12}),
+		{ID: primitive.NewObjectID(), PerformedAt: now, Status: models.MaintenanceStatusCompleted, PartsReplaced: []string{models.PartOilFilter}},
+	}
+
+	assert.Empty(t, activeWarranties(records, 45000, now))
+}
+
+func TestActiveWarranties_SoonestExpiryFirst(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []*models.MaintenanceRecord{
+		warrantyTestRecord(now.AddDate(0, -1, 0), 44000, models.MaintenanceStatusCompleted,
+			models.PartReplacement{Name: models.PartTires, WarrantyKm: 60000},
+			models.PartReplacement{Name: models.PartBattery, WarrantyMonths: 36}),
+		warrantyTestRecord(now.AddDate(0, -6, 0), 40000, models.MaintenanceStatusCompleted,
+			models.PartReplacement{Name: models.PartClutch, WarrantyMonths: 12}),
+	}
+
+	warranties := activeWarranties(records, 45000, now)
+
+	names := make([]string, len(warranties))
+	for i, warranty := range warranties {
+		names[i] = warranty.PartName
+	}
+	assert.Equal(t, []string{models.PartClutch, models.PartBattery, models.PartTires}, names)
+}
+
+func TestWithReplacedPartNames(t *testing.T) {
+	parts := withReplacedPartNames([]string{models.PartEngineOil}, []models.PartReplacement{
+		{Name: models.PartEngineOil},
+		{Name: models.PartBattery, WarrantyMonths: 24},
+	})
+
+	assert.Equal(t, []string{models.PartEngineOil, models.PartBattery}, parts)
+}
+
+func TestCreateMaintenanceRequest_ValidatesReplacements(t *testing.T) {
+	req := CreateMaintenanceRequest{
+		VehicleID:     "v1",
+		Types:         []string{models.MaintenanceTypeBatteryReplacement},
+		Description:   "Battery swap",
+		Currency:      "USD",
+		ServiceCenter: "Main depot",
+		PerformedAt:   time.Now(),
+		Status:        models.MaintenanceStatusCompleted,
+		Replacements:  []models.PartReplacement{{Name: models.PartBattery, WarrantyMonths: -1}},
+	}
+
+	assert.Error(t, validator.New().Struct(&req))
+
+	req.Replacements[0].WarrantyMonths = 24
+	assert.NoError(t, validator.New().Struct(&req))
+}
@@ -0,0 +1,549 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/repository"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook delivery headers
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-ID"
+)
+
+const webhookDeliveryTimeout = 10 * time.Second
+
+// activeWebhookCacheTTL is how long the active subscriptions are reused by
+// publish. Changes made through this service take effect immediately; the TTL
+// bounds how long other replicas keep delivering to the old set.
+const activeWebhookCacheTTL = 30 * time.Second
+
+var (
+	// ErrInvalidEventType is returned for subscriptions selecting unknown event types
+	ErrInvalidEventType = errors.New("invalid webhook event type")
+	// ErrInvalidWebhookURL is returned for subscription URLs that aren't http(s)
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrUnknownAlertTarget is returned for alert routing that names a missing subscription
+	ErrUnknownAlertTarget = errors.New("unknown webhook subscription")
+)
+
+// webhookEventTypes are the non-alert event types subscriptions may select
+var webhookEventTypes = map[string]bool{
+	models.EventVehicleStatusChanged:    true,
+	models.EventMaintenanceCreated:      true,
+	models.EventMaintenanceCompleted:    true,
+	models.EventTelemetryUnknownVehicle: true,
+}
+
+// webhookEventCategories may be selected with a wildcard, e.g. "alert.*"
+var webhookEventCategories = map[string]bool{"alert": true, "vehicle": true, "maintenance": true, "telemetry": true}
+
+// EventPublisher receives domain events for delivery to external subscribers
+type EventPublisher interface {
+	Publish(event models.WebhookEvent)
+}
+
+// webhookStore is the subset of the webhook repository used by the service
+type webhookStore interface {
+	Create(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	FindByID(id string) (*models.WebhookSubscription, error)
+	FindAll() ([]*models.WebhookSubscription, error)
+	FindActive() ([]*models.WebhookSubscription, error)
+	Update(id string, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	Delete(id string) error
+}
+
+// WebhookService manages webhook subscriptions and fans events out to the
+// active subscriptions that selected their type
+type WebhookService struct {
+	store       webhookStore
+	client      *http.Client
+	testClient  *http.Client // for test deliveries, which only reach public addresses
+	checkTarget func(ctx context.Context, rawURL string) error
+	deliveries  sync.WaitGroup
+
+	mu          sync.Mutex
+	dispatchers map[string]*webhookDispatcher // by subscription ID
+
+	activeMu      sync.Mutex
+	active        []*models.WebhookSubscription
+	activeExpires time.Time
+	activeVersion uint64 // bumped on every change, so a load racing one isn't cached
+}
+
+func NewWebhookService(webhookRepo *repository.WebhookRepository) *WebhookService {
+	return newWebhookService(webhookRepo)
+}
+
+func newWebhookService(store webhookStore) *WebhookService {
+	return &WebhookService{
+		store:       store,
+		client:      &http.Client{Timeout: webhookDeliveryTimeout},
+		testClient:  newPublicWebhookClient(),
+		checkTarget: checkWebhookTarget,
+		dispatchers: make(map[string]*webhookDispatcher),
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description,omitempty" validate:"max=500"`
+	EventTypes  []string `json:"eventTypes" validate:"required,min=1,dive,required"`
+	Secret      string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"` // generated when empty
+	Active      *bool    `json:"active,omitempty"`                                     // defaults to true
+	Concurrency int      `json:"concurrency,omitempty" validate:"omitempty,min=1,max=32"`
+	QueueSize   int      `json:"queueSize,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url,omitempty" validate:"omitempty,url"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	EventTypes  []string `json:"eventTypes,omitempty" validate:"omitempty,min=1,dive,required"`
+	Secret      string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Active      *bool    `json:"active,omitempty"`
+	Concurrency *int     `json:"concurrency,omitempty" validate:"omitempty,min=1,max=32"`
+	QueueSize   *int     `json:"queueSize,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// validateEventTypes checks every selected type is a known event type, a
+// known alert type or a category wildcard
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if webhookEventTypes[eventType] {
+			continue
+		}
+		if category, ok := strings.CutSuffix(eventType, ".*"); ok && webhookEventCategories[category] {
+			continue
+		}
+		if alertType, ok := strings.CutPrefix(eventType, models.EventAlertPrefix); ok {
+			if _, known := defaultAlertSeverities[alertType]; known {
+				continue
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidEventType, eventType)
+	}
+	return nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// withoutSecret returns a copy of a subscription safe to show after creation
+func withoutSecret(subscription *models.WebhookSubscription) *models.WebhookSubscription {
+	redacted := *subscription
+	redacted.Secret = ""
+	return &redacted
+}
+
+// CreateWebhook creates a subscription. The response is the only time its secret is returned.
+func (s *WebhookService) CreateWebhook(req *CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	now := time.Now()
+	subscription, err := s.store.Create(&models.WebhookSubscription{
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  req.EventTypes,
+		Secret:      secret,
+		Active:      active,
+		Concurrency: req.Concurrency,
+		QueueSize:   req.QueueSize,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateActive()
+	return subscription, nil
+}
+
+func (s *WebhookService) GetAllWebhooks() ([]*models.WebhookSubscription, error) {
+	subscriptions, err := s.store.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]*models.WebhookSubscription, len(subscriptions))
+	for i, subscription := range subscriptions {
+		redacted[i] = withoutSecret(subscription)
+	}
+	return redacted, nil
+}
+
+func (s *WebhookService) GetWebhook(id string) (*models.WebhookSubscription, error) {
+	subscription, err := s.store.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return withoutSecret(subscription), nil
+}
+
+func (s *WebhookService) UpdateWebhook(id string, req *UpdateWebhookRequest) (*models.WebhookSubscription, error) {
+	subscription, err := s.store.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := validateWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+		subscription.URL = req.URL
+	}
+	if req.Description != nil {
+		subscription.Description = *req.Description
+	}
+	if len(req.EventTypes) > 0 {
+		if err := validateEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+		subscription.EventTypes = req.EventTypes
+	}
+	if req.Secret != "" {
+		subscription.Secret = req.Secret
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	if req.Concurrency != nil {
+		subscription.Concurrency = *req.Concurrency
+	}
+	if req.QueueSize != nil {
+		subscription.QueueSize = *req.QueueSize
+	}
+
+	updated, err := s.store.Update(id, subscription)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateActive()
+
+	// Paused subscriptions finish what is already queued
+	if !updated.Active {
+		s.retireDispatcher(id, false)
+	}
+	return withoutSecret(updated), nil
+}
+
+func (s *WebhookService) DeleteWebhook(id string) error {
+	if err := s.store.Delete(id); err != nil {
+		return err
+	}
+	s.invalidateActive()
+	s.retireDispatcher(id, true)
+	return nil
+}
+
+// GetDeliveryStats returns a subscription's delivery queue and outcome counters
+func (s *WebhookService) GetDeliveryStats(id string) (*models.WebhookDeliveryStats, error) {
+	subscription, err := s.store.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	dispatcher := s.dispatchers[id]
+	s.mu.Unlock()
+
+	if dispatcher == nil {
+		// Nothing has been published to the subscription yet
+		return &models.WebhookDeliveryStats{
+			SubscriptionID: id,
+			Workers:        webhookWorkers(subscription),
+			QueueCapacity:  webhookQueueCapacity(subscription),
+		}, nil
+	}
+
+	stats := dispatcher.stats()
+	return &stats, nil
+}
+
+// TestDelivery sends a webhook.test event to a subscription right away,
+// whatever event types it selected, and reports the outcome. Subscriptions
+// whose URL reaches a private, loopback or link-local address are refused
+// with ErrWebhookTargetNotAllowed.
+func (s *WebhookService) TestDelivery(id string) (*models.WebhookDeliveryResult, error) {
+	subscription, err := s.store.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTargetCheckTimeout)
+	defer cancel()
+	if err := s.checkTarget(ctx, subscription.URL); err != nil {
+		return nil, err
+	}
+
+	event := newWebhookEvent(models.EventWebhookTest, "", map[string]interface{}{
+		"message": "Test delivery for webhook subscription",
+	})
+	return s.deliverWith(s.testClient, subscription, event), nil
+}
+
+func newWebhookEvent(eventType, vehicleID string, data map[string]interface{}) models.WebhookEvent {
+	return models.WebhookEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      eventType,
+		VehicleID: vehicleID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+// Publish queues an event for every active subscription that selected its
+// type. Each subscription delivers in the background through its own workers,
+// so a slow receiver only delays its own events.
+func (s *WebhookService) Publish(event models.WebhookEvent) {
+	s.publish(event, nil)
+}
+
+// publish queues an event for its subscribers. A routing override sends the
+// event to its target subscriptions, and to the subscriptions that selected
+// the event type only if the override includes the default routing.
+func (s *WebhookService) publish(event models.WebhookEvent, routing *models.AlertRouting) {
+	if event.ID == "" {
+		event.ID = primitive.NewObjectID().Hex()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	subscriptions, err := s.activeSubscriptions()
+	if err != nil {
+		fmt.Printf("Failed to load webhook subscriptions for %s event: %v\n", event.Type, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscription := range subscriptions {
+		targeted := routing != nil && routing.Targets(subscription.ID.Hex())
+		if !targeted && (!subscription.Matches(event.Type) || (routing != nil && !routing.IncludeDefault)) {
+			continue
+		}
+
+		if !s.dispatcherFor(subscription).enqueue(event) {
+			fmt.Printf("Webhook queue for %s is full of critical events, dropped %s event %s\n", subscription.URL, event.Type, event.ID)
+		}
+	}
+}
+
+// activeSubscriptions returns the active subscriptions, loading them from the
+// store only when the cached set was invalidated or has expired
+func (s *WebhookService) activeSubscriptions() ([]*models.WebhookSubscription, error) {
+	s.activeMu.Lock()
+	if time.Now().Before(s.activeExpires) {
+		defer s.activeMu.Unlock()
+		return s.active, nil
+	}
+	version := s.activeVersion
+	s.activeMu.Unlock()
+
+	subscriptions, err := s.store.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	s.activeMu.Lock()
+	if version == s.activeVersion {
+		s.active = subscriptions
+		s.activeExpires = time.Now().Add(activeWebhookCacheTTL)
+	}
+	s.activeMu.Unlock()
+	return subscriptions, nil
+}
+
+// invalidateActive makes the next publish reload the active subscriptions
+func (s *WebhookService) invalidateActive() {
+	s.activeMu.Lock()
+	s.active = nil
+	s.activeExpires = time.Time{}
+	s.activeVersion++
+	s.activeMu.Unlock()
+}
+
+// dispatcherFor returns the subscription's dispatcher, replacing it if the
+// subscription's concurrency or queue size changed. Callers hold s.mu.
+func (s *WebhookService) dispatcherFor(subscription *models.WebhookSubscription) *webhookDispatcher {
+	id := subscription.ID.Hex()
+	current := s.dispatchers[id]
+	if current != nil && current.fits(subscription) {
+		current.setSubscription(subscription)
+		return current
+	}
+
+	var after <-chan struct{}
+	if current != nil {
+		current.close(false)
+		after = current.done
+	}
+
+	dispatcher := newWebhookDispatcher(subscription, s.deliver, &s.deliveries, after)
+	s.dispatchers[id] = dispatcher
+	return dispatcher
+}
+
+// retireDispatcher stops a subscription's workers, optionally discarding queued events
+func (s *WebhookService) retireDispatcher(id string, discard bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dispatcher := s.dispatchers[id]; dispatcher != nil {
+		dispatcher.close(discard)
+		delete(s.dispatchers, id)
+	}
+}
+
+// NotifyAlert publishes an alert as an "alert.<type>" event, following the
+// vehicle's alert routing override if it has one
+func (s *WebhookService) NotifyAlert(vehicle *models.Vehicle, alert *models.Alert) {
+	s.publish(newWebhookEvent(models.EventAlertPrefix+alert.Type, alert.VehicleID, map[string]interface{}{
+		"alertId":     alert.ID.Hex(),
+		"alertType":   alert.Type,
+		"severity":    alert.Severity,
+		"message":     alert.Message,
+		"vehicleName": vehicle.Name,
+	}), vehicle.AlertRouting)
+}
+
+// ValidateAlertTargets checks every ID names an existing webhook subscription
+func (s *WebhookService) ValidateAlertTargets(webhookIDs []string) error {
+	for _, id := range webhookIDs {
+		if _, err := s.store.FindByID(id); err != nil {
+			return fmt.Errorf("%w: %s", ErrUnknownAlertTarget, id)
+		}
+	}
+	return nil
+}
+
+// Wait blocks until queued and in-flight deliveries have finished
+func (s *WebhookService) Wait() {
+	s.deliveries.Wait()
+}
+
+// Drain waits at most timeout for queued and in-flight deliveries to finish.
+// Once the timeout expires, the events still queued are dropped and every
+// subscription's workers stop after their current delivery.
+func (s *WebhookService) Drain(timeout time.Duration) error {
+	finished := make(chan struct{})
+	go func() {
+		s.deliveries.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := 0
+	for id, dispatcher := range s.dispatchers {
+		dropped += dispatcher.close(true)
+		delete(s.dispatchers, id)
+	}
+	return fmt.Errorf("webhook drain timed out after %s, dropped %d queued deliveries", timeout, dropped)
+}
+
+// deliver POSTs an event to a subscription, signed with an HMAC-SHA256 of the
+// body using the subscription's secret
+func (s *WebhookService) deliver(subscription *models.WebhookSubscription, event models.WebhookEvent) *models.WebhookDeliveryResult {
+	return s.deliverWith(s.client, subscription, event)
+}
+
+func (s *WebhookService) deliverWith(client *http.Client, subscription *models.WebhookSubscription, event models.WebhookEvent) *models.WebhookDeliveryResult {
+	result := &models.WebhookDeliveryResult{SubscriptionID: subscription.ID, EventID: event.ID}
+	start := time.Now()
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookIDHeader, event.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !result.Success {
+		result.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// SignWebhookPayload returns the signature header value for a payload, so
+// receivers can verify deliveries with the subscription secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
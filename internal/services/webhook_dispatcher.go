@@ -0,0 +1,298 @@
+package services
+
+import (
+	"fleet-backend/internal/models"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Webhook delivery concurrency and queue bounds per subscription
+const (
+	DefaultWebhookConcurrency = 4
+	MaxWebhookConcurrency     = 32
+	DefaultWebhookQueueSize   = 1000
+	MaxWebhookQueueSize       = 10000
+)
+
+// webhookDeliverFunc performs a single delivery attempt
+type webhookDeliverFunc func(subscription *models.WebhookSubscription, event models.WebhookEvent) *models.WebhookDeliveryResult
+
+// webhookQueueItem is an event waiting in a delivery lane
+type webhookQueueItem struct {
+	seq      uint64
+	event    models.WebhookEvent
+	critical bool
+}
+
+// webhookLane is one worker's FIFO queue. All events for a vehicle hash to the
+// same lane, which is what keeps them in order.
+type webhookLane struct {
+	queue []webhookQueueItem
+	ready *sync.Cond
+}
+
+// webhookDispatcher delivers one subscription's events with a fixed number of
+// workers and a bounded queue shared between them. When the queue is full the
+// oldest non-critical event is shed to make room.
+type webhookDispatcher struct {
+	deliver webhookDeliverFunc
+	pending *sync.WaitGroup // counts queued and in-flight events for WebhookService.Wait
+
+	mu           sync.Mutex
+	subscription *models.WebhookSubscription
+	lanes        []*webhookLane
+	capacity     int
+	queued       int
+	inFlight     int
+	seq          uint64
+	closed       bool
+
+	delivered    int64
+	failed       int64
+	shed         int64
+	totalLatency time.Duration
+	lastDelivery time.Time
+	lastError    string
+
+	workers sync.WaitGroup
+	done    chan struct{} // closed once every worker has exited
+}
+
+// webhookWorkers returns the subscription's delivery concurrency, applying the default and cap
+func webhookWorkers(subscription *models.WebhookSubscription) int {
+	if subscription.Concurrency <= 0 {
+		return DefaultWebhookConcurrency
+	}
+	if subscription.Concurrency > MaxWebhookConcurrency {
+		return MaxWebhookConcurrency
+	}
+	return subscription.Concurrency
+}
+
+// webhookQueueCapacity returns the subscription's queue size, applying the default and cap
+func webhookQueueCapacity(subscription *models.WebhookSubscription) int {
+	if subscription.QueueSize <= 0 {
+		return DefaultWebhookQueueSize
+	}
+	if subscription.QueueSize > MaxWebhookQueueSize {
+		return MaxWebhookQueueSize
+	}
+	return subscription.QueueSize
+}
+
+// isCriticalWebhookEvent reports whether an event must not be shed under pressure
+func isCriticalWebhookEvent(event models.WebhookEvent) bool {
+	severity, _ := event.Data["severity"].(string)
+	return severity == "critical"
+}
+
+// newWebhookDispatcher starts the workers for a subscription. Workers wait for
+// after to close before their first delivery, so a replacement dispatcher
+// doesn't overtake the one it replaces while that one drains.
+func newWebhookDispatcher(subscription *models.WebhookSubscription, deliver webhookDeliverFunc, pending *sync.WaitGroup, after <-chan struct{}) *webhookDispatcher {
+	d := &webhookDispatcher{
+		deliver:      deliver,
+		pending:      pending,
+		subscription: subscription,
+		capacity:     webhookQueueCapacity(subscription),
+		done:         make(chan struct{}),
+	}
+
+	d.lanes = make([]*webhookLane, webhookWorkers(subscription))
+	for i := range d.lanes {
+		lane := &webhookLane{ready: sync.NewCond(&d.mu)}
+		d.lanes[i] = lane
+		d.workers.Add(1)
+		go d.work(lane, after)
+	}
+
+	go func() {
+		d.workers.Wait()
+		close(d.done)
+	}()
+
+	return d
+}
+
+// fits reports whether the dispatcher was built for the subscription's current
+// concurrency and queue size
+func (d *webhookDispatcher) fits(subscription *models.WebhookSubscription) bool {
+	return len(d.lanes) == webhookWorkers(subscription) && d.capacity == webhookQueueCapacity(subscription)
+}
+
+// setSubscription swaps in the latest copy of the subscription (URL, secret)
+// used by deliveries that haven't started yet
+func (d *webhookDispatcher) setSubscription(subscription *models.WebhookSubscription) {
+	d.mu.Lock()
+	d.subscription = subscription
+	d.mu.Unlock()
+}
+
+// laneFor hashes a vehicle ID to a lane so its events stay in order
+func (d *webhookDispatcher) laneFor(vehicleID string) *webhookLane {
+	h := fnv.New32a()
+	h.Write([]byte(vehicleID))
+	return d.lanes[h.Sum32()%uint32(len(d.lanes))]
+}
+
+// enqueue queues an event for delivery. It returns false if the event was
+// dropped because the dispatcher is closed or the queue is full of critical events.
+func (d *webhookDispatcher) enqueue(event models.WebhookEvent) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return false
+	}
+
+	critical := isCriticalWebhookEvent(event)
+	if d.queued >= d.capacity && !d.shedOldestNonCritical() {
+		d.shed++
+		return false
+	}
+
+	d.seq++
+	lane := d.laneFor(event.VehicleID)
+	lane.queue = append(lane.queue, webhookQueueItem{seq: d.seq, event: event, critical: critical})
+	d.queued++
+	d.pending.Add(1)
+	lane.ready.Signal()
+	return true
+}
+
+// shedOldestNonCritical drops the longest-queued non-critical event. Callers hold d.mu.
+func (d *webhookDispatcher) shedOldestNonCritical() bool {
+	var oldestLane *webhookLane
+	oldestIndex := -1
+	for _, lane := range d.lanes {
+		for i, item := range lane.queue {
+			if item.critical {
+				continue
+			}
+			if oldestLane == nil || item.seq < oldestLane.queue[oldestIndex].seq {
+				oldestLane, oldestIndex = lane, i
+			}
+			break // later items in this lane are newer
+		}
+	}
+	if oldestLane == nil {
+		return false
+	}
+
+	dropped := oldestLane.queue[oldestIndex]
+	oldestLane.queue = append(oldestLane.queue[:oldestIndex], oldestLane.queue[oldestIndex+1:]...)
+	d.queued--
+	d.shed++
+	d.pending.Done()
+	fmt.Printf("Webhook queue for %s is full, shed %s event %s\n", d.subscription.URL, dropped.event.Type, dropped.event.ID)
+	return true
+}
+
+// next blocks until the lane has an event, returning false once the
+// dispatcher is closed and the lane is empty
+func (d *webhookDispatcher) next(lane *webhookLane) (webhookQueueItem, *models.WebhookSubscription, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for len(lane.queue) == 0 {
+		if d.closed {
+			return webhookQueueItem{}, nil, false
+		}
+		lane.ready.Wait()
+	}
+
+	item := lane.queue[0]
+	lane.queue = lane.queue[1:]
+	d.queued--
+	d.inFlight++
+	return item, d.subscription, true
+}
+
+func (d *webhookDispatcher) work(lane *webhookLane, after <-chan struct{}) {
+	defer d.workers.Done()
+	if after != nil {
+		<-after
+	}
+
+	for {
+		item, subscription, ok := d.next(lane)
+		if !ok {
+			return
+		}
+
+		start := time.Now()
+		result := d.deliver(subscription, item.event)
+		elapsed := time.Since(start)
+		if !result.Success {
+			fmt.Printf("Webhook delivery of %s event to %s failed: %s\n", item.event.Type, subscription.URL, result.Error)
+		}
+		d.record(result, elapsed)
+		d.pending.Done()
+	}
+}
+
+func (d *webhookDispatcher) record(result *models.WebhookDeliveryResult, elapsed time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inFlight--
+	d.totalLatency += elapsed
+	d.lastDelivery = time.Now()
+	if result.Success {
+		d.delivered++
+	} else {
+		d.failed++
+		d.lastError = result.Error
+	}
+}
+
+// close stops accepting events. Queued events are still delivered unless
+// discard is set; it returns how many were discarded. The done channel
+// closes once the workers have exited.
+func (d *webhookDispatcher) close(discard bool) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	discarded := 0
+	for _, lane := range d.lanes {
+		if discard {
+			for range lane.queue {
+				d.pending.Done()
+			}
+			discarded += len(lane.queue)
+			d.queued -= len(lane.queue)
+			lane.queue = nil
+		}
+		lane.ready.Broadcast()
+	}
+	d.closed = true
+	return discarded
+}
+
+// stats returns a snapshot of the dispatcher's queue and delivery counters
+func (d *webhookDispatcher) stats() models.WebhookDeliveryStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := models.WebhookDeliveryStats{
+		SubscriptionID: d.subscription.ID.Hex(),
+		Workers:        len(d.lanes),
+		QueueCapacity:  d.capacity,
+		Queued:         d.queued,
+		InFlight:       d.inFlight,
+		Delivered:      d.delivered,
+		Failed:         d.failed,
+		Shed:           d.shed,
+		LastError:      d.lastError,
+	}
+	if attempts := d.delivered + d.failed; attempts > 0 {
+		stats.AverageLatencyMs = float64(d.totalLatency) / float64(attempts) / float64(time.Millisecond)
+	}
+	if !d.lastDelivery.IsZero() {
+		lastDelivery := d.lastDelivery
+		stats.LastDeliveryAt = &lastDelivery
+	}
+	return stats
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWebhookDelivery_PreservesOrderPerVehicle(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]int)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		// Uneven receiver latency would reorder unsequenced deliveries
+		time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+
+		mu.Lock()
+		received[event.VehicleID] = append(received[event.VehicleID], int(event.Data["seq"].(float64)))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	service := newWebhookService(newMemoryWebhookStore())
+	subscription, err := service.CreateWebhook(&CreateWebhookRequest{
+		URL:         receiver.URL,
+		EventTypes:  []string{models.EventVehicleStatusChanged},
+		Concurrency: 4,
+	})
+	require.NoError(t, err)
+
+	const vehicles, eventsPerVehicle = 6, 20
+	for seq := 0; seq < eventsPerVehicle; seq++ {
+		for v := 0; v < vehicles; v++ {
+			service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, fmt.Sprintf("vehicle-%d", v), map[string]interface{}{"seq": seq}))
+		}
+	}
+	service.Wait()
+
+	require.Len(t, received, vehicles)
+	for vehicleID, sequence := range received {
+		require.Len(t, sequence, eventsPerVehicle, vehicleID)
+		for i, seq := range sequence {
+			assert.Equal(t, i, seq, "%s received events out of order", vehicleID)
+		}
+	}
+
+	stats, err := service.GetDeliveryStats(subscription.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Workers)
+	assert.Equal(t, int64(vehicles*eventsPerVehicle), stats.Delivered)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.InFlight)
+	assert.NotNil(t, stats.LastDeliveryAt)
+}
+
+func TestWebhookDelivery_SlowReceiverDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+	fast := newWebhookReceiver(t)
+
+	service := newWebhookService(newMemoryWebhookStore())
+	subscribe(t, service, slow.URL, models.EventVehicleStatusChanged)
+	subscribe(t, service, fast.URL, models.EventVehicleStatusChanged)
+
+	for i := 0; i < 10; i++ {
+		service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, fmt.Sprintf("vehicle-%d", i), nil))
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(fast.eventTypes()) == 10
+	}, 2*time.Second, 10*time.Millisecond)
+
+	close(release)
+	service.Wait()
+}
+
+func TestWebhookDelivery_DrainDropsQueuedEventsAfterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	service := newWebhookService(newMemoryWebhookStore())
+	_, err := service.CreateWebhook(&CreateWebhookRequest{
+		URL:         slow.URL,
+		EventTypes:  []string{models.EventVehicleStatusChanged},
+		Concurrency: 1,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "vehicle-1", nil))
+	}
+	<-started
+
+	err = service.Drain(20 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dropped 4 queued deliveries")
+
+	// Only the delivery in flight is still waited for
+	close(release)
+	service.Wait()
+	assert.Len(t, started, 0)
+}
+
+func TestWebhookDispatcher_ShedsOldestNonCriticalWhenFull(t *testing.T) {
+	unblock := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []string
+	deliver := func(subscription *models.WebhookSubscription, event models.WebhookEvent) *models.WebhookDeliveryResult {
+		<-unblock
+		mu.Lock()
+		delivered = append(delivered, event.ID)
+		mu.Unlock()
+		return &models.WebhookDeliveryResult{Success: true}
+	}
+
+	var pending sync.WaitGroup
+	subscription := &models.WebhookSubscription{ID: primitive.NewObjectID(), URL: "http://receiver", Concurrency: 1, QueueSize: 3}
+	dispatcher := newWebhookDispatcher(subscription, deliver, &pending, nil)
+
+	event := func(id, severity string) models.WebhookEvent {
+		return models.WebhookEvent{ID: id, Type: "alert.speeding", VehicleID: "v1", Data: map[string]interface{}{"severity": severity}}
+	}
+
+	// e0 is picked up by the only worker, leaving the queue empty
+	require.True(t, dispatcher.enqueue(event("e0", "low")))
+	require.Eventually(t, func() bool { return dispatcher.stats().InFlight == 1 }, time.Second, time.Millisecond)
+
+	require.True(t, dispatcher.enqueue(event("e1", "low")))
+	require.True(t, dispatcher.enqueue(event("e2", "critical")))
+	require.True(t, dispatcher.enqueue(event("e3", "medium")))
+
+	// The queue is full: each new event displaces the oldest non-critical one
+	require.True(t, dispatcher.enqueue(event("e4", "low")))
+	require.True(t, dispatcher.enqueue(event("e5", "critical")))
+
+	stats := dispatcher.stats()
+	assert.Equal(t, 3, stats.Queued)
+	assert.Equal(t, int64(2), stats.Shed)
+
+	close(unblock)
+	pending.Wait()
+	assert.Equal(t, []string{"e0", "e2", "e4", "e5"}, delivered)
+	dispatcher.close(false)
+	<-dispatcher.done
+}
+
+func TestWebhookDispatcher_DropsIncomingWhenQueueIsAllCritical(t *testing.T) {
+	unblock := make(chan struct{})
+	deliver := func(subscription *models.WebhookSubscription, event models.WebhookEvent) *models.WebhookDeliveryResult {
+		<-unblock
+		return &models.WebhookDeliveryResult{Success: true}
+	}
+
+	var pending sync.WaitGroup
+	subscription := &models.WebhookSubscription{ID: primitive.NewObjectID(), URL: "http://receiver", Concurrency: 1, QueueSize: 2}
+	dispatcher := newWebhookDispatcher(subscription, deliver, &pending, nil)
+	critical := models.WebhookEvent{Type: "alert.fuel_theft", VehicleID: "v1", Data: map[string]interface{}{"severity": "critical"}}
+
+	require.True(t, dispatcher.enqueue(critical))
+	require.Eventually(t, func() bool { return dispatcher.stats().InFlight == 1 }, time.Second, time.Millisecond)
+	require.True(t, dispatcher.enqueue(critical))
+	require.True(t, dispatcher.enqueue(critical))
+
+	assert.False(t, dispatcher.enqueue(models.WebhookEvent{Type: "alert.low_fuel", VehicleID: "v1"}))
+	assert.Equal(t, int64(1), dispatcher.stats().Shed)
+
+	close(unblock)
+	pending.Wait()
+	assert.Equal(t, int64(3), dispatcher.stats().Delivered)
+	dispatcher.close(false)
+	<-dispatcher.done
+}
+
+func TestWebhookService_ReplacedDispatcherKeepsOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		received = append(received, int(event.Data["seq"].(float64)))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	service := newWebhookService(newMemoryWebhookStore())
+	subscription := subscribe(t, service, receiver.URL, models.EventVehicleStatusChanged)
+
+	for seq := 0; seq < 5; seq++ {
+		service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "v1", map[string]interface{}{"seq": seq}))
+	}
+
+	// Changing concurrency swaps the dispatcher while the old one still has a backlog
+	concurrency := 8
+	_, err := service.UpdateWebhook(subscription.ID.Hex(), &UpdateWebhookRequest{Concurrency: &concurrency})
+	require.NoError(t, err)
+	for seq := 5; seq < 10; seq++ {
+		service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "v1", map[string]interface{}{"seq": seq}))
+	}
+	service.Wait()
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, received)
+
+	stats, err := service.GetDeliveryStats(subscription.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, 8, stats.Workers)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookTargetNotAllowed is returned when a test delivery would reach a
+// private, loopback or link-local address, so the endpoint can't be used to
+// probe the internal network
+var ErrWebhookTargetNotAllowed = newDomainError(ErrValidation, "webhook URL must resolve to a public address")
+
+// webhookTargetCheckTimeout bounds the host lookup before a test delivery
+const webhookTargetCheckTimeout = 5 * time.Second
+
+// isPublicAddress reports whether ip may receive test deliveries
+func isPublicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// checkWebhookTarget resolves the URL's host and rejects it if any of its
+// addresses isn't public
+func checkWebhookTarget(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidWebhookURL
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", parsed.Hostname(), err)
+	}
+	for _, address := range addresses {
+		if !isPublicAddress(address.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookTargetNotAllowed, parsed.Hostname(), address.IP)
+		}
+	}
+	return nil
+}
+
+// newPublicWebhookClient returns a client that refuses to connect to
+// addresses that aren't public, which also covers hosts re-resolving to an
+// internal address after checkWebhookTarget
+func newPublicWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookDeliveryTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   webhookDeliveryTimeout,
+		Transport: transport,
+		// Redirects could point back inside the network
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
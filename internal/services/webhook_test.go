@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryWebhookStore keeps subscriptions in memory
+type memoryWebhookStore struct {
+	subscriptions map[string]*models.WebhookSubscription
+}
+
+func newMemoryWebhookStore() *memoryWebhookStore {
+	return &memoryWebhookStore{subscriptions: make(map[string]*models.WebhookSubscription)}
+}
+
+func (m *memoryWebhookStore) Create(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	subscription.ID = primitive.NewObjectID()
+	stored := *subscription
+	m.subscriptions[subscription.ID.Hex()] = &stored
+	return subscription, nil
+}
+
+func (m *memoryWebhookStore) FindByID(id string) (*models.WebhookSubscription, error) {
+	subscription, ok := m.subscriptions[id]
+	if !ok {
+		return nil, errors.New("webhook not found")
+	}
+	found := *subscription
+	return &found, nil
+}
+
+func (m *memoryWebhookStore) FindAll() ([]*models.WebhookSubscription, error) {
+	var all []*models.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		found := *subscription
+		all = append(all, &found)
+	}
+	return all, nil
+}
+
+func (m *memoryWebhookStore) FindActive() ([]*models.WebhookSubscription, error) {
+	var active []*models.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		if subscription.Active {
+			found := *subscription
+			active = append(active, &found)
+		}
+	}
+	return active, nil
+}
+
+func (m *memoryWebhookStore) Update(id string, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	if _, ok := m.subscriptions[id]; !ok {
+		return nil, errors.New("webhook not found")
+	}
+	stored := *subscription
+	m.subscriptions[id] = &stored
+	return subscription, nil
+}
+
+func (m *memoryWebhookStore) Delete(id string) error {
+	if _, ok := m.subscriptions[id]; !ok {
+		return errors.New("webhook not found")
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+// webhookReceiver records the events delivered to it
+type webhookReceiver struct {
+	*httptest.Server
+	mu         sync.Mutex
+	events     []models.WebhookEvent
+	signatures []string
+	bodies     [][]byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	receiver := &webhookReceiver{}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event models.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		receiver.mu.Lock()
+		receiver.events = append(receiver.events, event)
+		receiver.signatures = append(receiver.signatures, r.Header.Get(WebhookSignatureHeader))
+		receiver.bodies = append(receiver.bodies, body)
+		receiver.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) eventTypes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]string, len(r.events))
+	for i, event := range r.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func subscribe(t *testing.T, service *WebhookService, url string, eventTypes ...string) *models.WebhookSubscription {
+	subscription, err := service.CreateWebhook(&CreateWebhookRequest{URL: url, EventTypes: eventTypes})
+	require.NoError(t, err)
+	return subscription
+}
+
+func TestWebhookService_EventsOnlyReachMatchingSubscriptions(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+
+	speeding := newWebhookReceiver(t)
+	allAlerts := newWebhookReceiver(t)
+	statusChanges := newWebhookReceiver(t)
+	inactive := newWebhookReceiver(t)
+
+	subscribe(t, service, speeding.URL, "alert.speeding")
+	subscribe(t, service, allAlerts.URL, "alert.*")
+	subscribe(t, service, statusChanges.URL, models.EventVehicleStatusChanged)
+	paused := subscribe(t, service, inactive.URL, "alert.*", models.EventVehicleStatusChanged)
+	active := false
+	_, err := service.UpdateWebhook(paused.ID.Hex(), &UpdateWebhookRequest{Active: &active})
+	require.NoError(t, err)
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Truck"}
+	service.NotifyAlert(vehicle, &models.Alert{ID: primitive.NewObjectID(), VehicleID: vehicle.ID.Hex(), Type: "speeding", Severity: "high"})
+	service.NotifyAlert(vehicle, &models.Alert{ID: primitive.NewObjectID(), VehicleID: vehicle.ID.Hex(), Type: "low_fuel", Severity: "medium"})
+	service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, vehicle.ID.Hex(), nil))
+	service.Wait()
+
+	assert.Equal(t, []string{"alert.speeding"}, speeding.eventTypes())
+	assert.ElementsMatch(t, []string{"alert.speeding", "alert.low_fuel"}, allAlerts.eventTypes())
+	assert.Equal(t, []string{models.EventVehicleStatusChanged}, statusChanges.eventTypes())
+	assert.Empty(t, inactive.eventTypes())
+}
+
+// allowLoopbackTargets lets test deliveries reach httptest receivers
+func allowLoopbackTargets(service *WebhookService) {
+	service.testClient = service.client
+	service.checkTarget = func(context.Context, string) error { return nil }
+}
+
+func TestWebhookService_DeliveriesAreSigned(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+	allowLoopbackTargets(service)
+	receiver := newWebhookReceiver(t)
+
+	subscription, err := service.CreateWebhook(&CreateWebhookRequest{
+		URL:        receiver.URL,
+		EventTypes: []string{models.EventMaintenanceCompleted},
+		Secret:     "a-shared-secret-value",
+	})
+	require.NoError(t, err)
+
+	result, err := service.TestDelivery(subscription.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, http.StatusNoContent, result.StatusCode)
+
+	require.Len(t, receiver.events, 1)
+	assert.Equal(t, models.EventWebhookTest, receiver.events[0].Type)
+	assert.Equal(t, SignWebhookPayload("a-shared-secret-value", receiver.bodies[0]), receiver.signatures[0])
+}
+
+func TestWebhookService_CreateValidation(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+
+	_, err := service.CreateWebhook(&CreateWebhookRequest{URL: "https://example.com/hook", EventTypes: []string{"alert.teleported"}})
+	assert.ErrorIs(t, err, ErrInvalidEventType)
+
+	_, err = service.CreateWebhook(&CreateWebhookRequest{URL: "ftp://example.com/hook", EventTypes: []string{"alert.*"}})
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	// Secrets are generated when omitted and hidden after creation
+	subscription, err := service.CreateWebhook(&CreateWebhookRequest{URL: "https://example.com/hook", EventTypes: []string{"maintenance.*"}})
+	require.NoError(t, err)
+	assert.Len(t, subscription.Secret, 64)
+	assert.True(t, subscription.Active)
+
+	listed, err := service.GetAllWebhooks()
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Secret)
+}
+
+func TestWebhookService_VehicleAlertRoutingOverridesDefault(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+
+	fleet := newWebhookReceiver(t)
+	onCall := newWebhookReceiver(t)
+	subscribe(t, service, fleet.URL, "alert.*")
+	// The on-call hook only selects status changes, yet routed alerts still reach it
+	onCallHook := subscribe(t, service, onCall.URL, models.EventVehicleStatusChanged)
+
+	routed := &models.Vehicle{
+		ID:           primitive.NewObjectID(),
+		Name:         "Reefer",
+		AlertRouting: &models.AlertRouting{WebhookIDs: []string{onCallHook.ID.Hex()}},
+	}
+	other := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Van"}
+
+	service.NotifyAlert(routed, &models.Alert{ID: primitive.NewObjectID(), VehicleID: routed.ID.Hex(), Type: "speeding", Severity: "high"})
+	service.NotifyAlert(other, &models.Alert{ID: primitive.NewObjectID(), VehicleID: other.ID.Hex(), Type: "low_fuel", Severity: "medium"})
+	service.Wait()
+
+	require.Len(t, onCall.events, 1)
+	assert.Equal(t, routed.ID.Hex(), onCall.events[0].VehicleID)
+	require.Len(t, fleet.events, 1)
+	assert.Equal(t, other.ID.Hex(), fleet.events[0].VehicleID)
+
+	// Routing only applies to alerts, not other vehicle events
+	service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, other.ID.Hex(), nil))
+	service.Wait()
+	assert.Equal(t, []string{"alert.speeding", models.EventVehicleStatusChanged}, onCall.eventTypes())
+}
+
+func TestWebhookService_VehicleAlertRoutingCanIncludeDefault(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+
+	fleet := newWebhookReceiver(t)
+	onCall := newWebhookReceiver(t)
+	subscribe(t, service, fleet.URL, "alert.*")
+	onCallHook := subscribe(t, service, onCall.URL, models.EventVehicleStatusChanged)
+
+	vehicle := &models.Vehicle{
+		ID:           primitive.NewObjectID(),
+		AlertRouting: &models.AlertRouting{WebhookIDs: []string{onCallHook.ID.Hex()}, IncludeDefault: true},
+	}
+	service.NotifyAlert(vehicle, &models.Alert{ID: primitive.NewObjectID(), VehicleID: vehicle.ID.Hex(), Type: "speeding", Severity: "high"})
+	service.Wait()
+
+	assert.Equal(t, []string{"alert.speeding"}, fleet.eventTypes())
+	assert.Equal(t, []string{"alert.speeding"}, onCall.eventTypes())
+}
+
+// memoryRoutingStore keeps a vehicle's alert routing in memory
+type memoryRoutingStore struct {
+	vehicle *models.Vehicle
+}
+
+func (m *memoryRoutingStore) FindByID(_ context.Context, id string) (*models.Vehicle, error) {
+	if m.vehicle == nil || m.vehicle.ID.Hex() != id {
+		return nil, errors.New("vehicle not found")
+	}
+	found := *m.vehicle
+	return &found, nil
+}
+
+func (m *memoryRoutingStore) UpdateAlertRouting(_ context.Context, id string, routing *models.AlertRouting) error {
+	m.vehicle.AlertRouting = routing
+	return nil
+}
+
+func TestVehicleService_AlertRoutingTargetsMustExist(t *testing.T) {
+	webhooks := newWebhookService(newMemoryWebhookStore())
+	hook := subscribe(t, webhooks, "https://example.com/on-call", "alert.*")
+
+	vehicles := &VehicleService{}
+	store := &memoryRoutingStore{vehicle: &models.Vehicle{ID: primitive.NewObjectID()}}
+	id := store.vehicle.ID.Hex()
+
+	_, err := vehicles.updateAlertRouting(context.Background(), store, id, &AlertRoutingRequest{WebhookIDs: []string{hook.ID.Hex()}})
+	assert.ErrorIs(t, err, ErrAlertRoutingUnavailable)
+
+	vehicles.SetAlertTargetValidator(webhooks)
+	_, err = vehicles.updateAlertRouting(context.Background(), store, id, &AlertRoutingRequest{WebhookIDs: []string{hook.ID.Hex(), primitive.NewObjectID().Hex()}})
+	assert.ErrorIs(t, err, ErrUnknownAlertTarget)
+	assert.Nil(t, store.vehicle.AlertRouting)
+
+	vehicle, err := vehicles.updateAlertRouting(context.Background(), store, id, &AlertRoutingRequest{WebhookIDs: []string{hook.ID.Hex(), hook.ID.Hex()}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{hook.ID.Hex()}, vehicle.AlertRouting.WebhookIDs)
+	assert.Equal(t, vehicle.AlertRouting, store.vehicle.AlertRouting)
+
+	vehicle, err = vehicles.updateAlertRouting(context.Background(), store, id, nil)
+	require.NoError(t, err)
+	assert.Nil(t, vehicle.AlertRouting)
+	assert.Nil(t, store.vehicle.AlertRouting)
+}
+
+func TestWebhookService_TestDeliveryRefusesInternalTargets(t *testing.T) {
+	service := newWebhookService(newMemoryWebhookStore())
+	receiver := newWebhookReceiver(t)
+
+	for _, url := range []string{receiver.URL, "http://10.0.0.8/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]:8080/"} {
+		subscription := subscribe(t, service, url, models.EventMaintenanceCompleted)
+
+		_, err := service.TestDelivery(subscription.ID.Hex())
+		assert.ErrorIs(t, err, ErrWebhookTargetNotAllowed, url)
+		assert.ErrorIs(t, err, ErrValidation, url)
+	}
+	assert.Empty(t, receiver.events)
+}
+
+func TestWebhookService_PublicClientRefusesInternalAddresses(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	_, err := newPublicWebhookClient().Get(receiver.URL)
+	assert.ErrorIs(t, err, ErrWebhookTargetNotAllowed)
+	assert.Empty(t, receiver.events)
+}
+
+// countingWebhookStore counts loads of the active subscriptions
+type countingWebhookStore struct {
+	*memoryWebhookStore
+	activeLoads int
+}
+
+func (c *countingWebhookStore) FindActive() ([]*models.WebhookSubscription, error) {
+	c.activeLoads++
+	return c.memoryWebhookStore.FindActive()
+}
+
+func TestWebhookService_CachesActiveSubscriptionsUntilChanged(t *testing.T) {
+	store := &countingWebhookStore{memoryWebhookStore: newMemoryWebhookStore()}
+	service := newWebhookService(store)
+	first := newWebhookReceiver(t)
+	subscription := subscribe(t, service, first.URL, models.EventVehicleStatusChanged)
+
+	for i := 0; i < 3; i++ {
+		service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "vehicle-1", nil))
+	}
+	service.Wait()
+	assert.Equal(t, 1, store.activeLoads)
+	assert.Len(t, first.eventTypes(), 3)
+
+	// A new subscription is picked up by the next event
+	second := newWebhookReceiver(t)
+	subscribe(t, service, second.URL, models.EventVehicleStatusChanged)
+	service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "vehicle-1", nil))
+	service.Wait()
+	assert.Equal(t, 2, store.activeLoads)
+	assert.Len(t, first.eventTypes(), 4)
+	assert.Len(t, second.eventTypes(), 1)
+
+	// So is a deleted one
+	require.NoError(t, service.DeleteWebhook(subscription.ID.Hex()))
+	service.Publish(newWebhookEvent(models.EventVehicleStatusChanged, "vehicle-1", nil))
+	service.Wait()
+	assert.Equal(t, 3, store.activeLoads)
+	assert.Len(t, first.eventTypes(), 4)
+	assert.Len(t, second.eventTypes(), 2)
+}
@@ -0,0 +1,30 @@
+// Package status holds the vehicle status state machine
+package status
+
+// Vehicle statuses
+const (
+	Active      = "active"
+	Idle        = "idle"
+	Maintenance = "maintenance"
+	Offline     = "offline"
+)
+
+// transitions lists the statuses each status may change to. A vehicle under
+// maintenance or offline has to become idle before it can go active, so it
+// can't skip a maintenance hold or a return-to-service check.
+var transitions = map[string]map[string]bool{
+	Active:      {Idle: true, Maintenance: true, Offline: true},
+	Idle:        {Active: true, Maintenance: true, Offline: true},
+	Maintenance: {Idle: true, Offline: true},
+	Offline:     {Idle: true, Maintenance: true},
+}
+
+// TransitionAllowed reports whether a vehicle may change from one status to
+// another. Keeping the same status is always allowed, as is any change from a
+// vehicle without a status yet.
+func TransitionAllowed(from, to string) bool {
+	if from == to || from == "" {
+		return true
+	}
+	return transitions[from][to]
+}
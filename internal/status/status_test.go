@@ -0,0 +1,29 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{Idle, Active, true},
+		{Active, Idle, true},
+		{Active, Maintenance, true},
+		{Maintenance, Idle, true},
+		{Maintenance, Active, false},
+		{Offline, Active, false},
+		{Offline, Idle, true},
+		{Maintenance, Maintenance, true},
+		{"", Active, true},
+		{Idle, "parked", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, TransitionAllowed(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
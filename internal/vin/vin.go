@@ -0,0 +1,79 @@
+// Package vin validates vehicle identification numbers
+package vin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Length is the number of characters in a VIN
+const Length = 17
+
+// checkDigitPosition is the index of the check digit within a VIN
+const checkDigitPosition = 8
+
+var (
+	// ErrInvalidLength is returned for VINs that aren't 17 characters long
+	ErrInvalidLength = errors.New("VIN must be 17 characters")
+	// ErrInvalidCharacter is returned for VINs with characters other than
+	// digits and the letters A-Z except I, O and Q
+	ErrInvalidCharacter = errors.New("VIN contains an invalid character")
+	// ErrInvalidCheckDigit is returned when the check digit doesn't match the
+	// rest of the VIN, which usually means a mistyped character
+	ErrInvalidCheckDigit = errors.New("VIN check digit does not match")
+)
+
+// weights are the ISO 3779 position weights; the check digit itself weighs 0
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Normalize trims surrounding whitespace and uppercases a VIN, so the same
+// vehicle is always stored under the same VIN
+func Normalize(vin string) string {
+	return strings.ToUpper(strings.TrimSpace(vin))
+}
+
+// Validate checks a normalized VIN: 17 characters, none of them I, O or Q,
+// and a check digit in position 9 matching the weighted sum of the others
+func Validate(vin string) error {
+	if len(vin) != Length {
+		return fmt.Errorf("%w, got %d", ErrInvalidLength, len(vin))
+	}
+
+	sum := 0
+	for i := 0; i < Length; i++ {
+		value, ok := transliterate(vin[i])
+		if !ok {
+			return fmt.Errorf("%w: %q at position %d", ErrInvalidCharacter, vin[i], i+1)
+		}
+		sum += value * weights[i]
+	}
+
+	expected := byte('0' + sum%11)
+	if sum%11 == 10 {
+		expected = 'X'
+	}
+	if vin[checkDigitPosition] != expected {
+		return fmt.Errorf("%w: expected %c, got %c", ErrInvalidCheckDigit, expected, vin[checkDigitPosition])
+	}
+	return nil
+}
+
+// transliterate returns the numeric value ISO 3779 assigns a VIN character
+func transliterate(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1, true
+	case c == 'P':
+		return 7, true
+	case c == 'R':
+		return 9, true
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true
+	}
+	return 0, false
+}
@@ -0,0 +1,41 @@
+package vin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		vin  string
+		err  error
+	}{
+		{"valid", "1HGCM82633A004352", nil},
+		{"check digit X", "1M8GDM9AXKP042788", nil},
+		{"bad check digit", "1HGCM82643A004352", ErrInvalidCheckDigit},
+		{"mistyped character", "1HGCM82633A004353", ErrInvalidCheckDigit},
+		{"too short", "1HGCM82633A00435", ErrInvalidLength},
+		{"too long", "1HGCM82633A0043521", ErrInvalidLength},
+		{"letter O", "1HGCM82633AO04352", ErrInvalidCharacter},
+		{"lowercase", "1hgcm82633a004352", ErrInvalidCharacter},
+		{"empty", "", ErrInvalidLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.vin)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "1HGCM82633A004352", Normalize(" 1hgcm82633a004352\n"))
+	assert.NoError(t, Validate(Normalize("1hgcm82633a004352")))
+}
@@ -0,0 +1,59 @@
+package websocket
+
+import (
+	"fleet-backend/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseBoundingBox parses a "minLat,minLng,maxLat,maxLng" query value
+func ParseBoundingBox(value string) (*BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must be minLat,minLng,maxLat,maxLng")
+	}
+
+	coords := make([]float64, len(parts))
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox coordinate %q", part)
+		}
+		coords[i] = coord
+	}
+
+	box := &BoundingBox{MinLat: coords[0], MinLng: coords[1], MaxLat: coords[2], MaxLng: coords[3]}
+	if err := box.validate(); err != nil {
+		return nil, err
+	}
+	return box, nil
+}
+
+// validate checks the box's coordinates are in range and its latitudes ordered
+func (b *BoundingBox) validate() error {
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLat > b.MaxLat {
+		return fmt.Errorf("bbox latitudes must satisfy -90 <= minLat <= maxLat <= 90")
+	}
+	if b.MinLng < -180 || b.MinLng > 180 || b.MaxLng < -180 || b.MaxLng > 180 {
+		return fmt.Errorf("bbox longitudes must be between -180 and 180")
+	}
+	return nil
+}
+
+// updateLocation extracts the position carried by an update, if any
+func updateLocation(update VehicleUpdate) (lat, lng float64, ok bool) {
+	switch location := update.Data["location"].(type) {
+	case models.Location:
+		return location.Lat, location.Lng, true
+	case *models.Location:
+		if location != nil {
+			return location.Lat, location.Lng, true
+		}
+	case map[string]interface{}:
+		lat, latOK := location["lat"].(float64)
+		lng, lngOK := location["lng"].(float64)
+		return lat, lng, latOK && lngOK
+	}
+	return 0, 0, false
+}
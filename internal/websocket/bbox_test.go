@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"fleet-backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldSendToClient_BoundingBox(t *testing.T) {
+	manager := NewManager()
+	// Roughly the Nairobi metropolitan area
+	client := &Client{Filters: VehicleFilters{BBox: &BoundingBox{MinLat: -1.45, MinLng: 36.65, MaxLat: -1.15, MaxLng: 37.05}}}
+
+	tests := []struct {
+		name     string
+		update   VehicleUpdate
+		expected bool
+	}{
+		{
+			name:     "point inside",
+			update:   VehicleUpdate{VehicleID: "v1", Data: map[string]interface{}{"location": models.Location{Lat: -1.2921, Lng: 36.8219}}},
+			expected: true,
+		},
+		{
+			name:     "point outside",
+			update:   VehicleUpdate{VehicleID: "v1", Data: map[string]interface{}{"location": models.Location{Lat: -4.0435, Lng: 39.6682}}},
+			expected: false,
+		},
+		{
+			name:     "decoded JSON location outside",
+			update:   VehicleUpdate{VehicleID: "v1", Data: map[string]interface{}{"location": map[string]interface{}{"lat": 0.5, "lng": 36.8}}},
+			expected: false,
+		},
+		{
+			name:     "no location passes through",
+			update:   VehicleUpdate{VehicleID: "v1", UpdateType: "fuel", Data: map[string]interface{}{"fuelLevel": 40.0}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, manager.shouldSendToClient(client, tt.update))
+		})
+	}
+}
+
+func TestShouldSendToClient_BoundingBoxWithOtherFilters(t *testing.T) {
+	manager := NewManager()
+	client := &Client{Filters: VehicleFilters{
+		VehicleIDs: []string{"v1"},
+		BBox:       &BoundingBox{MinLat: -1.45, MinLng: 36.65, MaxLat: -1.15, MaxLng: 37.05},
+	}}
+
+	// A location-less update still has to match the other filters
+	assert.True(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v1", UpdateType: "status"}))
+	assert.False(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v2", UpdateType: "status"}))
+}
+
+func TestBoundingBoxContains_Antimeridian(t *testing.T) {
+	box := BoundingBox{MinLat: -20, MinLng: 170, MaxLat: 20, MaxLng: -170}
+
+	assert.True(t, box.Contains(0, 175))
+	assert.True(t, box.Contains(0, -175))
+	assert.False(t, box.Contains(0, 0))
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	box, err := ParseBoundingBox("-1.45, 36.65, -1.15, 37.05")
+	require.NoError(t, err)
+	assert.Equal(t, &BoundingBox{MinLat: -1.45, MinLng: 36.65, MaxLat: -1.15, MaxLng: 37.05}, box)
+
+	for _, invalid := range []string{"1,2,3", "a,b,c,d", "10,0,5,1", "-95,0,0,1", "0,0,1,190"} {
+		_, err := ParseBoundingBox(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
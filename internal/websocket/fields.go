@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubscribableFields are the vehicle fields a client can ask to be notified about
+var SubscribableFields = []string{"fuelLevel", "location", "speed", "status", "odometer"}
+
+// ParseFields parses the "fields" query values, each a single field or a
+// comma-separated list, rejecting fields clients can't subscribe to
+func ParseFields(values []string) ([]string, error) {
+	var fields []string
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if !containsField(SubscribableFields, field) {
+				return nil, fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(SubscribableFields, ", "))
+			}
+			if !containsField(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// changesField reports whether an update carries a change to one of fields.
+// Vehicle updates only carry the fields that changed, so their data keys are
+// the delta.
+func changesField(update VehicleUpdate, fields []string) bool {
+	for _, field := range fields {
+		if _, ok := update.Data[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// updateForClient returns the update as a client receives it. In compact mode
+// a vehicle update's data is cut down to the client's fields of interest;
+// alerts, maintenance events and clients without fields of interest get the
+// update unchanged.
+func updateForClient(filters VehicleFilters, update VehicleUpdate) VehicleUpdate {
+	if !filters.Compact || len(filters.Fields) == 0 || isEventUpdate(update) {
+		return update
+	}
+
+	// The update is shared by every client, so the data is copied
+	data := make(map[string]interface{}, len(filters.Fields))
+	for _, field := range filters.Fields {
+		if value, ok := update.Data[field]; ok {
+			data[field] = value
+		}
+	}
+	update.Data = data
+	return update
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldSendToClient_Fields(t *testing.T) {
+	manager := NewManager()
+	client := &Client{Filters: VehicleFilters{Fields: []string{"fuelLevel"}}}
+
+	tests := []struct {
+		name     string
+		update   VehicleUpdate
+		expected bool
+	}{
+		{
+			name:     "pure location update",
+			update:   VehicleUpdate{VehicleID: "v1", UpdateType: "location", Data: map[string]interface{}{"location": models.Location{Lat: -1.29, Lng: 36.82}}},
+			expected: false,
+		},
+		{
+			name:     "fuel update",
+			update:   VehicleUpdate{VehicleID: "v1", UpdateType: "fuel", Data: map[string]interface{}{"fuelLevel": 40.0}},
+			expected: true,
+		},
+		{
+			name:     "fuel changed along with location",
+			update:   VehicleUpdate{VehicleID: "v1", UpdateType: "fuel", Data: map[string]interface{}{"fuelLevel": 40.0, "location": models.Location{}}},
+			expected: true,
+		},
+		{
+			name:     "alerts pass through",
+			update:   VehicleUpdate{VehicleID: "v1", UpdateType: "alert", Data: map[string]interface{}{"alertType": "speeding"}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, manager.shouldSendToClient(client, tt.update))
+		})
+	}
+}
+
+func TestBroadcast_FuelOnlySubscriberSkipsLocationUpdates(t *testing.T) {
+	manager := NewManager()
+	fuelOnly := manager.newClient("fuel-only", nil, VehicleFilters{Fields: []string{"fuelLevel"}}, 10)
+	everything := manager.newClient("everything", nil, VehicleFilters{}, 10)
+	manager.clients[fuelOnly.ID] = fuelOnly
+	manager.clients[everything.ID] = everything
+
+	manager.broadcastToClients(VehicleUpdate{VehicleID: "v1", UpdateType: "location", Data: map[string]interface{}{"location": models.Location{Lat: 1, Lng: 2}}, Timestamp: time.Now()})
+	manager.broadcastToClients(VehicleUpdate{VehicleID: "v1", UpdateType: "fuel", Data: map[string]interface{}{"fuelLevel": 55.0}, Timestamp: time.Now()})
+
+	require.Len(t, fuelOnly.Send, 1)
+	assert.Equal(t, "fuel", (<-fuelOnly.Send).UpdateType)
+	assert.Len(t, everything.Send, 2)
+}
+
+func TestBroadcast_CompactTrimsDataToFields(t *testing.T) {
+	manager := NewManager()
+	compact := manager.newClient("compact", nil, VehicleFilters{Fields: []string{"fuelLevel"}, Compact: true}, 10)
+	full := manager.newClient("full", nil, VehicleFilters{Fields: []string{"fuelLevel"}}, 10)
+	manager.clients[compact.ID] = compact
+	manager.clients[full.ID] = full
+
+	manager.broadcastToClients(VehicleUpdate{
+		VehicleID:  "v1",
+		UpdateType: "fuel",
+		Data:       map[string]interface{}{"fuelLevel": 55.0, "speed": 60, "location": models.Location{Lat: 1, Lng: 2}},
+		Timestamp:  time.Now(),
+	})
+
+	require.Len(t, compact.Send, 1)
+	assert.Equal(t, map[string]interface{}{"fuelLevel": 55.0}, (<-compact.Send).Data)
+	require.Len(t, full.Send, 1)
+	assert.Len(t, (<-full.Send).Data, 3, "trimming for one client leaves the shared update intact")
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"fuelLevel,location", "status", "fuelLevel"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fuelLevel", "location", "status"}, fields)
+
+	_, err = ParseFields([]string{"fuel"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlertSeverities are the severities alert updates carry, and clients may
+// filter alerts by
+var AlertSeverities = []string{"low", "medium", "high", "critical"}
+
+// ParseSeverities parses the "severities" query values, each a single
+// severity or a comma-separated list, rejecting unknown severities
+func ParseSeverities(values []string) ([]string, error) {
+	var severities []string
+	for _, value := range values {
+		for _, severity := range strings.Split(value, ",") {
+			severity = strings.ToLower(strings.TrimSpace(severity))
+			if severity == "" {
+				continue
+			}
+			if !containsField(AlertSeverities, severity) {
+				return nil, fmt.Errorf("unknown severity %q, expected one of %s", severity, strings.Join(AlertSeverities, ", "))
+			}
+			if !containsField(severities, severity) {
+				severities = append(severities, severity)
+			}
+		}
+	}
+	return severities, nil
+}
+
+// Validate checks the filters a client sent over the connection hold the
+// same values the connection's query parameters are checked for
+func (f VehicleFilters) Validate() error {
+	for _, severity := range f.Severities {
+		if !containsField(AlertSeverities, severity) {
+			return fmt.Errorf("unknown severity %q, expected one of %s", severity, strings.Join(AlertSeverities, ", "))
+		}
+	}
+	for _, field := range f.Fields {
+		if !containsField(SubscribableFields, field) {
+			return fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(SubscribableFields, ", "))
+		}
+	}
+	if f.BBox != nil {
+		return f.BBox.validate()
+	}
+	return nil
+}
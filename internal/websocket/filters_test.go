@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverities(t *testing.T) {
+	severities, err := ParseSeverities([]string{"critical, High", "critical"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"critical", "high"}, severities)
+
+	_, err = ParseSeverities([]string{"critical,urgent"})
+	assert.ErrorContains(t, err, `unknown severity "urgent"`)
+}
+
+func TestVehicleFilters_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters VehicleFilters
+		wantErr string
+	}{
+		{"empty", VehicleFilters{}, ""},
+		{"valid", VehicleFilters{Severities: []string{"high"}, Fields: []string{"fuelLevel"}, BBox: &BoundingBox{MinLat: -1.45, MinLng: 36.65, MaxLat: -1.15, MaxLng: 37.05}}, ""},
+		{"unknown severity", VehicleFilters{Severities: []string{"severe"}}, "unknown severity"},
+		{"unknown field", VehicleFilters{Fields: []string{"secret"}}, "unknown field"},
+		{"inverted bbox", VehicleFilters{BBox: &BoundingBox{MinLat: 10, MaxLat: 5}}, "bbox latitudes"},
+		{"bbox out of range", VehicleFilters{BBox: &BoundingBox{MaxLng: 200}}, "bbox longitudes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,16 +1,48 @@
 package websocket
 
 import (
+	"compress/flate"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// Client send buffer sizing
+const (
+	// DefaultClientBufferSize is the Send buffer size for a client when none is configured
+	DefaultClientBufferSize = 256
+	// minClientBufferSize is the smallest buffer given to a narrowly filtered client
+	minClientBufferSize = 32
+	// perVehicleClientBuffer is the buffer given per subscribed vehicle with adaptive sizing
+	perVehicleClientBuffer = 16
+	// wideSubscriptionMultiplier scales the buffer of clients subscribed to the whole fleet
+	wideSubscriptionMultiplier = 4
+	// maxPendingPerClient bounds the updates held back for a slow client
+	maxPendingPerClient = 4096
+	// DefaultDrainTimeout is how long Stop lets clients flush their send buffers
+	DefaultDrainTimeout = 5 * time.Second
+	// maxUnackedPerClient bounds the critical updates tracked for a client that never acknowledges
+	maxUnackedPerClient = 1024
+)
+
+var (
+	// ErrTooManyClients is returned when a client registers while the manager is at MaxClients
+	ErrTooManyClients = errors.New("websocket client limit reached")
+	// ErrManagerStopped is returned for broadcasts made once the manager is stopping
+	ErrManagerStopped = errors.New("websocket manager is stopped")
+	// ErrInvalidKeepalive is returned for keepalive timings that would drop healthy clients
+	ErrInvalidKeepalive = errors.New("invalid websocket keepalive config")
+)
+
 // Manager implements the WebSocketManager interface
 type Manager struct {
 	clients    map[string]*Client
@@ -20,10 +52,59 @@ type Manager struct {
 	mutex      sync.RWMutex
 	upgrader   websocket.Upgrader
 	done       chan struct{}
+	metrics    *broadcastMetrics
+
+	clientBufferSize      int
+	adaptiveClientBuffers bool
+	compression           CompressionConfig
+	drainTimeout          time.Duration
+	keepalive             KeepaliveConfig
+
+	// tags resolves the tags of updated vehicles for tag filters
+	tags vehicleTagIndex
+
+	// ackResendTimeout is how long a critical update may go unacknowledged
+	// before it is written to the client once more; 0 never re-sends
+	ackResendTimeout time.Duration
+	eventSeq         atomic.Uint64
+
+	// maxClients caps connected clients; 0 means unlimited. reserved counts
+	// registrations accepted but not yet added by the run loop, so concurrent
+	// registrations can't overshoot the cap. Both are guarded by mutex.
+	maxClients      int
+	reserved        int
+	rejectedClients atomic.Int64
+
+	stopOnce sync.Once
+	running  atomic.Bool    // set while the run loop is executing
+	loop     sync.WaitGroup // the run loop
+	writers  sync.WaitGroup // per-client writeMessages goroutines
 }
 
-// NewManager creates a new WebSocket manager
+// broadcastMetrics tracks broadcast pipeline health
+type broadcastMetrics struct {
+	mu                sync.Mutex
+	droppedByPriority map[string]int64
+	clientSendDrops   int64
+	processed         int64
+	totalLatency      time.Duration
+}
+
+// NewManager creates a new WebSocket manager with the default keepalive timings
 func NewManager() *Manager {
+	return newManager(DefaultKeepaliveConfig())
+}
+
+// NewManagerWithKeepalive creates a new WebSocket manager with the given
+// keepalive timings, which must pass KeepaliveConfig.Validate
+func NewManagerWithKeepalive(keepalive KeepaliveConfig) (*Manager, error) {
+	if err := keepalive.Validate(); err != nil {
+		return nil, err
+	}
+	return newManager(keepalive), nil
+}
+
+func newManager(keepalive KeepaliveConfig) *Manager {
 	return &Manager{
 		clients:    make(map[string]*Client),
 		register:   make(chan *Client),
@@ -37,47 +118,149 @@ func NewManager() *Manager {
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
-		done: make(chan struct{}),
+		done:             make(chan struct{}),
+		clientBufferSize: DefaultClientBufferSize,
+		drainTimeout:     DefaultDrainTimeout,
+		keepalive:        keepalive,
+		metrics: &broadcastMetrics{
+			droppedByPriority: make(map[string]int64),
+		},
 	}
 }
 
 // Start begins the WebSocket manager's main loop
 func (m *Manager) Start() error {
-	go m.run()
+	m.loop.Add(1)
+	m.running.Store(true)
+	go func() {
+		defer m.loop.Done()
+		defer m.running.Store(false)
+		m.run()
+	}()
 	log.Println("WebSocket manager started")
 	return nil
 }
 
-// Stop gracefully shuts down the WebSocket manager
+// Stop gracefully shuts down the WebSocket manager, draining clients for up
+// to the drain timeout as Drain does. With no drain timeout, connections are
+// closed right away and queued messages are dropped.
 func (m *Manager) Stop() error {
-	close(m.done)
+	if m.drainTimeout > 0 {
+		return m.Drain(m.drainTimeout)
+	}
+
+	m.stopLoop()
 	
 	// Close all client connections
-	m.mutex.Lock()
-	for _, client := range m.clients {
-		close(client.Send)
+	for _, client := range m.detachClients() {
 		if client.Conn != nil {
 			client.Conn.Close()
 		}
 	}
-	m.mutex.Unlock()
 	
 	log.Println("WebSocket manager stopped")
 	return nil
 }
 
+// Drain shuts the manager down without losing queued messages: broadcasts still
+// waiting in the queue are fanned out, then each client's writer flushes its
+// send buffer and sends a close frame before the connection is closed.
+// Connections still flushing when the timeout expires are closed anyway. New
+// broadcasts are refused with ErrManagerStopped from the start of the drain.
+func (m *Manager) Drain(timeout time.Duration) error {
+	m.stopLoop()
+
+	// The run loop has exited, so the remaining queue can be fanned out here
+	for pending := true; pending; {
+		select {
+		case update := <-m.broadcast:
+			start := time.Now()
+			m.broadcastToClients(update)
+			m.recordBroadcast(time.Since(start))
+		default:
+			pending = false
+		}
+	}
+
+	// Closing Send lets each writer deliver what is buffered and then exit
+	clients := m.detachClients()
+
+	flushed := make(chan struct{})
+	go func() {
+		m.writers.Wait()
+		close(flushed)
+	}()
+
+	var err error
+	select {
+	case <-flushed:
+	case <-time.After(timeout):
+		err = fmt.Errorf("websocket drain timed out after %s", timeout)
+	}
+
+	for _, client := range clients {
+		if client.Conn != nil {
+			client.Conn.Close()
+		}
+	}
+
+	log.Printf("WebSocket manager drained %d clients", len(clients))
+	return err
+}
+
+// stopping reports whether Stop or Drain was called
+func (m *Manager) stopping() bool {
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopLoop stops the run loop and waits for it to exit
+func (m *Manager) stopLoop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+	})
+	m.loop.Wait()
+}
+
+// detachClients removes every client and closes its send channel
+func (m *Manager) detachClients() []*Client {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	clients := make([]*Client, 0, len(m.clients))
+	for clientID, client := range m.clients {
+		delete(m.clients, clientID)
+		close(client.Send)
+		clients = append(clients, client)
+	}
+	return clients
+}
+
 // run is the main event loop for the WebSocket manager
 func (m *Manager) run() {
-	ticker := time.NewTicker(30 * time.Second) // Health check interval
+	ticker := time.NewTicker(m.keepalive.healthCheckInterval())
 	defer ticker.Stop()
 
+	var resend <-chan time.Time
+	if m.ackResendTimeout > 0 {
+		resendTicker := time.NewTicker(m.ackResendTimeout / 2)
+		defer resendTicker.Stop()
+		resend = resendTicker.C
+	}
+
 	for {
 		select {
 		case client := <-m.register:
 			m.mutex.Lock()
 			m.clients[client.ID] = client
+			m.reserved--
 			m.mutex.Unlock()
 			log.Printf("Client %s registered", client.ID)
+			m.writers.Add(1) // released by the client's writer; added here so Drain never races it
 			go m.handleClient(client)
 
 		case client := <-m.unregister:
@@ -93,30 +276,171 @@ func (m *Manager) run() {
 			log.Printf("Client %s unregistered", client.ID)
 
 		case update := <-m.broadcast:
+			start := time.Now()
 			m.broadcastToClients(update)
+			m.recordBroadcast(time.Since(start))
 
 		case <-ticker.C:
 			m.healthCheck()
 
+		case <-resend:
+			m.resendUnacked(time.Now())
+
 		case <-m.done:
 			return
 		}
 	}
 }
 
+// SetCompression enables or disables permessage-deflate for connections upgraded
+// afterwards. It must be called before the manager starts accepting clients.
+func (m *Manager) SetCompression(config CompressionConfig) error {
+	if config.Enabled && (config.Level < flate.HuffmanOnly || config.Level > flate.BestCompression) {
+		return fmt.Errorf("invalid compression level %d", config.Level)
+	}
+
+	m.compression = config
+	m.upgrader.EnableCompression = config.Enabled
+	return nil
+}
+
+// SetClientBufferSize sets the Send buffer size for clients registered afterwards
+func (m *Manager) SetClientBufferSize(size int) {
+	if size > 0 {
+		m.clientBufferSize = size
+	}
+}
+
+// SetAdaptiveClientBuffers sizes each client's Send buffer by the breadth of its
+// filters: whole-fleet subscribers get a larger buffer, clients following a few
+// vehicles a smaller one
+func (m *Manager) SetAdaptiveClientBuffers(enabled bool) {
+	m.adaptiveClientBuffers = enabled
+}
+
+// SetDrainTimeout sets how long Stop lets clients flush queued messages
+// before their connections are closed; 0 closes them without draining
+func (m *Manager) SetDrainTimeout(timeout time.Duration) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	m.drainTimeout = timeout
+}
+
+// SetAckResendTimeout makes the manager write a critical update once more to
+// clients that haven't acknowledged it within timeout; 0 disables re-sending.
+// It must be called before Start.
+func (m *Manager) SetAckResendTimeout(timeout time.Duration) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	m.ackResendTimeout = timeout
+}
+
+// SetMaxClients caps the number of connected clients; 0 removes the cap.
+// Clients registering beyond it are refused with ErrTooManyClients.
+func (m *Manager) SetMaxClients(max int) {
+	if max < 0 {
+		max = 0
+	}
+	m.mutex.Lock()
+	m.maxClients = max
+	m.mutex.Unlock()
+}
+
+// AtCapacity reports whether a new client would be refused, so handlers can
+// answer before upgrading the connection
+func (m *Manager) AtCapacity() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.maxClients > 0 && len(m.clients)+m.reserved >= m.maxClients
+}
+
 // RegisterClient registers a new WebSocket client
 func (m *Manager) RegisterClient(clientID string, conn *websocket.Conn, filters VehicleFilters) error {
-	client := &Client{
+	return m.RegisterClientWithBufferSize(clientID, conn, filters, 0)
+}
+
+// RegisterClientWithBufferSize registers a client with an explicit Send buffer size.
+// A size of zero uses the manager's configured sizing.
+func (m *Manager) RegisterClientWithBufferSize(clientID string, conn *websocket.Conn, filters VehicleFilters, bufferSize int) error {
+	if !m.reserveSlot() {
+		m.rejectedClients.Add(1)
+		log.Printf("Rejected client %s: client limit reached", clientID)
+		rejectConnection(conn)
+		return ErrTooManyClients
+	}
+
+	client := m.newClient(clientID, conn, filters, bufferSize)
+
+	select {
+	case m.register <- client:
+		return nil
+	case <-m.done:
+		m.mutex.Lock()
+		m.reserved--
+		m.mutex.Unlock()
+		return fmt.Errorf("websocket manager is shutting down")
+	}
+}
+
+// reserveSlot claims room for a client being registered, unless the manager is at capacity
+func (m *Manager) reserveSlot() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.maxClients > 0 && len(m.clients)+m.reserved >= m.maxClients {
+		return false
+	}
+	m.reserved++
+	return true
+}
+
+// rejectConnection tells a refused client to retry later. The caller still
+// closes the connection.
+func rejectConnection(conn *websocket.Conn) {
+	if conn == nil {
+		return
+	}
+	message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server has reached its client limit")
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+}
+
+// newClient builds a client with its Send buffer sized
+func (m *Manager) newClient(clientID string, conn *websocket.Conn, filters VehicleFilters, bufferSize int) *Client {
+	if bufferSize <= 0 {
+		bufferSize = m.clientBufferSizeFor(filters)
+	}
+
+	return &Client{
 		ID:       clientID,
 		Conn:     conn,
 		Filters:  filters,
-		Send:     make(chan VehicleUpdate, 256),
+		Send:     make(chan VehicleUpdate, bufferSize),
 		LastPing: time.Now(),
 		IsActive: true,
 	}
+}
 
-	m.register <- client
-	return nil
+// clientBufferSizeFor returns the Send buffer size for a subscription
+func (m *Manager) clientBufferSizeFor(filters VehicleFilters) int {
+	if !m.adaptiveClientBuffers {
+		return m.clientBufferSize
+	}
+
+	// Status, driver and alert filters still match across the whole fleet
+	if len(filters.VehicleIDs) == 0 {
+		return m.clientBufferSize * wideSubscriptionMultiplier
+	}
+
+	size := len(filters.VehicleIDs) * perVehicleClientBuffer
+	if size < minClientBufferSize {
+		size = minClientBufferSize
+	}
+	if size > m.clientBufferSize {
+		size = m.clientBufferSize
+	}
+	return size
 }
 
 // UnregisterClient removes a WebSocket client
@@ -126,23 +450,34 @@ func (m *Manager) UnregisterClient(clientID string) error {
 	m.mutex.RUnlock()
 
 	if exists {
-		m.unregister <- client
+		select {
+		case m.unregister <- client:
+		case <-m.done:
+		}
 	}
 	return nil
 }
 
 // BroadcastVehicleUpdate sends a single vehicle update to relevant clients
 func (m *Manager) BroadcastVehicleUpdate(vehicleID string, update VehicleUpdate) error {
+	if m.stopping() {
+		return ErrManagerStopped
+	}
 	select {
 	case m.broadcast <- update:
 		return nil
 	default:
+		m.recordDrop(update.Priority)
 		return fmt.Errorf("broadcast channel full, dropping update for vehicle %s", vehicleID)
 	}
 }
 
 // BroadcastBatchUpdates sends multiple vehicle updates efficiently
 func (m *Manager) BroadcastBatchUpdates(updates []VehicleUpdate) error {
+	if m.stopping() {
+		return ErrManagerStopped
+	}
+
 	// Sort updates by priority for efficient processing
 	priorityOrder := map[string]int{
 		PriorityCritical: 0,
@@ -158,6 +493,7 @@ func (m *Manager) BroadcastBatchUpdates(updates []VehicleUpdate) error {
 			select {
 			case m.broadcast <- update:
 			default:
+				m.recordDrop(update.Priority)
 				log.Printf("Dropping high priority update for vehicle %s due to full channel", update.VehicleID)
 			}
 		}
@@ -171,6 +507,7 @@ func (m *Manager) BroadcastBatchUpdates(updates []VehicleUpdate) error {
 			case m.broadcast <- update:
 			default:
 				// Drop low priority updates if channel is full
+				m.recordDrop(update.Priority)
 				continue
 			}
 		}
@@ -192,20 +529,75 @@ func (m *Manager) GetClientStats() ClientStats {
 	defer m.mutex.RUnlock()
 
 	stats := ClientStats{
-		TotalClients: len(m.clients),
+		TotalClients:    len(m.clients),
+		MaxClients:      m.maxClients,
+		RejectedClients: m.rejectedClients.Load(),
 	}
 
+	stats.Clients = make(map[string]ClientDeliveryStats, len(m.clients))
 	for _, client := range m.clients {
 		if client.IsActive {
 			stats.ActiveClients++
 		} else {
 			stats.InactiveClients++
 		}
+
+		client.pendingMu.Lock()
+		delivery := ClientDeliveryStats{
+			Pending:   len(client.pending) + len(client.urgent),
+			Coalesced: client.coalesced,
+			Dropped:   client.dropped,
+		}
+		client.pendingMu.Unlock()
+
+		client.ackMu.Lock()
+		delivery.Unacked = len(client.unacked)
+		delivery.Acked = client.acked
+		delivery.Resent = client.resent
+		client.ackMu.Unlock()
+
+		stats.Clients[client.ID] = delivery
+		stats.UnackedCritical += delivery.Unacked
 	}
 
 	return stats
 }
 
+// IsRunning reports whether the manager's main loop is processing events
+func (m *Manager) IsRunning() bool {
+	return m.running.Load()
+}
+
+// GetBroadcastHealth returns the current state of the broadcast pipeline
+func (m *Manager) GetBroadcastHealth() BroadcastHealth {
+	clientStats := m.GetClientStats()
+
+	health := BroadcastHealth{
+		QueueDepth:        len(m.broadcast),
+		QueueCapacity:     cap(m.broadcast),
+		TotalClients:      clientStats.TotalClients,
+		ActiveClients:     clientStats.ActiveClients,
+		InactiveClients:   clientStats.InactiveClients,
+		DroppedByPriority: make(map[string]int64),
+	}
+
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+
+	for priority, dropped := range m.metrics.droppedByPriority {
+		health.DroppedByPriority[priority] = dropped
+		health.TotalDropped += dropped
+	}
+	health.ClientSendDrops = m.metrics.clientSendDrops
+	health.BroadcastsProcessed = m.metrics.processed
+	if m.metrics.processed > 0 {
+		average := m.metrics.totalLatency / time.Duration(m.metrics.processed)
+		health.AverageBroadcastLatencyMs = float64(average) / float64(time.Millisecond)
+	}
+
+	return health
+}
+
 // GetUpgrader returns the WebSocket upgrader for external use
 func (m *Manager) GetUpgrader() *websocket.Upgrader {
 	return &m.upgrader
@@ -213,29 +605,164 @@ func (m *Manager) GetUpgrader() *websocket.Upgrader {
 
 // broadcastToClients sends an update to all relevant clients based on their filters
 func (m *Manager) broadcastToClients(update VehicleUpdate) {
+	// Every client receives a critical update under the same event ID
+	if update.Priority == PriorityCritical && update.EventID == "" {
+		update.EventID = strconv.FormatUint(m.eventSeq.Add(1), 10)
+	}
+
+	queuedAt := time.Now()
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	for _, client := range m.clients {
 		if m.shouldSendToClient(client, update) {
-			select {
-			case client.Send <- update:
-			default:
-				// Client's send channel is full, mark as inactive
-				client.IsActive = false
-				log.Printf("Client %s send channel full, marking as inactive", client.ID)
+			clientUpdate := updateForClient(client.Filters, update)
+			if clientUpdate.EventID != "" {
+				client.trackUnacked(clientUpdate, queuedAt)
 			}
+			m.enqueue(client, clientUpdate)
+		}
+	}
+}
+
+// enqueue queues an update on a client's send buffer. When the buffer is full
+// the update is held back instead. A held update replaces any older held
+// update of the same type for the same vehicle, so a slow client ends up with
+// the latest state of each vehicle rather than being dropped; alerts and
+// critical updates are never replaced.
+func (m *Manager) enqueue(client *Client, update VehicleUpdate) {
+	client.pendingMu.Lock()
+	defer client.pendingMu.Unlock()
+
+	key := pendingKey(update)
+	coalescable := !mustDeliver(update)
+
+	// A newer update must not overtake one already held back for the vehicle
+	if client.heldVehicles[update.VehicleID] > 0 {
+		if _, held := client.pending[key]; held && coalescable {
+			client.pending[key] = update
+			client.coalesced++
+			return
+		}
+	} else {
+		select {
+		case client.Send <- update:
+			return
+		default:
+		}
+	}
+
+	held := len(client.pending) + len(client.urgent)
+	if (coalescable && update.VehicleID == "") || held >= maxPendingPerClient {
+		// Nothing to coalesce with, mark as inactive
+		client.dropped++
+		m.recordClientSendDrop()
+		client.IsActive = false
+		log.Printf("Client %s send channel full, marking as inactive", client.ID)
+		return
+	}
+
+	if coalescable {
+		if client.pending == nil {
+			client.pending = make(map[string]VehicleUpdate)
 		}
+		client.pending[key] = update
+	} else {
+		client.urgent = append(client.urgent, update)
+	}
+	if client.heldVehicles == nil {
+		client.heldVehicles = make(map[string]int)
+	}
+	client.heldVehicles[update.VehicleID]++
+}
+
+// pendingKey identifies the held updates a newer update may replace
+func pendingKey(update VehicleUpdate) string {
+	return update.VehicleID + "|" + update.UpdateType
+}
+
+// mustDeliver reports whether every copy of an update has to reach clients,
+// rather than only the latest per vehicle
+func mustDeliver(update VehicleUpdate) bool {
+	return update.Priority == PriorityCritical || isAlertUpdate(update)
+}
+
+// takePending removes and returns the updates held back for a client, oldest first
+func (c *Client) takePending() []VehicleUpdate {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	if len(c.pending) == 0 && len(c.urgent) == 0 {
+		return nil
 	}
+
+	updates := make([]VehicleUpdate, 0, len(c.pending)+len(c.urgent))
+	updates = append(updates, c.urgent...)
+	for key, update := range c.pending {
+		updates = append(updates, update)
+		delete(c.pending, key)
+	}
+	c.urgent = nil
+	c.heldVehicles = nil
+	// Stable, so urgent updates with equal timestamps keep their order
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].Timestamp.Before(updates[j].Timestamp)
+	})
+	return updates
+}
+
+// recordDrop counts an update dropped because the broadcast channel was full
+func (m *Manager) recordDrop(priority string) {
+	m.metrics.mu.Lock()
+	m.metrics.droppedByPriority[priority]++
+	m.metrics.mu.Unlock()
+}
+
+// recordClientSendDrop counts an update dropped because a client's send buffer was full
+func (m *Manager) recordClientSendDrop() {
+	m.metrics.mu.Lock()
+	m.metrics.clientSendDrops++
+	m.metrics.mu.Unlock()
+}
+
+// recordBroadcast records the time taken to fan an update out to clients
+func (m *Manager) recordBroadcast(latency time.Duration) {
+	m.metrics.mu.Lock()
+	m.metrics.processed++
+	m.metrics.totalLatency += latency
+	m.metrics.mu.Unlock()
+}
+
+// isAlertUpdate reports whether an update concerns an alert, so alert filters apply to it
+func isAlertUpdate(update VehicleUpdate) bool {
+	return update.UpdateType == "alert" || update.UpdateType == "alert_ack"
+}
+
+// isMaintenanceUpdate reports whether an update is a maintenance event
+func isMaintenanceUpdate(update VehicleUpdate) bool {
+	return update.UpdateType == UpdateTypeMaintenanceDue || update.UpdateType == UpdateTypeReminderOverdue
+}
+
+// isEventUpdate reports whether an update is an event rather than a change
+// of vehicle fields, so field filters don't apply to it
+func isEventUpdate(update VehicleUpdate) bool {
+	return isAlertUpdate(update) || isMaintenanceUpdate(update)
 }
 
 // shouldSendToClient determines if an update should be sent to a specific client
 func (m *Manager) shouldSendToClient(client *Client, update VehicleUpdate) bool {
 	filters := client.Filters
 
+	// Maintenance events only go to clients that opted in
+	if isMaintenanceUpdate(update) && !filters.Maintenance {
+		return false
+	}
+
 	// If no filters are set, send all updates
 	if len(filters.VehicleIDs) == 0 && len(filters.Statuses) == 0 && 
-	   len(filters.Drivers) == 0 && len(filters.AlertTypes) == 0 {
+	   len(filters.Drivers) == 0 && len(filters.AlertTypes) == 0 &&
+	   len(filters.Severities) == 0 && filters.BBox == nil &&
+	   len(filters.Fields) == 0 && len(filters.Tags) == 0 {
 		return true
 	}
 
@@ -253,6 +780,11 @@ func (m *Manager) shouldSendToClient(client *Client, update VehicleUpdate) bool
 		}
 	}
 
+	// Check tag filter; it applies to alerts too, so a group only hears about its vehicles
+	if len(filters.Tags) > 0 && !m.vehicleHasTag(update.VehicleID, filters.Tags) {
+		return false
+	}
+
 	// Check status filter
 	if len(filters.Statuses) > 0 {
 		if status, ok := update.Data["status"].(string); ok {
@@ -269,8 +801,21 @@ func (m *Manager) shouldSendToClient(client *Client, update VehicleUpdate) bool
 		}
 	}
 
+	// Check bounding box filter; updates without a location pass through
+	if filters.BBox != nil {
+		if lat, lng, ok := updateLocation(update); ok && !filters.BBox.Contains(lat, lng) {
+			return false
+		}
+	}
+
+	// Check fields of interest; alerts and maintenance events aren't field
+	// changes and pass through
+	if len(filters.Fields) > 0 && !isEventUpdate(update) && !changesField(update, filters.Fields) {
+		return false
+	}
+
 	// Check alert type filter
-	if len(filters.AlertTypes) > 0 && update.UpdateType == "alert" {
+	if len(filters.AlertTypes) > 0 && isAlertUpdate(update) {
 		if alertType, ok := update.Data["alertType"].(string); ok {
 			found := false
 			for _, at := range filters.AlertTypes {
@@ -285,25 +830,53 @@ func (m *Manager) shouldSendToClient(client *Client, update VehicleUpdate) bool
 		}
 	}
 
+	// Check alert severity filter; combined with the alert type filter, both must match
+	if len(filters.Severities) > 0 && isAlertUpdate(update) {
+		if severity, ok := update.Data["severity"].(string); ok {
+			found := false
+			for _, s := range filters.Severities {
+				if s == severity {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
 	return true
 }
 
 // handleClient manages individual client connections
 func (m *Manager) handleClient(client *Client) {
 	defer func() {
-		m.unregister <- client
+		select {
+		case m.unregister <- client:
+		case <-m.done:
+			// Manager is shutting down and has already detached the client
+		}
 	}()
 
+	if m.compression.Enabled {
+		// Only takes effect if the client negotiated permessage-deflate
+		client.Conn.SetCompressionLevel(m.compression.Level)
+	}
+
 	// Set up ping/pong handlers for connection health
-	client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	client.Conn.SetReadDeadline(time.Now().Add(m.keepalive.ReadDeadline))
 	client.Conn.SetPongHandler(func(string) error {
 		client.LastPing = time.Now()
-		client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		client.Conn.SetReadDeadline(time.Now().Add(m.keepalive.ReadDeadline))
 		return nil
 	})
 
 	// Start goroutine to handle outgoing messages
-	go m.writeMessages(client)
+	go func() {
+		defer m.writers.Done()
+		m.writeMessages(client)
+	}()
 
 	// Handle incoming messages (mainly pings and filter updates)
 	for {
@@ -316,12 +889,25 @@ func (m *Manager) handleClient(client *Client) {
 			break
 		}
 
+		// Acknowledgements of critical updates
+		if msgType, ok := message["type"].(string); ok && msgType == MessageTypeAck {
+			if eventID, ok := message["eventId"].(string); ok {
+				client.acknowledge(eventID)
+			}
+			continue
+		}
+
 		// Handle filter updates
 		if msgType, ok := message["type"].(string); ok && msgType == "update_filters" {
 			if filtersData, ok := message["filters"]; ok {
 				filtersJSON, _ := json.Marshal(filtersData)
 				var newFilters VehicleFilters
 				if err := json.Unmarshal(filtersJSON, &newFilters); err == nil {
+					// Invalid filters leave the current ones in place
+					if err := newFilters.Validate(); err != nil {
+						log.Printf("Rejected filters for client %s: %v", client.ID, err)
+						continue
+					}
 					client.Filters = newFilters
 					log.Printf("Updated filters for client %s", client.ID)
 				}
@@ -332,27 +918,34 @@ func (m *Manager) handleClient(client *Client) {
 
 // writeMessages handles outgoing messages to a client
 func (m *Manager) writeMessages(client *Client) {
-	ticker := time.NewTicker(54 * time.Second) // Send ping every 54 seconds
+	ticker := time.NewTicker(m.keepalive.PingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case update, ok := <-client.Send:
-			client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
-				client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// Deliver the latest held-back state before closing
+				if m.writePending(client) == nil {
+					client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+					client.Conn.WriteMessage(websocket.CloseMessage, m.closeMessage())
+				}
 				return
 			}
 
-			// Send the vehicle update
-			if err := client.Conn.WriteJSON(map[string]interface{}{
-				"type": MessageTypeVehicleUpdate,
-				"data": update,
-			}); err != nil {
+			if err := m.writeUpdate(client, update); err != nil {
 				log.Printf("Error writing message to client %s: %v", client.ID, err)
 				return
 			}
 
+			// Once the buffer is drained, catch up on coalesced updates
+			if len(client.Send) == 0 {
+				if err := m.writePending(client); err != nil {
+					log.Printf("Error writing message to client %s: %v", client.ID, err)
+					return
+				}
+			}
+
 		case <-ticker.C:
 			client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -363,6 +956,98 @@ func (m *Manager) writeMessages(client *Client) {
 	}
 }
 
+// closeMessage is the close frame payload sent after a client's last message:
+// "going away" while the manager shuts down, empty otherwise
+func (m *Manager) closeMessage() []byte {
+	if m.stopping() {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	}
+	return []byte{}
+}
+
+// writePending writes the updates held back while the client's buffer was full
+func (m *Manager) writePending(client *Client) error {
+	for _, update := range client.takePending() {
+		if err := m.writeUpdate(client, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUpdate writes a single vehicle update frame to a client
+func (m *Manager) writeUpdate(client *Client, update VehicleUpdate) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type": MessageTypeVehicleUpdate,
+		"data": update,
+	})
+	if err != nil {
+		log.Printf("Error encoding message for client %s: %v", client.ID, err)
+		return nil
+	}
+
+	// Small frames cost more CPU to deflate than they save on the wire
+	if m.compression.Enabled {
+		client.Conn.EnableWriteCompression(len(payload) >= m.compression.Threshold)
+	}
+
+	client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return client.Conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// trackUnacked records a critical update queued for the client as awaiting
+// acknowledgement. It is tracked from the moment it is queued, so updates
+// dropped or coalesced away before reaching the client count as undelivered.
+func (c *Client) trackUnacked(update VehicleUpdate, at time.Time) {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	if len(c.unacked) >= maxUnackedPerClient {
+		return
+	}
+	if c.unacked == nil {
+		c.unacked = make(map[string]*unackedUpdate)
+	}
+	c.unacked[update.EventID] = &unackedUpdate{update: update, sentAt: at}
+}
+
+// acknowledge marks a critical update as received by the client. Unknown
+// and repeated event IDs are ignored.
+func (c *Client) acknowledge(eventID string) {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	if _, tracked := c.unacked[eventID]; tracked {
+		delete(c.unacked, eventID)
+		c.acked++
+	}
+}
+
+// resendUnacked queues each critical update unacknowledged for longer than
+// the resend timeout for its client once more. An update that still goes
+// unacknowledged stays counted, but isn't sent a third time.
+func (m *Manager) resendUnacked(now time.Time) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, client := range m.clients {
+		client.ackMu.Lock()
+		for _, entry := range client.unacked {
+			if entry.resent || now.Sub(entry.sentAt) < m.ackResendTimeout {
+				continue
+			}
+			select {
+			case client.Send <- entry.update:
+				entry.resent = true
+				client.resent++
+			default:
+				// The buffer is full; try again on the next tick
+			}
+		}
+		client.ackMu.Unlock()
+	}
+}
+
 // healthCheck monitors client connections and removes inactive ones
 func (m *Manager) healthCheck() {
 	m.mutex.Lock()
@@ -370,8 +1055,8 @@ func (m *Manager) healthCheck() {
 
 	now := time.Now()
 	for clientID, client := range m.clients {
-		// Remove clients that haven't responded to ping in 90 seconds
-		if now.Sub(client.LastPing) > 90*time.Second {
+		// Remove clients that haven't responded to a ping within the timeout
+		if now.Sub(client.LastPing) > m.keepalive.ClientTimeout {
 			log.Printf("Client %s timed out, removing", clientID)
 			delete(m.clients, clientID)
 			close(client.Send)
@@ -1,9 +1,11 @@
 package websocket
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -24,15 +26,18 @@ func TestNewManager(t *testing.T) {
 
 func TestManagerStartStop(t *testing.T) {
 	manager := NewManager()
+	assert.False(t, manager.IsRunning())
 	
 	err := manager.Start()
 	assert.NoError(t, err)
+	assert.True(t, manager.IsRunning())
 	
 	// Give the manager a moment to start
 	time.Sleep(10 * time.Millisecond)
 	
 	err = manager.Stop()
 	assert.NoError(t, err)
+	assert.False(t, manager.IsRunning())
 }
 
 func TestRegisterClient(t *testing.T) {
@@ -229,6 +234,79 @@ func TestShouldSendToClient(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "severity filter - critical alert passes",
+			filters: VehicleFilters{
+				Severities: []string{"critical"},
+			},
+			update: VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "alert",
+				Data: map[string]interface{}{
+					"alertType": "fuel_theft",
+					"severity":  "critical",
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "severity filter - medium alert filtered out",
+			filters: VehicleFilters{
+				Severities: []string{"critical"},
+			},
+			update: VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "alert",
+				Data: map[string]interface{}{
+					"alertType": "speeding",
+					"severity":  "medium",
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "severity filter - non-alert update passes",
+			filters: VehicleFilters{
+				Severities: []string{"critical"},
+			},
+			update: VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "location",
+				Data:       map[string]interface{}{},
+			},
+			expected: true,
+		},
+		{
+			name: "alert type and severity - both must match",
+			filters: VehicleFilters{
+				AlertTypes: []string{"fuel_theft"},
+				Severities: []string{"critical"},
+			},
+			update: VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "alert",
+				Data: map[string]interface{}{
+					"alertType": "speeding",
+					"severity":  "critical",
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "severity filter - acknowledgement of medium alert filtered out",
+			filters: VehicleFilters{
+				Severities: []string{"critical"},
+			},
+			update: VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "alert_ack",
+				Data: map[string]interface{}{
+					"alertType": "speeding",
+					"severity":  "medium",
+				},
+			},
+			expected: false,
+		},
 	}
 	
 	for _, tt := range tests {
@@ -320,4 +398,608 @@ func TestHealthCheck(t *testing.T) {
 	assert.True(t, exists)
 	_, exists = manager.clients["old-client"]
 	assert.False(t, exists)
-}
\ No newline at end of file
+}
+func TestGetBroadcastHealth(t *testing.T) {
+	// The manager is not started so queued updates stay unprocessed
+	manager := NewManager()
+	
+	health := manager.GetBroadcastHealth()
+	assert.Equal(t, 0, health.QueueDepth)
+	assert.Equal(t, 1000, health.QueueCapacity)
+	assert.Equal(t, int64(0), health.TotalDropped)
+	
+	for i := 0; i < 3; i++ {
+		err := manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{
+			VehicleID: "vehicle1",
+			Priority:  PriorityMedium,
+		})
+		require.NoError(t, err)
+	}
+	
+	health = manager.GetBroadcastHealth()
+	assert.Equal(t, 3, health.QueueDepth)
+	
+	// Fill the channel and verify drops are attributed to the update priority
+	for i := health.QueueDepth; i < health.QueueCapacity; i++ {
+		require.NoError(t, manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1", Priority: PriorityLow}))
+	}
+	
+	err := manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1", Priority: PriorityCritical})
+	assert.Error(t, err)
+	manager.BroadcastBatchUpdates([]VehicleUpdate{
+		{VehicleID: "vehicle2", Priority: PriorityLow},
+		{VehicleID: "vehicle3", Priority: PriorityLow},
+	})
+	
+	health = manager.GetBroadcastHealth()
+	assert.Equal(t, health.QueueCapacity, health.QueueDepth)
+	assert.Equal(t, int64(1), health.DroppedByPriority[PriorityCritical])
+	assert.Equal(t, int64(2), health.DroppedByPriority[PriorityLow])
+	assert.Equal(t, int64(3), health.TotalDropped)
+}
+
+func TestGetBroadcastHealth_Latency(t *testing.T) {
+	manager := NewManager()
+	err := manager.Start()
+	require.NoError(t, err)
+	defer manager.Stop()
+	
+	err = manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1", Priority: PriorityHigh})
+	require.NoError(t, err)
+	
+	assert.Eventually(t, func() bool {
+		return manager.GetBroadcastHealth().BroadcastsProcessed == 1
+	}, time.Second, 10*time.Millisecond)
+	
+	health := manager.GetBroadcastHealth()
+	assert.Equal(t, 0, health.QueueDepth)
+	assert.GreaterOrEqual(t, health.AverageBroadcastLatencyMs, float64(0))
+}
+
+func TestManagerDrain_DeliversQueuedBroadcasts(t *testing.T) {
+	manager := NewManager()
+	require.NoError(t, manager.Start())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if err := manager.RegisterClient("drain-client", conn, VehicleFilters{}); err != nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return manager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	updates := make([]VehicleUpdate, 100)
+	for i := range updates {
+		updates[i] = VehicleUpdate{VehicleID: "vehicle1", UpdateType: "location", Priority: PriorityMedium}
+	}
+	require.NoError(t, manager.BroadcastBatchUpdates(updates))
+	require.NoError(t, manager.Drain(2*time.Second))
+
+	delivered := 0
+	for {
+		var message map[string]interface{}
+		if err := conn.ReadJSON(&message); err != nil {
+			break
+		}
+		delivered++
+	}
+
+	assert.Equal(t, len(updates), delivered)
+	assert.Equal(t, 0, manager.GetConnectedClients())
+
+	// Stopping after a drain must not double-close client channels
+	assert.NoError(t, manager.Stop())
+	assert.Error(t, manager.RegisterClient("late-client", nil, VehicleFilters{}))
+}
+
+func TestManagerStop_DeliversQueuedUpdateBeforeCloseFrame(t *testing.T) {
+	manager := NewManager()
+	manager.SetDrainTimeout(2 * time.Second)
+	require.NoError(t, manager.Start())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if err := manager.RegisterClient("stop-client", conn, VehicleFilters{}); err != nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return manager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1", UpdateType: "location", Priority: PriorityMedium}))
+	require.NoError(t, manager.Stop())
+
+	var message struct {
+		Type string        `json:"type"`
+		Data VehicleUpdate `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, MessageTypeVehicleUpdate, message.Type)
+	assert.Equal(t, "vehicle1", message.Data.VehicleID)
+
+	// The update is followed by a close frame, not a dropped connection
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "expected a going away close frame, got %v", err)
+
+	assert.ErrorIs(t, manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1"}), ErrManagerStopped)
+}
+
+func TestRegisterClientWithBufferSize_ToleratesBurst(t *testing.T) {
+	manager := NewManager()
+
+	const burst = DefaultClientBufferSize * 2
+
+	// Clients are added without writers so nothing drains their buffers
+	defaultClient := manager.newClient("default-client", nil, VehicleFilters{}, 0)
+	largeClient := manager.newClient("large-client", nil, VehicleFilters{}, burst)
+	manager.clients[defaultClient.ID] = defaultClient
+	manager.clients[largeClient.ID] = largeClient
+
+	for i := 0; i < burst; i++ {
+		manager.broadcastToClients(VehicleUpdate{VehicleID: "vehicle1", UpdateType: "location"})
+	}
+
+	assert.Equal(t, burst, cap(largeClient.Send))
+	assert.Len(t, largeClient.Send, burst)
+	assert.True(t, largeClient.IsActive, "custom buffer should absorb the burst")
+
+	assert.Len(t, defaultClient.Send, DefaultClientBufferSize)
+	assert.True(t, defaultClient.IsActive, "overflow should be coalesced, not dropped")
+
+	stats := manager.GetClientStats().Clients[defaultClient.ID]
+	assert.Equal(t, 1, stats.Pending)
+	assert.Equal(t, int64(burst-DefaultClientBufferSize-1), stats.Coalesced)
+	assert.Equal(t, int64(0), stats.Dropped)
+	assert.Equal(t, int64(0), manager.GetBroadcastHealth().ClientSendDrops)
+}
+
+func TestEnqueue_DropsUpdatesWithoutVehicleID(t *testing.T) {
+	manager := NewManager()
+	client := manager.newClient("client", nil, VehicleFilters{}, 1)
+	manager.clients[client.ID] = client
+
+	manager.broadcastToClients(VehicleUpdate{UpdateType: "status"})
+	manager.broadcastToClients(VehicleUpdate{UpdateType: "status"})
+
+	assert.False(t, client.IsActive)
+	assert.Equal(t, int64(1), manager.GetClientStats().Clients[client.ID].Dropped)
+	assert.Equal(t, int64(1), manager.GetBroadcastHealth().ClientSendDrops)
+}
+
+func TestMaintenanceEvents_OnlyReachOptedInClients(t *testing.T) {
+	manager := NewManager()
+	subscribed := manager.newClient("subscribed", nil, VehicleFilters{Maintenance: true, Fields: []string{"location"}, Compact: true}, 4)
+	other := manager.newClient("other", nil, VehicleFilters{}, 4)
+	manager.clients[subscribed.ID] = subscribed
+	manager.clients[other.ID] = other
+
+	manager.broadcastToClients(VehicleUpdate{
+		VehicleID:  "vehicle1",
+		UpdateType: UpdateTypeReminderOverdue,
+		Data:       map[string]interface{}{"reminderId": "reminder1", "types": []string{"oil_change"}},
+		Timestamp:  time.Now(),
+		Priority:   PriorityHigh,
+	})
+
+	select {
+	case received := <-subscribed.Send:
+		assert.Equal(t, UpdateTypeReminderOverdue, received.UpdateType)
+		assert.Equal(t, "vehicle1", received.VehicleID)
+		// Field filters and compaction leave maintenance events alone
+		assert.Equal(t, "reminder1", received.Data["reminderId"])
+	default:
+		t.Fatal("subscribed client did not receive the overdue reminder")
+	}
+	assert.Empty(t, other.Send)
+
+	// Vehicle filters still apply to subscribed clients
+	subscribed.Filters.VehicleIDs = []string{"vehicle2"}
+	manager.broadcastToClients(VehicleUpdate{VehicleID: "vehicle1", UpdateType: UpdateTypeMaintenanceDue, Timestamp: time.Now()})
+	assert.Empty(t, subscribed.Send)
+}
+
+func TestSlowClient_ReceivesLatestStatePerVehicle(t *testing.T) {
+	manager := NewManager()
+
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The client's writer is not started yet, so it falls behind like a slow reader
+	client := manager.newClient("slow-client", <-conns, VehicleFilters{}, 4)
+	manager.clients[client.ID] = client
+
+	const rounds = 50
+	vehicles := []string{"vehicle1", "vehicle2", "vehicle3"}
+	start := time.Now()
+	for i := 0; i < rounds; i++ {
+		for _, vehicleID := range vehicles {
+			manager.broadcastToClients(VehicleUpdate{
+				VehicleID:  vehicleID,
+				UpdateType: "location",
+				Data:       map[string]interface{}{"seq": i},
+				Timestamp:  start.Add(time.Duration(i) * time.Millisecond),
+				Priority:   PriorityMedium,
+			})
+		}
+	}
+
+	stats := manager.GetClientStats().Clients[client.ID]
+	assert.True(t, client.IsActive, "slow client should not be marked inactive")
+	assert.Equal(t, int64(0), stats.Dropped)
+	assert.Equal(t, len(vehicles), stats.Pending)
+	assert.Equal(t, int64(rounds*len(vehicles)-4-len(vehicles)), stats.Coalesced)
+
+	manager.writers.Add(1)
+	go func() {
+		defer manager.writers.Done()
+		manager.writeMessages(client)
+	}()
+	defer close(client.Send)
+
+	latest := make(map[string]int)
+	delivered := 0
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(latest) < len(vehicles) || latest["vehicle1"] < rounds-1 || latest["vehicle2"] < rounds-1 || latest["vehicle3"] < rounds-1 {
+		var message struct {
+			Data VehicleUpdate `json:"data"`
+		}
+		require.NoError(t, conn.ReadJSON(&message))
+		delivered++
+		latest[message.Data.VehicleID] = int(message.Data.Data["seq"].(float64))
+	}
+
+	for _, vehicleID := range vehicles {
+		assert.Equal(t, rounds-1, latest[vehicleID], vehicleID)
+	}
+	assert.Equal(t, 4+len(vehicles), delivered)
+	assert.Equal(t, 1, manager.GetConnectedClients())
+}
+
+func TestSlowClient_KeepsAlertsAndCriticalUpdates(t *testing.T) {
+	manager := NewManager()
+	client := manager.newClient("slow-client", nil, VehicleFilters{Maintenance: true}, 1)
+	manager.clients[client.ID] = client
+
+	start := time.Now()
+	broadcast := func(updateType, priority string, seq int) {
+		manager.broadcastToClients(VehicleUpdate{
+			VehicleID:  "vehicle1",
+			UpdateType: updateType,
+			Data:       map[string]interface{}{"seq": seq},
+			Timestamp:  start.Add(time.Duration(seq) * time.Millisecond),
+			Priority:   priority,
+		})
+	}
+	broadcast("location", PriorityMedium, 0) // fills the buffer
+	broadcast("location", PriorityMedium, 1)
+	broadcast("alert", PriorityHigh, 2)
+	broadcast("status", PriorityCritical, 3)
+	broadcast("location", PriorityMedium, 4)
+	broadcast("alert", PriorityHigh, 5)
+	broadcast(UpdateTypeMaintenanceDue, PriorityMedium, 6)
+	broadcast("location", PriorityMedium, 7)
+
+	stats := manager.GetClientStats().Clients[client.ID]
+	assert.True(t, client.IsActive)
+	assert.Equal(t, 5, stats.Pending)
+	assert.Equal(t, int64(2), stats.Coalesced, "only location updates are coalesced")
+
+	var delivered []string
+	for _, update := range append([]VehicleUpdate{<-client.Send}, client.takePending()...) {
+		delivered = append(delivered, fmt.Sprintf("%s:%d", update.UpdateType, update.Data["seq"]))
+	}
+	assert.Equal(t, []string{"location:0", "alert:2", "status:3", "alert:5", "maintenance_due:6", "location:7"}, delivered)
+}
+
+func TestClientBufferSizeFor(t *testing.T) {
+	manager := NewManager()
+	manager.SetClientBufferSize(200)
+
+	assert.Equal(t, 200, manager.clientBufferSizeFor(VehicleFilters{}))
+	assert.Equal(t, 200, manager.clientBufferSizeFor(VehicleFilters{VehicleIDs: []string{"v1"}}))
+
+	manager.SetAdaptiveClientBuffers(true)
+
+	assert.Equal(t, 800, manager.clientBufferSizeFor(VehicleFilters{}), "whole-fleet subscription")
+	assert.Equal(t, 800, manager.clientBufferSizeFor(VehicleFilters{Statuses: []string{"active"}}), "status filters still span the fleet")
+	assert.Equal(t, minClientBufferSize, manager.clientBufferSizeFor(VehicleFilters{VehicleIDs: []string{"v1"}}))
+	assert.Equal(t, 80, manager.clientBufferSizeFor(VehicleFilters{VehicleIDs: []string{"v1", "v2", "v3", "v4", "v5"}}))
+
+	many := make([]string, 50)
+	assert.Equal(t, 200, manager.clientBufferSizeFor(VehicleFilters{VehicleIDs: many}), "capped at the configured size")
+}
+
+// dialCompressionTestClient starts a server registering clients on manager and
+// connects to it, optionally offering permessage-deflate
+func dialCompressionTestClient(t *testing.T, manager *Manager, offerCompression bool) (*websocket.Conn, *http.Response) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if err := manager.RegisterClient(r.URL.Query().Get("id"), conn, VehicleFilters{}); err != nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{EnableCompression: offerCompression}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?id=" + t.Name()
+	conn, resp, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, resp
+}
+
+func TestManagerCompression_RoundTrip(t *testing.T) {
+	for _, offerCompression := range []bool{true, false} {
+		t.Run(map[bool]string{true: "deflate", false: "plain"}[offerCompression], func(t *testing.T) {
+			manager := NewManager()
+			require.NoError(t, manager.SetCompression(CompressionConfig{Enabled: true, Level: 6, Threshold: 64}))
+			require.NoError(t, manager.Start())
+			defer manager.Stop()
+
+			conn, resp := dialCompressionTestClient(t, manager, offerCompression)
+
+			extensions := resp.Header.Get("Sec-Websocket-Extensions")
+			if offerCompression {
+				assert.Contains(t, extensions, "permessage-deflate")
+			} else {
+				assert.Empty(t, extensions, "clients without the extension must still connect")
+			}
+
+			require.Eventually(t, func() bool {
+				return manager.GetConnectedClients() == 1
+			}, time.Second, 10*time.Millisecond)
+
+			// One frame above the threshold and one below it
+			large := VehicleUpdate{
+				VehicleID:  "vehicle1",
+				UpdateType: "location",
+				Data:       map[string]interface{}{"address": strings.Repeat("Kenyatta Avenue, Nairobi ", 40)},
+				Priority:   PriorityMedium,
+			}
+			small := VehicleUpdate{VehicleID: "v2", Priority: PriorityMedium}
+			require.NoError(t, manager.BroadcastBatchUpdates([]VehicleUpdate{large, small}))
+
+			for _, expected := range []VehicleUpdate{large, small} {
+				var message struct {
+					Type string        `json:"type"`
+					Data VehicleUpdate `json:"data"`
+				}
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				require.NoError(t, conn.ReadJSON(&message))
+				assert.Equal(t, MessageTypeVehicleUpdate, message.Type)
+				assert.Equal(t, expected.VehicleID, message.Data.VehicleID)
+				assert.Equal(t, expected.Data["address"], message.Data.Data["address"])
+			}
+		})
+	}
+}
+
+func TestManagerSetCompression_InvalidLevel(t *testing.T) {
+	manager := NewManager()
+
+	assert.Error(t, manager.SetCompression(CompressionConfig{Enabled: true, Level: 12}))
+	assert.False(t, manager.upgrader.EnableCompression)
+
+	assert.NoError(t, manager.SetCompression(CompressionConfig{Enabled: false, Level: 12}), "level is ignored when disabled")
+}
+
+func TestManagerMaxClients_RejectsClientOverLimit(t *testing.T) {
+	manager := NewManager()
+	manager.SetMaxClients(2)
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+
+	var clientCount int32
+	registerErrs := make(chan error, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+
+		clientID := fmt.Sprintf("client-%d", atomic.AddInt32(&clientCount, 1))
+		err = manager.RegisterClient(clientID, conn, VehicleFilters{})
+		if err != nil {
+			conn.Close()
+		}
+		registerErrs <- err
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	accepted := make([]*websocket.Conn, 2)
+	for i := range accepted {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, <-registerErrs)
+		accepted[i] = conn
+	}
+	assert.Eventually(t, func() bool { return manager.GetConnectedClients() == 2 }, time.Second, 10*time.Millisecond)
+	assert.True(t, manager.AtCapacity())
+
+	// The third client is told to try again later
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.ErrorIs(t, <-registerErrs, ErrTooManyClients)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), "unexpected error: %v", err)
+
+	stats := manager.GetClientStats()
+	assert.Equal(t, 2, stats.TotalClients)
+	assert.Equal(t, 2, stats.MaxClients)
+	assert.Equal(t, int64(1), stats.RejectedClients)
+
+	// Once a client leaves, its slot is free again
+	accepted[0].Close()
+	assert.Eventually(t, func() bool { return !manager.AtCapacity() }, time.Second, 10*time.Millisecond)
+	conn, _, err = websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.NoError(t, <-registerErrs)
+}
+
+func TestKeepaliveConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultKeepaliveConfig().Validate())
+	assert.NoError(t, KeepaliveConfig{PingInterval: 4 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second}.Validate())
+
+	invalid := []KeepaliveConfig{
+		{},
+		{PingInterval: 5 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second},
+		{PingInterval: 6 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second},
+		{PingInterval: 4 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 5 * time.Second},
+		{PingInterval: -time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second},
+	}
+	for _, config := range invalid {
+		_, err := NewManagerWithKeepalive(config)
+		assert.ErrorIs(t, err, ErrInvalidKeepalive, "%+v", config)
+	}
+}
+
+func TestHealthCheck_UsesConfiguredClientTimeout(t *testing.T) {
+	manager, err := NewManagerWithKeepalive(KeepaliveConfig{PingInterval: 2 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second})
+	require.NoError(t, err)
+
+	manager.mutex.Lock()
+	manager.clients["stale"] = &Client{ID: "stale", Send: make(chan VehicleUpdate, 1), LastPing: time.Now().Add(-10 * time.Second)}
+	manager.clients["fresh"] = &Client{ID: "fresh", Send: make(chan VehicleUpdate, 1), LastPing: time.Now().Add(-6 * time.Second)}
+	manager.mutex.Unlock()
+
+	manager.healthCheck()
+
+	assert.Contains(t, manager.clients, "fresh")
+	assert.NotContains(t, manager.clients, "stale")
+}
+
+func TestManagerKeepalive_ReapsClientMissingPings(t *testing.T) {
+	manager, err := NewManagerWithKeepalive(KeepaliveConfig{PingInterval: 2 * time.Second, ReadDeadline: 5 * time.Second, ClientTimeout: 8 * time.Second})
+	require.NoError(t, err)
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := manager.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		manager.RegisterClient("cellular-client", conn, VehicleFilters{})
+	}))
+	defer server.Close()
+
+	// The client never reads, so the server's pings go unanswered
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	connected := time.Now()
+	require.Eventually(t, func() bool { return manager.GetConnectedClients() == 1 }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return manager.GetConnectedClients() == 0 }, 8*time.Second, 50*time.Millisecond)
+
+	// Reaped by the 5s read deadline, not right away and not at the 8s timeout
+	elapsed := time.Since(connected)
+	assert.GreaterOrEqual(t, elapsed, 4*time.Second)
+	assert.Less(t, elapsed, 7*time.Second)
+}
+
+func TestCriticalUpdate_AckClearsUnackedCount(t *testing.T) {
+	manager := NewManager()
+	require.NoError(t, manager.Start())
+	defer manager.Stop()
+
+	conn, _ := dialCompressionTestClient(t, manager, false)
+	require.Eventually(t, func() bool {
+		return manager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, manager.BroadcastVehicleUpdate("vehicle1", VehicleUpdate{VehicleID: "vehicle1", UpdateType: "alert", Priority: PriorityCritical}))
+
+	var message struct {
+		Type string        `json:"type"`
+		Data VehicleUpdate `json:"data"`
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	require.NoError(t, conn.ReadJSON(&message))
+	require.NotEmpty(t, message.Data.EventID, "critical updates carry an event ID")
+	assert.Equal(t, 1, manager.GetClientStats().UnackedCritical)
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": MessageTypeAck, "eventId": message.Data.EventID}))
+
+	require.Eventually(t, func() bool {
+		return manager.GetClientStats().UnackedCritical == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), manager.GetClientStats().Clients[t.Name()].Acked)
+}
+
+func TestResendUnacked_ResendsCriticalUpdateOnce(t *testing.T) {
+	manager := NewManager()
+	manager.SetAckResendTimeout(time.Second)
+	client := &Client{
+		ID:       "critical-client",
+		Send:     make(chan VehicleUpdate, 4),
+		LastPing: time.Now(),
+		IsActive: true,
+	}
+	manager.clients[client.ID] = client
+
+	manager.broadcastToClients(VehicleUpdate{VehicleID: "vehicle1", UpdateType: "alert", Priority: PriorityCritical})
+	manager.broadcastToClients(VehicleUpdate{VehicleID: "vehicle1", UpdateType: "location", Priority: PriorityHigh})
+	sent := <-client.Send
+	<-client.Send
+	require.NotEmpty(t, sent.EventID)
+
+	now := time.Now()
+	manager.resendUnacked(now.Add(500 * time.Millisecond))
+	assert.Empty(t, client.Send, "not resent before the timeout")
+
+	manager.resendUnacked(now.Add(2 * time.Second))
+	require.Len(t, client.Send, 1)
+	assert.Equal(t, sent.EventID, (<-client.Send).EventID)
+
+	manager.resendUnacked(now.Add(time.Minute))
+	assert.Empty(t, client.Send, "resent only once")
+
+	stats := manager.GetClientStats().Clients[client.ID]
+	assert.Equal(t, 1, stats.Unacked)
+	assert.Equal(t, int64(1), stats.Resent)
+
+	client.acknowledge(sent.EventID)
+	client.acknowledge(sent.EventID)
+	assert.Equal(t, 0, manager.GetClientStats().UnackedCritical)
+	assert.Equal(t, int64(1), manager.GetClientStats().Clients[client.ID].Acked)
+}
@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"strings"
+	"sync"
+)
+
+// vehicleTagIndex holds each vehicle's tags. Updates don't carry tags, so tag
+// filters look the vehicle up here instead.
+type vehicleTagIndex struct {
+	mu   sync.RWMutex
+	tags map[string][]string
+}
+
+// SetVehicleTags records a vehicle's current tags; no tags removes it from the index
+func (m *Manager) SetVehicleTags(vehicleID string, tags []string) {
+	m.tags.mu.Lock()
+	defer m.tags.mu.Unlock()
+
+	if m.tags.tags == nil {
+		m.tags.tags = make(map[string][]string)
+	}
+	if len(tags) == 0 {
+		delete(m.tags.tags, vehicleID)
+		return
+	}
+	m.tags.tags[vehicleID] = append([]string(nil), tags...)
+}
+
+// LoadVehicleTags replaces the whole index, keyed by vehicle ID
+func (m *Manager) LoadVehicleTags(tags map[string][]string) {
+	index := make(map[string][]string, len(tags))
+	for vehicleID, vehicleTags := range tags {
+		if len(vehicleTags) > 0 {
+			index[vehicleID] = append([]string(nil), vehicleTags...)
+		}
+	}
+
+	m.tags.mu.Lock()
+	m.tags.tags = index
+	m.tags.mu.Unlock()
+}
+
+// vehicleHasTag reports whether the vehicle carries any of the wanted tags.
+// Vehicles the index doesn't know have no tags and match no tag filter.
+func (m *Manager) vehicleHasTag(vehicleID string, wanted []string) bool {
+	m.tags.mu.RLock()
+	defer m.tags.mu.RUnlock()
+
+	return HasAnyTag(m.tags.tags[vehicleID], wanted)
+}
+
+// HasAnyTag reports whether tags contains any of wanted, ignoring case
+func HasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(tag, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
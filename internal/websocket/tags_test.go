@@ -0,0 +1,36 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldSendToClient_Tags(t *testing.T) {
+	manager := NewManager()
+	manager.LoadVehicleTags(map[string][]string{
+		"v1": {"depot-north"},
+		"v2": {"depot-south", "refrigerated"},
+	})
+	client := &Client{Filters: VehicleFilters{Tags: []string{"Depot-North", "refrigerated"}}}
+
+	assert.True(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v1", UpdateType: "location"}))
+	assert.True(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v2", UpdateType: "fuel"}))
+	assert.False(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v3", UpdateType: "location"}))
+
+	// Retagging takes effect for updates that follow
+	manager.SetVehicleTags("v1", []string{"depot-south"})
+	assert.False(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v1", UpdateType: "location"}))
+
+	manager.SetVehicleTags("v2", nil)
+	assert.False(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v2", UpdateType: "fuel"}))
+}
+
+func TestShouldSendToClient_UntaggedFiltersIgnoreTags(t *testing.T) {
+	manager := NewManager()
+	manager.SetVehicleTags("v1", []string{"depot-north"})
+	client := &Client{Filters: VehicleFilters{}}
+
+	assert.True(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v1", UpdateType: "location"}))
+	assert.True(t, manager.shouldSendToClient(client, VehicleUpdate{VehicleID: "v2", UpdateType: "location"}))
+}
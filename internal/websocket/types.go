@@ -1,6 +1,8 @@
 package websocket
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -12,15 +14,49 @@ type VehicleFilters struct {
 	Statuses   []string `json:"statuses,omitempty"`
 	Drivers    []string `json:"drivers,omitempty"`
 	AlertTypes []string `json:"alertTypes,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+	// Tags limits updates to vehicles carrying any of these tags, e.g. a depot
+	Tags []string `json:"tags,omitempty"`
+	// BBox limits location-carrying updates to a map viewport
+	BBox *BoundingBox `json:"bbox,omitempty"`
+	// Fields limits vehicle updates to those changing one of these fields
+	// (see SubscribableFields); alerts and maintenance events are not affected
+	Fields []string `json:"fields,omitempty"`
+	// Compact trims vehicle update data down to Fields
+	Compact bool `json:"compact,omitempty"`
+	// Maintenance opts in to maintenance due and overdue reminder events,
+	// which aren't sent otherwise
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// BoundingBox is a geographic viewport in decimal degrees. A MinLng greater
+// than MaxLng describes a box crossing the antimeridian.
+type BoundingBox struct {
+	MinLat float64 `json:"minLat"`
+	MinLng float64 `json:"minLng"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLng float64 `json:"maxLng"`
+}
+
+// Contains reports whether a point lies inside the box, edges included
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLng <= b.MaxLng {
+		return lng >= b.MinLng && lng <= b.MaxLng
+	}
+	return lng >= b.MinLng || lng <= b.MaxLng
 }
 
 // VehicleUpdate represents a vehicle update message
 type VehicleUpdate struct {
 	VehicleID  string                 `json:"vehicleId"`
-	UpdateType string                 `json:"updateType"` // "location", "fuel", "status", "alert"
+	UpdateType string                 `json:"updateType"` // "location", "fuel", "status", "alert", "alert_ack", "maintenance_due", "reminder_overdue"
 	Data       map[string]interface{} `json:"data"`
 	Timestamp  time.Time              `json:"timestamp"`
-	Priority   string                 `json:"priority"` // "low", "medium", "high", "critical"
+	Priority   string                 `json:"priority"`          // "low", "medium", "high", "critical"
+	EventID    string                 `json:"eventId,omitempty"` // set on critical updates for clients to acknowledge
 }
 
 // Client represents a WebSocket client connection
@@ -31,6 +67,29 @@ type Client struct {
 	Send       chan VehicleUpdate
 	LastPing   time.Time
 	IsActive   bool
+
+	// Updates held back while Send is full. Vehicle field updates keep only
+	// the latest per vehicle and update type; alerts and critical updates are
+	// all kept, in order. heldVehicles counts the held updates per vehicle.
+	pendingMu    sync.Mutex
+	pending      map[string]VehicleUpdate
+	urgent       []VehicleUpdate
+	heldVehicles map[string]int
+	coalesced    int64
+	dropped      int64
+
+	// Critical updates written but not yet acknowledged, keyed by event ID
+	ackMu   sync.Mutex
+	unacked map[string]*unackedUpdate
+	acked   int64
+	resent  int64
+}
+
+// unackedUpdate is a critical update awaiting the client's acknowledgement
+type unackedUpdate struct {
+	update VehicleUpdate
+	sentAt time.Time
+	resent bool
 }
 
 // WebSocketManager interface defines the contract for WebSocket management
@@ -45,11 +104,104 @@ type WebSocketManager interface {
 	GetClientStats() ClientStats
 }
 
+// CompressionConfig controls permessage-deflate compression of outbound frames.
+// Compression is negotiated per connection, so clients without the extension
+// keep receiving uncompressed frames.
+type CompressionConfig struct {
+	Enabled bool `json:"enabled"`
+	// Level is a compress/flate level from -2 (Huffman only) to 9 (best compression)
+	Level int `json:"level"`
+	// Threshold is the payload size in bytes below which frames are sent uncompressed
+	Threshold int `json:"threshold"`
+}
+
+// KeepaliveConfig controls how the manager detects dead connections. Each
+// client is pinged every PingInterval; a client that sends nothing, pongs
+// included, for ReadDeadline is disconnected, and the health check removes
+// clients that haven't answered a ping for ClientTimeout. Clients on cellular
+// networks, where idle connections are dropped sooner, need shorter timings.
+type KeepaliveConfig struct {
+	PingInterval  time.Duration `json:"pingInterval"`
+	ReadDeadline  time.Duration `json:"readDeadline"`
+	ClientTimeout time.Duration `json:"clientTimeout"`
+}
+
+// DefaultKeepaliveConfig returns the keepalive timings used by NewManager:
+// a ping every 54s, a 60s read deadline and a 90s health-check timeout
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval:  54 * time.Second,
+		ReadDeadline:  60 * time.Second,
+		ClientTimeout: 90 * time.Second,
+	}
+}
+
+// Validate checks that the timings are positive and that
+// PingInterval < ReadDeadline < ClientTimeout, so a healthy client always has
+// a ping to answer before its deadline passes
+func (c KeepaliveConfig) Validate() error {
+	if c.PingInterval <= 0 || c.ReadDeadline <= 0 || c.ClientTimeout <= 0 {
+		return fmt.Errorf("%w: timings must be positive", ErrInvalidKeepalive)
+	}
+	if c.PingInterval >= c.ReadDeadline {
+		return fmt.Errorf("%w: ping interval %s must be shorter than read deadline %s", ErrInvalidKeepalive, c.PingInterval, c.ReadDeadline)
+	}
+	if c.ReadDeadline >= c.ClientTimeout {
+		return fmt.Errorf("%w: read deadline %s must be shorter than client timeout %s", ErrInvalidKeepalive, c.ReadDeadline, c.ClientTimeout)
+	}
+	return nil
+}
+
+// healthCheckInterval is how often the health check runs, often enough that
+// timed out clients are removed within a third of ClientTimeout
+func (c KeepaliveConfig) healthCheckInterval() time.Duration {
+	return c.ClientTimeout / 3
+}
+
 // ClientStats provides statistics about connected clients
 type ClientStats struct {
 	TotalClients    int `json:"totalClients"`
 	ActiveClients   int `json:"activeClients"`
 	InactiveClients int `json:"inactiveClients"`
+	// MaxClients is the connected client limit; 0 means unlimited
+	MaxClients int `json:"maxClients"`
+	// RejectedClients counts clients refused because the limit was reached
+	RejectedClients int64 `json:"rejectedClients"`
+	// UnackedCritical is the number of critical updates written to clients
+	// that haven't been acknowledged yet, across all clients
+	UnackedCritical int `json:"unackedCritical"`
+	// Clients holds per-client delivery counters keyed by client ID
+	Clients map[string]ClientDeliveryStats `json:"clients"`
+}
+
+// ClientDeliveryStats describes how a slow client's updates were coalesced
+type ClientDeliveryStats struct {
+	// Pending is the number of updates waiting for buffer space
+	Pending int `json:"pending"`
+	// Coalesced counts updates replaced by a newer update of the same type for the same vehicle
+	Coalesced int64 `json:"coalesced"`
+	// Dropped counts updates that could not be buffered or coalesced
+	Dropped int64 `json:"dropped"`
+	// Unacked is the number of critical updates written but not acknowledged
+	Unacked int `json:"unacked"`
+	// Acked counts critical updates the client acknowledged
+	Acked int64 `json:"acked"`
+	// Resent counts critical updates written again for lack of an acknowledgement
+	Resent int64 `json:"resent"`
+}
+
+// BroadcastHealth describes the state of the broadcast pipeline
+type BroadcastHealth struct {
+	QueueDepth                int              `json:"queueDepth"`
+	QueueCapacity             int              `json:"queueCapacity"`
+	TotalClients              int              `json:"totalClients"`
+	ActiveClients             int              `json:"activeClients"`
+	InactiveClients           int              `json:"inactiveClients"`
+	DroppedByPriority         map[string]int64 `json:"droppedByPriority"`
+	TotalDropped              int64            `json:"totalDropped"`
+	ClientSendDrops           int64            `json:"clientSendDrops"`
+	BroadcastsProcessed       int64            `json:"broadcastsProcessed"`
+	AverageBroadcastLatencyMs float64          `json:"averageBroadcastLatencyMs"`
 }
 
 // Message types for WebSocket communication
@@ -59,6 +211,14 @@ const (
 	MessageTypePing          = "ping"
 	MessageTypePong          = "pong"
 	MessageTypeError         = "error"
+	MessageTypeAck           = "ack" // sent by clients to acknowledge a critical update's eventId
+)
+
+// Update types of maintenance events, sent only to clients with the
+// Maintenance filter set
+const (
+	UpdateTypeMaintenanceDue  = "maintenance_due"
+	UpdateTypeReminderOverdue = "reminder_overdue"
 )
 
 // Priority levels for message handling
@@ -14,6 +14,9 @@ func DefaultBatchConfig() BatchConfig {
 		MaxWaitTime:   5 * time.Minute,      // 5 minutes max wait time
 		RetryAttempts: 3,                    // 3 retry attempts
 		RetryBackoff:  1 * time.Second,      // 1 second initial backoff
+		RestartOnPanic: true,                // keep batching after a worker panic
+		RestartBackoff: 1 * time.Second,     // 1 second before restarting
+		DeadLetterCapacity: DefaultDeadLetterCapacity,
 	}
 }
 
@@ -56,6 +59,32 @@ func LoadBatchConfigFromEnv() BatchConfig {
 		}
 	}
 
+	// Load worker watchdog settings
+	if val := os.Getenv("BATCH_RESTART_ON_PANIC"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			config.RestartOnPanic = enabled
+		}
+	}
+
+	if val := os.Getenv("BATCH_MAX_WORKER_RESTARTS"); val != "" {
+		if restarts, err := strconv.Atoi(val); err == nil && restarts >= 0 {
+			config.MaxWorkerRestarts = restarts
+		}
+	}
+
+	if val := os.Getenv("BATCH_RESTART_BACKOFF"); val != "" {
+		if backoff, err := time.ParseDuration(val); err == nil && backoff >= 0 {
+			config.RestartBackoff = backoff
+		}
+	}
+
+	// Load dead-letter queue capacity
+	if val := os.Getenv("BATCH_DEAD_LETTER_CAPACITY"); val != "" {
+		if capacity, err := strconv.Atoi(val); err == nil && capacity > 0 {
+			config.DeadLetterCapacity = capacity
+		}
+	}
+
 	return config
 }
 
@@ -81,5 +110,13 @@ func ValidateConfig(config BatchConfig) error {
 		return ErrInvalidRetryBackoff
 	}
 
+	if config.MaxWorkerRestarts < 0 {
+		return ErrInvalidMaxRestarts
+	}
+
+	if config.RestartBackoff < 0 {
+		return ErrInvalidRestartBackoff
+	}
+
 	return nil
 }
\ No newline at end of file
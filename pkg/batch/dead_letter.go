@@ -0,0 +1,291 @@
+package batch
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultDeadLetterCapacity is the number of dead letters kept when the
+// configuration doesn't set one
+const DefaultDeadLetterCapacity = 1000
+
+// DeadLetter is a vehicle update that failed permanently, after the batch
+// retries and the individual fallback
+type DeadLetter struct {
+	ID        string            `json:"id"`
+	VehicleID string            `json:"vehicleId"`
+	Update    VehicleUpdateData `json:"update"`
+	Error     string            `json:"error"`
+	FailedAt  time.Time         `json:"failedAt"`
+	Retries   int               `json:"retries"` // times the update was retried from the queue before failing again
+}
+
+// DeadLetterRetryResult reports what happened to the dead letters selected for a retry
+type DeadLetterRetryResult struct {
+	Requeued []string          `json:"requeued"`           // dead letters queued for the next batch
+	Rejected map[string]string `json:"rejected,omitempty"` // dead letters kept because the queue refused them, with the reason
+	// Superseded lists dead letters dropped because the vehicle has a newer
+	// pending or stored update, which replaying them would overwrite
+	Superseded []string `json:"superseded,omitempty"`
+	NotFound   []string `json:"notFound,omitempty"`
+}
+
+// lastUpdateSource is implemented by repositories that can tell when a
+// vehicle's stored state was last updated
+type lastUpdateSource interface {
+	GetVehicleLastUpdate(vehicleID string) (time.Time, error)
+}
+
+// DeadLetterQueue keeps permanently failed updates for operators to retry or
+// discard. It holds at most capacity entries; the oldest are dropped first.
+type DeadLetterQueue struct {
+	mu       sync.Mutex
+	capacity int
+	entries  []DeadLetter // oldest first
+	dropped  int64
+}
+
+// NewDeadLetterQueue creates a dead-letter queue, using
+// DefaultDeadLetterCapacity when capacity isn't positive
+func NewDeadLetterQueue(capacity int) *DeadLetterQueue {
+	if capacity <= 0 {
+		capacity = DefaultDeadLetterCapacity
+	}
+	return &DeadLetterQueue{capacity: capacity}
+}
+
+// Add records a dead letter, assigning it an ID when it has none
+func (q *DeadLetterQueue) Add(letter DeadLetter) {
+	if letter.ID == "" {
+		letter.ID = uuid.NewString()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.entries) >= q.capacity {
+		q.entries = q.entries[1:]
+		q.dropped++
+	}
+	q.entries = append(q.entries, letter)
+}
+
+// List returns up to limit dead letters starting at offset, oldest first,
+// and the total number held
+func (q *DeadLetterQueue) List(offset, limit int) ([]DeadLetter, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	total := len(q.entries)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total || limit <= 0 {
+		return []DeadLetter{}, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	page := make([]DeadLetter, end-offset)
+	copy(page, q.entries[offset:end])
+	return page, total
+}
+
+// Take removes and returns the dead letters with the given IDs, or all of them
+// when ids is empty, along with the requested IDs that weren't found
+func (q *DeadLetterQueue) Take(ids []string) ([]DeadLetter, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(ids) == 0 {
+		taken := q.entries
+		q.entries = nil
+		return taken, nil
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var taken []DeadLetter
+	kept := q.entries[:0]
+	for _, letter := range q.entries {
+		if wanted[letter.ID] {
+			taken = append(taken, letter)
+			delete(wanted, letter.ID)
+		} else {
+			kept = append(kept, letter)
+		}
+	}
+	q.entries = kept
+
+	var notFound []string
+	for _, id := range ids {
+		if wanted[id] {
+			notFound = append(notFound, id)
+			delete(wanted, id)
+		}
+	}
+	return taken, notFound
+}
+
+// Len returns the number of dead letters held
+func (q *DeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Dropped returns how many dead letters were dropped to stay within capacity
+func (q *DeadLetterQueue) Dropped() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// ListDeadLetters returns a page of the permanently failed updates, oldest
+// first, and the total number held
+func (bp *DefaultBatchProcessor) ListDeadLetters(offset, limit int) ([]DeadLetter, int) {
+	return bp.deadLetters.List(offset, limit)
+}
+
+// RetryDeadLetters queues the dead letters with the given IDs, or all of them
+// when ids is empty, for the next batch. A requeued dead letter leaves the
+// queue; if its update fails permanently again it comes back under a new ID
+// with its retry count increased. Dead letters the update queue refuses stay
+// in the dead-letter queue. Dead letters older than the vehicle's pending or
+// stored update are dropped instead, so a retry never rolls a vehicle back.
+func (bp *DefaultBatchProcessor) RetryDeadLetters(ids []string) DeadLetterRetryResult {
+	letters, notFound := bp.deadLetters.Take(ids)
+	result := DeadLetterRetryResult{
+		Requeued: make([]string, 0, len(letters)),
+		NotFound: notFound,
+	}
+
+	for _, letter := range letters {
+		if bp.superseded(letter) {
+			result.Superseded = append(result.Superseded, letter.ID)
+			continue
+		}
+
+		// Track the retry before queuing it, since the worker may apply it right away
+		bp.retryingMux.Lock()
+		previous, hadPrevious := bp.retrying[letter.VehicleID]
+		bp.retrying[letter.VehicleID] = letter.Retries + 1
+		bp.retryingMux.Unlock()
+
+		if err := bp.AddUpdate(letter.VehicleID, letter.Update); err != nil {
+			bp.retryingMux.Lock()
+			if hadPrevious {
+				bp.retrying[letter.VehicleID] = previous
+			} else {
+				delete(bp.retrying, letter.VehicleID)
+			}
+			bp.retryingMux.Unlock()
+
+			bp.deadLetters.Add(letter)
+			if result.Rejected == nil {
+				result.Rejected = make(map[string]string)
+			}
+			result.Rejected[letter.ID] = err.Error()
+			continue
+		}
+
+		result.Requeued = append(result.Requeued, letter.ID)
+	}
+
+	bp.statsMux.Lock()
+	bp.stats.DeadLetterRetries += int64(len(result.Requeued))
+	bp.statsMux.Unlock()
+
+	if len(letters) > 0 {
+		log.Printf("Retried %d dead letters: %d requeued, %d rejected, %d superseded",
+			len(letters), len(result.Requeued), len(result.Rejected), len(result.Superseded))
+	}
+	return result
+}
+
+// superseded reports whether a newer update for the dead letter's vehicle is
+// pending in the current batch or already stored
+func (bp *DefaultBatchProcessor) superseded(letter DeadLetter) bool {
+	bp.updatesMux.RLock()
+	pending, isPending := bp.updates[letter.VehicleID]
+	bp.updatesMux.RUnlock()
+	if isPending && pending.Timestamp.After(letter.Update.Timestamp) {
+		return true
+	}
+
+	source, ok := bp.repository.(lastUpdateSource)
+	if !ok {
+		return false
+	}
+	lastUpdate, err := source.GetVehicleLastUpdate(letter.VehicleID)
+	if err != nil {
+		// Let the retry decide; a vehicle that's gone fails it again
+		return false
+	}
+	return lastUpdate.After(letter.Update.Timestamp)
+}
+
+// DiscardDeadLetters drops the dead letters with the given IDs, or all of them
+// when ids is empty. It returns how many were dropped and the requested IDs
+// that weren't found.
+func (bp *DefaultBatchProcessor) DiscardDeadLetters(ids []string) (int, []string) {
+	letters, notFound := bp.deadLetters.Take(ids)
+	if len(letters) > 0 {
+		log.Printf("Discarded %d dead letters", len(letters))
+	}
+	return len(letters), notFound
+}
+
+// deadLetter records a permanently failed update, counting it as a failed
+// retry when it came from the dead-letter queue
+func (bp *DefaultBatchProcessor) deadLetter(vehicleID string, update VehicleUpdateData, err error) {
+	bp.retryingMux.Lock()
+	retries, retried := bp.retrying[vehicleID]
+	delete(bp.retrying, vehicleID)
+	bp.retryingMux.Unlock()
+
+	bp.deadLetters.Add(DeadLetter{
+		VehicleID: vehicleID,
+		Update:    update,
+		Error:     err.Error(),
+		FailedAt:  time.Now(),
+		Retries:   retries,
+	})
+
+	bp.statsMux.Lock()
+	defer bp.statsMux.Unlock()
+	bp.stats.DeadLetters++
+	if retried {
+		bp.stats.DeadLetterRetryFailures++
+	}
+}
+
+// settleRetries counts the applied updates of vehicles with a dead letter
+// retry in flight as successful retries
+func (bp *DefaultBatchProcessor) settleRetries(applied map[string]VehicleUpdateData) {
+	bp.retryingMux.Lock()
+	if len(bp.retrying) == 0 {
+		bp.retryingMux.Unlock()
+		return
+	}
+	var succeeded int64
+	for vehicleID := range applied {
+		if _, ok := bp.retrying[vehicleID]; ok {
+			delete(bp.retrying, vehicleID)
+			succeeded++
+		}
+	}
+	bp.retryingMux.Unlock()
+
+	if succeeded > 0 {
+		bp.statsMux.Lock()
+		bp.stats.DeadLetterRetrySuccesses += succeeded
+		bp.statsMux.Unlock()
+	}
+}
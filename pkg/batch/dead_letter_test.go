@@ -0,0 +1,230 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeadLetterTestProcessor(repo VehicleRepository) *DefaultBatchProcessor {
+	return NewBatchProcessor(BatchConfig{
+		MaxBatchSize:  10,
+		BatchInterval: 20 * time.Millisecond,
+		MaxWaitTime:   time.Second,
+		RetryAttempts: 0,
+	}, repo)
+}
+
+// failPermanently pushes updates for the given vehicles through a batch whose
+// writes all fail, leaving one dead letter per vehicle
+func failPermanently(t *testing.T, processor *DefaultBatchProcessor, repo *MockVehicleRepository, vehicleIDs ...string) {
+	repo.On("UpdateVehiclesBatch", mock.Anything).Return(errors.New("database unavailable")).Once()
+	for _, vehicleID := range vehicleIDs {
+		repo.On("UpdateVehicle", vehicleID, mock.Anything).Return(errors.New("write conflict")).Once()
+		processor.addToCurrentBatch(vehicleID, VehicleUpdateData{Speed: intPtr(50), Timestamp: time.Now()})
+	}
+	require.Error(t, processor.ProcessBatch())
+}
+
+func TestDeadLetterQueue_ListPaginatesOldestFirst(t *testing.T) {
+	queue := NewDeadLetterQueue(0)
+	for i := 0; i < 5; i++ {
+		queue.Add(DeadLetter{VehicleID: fmt.Sprintf("vehicle%d", i)})
+	}
+
+	page, total := queue.List(0, 2)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "vehicle0", page[0].VehicleID)
+	assert.NotEmpty(t, page[0].ID)
+
+	page, _ = queue.List(4, 2)
+	require.Len(t, page, 1)
+	assert.Equal(t, "vehicle4", page[0].VehicleID)
+
+	page, _ = queue.List(10, 2)
+	assert.Empty(t, page)
+}
+
+func TestDeadLetterQueue_DropsOldestAtCapacity(t *testing.T) {
+	queue := NewDeadLetterQueue(2)
+	for i := 0; i < 3; i++ {
+		queue.Add(DeadLetter{VehicleID: fmt.Sprintf("vehicle%d", i)})
+	}
+
+	page, total := queue.List(0, 10)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "vehicle1", page[0].VehicleID)
+	assert.Equal(t, int64(1), queue.Dropped())
+}
+
+func TestBatchProcessor_PermanentFailuresAreDeadLettered(t *testing.T) {
+	repo := &MockVehicleRepository{}
+	processor := newDeadLetterTestProcessor(repo)
+
+	failPermanently(t, processor, repo, "vehicle1", "vehicle2")
+
+	letters, total := processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 2, total)
+	for _, letter := range letters {
+		assert.Equal(t, "write conflict", letter.Error)
+		assert.Equal(t, 0, letter.Retries)
+		assert.Equal(t, 50, *letter.Update.Speed)
+	}
+	assert.Equal(t, int64(2), processor.GetBatchStats().DeadLetters)
+}
+
+func TestBatchProcessor_RetryDeadLetterRemovesItOnSuccess(t *testing.T) {
+	repo := &MockVehicleRepository{}
+	processor := newDeadLetterTestProcessor(repo)
+	failPermanently(t, processor, repo, "vehicle1", "vehicle2")
+
+	letters, _ := processor.ListDeadLetters(0, 10)
+	var retried DeadLetter
+	for _, letter := range letters {
+		if letter.VehicleID == "vehicle1" {
+			retried = letter
+		}
+	}
+
+	repo.On("UpdateVehiclesBatch", mock.MatchedBy(func(batch map[string]VehicleUpdateData) bool {
+		_, ok := batch["vehicle1"]
+		return ok && len(batch) == 1
+	})).Return(nil).Once()
+
+	result := processor.RetryDeadLetters([]string{retried.ID, "missing"})
+	assert.Equal(t, []string{retried.ID}, result.Requeued)
+	assert.Equal(t, []string{"missing"}, result.NotFound)
+	assert.Empty(t, result.Rejected)
+
+	remaining, total := processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "vehicle2", remaining[0].VehicleID)
+
+	require.NoError(t, processor.Start())
+	defer processor.Stop()
+
+	assert.Eventually(t, func() bool {
+		return processor.GetBatchStats().DeadLetterRetrySuccesses == 1
+	}, time.Second, 10*time.Millisecond)
+	stats := processor.GetBatchStats()
+	assert.Equal(t, int64(1), stats.DeadLetterRetries)
+	assert.Equal(t, int64(0), stats.DeadLetterRetryFailures)
+	_, total = processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 1, total)
+}
+
+func TestBatchProcessor_RetriedDeadLetterFailingAgainReturns(t *testing.T) {
+	repo := &MockVehicleRepository{}
+	processor := newDeadLetterTestProcessor(repo)
+	failPermanently(t, processor, repo, "vehicle1")
+
+	result := processor.RetryDeadLetters(nil)
+	require.Len(t, result.Requeued, 1)
+
+	// The worker would pick the update up; hand it to the batch directly instead
+	request := <-processor.updateChan
+	failPermanently(t, processor, repo, request.vehicleID)
+
+	letters, total := processor.ListDeadLetters(0, 10)
+	require.Equal(t, 1, total)
+	assert.Equal(t, 1, letters[0].Retries)
+	assert.NotEqual(t, result.Requeued[0], letters[0].ID)
+	assert.Equal(t, int64(1), processor.GetBatchStats().DeadLetterRetryFailures)
+}
+
+func TestBatchProcessor_RetryKeepsDeadLettersTheQueueRefuses(t *testing.T) {
+	repo := &MockVehicleRepository{}
+	processor := newDeadLetterTestProcessor(repo)
+	failPermanently(t, processor, repo, "vehicle1")
+	for i := 0; i < cap(processor.updateChan); i++ {
+		require.NoError(t, processor.AddUpdate(fmt.Sprintf("live%d", i), VehicleUpdateData{Timestamp: time.Now()}))
+	}
+
+	result := processor.RetryDeadLetters(nil)
+	assert.Empty(t, result.Requeued)
+	require.Len(t, result.Rejected, 1)
+	for _, reason := range result.Rejected {
+		assert.Contains(t, reason, ErrQueueFull.Error())
+	}
+
+	_, total := processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, int64(0), processor.GetBatchStats().DeadLetterRetries)
+}
+
+// lastUpdateRepository reports stored last update times like the Mongo adapter
+type lastUpdateRepository struct {
+	MockVehicleRepository
+	lastUpdates map[string]time.Time
+}
+
+func (r *lastUpdateRepository) GetVehicleLastUpdate(vehicleID string) (time.Time, error) {
+	if lastUpdate, ok := r.lastUpdates[vehicleID]; ok {
+		return lastUpdate, nil
+	}
+	return time.Time{}, errors.New("vehicle not found")
+}
+
+func TestBatchProcessor_RetryDropsSupersededDeadLetters(t *testing.T) {
+	repo := &lastUpdateRepository{lastUpdates: make(map[string]time.Time)}
+	processor := newDeadLetterTestProcessor(repo)
+	failPermanently(t, processor, &repo.MockVehicleRepository, "pending", "stored", "current")
+	letters, _ := processor.ListDeadLetters(0, 10)
+	failedAt := letters[0].Update.Timestamp
+
+	// A newer report is waiting in the batch for one vehicle and already
+	// stored for another
+	newer := VehicleUpdateData{Speed: intPtr(80), Timestamp: failedAt.Add(time.Minute)}
+	processor.addToCurrentBatch("pending", newer)
+	repo.lastUpdates["stored"] = failedAt.Add(time.Minute)
+	repo.lastUpdates["current"] = failedAt.Add(-time.Minute)
+
+	result := processor.RetryDeadLetters(nil)
+	require.Len(t, result.Requeued, 1)
+	assert.Len(t, result.Superseded, 2)
+	request := <-processor.updateChan
+	assert.Equal(t, "current", request.vehicleID)
+
+	assert.Equal(t, newer, processor.updates["pending"], "the pending update is kept")
+	_, total := processor.ListDeadLetters(0, 10)
+	assert.Zero(t, total)
+}
+
+func TestBatchProcessor_OlderUpdateDoesNotReplacePending(t *testing.T) {
+	processor := newDeadLetterTestProcessor(&MockVehicleRepository{})
+	now := time.Now()
+
+	processor.addToCurrentBatch("vehicle1", VehicleUpdateData{Speed: intPtr(80), Timestamp: now})
+	processor.addToCurrentBatch("vehicle1", VehicleUpdateData{Speed: intPtr(50), Timestamp: now.Add(-time.Minute)})
+	require.NoError(t, processor.AddUpdates(map[string]VehicleUpdateData{
+		"vehicle1": {Speed: intPtr(40), Timestamp: now.Add(-2 * time.Minute)},
+	}))
+
+	assert.Equal(t, 80, *processor.updates["vehicle1"].Speed)
+}
+
+func TestBatchProcessor_DiscardDeadLetters(t *testing.T) {
+	repo := &MockVehicleRepository{}
+	processor := newDeadLetterTestProcessor(repo)
+	failPermanently(t, processor, repo, "vehicle1", "vehicle2", "vehicle3")
+
+	letters, _ := processor.ListDeadLetters(0, 10)
+	discarded, notFound := processor.DiscardDeadLetters([]string{letters[0].ID, "missing"})
+	assert.Equal(t, 1, discarded)
+	assert.Equal(t, []string{"missing"}, notFound)
+
+	_, total := processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 2, total)
+
+	discarded, notFound = processor.DiscardDeadLetters(nil)
+	assert.Equal(t, 2, discarded)
+	assert.Empty(t, notFound)
+	_, total = processor.ListDeadLetters(0, 10)
+	assert.Equal(t, 0, total)
+}
@@ -1,19 +1,31 @@
 package batch
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"fleet-backend/internal/models"
 )
 
+// Errors returned by AddUpdate when an update cannot be queued
+var (
+	ErrQueueFull        = errors.New("update queue is full")
+	ErrProcessorStopped = errors.New("batch processor is stopped")
+)
+
 // BatchProcessor defines the interface for batch processing vehicle updates
 type BatchProcessor interface {
 	AddUpdate(vehicleID string, update VehicleUpdateData) error
+	AddUpdates(updates map[string]VehicleUpdateData) error
 	ProcessBatch() error
+	Flush() error
 	SetBatchSize(size int)
 	SetBatchInterval(interval time.Duration)
 	GetBatchStats() BatchStats
+	// QueuePressure is how full the update queue is, from 0 (empty) to 1
+	// (full, updates are dropped), so producers can shed load beforehand
+	QueuePressure() float64
 	Start() error
 	Stop() error
 }
@@ -37,6 +49,16 @@ type BatchStats struct {
 	TotalUpdates     int64         `json:"totalUpdates"`
 	FailedUpdates    int64         `json:"failedUpdates"`
 	LastProcessedAt  time.Time     `json:"lastProcessedAt"`
+	WorkerRestarts   int64         `json:"workerRestarts"`          // times the worker was restarted after a panic
+	LastPanic        string        `json:"lastPanic,omitempty"`     // most recent worker panic
+	LastPanicAt      *time.Time    `json:"lastPanicAt,omitempty"`
+	QueuePressure    float64       `json:"queuePressure"`           // queue fill when the stats were taken, 0 to 1
+
+	// Dead-letter queue: updates recorded there and the outcome of retrying them
+	DeadLetters              int64 `json:"deadLetters"`
+	DeadLetterRetries        int64 `json:"deadLetterRetries"`        // dead letters queued again
+	DeadLetterRetrySuccesses int64 `json:"deadLetterRetrySuccesses"` // retried updates that were applied
+	DeadLetterRetryFailures  int64 `json:"deadLetterRetryFailures"`  // retried updates that failed again
 }
 
 // BatchConfig holds configuration for batch processing
@@ -46,6 +68,14 @@ type BatchConfig struct {
 	MaxWaitTime       time.Duration `json:"maxWaitTime"`       // 5 minutes
 	RetryAttempts     int           `json:"retryAttempts"`     // 3 attempts
 	RetryBackoff      time.Duration `json:"retryBackoff"`      // exponential backoff
+
+	// Watchdog: restart the worker after a panic instead of letting batching stop
+	RestartOnPanic    bool          `json:"restartOnPanic"`
+	MaxWorkerRestarts int           `json:"maxWorkerRestarts"` // 0 for no limit
+	RestartBackoff    time.Duration `json:"restartBackoff"`    // delay before restarting
+
+	// Permanently failed updates kept for operators to retry or discard
+	DeadLetterCapacity int `json:"deadLetterCapacity"` // 0 for DefaultDeadLetterCapacity
 }
 
 // VehicleRepository defines the interface for vehicle data persistence
@@ -54,6 +84,28 @@ type VehicleRepository interface {
 	UpdateVehiclesBatch(updates map[string]VehicleUpdateData) error
 }
 
+// PartialBatchError reports the vehicles of a batch whose updates failed while
+// the rest of the batch was applied
+type PartialBatchError struct {
+	Failed map[string]error
+}
+
+func (e *PartialBatchError) Error() string {
+	return fmt.Sprintf("%d vehicle updates in batch failed", len(e.Failed))
+}
+
+// DroppedUpdatesError reports the vehicles whose updates AddUpdates dropped
+// because the queue was full. It matches ErrQueueFull with errors.Is.
+type DroppedUpdatesError struct {
+	VehicleIDs []string
+}
+
+func (e *DroppedUpdatesError) Error() string {
+	return fmt.Sprintf("%s, dropped updates for %d vehicles", ErrQueueFull, len(e.VehicleIDs))
+}
+
+func (e *DroppedUpdatesError) Unwrap() error { return ErrQueueFull }
+
 // Error definitions for batch processing
 var (
 	ErrInvalidBatchSize     = fmt.Errorf("invalid batch size: must be greater than 0")
@@ -61,4 +113,6 @@ var (
 	ErrInvalidMaxWaitTime   = fmt.Errorf("invalid max wait time: must be greater than 0")
 	ErrInvalidRetryAttempts = fmt.Errorf("invalid retry attempts: must be greater than or equal to 0")
 	ErrInvalidRetryBackoff  = fmt.Errorf("invalid retry backoff: must be greater than or equal to 0")
+	ErrInvalidMaxRestarts   = fmt.Errorf("invalid max worker restarts: must be greater than or equal to 0")
+	ErrInvalidRestartBackoff = fmt.Errorf("invalid restart backoff: must be greater than or equal to 0")
 )
\ No newline at end of file
@@ -2,9 +2,12 @@ package batch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"runtime/debug"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,12 +17,17 @@ import (
 // DefaultBatchProcessor implements the BatchProcessor interface
 type DefaultBatchProcessor struct {
 	config     BatchConfig
+	configMux  sync.RWMutex
 	repository VehicleRepository
 	wsManager  websocket.WebSocketManager
 	
 	// Internal state
 	updates    map[string]VehicleUpdateData
 	updatesMux sync.RWMutex
+
+	// processMux serializes batch processing, so a flush and the worker never
+	// write updates for the same vehicle out of order
+	processMux sync.Mutex
 	
 	// Worker control
 	ctx        context.Context
@@ -33,6 +41,18 @@ type DefaultBatchProcessor struct {
 	// Channels for communication
 	updateChan chan updateRequest
 	stopChan   chan struct{}
+
+	// intervalChanged wakes the worker to re-arm its ticker after SetBatchInterval
+	intervalChanged chan struct{}
+
+	// batchGrew wakes the worker after AddUpdates merged updates into the
+	// current batch, so a full batch is processed without waiting
+	batchGrew chan struct{}
+
+	// Permanently failed updates, and the vehicles with a dead letter retry in flight
+	deadLetters *DeadLetterQueue
+	retrying    map[string]int // vehicle ID -> retries of its dead letter so far
+	retryingMux sync.Mutex
 }
 
 type updateRequest struct {
@@ -52,6 +72,10 @@ func NewBatchProcessor(config BatchConfig, repository VehicleRepository) *Defaul
 		cancel:     cancel,
 		updateChan: make(chan updateRequest, config.MaxBatchSize*2), // Buffer for updates
 		stopChan:   make(chan struct{}),
+		intervalChanged: make(chan struct{}, 1),
+		batchGrew:       make(chan struct{}, 1),
+		deadLetters: NewDeadLetterQueue(config.DeadLetterCapacity),
+		retrying:    make(map[string]int),
 		stats: BatchStats{
 			LastProcessedAt: time.Now(),
 		},
@@ -71,6 +95,10 @@ func NewBatchProcessorWithWebSocket(config BatchConfig, repository VehicleReposi
 		cancel:     cancel,
 		updateChan: make(chan updateRequest, config.MaxBatchSize*2), // Buffer for updates
 		stopChan:   make(chan struct{}),
+		intervalChanged: make(chan struct{}, 1),
+		batchGrew:       make(chan struct{}, 1),
+		deadLetters: NewDeadLetterQueue(config.DeadLetterCapacity),
+		retrying:    make(map[string]int),
 		stats: BatchStats{
 			LastProcessedAt: time.Now(),
 		},
@@ -88,14 +116,96 @@ func (bp *DefaultBatchProcessor) AddUpdate(vehicleID string, update VehicleUpdat
 	case bp.updateChan <- updateRequest{vehicleID: vehicleID, update: update}:
 		return nil
 	case <-bp.ctx.Done():
-		return fmt.Errorf("batch processor is stopped")
+		return ErrProcessorStopped
 	default:
-		return fmt.Errorf("update queue is full, dropping update for vehicle %s", vehicleID)
+		return fmt.Errorf("%w, dropping update for vehicle %s", ErrQueueFull, vehicleID)
 	}
 }
 
+// AddUpdates queues updates for several vehicles at once. Instead of one
+// channel send per update, the updates are merged into the current batch
+// under a single lock. The queue's capacity still applies: updates for
+// vehicles already pending replace theirs, and new vehicles are added while
+// the batch is below the capacity of the update channel. The rest are
+// dropped and reported in a *DroppedUpdatesError.
+func (bp *DefaultBatchProcessor) AddUpdates(updates map[string]VehicleUpdateData) error {
+	if bp.ctx.Err() != nil {
+		return ErrProcessorStopped
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	// Updates queued earlier go in first, so they can't overwrite these
+	bp.drainQueued()
+
+	capacity := cap(bp.updateChan)
+	var dropped []string
+	bp.updatesMux.Lock()
+	for vehicleID, update := range updates {
+		existing, pending := bp.updates[vehicleID]
+		if !pending && len(bp.updates) >= capacity {
+			dropped = append(dropped, vehicleID)
+			continue
+		}
+		if pending && existing.Timestamp.After(update.Timestamp) {
+			continue
+		}
+		bp.updates[vehicleID] = update
+	}
+	bp.updatesMux.Unlock()
+
+	select {
+	case bp.batchGrew <- struct{}{}:
+	default:
+		// The worker already has a pending wake-up
+	}
+
+	if len(dropped) > 0 {
+		sort.Strings(dropped)
+		return &DroppedUpdatesError{VehicleIDs: dropped}
+	}
+	return nil
+}
+
 // ProcessBatch processes the current batch of updates
 func (bp *DefaultBatchProcessor) ProcessBatch() error {
+	_, err := bp.processBatch()
+	return err
+}
+
+// Flush processes every pending update now, including updates still queued
+// for the worker, instead of waiting for the batch interval or max wait time.
+// It is safe to call while the worker runs; an update the worker is receiving
+// at that moment goes out with its next batch.
+func (bp *DefaultBatchProcessor) Flush() error {
+	_, err := bp.FlushPending()
+	return err
+}
+
+// FlushPending flushes like Flush and returns how many updates were flushed
+func (bp *DefaultBatchProcessor) FlushPending() (int, error) {
+	bp.drainQueued()
+	return bp.processBatch()
+}
+
+// drainQueued moves the updates queued on the channel into the current batch
+func (bp *DefaultBatchProcessor) drainQueued() {
+	for {
+		select {
+		case update := <-bp.updateChan:
+			bp.addToCurrentBatch(update.vehicleID, update.update)
+		default:
+			return
+		}
+	}
+}
+
+// processBatch processes the current batch and returns its number of updates
+func (bp *DefaultBatchProcessor) processBatch() (int, error) {
+	bp.processMux.Lock()
+	defer bp.processMux.Unlock()
+
 	bp.updatesMux.Lock()
 	currentUpdates := make(map[string]VehicleUpdateData)
 	for k, v := range bp.updates {
@@ -105,7 +215,7 @@ func (bp *DefaultBatchProcessor) ProcessBatch() error {
 	bp.updatesMux.Unlock()
 	
 	if len(currentUpdates) == 0 {
-		return nil
+		return 0, nil
 	}
 	
 	startTime := time.Now()
@@ -125,19 +235,20 @@ func (bp *DefaultBatchProcessor) ProcessBatch() error {
 	bp.updateStats(len(batches), len(currentUpdates), totalErrors, time.Since(startTime))
 	
 	if totalErrors > 0 {
-		return fmt.Errorf("failed to process %d out of %d batches", totalErrors, len(batches))
+		return len(currentUpdates), fmt.Errorf("failed to process %d out of %d batches", totalErrors, len(batches))
 	}
 	
-	return nil
+	return len(currentUpdates), nil
 }
 
 // processSingleBatch processes a single batch with retry logic
 func (bp *DefaultBatchProcessor) processSingleBatch(batch map[string]VehicleUpdateData) error {
-	for attempt := 0; attempt <= bp.config.RetryAttempts; attempt++ {
+	config := bp.GetConfig()
+	for attempt := 0; attempt <= config.RetryAttempts; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff
-			backoffDuration := time.Duration(math.Pow(2, float64(attempt-1))) * bp.config.RetryBackoff
-			log.Printf("Retrying batch processing after %v (attempt %d/%d)", backoffDuration, attempt, bp.config.RetryAttempts)
+			backoffDuration := time.Duration(math.Pow(2, float64(attempt-1))) * config.RetryBackoff
+			log.Printf("Retrying batch processing after %v (attempt %d/%d)", backoffDuration, attempt, config.RetryAttempts)
 			
 			select {
 			case <-time.After(backoffDuration):
@@ -150,13 +261,20 @@ func (bp *DefaultBatchProcessor) processSingleBatch(batch map[string]VehicleUpda
 		if err == nil {
 			// Broadcast updates via WebSocket after successful database update
 			bp.broadcastBatchUpdates(batch)
+			bp.settleRetries(batch)
 			return nil // Success
 		}
+
+		// The rest of the batch was applied, only the failed vehicles need another attempt
+		var partialErr *PartialBatchError
+		if errors.As(err, &partialErr) {
+			return bp.handlePartialFailure(batch, partialErr)
+		}
 		
 		log.Printf("Batch processing attempt %d failed: %v", attempt+1, err)
 		
 		// If this is the last attempt, we'll fall back to individual updates
-		if attempt == bp.config.RetryAttempts {
+		if attempt == config.RetryAttempts {
 			log.Printf("All batch retries failed, falling back to individual updates")
 			return bp.fallbackToIndividualUpdates(batch)
 		}
@@ -166,15 +284,49 @@ func (bp *DefaultBatchProcessor) processSingleBatch(batch map[string]VehicleUpda
 	return fmt.Errorf("unexpected error in batch processing")
 }
 
-// fallbackToIndividualUpdates processes updates individually when batch processing fails
+// handlePartialFailure broadcasts the applied updates of a partially failed
+// batch and falls back to individual updates for the failed vehicles only
+func (bp *DefaultBatchProcessor) handlePartialFailure(batch map[string]VehicleUpdateData, partialErr *PartialBatchError) error {
+	applied := make(map[string]VehicleUpdateData, len(batch))
+	failed := make(map[string]VehicleUpdateData, len(partialErr.Failed))
+	for vehicleID, update := range batch {
+		if _, ok := partialErr.Failed[vehicleID]; ok {
+			failed[vehicleID] = update
+		} else {
+			applied[vehicleID] = update
+		}
+	}
+
+	log.Printf("Batch partially failed for %d of %d vehicles, falling back to individual updates for them", len(failed), len(batch))
+	if len(applied) > 0 {
+		bp.broadcastBatchUpdates(applied)
+		bp.settleRetries(applied)
+	}
+	return bp.fallbackToIndividualUpdates(failed)
+}
+
+// fallbackToIndividualUpdates processes updates individually when batch processing fails.
+// Each update that still fails is a permanent failure: it counts towards
+// FailedUpdates and goes to the dead-letter queue. The ones that succeed are
+// broadcast like a successful batch.
 func (bp *DefaultBatchProcessor) fallbackToIndividualUpdates(batch map[string]VehicleUpdateData) error {
 	var errors []string
+	recovered := make(map[string]VehicleUpdateData, len(batch))
 	
 	for vehicleID, update := range batch {
 		if err := bp.repository.UpdateVehicle(vehicleID, update); err != nil {
+			log.Printf("Update for vehicle %s failed permanently: %v", vehicleID, err)
 			errors = append(errors, fmt.Sprintf("vehicle %s: %v", vehicleID, err))
 			bp.incrementFailedUpdates()
+			bp.deadLetter(vehicleID, update, err)
+			continue
 		}
+		recovered[vehicleID] = update
+	}
+
+	if len(recovered) > 0 {
+		bp.broadcastBatchUpdates(recovered)
+		bp.settleRetries(recovered)
 	}
 	
 	if len(errors) > 0 {
@@ -186,7 +338,8 @@ func (bp *DefaultBatchProcessor) fallbackToIndividualUpdates(batch map[string]Ve
 
 // splitIntoBatches splits a large update map into smaller batches
 func (bp *DefaultBatchProcessor) splitIntoBatches(updates map[string]VehicleUpdateData) []map[string]VehicleUpdateData {
-	if len(updates) <= bp.config.MaxBatchSize {
+	maxBatchSize := bp.GetConfig().MaxBatchSize
+	if len(updates) <= maxBatchSize {
 		return []map[string]VehicleUpdateData{updates}
 	}
 	
@@ -196,7 +349,7 @@ func (bp *DefaultBatchProcessor) splitIntoBatches(updates map[string]VehicleUpda
 	for vehicleID, update := range updates {
 		currentBatch[vehicleID] = update
 		
-		if len(currentBatch) >= bp.config.MaxBatchSize {
+		if len(currentBatch) >= maxBatchSize {
 			batches = append(batches, currentBatch)
 			currentBatch = make(map[string]VehicleUpdateData)
 		}
@@ -213,7 +366,7 @@ func (bp *DefaultBatchProcessor) splitIntoBatches(updates map[string]VehicleUpda
 // Start starts the batch processing worker
 func (bp *DefaultBatchProcessor) Start() error {
 	bp.workerWg.Add(1)
-	go bp.worker()
+	go bp.supervise()
 	log.Println("Batch processor started")
 	return nil
 }
@@ -227,33 +380,70 @@ func (bp *DefaultBatchProcessor) Stop() error {
 	return nil
 }
 
-// worker is the main worker goroutine that processes updates
-func (bp *DefaultBatchProcessor) worker() {
+// supervise runs the worker and, when RestartOnPanic is set, restarts it
+// after a panic so one bad batch doesn't stop persistence for good. Updates
+// queued on the channel survive a restart; the batch being processed when the
+// panic happened is lost.
+func (bp *DefaultBatchProcessor) supervise() {
 	defer bp.workerWg.Done()
 	
-	ticker := time.NewTicker(bp.config.BatchInterval)
+	for restarts := 0; ; restarts++ {
+		if !bp.runWorker() {
+			return // stopped normally
+		}
+		
+		config := bp.GetConfig()
+		if !config.RestartOnPanic {
+			log.Printf("Batch worker not restarted: restart on panic is disabled")
+			return
+		}
+		if config.MaxWorkerRestarts > 0 && restarts >= config.MaxWorkerRestarts {
+			log.Printf("Batch worker not restarted: reached the limit of %d restarts", config.MaxWorkerRestarts)
+			return
+		}
+		
+		// A stop during the backoff still restarts the worker, which flushes the final batch
+		select {
+		case <-time.After(config.RestartBackoff):
+		case <-bp.ctx.Done():
+		}
+		
+		bp.incrementWorkerRestarts()
+		log.Printf("Restarting batch worker (restart %d)", restarts+1)
+	}
+}
+
+// runWorker runs the worker until it returns, reporting whether it panicked
+func (bp *DefaultBatchProcessor) runWorker() (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Batch worker panicked: %v\n%s", r, debug.Stack())
+			bp.recordPanic(r)
+			panicked = true
+		}
+	}()
+	
+	bp.worker()
+	return false
+}
+
+// worker is the main worker goroutine that processes updates
+func (bp *DefaultBatchProcessor) worker() {
+	config := bp.GetConfig()
+	ticker := time.NewTicker(config.BatchInterval)
 	defer ticker.Stop()
 	
-	maxWaitTimer := time.NewTimer(bp.config.MaxWaitTime)
+	maxWaitTimer := time.NewTimer(config.MaxWaitTime)
 	defer maxWaitTimer.Stop()
 	
 	for {
 		select {
 		case update := <-bp.updateChan:
 			bp.addToCurrentBatch(update.vehicleID, update.update)
+			bp.batchReceived(maxWaitTimer)
 			
-			// Reset max wait timer when we receive an update
-			if !maxWaitTimer.Stop() {
-				<-maxWaitTimer.C
-			}
-			maxWaitTimer.Reset(bp.config.MaxWaitTime)
-			
-			// Check if batch is full
-			if bp.getCurrentBatchSize() >= bp.config.MaxBatchSize {
-				if err := bp.ProcessBatch(); err != nil {
-					log.Printf("Error processing full batch: %v", err)
-				}
-			}
+		case <-bp.batchGrew:
+			bp.batchReceived(maxWaitTimer)
 			
 		case <-ticker.C:
 			// Process batch on interval
@@ -261,14 +451,20 @@ func (bp *DefaultBatchProcessor) worker() {
 				log.Printf("Error processing interval batch: %v", err)
 			}
 			
+		case <-bp.intervalChanged:
+			ticker.Reset(bp.GetConfig().BatchInterval)
+			
 		case <-maxWaitTimer.C:
 			// Process batch when max wait time is reached
 			if err := bp.ProcessBatch(); err != nil {
 				log.Printf("Error processing max wait batch: %v", err)
 			}
-			maxWaitTimer.Reset(bp.config.MaxWaitTime)
+			maxWaitTimer.Reset(bp.GetConfig().MaxWaitTime)
 			
 		case <-bp.ctx.Done():
+			// Pick up updates still queued on the channel so the final batch includes them
+			bp.drainQueued()
+			
 			// Process remaining updates before stopping
 			if err := bp.ProcessBatch(); err != nil {
 				log.Printf("Error processing final batch: %v", err)
@@ -278,10 +474,29 @@ func (bp *DefaultBatchProcessor) worker() {
 	}
 }
 
-// addToCurrentBatch adds an update to the current batch
+// batchReceived resets the max wait timer after updates were received and
+// processes the batch if it is full
+func (bp *DefaultBatchProcessor) batchReceived(maxWaitTimer *time.Timer) {
+	if !maxWaitTimer.Stop() {
+		<-maxWaitTimer.C
+	}
+	maxWaitTimer.Reset(bp.GetConfig().MaxWaitTime)
+	
+	if bp.getCurrentBatchSize() >= bp.GetConfig().MaxBatchSize {
+		if err := bp.ProcessBatch(); err != nil {
+			log.Printf("Error processing full batch: %v", err)
+		}
+	}
+}
+
+// addToCurrentBatch adds an update to the current batch, unless an update
+// reported later is already pending for the vehicle
 func (bp *DefaultBatchProcessor) addToCurrentBatch(vehicleID string, update VehicleUpdateData) {
 	bp.updatesMux.Lock()
 	defer bp.updatesMux.Unlock()
+	if pending, ok := bp.updates[vehicleID]; ok && pending.Timestamp.After(update.Timestamp) {
+		return
+	}
 	bp.updates[vehicleID] = update
 }
 
@@ -292,21 +507,56 @@ func (bp *DefaultBatchProcessor) getCurrentBatchSize() int {
 	return len(bp.updates)
 }
 
-// SetBatchSize updates the batch size configuration
+// SetBatchSize updates the batch size configuration. It is safe to call while
+// the processor is running; the next flush check uses the new size.
 func (bp *DefaultBatchProcessor) SetBatchSize(size int) {
+	bp.configMux.Lock()
 	bp.config.MaxBatchSize = size
+	bp.configMux.Unlock()
 }
 
-// SetBatchInterval updates the batch interval configuration
+// SetBatchInterval updates the batch interval configuration. A running worker
+// re-arms its ticker with the new interval.
 func (bp *DefaultBatchProcessor) SetBatchInterval(interval time.Duration) {
+	bp.configMux.Lock()
 	bp.config.BatchInterval = interval
+	bp.configMux.Unlock()
+
+	select {
+	case bp.intervalChanged <- struct{}{}:
+	default:
+		// The worker already has a pending re-arm
+	}
+}
+
+// GetConfig returns a snapshot of the current batch configuration
+func (bp *DefaultBatchProcessor) GetConfig() BatchConfig {
+	bp.configMux.RLock()
+	defer bp.configMux.RUnlock()
+	return bp.config
 }
 
 // GetBatchStats returns current batch processing statistics
 func (bp *DefaultBatchProcessor) GetBatchStats() BatchStats {
 	bp.statsMux.RLock()
-	defer bp.statsMux.RUnlock()
-	return bp.stats
+	stats := bp.stats
+	bp.statsMux.RUnlock()
+
+	stats.QueuePressure = bp.QueuePressure()
+	return stats
+}
+
+// QueuePressure returns how full the update queue is, from 0 to 1. AddUpdate
+// drops updates once the update channel is full and AddUpdates once the
+// current batch holds as many vehicles as the channel can, so the fuller of
+// the two is reported.
+func (bp *DefaultBatchProcessor) QueuePressure() float64 {
+	capacity := cap(bp.updateChan)
+	if capacity == 0 {
+		return 0
+	}
+	queued := max(len(bp.updateChan), bp.getCurrentBatchSize())
+	return min(float64(queued)/float64(capacity), 1)
 }
 
 // updateStats updates the batch processing statistics
@@ -337,6 +587,22 @@ func (bp *DefaultBatchProcessor) incrementFailedUpdates() {
 	bp.stats.FailedUpdates++
 }
 
+// recordPanic records a worker panic in the statistics
+func (bp *DefaultBatchProcessor) recordPanic(r interface{}) {
+	now := time.Now()
+	bp.statsMux.Lock()
+	defer bp.statsMux.Unlock()
+	bp.stats.LastPanic = fmt.Sprint(r)
+	bp.stats.LastPanicAt = &now
+}
+
+// incrementWorkerRestarts increments the worker restart counter
+func (bp *DefaultBatchProcessor) incrementWorkerRestarts() {
+	bp.statsMux.Lock()
+	defer bp.statsMux.Unlock()
+	bp.stats.WorkerRestarts++
+}
+
 // broadcastBatchUpdates broadcasts vehicle updates via WebSocket after successful batch processing
 func (bp *DefaultBatchProcessor) broadcastBatchUpdates(batch map[string]VehicleUpdateData) {
 	if bp.wsManager == nil {
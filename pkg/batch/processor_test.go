@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"fleet-backend/internal/models"
+	"fleet-backend/internal/websocket"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -201,6 +202,115 @@ func TestBatchProcessor_ProcessBatchWithFallback(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestBatchProcessor_PartialFailureFallsBackForFailedVehiclesOnly(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	config := BatchConfig{
+		MaxBatchSize:  10,
+		BatchInterval: 1 * time.Second,
+		MaxWaitTime:   5 * time.Second,
+		RetryAttempts: 3,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessor(config, mockRepo)
+
+	update1 := VehicleUpdateData{FuelLevel: floatPtr(75.5), Timestamp: time.Now()}
+	update2 := VehicleUpdateData{Speed: intPtr(60), Timestamp: time.Now()}
+	processor.addToCurrentBatch("vehicle1", update1)
+	processor.addToCurrentBatch("vehicle2", update2)
+
+	// vehicle2 failed within the bulk write, vehicle1 was applied
+	mockRepo.On("UpdateVehiclesBatch", mock.AnythingOfType("map[string]batch.VehicleUpdateData")).
+		Return(&PartialBatchError{Failed: map[string]error{"vehicle2": errors.New("write conflict")}}).Once()
+	mockRepo.On("UpdateVehicle", "vehicle2", update2).Return(nil).Once()
+
+	err := processor.ProcessBatch()
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateVehicle", "vehicle1", mock.Anything)
+}
+
+// recordingBroadcaster records the vehicles of broadcast batch updates
+type recordingBroadcaster struct {
+	websocket.WebSocketManager
+	mu       sync.Mutex
+	vehicles []string
+}
+
+func (r *recordingBroadcaster) BroadcastBatchUpdates(updates []websocket.VehicleUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, update := range updates {
+		r.vehicles = append(r.vehicles, update.VehicleID)
+	}
+	return nil
+}
+
+func TestBatchProcessor_OnlyFailedDocumentGoesThroughFallback(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	broadcaster := &recordingBroadcaster{}
+	config := BatchConfig{
+		MaxBatchSize:  10,
+		BatchInterval: 1 * time.Second,
+		MaxWaitTime:   5 * time.Second,
+		RetryAttempts: 2,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessorWithWebSocket(config, mockRepo, broadcaster)
+
+	for i := 1; i <= 5; i++ {
+		processor.addToCurrentBatch(fmt.Sprintf("vehicle%d", i), VehicleUpdateData{Speed: intPtr(40 + i), Timestamp: time.Now()})
+	}
+
+	mockRepo.On("UpdateVehiclesBatch", mock.AnythingOfType("map[string]batch.VehicleUpdateData")).
+		Return(&PartialBatchError{Failed: map[string]error{"vehicle3": errors.New("document failed validation")}}).Once()
+	mockRepo.On("UpdateVehicle", "vehicle3", mock.AnythingOfType("batch.VehicleUpdateData")).
+		Return(errors.New("document failed validation")).Once()
+
+	err := processor.ProcessBatch()
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "UpdateVehiclesBatch", 1)
+	mockRepo.AssertNumberOfCalls(t, "UpdateVehicle", 1)
+
+	stats := processor.GetBatchStats()
+	assert.Equal(t, int64(1), stats.FailedUpdates)
+	assert.Equal(t, int64(5), stats.TotalUpdates)
+	assert.ElementsMatch(t, []string{"vehicle1", "vehicle2", "vehicle4", "vehicle5"}, broadcaster.vehicles)
+}
+
+func TestBatchProcessor_FallbackBroadcastsRecoveredUpdates(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	broadcaster := &recordingBroadcaster{}
+	config := BatchConfig{
+		MaxBatchSize:  10,
+		BatchInterval: 1 * time.Second,
+		MaxWaitTime:   5 * time.Second,
+		RetryAttempts: 0,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessorWithWebSocket(config, mockRepo, broadcaster)
+	processor.addToCurrentBatch("vehicle1", VehicleUpdateData{Speed: intPtr(40), Timestamp: time.Now()})
+	processor.addToCurrentBatch("vehicle2", VehicleUpdateData{Speed: intPtr(50), Timestamp: time.Now()})
+
+	mockRepo.On("UpdateVehiclesBatch", mock.AnythingOfType("map[string]batch.VehicleUpdateData")).
+		Return(errors.New("connection reset")).Once()
+	mockRepo.On("UpdateVehicle", "vehicle1", mock.AnythingOfType("batch.VehicleUpdateData")).Return(nil).Once()
+	mockRepo.On("UpdateVehicle", "vehicle2", mock.AnythingOfType("batch.VehicleUpdateData")).
+		Return(errors.New("vehicle not found")).Once()
+
+	err := processor.ProcessBatch()
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, int64(1), processor.GetBatchStats().FailedUpdates)
+	assert.Equal(t, []string{"vehicle1"}, broadcaster.vehicles)
+}
+
 func TestBatchProcessor_SplitIntoBatches(t *testing.T) {
 	mockRepo := &MockVehicleRepository{}
 	config := BatchConfig{
@@ -280,6 +390,91 @@ func TestBatchProcessor_WorkerLifecycle(t *testing.T) {
 	// The important thing is that the processor stopped gracefully
 }
 
+// panickingRepository panics on its first batch write and records the vehicles
+// of the batches written after that
+type panickingRepository struct {
+	mu       sync.Mutex
+	panicked bool
+	written  []string
+}
+
+func (r *panickingRepository) UpdateVehicle(vehicleID string, update VehicleUpdateData) error {
+	return nil
+}
+
+func (r *panickingRepository) UpdateVehiclesBatch(updates map[string]VehicleUpdateData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.panicked {
+		r.panicked = true
+		var broken map[string]int
+		broken["vehicle"] = 1 // nil map write
+	}
+	for vehicleID := range updates {
+		r.written = append(r.written, vehicleID)
+	}
+	return nil
+}
+
+func (r *panickingRepository) writtenVehicles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.written...)
+}
+
+func TestBatchProcessor_WorkerRecoversFromPanic(t *testing.T) {
+	repo := &panickingRepository{}
+	config := BatchConfig{
+		MaxBatchSize:   1, // every update is flushed immediately
+		BatchInterval:  time.Hour,
+		MaxWaitTime:    time.Hour,
+		RestartOnPanic: true,
+		RestartBackoff: 10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessor(config, repo)
+	assert.NoError(t, processor.Start())
+
+	update := VehicleUpdateData{FuelLevel: floatPtr(50), Timestamp: time.Now()}
+	assert.NoError(t, processor.AddUpdate("vehicle1", update))
+
+	assert.Eventually(t, func() bool {
+		return processor.GetBatchStats().WorkerRestarts == 1
+	}, time.Second, 10*time.Millisecond)
+
+	stats := processor.GetBatchStats()
+	assert.Contains(t, stats.LastPanic, "nil map")
+	assert.NotNil(t, stats.LastPanicAt)
+
+	// The restarted worker keeps persisting updates
+	assert.NoError(t, processor.AddUpdate("vehicle2", update))
+	assert.Eventually(t, func() bool {
+		return len(repo.writtenVehicles()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"vehicle2"}, repo.writtenVehicles())
+
+	assert.NoError(t, processor.Stop())
+}
+
+func TestBatchProcessor_WorkerNotRestartedWhenDisabled(t *testing.T) {
+	repo := &panickingRepository{}
+	config := BatchConfig{
+		MaxBatchSize:  1,
+		BatchInterval: time.Hour,
+		MaxWaitTime:   time.Hour,
+	}
+
+	processor := NewBatchProcessor(config, repo)
+	assert.NoError(t, processor.Start())
+	assert.NoError(t, processor.AddUpdate("vehicle1", VehicleUpdateData{Timestamp: time.Now()}))
+
+	assert.Eventually(t, func() bool {
+		return processor.GetBatchStats().LastPanic != ""
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, processor.Stop())
+	assert.Zero(t, processor.GetBatchStats().WorkerRestarts)
+}
+
 func TestBatchProcessor_BatchSizeLimit(t *testing.T) {
 	mockRepo := &MockVehicleRepository{}
 	config := BatchConfig{
@@ -381,6 +576,128 @@ func TestBatchProcessor_ConfigurationUpdate(t *testing.T) {
 	assert.Equal(t, newInterval, processor.config.BatchInterval)
 }
 
+func TestBatchProcessor_IntervalUpdateAppliesToRunningWorker(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	config := BatchConfig{
+		MaxBatchSize:  100,
+		BatchInterval: time.Hour, // would never fire during the test
+		MaxWaitTime:   time.Hour,
+		RetryAttempts: 1,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessor(config, mockRepo)
+	mockRepo.On("UpdateVehiclesBatch", mock.AnythingOfType("map[string]batch.VehicleUpdateData")).Return(nil)
+
+	assert.NoError(t, processor.Start())
+	defer processor.Stop()
+
+	processor.SetBatchInterval(20 * time.Millisecond)
+	assert.NoError(t, processor.AddUpdate("vehicle1", VehicleUpdateData{FuelLevel: floatPtr(60), Timestamp: time.Now()}))
+
+	// The re-armed ticker flushes the update long before the original interval
+	assert.Eventually(t, func() bool {
+		return processor.GetBatchStats().TotalUpdates == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, processor.GetConfig().BatchInterval)
+}
+
+func TestBatchProcessor_FlushWritesPendingUpdatesImmediately(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	config := BatchConfig{
+		MaxBatchSize:  100,
+		BatchInterval: time.Hour, // would never fire during the test
+		MaxWaitTime:   time.Hour,
+		RetryAttempts: 1,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessor(config, mockRepo)
+	mockRepo.On("UpdateVehiclesBatch", mock.MatchedBy(func(updates map[string]VehicleUpdateData) bool {
+		return len(updates) == 2
+	})).Return(nil).Once()
+
+	assert.NoError(t, processor.AddUpdate("vehicle1", VehicleUpdateData{FuelLevel: floatPtr(60), Timestamp: time.Now()}))
+	assert.NoError(t, processor.AddUpdate("vehicle2", VehicleUpdateData{Speed: intPtr(40), Timestamp: time.Now()}))
+
+	// The worker isn't started, so the updates are still queued on the
+	// channel; the flush picks them up without waiting for an interval
+	flushed, err := processor.FlushPending()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+	mockRepo.AssertNumberOfCalls(t, "UpdateVehiclesBatch", 1)
+	assert.Equal(t, int64(2), processor.GetBatchStats().TotalUpdates)
+
+	// Nothing is left for a second flush
+	assert.NoError(t, processor.Flush())
+	mockRepo.AssertNumberOfCalls(t, "UpdateVehiclesBatch", 1)
+}
+
+func TestBatchProcessor_AddUpdatesLandInNextBatch(t *testing.T) {
+	mockRepo := &MockVehicleRepository{}
+	config := BatchConfig{
+		MaxBatchSize:  100,
+		BatchInterval: time.Hour, // would never fire during the test
+		MaxWaitTime:   time.Hour,
+		RetryAttempts: 1,
+		RetryBackoff:  10 * time.Millisecond,
+	}
+
+	processor := NewBatchProcessor(config, mockRepo)
+	mockRepo.On("UpdateVehiclesBatch", mock.MatchedBy(func(updates map[string]VehicleUpdateData) bool {
+		return len(updates) == 100
+	})).Return(nil).Once()
+
+	assert.NoError(t, processor.Start())
+	defer processor.Stop()
+
+	updates := make(map[string]VehicleUpdateData, 100)
+	for i := 0; i < 100; i++ {
+		updates[fmt.Sprintf("vehicle%d", i)] = VehicleUpdateData{Speed: intPtr(i), Timestamp: time.Now()}
+	}
+	assert.NoError(t, processor.AddUpdates(updates))
+
+	// The batch is full, so the worker processes it without waiting for an interval
+	assert.Eventually(t, func() bool {
+		return processor.GetBatchStats().TotalUpdates == 100
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, processor.GetBatchStats().BatchesProcessed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBatchProcessor_AddUpdatesReportsDroppedVehicles(t *testing.T) {
+	config := BatchConfig{
+		MaxBatchSize:  2, // the queue holds 4 vehicles
+		BatchInterval: time.Hour,
+		MaxWaitTime:   time.Hour,
+	}
+	processor := NewBatchProcessor(config, &MockVehicleRepository{})
+
+	assert.NoError(t, processor.AddUpdates(map[string]VehicleUpdateData{
+		"vehicle1": {Speed: intPtr(10)},
+		"vehicle2": {Speed: intPtr(20)},
+		"vehicle3": {Speed: intPtr(30)},
+	}))
+
+	// vehicle1 is already pending, so only one of the two new vehicles fits
+	err := processor.AddUpdates(map[string]VehicleUpdateData{
+		"vehicle1": {Speed: intPtr(15)},
+		"vehicle4": {Speed: intPtr(40)},
+		"vehicle5": {Speed: intPtr(50)},
+	})
+	assert.ErrorIs(t, err, ErrQueueFull)
+	var dropped *DroppedUpdatesError
+	if assert.ErrorAs(t, err, &dropped) {
+		assert.Len(t, dropped.VehicleIDs, 1)
+		assert.Contains(t, []string{"vehicle4", "vehicle5"}, dropped.VehicleIDs[0])
+	}
+	assert.Equal(t, 4, processor.getCurrentBatchSize())
+	assert.Equal(t, 15, *processor.updates["vehicle1"].Speed)
+
+	assert.NoError(t, processor.Stop())
+	assert.ErrorIs(t, processor.AddUpdates(map[string]VehicleUpdateData{"vehicle6": {}}), ErrProcessorStopped)
+}
+
 // Helper functions for creating pointers
 func floatPtr(f float64) *float64 {
 	return &f
@@ -663,4 +980,23 @@ func TestVehicleUpdateData_PartialUpdates(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+func TestBatchProcessor_QueuePressure(t *testing.T) {
+	config := BatchConfig{
+		MaxBatchSize:  5, // the queue holds 10 updates
+		BatchInterval: time.Hour,
+		MaxWaitTime:   time.Hour,
+	}
+	processor := NewBatchProcessor(config, &MockVehicleRepository{})
+	assert.Zero(t, processor.QueuePressure())
+
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, processor.AddUpdate(fmt.Sprintf("vehicle%d", i), VehicleUpdateData{Speed: intPtr(i)}))
+	}
+	assert.InDelta(t, 0.9, processor.QueuePressure(), 1e-9)
+	assert.InDelta(t, 0.9, processor.GetBatchStats().QueuePressure, 1e-9)
+
+	assert.NoError(t, processor.AddUpdate("vehicle9", VehicleUpdateData{}))
+	assert.Error(t, processor.AddUpdate("vehicle10", VehicleUpdateData{}))
+	assert.Equal(t, 1.0, processor.QueuePressure())
+}
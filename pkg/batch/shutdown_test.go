@@ -0,0 +1,90 @@
+package batch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/websocket"
+
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShutdownOrder_FinalBatchReachesClients stops the batch processor and then
+// drains the WebSocket manager, the order used on server shutdown, and checks the
+// final flush's broadcasts reach the client before its connection is closed.
+func TestShutdownOrder_FinalBatchReachesClients(t *testing.T) {
+	wsManager := websocket.NewManager()
+	require.NoError(t, wsManager.Start())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsManager.GetUpgrader().Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if err := wsManager.RegisterClient("shutdown-client", conn, websocket.VehicleFilters{}); err != nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := gorillaws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return wsManager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mockRepo := new(MockVehicleRepository)
+	mockRepo.On("UpdateVehiclesBatch", mock.Anything).Return(nil)
+
+	// Long intervals so nothing is flushed before shutdown
+	config := DefaultBatchConfig()
+	config.BatchInterval = time.Hour
+	config.MaxWaitTime = time.Hour
+	processor := NewBatchProcessorWithWebSocket(config, mockRepo, wsManager)
+	require.NoError(t, processor.Start())
+
+	expected := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		vehicleID := fmt.Sprintf("vehicle-%d", i)
+		expected[vehicleID] = true
+		require.NoError(t, processor.AddUpdate(vehicleID, VehicleUpdateData{
+			FuelLevel: floatPtr(float64(i)),
+			Timestamp: time.Now(),
+		}))
+	}
+
+	received := make(chan map[string]bool, 1)
+	go func() {
+		seen := make(map[string]bool)
+		for {
+			var message struct {
+				Data websocket.VehicleUpdate `json:"data"`
+			}
+			if err := conn.ReadJSON(&message); err != nil {
+				received <- seen
+				return
+			}
+			seen[message.Data.VehicleID] = true
+		}
+	}()
+
+	require.NoError(t, processor.Stop())
+	require.NoError(t, wsManager.Drain(2*time.Second))
+
+	select {
+	case seen := <-received:
+		assert.Equal(t, expected, seen)
+	case <-time.After(3 * time.Second):
+		t.Fatal("client connection was not closed after drain")
+	}
+	assert.Equal(t, 0, wsManager.GetConnectedClients())
+}
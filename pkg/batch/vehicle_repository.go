@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"fleet-backend/internal/repository"
@@ -38,10 +39,28 @@ func (vra *VehicleRepositoryAdapter) UpdateVehicle(vehicleID string, update Vehi
 		return fmt.Errorf("invalid vehicle ID %s: %w", vehicleID, err)
 	}
 
-	// Build update document with only non-nil fields
+	result, err := vra.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": buildUpdateDoc(update)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update vehicle %s: %w", vehicleID, err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("vehicle %s not found", vehicleID)
+	}
+
+	return nil
+}
+
+// buildUpdateDoc builds a $set document with only the non-nil fields of an update
+func buildUpdateDoc(update VehicleUpdateData) bson.M {
 	updateDoc := bson.M{
-		"last_update": update.Timestamp,
-		"updated_at":  time.Now(),
+		"last_update":      update.Timestamp,
+		"last_reported_at": update.Timestamp,
+		"updated_at":       time.Now(),
 	}
 
 	if update.FuelLevel != nil {
@@ -60,24 +79,22 @@ func (vra *VehicleRepositoryAdapter) UpdateVehicle(vehicleID string, update Vehi
 		updateDoc["odometer"] = *update.Odometer
 	}
 
-	result, err := vra.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": objectID},
-		bson.M{"$set": updateDoc},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to update vehicle %s: %w", vehicleID, err)
-	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("vehicle %s not found", vehicleID)
-	}
+	return updateDoc
+}
 
-	return nil
+// bulkWriter is the subset of a mongo collection used for batch updates
+type bulkWriter interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
 }
 
-// UpdateVehiclesBatch updates multiple vehicles in a single batch operation
+// UpdateVehiclesBatch applies all updates in one unordered BulkWrite. When only
+// some updates fail it returns a *PartialBatchError naming the failed vehicles,
+// so callers can retry just those.
 func (vra *VehicleRepositoryAdapter) UpdateVehiclesBatch(updates map[string]VehicleUpdateData) error {
+	return updateVehiclesBatch(vra.collection, updates)
+}
+
+func updateVehiclesBatch(writer bulkWriter, updates map[string]VehicleUpdateData) error {
 	if len(updates) == 0 {
 		return nil
 	}
@@ -85,57 +102,48 @@ func (vra *VehicleRepositoryAdapter) UpdateVehiclesBatch(updates map[string]Vehi
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Use MongoDB bulk write operations for efficiency
-	var operations []mongo.WriteModel
+	failed := make(map[string]error)
+	operations := make([]mongo.WriteModel, 0, len(updates))
+	vehicleIDs := make([]string, 0, len(updates)) // vehicle of each operation, by index
 
 	for vehicleID, update := range updates {
 		objectID, err := primitive.ObjectIDFromHex(vehicleID)
 		if err != nil {
-			return fmt.Errorf("invalid vehicle ID %s: %w", vehicleID, err)
-		}
-
-		// Build update document with only non-nil fields
-		updateDoc := bson.M{
-			"last_update": update.Timestamp,
-			"updated_at":  time.Now(),
-		}
-
-		if update.FuelLevel != nil {
-			updateDoc["fuel_level"] = *update.FuelLevel
-		}
-		if update.Location != nil {
-			updateDoc["location"] = *update.Location
-		}
-		if update.Speed != nil {
-			updateDoc["speed"] = *update.Speed
-		}
-		if update.Status != nil {
-			updateDoc["status"] = *update.Status
-		}
-		if update.Odometer != nil {
-			updateDoc["odometer"] = *update.Odometer
+			failed[vehicleID] = fmt.Errorf("invalid vehicle ID %s: %w", vehicleID, err)
+			continue
 		}
 
 		operation := mongo.NewUpdateOneModel().
 			SetFilter(bson.M{"_id": objectID}).
-			SetUpdate(bson.M{"$set": updateDoc}).
+			SetUpdate(bson.M{"$set": buildUpdateDoc(update)}).
 			SetUpsert(false)
 
 		operations = append(operations, operation)
+		vehicleIDs = append(vehicleIDs, vehicleID)
+	}
+
+	if len(operations) > 0 {
+		// Unordered so one failing update doesn't stop the rest of the batch
+		result, err := writer.BulkWrite(ctx, operations, options.BulkWrite().SetOrdered(false))
+
+		var bulkErr mongo.BulkWriteException
+		switch {
+		case errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && len(bulkErr.WriteErrors) > 0:
+			for _, writeErr := range bulkErr.WriteErrors {
+				if writeErr.Index >= 0 && writeErr.Index < len(vehicleIDs) {
+					failed[vehicleIDs[writeErr.Index]] = writeErr
+				}
+			}
+		case err != nil:
+			return fmt.Errorf("bulk write failed: %w", err)
+		case result != nil && result.MatchedCount < int64(len(operations)):
+			log.Printf("Batch update matched %d of %d vehicles, the rest no longer exist", result.MatchedCount, len(operations))
+		}
 	}
 
-	// Execute bulk write
-	result, err := vra.collection.BulkWrite(ctx, operations)
-	if err != nil {
-		return fmt.Errorf("bulk write failed: %w", err)
-	}
-
-	// Check if all updates were successful
-	expectedUpdates := int64(len(updates))
-	if result.ModifiedCount != expectedUpdates {
-		return fmt.Errorf("expected %d updates, but only %d were modified", expectedUpdates, result.ModifiedCount)
+	if len(failed) > 0 {
+		return &PartialBatchError{Failed: failed}
 	}
-
 	return nil
 }
 
@@ -189,4 +197,4 @@ func (vra *VehicleRepositoryAdapter) GetVehicleLastUpdate(vehicleID string) (tim
 	}
 
 	return result.LastUpdate, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,93 @@
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recordingBulkWriter records BulkWrite calls and fails the operations at failIndexes
+type recordingBulkWriter struct {
+	calls       int
+	models      []mongo.WriteModel
+	ordered     *bool
+	failIndexes []int
+}
+
+func (w *recordingBulkWriter) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	w.calls++
+	w.models = models
+	w.ordered = options.MergeBulkWriteOptions(opts...).Ordered
+
+	if len(w.failIndexes) > 0 {
+		var writeErrors []mongo.BulkWriteError
+		for _, index := range w.failIndexes {
+			writeErrors = append(writeErrors, mongo.BulkWriteError{
+				WriteError: mongo.WriteError{Index: index, Code: 11000, Message: "write failed"},
+			})
+		}
+		return &mongo.BulkWriteResult{}, mongo.BulkWriteException{WriteErrors: writeErrors}
+	}
+
+	count := int64(len(models))
+	return &mongo.BulkWriteResult{MatchedCount: count, ModifiedCount: count}, nil
+}
+
+func TestUpdateVehiclesBatch_SingleUnorderedBulkWrite(t *testing.T) {
+	writer := &recordingBulkWriter{}
+	updates := map[string]VehicleUpdateData{
+		primitive.NewObjectID().Hex(): {FuelLevel: floatPtr(40), Timestamp: time.Now()},
+		primitive.NewObjectID().Hex(): {Speed: intPtr(60), Timestamp: time.Now()},
+		primitive.NewObjectID().Hex(): {Odometer: intPtr(1200), Timestamp: time.Now()},
+	}
+
+	err := updateVehiclesBatch(writer, updates)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, writer.calls)
+	assert.Len(t, writer.models, len(updates))
+	require.NotNil(t, writer.ordered)
+	assert.False(t, *writer.ordered)
+
+	// Only the non-nil fields are set
+	for _, model := range writer.models {
+		set := model.(*mongo.UpdateOneModel).Update.(bson.M)["$set"].(bson.M)
+		assert.Len(t, set, 4) // timestamps and the one changed field
+	}
+}
+
+func TestUpdateVehiclesBatch_ReportsFailedVehicles(t *testing.T) {
+	writer := &recordingBulkWriter{failIndexes: []int{0}}
+	updates := map[string]VehicleUpdateData{
+		primitive.NewObjectID().Hex(): {Speed: intPtr(60), Timestamp: time.Now()},
+		primitive.NewObjectID().Hex(): {Speed: intPtr(70), Timestamp: time.Now()},
+		"not-an-object-id":            {Speed: intPtr(80), Timestamp: time.Now()},
+	}
+
+	err := updateVehiclesBatch(writer, updates)
+
+	var partialErr *PartialBatchError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 1, writer.calls)
+	assert.Len(t, writer.models, 2) // the invalid ID never reaches Mongo
+	assert.Len(t, partialErr.Failed, 2)
+	assert.Contains(t, partialErr.Failed, "not-an-object-id")
+
+	// The failed operation maps back to its vehicle
+	failedFilter := writer.models[0].(*mongo.UpdateOneModel).Filter.(bson.M)["_id"].(primitive.ObjectID)
+	assert.Contains(t, partialErr.Failed, failedFilter.Hex())
+}
+
+func TestUpdateVehiclesBatch_Empty(t *testing.T) {
+	writer := &recordingBulkWriter{}
+
+	assert.NoError(t, updateVehiclesBatch(writer, nil))
+	assert.Zero(t, writer.calls)
+}
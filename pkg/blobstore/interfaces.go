@@ -0,0 +1,20 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque file contents under slash-separated keys, such as
+// the documents attached to maintenance records
+type BlobStore interface {
+	// Put stores the contents of r under key, replacing any existing blob
+	Put(key string, r io.Reader, contentType string) error
+	// Get opens the blob stored under key; the caller closes it
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key; deleting a missing blob is not an error
+	Delete(key string) error
+}
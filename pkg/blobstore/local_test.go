@@ -0,0 +1,38 @@
+package blobstore
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("maintenance/r1/a1", strings.NewReader("receipt"), "text/plain"))
+
+	blob, err := store.Get("maintenance/r1/a1")
+	require.NoError(t, err)
+	content, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	assert.Equal(t, "receipt", string(content))
+
+	require.NoError(t, store.Delete("maintenance/r1/a1"))
+	_, err = store.Get("maintenance/r1/a1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete("maintenance/r1/a1"), "deleting a missing blob is not an error")
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a/../../outside", "a//b", `a\b`} {
+		assert.Error(t, store.Put(key, strings.NewReader("x"), ""), key)
+	}
+}
@@ -0,0 +1,103 @@
+package blobstore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 keeps objects in memory by request path and records the last request
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	last    *http.Request
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.last = r
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.EscapedPath()] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3Store(t *testing.T) (*S3Store, *fakeS3) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "fleet-attachments",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC) }
+	return store, fake
+}
+
+func TestS3Store_PutGetDelete(t *testing.T) {
+	store, fake := newTestS3Store(t)
+
+	require.NoError(t, store.Put("maintenance/r1/oil change+receipt.pdf", strings.NewReader("receipt"), "application/pdf"))
+	assert.Equal(t, "/fleet-attachments/maintenance/r1/oil%20change%2Breceipt.pdf", fake.last.URL.EscapedPath())
+	assert.Equal(t, "application/pdf", fake.last.Header.Get("Content-Type"))
+	assert.Equal(t, "20240524T000000Z", fake.last.Header.Get("X-Amz-Date"))
+	assert.True(t, strings.HasPrefix(fake.last.Header.Get("Authorization"),
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240524/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+
+	blob, err := store.Get("maintenance/r1/oil change+receipt.pdf")
+	require.NoError(t, err)
+	content, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	blob.Close()
+	assert.Equal(t, "receipt", string(content))
+
+	require.NoError(t, store.Delete("maintenance/r1/oil change+receipt.pdf"))
+	_, err = store.Get("maintenance/r1/oil change+receipt.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestS3Store_SignatureCoversPayload(t *testing.T) {
+	store, fake := newTestS3Store(t)
+
+	require.NoError(t, store.Put("a", strings.NewReader("one"), ""))
+	first := fake.last.Header.Get("Authorization")
+	require.NoError(t, store.Put("a", strings.NewReader("two"), ""))
+	assert.NotEqual(t, first, fake.last.Header.Get("Authorization"))
+	assert.Equal(t, sha256Hex([]byte("two")), fake.last.Header.Get("X-Amz-Content-Sha256"))
+}
+
+func TestNewS3Store_RequiresBucketAndCredentials(t *testing.T) {
+	_, err := NewS3Store(S3Config{Region: "eu-west-1", AccessKeyID: "a", SecretAccessKey: "b"})
+	assert.Error(t, err)
+	_, err = NewS3Store(S3Config{Region: "eu-west-1", Bucket: "b"})
+	assert.Error(t, err)
+
+	store, err := NewS3Store(S3Config{Region: "eu-west-1", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3.eu-west-1.amazonaws.com", store.endpoint.Host)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Defaults for adaptive TTLs
+const (
+	DefaultAdaptiveTTLMinFactor = 0.25
+	DefaultAdaptiveTTLMaxFactor = 4.0
+	DefaultAdaptiveTTLMaxKeys   = 10000
+)
+
+// adaptiveTTL scales the TTL of each cache key by how it has been used: keys
+// that keep being hit are kept longer and keys that keep being invalidated
+// expire sooner. A key's TTL is multiplied by (1 + hits) / 2 and divided by
+// (1 + invalidations), counted since it was last stored and clamped to
+// [minFactor, maxFactor]; a key read once keeps its base TTL. Each store
+// halves the counts, so the TTL follows changes in how a key is used. Keys
+// without any recorded use keep their base TTL.
+type adaptiveTTL struct {
+	mu        sync.Mutex
+	minFactor float64
+	maxFactor float64
+	maxKeys   int
+	usage     map[string]*keyUsage
+}
+
+// keyUsage is how often a key was hit and invalidated, decayed on each store
+type keyUsage struct {
+	hits          int
+	invalidations int
+}
+
+// newAdaptiveTTL creates a tracker, using the defaults for non-positive
+// settings. Keys that are never stored again stay tracked, so once maxKeys
+// keys are tracked all usage is dropped and counting starts over.
+func newAdaptiveTTL(minFactor, maxFactor float64, maxKeys int) *adaptiveTTL {
+	if minFactor <= 0 {
+		minFactor = DefaultAdaptiveTTLMinFactor
+	}
+	if maxFactor <= 0 {
+		maxFactor = DefaultAdaptiveTTLMaxFactor
+	}
+	if maxKeys <= 0 {
+		maxKeys = DefaultAdaptiveTTLMaxKeys
+	}
+	return &adaptiveTTL{
+		minFactor: minFactor,
+		maxFactor: maxFactor,
+		maxKeys:   maxKeys,
+		usage:     make(map[string]*keyUsage),
+	}
+}
+
+// hit records a cache hit on key. A nil tracker records nothing.
+func (a *adaptiveTTL) hit(key string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.track(key).hits++
+}
+
+// invalidated records that key was removed before it expired
+func (a *adaptiveTTL) invalidated(key string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.track(key).invalidations++
+}
+
+// ttl returns the TTL to store key with, given its base TTL. A nil tracker
+// returns the base TTL.
+func (a *adaptiveTTL) ttl(key string, base time.Duration) time.Duration {
+	if a == nil || base <= 0 {
+		return base
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	usage, tracked := a.usage[key]
+	if !tracked {
+		return base
+	}
+
+	factor := float64(1+usage.hits) / 2 / float64(1+usage.invalidations)
+	factor = min(max(factor, a.minFactor), a.maxFactor)
+
+	usage.hits /= 2
+	usage.invalidations /= 2
+	if usage.hits == 0 && usage.invalidations == 0 {
+		delete(a.usage, key)
+	}
+	return time.Duration(float64(base) * factor)
+}
+
+// track returns key's usage, starting to track it if needed
+func (a *adaptiveTTL) track(key string) *keyUsage {
+	usage, tracked := a.usage[key]
+	if tracked {
+		return usage
+	}
+	if len(a.usage) >= a.maxKeys {
+		a.usage = make(map[string]*keyUsage)
+	}
+	usage = &keyUsage{}
+	a.usage[key] = usage
+	return usage
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newAdaptiveTestManager(t *testing.T) (*RedisCacheManager, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	config := DefaultCacheConfig()
+	config.AdaptiveTTLEnabled = true
+	return NewRedisCacheManager(newTestRedisClient(t, mr), config), mr
+}
+
+func TestRedisCacheManager_AdaptiveTTLKeepsHotVehiclesLonger(t *testing.T) {
+	manager, mr := newAdaptiveTestManager(t)
+	hot := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Hot"}
+	oneShot := &models.Vehicle{ID: primitive.NewObjectID(), Name: "One-shot"}
+
+	for _, vehicle := range []*models.Vehicle{hot, oneShot} {
+		require.NoError(t, manager.SetVehicle(vehicle.ID.Hex(), vehicle, time.Minute))
+	}
+	for i := 0; i < 10; i++ {
+		_, err := manager.GetVehicle(hot.ID.Hex())
+		require.NoError(t, err)
+	}
+	_, err := manager.GetVehicle(oneShot.ID.Hex())
+	require.NoError(t, err)
+
+	// Both are cached again, e.g. after a refresh
+	for _, vehicle := range []*models.Vehicle{hot, oneShot} {
+		require.NoError(t, manager.SetVehicle(vehicle.ID.Hex(), vehicle, time.Minute))
+	}
+
+	hotTTL := mr.TTL(manager.buildKey("vehicle", hot.ID.Hex()))
+	oneShotTTL := mr.TTL(manager.buildKey("vehicle", oneShot.ID.Hex()))
+	assert.Equal(t, time.Minute, oneShotTTL)
+	assert.Greater(t, hotTTL, oneShotTTL)
+	assert.Equal(t, 4*time.Minute, hotTTL, "capped at the max factor")
+}
+
+func TestRedisCacheManager_AdaptiveTTLShortensInvalidatedVehicles(t *testing.T) {
+	manager, mr := newAdaptiveTestManager(t)
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Name: "Churning"}
+	id := vehicle.ID.Hex()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, manager.SetVehicle(id, vehicle, time.Minute))
+		require.NoError(t, manager.InvalidateVehicle(id))
+	}
+	require.NoError(t, manager.SetVehicle(id, vehicle, time.Minute))
+
+	assert.Less(t, mr.TTL(manager.buildKey("vehicle", id)), time.Minute)
+}
+
+func TestRedisCacheManager_StaticTTLWhenAdaptiveDisabled(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	manager := NewRedisCacheManager(newTestRedisClient(t, mr), DefaultCacheConfig())
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+	id := vehicle.ID.Hex()
+	require.NoError(t, manager.SetVehicle(id, vehicle, time.Minute))
+	for i := 0; i < 10; i++ {
+		_, err := manager.GetVehicle(id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, manager.SetVehicle(id, vehicle, time.Minute))
+
+	assert.Equal(t, time.Minute, mr.TTL(manager.buildKey("vehicle", id)))
+}
+
+func TestAdaptiveTTL_DecaysBetweenStores(t *testing.T) {
+	adaptive := newAdaptiveTTL(0, 0, 0)
+	for i := 0; i < 7; i++ {
+		adaptive.hit("key")
+	}
+
+	assert.Equal(t, 4*time.Second, adaptive.ttl("key", time.Second))
+	// The 7 hits were halved to 3
+	assert.Equal(t, 2*time.Second, adaptive.ttl("key", time.Second))
+	assert.Equal(t, time.Second, adaptive.ttl("key", time.Second))
+	assert.Empty(t, adaptive.usage)
+}
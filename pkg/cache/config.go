@@ -8,12 +8,33 @@ type CacheConfig struct {
 	VehicleListTTL    time.Duration `json:"vehicleListTTL"`    // 2 minutes for list data
 	AlertDataTTL      time.Duration `json:"alertDataTTL"`      // 10 seconds for alerts
 	HistoricalDataTTL time.Duration `json:"historicalDataTTL"` // 10 minutes for historical data
+	SearchResultTTL   time.Duration `json:"searchResultTTL"`   // 15 seconds for search results
+	FilteredListTTL   time.Duration `json:"filteredListTTL"`   // 15 seconds for lists not invalidated on writes
 	MaxMemoryUsage    int64         `json:"maxMemoryUsage"`    // 100MB limit
 	EvictionPolicy    string        `json:"evictionPolicy"`    // "lru"
 	KeyPrefix         string        `json:"keyPrefix"`         // prefix for all cache keys
 	TagPrefix         string        `json:"tagPrefix"`         // prefix for tag keys
+
+	// In-memory fallback used while Redis is unavailable
+	FallbackEnabled    bool `json:"fallbackEnabled"`
+	FallbackMaxEntries int  `json:"fallbackMaxEntries"`
+
+	// Stale-while-revalidate for the full vehicle list: past this age the cached
+	// list is still served while it is refreshed in the background. It must be
+	// below VehicleListTTL to take effect; 0 disables it.
+	VehicleListSoftTTL time.Duration `json:"vehicleListSoftTTL"`
+
+	// Adaptive TTLs: the Redis cache keeps keys with high hit rates longer and
+	// drops frequently invalidated keys sooner, scaling the TTL it is given by
+	// between AdaptiveTTLMinFactor and AdaptiveTTLMaxFactor. Off by default.
+	AdaptiveTTLEnabled   bool    `json:"adaptiveTTLEnabled"`
+	AdaptiveTTLMinFactor float64 `json:"adaptiveTTLMinFactor"`
+	AdaptiveTTLMaxFactor float64 `json:"adaptiveTTLMaxFactor"`
 }
 
+// DefaultFallbackMaxEntries bounds the in-memory fallback cache when no size is configured
+const DefaultFallbackMaxEntries = 1000
+
 // DefaultCacheConfig returns default cache configuration
 func DefaultCacheConfig() CacheConfig {
 	return CacheConfig{
@@ -21,10 +42,16 @@ func DefaultCacheConfig() CacheConfig {
 		VehicleListTTL:    2 * time.Minute,
 		AlertDataTTL:      10 * time.Second,
 		HistoricalDataTTL: 10 * time.Minute,
+		SearchResultTTL:   15 * time.Second,
+		FilteredListTTL:   15 * time.Second,
 		MaxMemoryUsage:    100 * 1024 * 1024, // 100MB
 		EvictionPolicy:    "lru",
 		KeyPrefix:         "fleet:",
 		TagPrefix:         "tag:",
+		FallbackEnabled:    true,
+		FallbackMaxEntries: DefaultFallbackMaxEntries,
+		AdaptiveTTLMinFactor: DefaultAdaptiveTTLMinFactor,
+		AdaptiveTTLMaxFactor: DefaultAdaptiveTTLMaxFactor,
 	}
 }
 
@@ -39,6 +66,10 @@ func (c CacheConfig) GetTTLForDataType(dataType string) time.Duration {
 		return c.AlertDataTTL
 	case "historical":
 		return c.HistoricalDataTTL
+	case "search":
+		return c.SearchResultTTL
+	case "filtered_list":
+		return c.FilteredListTTL
 	default:
 		return c.VehicleDataTTL
 	}
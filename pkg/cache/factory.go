@@ -4,12 +4,20 @@ import (
 	"fleet-backend/pkg/redis"
 )
 
-// NewCacheManager creates a new cache manager with the specified Redis client and configuration
+// NewCacheManager creates a new cache manager with the specified Redis client and configuration.
+// When the in-memory fallback is enabled, reads and writes are served from a bounded
+// LRU while Redis is unavailable.
 func NewCacheManager(redisClient *redis.Client, config CacheConfig) CacheManager {
-	return NewRedisCacheManager(redisClient, config)
+	redisCache := NewRedisCacheManager(redisClient, config)
+	if !config.FallbackEnabled {
+		return redisCache
+	}
+
+	fallback := NewMemoryCacheManager(config, config.FallbackMaxEntries)
+	return NewFallbackCacheManager(redisCache, fallback, redisClient.IsConnected)
 }
 
 // NewDefaultCacheManager creates a new cache manager with default configuration
 func NewDefaultCacheManager(redisClient *redis.Client) CacheManager {
-	return NewRedisCacheManager(redisClient, DefaultCacheConfig())
-}
\ No newline at end of file
+	return NewCacheManager(redisClient, DefaultCacheConfig())
+}
@@ -0,0 +1,315 @@
+package cache
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"fleet-backend/internal/models"
+)
+
+// maxMissedInvalidations bounds the invalidations remembered during an outage
+const maxMissedInvalidations = 10000
+
+// FallbackCacheManager wraps a primary (Redis) cache manager and transparently
+// serves from a bounded in-memory LRU while the primary is unavailable. The
+// fallback is populated by the reads/writes that happen during the outage and
+// is cleared as soon as the primary recovers, so stale data never outlives it.
+// Invalidations the primary missed during the outage are replayed before it
+// serves again, so it doesn't return entries changed in the meantime.
+//
+// There is no separate circuit breaker in front of Redis; this manager is it.
+// While isAvailable (the Redis client's connection monitor) reports the
+// primary down, the breaker is open and the primary isn't called at all. Once
+// it reports the primary up, each call probes the primary: a success closes
+// the breaker and clears the fallback, an error keeps serving the fallback.
+type FallbackCacheManager struct {
+	primary     CacheManager
+	fallback    *MemoryCacheManager
+	isAvailable func() bool // optional external availability signal (e.g. Redis client connection state)
+	degraded    bool
+	missed      map[string]func() error // invalidations still owed to the primary, by target
+	mu          sync.RWMutex
+	replayMu    sync.Mutex
+}
+
+// NewFallbackCacheManager creates a cache manager that falls back to the given
+// in-memory cache whenever the primary errors or isAvailable reports false
+func NewFallbackCacheManager(primary CacheManager, fallback *MemoryCacheManager, isAvailable func() bool) *FallbackCacheManager {
+	return &FallbackCacheManager{
+		primary:     primary,
+		fallback:    fallback,
+		isAvailable: isAvailable,
+		missed:      make(map[string]func() error),
+	}
+}
+
+// IsDegraded reports whether requests are currently being served from the fallback cache
+func (f *FallbackCacheManager) IsDegraded() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.degraded
+}
+
+// GetVehicle retrieves a vehicle from the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) GetVehicle(vehicleID string) (*models.Vehicle, error) {
+	if f.usePrimary() {
+		vehicle, err := f.primary.GetVehicle(vehicleID)
+		if err == nil {
+			f.markRecovered()
+			return vehicle, nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.GetVehicle(vehicleID)
+}
+
+// GetVehicles retrieves vehicles from the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) GetVehicles(vehicleIDs []string) (map[string]*models.Vehicle, error) {
+	if f.usePrimary() {
+		vehicles, err := f.primary.GetVehicles(vehicleIDs)
+		if err == nil {
+			f.markRecovered()
+			return vehicles, nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.GetVehicles(vehicleIDs)
+}
+
+// SetVehicle stores a vehicle in the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error {
+	if f.usePrimary() {
+		err := f.primary.SetVehicle(vehicleID, vehicle, ttl)
+		if err == nil {
+			f.markRecovered()
+			return nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.SetVehicle(vehicleID, vehicle, ttl)
+}
+
+// InvalidateVehicle removes a vehicle from both caches
+func (f *FallbackCacheManager) InvalidateVehicle(vehicleID string) error {
+	f.fallback.InvalidateVehicle(vehicleID)
+	return f.primaryInvalidation("vehicle:"+vehicleID, func() error { return f.primary.InvalidateVehicle(vehicleID) })
+}
+
+// InvalidateVehiclesByTag removes all vehicles with a specific tag from both caches
+func (f *FallbackCacheManager) InvalidateVehiclesByTag(tag string) error {
+	f.fallback.InvalidateVehiclesByTag(tag)
+	return f.primaryInvalidation("vehicle_tag:"+tag, func() error { return f.primary.InvalidateVehiclesByTag(tag) })
+}
+
+// GetVehicleList retrieves a vehicle list from the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) GetVehicleList(key string) ([]*models.Vehicle, error) {
+	if f.usePrimary() {
+		vehicles, err := f.primary.GetVehicleList(key)
+		if err == nil {
+			f.markRecovered()
+			return vehicles, nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.GetVehicleList(key)
+}
+
+// SetVehicleList stores a vehicle list in the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) SetVehicleList(key string, vehicles []*models.Vehicle, ttl time.Duration) error {
+	if f.usePrimary() {
+		err := f.primary.SetVehicleList(key, vehicles, ttl)
+		if err == nil {
+			f.markRecovered()
+			return nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.SetVehicleList(key, vehicles, ttl)
+}
+
+// Get retrieves a generic value from the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) Get(key string, dest interface{}) error {
+	if f.usePrimary() {
+		err := f.primary.Get(key, dest)
+		if err == nil {
+			f.markRecovered()
+			return nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.Get(key, dest)
+}
+
+// Set stores a generic value in the primary cache, or the fallback during an outage
+func (f *FallbackCacheManager) Set(key string, value interface{}, ttl time.Duration) error {
+	if f.usePrimary() {
+		err := f.primary.Set(key, value, ttl)
+		if err == nil {
+			f.markRecovered()
+			return nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.Set(key, value, ttl)
+}
+
+// Delete removes a key from both caches
+func (f *FallbackCacheManager) Delete(key string) error {
+	f.fallback.Delete(key)
+	return f.primaryInvalidation("key:"+key, func() error { return f.primary.Delete(key) })
+}
+
+// TagKey associates tags with a key in whichever cache is currently active
+func (f *FallbackCacheManager) TagKey(key string, tags ...string) error {
+	if f.usePrimary() {
+		err := f.primary.TagKey(key, tags...)
+		if err == nil {
+			return nil
+		}
+		f.markDegraded(err)
+	}
+	return f.fallback.TagKey(key, tags...)
+}
+
+// InvalidateByTag removes all keys associated with a tag from both caches
+func (f *FallbackCacheManager) InvalidateByTag(tag string) error {
+	f.fallback.InvalidateByTag(tag)
+	return f.primaryInvalidation("tag:"+tag, func() error { return f.primary.InvalidateByTag(tag) })
+}
+
+// GetCacheStats returns statistics for whichever cache is currently serving requests
+func (f *FallbackCacheManager) GetCacheStats() CacheStats {
+	if f.IsDegraded() {
+		return f.fallback.GetCacheStats()
+	}
+	return f.primary.GetCacheStats()
+}
+
+// HealthCheck reports the health of the primary cache
+func (f *FallbackCacheManager) HealthCheck() error {
+	return f.primary.HealthCheck()
+}
+
+// Close closes both caches
+func (f *FallbackCacheManager) Close() error {
+	f.fallback.Close()
+	return f.primary.Close()
+}
+
+// usePrimary reports whether the primary cache should be attempted. The
+// primary isn't used until the invalidations it missed have been replayed.
+func (f *FallbackCacheManager) usePrimary() bool {
+	if f.isAvailable != nil && !f.isAvailable() {
+		f.markDegraded(nil)
+		return false
+	}
+	return f.replayMissed()
+}
+
+// primaryInvalidation applies an invalidation to the primary cache while it is
+// reachable. During an outage the fallback is invalidated by the caller and
+// the primary's invalidation is remembered under target for replay.
+func (f *FallbackCacheManager) primaryInvalidation(target string, invalidate func() error) error {
+	if !f.usePrimary() {
+		f.remember(target, invalidate)
+		return nil
+	}
+
+	if err := invalidate(); err != nil {
+		f.markDegraded(err)
+		f.remember(target, invalidate)
+		return nil
+	}
+
+	f.markRecovered()
+	return nil
+}
+
+// remember queues an invalidation the primary missed. Past
+// maxMissedInvalidations further ones are dropped and their entries in the
+// primary live until they expire.
+func (f *FallbackCacheManager) remember(target string, invalidate func() error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, queued := f.missed[target]; !queued && len(f.missed) >= maxMissedInvalidations {
+		log.Printf("Cache invalidation backlog full, %s stays in the primary until it expires", target)
+		return
+	}
+	f.missed[target] = invalidate
+}
+
+// replayMissed applies the invalidations the primary missed, reporting
+// whether all of them have been. Those that fail stay queued.
+func (f *FallbackCacheManager) replayMissed() bool {
+	f.mu.RLock()
+	pending := len(f.missed)
+	f.mu.RUnlock()
+	if pending == 0 {
+		return true
+	}
+
+	f.replayMu.Lock()
+	defer f.replayMu.Unlock()
+
+	f.mu.RLock()
+	missed := make(map[string]func() error, len(f.missed))
+	for target, invalidate := range f.missed {
+		missed[target] = invalidate
+	}
+	f.mu.RUnlock()
+
+	replayed := 0
+	for target, invalidate := range missed {
+		if err := invalidate(); err != nil {
+			f.markDegraded(err)
+			return false
+		}
+
+		f.mu.Lock()
+		delete(f.missed, target)
+		f.mu.Unlock()
+		replayed++
+	}
+
+	if replayed > 0 {
+		log.Printf("Cache primary reachable, replayed %d missed invalidations", replayed)
+	}
+	return true
+}
+
+// markDegraded switches reads and writes over to the fallback cache
+func (f *FallbackCacheManager) markDegraded(cause error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.degraded {
+		f.degraded = true
+		if cause != nil {
+			log.Printf("Cache primary unavailable, serving from in-memory fallback: %v", cause)
+		} else {
+			log.Printf("Cache primary unavailable, serving from in-memory fallback")
+		}
+	}
+}
+
+// markRecovered switches back to the primary cache and drops the fallback contents
+func (f *FallbackCacheManager) markRecovered() {
+	f.mu.RLock()
+	degraded := f.degraded
+	f.mu.RUnlock()
+
+	if !degraded {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.degraded {
+		f.degraded = false
+		f.fallback.Clear()
+		log.Printf("Cache primary recovered, in-memory fallback cleared")
+	}
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/config"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// loadVehicle mimics the cache-aside read path used by the vehicle service and
+// reports whether the database had to be consulted
+func loadVehicle(t *testing.T, manager CacheManager, vehicle *models.Vehicle) bool {
+	cached, err := manager.GetVehicle(vehicle.ID.Hex())
+	require.NoError(t, err)
+	if cached != nil {
+		return false
+	}
+
+	require.NoError(t, manager.SetVehicle(vehicle.ID.Hex(), vehicle, time.Minute))
+	return true
+}
+
+func newTestRedisClient(t *testing.T, mr *miniredis.Miniredis) *redis.Client {
+	client := redis.NewClient(config.RedisConfig{
+		Host:         mr.Host(),
+		Port:         mr.Port(),
+		PoolSize:     2,
+		MaxRetries:   0,
+		DialTimeout:  100 * time.Millisecond,
+		ReadTimeout:  100 * time.Millisecond,
+		WriteTimeout: 100 * time.Millisecond,
+		PoolTimeout:  100 * time.Millisecond,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestFallbackCacheManager_RedisOutage(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	cacheConfig := DefaultCacheConfig()
+	redisCache := NewRedisCacheManager(newTestRedisClient(t, mr), cacheConfig)
+	fallback := NewMemoryCacheManager(cacheConfig, 10)
+	manager := NewFallbackCacheManager(redisCache, fallback, nil)
+
+	vehicle := &models.Vehicle{
+		ID:          primitive.NewObjectID(),
+		Name:        "Test Vehicle",
+		PlateNumber: "ABC123",
+		Driver:      "John Doe",
+		Status:      "active",
+	}
+
+	// Healthy: Redis serves the cache and the fallback stays empty
+	assert.True(t, loadVehicle(t, manager, vehicle))
+	assert.False(t, loadVehicle(t, manager, vehicle))
+	assert.False(t, manager.IsDegraded())
+	assert.Equal(t, 0, fallback.Len())
+
+	// Outage: the first read goes to the database, repeated reads are absorbed in memory
+	mr.Close()
+
+	dbReads := 0
+	for i := 0; i < 5; i++ {
+		if loadVehicle(t, manager, vehicle) {
+			dbReads++
+		}
+	}
+	assert.Equal(t, 1, dbReads)
+	assert.True(t, manager.IsDegraded())
+	assert.Equal(t, 1, fallback.Len())
+
+	// Recovery: the fallback is cleared and Redis serves again
+	require.NoError(t, mr.Restart())
+
+	require.Eventually(t, func() bool { return redisCache.HealthCheck() == nil }, 3*time.Second, 50*time.Millisecond)
+	assert.False(t, loadVehicle(t, manager, vehicle), "vehicle should still be cached in Redis")
+	assert.False(t, manager.IsDegraded())
+	assert.Equal(t, 0, fallback.Len())
+}
+
+// flakyCacheManager is a CacheManager whose operations can be made to fail
+type flakyCacheManager struct {
+	*MemoryCacheManager
+	mu   sync.Mutex
+	down bool
+}
+
+func (f *flakyCacheManager) setDown(down bool) {
+	f.mu.Lock()
+	f.down = down
+	f.mu.Unlock()
+}
+
+func (f *flakyCacheManager) isDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.down
+}
+
+func (f *flakyCacheManager) GetVehicle(vehicleID string) (*models.Vehicle, error) {
+	if f.isDown() {
+		return nil, errors.New("connection refused")
+	}
+	return f.MemoryCacheManager.GetVehicle(vehicleID)
+}
+
+func (f *flakyCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error {
+	if f.isDown() {
+		return errors.New("connection refused")
+	}
+	return f.MemoryCacheManager.SetVehicle(vehicleID, vehicle, ttl)
+}
+
+func TestFallbackCacheManager_AvailabilitySignal(t *testing.T) {
+	primary := &flakyCacheManager{MemoryCacheManager: NewMemoryCacheManager(DefaultCacheConfig(), 10)}
+	fallback := NewMemoryCacheManager(DefaultCacheConfig(), 10)
+
+	available := true
+	var mu sync.Mutex
+	isAvailable := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return available
+	}
+	manager := NewFallbackCacheManager(primary, fallback, isAvailable)
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Driver: "Jane", Status: "idle"}
+
+	// The availability signal short-circuits the primary entirely
+	mu.Lock()
+	available = false
+	mu.Unlock()
+	primary.setDown(true)
+
+	assert.True(t, loadVehicle(t, manager, vehicle))
+	assert.False(t, loadVehicle(t, manager, vehicle))
+	assert.True(t, manager.IsDegraded())
+
+	// Writes during the outage invalidate the fallback copy
+	require.NoError(t, manager.InvalidateVehicle(vehicle.ID.Hex()))
+	assert.Equal(t, 0, fallback.Len())
+
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	primary.setDown(false)
+
+	assert.True(t, loadVehicle(t, manager, vehicle))
+	assert.False(t, manager.IsDegraded())
+}
+
+func (f *flakyCacheManager) InvalidateVehicle(vehicleID string) error {
+	if f.isDown() {
+		return errors.New("connection refused")
+	}
+	return f.MemoryCacheManager.InvalidateVehicle(vehicleID)
+}
+
+func TestFallbackCacheManager_ReplaysMissedInvalidationsOnRecovery(t *testing.T) {
+	primary := &flakyCacheManager{MemoryCacheManager: NewMemoryCacheManager(DefaultCacheConfig(), 10)}
+	manager := NewFallbackCacheManager(primary, NewMemoryCacheManager(DefaultCacheConfig(), 10), nil)
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Driver: "Jane", Status: "idle"}
+	assert.True(t, loadVehicle(t, manager, vehicle))
+
+	// The vehicle changes while the primary is down
+	primary.setDown(true)
+	require.NoError(t, manager.InvalidateVehicle(vehicle.ID.Hex()))
+	assert.True(t, manager.IsDegraded())
+
+	// Once it's back, the primary no longer serves the old copy
+	primary.setDown(false)
+	assert.True(t, loadVehicle(t, manager, vehicle), "stale vehicle should have been invalidated in the primary")
+	assert.False(t, manager.IsDegraded())
+	assert.False(t, loadVehicle(t, manager, vehicle))
+}
@@ -10,6 +10,7 @@ import (
 type CacheManager interface {
 	// Vehicle operations
 	GetVehicle(vehicleID string) (*models.Vehicle, error)
+	GetVehicles(vehicleIDs []string) (map[string]*models.Vehicle, error)
 	SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error
 	InvalidateVehicle(vehicleID string) error
 	InvalidateVehiclesByTag(tag string) error
@@ -0,0 +1,373 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"fleet-backend/internal/models"
+)
+
+// MemoryCacheManager implements CacheManager using a bounded in-process LRU.
+// It is primarily used as a fallback while Redis is unavailable.
+type MemoryCacheManager struct {
+	config     CacheConfig
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	tagKeys    map[string]map[string]struct{} // tag -> keys
+	keyTags    map[string]map[string]struct{} // key -> tags
+	stats      *cacheStats
+	mu         sync.Mutex
+}
+
+// memoryEntry is a single cached value stored in the LRU list
+type memoryEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheManager creates a new in-memory LRU cache manager holding at most maxEntries keys
+func NewMemoryCacheManager(config CacheConfig, maxEntries int) *MemoryCacheManager {
+	if maxEntries <= 0 {
+		maxEntries = DefaultFallbackMaxEntries
+	}
+
+	return &MemoryCacheManager{
+		config:     config,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		tagKeys:    make(map[string]map[string]struct{}),
+		keyTags:    make(map[string]map[string]struct{}),
+		stats:      &cacheStats{},
+	}
+}
+
+// GetVehicle retrieves a vehicle from the in-memory cache
+func (m *MemoryCacheManager) GetVehicle(vehicleID string) (*models.Vehicle, error) {
+	data, ok := m.getEntry(m.buildKey("vehicle", vehicleID))
+	if !ok {
+		return nil, nil // Cache miss, not an error
+	}
+
+	var vehicle models.Vehicle
+	if err := json.Unmarshal(data, &vehicle); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vehicle data: %w", err)
+	}
+
+	return &vehicle, nil
+}
+
+// GetVehicles retrieves the cached vehicles among vehicleIDs, keyed by ID
+func (m *MemoryCacheManager) GetVehicles(vehicleIDs []string) (map[string]*models.Vehicle, error) {
+	vehicles := make(map[string]*models.Vehicle, len(vehicleIDs))
+	for _, vehicleID := range vehicleIDs {
+		data, ok := m.getEntry(m.buildKey("vehicle", vehicleID))
+		if !ok {
+			continue
+		}
+
+		var vehicle models.Vehicle
+		if err := json.Unmarshal(data, &vehicle); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal vehicle data: %w", err)
+		}
+		vehicles[vehicleID] = &vehicle
+	}
+
+	return vehicles, nil
+}
+
+// SetVehicle stores a vehicle in the in-memory cache with TTL
+func (m *MemoryCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error {
+	key := m.buildKey("vehicle", vehicleID)
+
+	data, err := json.Marshal(vehicle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vehicle data: %w", err)
+	}
+
+	m.setEntry(key, data, ttl)
+
+	return m.TagKey(key,
+		fmt.Sprintf("vehicle:%s", vehicleID),
+		fmt.Sprintf("driver:%s", vehicle.Driver),
+		fmt.Sprintf("status:%s", vehicle.Status),
+	)
+}
+
+// InvalidateVehicle removes a specific vehicle from the in-memory cache
+func (m *MemoryCacheManager) InvalidateVehicle(vehicleID string) error {
+	return m.Delete(m.buildKey("vehicle", vehicleID))
+}
+
+// InvalidateVehiclesByTag removes all vehicles with a specific tag
+func (m *MemoryCacheManager) InvalidateVehiclesByTag(tag string) error {
+	return m.InvalidateByTag(tag)
+}
+
+// GetVehicleList retrieves a list of vehicles from the in-memory cache
+func (m *MemoryCacheManager) GetVehicleList(key string) ([]*models.Vehicle, error) {
+	data, ok := m.getEntry(m.buildKey("vehicle_list", key))
+	if !ok {
+		return nil, nil // Cache miss
+	}
+
+	var vehicles []*models.Vehicle
+	if err := json.Unmarshal(data, &vehicles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vehicle list data: %w", err)
+	}
+
+	return vehicles, nil
+}
+
+// SetVehicleList stores a list of vehicles in the in-memory cache
+func (m *MemoryCacheManager) SetVehicleList(key string, vehicles []*models.Vehicle, ttl time.Duration) error {
+	cacheKey := m.buildKey("vehicle_list", key)
+
+	data, err := json.Marshal(vehicles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vehicle list data: %w", err)
+	}
+
+	m.setEntry(cacheKey, data, ttl)
+
+	var tags []string
+	for _, vehicle := range vehicles {
+		tags = append(tags, fmt.Sprintf("vehicle:%s", vehicle.ID.Hex()))
+	}
+
+	return m.TagKey(cacheKey, tags...)
+}
+
+// Get retrieves a generic value from the in-memory cache
+func (m *MemoryCacheManager) Get(key string, dest interface{}) error {
+	data, ok := m.getEntry(m.buildKey("generic", key))
+	if !ok {
+		return nil // Cache miss
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return nil
+}
+
+// Set stores a generic value in the in-memory cache
+func (m *MemoryCacheManager) Set(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	m.setEntry(m.buildKey("generic", key), data, ttl)
+	return nil
+}
+
+// Delete removes a key from the in-memory cache
+func (m *MemoryCacheManager) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(key)
+	return nil
+}
+
+// TagKey associates tags with a cache key for intelligent invalidation
+func (m *MemoryCacheManager) TagKey(key string, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Tags only make sense for keys that are still resident
+	if _, exists := m.entries[key]; !exists {
+		return nil
+	}
+
+	if m.keyTags[key] == nil {
+		m.keyTags[key] = make(map[string]struct{})
+	}
+
+	for _, tag := range tags {
+		if m.tagKeys[tag] == nil {
+			m.tagKeys[tag] = make(map[string]struct{})
+		}
+		m.tagKeys[tag][key] = struct{}{}
+		m.keyTags[key][tag] = struct{}{}
+	}
+
+	return nil
+}
+
+// InvalidateByTag removes all keys associated with a tag
+func (m *MemoryCacheManager) InvalidateByTag(tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := m.tagKeys[tag]
+	evicted := len(keys)
+	for key := range keys {
+		m.removeLocked(key)
+	}
+	delete(m.tagKeys, tag)
+
+	m.stats.mu.Lock()
+	m.stats.evictionCount += int64(evicted)
+	m.stats.mu.Unlock()
+
+	return nil
+}
+
+// Clear removes every entry from the in-memory cache
+func (m *MemoryCacheManager) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make(map[string]*list.Element)
+	m.lru.Init()
+	m.tagKeys = make(map[string]map[string]struct{})
+	m.keyTags = make(map[string]map[string]struct{})
+}
+
+// Len returns the number of entries currently held in memory
+func (m *MemoryCacheManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.lru.Len()
+}
+
+// GetCacheStats returns cache performance statistics
+func (m *MemoryCacheManager) GetCacheStats() CacheStats {
+	m.mu.Lock()
+	keyCount := m.lru.Len()
+	var memoryUsage int64
+	for e := m.lru.Front(); e != nil; e = e.Next() {
+		memoryUsage += int64(len(e.Value.(*memoryEntry).data))
+	}
+	m.mu.Unlock()
+
+	m.stats.mu.RLock()
+	totalHits := m.stats.totalHits
+	totalMisses := m.stats.totalMisses
+	evictionCount := m.stats.evictionCount
+	m.stats.mu.RUnlock()
+
+	total := totalHits + totalMisses
+	var hitRate, missRate float64
+	if total > 0 {
+		hitRate = float64(totalHits) / float64(total)
+		missRate = float64(totalMisses) / float64(total)
+	}
+
+	return CacheStats{
+		HitRate:       hitRate,
+		MissRate:      missRate,
+		MemoryUsage:   memoryUsage,
+		KeyCount:      keyCount,
+		EvictionCount: int(evictionCount),
+		TotalHits:     totalHits,
+		TotalMisses:   totalMisses,
+	}
+}
+
+// HealthCheck always succeeds for the in-memory cache
+func (m *MemoryCacheManager) HealthCheck() error {
+	return nil
+}
+
+// Close releases all cached entries
+func (m *MemoryCacheManager) Close() error {
+	m.Clear()
+	return nil
+}
+
+// Helper methods
+
+func (m *MemoryCacheManager) buildKey(keyType, identifier string) string {
+	return fmt.Sprintf("%s%s:%s", m.config.KeyPrefix, keyType, identifier)
+}
+
+// getEntry returns the cached bytes for key, promoting it to most recently used
+func (m *MemoryCacheManager) getEntry(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, exists := m.entries[key]
+	if !exists {
+		m.recordMiss()
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		m.removeLocked(key)
+		m.recordMiss()
+		return nil, false
+	}
+
+	m.lru.MoveToFront(element)
+	m.recordHit()
+	return entry.data, true
+}
+
+// setEntry stores data under key, evicting the least recently used entries when full
+func (m *MemoryCacheManager) setEntry(key string, data []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, exists := m.entries[key]; exists {
+		entry := element.Value.(*memoryEntry)
+		entry.data = data
+		entry.expiresAt = expiresAt
+		m.lru.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryEntry{key: key, data: data, expiresAt: expiresAt})
+
+	for m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.removeLocked(oldest.Value.(*memoryEntry).key)
+
+		m.stats.mu.Lock()
+		m.stats.evictionCount++
+		m.stats.mu.Unlock()
+	}
+}
+
+// removeLocked deletes key and its tag associations; the caller must hold m.mu
+func (m *MemoryCacheManager) removeLocked(key string) {
+	if element, exists := m.entries[key]; exists {
+		m.lru.Remove(element)
+		delete(m.entries, key)
+	}
+
+	for tag := range m.keyTags[key] {
+		delete(m.tagKeys[tag], key)
+		if len(m.tagKeys[tag]) == 0 {
+			delete(m.tagKeys, tag)
+		}
+	}
+	delete(m.keyTags, key)
+}
+
+func (m *MemoryCacheManager) recordHit() {
+	m.stats.mu.Lock()
+	m.stats.totalHits++
+	m.stats.mu.Unlock()
+}
+
+func (m *MemoryCacheManager) recordMiss() {
+	m.stats.mu.Lock()
+	m.stats.totalMisses++
+	m.stats.mu.Unlock()
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryCacheManager_VehicleOperations(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 10)
+
+	vehicle := &models.Vehicle{
+		ID:          primitive.NewObjectID(),
+		Name:        "Test Vehicle",
+		PlateNumber: "ABC123",
+		Driver:      "John Doe",
+		Status:      "active",
+	}
+	vehicleID := vehicle.ID.Hex()
+
+	cached, err := manager.GetVehicle(vehicleID)
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+
+	require.NoError(t, manager.SetVehicle(vehicleID, vehicle, time.Minute))
+
+	cached, err = manager.GetVehicle(vehicleID)
+	assert.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, vehicle.PlateNumber, cached.PlateNumber)
+
+	// Keys built by callers (as the vehicle service does) must address the same entry
+	require.NoError(t, manager.Delete("fleet:vehicle:"+vehicleID))
+	cached, err = manager.GetVehicle(vehicleID)
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+
+	stats := manager.GetCacheStats()
+	assert.Equal(t, int64(1), stats.TotalHits)
+	assert.Equal(t, int64(2), stats.TotalMisses)
+}
+
+func TestMemoryCacheManager_LRUEviction(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 2)
+
+	require.NoError(t, manager.Set("a", "1", time.Minute))
+	require.NoError(t, manager.Set("b", "2", time.Minute))
+
+	// Touch "a" so that "b" becomes the least recently used entry
+	var value string
+	require.NoError(t, manager.Get("a", &value))
+	assert.Equal(t, "1", value)
+
+	require.NoError(t, manager.Set("c", "3", time.Minute))
+	assert.Equal(t, 2, manager.Len())
+
+	value = ""
+	require.NoError(t, manager.Get("b", &value))
+	assert.Empty(t, value, "least recently used entry should be evicted")
+
+	require.NoError(t, manager.Get("a", &value))
+	assert.Equal(t, "1", value)
+	assert.Equal(t, 1, manager.GetCacheStats().EvictionCount)
+}
+
+func TestMemoryCacheManager_TTLExpiry(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 10)
+
+	require.NoError(t, manager.Set("short", "value", 20*time.Millisecond))
+	time.Sleep(40 * time.Millisecond)
+
+	var value string
+	require.NoError(t, manager.Get("short", &value))
+	assert.Empty(t, value)
+	assert.Equal(t, 0, manager.Len())
+}
+
+func TestMemoryCacheManager_InvalidateByTag(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 10)
+
+	vehicle := &models.Vehicle{ID: primitive.NewObjectID(), Driver: "Jane", Status: "idle"}
+	require.NoError(t, manager.SetVehicle(vehicle.ID.Hex(), vehicle, time.Minute))
+	require.NoError(t, manager.SetVehicleList("all_vehicles", []*models.Vehicle{vehicle}, time.Minute))
+	assert.Equal(t, 2, manager.Len())
+
+	require.NoError(t, manager.InvalidateByTag("vehicle:"+vehicle.ID.Hex()))
+	assert.Equal(t, 0, manager.Len())
+	assert.Equal(t, 2, manager.GetCacheStats().EvictionCount)
+}
+
+func TestMemoryCacheManager_GetVehicles(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 10)
+
+	ids := make([]string, 5)
+	for i := range ids {
+		vehicle := &models.Vehicle{ID: primitive.NewObjectID()}
+		ids[i] = vehicle.ID.Hex()
+		if i < 3 {
+			require.NoError(t, manager.SetVehicle(ids[i], vehicle, time.Minute))
+		}
+	}
+
+	vehicles, err := manager.GetVehicles(ids)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 3)
+	for _, id := range ids[:3] {
+		assert.Contains(t, vehicles, id)
+	}
+
+	stats := manager.GetCacheStats()
+	assert.Equal(t, int64(3), stats.TotalHits)
+	assert.Equal(t, int64(2), stats.TotalMisses)
+}
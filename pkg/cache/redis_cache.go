@@ -21,6 +21,9 @@ type RedisCacheManager struct {
 	config CacheConfig
 	stats  *cacheStats
 	ctx    context.Context
+
+	// adaptive scales TTLs by key usage; nil unless AdaptiveTTLEnabled
+	adaptive *adaptiveTTL
 }
 
 // cacheStats tracks cache performance metrics
@@ -33,12 +36,16 @@ type cacheStats struct {
 
 // NewRedisCacheManager creates a new Redis-backed cache manager
 func NewRedisCacheManager(redisClient *redis.Client, config CacheConfig) *RedisCacheManager {
-	return &RedisCacheManager{
+	manager := &RedisCacheManager{
 		client: redisClient,
 		config: config,
 		stats:  &cacheStats{},
 		ctx:    context.Background(),
 	}
+	if config.AdaptiveTTLEnabled {
+		manager.adaptive = newAdaptiveTTL(config.AdaptiveTTLMinFactor, config.AdaptiveTTLMaxFactor, DefaultAdaptiveTTLMaxKeys)
+	}
+	return manager
 }
 
 // GetVehicle retrieves a vehicle from cache
@@ -60,9 +67,50 @@ func (r *RedisCacheManager) GetVehicle(vehicleID string) (*models.Vehicle, error
 	}
 	
 	r.recordHit()
+	r.adaptive.hit(key)
 	return &vehicle, nil
 }
 
+// GetVehicles retrieves several vehicles from cache in a single MGET. Only
+// the vehicles found are returned, keyed by ID; the caller loads the misses.
+func (r *RedisCacheManager) GetVehicles(vehicleIDs []string) (map[string]*models.Vehicle, error) {
+	vehicles := make(map[string]*models.Vehicle, len(vehicleIDs))
+	if len(vehicleIDs) == 0 {
+		return vehicles, nil
+	}
+	
+	keys := make([]string, len(vehicleIDs))
+	for i, vehicleID := range vehicleIDs {
+		keys[i] = r.buildKey("vehicle", vehicleID)
+	}
+	
+	values, err := r.client.GetClient().MGet(r.ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicles from cache: %w", err)
+	}
+	
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			r.recordMiss()
+			continue
+		}
+		
+		var vehicle models.Vehicle
+		if err := json.Unmarshal([]byte(data), &vehicle); err != nil {
+			// Let the caller reload an entry that can't be decoded
+			r.recordMiss()
+			continue
+		}
+		
+		r.recordHit()
+		r.adaptive.hit(keys[i])
+		vehicles[vehicleIDs[i]] = &vehicle
+	}
+	
+	return vehicles, nil
+}
+
 // SetVehicle stores a vehicle in cache with TTL
 func (r *RedisCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle, ttl time.Duration) error {
 	key := r.buildKey("vehicle", vehicleID)
@@ -72,7 +120,7 @@ func (r *RedisCacheManager) SetVehicle(vehicleID string, vehicle *models.Vehicle
 		return fmt.Errorf("failed to marshal vehicle data: %w", err)
 	}
 	
-	if err := r.client.GetClient().Set(r.ctx, key, data, ttl).Err(); err != nil {
+	if err := r.client.GetClient().Set(r.ctx, key, data, r.adaptive.ttl(key, ttl)).Err(); err != nil {
 		return fmt.Errorf("failed to set vehicle in cache: %w", err)
 	}
 	
@@ -121,6 +169,7 @@ func (r *RedisCacheManager) GetVehicleList(key string) ([]*models.Vehicle, error
 	}
 	
 	r.recordHit()
+	r.adaptive.hit(cacheKey)
 	return vehicles, nil
 }
 
@@ -133,7 +182,7 @@ func (r *RedisCacheManager) SetVehicleList(key string, vehicles []*models.Vehicl
 		return fmt.Errorf("failed to marshal vehicle list data: %w", err)
 	}
 	
-	if err := r.client.GetClient().Set(r.ctx, cacheKey, data, ttl).Err(); err != nil {
+	if err := r.client.GetClient().Set(r.ctx, cacheKey, data, r.adaptive.ttl(cacheKey, ttl)).Err(); err != nil {
 		return fmt.Errorf("failed to set vehicle list in cache: %w", err)
 	}
 	
@@ -168,6 +217,7 @@ func (r *RedisCacheManager) Get(key string, dest interface{}) error {
 	}
 	
 	r.recordHit()
+	r.adaptive.hit(cacheKey)
 	return nil
 }
 
@@ -180,7 +230,7 @@ func (r *RedisCacheManager) Set(key string, value interface{}, ttl time.Duration
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
 	
-	return r.client.GetClient().Set(r.ctx, cacheKey, data, ttl).Err()
+	return r.client.GetClient().Set(r.ctx, cacheKey, data, r.adaptive.ttl(cacheKey, ttl)).Err()
 }
 
 // Delete removes a key from cache
@@ -190,6 +240,7 @@ func (r *RedisCacheManager) Delete(key string) error {
 		fmt.Printf("Warning: failed to remove tags for key %s: %v\n", key, err)
 	}
 	
+	r.adaptive.invalidated(key)
 	return r.client.GetClient().Del(r.ctx, key).Err()
 }
 
@@ -200,13 +251,13 @@ func (r *RedisCacheManager) TagKey(key string, tags ...string) error {
 	// Store key-to-tags mapping
 	keyTagsKey := r.buildTagKey("key_tags", key)
 	pipe.SAdd(r.ctx, keyTagsKey, tags)
-	pipe.Expire(r.ctx, keyTagsKey, r.config.VehicleDataTTL*2) // Tags live longer than data
+	pipe.Expire(r.ctx, keyTagsKey, r.tagTTL()) // Tags live longer than data
 	
 	// Store tag-to-keys mapping
 	for _, tag := range tags {
 		tagKeysKey := r.buildTagKey("tag_keys", tag)
 		pipe.SAdd(r.ctx, tagKeysKey, key)
-		pipe.Expire(r.ctx, tagKeysKey, r.config.VehicleDataTTL*2)
+		pipe.Expire(r.ctx, tagKeysKey, r.tagTTL())
 	}
 	
 	_, err := pipe.Exec(r.ctx)
@@ -249,6 +300,10 @@ func (r *RedisCacheManager) InvalidateByTag(tag string) error {
 	r.stats.evictionCount += int64(len(keys))
 	r.stats.mu.Unlock()
 	
+	for _, key := range keys {
+		r.adaptive.invalidated(key)
+	}
+	
 	return nil
 }
 
@@ -319,6 +374,16 @@ func (r *RedisCacheManager) buildTagKey(keyType, identifier string) string {
 	return fmt.Sprintf("%s%s:%s", r.config.TagPrefix, keyType, identifier)
 }
 
+// tagTTL is how long tag associations are kept, long enough to outlive the
+// data they tag even when adaptive TTLs stretched it
+func (r *RedisCacheManager) tagTTL() time.Duration {
+	ttl := r.config.VehicleDataTTL * 2
+	if r.adaptive != nil && r.adaptive.maxFactor > 1 {
+		ttl = time.Duration(float64(ttl) * r.adaptive.maxFactor)
+	}
+	return ttl
+}
+
 func (r *RedisCacheManager) recordHit() {
 	r.stats.mu.Lock()
 	r.stats.totalHits++
@@ -393,6 +393,45 @@ func TestRedisCacheManager_Stats(t *testing.T) {
 	})
 }
 
+func TestRedisCacheManager_GetVehicles(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	
+	manager := NewRedisCacheManager(newTestRedisClient(t, mr), DefaultCacheConfig())
+	
+	ids := make([]string, 5)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID().Hex()
+	}
+	for _, id := range ids[:3] {
+		objectID, _ := primitive.ObjectIDFromHex(id)
+		require.NoError(t, manager.SetVehicle(id, &models.Vehicle{ID: objectID, Name: "Vehicle " + id}, time.Minute))
+	}
+	
+	vehicles, err := manager.GetVehicles(ids)
+	require.NoError(t, err)
+	
+	require.Len(t, vehicles, 3)
+	for _, id := range ids[:3] {
+		require.Contains(t, vehicles, id)
+		assert.Equal(t, "Vehicle "+id, vehicles[id].Name)
+	}
+	for _, id := range ids[3:] {
+		assert.NotContains(t, vehicles, id)
+	}
+	
+	stats := manager.GetCacheStats()
+	assert.Equal(t, int64(3), stats.TotalHits)
+	assert.Equal(t, int64(2), stats.TotalMisses)
+	
+	// Nothing to fetch, nothing counted
+	vehicles, err = manager.GetVehicles(nil)
+	require.NoError(t, err)
+	assert.Empty(t, vehicles)
+	assert.Equal(t, int64(5), manager.GetCacheStats().TotalHits+manager.GetCacheStats().TotalMisses)
+}
+
 func TestRedisCacheManager_HealthCheck(t *testing.T) {
 	mr, err := miniredis.Run()
 	require.NoError(t, err)
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"fleet-backend/internal/models"
+)
+
+// VehicleListLoader loads a vehicle list from the source of truth
+type VehicleListLoader func() ([]*models.Vehicle, error)
+
+// StaleWhileRevalidate serves cached vehicle lists with a soft and a hard TTL.
+// A list is cached for the hard TTL; once it is older than the soft TTL the
+// cached list is still returned right away while the loader refreshes it in
+// the background, so callers don't wait on the database whenever it expires.
+//
+// The soft expiry is stored next to the list under its own key, with the hard
+// TTL, so it is shared by every replica using the same cache. A list without
+// one, such as one written by SetVehicleList directly, is treated as fresh.
+type StaleWhileRevalidate struct {
+	cache CacheManager
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+// NewStaleWhileRevalidate creates stale-while-revalidate reads over cache
+func NewStaleWhileRevalidate(cache CacheManager) *StaleWhileRevalidate {
+	return &StaleWhileRevalidate{
+		cache:      cache,
+		refreshing: make(map[string]bool),
+	}
+}
+
+// GetVehicleList returns the list cached under key. On a miss it loads and
+// caches the list before returning; past the soft TTL it returns the cached
+// list and starts a background refresh, unless one is already running.
+func (s *StaleWhileRevalidate) GetVehicleList(key string, softTTL, hardTTL time.Duration, loader VehicleListLoader) ([]*models.Vehicle, error) {
+	vehicles, err := s.cache.GetVehicleList(key)
+	if err != nil {
+		log.Printf("Cache error for vehicle list %s: %v", key, err)
+	}
+	if err != nil || vehicles == nil {
+		return s.load(key, softTTL, hardTTL, loader)
+	}
+
+	var softExpiresAt time.Time
+	if err := s.cache.Get(softExpiryKey(key), &softExpiresAt); err != nil {
+		log.Printf("Failed to read soft expiry for vehicle list %s: %v", key, err)
+		return vehicles, nil
+	}
+	if !softExpiresAt.IsZero() && time.Now().After(softExpiresAt) {
+		s.refresh(key, softTTL, hardTTL, loader)
+	}
+	return vehicles, nil
+}
+
+// SetVehicleList caches a list for hardTTL, to be refreshed after softTTL
+func (s *StaleWhileRevalidate) SetVehicleList(key string, vehicles []*models.Vehicle, softTTL, hardTTL time.Duration) error {
+	if err := s.cache.SetVehicleList(key, vehicles, hardTTL); err != nil {
+		return err
+	}
+	return s.cache.Set(softExpiryKey(key), time.Now().Add(softTTL), hardTTL)
+}
+
+// load loads the list and caches it; a caching failure doesn't fail the read
+func (s *StaleWhileRevalidate) load(key string, softTTL, hardTTL time.Duration, loader VehicleListLoader) ([]*models.Vehicle, error) {
+	vehicles, err := loader()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SetVehicleList(key, vehicles, softTTL, hardTTL); err != nil {
+		log.Printf("Failed to cache vehicle list %s: %v", key, err)
+	}
+	return vehicles, nil
+}
+
+// refresh reloads the list in the background, once per key at a time
+func (s *StaleWhileRevalidate) refresh(key string, softTTL, hardTTL time.Duration, loader VehicleListLoader) {
+	s.mu.Lock()
+	if s.refreshing[key] {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing[key] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.refreshing, key)
+			s.mu.Unlock()
+		}()
+
+		// The stale list stays cached until its hard TTL if the refresh fails
+		if _, err := s.load(key, softTTL, hardTTL, loader); err != nil {
+			log.Printf("Background refresh of vehicle list %s failed: %v", key, err)
+		}
+	}()
+}
+
+// softExpiryKey is the generic cache key holding a list's soft expiry
+func softExpiryKey(key string) string {
+	return "vehicle_list_soft_expiry:" + key
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleWhileRevalidate_ServesStaleListWhileRefreshing(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 100)
+	swr := NewStaleWhileRevalidate(manager)
+
+	var loads int32
+	release := make(chan struct{})
+	loader := func() ([]*models.Vehicle, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			return []*models.Vehicle{{Name: "Truck 1"}}, nil
+		}
+		<-release // the refresh blocks until the stale read has returned
+		return []*models.Vehicle{{Name: "Truck 1"}, {Name: "Truck 2"}}, nil
+	}
+
+	vehicles, err := swr.GetVehicleList("all_vehicles", 50*time.Millisecond, time.Minute, loader)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+
+	time.Sleep(60 * time.Millisecond)
+
+	vehicles, err = swr.GetVehicleList("all_vehicles", 50*time.Millisecond, time.Minute, loader)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1, "the stale list is returned without waiting for the loader")
+
+	// A second stale read doesn't start another refresh
+	_, err = swr.GetVehicleList("all_vehicles", 50*time.Millisecond, time.Minute, loader)
+	require.NoError(t, err)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		cached, err := manager.GetVehicleList("all_vehicles")
+		return err == nil && len(cached) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	// The refreshed list is fresh again
+	vehicles, err = swr.GetVehicleList("all_vehicles", 50*time.Millisecond, time.Minute, loader)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 2)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestStaleWhileRevalidate_FailedRefreshKeepsStaleList(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 100)
+	swr := NewStaleWhileRevalidate(manager)
+	require.NoError(t, swr.SetVehicleList("all_vehicles", []*models.Vehicle{{Name: "Truck 1"}}, time.Millisecond, time.Minute))
+	time.Sleep(5 * time.Millisecond)
+
+	var loads int32
+	failing := func() ([]*models.Vehicle, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, errors.New("database unavailable")
+	}
+
+	vehicles, err := swr.GetVehicleList("all_vehicles", time.Millisecond, time.Minute, failing)
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&loads) == 1 }, time.Second, 5*time.Millisecond)
+	cached, err := manager.GetVehicleList("all_vehicles")
+	require.NoError(t, err)
+	assert.Len(t, cached, 1)
+}
+
+func TestStaleWhileRevalidate_MissLoadsSynchronously(t *testing.T) {
+	manager := NewMemoryCacheManager(DefaultCacheConfig(), 100)
+	swr := NewStaleWhileRevalidate(manager)
+
+	vehicles, err := swr.GetVehicleList("all_vehicles", time.Second, time.Minute, func() ([]*models.Vehicle, error) {
+		return []*models.Vehicle{{Name: "Truck 1"}}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, vehicles, 1)
+
+	_, err = swr.GetVehicleList("other", time.Second, time.Minute, func() ([]*models.Vehicle, error) {
+		return nil, errors.New("database unavailable")
+	})
+	assert.Error(t, err)
+}
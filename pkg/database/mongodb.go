@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
@@ -161,6 +162,22 @@ func createIndexes(db *mongo.Database) error {
 		log.Printf("Failed to create alert indexes: %v", err)
 	}
 
+	// Maintenance records collection indexes
+	maintenanceCollection := db.Collection("maintenance_records")
+	maintenanceIndexes := []mongo.IndexModel{
+		{
+			// Serves latest-record-per-vehicle lookups; key order matters here
+			Keys: bson.D{
+				{Key: "vehicle_id", Value: 1},
+				{Key: "performed_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := maintenanceCollection.Indexes().CreateMany(ctx, maintenanceIndexes); err != nil {
+		log.Printf("Failed to create maintenance record indexes: %v", err)
+	}
+
 	log.Println("Database indexes created successfully")
 	return nil
 }
@@ -0,0 +1,65 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"fleet-backend/internal/models"
+)
+
+// OverdueReminderDigest lists the overdue service reminders of a fleet, grouped by vehicle
+type OverdueReminderDigest struct {
+	GeneratedAt time.Time
+	Vehicles    []VehicleReminders
+}
+
+// VehicleReminders are the overdue reminders of one vehicle
+type VehicleReminders struct {
+	VehicleID   string
+	VehicleName string
+	PlateNumber string
+	Reminders   []*models.ServiceReminder
+}
+
+// ReminderCount returns the number of reminders in the digest
+func (d OverdueReminderDigest) ReminderCount() int {
+	count := 0
+	for _, vehicle := range d.Vehicles {
+		count += len(vehicle.Reminders)
+	}
+	return count
+}
+
+type overdueReminderData struct {
+	OverdueReminderDigest
+	MaintenanceLink string
+}
+
+// SendOverdueReminderDigest emails the digest of overdue service reminders to one recipient
+func (s *EmailService) SendOverdueReminderDigest(to string, digest OverdueReminderDigest) error {
+	tmpl, err := template.ParseFS(templateFS, "templates/overdue_reminders.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	data := overdueReminderData{
+		OverdueReminderDigest: digest,
+		MaintenanceLink:       fmt.Sprintf("%s/maintenance", s.appURL),
+	}
+	if err := tmpl.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to execute email template: %w", err)
+	}
+
+	subject := fmt.Sprintf("%d overdue service reminders across %d vehicles - Fleet Backend",
+		digest.ReminderCount(), len(digest.Vehicles))
+	message := s.buildEmailMessage(to, subject, body.String())
+
+	if err := s.sendEmail(to, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
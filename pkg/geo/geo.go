@@ -0,0 +1,54 @@
+package geo
+
+import "math"
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations
+const EarthRadiusMeters = 6371000
+
+// Point is a WGS84 coordinate
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// DistanceMeters returns the great-circle (haversine) distance between two points in meters
+func DistanceMeters(a, b Point) float64 {
+	lat1Rad := a.Lat * math.Pi / 180
+	lat2Rad := b.Lat * math.Pi / 180
+	deltaLat := (b.Lat - a.Lat) * math.Pi / 180
+	deltaLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1Rad)*math.Cos(lat2Rad)*
+			math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+	c := 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+
+	return EarthRadiusMeters * c
+}
+
+// InCircle reports whether p lies within radiusMeters of center
+func InCircle(p, center Point, radiusMeters float64) bool {
+	return DistanceMeters(p, center) <= radiusMeters
+}
+
+// InPolygon reports whether p lies inside the polygon using ray casting. The
+// polygon is treated as planar in lat/lng, which is accurate for zones the size
+// of depots and cities; it may be open or closed (first vertex repeated).
+func InPolygon(p Point, polygon []Point) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) {
+			crossLng := (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat) + a.Lng
+			if p.Lng < crossLng {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
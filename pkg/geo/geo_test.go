@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	nairobi := Point{Lat: -1.2921, Lng: 36.8219}
+	mombasa := Point{Lat: -4.0435, Lng: 39.6682}
+
+	assert.InDelta(t, 440000, DistanceMeters(nairobi, mombasa), 5000)
+	assert.Equal(t, 0.0, DistanceMeters(nairobi, nairobi))
+}
+
+func TestInCircle(t *testing.T) {
+	center := Point{Lat: 40.7128, Lng: -74.0060}
+
+	assert.True(t, InCircle(Point{Lat: 40.7130, Lng: -74.0062}, center, 100))
+	assert.False(t, InCircle(Point{Lat: 40.7228, Lng: -74.0060}, center, 100))
+}
+
+func TestInPolygon(t *testing.T) {
+	square := []Point{
+		{Lat: 0, Lng: 0},
+		{Lat: 0, Lng: 1},
+		{Lat: 1, Lng: 1},
+		{Lat: 1, Lng: 0},
+	}
+
+	assert.True(t, InPolygon(Point{Lat: 0.5, Lng: 0.5}, square))
+	assert.False(t, InPolygon(Point{Lat: 1.5, Lng: 0.5}, square))
+	assert.False(t, InPolygon(Point{Lat: 0.5, Lng: -0.1}, square))
+
+	// Closed rings behave the same as open ones
+	closed := append(square, square[0])
+	assert.True(t, InPolygon(Point{Lat: 0.5, Lng: 0.5}, closed))
+
+	// Concave L-shape: the notch is outside
+	lShape := []Point{
+		{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 1, Lng: 2},
+		{Lat: 1, Lng: 1}, {Lat: 2, Lng: 1}, {Lat: 2, Lng: 0},
+	}
+	assert.True(t, InPolygon(Point{Lat: 1.5, Lng: 0.5}, lShape))
+	assert.False(t, InPolygon(Point{Lat: 1.5, Lng: 1.5}, lShape))
+
+	assert.False(t, InPolygon(Point{Lat: 0, Lng: 0}, square[:2]))
+}
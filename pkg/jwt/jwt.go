@@ -18,6 +18,8 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	Email string `json:"email"`
 	Role     string `json:"role"`
+	// TenantID is the customer the user belongs to in multi-tenant deployments
+	TenantID string `json:"tenant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -50,6 +52,12 @@ func NewJWTUtil() *JWTUtil {
 }
 
 func (j *JWTUtil) GenerateToken(userID, email, role string) (string, error) {
+	return j.GenerateTenantToken(userID, email, role, "")
+}
+
+// GenerateTenantToken generates a token for a user of a tenant; an empty
+// tenant ID leaves the claim out
+func (j *JWTUtil) GenerateTenantToken(userID, email, role, tenantID string) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(j.expiry)
 	
@@ -57,6 +65,7 @@ func (j *JWTUtil) GenerateToken(userID, email, role string) (string, error) {
 		UserID:   userID,
 		Email: email,
 		Role:     role,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -120,7 +129,7 @@ func (j *JWTUtil) RefreshToken(tokenString string) (string, error) {
 	}
 
 	// Generate new token
-	return j.GenerateToken(claims.UserID, claims.Email, claims.Role)
+	return j.GenerateTenantToken(claims.UserID, claims.Email, claims.Role, claims.TenantID)
 }
 
 // parseExpiredToken parses a token without validating expiration
@@ -0,0 +1,17 @@
+package lock
+
+import (
+	"time"
+)
+
+// Locker hands out advisory locks that expire after their TTL unless renewed.
+// Locks belong to the Locker's owner, so replicas sharing a backend each use
+// their own Locker; acquiring a lock the owner already holds extends it.
+type Locker interface {
+	// TryAcquire takes the lock on key for ttl and reports whether the owner holds it
+	TryAcquire(key string, ttl time.Duration) (bool, error)
+	// Renew extends a lock the owner holds and reports whether it was still held
+	Renew(key string, ttl time.Duration) (bool, error)
+	// Release gives up a lock the owner holds; releasing a lock held by another owner is a no-op
+	Release(key string) error
+}
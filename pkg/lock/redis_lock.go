@@ -0,0 +1,202 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extends the lock only if it still belongs to the owner
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock only if it still belongs to the owner
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker implements Locker with Redis keys set by SET NX PX. Locks it
+// acquires are renewed in the background while Start runs, so they are kept
+// until released or until this replica stops renewing them.
+type RedisLocker struct {
+	client    *redis.Client
+	keyPrefix string
+	owner     string
+	ctx       context.Context
+
+	mu       sync.Mutex
+	held     map[string]time.Duration // key -> ttl to renew with
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRedisLocker creates a Redis-backed locker whose locks belong to owner
+func NewRedisLocker(client *redis.Client, keyPrefix, owner string) *RedisLocker {
+	if keyPrefix == "" {
+		keyPrefix = "lock:"
+	}
+	if owner == "" {
+		owner = NewOwnerID()
+	}
+	return &RedisLocker{
+		client:    client,
+		keyPrefix: keyPrefix,
+		owner:     owner,
+		ctx:       context.Background(),
+		held:      make(map[string]time.Duration),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// NewOwnerID returns an ID unique to this process, for telling replicas apart
+func NewOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Owner returns the ID the locker's locks are held under
+func (l *RedisLocker) Owner() string {
+	return l.owner
+}
+
+// TryAcquire takes the lock on key for ttl, or extends it if the owner already holds it
+func (l *RedisLocker) TryAcquire(key string, ttl time.Duration) (bool, error) {
+	acquired, err := l.client.SetNX(l.ctx, l.keyPrefix+key, l.owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !acquired {
+		// The key exists; it may already be ours
+		return l.Renew(key, ttl)
+	}
+
+	l.mu.Lock()
+	l.held[key] = ttl
+	l.mu.Unlock()
+	return true, nil
+}
+
+// Renew extends the lock on key if the owner still holds it
+func (l *RedisLocker) Renew(key string, ttl time.Duration) (bool, error) {
+	renewed, err := l.renew(key, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	if renewed {
+		l.held[key] = ttl
+	} else {
+		delete(l.held, key)
+	}
+	l.mu.Unlock()
+	return renewed, nil
+}
+
+func (l *RedisLocker) renew(key string, ttl time.Duration) (bool, error) {
+	renewed, err := renewScript.Run(l.ctx, l.client, []string{l.keyPrefix + key}, l.owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lock %s: %w", key, err)
+	}
+	return renewed == 1, nil
+}
+
+// Release gives up the lock on key if the owner holds it
+func (l *RedisLocker) Release(key string) error {
+	l.mu.Lock()
+	delete(l.held, key)
+	l.mu.Unlock()
+
+	if err := releaseScript.Run(l.ctx, l.client, []string{l.keyPrefix + key}, l.owner).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", key, err)
+	}
+	return nil
+}
+
+// Start renews the held locks every interval until Stop is called. The
+// interval should be well below the shortest lock TTL.
+func (l *RedisLocker) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.renewHeld()
+		case <-l.stopChan:
+			return
+		}
+	}
+}
+
+// Stop stops renewing and releases the held locks so other replicas can take them over
+func (l *RedisLocker) Stop() {
+	l.stopOnce.Do(func() { close(l.stopChan) })
+
+	for _, key := range l.heldKeys() {
+		if err := l.Release(key); err != nil {
+			log.Printf("Error releasing lock: %v", err)
+		}
+	}
+}
+
+// renewHeld renews every held lock, dropping the ones another owner has taken.
+// Locks released while renewing are not held again.
+func (l *RedisLocker) renewHeld() {
+	l.mu.Lock()
+	held := make(map[string]time.Duration, len(l.held))
+	for key, ttl := range l.held {
+		held[key] = ttl
+	}
+	l.mu.Unlock()
+
+	for key, ttl := range held {
+		renewed, err := l.renew(key, ttl)
+		if err != nil {
+			// Keep the key; the next renewal may succeed before the lock expires
+			log.Printf("Error renewing lock: %v", err)
+			continue
+		}
+
+		// A lock released meanwhile is already gone and must not be held again
+		l.mu.Lock()
+		_, stillHeld := l.held[key]
+		if !renewed && stillHeld {
+			delete(l.held, key)
+			log.Printf("Lock %s was lost to another owner", key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *RedisLocker) heldKeys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]string, 0, len(l.held))
+	for key := range l.held {
+		keys = append(keys, key)
+	}
+	return keys
+}
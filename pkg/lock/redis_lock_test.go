@@ -0,0 +1,163 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client, func()) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	cleanup := func() {
+		client.Close()
+		mr.Close()
+	}
+
+	return mr, client, cleanup
+}
+
+func TestRedisLocker_OnlyOneInstanceAcquires(t *testing.T) {
+	_, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	acquired, err := first.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = second.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "second instance must not take a held lock")
+
+	// Locks are per key
+	acquired, err = second.TryAcquire("vehicle-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The holder acquiring again keeps the lock
+	acquired, err = first.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestRedisLocker_ReleaseHandsOver(t *testing.T) {
+	_, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	_, err := first.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+
+	// Releasing someone else's lock does nothing
+	require.NoError(t, second.Release("vehicle-1"))
+	acquired, err := second.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, first.Release("vehicle-1"))
+	acquired, err = second.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestRedisLocker_ExpiredLockIsTakenOver(t *testing.T) {
+	mr, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	_, err := first.TryAcquire("vehicle-1", 10*time.Second)
+	require.NoError(t, err)
+
+	mr.FastForward(11 * time.Second)
+
+	acquired, err := second.TryAcquire("vehicle-1", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The former holder can no longer renew it
+	renewed, err := first.Renew("vehicle-1", 10*time.Second)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	assert.Empty(t, first.heldKeys())
+}
+
+func TestRedisLocker_RenewHeldExtendsLocks(t *testing.T) {
+	mr, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	_, err := first.TryAcquire("vehicle-1", 10*time.Second)
+	require.NoError(t, err)
+
+	// Renewing before expiry keeps the lock past its original TTL
+	mr.FastForward(8 * time.Second)
+	first.renewHeld()
+	mr.FastForward(8 * time.Second)
+
+	acquired, err := second.TryAcquire("vehicle-1", 10*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, 2*time.Second, mr.TTL("test:lock:vehicle-1"))
+}
+
+func TestRedisLocker_RenewHeldSkipsReleasedLocks(t *testing.T) {
+	_, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	for _, key := range []string{"vehicle-1", "vehicle-2"} {
+		_, err := first.TryAcquire(key, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, first.Release("vehicle-1"))
+
+	first.renewHeld()
+
+	assert.Equal(t, []string{"vehicle-2"}, first.heldKeys())
+	acquired, err := second.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestRedisLocker_StopReleasesHeldLocks(t *testing.T) {
+	_, client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	first := NewRedisLocker(client, "test:lock:", "instance-a")
+	second := NewRedisLocker(client, "test:lock:", "instance-b")
+
+	_, err := first.TryAcquire("vehicle-1", time.Minute)
+	require.NoError(t, err)
+	_, err = first.TryAcquire("vehicle-2", time.Minute)
+	require.NoError(t, err)
+
+	first.Stop()
+
+	for _, key := range []string{"vehicle-1", "vehicle-2"} {
+		acquired, err := second.TryAcquire(key, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, acquired, key)
+	}
+}
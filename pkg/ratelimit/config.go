@@ -1,6 +1,9 @@
 package ratelimit
 
 import (
+	"net"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,6 +20,106 @@ type Config struct {
 	
 	// Enable/disable rate limiting
 	Enabled bool `json:"enabled"`
+	
+	// Clients that are never rate limited (internal services, health checks)
+	Allowlist Allowlist `json:"allowlist"`
+	
+	// Bounds on the custom limits held in memory. Clients beyond the cap or
+	// idle for longer than the TTL are dropped; the Redis limiter reloads
+	// them from Redis when they return. Zero uses the defaults.
+	CustomLimitsMaxEntries int           `json:"customLimitsMaxEntries"`
+	CustomLimitsIdleTTL    time.Duration `json:"customLimitsIdleTtl"`
+	
+	// TenantLimit caps the combined requests of all clients of a tenant, on
+	// top of each client's own limit. A zero limit disables tenant limiting.
+	// TenantLimits overrides it for individual tenants.
+	TenantLimit  RateLimit            `json:"tenantLimit"`
+	TenantLimits map[string]RateLimit `json:"tenantLimits"`
+}
+
+// RequestsPerMinute returns a limit of n requests a minute, all of which may
+// be used at once
+func RequestsPerMinute(n int) RateLimit {
+	return RateLimit{RequestsPerMinute: n, BurstSize: n, WindowSize: time.Minute}
+}
+
+// TenantLimitFor returns the limit shared by the tenant's clients, and whether
+// the tenant is limited at all
+func (c *Config) TenantLimitFor(tenantID string) (RateLimit, bool) {
+	limit, exists := c.TenantLimits[tenantID]
+	if !exists {
+		limit = c.TenantLimit
+	}
+	return limit, limit.BurstSize > 0 && limit.WindowSize > 0
+}
+
+// Allowlist identifies trusted clients that bypass rate limiting. The
+// middleware checks it before authentication, so a "user:" client ID prefix
+// never matches; use "api:" or "anon:" prefixes, API keys or CIDRs. CIDRs
+// must only be checked against an IP taken from the connection or from a
+// trusted proxy, never from headers the client controls.
+type Allowlist struct {
+	ClientIDPrefixes []string `json:"clientIdPrefixes"`
+	APIKeys          []string `json:"apiKeys"`
+	CIDRs            []string `json:"cidrs"`
+	
+	parseOnce sync.Once
+	networks  []*net.IPNet
+}
+
+// Contains reports whether the client identified by clientID (and optionally
+// its IP address) is allowlisted
+func (a *Allowlist) Contains(clientID, clientIP string) bool {
+	for _, prefix := range a.ClientIDPrefixes {
+		if prefix != "" && strings.HasPrefix(clientID, prefix) {
+			return true
+		}
+	}
+	
+	for _, key := range a.APIKeys {
+		if key != "" && clientID == "api:"+key {
+			return true
+		}
+	}
+	
+	if clientIP == "" || len(a.CIDRs) == 0 {
+		return false
+	}
+	
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	
+	a.parseOnce.Do(func() {
+		for _, cidr := range a.CIDRs {
+			if _, network, err := net.ParseCIDR(strings.TrimSpace(cidr)); err == nil {
+				a.networks = append(a.networks, network)
+			}
+		}
+	})
+	
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	
+	return false
+}
+
+// clientIPFromID extracts the IP address embedded in anonymous client IDs
+// of the form "anon:<ip>:<hash>"
+func clientIPFromID(clientID string) string {
+	if !strings.HasPrefix(clientID, "anon:") {
+		return ""
+	}
+	
+	rest := strings.TrimPrefix(clientID, "anon:")
+	if idx := strings.LastIndex(rest, ":"); idx > 0 {
+		return rest[:idx]
+	}
+	return rest
 }
 
 // DefaultConfig returns a default rate limiting configuration
@@ -57,6 +160,9 @@ func DefaultConfig() *Config {
 		RedisKeyPrefix:  "ratelimit:",
 		CleanupInterval: 5 * time.Minute,
 		Enabled:         true,
+		
+		CustomLimitsMaxEntries: DefaultCustomLimitsMaxEntries,
+		CustomLimitsIdleTTL:    DefaultCustomLimitsIdleTTL,
 	}
 }
 
@@ -103,6 +209,15 @@ func (c *Config) GetEndpointKey(endpoint, method string) string {
 	return "default"
 }
 
+// GetEndpointCategory resolves an endpoint identifier of the form
+// "METHOD:/path" (as produced by the middleware) to its rate limit category
+func (c *Config) GetEndpointCategory(endpoint string) string {
+	if idx := strings.Index(endpoint, ":"); idx > 0 {
+		return c.GetEndpointKey(endpoint[idx+1:], endpoint[:idx])
+	}
+	return c.GetEndpointKey(endpoint, "")
+}
+
 // matchesPattern checks if a key matches a pattern with wildcards
 func matchesPattern(key, pattern string) bool {
 	if pattern[len(pattern)-1] == '*' {
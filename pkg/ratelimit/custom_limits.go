@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Defaults for the in-memory custom limit cache
+const (
+	DefaultCustomLimitsMaxEntries = 10000
+	DefaultCustomLimitsIdleTTL    = time.Hour
+)
+
+// customLimitCache holds per-client custom limits in memory, bounded by
+// maxEntries and dropping clients not looked up for idleTTL. The least
+// recently used client is evicted first when the cache is full.
+type customLimitCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	idleTTL    time.Duration
+	order      *list.List // front is the most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+// customLimitEntry is a client's limits, endpoint -> limit. Stored maps are
+// never modified; updates replace them.
+type customLimitEntry struct {
+	clientID string
+	limits   map[string]RateLimit
+	lastUsed time.Time
+}
+
+// newCustomLimitCache creates a cache, using the defaults for non-positive
+// settings
+func newCustomLimitCache(maxEntries int, idleTTL time.Duration) *customLimitCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCustomLimitsMaxEntries
+	}
+	if idleTTL <= 0 {
+		idleTTL = DefaultCustomLimitsIdleTTL
+	}
+	return &customLimitCache{
+		maxEntries: maxEntries,
+		idleTTL:    idleTTL,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// get returns the client's limits and marks the client as used. Idle entries
+// are dropped and reported as missing.
+func (c *customLimitCache) get(clientID string) (map[string]RateLimit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[clientID]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*customLimitEntry)
+	now := c.now()
+	if now.Sub(entry.lastUsed) > c.idleTTL {
+		c.remove(element)
+		return nil, false
+	}
+	entry.lastUsed = now
+	c.order.MoveToFront(element)
+	return entry.limits, true
+}
+
+// put stores the client's limits, replacing any held
+func (c *customLimitCache) put(clientID string, limits map[string]RateLimit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(clientID, limits)
+}
+
+// putIfAbsent stores the client's limits unless some are already held, so a
+// slow reload can't overwrite a newer update
+func (c *customLimitCache) putIfAbsent(clientID string, limits map[string]RateLimit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[clientID]; exists {
+		return
+	}
+	c.store(clientID, limits)
+}
+
+// len returns the number of clients held in memory
+func (c *customLimitCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// evictIdle drops every client not used for idleTTL
+func (c *customLimitCache) evictIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for element := c.order.Back(); element != nil; {
+		entry := element.Value.(*customLimitEntry)
+		if now.Sub(entry.lastUsed) <= c.idleTTL {
+			// Everything further forward was used more recently
+			return
+		}
+		previous := element.Prev()
+		c.remove(element)
+		element = previous
+	}
+}
+
+func (c *customLimitCache) store(clientID string, limits map[string]RateLimit) {
+	if element, exists := c.entries[clientID]; exists {
+		entry := element.Value.(*customLimitEntry)
+		entry.limits = limits
+		entry.lastUsed = c.now()
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[clientID] = c.order.PushFront(&customLimitEntry{clientID: clientID, limits: limits, lastUsed: c.now()})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *customLimitCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*customLimitEntry).clientID)
+}
+
+// withLimit returns a copy of limits with the endpoint's limit set
+func withLimit(limits map[string]RateLimit, endpoint string, limit RateLimit) map[string]RateLimit {
+	updated := make(map[string]RateLimit, len(limits)+1)
+	for key, value := range limits {
+		updated[key] = value
+	}
+	updated[endpoint] = limit
+	return updated
+}
@@ -7,9 +7,14 @@ import (
 // RateLimiter defines the interface for rate limiting functionality
 type RateLimiter interface {
 	Allow(clientID string, endpoint string) (bool, time.Duration, error)
+	AllowWithInfo(clientID string, endpoint string) (RateLimitInfo, error)
+	AllowTenant(tenantID string) (RateLimitInfo, error)
+	Bypass(clientID string, clientIP string) bool
 	GetLimits(clientID string) map[string]RateLimit
 	SetCustomLimit(clientID string, endpoint string, limit RateLimit) error
 	GetStats() RateLimiterStats
+	// Close stops the limiter's background cleanup
+	Close()
 }
 
 // RateLimit defines the configuration for rate limiting
@@ -19,12 +24,28 @@ type RateLimit struct {
 	WindowSize        time.Duration `json:"windowSize"`
 }
 
+// RateLimitInfo describes the outcome of a rate limit check along with the
+// quota state clients need to back off intelligently
+type RateLimitInfo struct {
+	Allowed    bool          `json:"allowed"`
+	Limit      RateLimit     `json:"limit"`
+	Remaining  int           `json:"remaining"`
+	ResetAt    time.Time     `json:"resetAt"`
+	RetryAfter time.Duration `json:"retryAfter"`
+}
+
 // RateLimiterStats provides statistics about rate limiting
 type RateLimiterStats struct {
 	TotalRequests     int64   `json:"totalRequests"`
 	BlockedRequests   int64   `json:"blockedRequests"`
+	BypassedRequests  int64   `json:"bypassedRequests"`
+	// TenantBlockedRequests counts requests blocked by a tenant's shared limit
+	TenantBlockedRequests int64 `json:"tenantBlockedRequests"`
 	AverageLatency    float64 `json:"averageLatency"`
 	ActiveClients     int     `json:"activeClients"`
+	// CustomLimitEntries is the number of clients whose custom limits are
+	// held in memory, including clients the Redis limiter found to have none
+	CustomLimitEntries int `json:"customLimitEntries"`
 }
 
 // TokenBucket represents a token bucket for rate limiting
@@ -12,10 +12,13 @@ import (
 type MemoryRateLimiter struct {
 	config       *Config
 	stats        *RateLimiterStats
-	customLimits map[string]map[string]RateLimit // clientID -> endpoint -> limit
-	tokens       map[string]*TokenBucket         // key -> token bucket
+	customLimits *customLimitCache       // clientID -> endpoint -> limit
+	tokens       map[string]*TokenBucket // key -> token bucket
 	mu           sync.RWMutex
 	ctx          context.Context
+	stopChan     chan struct{} // closed by Close to stop the cleanup goroutine
+	stopped      chan struct{} // closed once the cleanup goroutine has exited
+	closeOnce    sync.Once
 }
 
 // NewMemoryRateLimiter creates a new in-memory rate limiter
@@ -27,9 +30,11 @@ func NewMemoryRateLimiter(config *Config) *MemoryRateLimiter {
 	limiter := &MemoryRateLimiter{
 		config:       config,
 		stats:        &RateLimiterStats{},
-		customLimits: make(map[string]map[string]RateLimit),
+		customLimits: newCustomLimitCache(config.CustomLimitsMaxEntries, config.CustomLimitsIdleTTL),
 		tokens:       make(map[string]*TokenBucket),
 		ctx:          context.Background(),
+		stopChan:     make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
@@ -40,21 +45,66 @@ func NewMemoryRateLimiter(config *Config) *MemoryRateLimiter {
 
 // Allow checks if a request should be allowed based on rate limits
 func (r *MemoryRateLimiter) Allow(clientID string, endpoint string) (bool, time.Duration, error) {
-	if !r.config.Enabled {
-		return true, 0, nil
+	info, err := r.AllowWithInfo(clientID, endpoint)
+	if err != nil {
+		return false, 0, err
 	}
 
-	atomic.AddInt64(&r.stats.TotalRequests, 1)
+	return info.Allowed, info.RetryAfter, nil
+}
+
+// AllowWithInfo checks if a request should be allowed and reports the remaining
+// tokens and the time at which the bucket will be full again
+func (r *MemoryRateLimiter) AllowWithInfo(clientID string, endpoint string) (RateLimitInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	// Get the rate limit for this client and endpoint
 	limit := r.getRateLimit(clientID, endpoint)
 
+	// Allowlisted clients are never limited
+	if r.Bypass(clientID, clientIPFromID(clientID)) {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+
+	if !r.config.Enabled {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+
+	atomic.AddInt64(&r.stats.TotalRequests, 1)
+
 	// Generate key
 	key := fmt.Sprintf("%s:%s", clientID, endpoint)
 
+	info := r.takeToken(key, limit)
+	if !info.Allowed {
+		atomic.AddInt64(&r.stats.BlockedRequests, 1)
+	}
+	return info, nil
+}
+
+// AllowTenant checks a request against the limit shared by all clients of the
+// tenant. Tenants without a limit are always allowed.
+func (r *MemoryRateLimiter) AllowTenant(tenantID string) (RateLimitInfo, error) {
+	limit, limited := r.config.TenantLimitFor(tenantID)
+	if tenantID == "" || !limited || !r.config.Enabled {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	// Client keys always contain the endpoint, so this can't collide with them
+	info := r.takeToken("tenant:"+tenantID, limit)
+	if !info.Allowed {
+		atomic.AddInt64(&r.stats.TenantBlockedRequests, 1)
+	}
+	return info, nil
+}
+
+// takeToken takes a token from the key's bucket, refilling it for the time
+// elapsed. The caller must hold the lock.
+func (r *MemoryRateLimiter) takeToken(key string, limit RateLimit) RateLimitInfo {
 	tokenBucket := r.getOrCreateTokenBucket(key, limit)
 
 	now := time.Now()
@@ -66,32 +116,54 @@ func (r *MemoryRateLimiter) Allow(clientID string, endpoint string) (bool, time.
 		tokenBucket.Tokens = min(tokenBucket.Capacity, tokenBucket.Tokens+tokensToAdd)
 	}
 
+	timeUntilRefill := time.Minute / time.Duration(max(1, limit.RequestsPerMinute))
+
 	// Check if request can be allowed
 	if tokenBucket.Tokens > 0 {
 		tokenBucket.Tokens--
 		tokenBucket.LastRefill = now
-		return true, 0, nil
+		return RateLimitInfo{
+			Allowed:   true,
+			Limit:     limit,
+			Remaining: tokenBucket.Tokens,
+			ResetAt:   now.Add(timeUntilRefill * time.Duration(tokenBucket.Capacity-tokenBucket.Tokens)),
+		}
 	}
 
 	// Calculate when tokens will be available
-	timeUntilRefill := time.Minute / time.Duration(limit.RequestsPerMinute)
 	resetTime := timeUntilRefill * time.Duration(max(1, tokenBucket.Tokens*-1+1))
 
-	atomic.AddInt64(&r.stats.BlockedRequests, 1)
-	return false, resetTime, nil
+	return RateLimitInfo{
+		Allowed:    false,
+		Limit:      limit,
+		Remaining:  0,
+		ResetAt:    now.Add(timeUntilRefill * time.Duration(tokenBucket.Capacity)),
+		RetryAfter: resetTime,
+	}
+}
+
+// Bypass reports whether the client is allowlisted and, if so, records the
+// request as bypassed rather than counting it against any limit
+func (r *MemoryRateLimiter) Bypass(clientID string, clientIP string) bool {
+	if !r.config.Allowlist.Contains(clientID, clientIP) {
+		return false
+	}
+
+	atomic.AddInt64(&r.stats.BypassedRequests, 1)
+	return true
 }
 
 // getRateLimit gets the rate limit for a specific client and endpoint
 func (r *MemoryRateLimiter) getRateLimit(clientID, endpoint string) RateLimit {
 	// Check for custom limits first
-	if clientLimits, exists := r.customLimits[clientID]; exists {
+	if clientLimits, exists := r.customLimits.get(clientID); exists {
 		if limit, exists := clientLimits[endpoint]; exists {
 			return limit
 		}
 	}
 
 	// Get endpoint category
-	endpointKey := r.config.GetEndpointKey(endpoint, "")
+	endpointKey := r.config.GetEndpointCategory(endpoint)
 
 	// Return default limit for endpoint category
 	if limit, exists := r.config.DefaultLimits[endpointKey]; exists {
@@ -138,27 +210,23 @@ func (r *MemoryRateLimiter) GetLimits(clientID string) map[string]RateLimit {
 	}
 
 	// Override with custom limits
-	r.mu.RLock()
-	if clientLimits, exists := r.customLimits[clientID]; exists {
+	if clientLimits, exists := r.customLimits.get(clientID); exists {
 		for endpoint, limit := range clientLimits {
 			limits[endpoint] = limit
 		}
 	}
-	r.mu.RUnlock()
 
 	return limits
 }
 
-// SetCustomLimit sets a custom rate limit for a specific client and endpoint
+// SetCustomLimit sets a custom rate limit for a specific client and endpoint.
+// There is no durable copy, so limits evicted from memory are lost.
 func (r *MemoryRateLimiter) SetCustomLimit(clientID string, endpoint string, limit RateLimit) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.customLimits[clientID] == nil {
-		r.customLimits[clientID] = make(map[string]RateLimit)
-	}
-
-	r.customLimits[clientID][endpoint] = limit
+	current, _ := r.customLimits.get(clientID)
+	r.customLimits.put(clientID, withLimit(current, endpoint, limit))
 	return nil
 }
 
@@ -168,7 +236,8 @@ func (r *MemoryRateLimiter) GetStats() RateLimiterStats {
 	defer r.mu.RUnlock()
 
 	stats := *r.stats
-	stats.ActiveClients = len(r.customLimits)
+	stats.CustomLimitEntries = r.customLimits.len()
+	stats.ActiveClients = stats.CustomLimitEntries
 
 	// Calculate average latency (simplified)
 	if stats.TotalRequests > 0 {
@@ -180,10 +249,17 @@ func (r *MemoryRateLimiter) GetStats() RateLimiterStats {
 
 // cleanupExpiredTokens cleans up old token buckets (simplified implementation)
 func (r *MemoryRateLimiter) cleanupExpiredTokens() {
+	defer close(r.stopped)
 	ticker := time.NewTicker(r.config.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stopChan:
+			return
+		}
+
 		r.mu.Lock()
 
 		now := time.Now()
@@ -194,13 +270,20 @@ func (r *MemoryRateLimiter) cleanupExpiredTokens() {
 			}
 		}
 
-		// Clean up custom limits that haven't been used recently (simplified)
-		// In a real implementation, you'd track access times
-
 		r.mu.Unlock()
+
+		// Drop custom limits of clients that haven't been seen recently
+		r.customLimits.evictIdle()
 	}
 }
 
+// Close stops the cleanup goroutine and waits for it to exit. The limiter
+// keeps working, but unused token buckets are no longer freed.
+func (r *MemoryRateLimiter) Close() {
+	r.closeOnce.Do(func() { close(r.stopChan) })
+	<-r.stopped
+}
+
 // Helper functions for min/max implementation without external dependencies
 func min(a, b int) int {
 	if a < b {
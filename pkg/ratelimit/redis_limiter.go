@@ -16,9 +16,12 @@ type RedisRateLimiter struct {
 	client       *redis.Client
 	config       *Config
 	stats        *RateLimiterStats
-	customLimits map[string]map[string]RateLimit // clientID -> endpoint -> limit
+	customLimits *customLimitCache // bounded copy of the custom limits stored in Redis
 	mu           sync.RWMutex
 	ctx          context.Context
+	stopChan     chan struct{} // closed by Close to stop the cleanup goroutine
+	stopped      chan struct{} // closed once the cleanup goroutine has exited
+	closeOnce    sync.Once
 }
 
 // NewRedisRateLimiter creates a new Redis-backed rate limiter
@@ -31,8 +34,10 @@ func NewRedisRateLimiter(client *redis.Client, config *Config) *RedisRateLimiter
 		client:       client,
 		config:       config,
 		stats:        &RateLimiterStats{},
-		customLimits: make(map[string]map[string]RateLimit),
+		customLimits: newCustomLimitCache(config.CustomLimitsMaxEntries, config.CustomLimitsIdleTTL),
 		ctx:          context.Background(),
+		stopChan:     make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}
 	
 	// Start cleanup goroutine
@@ -43,34 +48,70 @@ func NewRedisRateLimiter(client *redis.Client, config *Config) *RedisRateLimiter
 
 // Allow checks if a request should be allowed based on rate limits
 func (r *RedisRateLimiter) Allow(clientID string, endpoint string) (bool, time.Duration, error) {
-	if !r.config.Enabled {
-		return true, 0, nil
+	info, err := r.AllowWithInfo(clientID, endpoint)
+	if err != nil {
+		return false, 0, err
 	}
 	
-	atomic.AddInt64(&r.stats.TotalRequests, 1)
-	
+	return info.Allowed, info.RetryAfter, nil
+}
+
+// AllowWithInfo checks if a request should be allowed and reports the remaining
+// quota and the absolute time at which the current window resets
+func (r *RedisRateLimiter) AllowWithInfo(clientID string, endpoint string) (RateLimitInfo, error) {
 	// Get the rate limit for this client and endpoint
 	limit := r.getRateLimit(clientID, endpoint)
 	
+	// Allowlisted clients are never limited
+	if r.Bypass(clientID, clientIPFromID(clientID)) {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+	
+	if !r.config.Enabled {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+	
+	atomic.AddInt64(&r.stats.TotalRequests, 1)
+	
 	// Generate Redis key
 	key := fmt.Sprintf("%s%s:%s", r.config.RedisKeyPrefix, clientID, endpoint)
 	
 	// Use Lua script for atomic token bucket operations
-	allowed, resetTime, err := r.checkTokenBucket(key, limit)
+	info, err := r.checkTokenBucket(key, limit)
 	if err != nil {
-		return false, 0, fmt.Errorf("rate limit check failed: %w", err)
+		return RateLimitInfo{}, fmt.Errorf("rate limit check failed: %w", err)
 	}
 	
-	if !allowed {
+	if !info.Allowed {
 		atomic.AddInt64(&r.stats.BlockedRequests, 1)
-		return false, resetTime, nil
 	}
 	
-	return true, 0, nil
+	return info, nil
+}
+
+// AllowTenant checks a request against the limit shared by all clients of the
+// tenant. Tenants without a limit are always allowed.
+func (r *RedisRateLimiter) AllowTenant(tenantID string) (RateLimitInfo, error) {
+	limit, limited := r.config.TenantLimitFor(tenantID)
+	if tenantID == "" || !limited || !r.config.Enabled {
+		return RateLimitInfo{Allowed: true, Limit: limit, Remaining: limit.BurstSize}, nil
+	}
+	
+	key := fmt.Sprintf("%stenant:%s", r.config.RedisKeyPrefix, tenantID)
+	info, err := r.checkTokenBucket(key, limit)
+	if err != nil {
+		return RateLimitInfo{}, fmt.Errorf("tenant rate limit check failed: %w", err)
+	}
+	
+	if !info.Allowed {
+		atomic.AddInt64(&r.stats.TenantBlockedRequests, 1)
+	}
+	
+	return info, nil
 }
 
 // checkTokenBucket performs atomic token bucket check using Lua script
-func (r *RedisRateLimiter) checkTokenBucket(key string, limit RateLimit) (bool, time.Duration, error) {
+func (r *RedisRateLimiter) checkTokenBucket(key string, limit RateLimit) (RateLimitInfo, error) {
 	now := time.Now()
 	
 	// Simplified Lua script for sliding window rate limiting
@@ -96,19 +137,23 @@ func (r *RedisRateLimiter) checkTokenBucket(key string, limit RateLimit) (bool,
 			count = count + 1
 		end
 		
-		-- Calculate reset time (convert from milliseconds to seconds)
+		-- Calculate reset time in milliseconds to keep sub-second precision
 		local reset_time = 0
 		if not allowed then
-			reset_time = math.ceil(((window_start + window_size) - now) / 1000)
+			reset_time = (window_start + window_size) - now
 		end
 		
+		-- Remaining requests in the window and absolute reset time (milliseconds)
+		local remaining = math.max(0, burst_size - count)
+		local reset_at = window_start + window_size
+		
 		-- Save state with TTL
 		local ttl = math.max(1, math.ceil(window_size + 1))
 		redis.call('HSET', key, 'count', count)
 		redis.call('HSET', key, 'window_start', window_start)
 		redis.call('EXPIRE', key, ttl)
 		
-		return {allowed and 1 or 0, reset_time}
+		return {allowed and 1 or 0, reset_time, remaining, reset_at}
 	`
 	
 	result, err := r.client.Eval(r.ctx, script, []string{key}, 
@@ -117,34 +162,50 @@ func (r *RedisRateLimiter) checkTokenBucket(key string, limit RateLimit) (bool,
 		now.UnixMilli()).Result()
 	
 	if err != nil {
-		return false, 0, err
+		return RateLimitInfo{}, err
 	}
 	
 	resultSlice, ok := result.([]interface{})
-	if !ok || len(resultSlice) != 2 {
-		return false, 0, fmt.Errorf("unexpected script result format")
+	if !ok || len(resultSlice) != 4 {
+		return RateLimitInfo{}, fmt.Errorf("unexpected script result format")
 	}
 	
-	allowed := resultSlice[0].(int64) == 1
-	resetTime := time.Duration(resultSlice[1].(int64)) * time.Second
+	info := RateLimitInfo{
+		Allowed:   resultSlice[0].(int64) == 1,
+		Limit:     limit,
+		Remaining: int(resultSlice[2].(int64)),
+		ResetAt:   time.UnixMilli(resultSlice[3].(int64)),
+	}
+	if !info.Allowed {
+		info.RetryAfter = time.Duration(resultSlice[1].(int64)) * time.Millisecond
+	}
 	
-	return allowed, resetTime, nil
+	return info, nil
+}
+
+// Bypass reports whether the client is allowlisted and, if so, records the
+// request as bypassed rather than counting it against any limit
+func (r *RedisRateLimiter) Bypass(clientID string, clientIP string) bool {
+	if !r.config.Allowlist.Contains(clientID, clientIP) {
+		return false
+	}
+	
+	atomic.AddInt64(&r.stats.BypassedRequests, 1)
+	return true
 }
 
 // getRateLimit gets the rate limit for a specific client and endpoint
 func (r *RedisRateLimiter) getRateLimit(clientID, endpoint string) RateLimit {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	
 	// Check for custom limits first
-	if clientLimits, exists := r.customLimits[clientID]; exists {
-		if limit, exists := clientLimits[endpoint]; exists {
-			return limit
-		}
+	if limit, exists := r.clientLimits(clientID)[endpoint]; exists {
+		return limit
 	}
 	
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
 	// Get endpoint category
-	endpointKey := r.config.GetEndpointKey(endpoint, "")
+	endpointKey := r.config.GetEndpointCategory(endpoint)
 	
 	// Return default limit for endpoint category
 	if limit, exists := r.config.DefaultLimits[endpointKey]; exists {
@@ -166,6 +227,8 @@ func (r *RedisRateLimiter) getRateLimit(clientID, endpoint string) RateLimit {
 
 // GetLimits returns the current rate limits for a client
 func (r *RedisRateLimiter) GetLimits(clientID string) map[string]RateLimit {
+	clientLimits := r.clientLimits(clientID)
+	
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
@@ -177,10 +240,8 @@ func (r *RedisRateLimiter) GetLimits(clientID string) map[string]RateLimit {
 	}
 	
 	// Override with custom limits
-	if clientLimits, exists := r.customLimits[clientID]; exists {
-		for endpoint, limit := range clientLimits {
-			limits[endpoint] = limit
-		}
+	for endpoint, limit := range clientLimits {
+		limits[endpoint] = limit
 	}
 	
 	return limits
@@ -191,34 +252,80 @@ func (r *RedisRateLimiter) SetCustomLimit(clientID string, endpoint string, limi
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
-	if r.customLimits[clientID] == nil {
-		r.customLimits[clientID] = make(map[string]RateLimit)
+	// Start from the durable copy when the client was evicted from memory,
+	// so its other endpoints' limits aren't overwritten
+	current, exists := r.customLimits.get(clientID)
+	if !exists {
+		var err error
+		if current, err = r.loadClientLimits(clientID); err != nil {
+			return fmt.Errorf("failed to load custom limits: %w", err)
+		}
 	}
-	
-	r.customLimits[clientID][endpoint] = limit
+	limits := withLimit(current, endpoint, limit)
 	
 	// Persist custom limits to Redis for durability
-	key := fmt.Sprintf("%scustom:%s", r.config.RedisKeyPrefix, clientID)
-	data, err := json.Marshal(r.customLimits[clientID])
+	data, err := json.Marshal(limits)
 	if err != nil {
 		return fmt.Errorf("failed to marshal custom limits: %w", err)
 	}
 	
-	err = r.client.Set(r.ctx, key, data, 24*time.Hour).Err()
+	err = r.client.Set(r.ctx, r.customLimitKey(clientID), data, 24*time.Hour).Err()
 	if err != nil {
 		return fmt.Errorf("failed to persist custom limits: %w", err)
 	}
 	
+	r.customLimits.put(clientID, limits)
 	return nil
 }
 
+// clientLimits returns the client's custom limits, reloading them from Redis
+// when they aren't held in memory. Clients without custom limits are cached
+// too, so each costs at most one Redis lookup per idle period.
+func (r *RedisRateLimiter) clientLimits(clientID string) map[string]RateLimit {
+	if limits, exists := r.customLimits.get(clientID); exists {
+		return limits
+	}
+	
+	limits, err := r.loadClientLimits(clientID)
+	if err != nil {
+		// Fall back to the defaults; the next request retries the lookup
+		return nil
+	}
+	r.customLimits.putIfAbsent(clientID, limits)
+	return limits
+}
+
+// loadClientLimits reads the client's custom limits from Redis; a client
+// without any yields nil
+func (r *RedisRateLimiter) loadClientLimits(clientID string) (map[string]RateLimit, error) {
+	data, err := r.client.Get(r.ctx, r.customLimitKey(clientID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	var limits map[string]RateLimit
+	if err := json.Unmarshal([]byte(data), &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// customLimitKey returns the Redis key of the client's custom limits
+func (r *RedisRateLimiter) customLimitKey(clientID string) string {
+	return fmt.Sprintf("%scustom:%s", r.config.RedisKeyPrefix, clientID)
+}
+
 // GetStats returns current rate limiter statistics
 func (r *RedisRateLimiter) GetStats() RateLimiterStats {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
 	stats := *r.stats
-	stats.ActiveClients = len(r.customLimits)
+	stats.CustomLimitEntries = r.customLimits.len()
+	stats.ActiveClients = stats.CustomLimitEntries
 	
 	// Calculate average latency (simplified)
 	if stats.TotalRequests > 0 {
@@ -228,47 +335,35 @@ func (r *RedisRateLimiter) GetStats() RateLimiterStats {
 	return stats
 }
 
-// cleanupExpiredKeys removes expired rate limit keys from Redis
+// cleanupExpiredKeys drops idle clients' custom limits from memory. Redis
+// expires rate limit keys by TTL and keeps the durable custom limits.
 func (r *RedisRateLimiter) cleanupExpiredKeys() {
+	defer close(r.stopped)
 	ticker := time.NewTicker(r.config.CleanupInterval)
 	defer ticker.Stop()
 	
-	for range ticker.C {
-		// Use SCAN to find expired keys
-		pattern := r.config.RedisKeyPrefix + "*"
-		iter := r.client.Scan(r.ctx, 0, pattern, 100).Iterator()
-		
-		var expiredKeys []string
-		for iter.Next(r.ctx) {
-			key := iter.Val()
-			
-			// Check if key exists (TTL will handle expiration)
-			exists, err := r.client.Exists(r.ctx, key).Result()
-			if err != nil || exists == 0 {
-				expiredKeys = append(expiredKeys, key)
-			}
-		}
-		
-		// Clean up expired keys from memory
-		if len(expiredKeys) > 0 {
-			r.mu.Lock()
-			for range expiredKeys {
-				// Extract client ID from key and clean up custom limits if needed
-				// This is a simplified cleanup - in production, you might want more sophisticated logic
-			}
-			r.mu.Unlock()
+	for {
+		select {
+		case <-ticker.C:
+			r.customLimits.evictIdle()
+		case <-r.stopChan:
+			return
 		}
 	}
 }
 
+// Close stops the cleanup goroutine and waits for it to exit. The limiter
+// keeps working, but idle clients' custom limits are no longer evicted.
+func (r *RedisRateLimiter) Close() {
+	r.closeOnce.Do(func() { close(r.stopChan) })
+	<-r.stopped
+}
+
 // LoadCustomLimits loads custom limits from Redis on startup
 func (r *RedisRateLimiter) LoadCustomLimits() error {
 	pattern := fmt.Sprintf("%scustom:*", r.config.RedisKeyPrefix)
 	iter := r.client.Scan(r.ctx, 0, pattern, 100).Iterator()
 	
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	
 	for iter.Next(r.ctx) {
 		key := iter.Val()
 		
@@ -286,8 +381,12 @@ func (r *RedisRateLimiter) LoadCustomLimits() error {
 			continue // Skip if unmarshal error
 		}
 		
-		r.customLimits[clientID] = limits
+		r.customLimits.put(clientID, limits)
 	}
 	
 	return iter.Err()
-}
\ No newline at end of file
+}
+// Ping verifies the limiter can reach its Redis backend
+func (r *RedisRateLimiter) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 )
 
 func setupTestRedis(t *testing.T) (*redis.Client, func()) {
@@ -77,6 +78,52 @@ func TestRedisRateLimiter_Allow_BasicFunctionality(t *testing.T) {
 	assert.Greater(t, resetTime, time.Duration(0))
 }
 
+func TestRedisRateLimiter_AllowWithInfo_Remaining(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.DefaultLimits["default"] = RateLimit{
+		RequestsPerMinute: 5,
+		BurstSize:         3,
+		WindowSize:        time.Minute,
+	}
+	
+	limiter := NewRedisRateLimiter(client, config)
+	
+	start := time.Now()
+	
+	// Remaining should decrement with every allowed request and reach zero
+	for expected := 2; expected >= 0; expected-- {
+		info, err := limiter.AllowWithInfo("test-client", "test-endpoint")
+		require.NoError(t, err)
+		assert.True(t, info.Allowed)
+		assert.Equal(t, expected, info.Remaining)
+		assert.Equal(t, 3, info.Limit.BurstSize)
+		assert.WithinDuration(t, start.Add(time.Minute), info.ResetAt, 2*time.Second)
+	}
+	
+	// Once exhausted the request is blocked with nothing remaining
+	info, err := limiter.AllowWithInfo("test-client", "test-endpoint")
+	require.NoError(t, err)
+	assert.False(t, info.Allowed)
+	assert.Equal(t, 0, info.Remaining)
+	assert.Greater(t, info.RetryAfter, time.Duration(0))
+	assert.WithinDuration(t, start.Add(time.Minute), info.ResetAt, 2*time.Second)
+}
+
+func TestRedisRateLimiter_Allow_MethodPrefixedEndpoint(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	limiter := NewRedisRateLimiter(client, DefaultConfig())
+	
+	// Endpoints produced by the middleware carry the HTTP method as a prefix
+	info, err := limiter.AllowWithInfo("test-client", "POST:/api/v1/auth/login")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().DefaultLimits["auth_login"], info.Limit)
+}
+
 func TestRedisRateLimiter_Allow_WindowReset(t *testing.T) {
 	client, cleanup := setupTestRedis(t)
 	defer cleanup()
@@ -113,6 +160,32 @@ func TestRedisRateLimiter_Allow_WindowReset(t *testing.T) {
 	assert.True(t, allowed)
 }
 
+func TestRedisRateLimiter_Allow_SubSecondRetryAfter(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.DefaultLimits["default"] = RateLimit{
+		RequestsPerMinute: 10,
+		BurstSize:         1,
+		WindowSize:        200 * time.Millisecond,
+	}
+	
+	limiter := NewRedisRateLimiter(client, config)
+	
+	allowed, _, err := limiter.Allow("test-client", "test-endpoint")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	
+	// A 200ms window must not be reported as a whole second wait
+	allowed, retryAfter, err := limiter.Allow("test-client", "test-endpoint")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.Less(t, retryAfter, time.Second)
+	assert.LessOrEqual(t, retryAfter, 200*time.Millisecond)
+}
+
 func TestRedisRateLimiter_Allow_DifferentClients(t *testing.T) {
 	client, cleanup := setupTestRedis(t)
 	defer cleanup()
@@ -211,6 +284,87 @@ func TestRedisRateLimiter_Allow_DisabledLimiter(t *testing.T) {
 	}
 }
 
+func TestRedisRateLimiter_Allow_Allowlist(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.DefaultLimits["default"] = RateLimit{
+		RequestsPerMinute: 5,
+		BurstSize:         2,
+		WindowSize:        time.Minute,
+	}
+	config.Allowlist = Allowlist{
+		APIKeys: []string{"internal-telemetry"},
+		CIDRs:   []string{"10.0.0.0/8"},
+	}
+	
+	limiter := NewRedisRateLimiter(client, config)
+	
+	// Allowlisted API key and CIDR are never blocked, even well past the burst size
+	for i := 0; i < 10; i++ {
+		allowed, _, err := limiter.Allow("api:internal-telemetry", "test-endpoint")
+		require.NoError(t, err)
+		assert.True(t, allowed, "allowlisted API key request %d should be allowed", i+1)
+		
+		allowed, _, err = limiter.Allow("anon:10.1.2.3:abcd1234", "test-endpoint")
+		require.NoError(t, err)
+		assert.True(t, allowed, "allowlisted CIDR request %d should be allowed", i+1)
+	}
+	
+	// A normal client is blocked once the burst is exhausted
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow("api:public-key", "test-endpoint")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, _, err := limiter.Allow("api:public-key", "test-endpoint")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	
+	// Bypassed requests are recorded separately from limited traffic
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(20), stats.BypassedRequests)
+	assert.Equal(t, int64(3), stats.TotalRequests)
+	assert.Equal(t, int64(1), stats.BlockedRequests)
+}
+
+func TestAllowlist_Contains(t *testing.T) {
+	allowlist := &Allowlist{
+		ClientIDPrefixes: []string{"user:service-"},
+		APIKeys:          []string{"internal"},
+		CIDRs:            []string{"10.0.0.0/8", "fd00::/8", "not-a-cidr"},
+	}
+	
+	tests := []struct {
+		name     string
+		clientID string
+		clientIP string
+		expected bool
+	}{
+		{"client ID prefix", "user:service-ingest", "", true},
+		{"other user", "user:alice", "", false},
+		{"allowlisted API key", "api:internal", "", true},
+		{"other API key", "api:internal-2", "", false},
+		{"IPv4 in CIDR", "user:alice", "10.20.30.40", true},
+		{"IPv4 outside CIDR", "user:alice", "192.168.1.1", false},
+		{"IPv6 in CIDR", "user:alice", "fd00::1", true},
+		{"invalid IP", "user:alice", "bogus", false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, allowlist.Contains(tt.clientID, tt.clientIP))
+		})
+	}
+}
+
+func TestClientIPFromID(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", clientIPFromID("anon:10.0.0.1:abcd1234"))
+	assert.Equal(t, "fd00::1", clientIPFromID("anon:fd00::1:abcd1234"))
+	assert.Equal(t, "", clientIPFromID("user:alice"))
+}
+
 func TestRedisRateLimiter_SetCustomLimit(t *testing.T) {
 	client, cleanup := setupTestRedis(t)
 	defer cleanup()
@@ -246,6 +400,64 @@ func TestRedisRateLimiter_SetCustomLimit(t *testing.T) {
 	assert.False(t, allowed)
 }
 
+func TestRedisRateLimiter_CustomLimitsOverflowCap(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.CustomLimitsMaxEntries = 2
+	limiter := NewRedisRateLimiter(client, config)
+	
+	customLimit := RateLimit{RequestsPerMinute: 10, BurstSize: 5, WindowSize: time.Minute}
+	for _, clientID := range []string{"client-1", "client-2", "client-3"} {
+		require.NoError(t, limiter.SetCustomLimit(clientID, "endpoint-a", customLimit))
+	}
+	
+	// The least recently used client is dropped from memory only
+	assert.Equal(t, 2, limiter.GetStats().CustomLimitEntries)
+	_, inMemory := limiter.customLimits.get("client-1")
+	assert.False(t, inMemory)
+	exists, err := client.Exists(context.Background(), limiter.customLimitKey("client-1")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), exists, "Redis keeps the durable copy")
+	
+	// The evicted client's limits are reloaded when it returns
+	assert.Equal(t, customLimit, limiter.GetLimits("client-1")["endpoint-a"])
+	assert.Equal(t, 2, limiter.GetStats().CustomLimitEntries)
+	
+	// Updating an evicted client keeps its other endpoints' limits
+	otherLimit := RateLimit{RequestsPerMinute: 20, BurstSize: 8, WindowSize: time.Minute}
+	require.NoError(t, limiter.SetCustomLimit("client-2", "endpoint-b", otherLimit))
+	limits := limiter.GetLimits("client-2")
+	assert.Equal(t, customLimit, limits["endpoint-a"])
+	assert.Equal(t, otherLimit, limits["endpoint-b"])
+}
+
+func TestRedisRateLimiter_CustomLimitsIdleEviction(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.CustomLimitsIdleTTL = time.Minute
+	limiter := NewRedisRateLimiter(client, config)
+	now := time.Now()
+	limiter.customLimits.now = func() time.Time { return now }
+	
+	customLimit := RateLimit{RequestsPerMinute: 10, BurstSize: 5, WindowSize: time.Minute}
+	require.NoError(t, limiter.SetCustomLimit("idle-client", "endpoint-a", customLimit))
+	require.NoError(t, limiter.SetCustomLimit("busy-client", "endpoint-a", customLimit))
+	
+	now = now.Add(45 * time.Second)
+	limiter.GetLimits("busy-client")
+	now = now.Add(30 * time.Second)
+	limiter.customLimits.evictIdle()
+	
+	assert.Equal(t, 1, limiter.GetStats().CustomLimitEntries)
+	_, inMemory := limiter.customLimits.get("busy-client")
+	assert.True(t, inMemory)
+	assert.Equal(t, customLimit, limiter.GetLimits("idle-client")["endpoint-a"])
+}
+
 func TestRedisRateLimiter_GetLimits(t *testing.T) {
 	client, cleanup := setupTestRedis(t)
 	defer cleanup()
@@ -380,4 +592,61 @@ func TestMatchesPattern(t *testing.T) {
 			assert.Equal(t, tt.matches, result)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestRateLimiters_AllowTenant(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	
+	config := DefaultConfig()
+	config.TenantLimit = RequestsPerMinute(3)
+	config.TenantLimits = map[string]RateLimit{"big-tenant": RequestsPerMinute(5)}
+	
+	limiters := map[string]RateLimiter{
+		"redis":  NewRedisRateLimiter(client, config),
+		"memory": NewMemoryRateLimiter(config),
+	}
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			allowed := func(tenantID string, requests int) int {
+				count := 0
+				for i := 0; i < requests; i++ {
+					info, err := limiter.AllowTenant(tenantID)
+					require.NoError(t, err)
+					if info.Allowed {
+						count++
+					}
+				}
+				return count
+			}
+			
+			assert.Equal(t, 3, allowed(name+"-small", 6))
+			assert.Equal(t, 3, allowed(name+"-other", 3), "tenants don't share a cap")
+			assert.Equal(t, 5, allowed("big-tenant", 7), "override replaces the default tenant limit")
+			assert.Equal(t, 6, allowed("", 6), "requests without a tenant aren't tenant limited")
+			
+			stats := limiter.GetStats()
+			assert.Equal(t, int64(5), stats.TenantBlockedRequests)
+		})
+	}
+}
+
+func TestRateLimiters_CloseStopsCleanupGoroutine(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	// Leave miniredis and the client's connections out of the check
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := DefaultConfig()
+	config.CleanupInterval = 10 * time.Millisecond
+	for name, limiter := range map[string]RateLimiter{
+		"redis":  NewRedisRateLimiter(client, config),
+		"memory": NewMemoryRateLimiter(config),
+	} {
+		_, err := limiter.AllowWithInfo("client1", "/api/v1/vehicles")
+		require.NoError(t, err, name)
+		time.Sleep(30 * time.Millisecond) // let cleanup run
+
+		limiter.Close()
+		limiter.Close() // closing twice is harmless
+	}
+}
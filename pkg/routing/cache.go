@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"fleet-backend/pkg/geo"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultCacheTTL is how long a cached route is reused
+	DefaultCacheTTL = 15 * time.Minute
+	// DefaultCachePrecision rounds coordinates to 3 decimals, about 110 m,
+	// so nearby requests share a route
+	DefaultCachePrecision = 3
+)
+
+// CachedProvider caches another provider's routes in Redis, keyed by origin
+// and destination rounded to a fixed precision. Redis failures fall through
+// to the provider.
+type CachedProvider struct {
+	provider  Provider
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+	precision int
+	ctx       context.Context
+}
+
+// NewCachedProvider wraps provider with a Redis route cache
+func NewCachedProvider(provider Provider, client *redis.Client, keyPrefix string, ttl time.Duration, precision int) *CachedProvider {
+	if keyPrefix == "" {
+		keyPrefix = "routing:route:"
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if precision <= 0 {
+		precision = DefaultCachePrecision
+	}
+	return &CachedProvider{
+		provider:  provider,
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+		precision: precision,
+		ctx:       context.Background(),
+	}
+}
+
+// Name returns the wrapped provider's name
+func (p *CachedProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Route returns the cached route between the rounded points, asking the
+// provider on a miss. Routes come from the rounded points too, so every
+// request sharing a cache key gets the same answer.
+func (p *CachedProvider) Route(origin, destination geo.Point) (*Route, error) {
+	origin, destination = p.round(origin), p.round(destination)
+	key := p.key(origin, destination)
+
+	cached, err := p.client.Get(p.ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var route Route
+		if err := json.Unmarshal(cached, &route); err == nil {
+			return &route, nil
+		}
+		log.Printf("Discarding unreadable cached route %s", key)
+	case !errors.Is(err, redis.Nil):
+		log.Printf("Route cache unavailable: %v", err)
+	}
+
+	route, err := p.provider.Route(origin, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(route); err == nil {
+		if err := p.client.Set(p.ctx, key, data, p.ttl).Err(); err != nil {
+			log.Printf("Failed to cache route %s: %v", key, err)
+		}
+	}
+	return route, nil
+}
+
+func (p *CachedProvider) round(point geo.Point) geo.Point {
+	scale := math.Pow(10, float64(p.precision))
+	return geo.Point{
+		Lat: math.Round(point.Lat*scale) / scale,
+		Lng: math.Round(point.Lng*scale) / scale,
+	}
+}
+
+func (p *CachedProvider) key(origin, destination geo.Point) string {
+	return fmt.Sprintf("%s%s:%.*f,%.*f:%.*f,%.*f", p.keyPrefix, p.provider.Name(),
+		p.precision, origin.Lat, p.precision, origin.Lng,
+		p.precision, destination.Lat, p.precision, destination.Lng)
+}
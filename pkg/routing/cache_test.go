@@ -0,0 +1,84 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fleet-backend/pkg/geo"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProvider struct {
+	route *Route
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubProvider) Route(origin, destination geo.Point) (*Route, error) {
+	p.calls++
+	return p.route, p.err
+}
+
+func newTestCache(t *testing.T, provider Provider) (*CachedProvider, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewCachedProvider(provider, client, "test:route:", time.Minute, DefaultCachePrecision), mr
+}
+
+func TestCachedProvider_NearbyPointsShareRoute(t *testing.T) {
+	stub := &stubProvider{route: &Route{DistanceMeters: 12000, DurationSeconds: 900}}
+	cached, mr := newTestCache(t, stub)
+
+	first, err := cached.Route(geo.Point{Lat: -1.29210, Lng: 36.82190}, geo.Point{Lat: -1.30000, Lng: 36.90000})
+	require.NoError(t, err)
+	// Within the rounding precision of the first request
+	second, err := cached.Route(geo.Point{Lat: -1.29230, Lng: 36.82170}, geo.Point{Lat: -1.30020, Lng: 36.89980})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, stub.calls)
+	assert.Equal(t, first, second)
+	assert.True(t, mr.Exists("test:route:stub:-1.292,36.822:-1.300,36.900"))
+	assert.Equal(t, time.Minute, mr.TTL("test:route:stub:-1.292,36.822:-1.300,36.900"))
+
+	_, err = cached.Route(geo.Point{Lat: -1.2, Lng: 36.8}, geo.Point{Lat: -1.3, Lng: 36.9})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stub.calls, "a different origin misses the cache")
+}
+
+func TestCachedProvider_DoesNotCacheErrors(t *testing.T) {
+	stub := &stubProvider{err: errors.New("provider down")}
+	cached, _ := newTestCache(t, stub)
+	origin, destination := geo.Point{Lat: 0, Lng: 0}, geo.Point{Lat: 0, Lng: 1}
+
+	_, err := cached.Route(origin, destination)
+	require.Error(t, err)
+
+	stub.route, stub.err = &Route{DistanceMeters: 111000, DurationSeconds: 5400}, nil
+	route, err := cached.Route(origin, destination)
+	require.NoError(t, err)
+	assert.Equal(t, 111000.0, route.DistanceMeters)
+	assert.Equal(t, 2, stub.calls)
+}
+
+func TestCachedProvider_RedisDownFallsThrough(t *testing.T) {
+	stub := &stubProvider{route: &Route{DistanceMeters: 5000, DurationSeconds: 600}}
+	cached, mr := newTestCache(t, stub)
+	mr.Close()
+
+	route, err := cached.Route(geo.Point{Lat: 0, Lng: 0}, geo.Point{Lat: 0, Lng: 0.05})
+	require.NoError(t, err)
+	assert.Equal(t, 5000.0, route.DistanceMeters)
+}
@@ -0,0 +1,95 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"fleet-backend/pkg/geo"
+)
+
+// googleDistanceMatrixURL is the Google Maps Distance Matrix endpoint
+const googleDistanceMatrixURL = "https://maps.googleapis.com/maps/api/distancematrix/json"
+
+// GoogleProvider routes with the Google Maps Distance Matrix API
+type GoogleProvider struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewGoogleProvider creates a provider using the given Maps API key
+func NewGoogleProvider(apiKey string, timeout time.Duration) *GoogleProvider {
+	return &GoogleProvider{
+		apiKey:   apiKey,
+		endpoint: googleDistanceMatrixURL,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns "google"
+func (p *GoogleProvider) Name() string {
+	return "google"
+}
+
+// Route asks Google for the driving distance and duration
+func (p *GoogleProvider) Route(origin, destination geo.Point) (*Route, error) {
+	query := url.Values{}
+	query.Set("origins", fmt.Sprintf("%f,%f", origin.Lat, origin.Lng))
+	query.Set("destinations", fmt.Sprintf("%f,%f", destination.Lat, destination.Lng))
+	query.Set("mode", "driving")
+	query.Set("key", p.apiKey)
+
+	resp, err := p.client.Get(p.endpoint + "?" + query.Encode())
+	if err != nil {
+		// Report the cause without the URL, which carries the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("google distance matrix request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Rows         []struct {
+			Elements []struct {
+				Status   string `json:"status"`
+				Distance struct {
+					Value float64 `json:"value"`
+				} `json:"distance"`
+				Duration struct {
+					Value float64 `json:"value"`
+				} `json:"duration"`
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("google distance matrix returned status %d with an unreadable body: %w", resp.StatusCode, err)
+	}
+	if body.Status != "OK" {
+		return nil, fmt.Errorf("google distance matrix returned %s: %s", body.Status, body.ErrorMessage)
+	}
+	if len(body.Rows) == 0 || len(body.Rows[0].Elements) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	element := body.Rows[0].Elements[0]
+	switch element.Status {
+	case "OK":
+	case "ZERO_RESULTS", "NOT_FOUND":
+		return nil, ErrNoRoute
+	default:
+		return nil, fmt.Errorf("google distance matrix element returned %s", element.Status)
+	}
+
+	return &Route{
+		DistanceMeters:  element.Distance.Value,
+		DurationSeconds: element.Duration.Value,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"fleet-backend/pkg/geo"
+)
+
+// DefaultOSRMURL is the public OSRM demo server, which is only suitable for light use
+const DefaultOSRMURL = "https://router.project-osrm.org"
+
+// OSRMProvider routes with an OSRM server's driving profile
+type OSRMProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOSRMProvider creates a provider for the OSRM server at baseURL
+func NewOSRMProvider(baseURL string, timeout time.Duration) *OSRMProvider {
+	if baseURL == "" {
+		baseURL = DefaultOSRMURL
+	}
+	return &OSRMProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns "osrm"
+func (p *OSRMProvider) Name() string {
+	return "osrm"
+}
+
+// Route asks OSRM for the fastest driving route
+func (p *OSRMProvider) Route(origin, destination geo.Point) (*Route, error) {
+	// OSRM takes coordinates as lng,lat
+	url := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=false",
+		p.baseURL, origin.Lng, origin.Lat, destination.Lng, destination.Lat)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("osrm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+		} `json:"routes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("osrm returned status %d with an unreadable body: %w", resp.StatusCode, err)
+	}
+
+	switch {
+	case body.Code == "NoRoute" || (body.Code == "Ok" && len(body.Routes) == 0):
+		return nil, ErrNoRoute
+	case body.Code != "Ok":
+		return nil, fmt.Errorf("osrm returned %s: %s", body.Code, body.Message)
+	}
+
+	return &Route{
+		DistanceMeters:  body.Routes[0].Distance,
+		DurationSeconds: body.Routes[0].Duration,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fleet-backend/pkg/geo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSRMProvider_Route(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"code":"Ok","routes":[{"distance":15234.5,"duration":1260.2}]}`))
+	}))
+	defer server.Close()
+
+	route, err := NewOSRMProvider(server.URL+"/", time.Second).Route(geo.Point{Lat: -1.29, Lng: 36.82}, geo.Point{Lat: -1.3, Lng: 36.9})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/route/v1/driving/36.820000,-1.290000;36.900000,-1.300000", path, "coordinates are sent as lng,lat")
+	assert.Equal(t, 15234.5, route.DistanceMeters)
+	assert.Equal(t, 1260.2, route.DurationSeconds)
+}
+
+func TestOSRMProvider_NoRoute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"NoRoute","message":"Impossible route between points"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewOSRMProvider(server.URL, time.Second).Route(geo.Point{Lat: 0, Lng: 0}, geo.Point{Lat: 0, Lng: 1})
+	assert.ErrorIs(t, err, ErrNoRoute)
+}
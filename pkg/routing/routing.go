@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"errors"
+
+	"fleet-backend/pkg/geo"
+)
+
+// ErrNoRoute is returned when a provider finds no road route between two points
+var ErrNoRoute = errors.New("no route found")
+
+// Route is the road distance and driving time between two points
+type Route struct {
+	DistanceMeters  float64 `json:"distanceMeters"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// Provider computes road routes with an external routing service
+type Provider interface {
+	// Name identifies the provider in ETA responses
+	Name() string
+	Route(origin, destination geo.Point) (*Route, error)
+}
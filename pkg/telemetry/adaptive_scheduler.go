@@ -29,6 +29,7 @@ type AdaptiveScheduler struct {
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
+	wg          sync.WaitGroup // vehicle scheduler goroutines
 }
 
 type VehicleSchedule struct {
@@ -105,8 +106,14 @@ func (as *AdaptiveScheduler) adjustUpdateFrequency(schedule *VehicleSchedule) {
 	schedule.NextUpdate = time.Now().Add(schedule.UpdateInterval)
 }
 
-// startScheduler starts the update scheduler for a vehicle
+// startScheduler starts the update scheduler for a vehicle, replacing the one
+// already running. Must be called with as.mu held.
 func (as *AdaptiveScheduler) startScheduler(schedule *VehicleSchedule, callback func(string)) {
+	// Once stopped, no scheduler is started again
+	if as.ctx.Err() != nil {
+		return
+	}
+	
 	// Stop existing ticker if running
 	if schedule.ticker != nil {
 		schedule.ticker.Stop()
@@ -114,22 +121,28 @@ func (as *AdaptiveScheduler) startScheduler(schedule *VehicleSchedule, callback
 		schedule.stopChan = make(chan struct{})
 	}
 	
-	schedule.ticker = time.NewTicker(schedule.UpdateInterval)
+	// The goroutine keeps its own ticker and stop channel, so a restart can't
+	// hand the replacement's to it
+	ticker := time.NewTicker(schedule.UpdateInterval)
+	stopChan := schedule.stopChan
+	schedule.ticker = ticker
 	
+	as.wg.Add(1)
 	go func() {
+		defer as.wg.Done()
 		for {
 			select {
-			case <-schedule.ticker.C:
+			case <-ticker.C:
 				callback(schedule.VehicleID)
-				schedule.LastUpdate = time.Now()
 				
 				// Readjust frequency based on current state
 				as.mu.Lock()
+				schedule.LastUpdate = time.Now()
 				as.adjustUpdateFrequency(schedule)
-				schedule.ticker.Reset(schedule.UpdateInterval)
+				ticker.Reset(schedule.UpdateInterval)
 				as.mu.Unlock()
 				
-			case <-schedule.stopChan:
+			case <-stopChan:
 				return
 			case <-as.ctx.Done():
 				return
@@ -146,16 +159,53 @@ func (as *AdaptiveScheduler) GetVehicleSchedule(vehicleID string) (*VehicleSched
 	return schedule, exists
 }
 
-// Stop stops all schedulers
-func (as *AdaptiveScheduler) Stop() {
-	as.cancel()
+// RemoveVehicle stops and forgets a vehicle's scheduler; a state change
+// schedules it again
+func (as *AdaptiveScheduler) RemoveVehicle(vehicleID string) {
 	as.mu.Lock()
 	defer as.mu.Unlock()
 	
+	schedule, exists := as.vehicles[vehicleID]
+	if !exists {
+		return
+	}
+	if schedule.ticker != nil {
+		schedule.ticker.Stop()
+		schedule.ticker = nil
+		close(schedule.stopChan)
+	}
+	delete(as.vehicles, vehicleID)
+}
+
+// StopVehicles stops and forgets every vehicle's scheduler. Unlike Stop,
+// vehicles are scheduled again as their state changes.
+func (as *AdaptiveScheduler) StopVehicles() {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	
+	for vehicleID, schedule := range as.vehicles {
+		if schedule.ticker != nil {
+			schedule.ticker.Stop()
+			schedule.ticker = nil
+			close(schedule.stopChan)
+		}
+		delete(as.vehicles, vehicleID)
+	}
+}
+
+// Stop stops all schedulers and waits for their goroutines to exit, including
+// any update callback in progress. It is safe to call more than once.
+func (as *AdaptiveScheduler) Stop() {
+	as.cancel()
+	as.mu.Lock()
 	for _, schedule := range as.vehicles {
 		if schedule.ticker != nil {
 			schedule.ticker.Stop()
+			schedule.ticker = nil
 			close(schedule.stopChan)
 		}
 	}
+	as.mu.Unlock()
+	
+	as.wg.Wait()
 }
\ No newline at end of file
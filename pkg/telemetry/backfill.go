@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+)
+
+// SetBulkLimits sets how many samples a bulk request may hold and how many
+// samples of a chunked backfill are ingested at a time. Non-positive values
+// keep the current limit.
+func (i *Ingestor) SetBulkLimits(maxSamples, chunkSize int) {
+	if maxSamples > 0 {
+		i.maxBulkSamples = maxSamples
+	}
+	if chunkSize > 0 {
+		i.bulkChunkSize = chunkSize
+	}
+}
+
+// MaxBulkSamples returns the most samples accepted in one bulk request
+func (i *Ingestor) MaxBulkSamples() int {
+	return i.maxBulkSamples
+}
+
+// BulkChunkSize returns how many samples of a chunked backfill are ingested at a time
+func (i *Ingestor) BulkChunkSize() int {
+	return i.bulkChunkSize
+}
+
+// BackfillResult summarizes a chunked backfill. Accepted samples are only
+// counted and at most MaxReportedRejections rejections are listed, so the
+// result stays small however many samples were sent.
+type BackfillResult struct {
+	Accepted          int            `json:"accepted"`
+	Rejected          int            `json:"rejected"`
+	Rejections        []SampleResult `json:"rejections"`
+	OmittedRejections int            `json:"omittedRejections,omitempty"`
+	ChunksProcessed   int            `json:"chunksProcessed"`
+}
+
+// Backfill ingests a large sequence of samples one chunk at a time, so only
+// the current chunk has to be held in memory
+type Backfill struct {
+	ingestor *Ingestor
+	known    map[string]*vehicleCheck
+	next     int
+	result   BackfillResult
+}
+
+// NewBackfill starts a chunked backfill
+func (i *Ingestor) NewBackfill() *Backfill {
+	return &Backfill{
+		ingestor: i,
+		known:    make(map[string]*vehicleCheck),
+		result:   BackfillResult{Rejections: []SampleResult{}},
+	}
+}
+
+// Ingest processes the next chunk of samples. Sample indexes in the result
+// count from the start of the backfill, not the chunk.
+func (b *Backfill) Ingest(ctx context.Context, samples []TelemetrySample) {
+	now := time.Now() // a long backfill re-reads the clock for each chunk
+	for index := range samples {
+		sample := &samples[index]
+		reason := b.ingestor.ingest(ctx, sample, b.known, now)
+
+		if reason == "" {
+			b.result.Accepted++
+		} else {
+			b.result.Rejected++
+			if len(b.result.Rejections) < MaxReportedRejections {
+				b.result.Rejections = append(b.result.Rejections, SampleResult{
+					Index:     b.next,
+					VehicleID: sample.VehicleID,
+					Reason:    reason,
+				})
+			} else {
+				b.result.OmittedRejections++
+			}
+		}
+		b.next++
+	}
+	b.result.ChunksProcessed++
+}
+
+// Samples returns how many samples have been ingested so far
+func (b *Backfill) Samples() int {
+	return b.next
+}
+
+// Result returns the summary of the chunks ingested so far
+func (b *Backfill) Result() BackfillResult {
+	return b.result
+}
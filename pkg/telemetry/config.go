@@ -1,8 +1,10 @@
 package telemetry
 
 import (
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,6 +17,18 @@ func LoadTelemetryConfig() TelemetryConfig {
 		EnableBatching:          true,
 		MaxConcurrentUpdates:    10,
 		HealthCheckInterval:     5 * time.Minute,
+		EnableDeduplication:     false,
+		DeduplicationWindow:     5 * time.Minute,
+		MinPlausibleSpeedKmh:    0,
+		MaxPlausibleSpeedKmh:    250,
+		VehicleLockTTL:          30 * time.Second,
+		MaxBulkSamples:          DefaultMaxBulkSamples,
+		BulkChunkSize:           DefaultBulkChunkSize,
+		ReplaySecrets:           make(map[string]string),
+		ReplayWindow:            5 * time.Minute,
+		UnknownVehicleMode:      UnknownVehicleReject,
+		SampleRetention:         30 * 24 * time.Hour,
+		ShedPressure:            DefaultShedPressure,
 	}
 	
 	// Load from environment variables
@@ -54,6 +68,89 @@ func LoadTelemetryConfig() TelemetryConfig {
 		}
 	}
 	
+	if val := os.Getenv("TELEMETRY_DEDUP_ENABLED"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			config.EnableDeduplication = enabled
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_DEDUP_WINDOW"); val != "" {
+		if window, err := time.ParseDuration(val); err == nil && window > 0 {
+			config.DeduplicationWindow = window
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_MIN_SPEED_KMH"); val != "" {
+		if minSpeed, err := strconv.Atoi(val); err == nil {
+			config.MinPlausibleSpeedKmh = minSpeed
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_MAX_SPEED_KMH"); val != "" {
+		if maxSpeed, err := strconv.Atoi(val); err == nil && maxSpeed > config.MinPlausibleSpeedKmh {
+			config.MaxPlausibleSpeedKmh = maxSpeed
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_VEHICLE_LOCK_TTL"); val != "" {
+		if ttl, err := time.ParseDuration(val); err == nil && ttl > 0 {
+			config.VehicleLockTTL = ttl
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_MAX_BULK_SAMPLES"); val != "" {
+		if maxSamples, err := strconv.Atoi(val); err == nil && maxSamples > 0 {
+			config.MaxBulkSamples = maxSamples
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_BULK_CHUNK_SIZE"); val != "" {
+		if chunkSize, err := strconv.Atoi(val); err == nil && chunkSize > 0 {
+			config.BulkChunkSize = chunkSize
+		}
+	}
+	
+	// Comma-separated userID=secret pairs, e.g. "64f0...=s3cret,64f1...=0ther"
+	if val := os.Getenv("TELEMETRY_REPLAY_SECRETS"); val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			userID, secret, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			userID = strings.TrimSpace(userID)
+			if !ok || userID == "" || secret == "" {
+				// The entry may hold a secret, so it isn't logged
+				log.Println("Warning: ignoring malformed entry in TELEMETRY_REPLAY_SECRETS")
+				continue
+			}
+			config.ReplaySecrets[userID] = secret
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_REPLAY_WINDOW"); val != "" {
+		if window, err := time.ParseDuration(val); err == nil && window > 0 {
+			config.ReplayWindow = window
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_SHED_PRESSURE"); val != "" {
+		if pressure, err := strconv.ParseFloat(val, 64); err == nil && pressure >= 0 && pressure <= 1 {
+			config.ShedPressure = pressure
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_SAMPLE_RETENTION"); val != "" {
+		if retention, err := time.ParseDuration(val); err == nil && retention > 0 {
+			config.SampleRetention = retention
+		}
+	}
+	
+	if val := os.Getenv("TELEMETRY_UNKNOWN_VEHICLE_MODE"); val != "" {
+		mode := strings.ToLower(strings.TrimSpace(val))
+		if IsValidUnknownVehicleMode(mode) {
+			config.UnknownVehicleMode = mode
+		} else {
+			log.Printf("Warning: ignoring invalid TELEMETRY_UNKNOWN_VEHICLE_MODE %q", val)
+		}
+	}
+	
 	return config
 }
 
@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Deduplicator detects telemetry messages that have already been processed,
+// so at-least-once transports (MQTT, Kafka) don't apply the same sample twice
+type Deduplicator interface {
+	// IsDuplicate records the message and reports whether it was already seen
+	// within the deduplication window
+	IsDuplicate(vehicleID, messageID string) bool
+	// Forget removes a recorded message, so a retry of a message that
+	// couldn't be processed isn't rejected as a duplicate
+	Forget(vehicleID, messageID string)
+}
+
+// RedisDeduplicator tracks seen message IDs in Redis keys that expire after the window
+type RedisDeduplicator struct {
+	client    *redis.Client
+	keyPrefix string
+	window    time.Duration
+	ctx       context.Context
+}
+
+// NewRedisDeduplicator creates a Redis-backed deduplicator
+func NewRedisDeduplicator(client *redis.Client, keyPrefix string, window time.Duration) *RedisDeduplicator {
+	if keyPrefix == "" {
+		keyPrefix = "telemetry:dedup:"
+	}
+	return &RedisDeduplicator{
+		client:    client,
+		keyPrefix: keyPrefix,
+		window:    window,
+		ctx:       context.Background(),
+	}
+}
+
+// IsDuplicate atomically marks the message as seen and reports whether it already was
+func (d *RedisDeduplicator) IsDuplicate(vehicleID, messageID string) bool {
+	key := d.keyPrefix + vehicleID + ":" + messageID
+
+	firstSeen, err := d.client.SetNX(d.ctx, key, 1, d.window).Result()
+	if err != nil {
+		// Fail open: processing a duplicate is better than dropping a new sample
+		log.Printf("Telemetry deduplication unavailable for vehicle %s: %v", vehicleID, err)
+		return false
+	}
+
+	return !firstSeen
+}
+
+// Forget deletes the message's key so it can be processed again
+func (d *RedisDeduplicator) Forget(vehicleID, messageID string) {
+	if err := d.client.Del(d.ctx, d.keyPrefix+vehicleID+":"+messageID).Err(); err != nil {
+		log.Printf("Failed to forget telemetry message %s for vehicle %s: %v", messageID, vehicleID, err)
+	}
+}
+
+// MemoryDeduplicator tracks seen message IDs in process memory, for deployments without Redis
+type MemoryDeduplicator struct {
+	seen   map[string]time.Time // key -> expiry
+	window time.Duration
+	mu     sync.Mutex
+}
+
+// NewMemoryDeduplicator creates an in-memory deduplicator
+func NewMemoryDeduplicator(window time.Duration) *MemoryDeduplicator {
+	return &MemoryDeduplicator{
+		seen:   make(map[string]time.Time),
+		window: window,
+	}
+}
+
+// IsDuplicate marks the message as seen and reports whether it already was
+func (d *MemoryDeduplicator) IsDuplicate(vehicleID, messageID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	key := vehicleID + ":" + messageID
+
+	if expiry, exists := d.seen[key]; exists && now.Before(expiry) {
+		return true
+	}
+
+	d.seen[key] = now.Add(d.window)
+
+	// Sweep expired entries once the map grows so it stays bounded by the window
+	if len(d.seen)%1000 == 0 {
+		for k, expiry := range d.seen {
+			if !now.Before(expiry) {
+				delete(d.seen, k)
+			}
+		}
+	}
+
+	return false
+}
+
+// Forget removes the message so it can be processed again
+func (d *MemoryDeduplicator) Forget(vehicleID, messageID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, vehicleID+":"+messageID)
+}
@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/batch"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingBatchProcessor records the updates handed to it
+type recordingBatchProcessor struct {
+	mu      sync.Mutex
+	updates []batch.VehicleUpdateData
+}
+
+func (r *recordingBatchProcessor) AddUpdate(vehicleID string, update batch.VehicleUpdateData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, update)
+	return nil
+}
+
+func (r *recordingBatchProcessor) AddUpdates(updates map[string]batch.VehicleUpdateData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, update := range updates {
+		r.updates = append(r.updates, update)
+	}
+	return nil
+}
+
+func (r *recordingBatchProcessor) ProcessBatch() error                     { return nil }
+func (r *recordingBatchProcessor) Flush() error                            { return nil }
+func (r *recordingBatchProcessor) SetBatchSize(size int)                   {}
+func (r *recordingBatchProcessor) SetBatchInterval(interval time.Duration) {}
+func (r *recordingBatchProcessor) GetBatchStats() batch.BatchStats         { return batch.BatchStats{} }
+func (r *recordingBatchProcessor) QueuePressure() float64                  { return 0 }
+func (r *recordingBatchProcessor) Start() error                            { return nil }
+func (r *recordingBatchProcessor) Stop() error                             { return nil }
+
+func setupDedupRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	t.Cleanup(func() {
+		client.Close()
+		mr.Close()
+	})
+	return mr, client
+}
+
+func TestRedisDeduplicator_DropsRepeatedMessageID(t *testing.T) {
+	mr, client := setupDedupRedis(t)
+	dedup := NewRedisDeduplicator(client, "", time.Minute)
+
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+	assert.True(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+
+	// Message IDs are scoped per vehicle
+	assert.False(t, dedup.IsDuplicate("vehicle-2", "msg-1"))
+
+	// The key expires with the window
+	assert.Equal(t, time.Minute, mr.TTL("telemetry:dedup:vehicle-1:msg-1"))
+	mr.FastForward(time.Minute + time.Second)
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+}
+
+func TestDeduplicator_ForgetAllowsRetry(t *testing.T) {
+	_, client := setupDedupRedis(t)
+
+	for name, dedup := range map[string]Deduplicator{
+		"redis":  NewRedisDeduplicator(client, "", time.Minute),
+		"memory": NewMemoryDeduplicator(time.Minute),
+	} {
+		assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"), name)
+		dedup.Forget("vehicle-1", "msg-1")
+		assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"), name)
+		assert.True(t, dedup.IsDuplicate("vehicle-1", "msg-1"), name)
+	}
+}
+
+func TestRedisDeduplicator_FailsOpen(t *testing.T) {
+	mr, client := setupDedupRedis(t)
+	dedup := NewRedisDeduplicator(client, "", time.Minute)
+	mr.Close()
+
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+}
+
+func TestMemoryDeduplicator_Window(t *testing.T) {
+	dedup := NewMemoryDeduplicator(50 * time.Millisecond)
+
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+	assert.True(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, dedup.IsDuplicate("vehicle-1", "msg-1"))
+}
+
+func TestProcessVehicleMessage_DropsDuplicate(t *testing.T) {
+	_, client := setupDedupRedis(t)
+	processor := &recordingBatchProcessor{}
+
+	service := NewOptimizedTelemetryService(nil, processor)
+	defer service.cancel()
+	service.SetDeduplicator(NewRedisDeduplicator(client, "", time.Minute))
+
+	vehicle := &models.Vehicle{
+		FuelLevel: 50,
+		Speed:     40,
+		Status:    "active",
+		Location:  models.Location{Lat: 40.7128, Lng: -74.0060},
+	}
+
+	require.NoError(t, service.ProcessVehicleMessage("vehicle-1", "msg-1", vehicle))
+
+	// Replay the same GPS sample with a different position to prove it never reaches delta tracking
+	replayed := *vehicle
+	replayed.Location = models.Location{Lat: 41.0, Lng: -75.0}
+	require.NoError(t, service.ProcessVehicleMessage("vehicle-1", "msg-1", &replayed))
+
+	stats := service.GetStats()
+	assert.Equal(t, int64(2), stats.TotalUpdatesRequested)
+	assert.Equal(t, int64(1), stats.DuplicatesDropped)
+	assert.Len(t, processor.updates, 1)
+}
+
+func TestProcessVehicleMessage_NoMessageIDSkipsDedup(t *testing.T) {
+	processor := &recordingBatchProcessor{}
+
+	service := NewOptimizedTelemetryService(nil, processor)
+	defer service.cancel()
+	service.SetDeduplicator(NewMemoryDeduplicator(time.Minute))
+
+	vehicle := &models.Vehicle{FuelLevel: 50, Status: "active"}
+	require.NoError(t, service.ProcessVehicleUpdate("vehicle-1", vehicle))
+
+	vehicle.Location = models.Location{Lat: 41.0, Lng: -75.0}
+	require.NoError(t, service.ProcessVehicleUpdate("vehicle-1", vehicle))
+
+	assert.Equal(t, int64(0), service.GetStats().DuplicatesDropped)
+}
+
+func TestProcessVehicleMessage_RejectsImplausibleSpeed(t *testing.T) {
+	processor := &recordingBatchProcessor{}
+
+	service := NewOptimizedTelemetryService(nil, processor)
+	defer service.cancel()
+
+	vehicle := &models.Vehicle{FuelLevel: 50, Speed: 5000, Status: "active"}
+	assert.ErrorIs(t, service.ProcessVehicleUpdate("vehicle-1", vehicle), ErrImplausibleSpeed)
+
+	vehicle.Speed = -1
+	assert.ErrorIs(t, service.ProcessVehicleUpdate("vehicle-1", vehicle), ErrImplausibleSpeed)
+
+	assert.Equal(t, int64(2), service.GetStats().SpeedAnomalies)
+	assert.Empty(t, processor.updates)
+}
@@ -0,0 +1,307 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/batch"
+	"log"
+	"sync"
+	"time"
+)
+
+// Reasons a telemetry sample can be rejected
+const (
+	RejectMissingVehicleID   = "missing_vehicle_id"
+	RejectUnknownVehicle     = "unknown_vehicle"
+	RejectInvalidCoordinates = "invalid_coordinates"
+	RejectInvalidSample      = "invalid_sample"
+	RejectImplausibleSpeed   = "implausible_speed"
+	RejectStaleTimestamp     = "stale_timestamp"
+	RejectFutureTimestamp    = "future_timestamp"
+	RejectDuplicate          = "duplicate"
+	RejectQueueFull          = "queue_full"
+	RejectProcessorStopped   = "processor_stopped"
+	RejectProcessingFailed   = "processing_failed"
+)
+
+const (
+	// DefaultMaxSampleAge is how old a sample may be before it is rejected as stale
+	DefaultMaxSampleAge = 24 * time.Hour
+	// DefaultMaxClockSkew is how far in the future a sample timestamp may be
+	DefaultMaxClockSkew = 5 * time.Minute
+	// DefaultMaxBulkSamples caps the number of samples accepted in one bulk request
+	DefaultMaxBulkSamples = 1000
+	// DefaultBulkChunkSize is how many samples of a chunked backfill are ingested at a time
+	DefaultBulkChunkSize = 500
+	// MaxReportedRejections caps the rejections listed in a chunked backfill result
+	MaxReportedRejections = 1000
+)
+
+// TelemetrySample is a single telemetry reading pushed by an integrator
+type TelemetrySample struct {
+	VehicleID string           `json:"vehicleId"`
+	MessageID string           `json:"messageId,omitempty"`
+	Location  *models.Location `json:"location,omitempty"`
+	FuelLevel *float64         `json:"fuelLevel,omitempty"`
+	Speed     *int             `json:"speed,omitempty"`
+	Odometer  *int             `json:"odometer,omitempty"`
+	Status    *string          `json:"status,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// SampleResult reports whether a single sample was accepted
+type SampleResult struct {
+	Index     int    `json:"index"`
+	VehicleID string `json:"vehicleId"`
+	Accepted  bool   `json:"accepted"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// BulkIngestResult summarizes a bulk telemetry request
+type BulkIngestResult struct {
+	Accepted int            `json:"accepted"`
+	Rejected int            `json:"rejected"`
+	Results  []SampleResult `json:"results"`
+}
+
+// VehicleLookup resolves vehicle IDs to vehicles
+type VehicleLookup interface {
+	GetVehicleByID(ctx context.Context, id string) (*models.Vehicle, error)
+}
+
+// OdometerRecorder receives accepted odometer readings
+type OdometerRecorder interface {
+	RecordOdometer(vehicleID string, odometer int, at time.Time)
+}
+
+// IdleRecorder receives vehicle state changes to accumulate idle time
+type IdleRecorder interface {
+	RecordState(vehicleID string, idle bool, at time.Time)
+}
+
+// StatusRecorder receives the vehicle status of accepted samples
+type StatusRecorder interface {
+	RecordStatus(vehicleID, status string, at time.Time)
+}
+
+// SpeedRecorder receives accepted speed readings for speed statistics
+type SpeedRecorder interface {
+	RecordSpeed(vehicleID string, speedKmh int, location *models.Location, at time.Time)
+}
+
+// Ingestor validates pushed telemetry samples and queues the valid ones on the batch processor
+type Ingestor struct {
+	vehicles        VehicleLookup
+	batchProcessor  batch.BatchProcessor
+	deduplicator    Deduplicator
+	maxSampleAge    time.Duration
+	maxClockSkew    time.Duration
+	speedBounds     services.SpeedPlausibility
+	boundsMu        sync.RWMutex
+	odometers       OdometerRecorder
+	idle            IdleRecorder
+	statuses        StatusRecorder
+	speeds          SpeedRecorder
+	maxBulkSamples  int
+	bulkChunkSize   int
+	unknownVehicles UnknownVehicleHandling
+}
+
+// NewIngestor creates a telemetry ingestor
+func NewIngestor(vehicles VehicleLookup, batchProcessor batch.BatchProcessor) *Ingestor {
+	return &Ingestor{
+		vehicles:        vehicles,
+		batchProcessor:  batchProcessor,
+		maxSampleAge:    DefaultMaxSampleAge,
+		maxClockSkew:    DefaultMaxClockSkew,
+		speedBounds:     services.DefaultSpeedPlausibility(),
+		maxBulkSamples:  DefaultMaxBulkSamples,
+		bulkChunkSize:   DefaultBulkChunkSize,
+		unknownVehicles: UnknownVehicleHandling{Mode: UnknownVehicleReject},
+	}
+}
+
+// SetDeduplicator enables dropping of samples whose message ID was already ingested
+func (i *Ingestor) SetDeduplicator(deduplicator Deduplicator) {
+	i.deduplicator = deduplicator
+}
+
+// SetMaxSampleAge sets how old a sample may be before it is rejected as stale
+func (i *Ingestor) SetMaxSampleAge(maxAge time.Duration) {
+	i.maxSampleAge = maxAge
+}
+
+// SetSpeedPlausibility sets the range of speeds accepted from samples
+func (i *Ingestor) SetSpeedPlausibility(bounds services.SpeedPlausibility) {
+	i.boundsMu.Lock()
+	defer i.boundsMu.Unlock()
+	i.speedBounds = bounds
+}
+
+// SetOdometerRecorder forwards the odometer of each accepted sample to recorder
+func (i *Ingestor) SetOdometerRecorder(recorder OdometerRecorder) {
+	i.odometers = recorder
+}
+
+// SetIdleRecorder forwards the status of each accepted sample to recorder
+func (i *Ingestor) SetIdleRecorder(recorder IdleRecorder) {
+	i.idle = recorder
+}
+
+// SetStatusRecorder forwards the status of each accepted sample to recorder,
+// or the vehicle's current status when the sample doesn't report one
+func (i *Ingestor) SetStatusRecorder(recorder StatusRecorder) {
+	i.statuses = recorder
+}
+
+// SetSpeedRecorder forwards the speed of each accepted sample to recorder
+func (i *Ingestor) SetSpeedRecorder(recorder SpeedRecorder) {
+	i.speeds = recorder
+}
+
+// IngestBulk processes each sample independently and reports a result per sample,
+// in request order
+func (i *Ingestor) IngestBulk(ctx context.Context, samples []TelemetrySample) BulkIngestResult {
+	result := BulkIngestResult{Results: make([]SampleResult, len(samples))}
+	known := make(map[string]*vehicleCheck) // vehicle checks are shared across samples of one request
+	now := time.Now()
+
+	for index := range samples {
+		sample := &samples[index]
+		reason := i.ingest(ctx, sample, known, now)
+
+		result.Results[index] = SampleResult{
+			Index:     index,
+			VehicleID: sample.VehicleID,
+			Accepted:  reason == "",
+			Reason:    reason,
+		}
+		if reason == "" {
+			result.Accepted++
+		} else {
+			result.Rejected++
+		}
+	}
+
+	return result
+}
+
+// ingest validates and queues one sample, returning the rejection reason or ""
+func (i *Ingestor) ingest(ctx context.Context, sample *TelemetrySample, known map[string]*vehicleCheck, now time.Time) string {
+	if reason := i.validate(sample, now); reason != "" {
+		return reason
+	}
+
+	check, checked := known[sample.VehicleID]
+	if !checked {
+		check = i.checkVehicle(ctx, sample, now)
+		known[sample.VehicleID] = check
+	}
+	if check.reason != "" {
+		return check.reason
+	}
+
+	// The ID is claimed before queueing so concurrent retries can't both get
+	// through, and released again if the sample isn't queued
+	deduplicated := sample.MessageID != "" && i.deduplicator != nil
+	if deduplicated && i.deduplicator.IsDuplicate(sample.VehicleID, sample.MessageID) {
+		return RejectDuplicate
+	}
+
+	timestamp := sample.Timestamp
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+
+	err := i.batchProcessor.AddUpdate(sample.VehicleID, batch.VehicleUpdateData{
+		FuelLevel: sample.FuelLevel,
+		Location:  sample.Location,
+		Speed:     sample.Speed,
+		Status:    sample.Status,
+		Odometer:  sample.Odometer,
+		Timestamp: timestamp,
+	})
+	if err != nil && deduplicated {
+		i.deduplicator.Forget(sample.VehicleID, sample.MessageID)
+	}
+
+	switch {
+	case err == nil:
+		if sample.Odometer != nil && i.odometers != nil {
+			i.odometers.RecordOdometer(sample.VehicleID, *sample.Odometer, timestamp)
+		}
+		if sample.Status != nil && i.idle != nil {
+			i.idle.RecordState(sample.VehicleID, *sample.Status == "idle", timestamp)
+		}
+		// Any report confirms the vehicle is still in its status, as it
+		// does for vehicle updates
+		if sample.Status != nil {
+			check.status = *sample.Status
+		}
+		if check.status != "" && i.statuses != nil {
+			i.statuses.RecordStatus(sample.VehicleID, check.status, timestamp)
+		}
+		if sample.Speed != nil && i.speeds != nil {
+			i.speeds.RecordSpeed(sample.VehicleID, *sample.Speed, sample.Location, timestamp)
+		}
+		return ""
+	case errors.Is(err, batch.ErrQueueFull):
+		return RejectQueueFull
+	case errors.Is(err, batch.ErrProcessorStopped):
+		return RejectProcessorStopped
+	default:
+		return RejectProcessingFailed
+	}
+}
+
+// validate checks a sample's fields without touching any backend
+func (i *Ingestor) validate(sample *TelemetrySample, now time.Time) string {
+	if sample.VehicleID == "" {
+		return RejectMissingVehicleID
+	}
+
+	if loc := sample.Location; loc != nil {
+		if loc.Lat < -90 || loc.Lat > 90 || loc.Lng < -180 || loc.Lng > 180 {
+			return RejectInvalidCoordinates
+		}
+	}
+
+	if sample.FuelLevel != nil && *sample.FuelLevel < 0 {
+		return RejectInvalidSample
+	}
+	i.boundsMu.RLock()
+	bounds := i.speedBounds
+	i.boundsMu.RUnlock()
+
+	if sample.Speed != nil && !bounds.IsPlausible(*sample.Speed) {
+		log.Printf("speed_anomaly: rejected speed %d km/h for vehicle %s", *sample.Speed, sample.VehicleID)
+		return RejectImplausibleSpeed
+	}
+	if sample.Odometer != nil && *sample.Odometer < 0 {
+		return RejectInvalidSample
+	}
+	if sample.Status != nil {
+		switch *sample.Status {
+		case "active", "idle", "maintenance", "offline":
+		default:
+			return RejectInvalidSample
+		}
+	}
+	if sample.Location == nil && sample.FuelLevel == nil && sample.Speed == nil &&
+		sample.Odometer == nil && sample.Status == nil {
+		return RejectInvalidSample
+	}
+
+	if !sample.Timestamp.IsZero() {
+		if i.maxSampleAge > 0 && now.Sub(sample.Timestamp) > i.maxSampleAge {
+			return RejectStaleTimestamp
+		}
+		if sample.Timestamp.Sub(now) > i.maxClockSkew {
+			return RejectFutureTimestamp
+		}
+	}
+
+	return ""
+}
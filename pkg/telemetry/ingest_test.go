@@ -0,0 +1,257 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/batch"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVehicleLookup struct {
+	vehicles map[string]bool
+	lookups  int
+}
+
+func (f *fakeVehicleLookup) GetVehicleByID(_ context.Context, id string) (*models.Vehicle, error) {
+	f.lookups++
+	if f.vehicles[id] {
+		return &models.Vehicle{}, nil
+	}
+	return nil, errors.New("vehicle not found")
+}
+
+// fullBatchProcessor rejects every update as if its queue were full
+type fullBatchProcessor struct {
+	recordingBatchProcessor
+}
+
+func (f *fullBatchProcessor) AddUpdate(vehicleID string, update batch.VehicleUpdateData) error {
+	return fmt.Errorf("%w, dropping update for vehicle %s", batch.ErrQueueFull, vehicleID)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+
+func TestIngestBulk_MixedResults(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true, "v2": true}}
+	processor := &recordingBatchProcessor{}
+	ingestor := NewIngestor(lookup, processor)
+
+	samples := []TelemetrySample{
+		{VehicleID: "v1", Location: &models.Location{Lat: -1.29, Lng: 36.82}, Speed: intPtr(45)},
+		{VehicleID: "v2", Location: &models.Location{Lat: 123.0, Lng: 36.82}},
+		{VehicleID: "ghost", FuelLevel: floatPtr(40)},
+		{VehicleID: "v2", FuelLevel: floatPtr(55), Timestamp: time.Now().Add(-time.Minute)},
+		{VehicleID: "v1", Speed: intPtr(50), Timestamp: time.Now().Add(-48 * time.Hour)},
+		{Speed: intPtr(10)},
+		{VehicleID: "v1"},
+	}
+
+	result := ingestor.IngestBulk(context.Background(), samples)
+
+	require.Len(t, result.Results, len(samples))
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, 5, result.Rejected)
+
+	expected := []struct {
+		accepted bool
+		reason   string
+	}{
+		{true, ""},
+		{false, RejectInvalidCoordinates},
+		{false, RejectUnknownVehicle},
+		{true, ""},
+		{false, RejectStaleTimestamp},
+		{false, RejectMissingVehicleID},
+		{false, RejectInvalidSample},
+	}
+	for i, want := range expected {
+		assert.Equal(t, i, result.Results[i].Index)
+		assert.Equal(t, samples[i].VehicleID, result.Results[i].VehicleID)
+		assert.Equal(t, want.accepted, result.Results[i].Accepted, "sample %d", i)
+		assert.Equal(t, want.reason, result.Results[i].Reason, "sample %d", i)
+	}
+
+	// Only accepted samples reach the batch processor
+	require.Len(t, processor.updates, 2)
+	assert.Equal(t, 45, *processor.updates[0].Speed)
+	assert.Equal(t, 55.0, *processor.updates[1].FuelLevel)
+	assert.False(t, processor.updates[0].Timestamp.IsZero())
+
+	// Each vehicle is looked up once per request
+	assert.Equal(t, 3, lookup.lookups)
+}
+
+func TestIngestBulk_QueueFull(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	ingestor := NewIngestor(lookup, &fullBatchProcessor{})
+
+	result := ingestor.IngestBulk(context.Background(), []TelemetrySample{{VehicleID: "v1", Speed: intPtr(30)}})
+
+	assert.Equal(t, 0, result.Accepted)
+	assert.Equal(t, RejectQueueFull, result.Results[0].Reason)
+}
+
+func TestIngestBulk_Duplicates(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	processor := &recordingBatchProcessor{}
+	ingestor := NewIngestor(lookup, processor)
+	ingestor.SetDeduplicator(NewMemoryDeduplicator(time.Minute))
+
+	result := ingestor.IngestBulk(context.Background(), []TelemetrySample{
+		{VehicleID: "v1", MessageID: "m1", Speed: intPtr(30)},
+		{VehicleID: "v1", MessageID: "m1", Speed: intPtr(30)},
+	})
+
+	assert.True(t, result.Results[0].Accepted)
+	assert.Equal(t, RejectDuplicate, result.Results[1].Reason)
+	assert.Len(t, processor.updates, 1)
+}
+
+func TestIngestBulk_RetryAfterQueueFullIsNotDuplicate(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	dedup := NewMemoryDeduplicator(time.Minute)
+	sample := TelemetrySample{VehicleID: "v1", MessageID: "m1", Speed: intPtr(30)}
+
+	full := NewIngestor(lookup, &fullBatchProcessor{})
+	full.SetDeduplicator(dedup)
+	result := full.IngestBulk(context.Background(), []TelemetrySample{sample})
+	assert.Equal(t, RejectQueueFull, result.Results[0].Reason)
+
+	// The device retries once the queue has room
+	processor := &recordingBatchProcessor{}
+	ingestor := NewIngestor(lookup, processor)
+	ingestor.SetDeduplicator(dedup)
+	result = ingestor.IngestBulk(context.Background(), []TelemetrySample{sample})
+	assert.True(t, result.Results[0].Accepted)
+	assert.Len(t, processor.updates, 1)
+}
+
+func TestIngestBulk_FutureTimestamp(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	ingestor := NewIngestor(lookup, &recordingBatchProcessor{})
+
+	result := ingestor.IngestBulk(context.Background(), []TelemetrySample{
+		{VehicleID: "v1", Speed: intPtr(30), Timestamp: time.Now().Add(time.Hour)},
+	})
+
+	assert.Equal(t, RejectFutureTimestamp, result.Results[0].Reason)
+}
+
+func TestIngestBulk_ImplausibleSpeed(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	processor := &recordingBatchProcessor{}
+	ingestor := NewIngestor(lookup, processor)
+
+	result := ingestor.IngestBulk(context.Background(), []TelemetrySample{
+		{VehicleID: "v1", Speed: intPtr(-10)},
+		{VehicleID: "v1", Speed: intPtr(0)},
+		{VehicleID: "v1", Speed: intPtr(90)},
+		{VehicleID: "v1", Speed: intPtr(5000)},
+	})
+
+	assert.Equal(t, RejectImplausibleSpeed, result.Results[0].Reason)
+	assert.True(t, result.Results[1].Accepted)
+	assert.True(t, result.Results[2].Accepted)
+	assert.Equal(t, RejectImplausibleSpeed, result.Results[3].Reason)
+	assert.Len(t, processor.updates, 2)
+}
+
+// recordingOdometers captures the odometer readings forwarded by the ingestor
+type recordingOdometers struct {
+	readings map[string]int
+}
+
+func (r *recordingOdometers) RecordOdometer(vehicleID string, odometer int, at time.Time) {
+	r.readings[vehicleID] = odometer
+}
+
+func TestIngestBulk_ForwardsAcceptedOdometers(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true, "v2": true}}
+	odometers := &recordingOdometers{readings: make(map[string]int)}
+	ingestor := NewIngestor(lookup, &recordingBatchProcessor{})
+	ingestor.SetOdometerRecorder(odometers)
+
+	ingestor.IngestBulk(context.Background(), []TelemetrySample{
+		{VehicleID: "v1", Odometer: intPtr(12000)},
+		{VehicleID: "v2", Odometer: intPtr(-5)},
+		{VehicleID: "ghost", Odometer: intPtr(300)},
+	})
+
+	assert.Equal(t, map[string]int{"v1": 12000}, odometers.readings)
+}
+
+// statusVehicleLookup finds every vehicle with the given status
+type statusVehicleLookup struct {
+	status string
+}
+
+func (s *statusVehicleLookup) GetVehicleByID(_ context.Context, id string) (*models.Vehicle, error) {
+	return &models.Vehicle{Status: s.status}, nil
+}
+
+type statusReading struct {
+	vehicleID string
+	status    string
+}
+
+type recordingStatuses struct {
+	readings []statusReading
+}
+
+func (r *recordingStatuses) RecordStatus(vehicleID, status string, at time.Time) {
+	r.readings = append(r.readings, statusReading{vehicleID, status})
+}
+
+func TestIngestBulk_RecordsCurrentStatusForEverySample(t *testing.T) {
+	statuses := &recordingStatuses{}
+	ingestor := NewIngestor(&statusVehicleLookup{status: "active"}, &recordingBatchProcessor{})
+	ingestor.SetStatusRecorder(statuses)
+
+	maintenance := "maintenance"
+	ingestor.IngestBulk(context.Background(), []TelemetrySample{
+		{VehicleID: "v1", Location: &models.Location{Lat: -1.29, Lng: 36.82}},
+		{VehicleID: "v1", Speed: intPtr(20), Status: &maintenance},
+		{VehicleID: "v1", Speed: intPtr(0)},
+	})
+
+	// Position-only samples confirm the latest known status
+	assert.Equal(t, []statusReading{
+		{"v1", "active"},
+		{"v1", "maintenance"},
+		{"v1", "maintenance"},
+	}, statuses.readings)
+}
+
+func TestBackfill_CapsReportedRejections(t *testing.T) {
+	lookup := &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}}
+	processor := &recordingBatchProcessor{}
+	backfill := NewIngestor(lookup, processor).NewBackfill()
+
+	chunk := make([]TelemetrySample, 500)
+	for i := range chunk {
+		chunk[i] = TelemetrySample{VehicleID: "ghost", Speed: intPtr(30)}
+	}
+	for i := 0; i < 3; i++ {
+		backfill.Ingest(context.Background(), chunk)
+	}
+	backfill.Ingest(context.Background(), []TelemetrySample{{VehicleID: "v1", Speed: intPtr(30)}})
+
+	result := backfill.Result()
+	assert.Equal(t, 1, result.Accepted)
+	assert.Equal(t, 1500, result.Rejected)
+	assert.Len(t, result.Rejections, MaxReportedRejections)
+	assert.Equal(t, 1500-MaxReportedRejections, result.OmittedRejections)
+	assert.Equal(t, 4, result.ChunksProcessed)
+	assert.Equal(t, 1501, backfill.Samples())
+	assert.Len(t, processor.updates, 1)
+
+	// Each vehicle is still looked up once across chunks
+	assert.Equal(t, 2, lookup.lookups)
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-backend/internal/repository"
+	"fleet-backend/internal/services"
+	"fleet-backend/pkg/batch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/goleak"
+)
+
+func TestOptimizedTelemetryService_StopLeavesNoGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// Loading the initial schedules fails quickly against a database that
+	// can't be reached; the service runs without them
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+	vehicleRepo := repository.NewVehicleRepository(client.Database("fleet_test"))
+	vehicleRepo.SetTimeouts(repository.Timeouts{Read: 50 * time.Millisecond})
+
+	processor := batch.NewBatchProcessor(batch.BatchConfig{
+		MaxBatchSize:  10,
+		BatchInterval: time.Hour,
+		MaxWaitTime:   time.Hour,
+	}, nil)
+	ots := NewOptimizedTelemetryService(services.NewVehicleService(vehicleRepo), processor)
+	ots.config.HealthCheckInterval = 10 * time.Millisecond
+
+	assert.Error(t, ots.Start())
+
+	// State changes start a scheduler per vehicle, and restart it on each change
+	ots.UpdateVehicleState("vehicle-1", StateIdle)
+	ots.UpdateVehicleState("vehicle-1", StateActive)
+	ots.UpdateVehicleState("vehicle-2", StateMaintenance)
+	time.Sleep(30 * time.Millisecond) // let the health check run
+
+	require.NoError(t, ots.Stop())
+
+	// A state change after Stop doesn't start another scheduler
+	ots.UpdateVehicleState("vehicle-2", StateOffline)
+}
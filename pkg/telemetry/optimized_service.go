@@ -2,15 +2,20 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fleet-backend/internal/models"
 	"fleet-backend/internal/services"
 	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/lock"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 )
 
+// ErrImplausibleSpeed is returned for updates whose speed is outside the plausible range
+var ErrImplausibleSpeed = errors.New("implausible speed reading")
+
 // OptimizedTelemetryService combines all optimization strategies
 type OptimizedTelemetryService struct {
 	vehicleService    *services.VehicleService
@@ -18,6 +23,10 @@ type OptimizedTelemetryService struct {
 	deltaTracker      *DeltaTracker
 	rateLimiter       *SmartRateLimiter
 	batchProcessor    batch.BatchProcessor
+	deduplicator      Deduplicator
+	idleRecorder      IdleRecorder
+	speedRecorder     SpeedRecorder
+	vehicleLocker     lock.Locker
 	
 	// Configuration
 	config            TelemetryConfig
@@ -27,6 +36,7 @@ type OptimizedTelemetryService struct {
 	mu                sync.RWMutex
 	ctx               context.Context
 	cancel            context.CancelFunc
+	wg                sync.WaitGroup // background goroutines, waited for on Stop
 	
 	// Statistics
 	stats             TelemetryStats
@@ -40,17 +50,73 @@ type TelemetryConfig struct {
 	EnableBatching          bool
 	MaxConcurrentUpdates    int
 	HealthCheckInterval     time.Duration
+	EnableDeduplication     bool
+	DeduplicationWindow     time.Duration
+	MinPlausibleSpeedKmh    int
+	MaxPlausibleSpeedKmh    int
+	VehicleLockTTL          time.Duration
+	// MaxBulkSamples caps the samples in one bulk request; larger backfills
+	// must be sent chunked
+	MaxBulkSamples          int
+	// BulkChunkSize is how many samples of a chunked backfill are held in
+	// memory and ingested at a time
+	BulkChunkSize           int
+	// ReplaySecrets maps the user ID of each integration whose ingestion
+	// requests must be signed to its signing secret; other callers are not
+	// checked. ReplayWindow is how far a signed request's timestamp may be
+	// from the server's clock.
+	ReplaySecrets           map[string]string
+	ReplayWindow            time.Duration
+	// UnknownVehicleMode is how pushed telemetry for vehicle IDs no vehicle
+	// has is handled: reject, provision or queue
+	UnknownVehicleMode      string
+	// SampleRetention is how long raw telemetry samples are kept before they expire
+	SampleRetention         time.Duration
+	// ShedPressure is the batch queue pressure from which low-priority delta
+	// updates are shed instead of queued; 0 disables shedding
+	ShedPressure            float64
+}
+
+// Optimizations lists which optimizations the service applies. Each can be
+// switched at runtime, e.g. to rule one out while it misbehaves in production.
+type Optimizations struct {
+	AdaptiveScheduling bool `json:"adaptiveScheduling"`
+	DeltaUpdates       bool `json:"deltaUpdates"`
+	RateLimiting       bool `json:"rateLimiting"`
+	Batching           bool `json:"batching"`
+	// Deduplication only takes effect with a deduplicator set
+	Deduplication bool `json:"deduplication"`
 }
 
+// DefaultShedPressure is the batch queue pressure from which updates are shed
+const DefaultShedPressure = 0.8
+
+// TelemetryStats counts what happened to the updates the service was asked
+// to process. UpdatesSkipped covers the updates the optimizations saved:
+// duplicates, rate limit rejects, delta skips and updates shed under queue
+// pressure.
 type TelemetryStats struct {
-	TotalUpdatesRequested   int64
-	UpdatesSkipped         int64
-	UpdatesSent            int64
-	RateLimitRejects       int64
-	DeltaSkips            int64
-	AverageUpdateSize     float64
-	LastUpdateTime        time.Time
-	ActiveVehicleCount    int
+	TotalUpdatesRequested   int64     `json:"totalUpdatesRequested"`
+	UpdatesSkipped         int64     `json:"updatesSkipped"`
+	UpdatesSent            int64     `json:"updatesSent"`
+	RateLimitRejects       int64     `json:"rateLimitRejects"`
+	DeltaSkips            int64     `json:"deltaSkips"`
+	DuplicatesDropped     int64     `json:"duplicatesDropped"`
+	SpeedAnomalies        int64     `json:"speedAnomalies"`
+	LockedSkips           int64     `json:"lockedSkips"`
+	PressureSheds         int64     `json:"pressureSheds"`
+	AverageUpdateSize     float64   `json:"averageUpdateSize"`
+	LastUpdateTime        time.Time `json:"lastUpdateTime"`
+	ActiveVehicleCount    int       `json:"activeVehicleCount"`
+}
+
+// OptimizationEfficiency is the fraction of requested updates that were
+// skipped, 0 before any update is requested
+func (s TelemetryStats) OptimizationEfficiency() float64 {
+	if s.TotalUpdatesRequested == 0 {
+		return 0
+	}
+	return float64(s.UpdatesSkipped) / float64(s.TotalUpdatesRequested)
 }
 
 func NewOptimizedTelemetryService(vehicleService *services.VehicleService, batchProcessor batch.BatchProcessor) *OptimizedTelemetryService {
@@ -69,6 +135,10 @@ func NewOptimizedTelemetryService(vehicleService *services.VehicleService, batch
 			EnableBatching:          true,
 			MaxConcurrentUpdates:    10,
 			HealthCheckInterval:     5 * time.Minute,
+			DeduplicationWindow:     5 * time.Minute,
+			MaxPlausibleSpeedKmh:    services.DefaultSpeedPlausibility().MaxKmh,
+			VehicleLockTTL:          30 * time.Second,
+			ShedPressure:            DefaultShedPressure,
 		},
 		activeVehicles: make(map[string]bool),
 		ctx:           ctx,
@@ -76,19 +146,128 @@ func NewOptimizedTelemetryService(vehicleService *services.VehicleService, batch
 	}
 }
 
+// SetDeduplicator enables dropping of telemetry messages whose ID was already processed
+func (ots *OptimizedTelemetryService) SetDeduplicator(deduplicator Deduplicator) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.deduplicator = deduplicator
+	ots.config.EnableDeduplication = deduplicator != nil
+}
+
+// Optimizations returns the optimizations currently enabled
+func (ots *OptimizedTelemetryService) Optimizations() Optimizations {
+	ots.mu.RLock()
+	defer ots.mu.RUnlock()
+	return Optimizations{
+		AdaptiveScheduling: ots.config.EnableAdaptiveScheduling,
+		DeltaUpdates:       ots.config.EnableDeltaUpdates,
+		RateLimiting:       ots.config.EnableRateLimiting,
+		Batching:           ots.config.EnableBatching,
+		Deduplication:      ots.config.EnableDeduplication,
+	}
+}
+
+// SetAdaptiveSchedulingEnabled switches adaptive scheduling. Disabling it
+// stops every vehicle's scheduled updates; once enabled again, vehicles are
+// scheduled as their state changes.
+func (ots *OptimizedTelemetryService) SetAdaptiveSchedulingEnabled(enabled bool) {
+	ots.mu.Lock()
+	ots.config.EnableAdaptiveScheduling = enabled
+	ots.mu.Unlock()
+
+	if !enabled && ots.scheduler != nil {
+		ots.scheduler.StopVehicles()
+	}
+}
+
+// SetDeltaUpdatesEnabled switches delta tracking; while disabled every update
+// is processed in full
+func (ots *OptimizedTelemetryService) SetDeltaUpdatesEnabled(enabled bool) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.EnableDeltaUpdates = enabled
+}
+
+// SetRateLimitingEnabled switches per-vehicle rate limiting
+func (ots *OptimizedTelemetryService) SetRateLimitingEnabled(enabled bool) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.EnableRateLimiting = enabled
+}
+
+// SetBatchingEnabled switches batching; while disabled updates are written
+// directly. Updates already queued are still written by the batch processor.
+func (ots *OptimizedTelemetryService) SetBatchingEnabled(enabled bool) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.EnableBatching = enabled
+}
+
+// SetDeduplicationEnabled switches dropping of replayed messages. It has no
+// effect without a deduplicator.
+func (ots *OptimizedTelemetryService) SetDeduplicationEnabled(enabled bool) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.EnableDeduplication = enabled && ots.deduplicator != nil
+}
+
+// SetIdleRecorder forwards vehicle state changes to recorder so idle time is accumulated
+func (ots *OptimizedTelemetryService) SetIdleRecorder(recorder IdleRecorder) {
+	ots.idleRecorder = recorder
+}
+
+// SetSpeedRecorder forwards vehicle speeds to recorder for speed statistics
+func (ots *OptimizedTelemetryService) SetSpeedRecorder(recorder SpeedRecorder) {
+	ots.speedRecorder = recorder
+}
+
+// SetVehicleLocker makes scheduled updates of a vehicle run only on the replica
+// holding its lock, so replicas sharing a database don't drive the same vehicle.
+// Locks are taken for ttl; the locker is expected to renew the ones it holds.
+func (ots *OptimizedTelemetryService) SetVehicleLocker(locker lock.Locker, ttl time.Duration) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.vehicleLocker = locker
+	if ttl > 0 {
+		ots.config.VehicleLockTTL = ttl
+	}
+}
+
+// SetSpeedPlausibility sets the range of speeds accepted before an update is
+// rejected as a speed anomaly
+func (ots *OptimizedTelemetryService) SetSpeedPlausibility(bounds services.SpeedPlausibility) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.MinPlausibleSpeedKmh = bounds.MinKmh
+	ots.config.MaxPlausibleSpeedKmh = bounds.MaxKmh
+}
+
+// SetShedPressure sets the batch queue pressure, from 0 to 1, from which
+// low-priority delta updates are shed; 0 disables shedding
+func (ots *OptimizedTelemetryService) SetShedPressure(pressure float64) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.config.ShedPressure = min(max(pressure, 0), 1)
+}
+
 // Start initializes the optimized telemetry service
 func (ots *OptimizedTelemetryService) Start() error {
 	log.Println("Starting optimized telemetry service...")
 	
-	// Start batch processor if enabled
-	if ots.config.EnableBatching && ots.batchProcessor != nil {
+	// Start the batch processor even with batching disabled, so batching can
+	// be enabled at runtime
+	if ots.batchProcessor != nil {
 		if err := ots.batchProcessor.Start(); err != nil {
 			return fmt.Errorf("failed to start batch processor: %v", err)
 		}
 	}
 	
 	// Start health check routine
-	go ots.healthCheckLoop()
+	ots.wg.Add(1)
+	go func() {
+		defer ots.wg.Done()
+		ots.healthCheckLoop()
+	}()
 	
 	// Initialize vehicle schedules
 	if err := ots.initializeVehicleSchedules(); err != nil {
@@ -99,7 +278,8 @@ func (ots *OptimizedTelemetryService) Start() error {
 	return nil
 }
 
-// Stop gracefully shuts down the telemetry service
+// Stop gracefully shuts down the telemetry service. It returns once the
+// health check and every vehicle scheduler have exited.
 func (ots *OptimizedTelemetryService) Stop() error {
 	log.Println("Stopping optimized telemetry service...")
 	
@@ -108,8 +288,9 @@ func (ots *OptimizedTelemetryService) Stop() error {
 	if ots.scheduler != nil {
 		ots.scheduler.Stop()
 	}
+	ots.wg.Wait()
 	
-	if ots.config.EnableBatching && ots.batchProcessor != nil {
+	if ots.batchProcessor != nil {
 		if err := ots.batchProcessor.Stop(); err != nil {
 			log.Printf("Error stopping batch processor: %v", err)
 		}
@@ -121,10 +302,44 @@ func (ots *OptimizedTelemetryService) Stop() error {
 
 // ProcessVehicleUpdate processes a vehicle update with all optimizations
 func (ots *OptimizedTelemetryService) ProcessVehicleUpdate(vehicleID string, vehicle *models.Vehicle) error {
+	return ots.ProcessVehicleMessage(vehicleID, "", vehicle)
+}
+
+// ProcessVehicleMessage processes a vehicle update carrying an idempotency/message ID.
+// Messages whose ID was already seen within the deduplication window are dropped;
+// an empty message ID disables deduplication for that update.
+func (ots *OptimizedTelemetryService) ProcessVehicleMessage(vehicleID, messageID string, vehicle *models.Vehicle) error {
 	ots.incrementTotalRequests()
+	optimizations := ots.Optimizations()
+	
+	// 0. Drop replayed messages before they reach any other stage
+	if messageID != "" && optimizations.Deduplication && ots.deduplicator != nil {
+		if ots.deduplicator.IsDuplicate(vehicleID, messageID) {
+			ots.incrementDuplicatesDropped()
+			return nil
+		}
+	}
+	
+	// Reject sensor faults before they can trigger speeding alerts
+	ots.mu.RLock()
+	bounds := services.SpeedPlausibility{MinKmh: ots.config.MinPlausibleSpeedKmh, MaxKmh: ots.config.MaxPlausibleSpeedKmh}
+	ots.mu.RUnlock()
+	if vehicle != nil && !bounds.IsPlausible(vehicle.Speed) {
+		ots.incrementSpeedAnomalies()
+		log.Printf("speed_anomaly: rejected speed %d km/h for vehicle %s", vehicle.Speed, vehicleID)
+		return ErrImplausibleSpeed
+	}
+	
+	// Track idle time and speed history from the report
+	if vehicle != nil && ots.idleRecorder != nil {
+		ots.idleRecorder.RecordState(vehicleID, ots.mapStatusToState(vehicle.Status) == StateIdle, time.Now())
+	}
+	if vehicle != nil && ots.speedRecorder != nil {
+		ots.speedRecorder.RecordSpeed(vehicleID, vehicle.Speed, &vehicle.Location, time.Now())
+	}
 	
 	// 1. Check rate limiting if enabled
-	if ots.config.EnableRateLimiting {
+	if optimizations.RateLimiting {
 		priority := ots.determinePriority(vehicle)
 		allowed, retryAfter := ots.rateLimiter.CanMakeRequest(vehicleID, priority)
 		
@@ -136,7 +351,15 @@ func (ots *OptimizedTelemetryService) ProcessVehicleUpdate(vehicleID string, veh
 	}
 	
 	// 2. Check if update is significant using delta tracking
-	if ots.config.EnableDeltaUpdates {
+	if optimizations.DeltaUpdates {
+		// Shed minor updates while the batch queue is close to dropping
+		// updates. This comes before the delta tracker records the update as
+		// sent, so the shed change still goes out with the next one.
+		if ots.shouldShed(ots.determinePriority(vehicle)) {
+			ots.incrementPressureSheds()
+			return nil
+		}
+		
 		shouldUpdate, changes := ots.deltaTracker.ShouldUpdate(vehicleID, vehicle)
 		if !shouldUpdate {
 			ots.incrementDeltaSkips()
@@ -153,7 +376,7 @@ func (ots *OptimizedTelemetryService) ProcessVehicleUpdate(vehicleID string, veh
 
 // processDeltaUpdate processes only the changed fields
 func (ots *OptimizedTelemetryService) processDeltaUpdate(vehicleID string, changes map[string]interface{}) error {
-	if ots.config.EnableBatching && ots.batchProcessor != nil {
+	if ots.Optimizations().Batching && ots.batchProcessor != nil {
 		// Convert changes to batch update format
 		updateData := ots.convertToBatchUpdate(changes)
 		return ots.batchProcessor.AddUpdate(vehicleID, updateData)
@@ -165,7 +388,7 @@ func (ots *OptimizedTelemetryService) processDeltaUpdate(vehicleID string, chang
 
 // processFullUpdate processes a complete vehicle update
 func (ots *OptimizedTelemetryService) processFullUpdate(vehicleID string, vehicle *models.Vehicle) error {
-	if ots.config.EnableBatching && ots.batchProcessor != nil {
+	if ots.Optimizations().Batching && ots.batchProcessor != nil {
 		updateData := batch.VehicleUpdateData{
 			FuelLevel: &vehicle.FuelLevel,
 			Location:  &vehicle.Location,
@@ -181,12 +404,14 @@ func (ots *OptimizedTelemetryService) processFullUpdate(vehicleID string, vehicl
 	updateReq := &services.UpdateVehicleRequest{
 		FuelLevel: vehicle.FuelLevel,
 		Location:  &vehicle.Location,
-		Speed:     vehicle.Speed,
+		Speed:     &vehicle.Speed,
 		Status:    vehicle.Status,
 		Odometer:  vehicle.Odometer,
+		// Telemetry reports fuel in liters
+		FuelInLiters: true,
 	}
 	
-	_, err := ots.vehicleService.UpdateVehicle(vehicleID, updateReq)
+	_, err := ots.vehicleService.UpdateVehicle(ots.ctx, vehicleID, updateReq)
 	if err == nil {
 		ots.incrementUpdatesSent()
 	}
@@ -195,7 +420,7 @@ func (ots *OptimizedTelemetryService) processFullUpdate(vehicleID string, vehicl
 
 // UpdateVehicleState updates vehicle state and adjusts scheduling
 func (ots *OptimizedTelemetryService) UpdateVehicleState(vehicleID string, state VehicleState) {
-	if ots.config.EnableAdaptiveScheduling {
+	if ots.Optimizations().AdaptiveScheduling {
 		ots.scheduler.UpdateVehicleState(vehicleID, state, func(id string) {
 			// This callback is triggered when it's time to update the vehicle
 			ots.scheduleVehicleUpdate(id)
@@ -206,12 +431,27 @@ func (ots *OptimizedTelemetryService) UpdateVehicleState(vehicleID string, state
 	ots.mu.Lock()
 	ots.activeVehicles[vehicleID] = (state == StateActive || state == StateIdle)
 	ots.mu.Unlock()
+
+	if ots.idleRecorder != nil {
+		ots.idleRecorder.RecordState(vehicleID, state == StateIdle, time.Now())
+	}
 }
 
 // scheduleVehicleUpdate is called by the adaptive scheduler
 func (ots *OptimizedTelemetryService) scheduleVehicleUpdate(vehicleID string) {
+	// Another replica drives this vehicle
+	if !ots.ownsVehicle(vehicleID) {
+		ots.incrementLockedSkips()
+		return
+	}
+	
 	// Get current vehicle data
-	vehicle, err := ots.vehicleService.GetVehicleByID(vehicleID)
+	vehicle, err := ots.vehicleService.GetVehicleByID(ots.ctx, vehicleID)
+	if errors.Is(err, services.ErrVehicleNotFound) {
+		// The vehicle was deleted; stop driving it and hand its lock back
+		ots.forgetVehicle(vehicleID)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to get vehicle %s for scheduled update: %v", vehicleID, err)
 		return
@@ -223,9 +463,47 @@ func (ots *OptimizedTelemetryService) scheduleVehicleUpdate(vehicleID string) {
 	}
 }
 
+// ownsVehicle reports whether this replica holds the vehicle's lock, taking it
+// if it is free. Without a locker every vehicle is driven by this replica.
+func (ots *OptimizedTelemetryService) ownsVehicle(vehicleID string) bool {
+	ots.mu.RLock()
+	locker := ots.vehicleLocker
+	ttl := ots.config.VehicleLockTTL
+	ots.mu.RUnlock()
+	
+	if locker == nil {
+		return true
+	}
+	
+	acquired, err := locker.TryAcquire("vehicle:"+vehicleID, ttl)
+	if err != nil {
+		// Skip rather than risk two replicas driving the vehicle; the next tick retries
+		log.Printf("Failed to lock vehicle %s for scheduled update: %v", vehicleID, err)
+		return false
+	}
+	return acquired
+}
+
+// forgetVehicle stops scheduling a vehicle and releases its lock, so the
+// locker stops renewing it
+func (ots *OptimizedTelemetryService) forgetVehicle(vehicleID string) {
+	ots.scheduler.RemoveVehicle(vehicleID)
+	
+	ots.mu.Lock()
+	delete(ots.activeVehicles, vehicleID)
+	locker := ots.vehicleLocker
+	ots.mu.Unlock()
+	
+	if locker != nil {
+		if err := locker.Release("vehicle:" + vehicleID); err != nil {
+			log.Printf("Failed to release lock of deleted vehicle %s: %v", vehicleID, err)
+		}
+	}
+}
+
 // initializeVehicleSchedules sets up initial schedules for all vehicles
 func (ots *OptimizedTelemetryService) initializeVehicleSchedules() error {
-	vehicles, err := ots.vehicleService.GetAllVehicles()
+	vehicles, err := ots.vehicleService.GetAllVehicles(ots.ctx)
 	if err != nil {
 		return err
 	}
@@ -254,6 +532,29 @@ func (ots *OptimizedTelemetryService) mapStatusToState(status string) VehicleSta
 	}
 }
 
+// shouldShed reports whether a delta update of the given priority is shed
+// rather than queued on the batch processor. From the shed pressure low
+// priority updates are shed, and from halfway between it and a full queue
+// medium priority ones too; high and critical updates are always queued.
+func (ots *OptimizedTelemetryService) shouldShed(priority Priority) bool {
+	ots.mu.RLock()
+	batching := ots.config.EnableBatching
+	threshold := ots.config.ShedPressure
+	ots.mu.RUnlock()
+	if !batching || ots.batchProcessor == nil || priority >= PriorityHigh {
+		return false
+	}
+	if threshold <= 0 {
+		return false
+	}
+
+	pressure := ots.batchProcessor.QueuePressure()
+	if priority == PriorityMedium {
+		return pressure >= (threshold+1)/2
+	}
+	return pressure >= threshold
+}
+
 // determinePriority determines update priority based on vehicle data
 func (ots *OptimizedTelemetryService) determinePriority(vehicle *models.Vehicle) Priority {
 	// Critical: Low fuel, alerts, or maintenance status
@@ -306,7 +607,8 @@ func (ots *OptimizedTelemetryService) convertToBatchUpdate(changes map[string]in
 
 // directDeltaUpdate performs direct update with only changed fields
 func (ots *OptimizedTelemetryService) directDeltaUpdate(vehicleID string, changes map[string]interface{}) error {
-	updateReq := &services.UpdateVehicleRequest{}
+	// Telemetry reports fuel in liters
+	updateReq := &services.UpdateVehicleRequest{FuelInLiters: true}
 	
 	if fuelLevel, ok := changes["fuelLevel"].(float64); ok {
 		updateReq.FuelLevel = fuelLevel
@@ -317,7 +619,7 @@ func (ots *OptimizedTelemetryService) directDeltaUpdate(vehicleID string, change
 	}
 	
 	if speed, ok := changes["speed"].(int); ok {
-		updateReq.Speed = speed
+		updateReq.Speed = &speed
 	}
 	
 	if status, ok := changes["status"].(string); ok {
@@ -328,7 +630,7 @@ func (ots *OptimizedTelemetryService) directDeltaUpdate(vehicleID string, change
 		updateReq.Odometer = odometer
 	}
 	
-	_, err := ots.vehicleService.UpdateVehicle(vehicleID, updateReq)
+	_, err := ots.vehicleService.UpdateVehicle(ots.ctx, vehicleID, updateReq)
 	if err == nil {
 		ots.incrementUpdatesSent()
 	}
@@ -355,9 +657,9 @@ func (ots *OptimizedTelemetryService) performHealthCheck() {
 	stats := ots.GetStats()
 	
 	// Log current statistics
-	log.Printf("Telemetry Stats - Total: %d, Sent: %d, Skipped: %d, Rate Limited: %d, Delta Skips: %d, Active Vehicles: %d",
+	log.Printf("Telemetry Stats - Total: %d, Sent: %d, Skipped: %d, Rate Limited: %d, Delta Skips: %d, Duplicates: %d, Active Vehicles: %d",
 		stats.TotalUpdatesRequested, stats.UpdatesSent, stats.UpdatesSkipped,
-		stats.RateLimitRejects, stats.DeltaSkips, stats.ActiveVehicleCount)
+		stats.RateLimitRejects, stats.DeltaSkips, stats.DuplicatesDropped, stats.ActiveVehicleCount)
 	
 	// Adjust thresholds based on performance
 	if stats.RateLimitRejects > stats.UpdatesSent/2 {
@@ -383,12 +685,40 @@ func (ots *OptimizedTelemetryService) incrementRateLimitRejects() {
 	ots.statsMux.Lock()
 	defer ots.statsMux.Unlock()
 	ots.stats.RateLimitRejects++
+	ots.stats.UpdatesSkipped++
 }
 
 func (ots *OptimizedTelemetryService) incrementDeltaSkips() {
 	ots.statsMux.Lock()
 	defer ots.statsMux.Unlock()
 	ots.stats.DeltaSkips++
+	ots.stats.UpdatesSkipped++
+}
+
+func (ots *OptimizedTelemetryService) incrementDuplicatesDropped() {
+	ots.statsMux.Lock()
+	defer ots.statsMux.Unlock()
+	ots.stats.DuplicatesDropped++
+	ots.stats.UpdatesSkipped++
+}
+
+func (ots *OptimizedTelemetryService) incrementSpeedAnomalies() {
+	ots.statsMux.Lock()
+	defer ots.statsMux.Unlock()
+	ots.stats.SpeedAnomalies++
+}
+
+func (ots *OptimizedTelemetryService) incrementPressureSheds() {
+	ots.statsMux.Lock()
+	defer ots.statsMux.Unlock()
+	ots.stats.PressureSheds++
+	ots.stats.UpdatesSkipped++
+}
+
+func (ots *OptimizedTelemetryService) incrementLockedSkips() {
+	ots.statsMux.Lock()
+	defer ots.statsMux.Unlock()
+	ots.stats.LockedSkips++
 }
 
 // GetStats returns current telemetry statistics
@@ -0,0 +1,199 @@
+package telemetry
+
+import (
+	"fleet-backend/internal/models"
+	"fleet-backend/pkg/batch"
+	"fleet-backend/pkg/lock"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptimizedTelemetryService_OnlyOneReplicaDrivesVehicle(t *testing.T) {
+	mr, client := setupDedupRedis(t)
+
+	first := NewOptimizedTelemetryService(nil, nil)
+	first.SetVehicleLocker(lock.NewRedisLocker(client, "telemetry:lock:", "replica-a"), 30*time.Second)
+	second := NewOptimizedTelemetryService(nil, nil)
+	second.SetVehicleLocker(lock.NewRedisLocker(client, "telemetry:lock:", "replica-b"), 30*time.Second)
+
+	assert.True(t, first.ownsVehicle("vehicle-1"))
+	assert.False(t, second.ownsVehicle("vehicle-1"))
+	assert.True(t, second.ownsVehicle("vehicle-2"))
+
+	// The holder keeps the vehicle on later ticks
+	assert.True(t, first.ownsVehicle("vehicle-1"))
+
+	// A replica that stops renewing loses the vehicle once the lock expires
+	mr.FastForward(31 * time.Second)
+	assert.True(t, second.ownsVehicle("vehicle-1"))
+	assert.False(t, first.ownsVehicle("vehicle-1"))
+}
+
+func TestOptimizedTelemetryService_ForgettingVehicleReleasesItsLock(t *testing.T) {
+	_, client := setupDedupRedis(t)
+
+	first := NewOptimizedTelemetryService(nil, nil)
+	first.SetVehicleLocker(lock.NewRedisLocker(client, "telemetry:lock:", "replica-a"), 30*time.Second)
+	defer first.Stop()
+	second := NewOptimizedTelemetryService(nil, nil)
+	second.SetVehicleLocker(lock.NewRedisLocker(client, "telemetry:lock:", "replica-b"), 30*time.Second)
+
+	first.UpdateVehicleState("vehicle-1", StateActive)
+	_, scheduled := first.scheduler.GetVehicleSchedule("vehicle-1")
+	require.True(t, scheduled)
+	require.True(t, first.ownsVehicle("vehicle-1"))
+
+	// A deleted vehicle is no longer scheduled and its lock is free
+	first.forgetVehicle("vehicle-1")
+
+	_, scheduled = first.scheduler.GetVehicleSchedule("vehicle-1")
+	assert.False(t, scheduled)
+	assert.Zero(t, first.GetStats().ActiveVehicleCount)
+	assert.True(t, second.ownsVehicle("vehicle-1"))
+}
+
+func TestOptimizedTelemetryService_DrivesEveryVehicleWithoutLocker(t *testing.T) {
+	ots := NewOptimizedTelemetryService(nil, nil)
+	assert.True(t, ots.ownsVehicle("vehicle-1"))
+}
+
+func TestOptimizedTelemetryService_SkipsVehicleWhenLockUnavailable(t *testing.T) {
+	mr, client := setupDedupRedis(t)
+	ots := NewOptimizedTelemetryService(nil, nil)
+	ots.SetVehicleLocker(lock.NewRedisLocker(client, "telemetry:lock:", "replica-a"), 30*time.Second)
+
+	mr.SetError("connection refused")
+	assert.False(t, ots.ownsVehicle("vehicle-1"))
+}
+
+func TestOptimizedTelemetryService_StatsCountOptimizationSkips(t *testing.T) {
+	ots := NewOptimizedTelemetryService(nil, nil)
+	assert.Zero(t, ots.GetStats().OptimizationEfficiency())
+
+	for i := 0; i < 8; i++ {
+		ots.incrementTotalRequests()
+	}
+	ots.incrementDuplicatesDropped()
+	ots.incrementRateLimitRejects()
+	ots.incrementDeltaSkips()
+	ots.incrementDeltaSkips()
+	ots.incrementSpeedAnomalies()
+
+	stats := ots.GetStats()
+	assert.Equal(t, int64(4), stats.UpdatesSkipped)
+	assert.Equal(t, 0.5, stats.OptimizationEfficiency())
+}
+
+func TestOptimizedTelemetryService_ShedsLowPriorityUpdatesUnderQueuePressure(t *testing.T) {
+	processor := batch.NewBatchProcessor(batch.BatchConfig{
+		MaxBatchSize:  5, // the queue holds 10 updates
+		BatchInterval: time.Hour,
+		MaxWaitTime:   time.Hour,
+	}, nil)
+	ots := NewOptimizedTelemetryService(nil, processor)
+
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, processor.AddUpdate(fmt.Sprintf("queued-%d", i), batch.VehicleUpdateData{}))
+	}
+	assert.InDelta(t, 0.9, processor.QueuePressure(), 1e-9)
+
+	// A parked vehicle is low priority and an idle one medium; both are shed
+	assert.NoError(t, ots.ProcessVehicleUpdate("parked", &models.Vehicle{Status: "offline", FuelLevel: 50}))
+	assert.NoError(t, ots.ProcessVehicleUpdate("waiting", &models.Vehicle{Status: "idle", FuelLevel: 50}))
+	assert.Equal(t, int64(2), ots.GetStats().PressureSheds)
+	assert.InDelta(t, 0.9, processor.QueuePressure(), 1e-9)
+
+	// A vehicle low on fuel is critical and still takes the last slot
+	assert.NoError(t, ots.ProcessVehicleUpdate("stranded", &models.Vehicle{Status: "offline", FuelLevel: 5}))
+	assert.Equal(t, int64(2), ots.GetStats().PressureSheds)
+	assert.Equal(t, 1.0, processor.QueuePressure())
+}
+
+// pressuredBatchProcessor reports a queue pressure set by the test
+type pressuredBatchProcessor struct {
+	recordingBatchProcessor
+	pressure float64
+}
+
+func (p *pressuredBatchProcessor) QueuePressure() float64 { return p.pressure }
+
+func TestOptimizedTelemetryService_SendsShedChangeOncePressureClears(t *testing.T) {
+	processor := &pressuredBatchProcessor{}
+	ots := NewOptimizedTelemetryService(nil, processor)
+	parked := &models.Vehicle{Status: "offline", FuelLevel: 50, Location: models.Location{Lat: -1.2921, Lng: 36.8219}}
+
+	assert.NoError(t, ots.ProcessVehicleUpdate("parked", parked))
+	require.Len(t, processor.updates, 1)
+
+	// The vehicle is towed while the queue is under pressure
+	towed := *parked
+	towed.Location = models.Location{Lat: -1.3000, Lng: 36.8300}
+	processor.pressure = 0.9
+	assert.NoError(t, ots.ProcessVehicleUpdate("parked", &towed))
+	assert.Equal(t, int64(1), ots.GetStats().PressureSheds)
+	assert.Len(t, processor.updates, 1)
+
+	// The next report of the same position is still a change
+	processor.pressure = 0
+	assert.NoError(t, ots.ProcessVehicleUpdate("parked", &towed))
+	require.Len(t, processor.updates, 2)
+	assert.Equal(t, towed.Location, *processor.updates[1].Location)
+	assert.Zero(t, ots.GetStats().DeltaSkips)
+}
+
+func TestOptimizedTelemetryService_QueuesLowPriorityUpdatesBelowShedPressure(t *testing.T) {
+	processor := batch.NewBatchProcessor(batch.BatchConfig{MaxBatchSize: 5, BatchInterval: time.Hour, MaxWaitTime: time.Hour}, nil)
+	ots := NewOptimizedTelemetryService(nil, processor)
+	for i := 0; i < 7; i++ {
+		assert.NoError(t, processor.AddUpdate(fmt.Sprintf("queued-%d", i), batch.VehicleUpdateData{}))
+	}
+
+	assert.NoError(t, ots.ProcessVehicleUpdate("parked", &models.Vehicle{Status: "offline", FuelLevel: 50}))
+	assert.Zero(t, ots.GetStats().PressureSheds)
+	assert.InDelta(t, 0.8, processor.QueuePressure(), 1e-9)
+
+	// With shedding disabled even a nearly full queue takes low-priority updates
+	ots.SetShedPressure(0)
+	assert.NoError(t, processor.AddUpdate("queued-7", batch.VehicleUpdateData{}))
+	assert.NoError(t, ots.ProcessVehicleUpdate("idle", &models.Vehicle{Status: "offline", FuelLevel: 40}))
+	assert.Zero(t, ots.GetStats().PressureSheds)
+	assert.Equal(t, 1.0, processor.QueuePressure())
+}
+
+func TestOptimizedTelemetryService_DisablingDeltaUpdatesLetsSkippedUpdatesThrough(t *testing.T) {
+	processor := batch.NewBatchProcessor(batch.BatchConfig{MaxBatchSize: 5, BatchInterval: time.Hour, MaxWaitTime: time.Hour}, nil)
+	ots := NewOptimizedTelemetryService(nil, processor)
+	vehicle := &models.Vehicle{Status: "active", FuelLevel: 50, Speed: 40}
+
+	// The first report is sent; an unchanged one is skipped as insignificant
+	assert.NoError(t, ots.ProcessVehicleUpdate("vehicle-1", vehicle))
+	assert.NoError(t, ots.ProcessVehicleUpdate("vehicle-1", vehicle))
+	assert.Equal(t, int64(1), ots.GetStats().DeltaSkips)
+	assert.InDelta(t, 0.1, processor.QueuePressure(), 1e-9)
+
+	ots.SetDeltaUpdatesEnabled(false)
+	assert.False(t, ots.Optimizations().DeltaUpdates)
+	assert.NoError(t, ots.ProcessVehicleUpdate("vehicle-1", vehicle))
+	assert.NoError(t, ots.ProcessVehicleUpdate("vehicle-1", vehicle))
+	assert.Equal(t, int64(1), ots.GetStats().DeltaSkips)
+	assert.InDelta(t, 0.3, processor.QueuePressure(), 1e-9)
+
+	ots.SetDeltaUpdatesEnabled(true)
+	assert.NoError(t, ots.ProcessVehicleUpdate("vehicle-1", vehicle))
+	assert.Equal(t, int64(2), ots.GetStats().DeltaSkips)
+}
+
+func TestOptimizedTelemetryService_DeduplicationNeedsDeduplicator(t *testing.T) {
+	ots := NewOptimizedTelemetryService(nil, nil)
+	ots.SetDeduplicationEnabled(true)
+	assert.False(t, ots.Optimizations().Deduplication)
+
+	ots.SetDeduplicator(NewMemoryDeduplicator(time.Minute))
+	assert.True(t, ots.Optimizations().Deduplication)
+	ots.SetDeduplicationEnabled(false)
+	assert.False(t, ots.Optimizations().Deduplication)
+}
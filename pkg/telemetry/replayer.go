@@ -0,0 +1,307 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fleet-backend/internal/models"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recording formats read by ReadReplayRecords
+const (
+	ReplayFormatJSONL = "jsonl"
+	ReplayFormatCSV   = "csv"
+)
+
+// maxReplayErrors caps the errors listed in a replay result
+const maxReplayErrors = 100
+
+// ErrReplayInProgress is returned when a replay is started while another runs
+var ErrReplayInProgress = errors.New("a telemetry replay is already running")
+
+// ReplayRecord is one recorded reading of a vehicle
+type ReplayRecord struct {
+	VehicleID string          `json:"vehicleId"`
+	Timestamp time.Time       `json:"timestamp"`
+	Location  models.Location `json:"location"`
+	Speed     int             `json:"speed"`
+	FuelLevel float64         `json:"fuelLevel"`
+}
+
+// ReplayResult summarizes a finished replay
+type ReplayResult struct {
+	Records   int      `json:"records"`
+	Processed int      `json:"processed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// VehicleUpdateProcessor runs vehicle updates through the update pipeline
+type VehicleUpdateProcessor interface {
+	ProcessVehicleUpdate(vehicleID string, vehicle *models.Vehicle) error
+}
+
+// TelemetryReplayer feeds recorded telemetry through the update pipeline for
+// demos and regression tests. Gaps between recorded timestamps are waited out
+// divided by the replay speed, so a speed of 60 plays a recorded minute each
+// second; a speed of 0 plays records back to back.
+type TelemetryReplayer struct {
+	processor VehicleUpdateProcessor
+	vehicles  VehicleLookup
+	sleep     func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	running bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewTelemetryReplayer creates a replayer. Each vehicle's first record is
+// applied on top of the vehicle as looked up, so fields a recording doesn't
+// carry, like status and odometer, keep their current values.
+func NewTelemetryReplayer(processor VehicleUpdateProcessor, vehicles VehicleLookup) *TelemetryReplayer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TelemetryReplayer{
+		processor: processor,
+		vehicles:  vehicles,
+		sleep:     sleepContext,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start replays records in the background, returning ErrReplayInProgress if
+// a replay is already running
+func (r *TelemetryReplayer) Start(records []ReplayRecord, speed float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return ErrReplayInProgress
+	}
+	r.running = true
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			r.running = false
+			r.mu.Unlock()
+		}()
+
+		result, err := r.Replay(r.ctx, records, speed)
+		if err != nil {
+			log.Printf("Telemetry replay stopped after %d of %d records: %v", result.Processed+result.Failed, result.Records, err)
+			return
+		}
+		log.Printf("Telemetry replay finished: %d records, %d processed, %d failed", result.Records, result.Processed, result.Failed)
+	}()
+	return nil
+}
+
+// Running reports whether a background replay is in progress
+func (r *TelemetryReplayer) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// Stop cancels any background replay
+func (r *TelemetryReplayer) Stop() {
+	r.cancel()
+}
+
+// Replay feeds records through the update pipeline in timestamp order,
+// returning early only if ctx is cancelled
+func (r *TelemetryReplayer) Replay(ctx context.Context, records []ReplayRecord, speed float64) (ReplayResult, error) {
+	result := ReplayResult{Records: len(records)}
+
+	ordered := make([]ReplayRecord, len(records))
+	copy(ordered, records)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp.Before(ordered[j].Timestamp)
+	})
+
+	vehicles := make(map[string]models.Vehicle)
+	for i, record := range ordered {
+		if i > 0 && speed > 0 {
+			gap := time.Duration(float64(record.Timestamp.Sub(ordered[i-1].Timestamp)) / speed)
+			if gap > 0 {
+				if err := r.sleep(ctx, gap); err != nil {
+					return result, err
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		vehicle, known := vehicles[record.VehicleID]
+		if !known {
+			current, err := r.vehicles.GetVehicleByID(ctx, record.VehicleID)
+			if err != nil {
+				result.fail(fmt.Sprintf("record %d: vehicle %s: %v", i, record.VehicleID, err))
+				continue
+			}
+			vehicle = *current
+		}
+
+		vehicle.Location = record.Location
+		vehicle.Speed = record.Speed
+		vehicle.FuelLevel = record.FuelLevel
+		vehicle.LastUpdate = time.Now()
+		vehicles[record.VehicleID] = vehicle
+
+		// Each update gets its own copy; the pipeline may hold on to it
+		update := vehicle
+		if err := r.processor.ProcessVehicleUpdate(record.VehicleID, &update); err != nil {
+			result.fail(fmt.Sprintf("record %d: vehicle %s: %v", i, record.VehicleID, err))
+			continue
+		}
+		result.Processed++
+	}
+
+	return result, nil
+}
+
+func (result *ReplayResult) fail(message string) {
+	result.Failed++
+	if len(result.Errors) < maxReplayErrors {
+		result.Errors = append(result.Errors, message)
+	}
+}
+
+// ReadReplayRecords reads a recording in the given format. JSONL recordings
+// hold one ReplayRecord object per line. CSV recordings start with a header
+// naming the columns vehicleId, timestamp (RFC 3339), lat, lng, speed and
+// fuelLevel, in any order.
+func ReadReplayRecords(reader io.Reader, format string) ([]ReplayRecord, error) {
+	switch format {
+	case ReplayFormatJSONL:
+		return readReplayJSONL(reader)
+	case ReplayFormatCSV:
+		return readReplayCSV(reader)
+	default:
+		return nil, fmt.Errorf("unsupported replay format %q: use %s or %s", format, ReplayFormatJSONL, ReplayFormatCSV)
+	}
+}
+
+func readReplayJSONL(reader io.Reader) ([]ReplayRecord, error) {
+	var records []ReplayRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record ReplayRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := validateReplayRecord(record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+var replayCSVColumns = []string{"vehicleId", "timestamp", "lat", "lng", "speed", "fuelLevel"}
+
+func readReplayCSV(reader io.Reader) ([]ReplayRecord, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range replayCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var records []ReplayRecord
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := parseReplayRow(row, columns)
+		if err == nil {
+			err = validateReplayRecord(record)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+}
+
+func parseReplayRow(row []string, columns map[string]int) (ReplayRecord, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(row[columns[name]])
+	}
+
+	record := ReplayRecord{VehicleID: field("vehicleId")}
+	var err error
+	if record.Timestamp, err = time.Parse(time.RFC3339, field("timestamp")); err != nil {
+		return record, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	if record.Location.Lat, err = strconv.ParseFloat(field("lat"), 64); err != nil {
+		return record, fmt.Errorf("invalid lat: %w", err)
+	}
+	if record.Location.Lng, err = strconv.ParseFloat(field("lng"), 64); err != nil {
+		return record, fmt.Errorf("invalid lng: %w", err)
+	}
+	if record.Speed, err = strconv.Atoi(field("speed")); err != nil {
+		return record, fmt.Errorf("invalid speed: %w", err)
+	}
+	if record.FuelLevel, err = strconv.ParseFloat(field("fuelLevel"), 64); err != nil {
+		return record, fmt.Errorf("invalid fuelLevel: %w", err)
+	}
+	return record, nil
+}
+
+func validateReplayRecord(record ReplayRecord) error {
+	if record.VehicleID == "" {
+		return errors.New("vehicleId is required")
+	}
+	if record.Timestamp.IsZero() {
+		return errors.New("timestamp is required")
+	}
+	return nil
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const replayRecording = `
+{"vehicleId": "v1", "timestamp": "2026-01-01T08:00:00Z", "location": {"lat": -1.2900, "lng": 36.8200}, "speed": 0, "fuelLevel": 60}
+{"vehicleId": "v1", "timestamp": "2026-01-01T08:01:00Z", "location": {"lat": -1.2950, "lng": 36.8250}, "speed": 40, "fuelLevel": 59.5}
+{"vehicleId": "v1", "timestamp": "2026-01-01T08:03:00Z", "location": {"lat": -1.3050, "lng": 36.8350}, "speed": 70, "fuelLevel": 58.8}
+`
+
+func TestTelemetryReplayer_ReplaysThroughPipeline(t *testing.T) {
+	records, err := ReadReplayRecords(strings.NewReader(replayRecording), ReplayFormatJSONL)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	processor := &recordingBatchProcessor{}
+	service := NewOptimizedTelemetryService(nil, processor)
+	replayer := NewTelemetryReplayer(service, &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}})
+	var waits []time.Duration
+	replayer.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	result, err := replayer.Replay(context.Background(), records, 60)
+	require.NoError(t, err)
+
+	assert.Equal(t, ReplayResult{Records: 3, Processed: 3}, result)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits, "gaps are compressed 60x")
+	require.Len(t, processor.updates, 3)
+	assert.Equal(t, 70, *processor.updates[2].Speed)
+
+	// Replaying the last reading again is an insignificant change and skipped
+	result, err = replayer.Replay(context.Background(), records[2:], 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Processed)
+	assert.Len(t, processor.updates, 3)
+	assert.Equal(t, int64(1), service.GetStats().DeltaSkips)
+}
+
+func TestTelemetryReplayer_ReportsUnknownVehicles(t *testing.T) {
+	records := []ReplayRecord{
+		{VehicleID: "ghost", Timestamp: time.Now()},
+		{VehicleID: "v1", Timestamp: time.Now()},
+	}
+	processor := &recordingBatchProcessor{}
+	replayer := NewTelemetryReplayer(NewOptimizedTelemetryService(nil, processor), &fakeVehicleLookup{vehicles: map[string]bool{"v1": true}})
+
+	result, err := replayer.Replay(context.Background(), records, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "ghost")
+	assert.Len(t, processor.updates, 1)
+}
+
+func TestReadReplayRecords_CSV(t *testing.T) {
+	recording := "timestamp,vehicleId,lat,lng,speed,fuelLevel\n" +
+		"2026-01-01T08:00:00Z,v1,-1.29,36.82,45,60.5\n"
+
+	records, err := ReadReplayRecords(strings.NewReader(recording), ReplayFormatCSV)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "v1", records[0].VehicleID)
+	assert.Equal(t, -1.29, records[0].Location.Lat)
+	assert.Equal(t, 45, records[0].Speed)
+	assert.Equal(t, 60.5, records[0].FuelLevel)
+
+	_, err = ReadReplayRecords(strings.NewReader("vehicleId,timestamp\nv1,2026-01-01T08:00:00Z\n"), ReplayFormatCSV)
+	assert.ErrorContains(t, err, `missing column "lat"`)
+
+	_, err = ReadReplayRecords(strings.NewReader(`{"vehicleId": "v1"}`), ReplayFormatJSONL)
+	assert.ErrorContains(t, err, "timestamp is required")
+}
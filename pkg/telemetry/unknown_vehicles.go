@@ -0,0 +1,145 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/services"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ways of handling telemetry for vehicle IDs no vehicle has
+const (
+	// UnknownVehicleReject rejects the samples, logs them and publishes an
+	// unknown vehicle event
+	UnknownVehicleReject = "reject"
+	// UnknownVehicleProvision creates a minimal vehicle record and accepts the samples
+	UnknownVehicleProvision = "provision"
+	// UnknownVehicleQueue rejects the samples and queues the ID for an
+	// operator to provision
+	UnknownVehicleQueue = "queue"
+)
+
+// RejectUnknownVehicleQueued is the rejection reason for samples whose
+// vehicle ID was queued for provisioning
+const RejectUnknownVehicleQueued = "unknown_vehicle_queued"
+
+// ErrInvalidUnknownVehicleHandling is returned for an unknown mode, or a mode
+// without the dependency it needs
+var ErrInvalidUnknownVehicleHandling = errors.New("invalid unknown vehicle handling")
+
+// VehicleProvisioner creates minimal vehicle records for unknown vehicle IDs
+type VehicleProvisioner interface {
+	ProvisionVehicle(ctx context.Context, id string) (*models.Vehicle, error)
+}
+
+// UnknownVehicleQueuer queues unknown vehicle IDs for manual provisioning
+type UnknownVehicleQueuer interface {
+	Enqueue(ctx context.Context, vehicleID string, location *models.Location, at time.Time) error
+}
+
+// UnknownVehicleHandling selects what the ingestor does with samples for
+// vehicle IDs no vehicle has. Provision mode needs a Provisioner and queue
+// mode a Queue; Events, when set, receives an event for each rejected ID.
+type UnknownVehicleHandling struct {
+	Mode        string
+	Provisioner VehicleProvisioner
+	Queue       UnknownVehicleQueuer
+	Events      services.EventPublisher
+}
+
+// IsValidUnknownVehicleMode reports whether mode is one of the unknown vehicle modes
+func IsValidUnknownVehicleMode(mode string) bool {
+	switch mode {
+	case UnknownVehicleReject, UnknownVehicleProvision, UnknownVehicleQueue:
+		return true
+	}
+	return false
+}
+
+// SetUnknownVehicleHandling sets how samples for unknown vehicle IDs are
+// handled; the default rejects them
+func (i *Ingestor) SetUnknownVehicleHandling(handling UnknownVehicleHandling) error {
+	switch {
+	case !IsValidUnknownVehicleMode(handling.Mode):
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidUnknownVehicleHandling, handling.Mode)
+	case handling.Mode == UnknownVehicleProvision && handling.Provisioner == nil:
+		return fmt.Errorf("%w: provision mode needs a provisioner", ErrInvalidUnknownVehicleHandling)
+	case handling.Mode == UnknownVehicleQueue && handling.Queue == nil:
+		return fmt.Errorf("%w: queue mode needs a queue", ErrInvalidUnknownVehicleHandling)
+	}
+	i.unknownVehicles = handling
+	return nil
+}
+
+// vehicleCheck is the outcome of looking up a sample's vehicle, shared by
+// every sample of the vehicle in a request
+type vehicleCheck struct {
+	reason string // rejection reason, or "" when the vehicle accepts samples
+	status string // the vehicle's status, as of its latest accepted sample
+}
+
+// checkVehicle looks up the sample's vehicle, returning the rejection reason
+// for every sample of the vehicle in the request or "", and its status
+func (i *Ingestor) checkVehicle(ctx context.Context, sample *TelemetrySample, now time.Time) *vehicleCheck {
+	vehicle, err := i.vehicles.GetVehicleByID(ctx, sample.VehicleID)
+	switch {
+	case err == nil && vehicle != nil && !vehicle.IsArchived():
+		return &vehicleCheck{status: vehicle.Status}
+	case errors.Is(err, services.ErrVehicleNotFound):
+		return i.handleUnknownVehicle(ctx, sample, now)
+	default:
+		// Archived vehicles and failed lookups are rejected whatever the mode
+		return &vehicleCheck{reason: RejectUnknownVehicle}
+	}
+}
+
+// handleUnknownVehicle applies the unknown vehicle mode to the first sample of
+// a request for a vehicle ID no vehicle has
+func (i *Ingestor) handleUnknownVehicle(ctx context.Context, sample *TelemetrySample, now time.Time) *vehicleCheck {
+	handling := i.unknownVehicles
+	// IDs that can never name a vehicle aren't provisioned or queued
+	if handling.Mode != UnknownVehicleReject && handling.Mode != "" && !primitive.IsValidObjectID(sample.VehicleID) {
+		handling.Mode = UnknownVehicleReject
+	}
+
+	switch handling.Mode {
+	case UnknownVehicleProvision:
+		vehicle, err := handling.Provisioner.ProvisionVehicle(ctx, sample.VehicleID)
+		if err != nil {
+			log.Printf("unknown_vehicle: failed to provision vehicle %s: %v", sample.VehicleID, err)
+			return &vehicleCheck{reason: RejectUnknownVehicle}
+		}
+		log.Printf("unknown_vehicle: provisioned vehicle %s from telemetry", sample.VehicleID)
+		return &vehicleCheck{status: vehicle.Status}
+
+	case UnknownVehicleQueue:
+		at := sample.Timestamp
+		if at.IsZero() {
+			at = now
+		}
+		if err := handling.Queue.Enqueue(ctx, sample.VehicleID, sample.Location, at); err != nil {
+			log.Printf("unknown_vehicle: failed to queue vehicle %s: %v", sample.VehicleID, err)
+			return &vehicleCheck{reason: RejectUnknownVehicle}
+		}
+		log.Printf("unknown_vehicle: queued vehicle %s for provisioning", sample.VehicleID)
+		return &vehicleCheck{reason: RejectUnknownVehicleQueued}
+
+	default:
+		log.Printf("unknown_vehicle: rejected telemetry for vehicle %s", sample.VehicleID)
+		if handling.Events != nil {
+			handling.Events.Publish(models.WebhookEvent{
+				ID:        primitive.NewObjectID().Hex(),
+				Type:      models.EventTelemetryUnknownVehicle,
+				VehicleID: sample.VehicleID,
+				Timestamp: now,
+				Data:      map[string]interface{}{"messageId": sample.MessageID},
+			})
+		}
+		return &vehicleCheck{reason: RejectUnknownVehicle}
+	}
+}
@@ -0,0 +1,146 @@
+package telemetry
+
+import (
+	"context"
+	"fleet-backend/internal/models"
+	"fleet-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryFleet is a vehicle lookup that reports missing vehicles the way the
+// vehicle service does, and provisions them
+type memoryFleet struct {
+	vehicles    map[string]*models.Vehicle
+	provisioned []string
+}
+
+func (f *memoryFleet) GetVehicleByID(_ context.Context, id string) (*models.Vehicle, error) {
+	if vehicle, ok := f.vehicles[id]; ok {
+		return vehicle, nil
+	}
+	return nil, services.ErrVehicleNotFound
+}
+
+func (f *memoryFleet) ProvisionVehicle(_ context.Context, id string) (*models.Vehicle, error) {
+	vehicle := &models.Vehicle{Name: "UNPROVISIONED-" + id}
+	f.vehicles[id] = vehicle
+	f.provisioned = append(f.provisioned, id)
+	return vehicle, nil
+}
+
+type queuedVehicle struct {
+	vehicleID string
+	location  *models.Location
+	at        time.Time
+}
+
+type memoryUnknownVehicleQueue struct {
+	queued []queuedVehicle
+}
+
+func (q *memoryUnknownVehicleQueue) Enqueue(_ context.Context, vehicleID string, location *models.Location, at time.Time) error {
+	q.queued = append(q.queued, queuedVehicle{vehicleID: vehicleID, location: location, at: at})
+	return nil
+}
+
+type recordingEventPublisher struct {
+	events []models.WebhookEvent
+}
+
+func (p *recordingEventPublisher) Publish(event models.WebhookEvent) {
+	p.events = append(p.events, event)
+}
+
+func unknownVehicleSamples(id string) []TelemetrySample {
+	return []TelemetrySample{
+		{VehicleID: id, Location: &models.Location{Lat: -1.29, Lng: 36.82}, Timestamp: time.Now().Add(-time.Minute)},
+		{VehicleID: id, Speed: intPtr(40)},
+	}
+}
+
+func TestIngestBulk_UnknownVehicleRejectedByDefault(t *testing.T) {
+	fleet := &memoryFleet{vehicles: map[string]*models.Vehicle{}}
+	processor := &recordingBatchProcessor{}
+	events := &recordingEventPublisher{}
+	ingestor := NewIngestor(fleet, processor)
+	require.NoError(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleReject, Events: events}))
+
+	id := primitive.NewObjectID().Hex()
+	result := ingestor.IngestBulk(context.Background(), unknownVehicleSamples(id))
+
+	assert.Equal(t, 0, result.Accepted)
+	for _, sample := range result.Results {
+		assert.Equal(t, RejectUnknownVehicle, sample.Reason)
+	}
+	assert.Empty(t, processor.updates)
+	assert.Empty(t, fleet.provisioned)
+
+	// One event per vehicle per request
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.EventTelemetryUnknownVehicle, events.events[0].Type)
+	assert.Equal(t, id, events.events[0].VehicleID)
+}
+
+func TestIngestBulk_UnknownVehicleProvisioned(t *testing.T) {
+	fleet := &memoryFleet{vehicles: map[string]*models.Vehicle{}}
+	processor := &recordingBatchProcessor{}
+	ingestor := NewIngestor(fleet, processor)
+	require.NoError(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleProvision, Provisioner: fleet}))
+
+	id := primitive.NewObjectID().Hex()
+	result := ingestor.IngestBulk(context.Background(), unknownVehicleSamples(id))
+
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, []string{id}, fleet.provisioned)
+	assert.Contains(t, fleet.vehicles, id)
+	assert.Len(t, processor.updates, 2)
+}
+
+func TestIngestBulk_UnknownVehicleQueued(t *testing.T) {
+	fleet := &memoryFleet{vehicles: map[string]*models.Vehicle{}}
+	processor := &recordingBatchProcessor{}
+	queue := &memoryUnknownVehicleQueue{}
+	ingestor := NewIngestor(fleet, processor)
+	require.NoError(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleQueue, Queue: queue}))
+
+	id := primitive.NewObjectID().Hex()
+	samples := unknownVehicleSamples(id)
+	result := ingestor.IngestBulk(context.Background(), samples)
+
+	assert.Equal(t, 0, result.Accepted)
+	for _, sample := range result.Results {
+		assert.Equal(t, RejectUnknownVehicleQueued, sample.Reason)
+	}
+	assert.Empty(t, processor.updates)
+
+	require.Len(t, queue.queued, 1)
+	assert.Equal(t, id, queue.queued[0].vehicleID)
+	assert.Equal(t, samples[0].Location, queue.queued[0].location)
+	assert.Equal(t, samples[0].Timestamp, queue.queued[0].at)
+}
+
+func TestIngestBulk_UnknownVehicleModesKeepRejectingInvalidIDs(t *testing.T) {
+	fleet := &memoryFleet{vehicles: map[string]*models.Vehicle{}}
+	queue := &memoryUnknownVehicleQueue{}
+	ingestor := NewIngestor(fleet, &recordingBatchProcessor{})
+	require.NoError(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleQueue, Queue: queue}))
+
+	result := ingestor.IngestBulk(context.Background(), unknownVehicleSamples("not-an-id"))
+
+	assert.Equal(t, RejectUnknownVehicle, result.Results[0].Reason)
+	assert.Empty(t, queue.queued)
+}
+
+func TestSetUnknownVehicleHandling_RequiresModeDependencies(t *testing.T) {
+	ingestor := NewIngestor(&memoryFleet{}, &recordingBatchProcessor{})
+
+	assert.ErrorIs(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: "ignore"}), ErrInvalidUnknownVehicleHandling)
+	assert.ErrorIs(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleProvision}), ErrInvalidUnknownVehicleHandling)
+	assert.ErrorIs(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleQueue}), ErrInvalidUnknownVehicleHandling)
+	assert.NoError(t, ingestor.SetUnknownVehicleHandling(UnknownVehicleHandling{Mode: UnknownVehicleReject}))
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"net/http"
 
+	"fleet-backend/pkg/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
@@ -13,6 +15,8 @@ type APIResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   interface{} `json:"error,omitempty"`
+	// Code identifies the kind of error for clients, e.g. "duplicate_plate"
+	Code string `json:"code,omitempty"`
 }
 
 // SuccessResponse sends a successful response
@@ -26,20 +30,44 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 
 // ErrorResponse sends an error response
 func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
+	CodedErrorResponse(c, statusCode, "", message, err)
+}
+
+// CodedErrorResponse sends an error response with a machine-readable code
+func CodedErrorResponse(c *gin.Context, statusCode int, code string, message string, err error) {
 	response := APIResponse{
 		Success: false,
 		Message: message,
+		Code:    code,
 	}
 
 	if err != nil {
 		response.Error = err.Error()
 	}
 
+	// Server errors are logged with the request ID so they can be traced
+	if statusCode >= http.StatusInternalServerError && c.Request != nil {
+		logger.FromContext(c.Request.Context()).Error(message, "status", statusCode, "error", response.Error)
+	}
+
 	c.JSON(statusCode, response)
 }
 
+// CodeValidationFailed is the code of responses to requests that failed validation
+const CodeValidationFailed = "validation_failed"
+
 // ValidationErrorResponse sends a validation error response
 func ValidationErrorResponse(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Success: false,
+		Message: "Validation failed",
+		Error:   ValidationErrorMessages(err),
+		Code:    CodeValidationFailed,
+	})
+}
+
+// ValidationErrorMessages converts a validation error into user-friendly messages
+func ValidationErrorMessages(err error) []string {
 	var errors []string
 	
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
@@ -50,11 +78,7 @@ func ValidationErrorResponse(c *gin.Context, err error) {
 		errors = append(errors, err.Error())
 	}
 
-	c.JSON(http.StatusBadRequest, APIResponse{
-		Success: false,
-		Message: "Validation failed",
-		Error:   errors,
-	})
+	return errors
 }
 
 // getValidationErrorMessage returns a user-friendly validation error message